@@ -10,6 +10,14 @@ type Node interface {
 	Pos() token.Pos
 }
 
+// Ranger is implemented by the nodes of the syntax tree which know where
+// their source ends. Together with Pos, End describes the source range of a
+// node, [Pos, End), for editors and error reporters.
+type Ranger interface {
+	Node
+	End() token.Pos
+}
+
 func (File) node()       {}
 func (ObjectList) node() {}
 func (ObjectKey) node()  {}
@@ -25,12 +33,31 @@ func (ListType) node()     {}
 type File struct {
 	Node     Node            // usually a *ObjectList
 	Comments []*CommentGroup // list of all comments in the source
+
+	// Directives are the directives of the comments not associated with an
+	// item, i.e. at the top of the file
+	Directives []*CommentDirective
+
+	// EndPos is the position right after the last character of the
+	// source, including trailing comments and white space, i.e. to append
+	// items. A file without a trailing newline ends on the line of its
+	// last item.
+	EndPos token.Pos
 }
 
 func (f *File) Pos() token.Pos {
 	return f.Node.Pos()
 }
 
+// End returns EndPos, the end of the source, or the end of the root node if
+// it's not set.
+func (f *File) End() token.Pos {
+	if f.EndPos.IsValid() {
+		return f.EndPos
+	}
+	return End(f.Node)
+}
+
 // ObjectList represents a list of ObjectItems. An HCL file itself is an
 // ObjectList.
 type ObjectList struct {
@@ -42,10 +69,21 @@ func (o *ObjectList) Add(item *ObjectItem) {
 }
 
 func (o *ObjectList) Pos() token.Pos {
-	// always returns the uninitiliazed position
+	// returns the uninitiliazed position for an empty list
+	if len(o.Items) == 0 {
+		return token.Pos{}
+	}
 	return o.Items[0].Pos()
 }
 
+func (o *ObjectList) End() token.Pos {
+	// returns the uninitiliazed position for an empty list
+	if len(o.Items) == 0 {
+		return token.Pos{}
+	}
+	return o.Items[len(o.Items)-1].End()
+}
+
 // ObjectItem represents a HCL Object Item. An item is represented with a key
 // (or keys). It can be an assignment or an object (both normal and nested)
 type ObjectItem struct {
@@ -64,12 +102,23 @@ type ObjectItem struct {
 
 	LeadComment *CommentGroup // associated lead comment
 	LineComment *CommentGroup // associated line comment
+
+	// Directives are the directives of the lead and line comment
+	Directives []*CommentDirective
 }
 
 func (o *ObjectItem) Pos() token.Pos {
 	return o.Keys[0].Pos()
 }
 
+// End returns the end of the value, without the line comment.
+func (o *ObjectItem) End() token.Pos {
+	if o.Val == nil {
+		return o.Keys[len(o.Keys)-1].End()
+	}
+	return End(o.Val)
+}
+
 // ObjectKeys are either an identifier or of type string.
 type ObjectKey struct {
 	Token token.Token
@@ -79,30 +128,64 @@ func (o *ObjectKey) Pos() token.Pos {
 	return o.Token.Pos
 }
 
+func (o *ObjectKey) End() token.Pos {
+	return o.Token.End()
+}
+
 // LiteralType represents a literal of basic type. Valid types are:
-// token.NUMBER, token.FLOAT, token.BOOL and token.STRING
+// token.NUMBER, token.FLOAT, token.BOOL, token.STRING, token.HEREDOC and
+// token.UNIT
 type LiteralType struct {
 	Token token.Token
 
 	// associated line comment, only when used in a list
 	LineComment *CommentGroup
+
+	// Template is the parsed content of a string containing "${" or "%{",
+	// i.e. "${var.name}", set by the parser for analyzers.
+	// It's nil for other literals and if the template is invalid, whose
+	// errors are reported when it's evaluated.
+	Template *Template
+
+	// Parts are the original tokens of a string folded from several strings,
+	// i.e. "a" + "b", including the "+" tokens, so the printer can keep the
+	// pieces. Token is the folded string. It's nil for other literals.
+	Parts []token.Token
 }
 
 func (l *LiteralType) Pos() token.Pos {
 	return l.Token.Pos
 }
 
+func (l *LiteralType) End() token.Pos {
+	if len(l.Parts) > 0 {
+		return l.Parts[len(l.Parts)-1].End()
+	}
+	return l.Token.End()
+}
+
 // ListStatement represents a HCL List type
 type ListType struct {
 	Lbrack token.Pos // position of "["
 	Rbrack token.Pos // position of "]"
 	List   []Node    // the elements in lexical order
+
+	// Newlines and TrailingComma record the separator style of the source,
+	// which the printer keeps: whether the elements are separated by
+	// newlines only, as allowed by parser.OptionalListCommas, instead of
+	// commas and whether a comma follows the last element.
+	Newlines      bool
+	TrailingComma bool
 }
 
 func (l *ListType) Pos() token.Pos {
 	return l.Lbrack
 }
 
+func (l *ListType) End() token.Pos {
+	return after(l.Rbrack)
+}
+
 func (l *ListType) Add(node Node) {
 	l.List = append(l.List, node)
 }
@@ -112,12 +195,20 @@ type ObjectType struct {
 	Lbrace token.Pos   // position of "{"
 	Rbrace token.Pos   // position of "}"
 	List   *ObjectList // the nodes in lexical order
+
+	// TrailComment is the comment group after the last item, ending on the
+	// line before "}", if any
+	TrailComment *CommentGroup
 }
 
 func (o *ObjectType) Pos() token.Pos {
 	return o.Lbrace
 }
 
+func (o *ObjectType) End() token.Pos {
+	return after(o.Rbrace)
+}
+
 // Comment node represents a single //, # style or /*- style commment
 type Comment struct {
 	Start token.Pos // position of / or #
@@ -128,6 +219,10 @@ func (c *Comment) Pos() token.Pos {
 	return c.Start
 }
 
+func (c *Comment) End() token.Pos {
+	return token.Token{Pos: c.Start, Text: c.Text}.End()
+}
+
 // CommentGroup node represents a sequence of comments with no other tokens and
 // no empty lines between.
 type CommentGroup struct {
@@ -137,3 +232,46 @@ type CommentGroup struct {
 func (c *CommentGroup) Pos() token.Pos {
 	return c.List[0].Pos()
 }
+
+func (c *CommentGroup) End() token.Pos {
+	return c.List[len(c.List)-1].End()
+}
+
+// End returns the end of the source range of n, the position of the
+// character immediately after it, or its position if n doesn't know where it
+// ends, such as the expressions of templates.
+func End(n Node) token.Pos {
+	if r, ok := n.(Ranger); ok {
+		return r.End()
+	}
+	return n.Pos()
+}
+
+// ObjectListOf returns the items of the object n, a File, an ObjectList or
+// an ObjectType, whose nil list has no items. It reports false for other
+// nodes.
+func ObjectListOf(n Node) (*ObjectList, bool) {
+	switch t := n.(type) {
+	case *File:
+		return ObjectListOf(t.Node)
+	case *ObjectList:
+		return t, true
+	case *ObjectType:
+		if t.List == nil {
+			return &ObjectList{}, true
+		}
+		return t.List, true
+	}
+	return nil, false
+}
+
+// after returns the position after the single character delimiter at pos,
+// such as "}". Invalid positions, of nodes which weren't parsed, are kept.
+func after(pos token.Pos) token.Pos {
+	if !pos.IsValid() {
+		return pos
+	}
+	pos.Offset++
+	pos.Column++
+	return pos
+}
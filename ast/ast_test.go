@@ -0,0 +1,84 @@
+package ast_test
+
+import (
+	"testing"
+
+	"github.com/fatih/hcl/ast"
+	"github.com/fatih/hcl/parser"
+)
+
+func TestEnd(t *testing.T) {
+	src := `# lead
+name = "web" // line
+ports = [80,
+  443]
+service "http" {
+  motd = <<EOT
+hello
+EOT
+}
+`
+	f, err := parser.Parse([]byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// source returns the source range of the node
+	source := func(n ast.Node) string {
+		return src[n.Pos().Offset:ast.End(n).Offset]
+	}
+
+	items := f.Node.(*ast.ObjectList).Items
+	service := items[2].Val.(*ast.ObjectType)
+	cases := []struct {
+		n    ast.Node
+		want string
+	}{
+		{items[0].LeadComment, "# lead"},
+		{items[0].LineComment, "// line"},
+		{items[0], `name = "web"`},
+		{items[0].Keys[0], "name"},
+		{items[0].Val, `"web"`},
+		{items[1], "ports = [80,\n  443]"},
+		{items[1].Val.(*ast.ListType).List[1], "443"},
+		{service, "{\n  motd = <<EOT\nhello\nEOT\n}"},
+		{service.List.Items[0].Val, "<<EOT\nhello\nEOT"},
+		{f.Node, src[len("# lead\n") : len(src)-1]},
+		{f, src[len("# lead\n"):]},
+	}
+
+	for _, c := range cases {
+		if got := source(c.n); got != c.want {
+			t.Errorf("%T: want %q, got %q", c.n, c.want, got)
+		}
+	}
+
+}
+
+func TestObjectListOf(t *testing.T) {
+	f, err := parser.Parse([]byte("a {\n  b = 1\n}\nc = {}\nd = 1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	items := f.Node.(*ast.ObjectList).Items
+
+	cases := []struct {
+		n     ast.Node
+		items int
+		ok    bool
+	}{
+		{f, 3, true},
+		{f.Node, 3, true},
+		{items[0].Val, 1, true},
+		{items[1].Val, 0, true},
+		{&ast.ObjectType{}, 0, true},
+		{items[2].Val, 0, false},
+	}
+
+	for i, c := range cases {
+		list, ok := ast.ObjectListOf(c.n)
+		if ok != c.ok || ok && len(list.Items) != c.items {
+			t.Errorf("%d: want %d items, %t, got %v, %t", i, c.items, c.ok, list, ok)
+		}
+	}
+}
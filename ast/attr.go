@@ -0,0 +1,245 @@
+package ast
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/fatih/hcl/token"
+)
+
+// Value is the value of an attribute or an element of a list. Its getters
+// return the value if it's of the requested type, without the conversions
+// of the decoder, i.e. AsInt fails for "80".
+type Value struct {
+	// Name is the name of the attribute, with the index for elements of
+	// lists, i.e. "ports[1]". It's used for errors.
+	Name string
+
+	Node Node
+}
+
+// Attribute is an item with a value which isn't an object, i.e. port = 80.
+type Attribute struct {
+	Value
+	Item *ObjectItem
+}
+
+// Block is an item with an object value, i.e. service "web" {} or
+// limits = {}. Its attributes and nested blocks are accessed like the ones
+// of a file.
+type Block struct {
+	Type   string   // first key, unquoted
+	Labels []string // other keys, unquoted
+	Item   *ObjectItem
+}
+
+// Attributes returns the attributes of f, in the order of the source.
+func (f *File) Attributes() []*Attribute {
+	list, _ := f.Node.(*ObjectList)
+	return attributes(list)
+}
+
+// Attr returns the attribute name of f, or nil if there's none. If there are
+// several, the last one is returned, like the decoder does.
+func (f *File) Attr(name string) *Attribute {
+	return attr(f.Attributes(), name)
+}
+
+// Blocks returns the blocks of f of the given type, or all blocks if typ is
+// empty.
+func (f *File) Blocks(typ string) []*Block {
+	list, _ := f.Node.(*ObjectList)
+	return blocks(list, typ)
+}
+
+// Attributes returns the attributes of b, in the order of the source.
+func (b *Block) Attributes() []*Attribute {
+	return attributes(b.Item.Val.(*ObjectType).List)
+}
+
+// Attr returns the attribute name of b, or nil if there's none. If there are
+// several, the last one is returned.
+func (b *Block) Attr(name string) *Attribute {
+	return attr(b.Attributes(), name)
+}
+
+// Blocks returns the nested blocks of b of the given type, or all nested
+// blocks if typ is empty.
+func (b *Block) Blocks(typ string) []*Block {
+	return blocks(b.Item.Val.(*ObjectType).List, typ)
+}
+
+func attributes(list *ObjectList) []*Attribute {
+	if list == nil {
+		return nil
+	}
+
+	var attrs []*Attribute
+	for _, item := range list.Items {
+		if _, ok := item.Val.(*ObjectType); ok || len(item.Keys) != 1 {
+			continue
+		}
+
+		attrs = append(attrs, &Attribute{
+			Value: Value{Name: item.Keys[0].Token.Value(), Node: item.Val},
+			Item:  item,
+		})
+	}
+	return attrs
+}
+
+func attr(attrs []*Attribute, name string) *Attribute {
+	for i := len(attrs) - 1; i >= 0; i-- {
+		if attrs[i].Name == name {
+			return attrs[i]
+		}
+	}
+	return nil
+}
+
+func blocks(list *ObjectList, typ string) []*Block {
+	if list == nil {
+		return nil
+	}
+
+	var res []*Block
+	for _, item := range list.Items {
+		if _, ok := item.Val.(*ObjectType); !ok || len(item.Keys) == 0 {
+			continue
+		}
+
+		b := &Block{Type: item.Keys[0].Token.Value(), Item: item}
+		if typ != "" && b.Type != typ {
+			continue
+		}
+
+		for _, k := range item.Keys[1:] {
+			b.Labels = append(b.Labels, k.Token.Value())
+		}
+		res = append(res, b)
+	}
+	return res
+}
+
+// Pos returns the position of the value.
+func (v Value) Pos() token.Pos {
+	return v.Node.Pos()
+}
+
+// AsString returns the unquoted value of a string.
+func (v Value) AsString() (string, error) {
+	lit, err := v.literal("a string", token.STRING, token.HEREDOC)
+	if err != nil {
+		return "", err
+	}
+	if lit.Token.Type == token.HEREDOC {
+		return lit.Token.HeredocValue(), nil
+	}
+
+	s, err := strconv.Unquote(lit.Token.Text)
+	if err != nil {
+		return "", v.errorf("invalid string %s", lit.Token.Text)
+	}
+	return s, nil
+}
+
+// AsInt returns the value of a number without a fraction.
+func (v Value) AsInt() (int64, error) {
+	lit, err := v.literal("an integer", token.NUMBER)
+	if err != nil {
+		return 0, err
+	}
+
+	i, err := lit.Token.BigInt()
+	if err != nil {
+		return 0, v.errorf("%s", err)
+	}
+	if !i.IsInt64() {
+		return 0, v.errorf("number %s overflows int64", lit.Token.Text)
+	}
+	return i.Int64(), nil
+}
+
+// AsFloat returns the value of a number, with or without a fraction.
+func (v Value) AsFloat() (float64, error) {
+	lit, err := v.literal("a number", token.NUMBER, token.FLOAT)
+	if err != nil {
+		return 0, err
+	}
+
+	f, err := lit.Token.BigFloat()
+	if err != nil {
+		return 0, v.errorf("%s", err)
+	}
+	res, _ := f.Float64()
+	return res, nil
+}
+
+// AsBool returns the value of a bool.
+func (v Value) AsBool() (bool, error) {
+	lit, err := v.literal("a bool", token.BOOL)
+	if err != nil {
+		return false, err
+	}
+	return strings.EqualFold(lit.Token.Text, "true"), nil
+}
+
+// IsNull reports whether the value is null.
+func (v Value) IsNull() bool {
+	lit, ok := v.Node.(*LiteralType)
+	return ok && lit.Token.Type == token.NULL
+}
+
+// AsList returns the elements of a list.
+func (v Value) AsList() ([]Value, error) {
+	list, ok := v.Node.(*ListType)
+	if !ok {
+		return nil, v.errorf("expected a list, got %s", describe(v.Node))
+	}
+
+	elems := make([]Value, len(list.List))
+	for i, n := range list.List {
+		elems[i] = Value{Name: fmt.Sprintf("%s[%d]", v.Name, i), Node: n}
+	}
+	return elems, nil
+}
+
+// literal returns the literal of v if it's of one of the given types
+func (v Value) literal(want string, types ...token.Type) (*LiteralType, error) {
+	if lit, ok := v.Node.(*LiteralType); ok {
+		for _, typ := range types {
+			if lit.Token.Type == typ {
+				return lit, nil
+			}
+		}
+	}
+	return nil, v.errorf("expected %s, got %s", want, describe(v.Node))
+}
+
+// errorf returns an error formatted like the errors of package parser
+func (v Value) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("At %s: %s: %s", v.Pos(), v.Name, fmt.Sprintf(format, args...))
+}
+
+// describe returns the kind of the value n for errors
+func describe(n Node) string {
+	switch t := n.(type) {
+	case *ListType:
+		return "list"
+	case *ObjectType:
+		return "object"
+	case *LiteralType:
+		switch t.Token.Type {
+		case token.STRING, token.HEREDOC:
+			return "string"
+		case token.NUMBER, token.FLOAT, token.UNIT:
+			return "number"
+		case token.BOOL:
+			return "bool"
+		case token.NULL:
+			return "null"
+		}
+	}
+	return fmt.Sprintf("%T", n)
+}
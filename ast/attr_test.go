@@ -0,0 +1,112 @@
+package ast_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/fatih/hcl/parser"
+)
+
+func TestAttributes(t *testing.T) {
+	src := `name = "app"
+debug = false
+name = "override"
+
+service "http" "web" {
+  port    = 80
+  weight  = 0.5
+  hosts   = ["a", "b"]
+  proxy   = null
+  limits = {
+    cpu = 2
+  }
+}
+
+service "http" "api" {}
+`
+	f, err := (&parser.Config{Syntax: parser.Experimental}).Parse([]byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for _, a := range f.Attributes() {
+		names = append(names, a.Name)
+	}
+	if want := []string{"name", "debug", "name"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("want attributes %v, got %v", want, names)
+	}
+
+	if s, err := f.Attr("name").AsString(); err != nil || s != "override" {
+		t.Errorf("name: want %q, got %q, %v", "override", s, err)
+	}
+
+	if b, err := f.Attr("debug").AsBool(); err != nil || b {
+		t.Errorf("debug: want false, got %v, %v", b, err)
+	}
+
+	if f.Attr("missing") != nil {
+		t.Error("expected no attribute missing")
+	}
+
+	blocks := f.Blocks("service")
+	if len(blocks) != 2 || !reflect.DeepEqual(blocks[0].Labels, []string{"http", "web"}) {
+		t.Fatalf("unexpected blocks: %v", blocks)
+	}
+
+	web := blocks[0]
+	if i, err := web.Attr("port").AsInt(); err != nil || i != 80 {
+		t.Errorf("port: want 80, got %d, %v", i, err)
+	}
+
+	if f, err := web.Attr("port").AsFloat(); err != nil || f != 80 {
+		t.Errorf("port: want 80, got %v, %v", f, err)
+	}
+
+	if f, err := web.Attr("weight").AsFloat(); err != nil || f != 0.5 {
+		t.Errorf("weight: want 0.5, got %v, %v", f, err)
+	}
+
+	if !web.Attr("proxy").IsNull() || web.Attr("port").IsNull() {
+		t.Error("expected proxy to be null and port not")
+	}
+
+	hosts, err := web.Attr("hosts").AsList()
+	if err != nil || len(hosts) != 2 {
+		t.Fatalf("hosts: unexpected result %v, %v", hosts, err)
+	}
+	if s, err := hosts[1].AsString(); err != nil || s != "b" {
+		t.Errorf("hosts[1]: want %q, got %q, %v", "b", s, err)
+	}
+
+	limits := web.Blocks("limits")
+	if len(limits) != 1 || limits[0].Attr("cpu") == nil {
+		t.Errorf("unexpected limits: %v", limits)
+	}
+
+	if attrs := blocks[1].Attributes(); len(attrs) != 0 {
+		t.Errorf("unexpected attributes of an empty block: %v", attrs)
+	}
+
+	errCases := []struct {
+		err  error
+		want string
+	}{
+		{second(web.Attr("port").AsString()), "At 6:13: port: expected a string, got number"},
+		{second(web.Attr("weight").AsInt()), "At 7:13: weight: expected an integer, got number"},
+		{second(f.Attr("name").AsBool()), "At 3:8: name: expected a bool, got string"},
+		{second(f.Attr("name").AsList()), "At 3:8: name: expected a list, got string"},
+		{second(hosts[0].AsInt()), "At 8:14: hosts[0]: expected an integer, got string"},
+		{second(web.Attr("proxy").AsString()), "At 9:13: proxy: expected a string, got null"},
+	}
+
+	for _, c := range errCases {
+		if c.err == nil || c.err.Error() != c.want {
+			t.Errorf("want error %q, got: %v", c.want, c.err)
+		}
+	}
+}
+
+func second(_ interface{}, err error) error {
+	return err
+}
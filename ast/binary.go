@@ -0,0 +1,608 @@
+package ast
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/fatih/hcl/token"
+)
+
+// binaryMagic starts every binary encoded file, the last byte is the version
+// of the encoding
+const binaryMagic = "HCL\x00\x07"
+
+const (
+	tagNil byte = iota
+	tagObjectList
+	tagLiteral
+	tagList
+	tagObject
+	tagTemplate
+	tagTemplateText
+	tagVariable
+	tagCall
+	tagBinary
+	tagUnary
+	tagConditional
+	tagFor
+	tagIf
+)
+
+// flags of the separator style of lists
+const (
+	listNewlines = 1 << iota
+	listTrailingComma
+)
+
+var errBinaryTruncated = errors.New("ast: truncated binary data")
+
+// MarshalBinary encodes f into a compact binary form, which contains the
+// positions, comments and directives of the file. It implements
+// encoding.BinaryMarshaler, so files can be encoded with encoding/gob.
+func (f *File) MarshalBinary() ([]byte, error) {
+	w := &binaryWriter{
+		files:  make(map[string]int),
+		groups: make(map[*CommentGroup]int),
+	}
+	for _, c := range f.Comments {
+		w.group(c)
+	}
+
+	// the body is written first, so the table of comment groups contains
+	// the groups not listed in f.Comments
+	if err := w.node(f.Node); err != nil {
+		return nil, err
+	}
+	w.directives(f.Directives)
+	w.pos(f.EndPos)
+	body := w.buf
+
+	w.buf = nil
+	for _, c := range w.table {
+		w.uint(len(c.List))
+		for _, comment := range c.List {
+			w.pos(comment.Start)
+			w.string(comment.Text)
+		}
+	}
+	table := w.buf
+
+	w.buf = append([]byte(nil), binaryMagic...)
+	w.uint(len(w.names))
+	for _, name := range w.names {
+		w.string(name)
+	}
+	w.uint(len(f.Comments))
+	w.uint(len(w.table))
+	return append(append(w.buf, table...), body...), nil
+}
+
+// UnmarshalBinary decodes a file encoded with MarshalBinary into f.
+func (f *File) UnmarshalBinary(data []byte) error {
+	if len(data) < len(binaryMagic) || string(data[:len(binaryMagic)-1]) != binaryMagic[:len(binaryMagic)-1] {
+		return errors.New("ast: invalid binary data")
+	}
+	if data[len(binaryMagic)-1] != binaryMagic[len(binaryMagic)-1] {
+		return fmt.Errorf("ast: unsupported binary version %d", data[len(binaryMagic)-1])
+	}
+
+	r := &binaryReader{data: data[len(binaryMagic):]}
+	r.files = make([]string, r.len())
+	for i := range r.files {
+		r.files[i] = r.string()
+	}
+
+	n := r.uint()
+	table := make([]*CommentGroup, r.len())
+	for i := range table {
+		c := &CommentGroup{List: make([]*Comment, r.len())}
+		for j := range c.List {
+			c.List[j] = &Comment{Start: r.pos(), Text: r.string()}
+		}
+		table[i] = c
+	}
+	if r.err == nil && n > len(table) {
+		r.err = errors.New("ast: invalid comment count")
+	}
+	if r.err != nil {
+		return r.err
+	}
+	r.table = table
+
+	node := r.node()
+	directives := r.directives()
+	end := r.pos()
+	if r.err == nil && len(r.data) > 0 {
+		r.err = errors.New("ast: trailing binary data")
+	}
+	if r.err != nil {
+		return r.err
+	}
+
+	f.Node = node
+	f.Comments = nil
+	if n > 0 {
+		f.Comments = table[:n]
+	}
+	f.Directives = directives
+	f.EndPos = end
+	return nil
+}
+
+type binaryWriter struct {
+	buf    []byte
+	files  map[string]int // file name -> index into names plus one
+	names  []string
+	groups map[*CommentGroup]int // comment group -> index into table
+	table  []*CommentGroup
+}
+
+func (w *binaryWriter) uint(n int) {
+	var b [binary.MaxVarintLen64]byte
+	w.buf = append(w.buf, b[:binary.PutUvarint(b[:], uint64(n))]...)
+}
+
+func (w *binaryWriter) int(n int) {
+	var b [binary.MaxVarintLen64]byte
+	w.buf = append(w.buf, b[:binary.PutVarint(b[:], int64(n))]...)
+}
+
+func (w *binaryWriter) string(s string) {
+	w.uint(len(s))
+	w.buf = append(w.buf, s...)
+}
+
+// pos writes the file name as an index into the file names, 0 if there's none
+func (w *binaryWriter) pos(p token.Pos) {
+	i, ok := w.files[p.Filename]
+	if !ok && p.Filename != "" {
+		w.names = append(w.names, p.Filename)
+		i = len(w.names)
+		w.files[p.Filename] = i
+	}
+	w.uint(i)
+	w.int(p.Offset)
+	w.int(p.Line)
+	w.int(p.Column)
+}
+
+func (w *binaryWriter) token(t token.Token) {
+	w.int(int(t.Type))
+	w.pos(t.Pos)
+	w.string(t.Text)
+}
+
+// group returns the index of c in the table plus one, or 0 for nil
+func (w *binaryWriter) group(c *CommentGroup) int {
+	if c == nil {
+		return 0
+	}
+
+	i, ok := w.groups[c]
+	if !ok {
+		i = len(w.table)
+		w.groups[c] = i
+		w.table = append(w.table, c)
+	}
+	return i + 1
+}
+
+func (w *binaryWriter) directives(ds []*CommentDirective) {
+	w.uint(len(ds))
+	for _, d := range ds {
+		w.pos(d.Pos)
+		w.string(d.Name)
+		w.string(d.Value)
+	}
+}
+
+func (w *binaryWriter) node(n Node) error {
+	switch t := n.(type) {
+	case nil:
+		w.buf = append(w.buf, tagNil)
+	case *ObjectList:
+		w.buf = append(w.buf, tagObjectList)
+		w.uint(len(t.Items))
+		for _, item := range t.Items {
+			w.uint(len(item.Keys))
+			for _, k := range item.Keys {
+				w.token(k.Token)
+			}
+			w.pos(item.Assign)
+			if err := w.node(item.Val); err != nil {
+				return err
+			}
+			w.uint(w.group(item.LeadComment))
+			w.uint(w.group(item.LineComment))
+			w.directives(item.Directives)
+		}
+	case *LiteralType:
+		w.buf = append(w.buf, tagLiteral)
+		w.token(t.Token)
+		w.uint(w.group(t.LineComment))
+		if t.Template == nil {
+			w.buf = append(w.buf, tagNil)
+			return nil
+		}
+		return w.node(t.Template)
+	case *ListType:
+		w.buf = append(w.buf, tagList)
+		w.pos(t.Lbrack)
+		w.pos(t.Rbrack)
+
+		var style int
+		if t.Newlines {
+			style |= listNewlines
+		}
+		if t.TrailingComma {
+			style |= listTrailingComma
+		}
+		w.uint(style)
+		w.uint(len(t.List))
+		for _, elem := range t.List {
+			if err := w.node(elem); err != nil {
+				return err
+			}
+		}
+	case *ObjectType:
+		w.buf = append(w.buf, tagObject)
+		w.pos(t.Lbrace)
+		w.pos(t.Rbrace)
+		w.uint(w.group(t.TrailComment))
+		if t.List == nil {
+			w.buf = append(w.buf, tagNil)
+			return nil
+		}
+		return w.node(t.List)
+	case *Template:
+		w.buf = append(w.buf, tagTemplate)
+		w.pos(t.Start)
+		return w.exprs(t.Parts)
+	case *TemplateText:
+		w.buf = append(w.buf, tagTemplateText)
+		w.pos(t.Start)
+		w.string(t.Text)
+	case *Variable:
+		w.buf = append(w.buf, tagVariable)
+		w.pos(t.NamePos)
+		w.string(t.Name)
+	case *Call:
+		w.buf = append(w.buf, tagCall)
+		w.pos(t.NamePos)
+		w.string(t.Name)
+		w.pos(t.Lparen)
+		w.pos(t.Rparen)
+		return w.exprs(t.Args)
+	case *Binary:
+		w.buf = append(w.buf, tagBinary)
+		w.pos(t.OpPos)
+		w.int(int(t.Op))
+		return w.exprs([]Expr{t.X, t.Y})
+	case *Unary:
+		w.buf = append(w.buf, tagUnary)
+		w.pos(t.OpPos)
+		w.int(int(t.Op))
+		return w.exprs([]Expr{t.X})
+	case *Conditional:
+		w.buf = append(w.buf, tagConditional)
+		w.pos(t.Question)
+		w.pos(t.Colon)
+		return w.exprs([]Expr{t.Cond, t.True, t.False})
+	case *ForDirective:
+		w.buf = append(w.buf, tagFor)
+		w.pos(t.For)
+		w.pos(t.EndFor)
+		w.string(t.Key)
+		w.string(t.Value)
+		if err := w.exprs([]Expr{t.Collection}); err != nil {
+			return err
+		}
+		return w.exprs(t.Body)
+	case *IfDirective:
+		w.buf = append(w.buf, tagIf)
+		w.pos(t.If)
+		w.pos(t.EndIf)
+		if err := w.exprs([]Expr{t.Cond}); err != nil {
+			return err
+		}
+		if err := w.exprs(t.True); err != nil {
+			return err
+		}
+		return w.exprs(t.Else)
+	default:
+		return fmt.Errorf("ast: can't encode %T", n)
+	}
+	return nil
+}
+
+// exprs writes the number of expressions plus one, or 0 for nil, followed by
+// the expressions
+func (w *binaryWriter) exprs(list []Expr) error {
+	if list == nil {
+		w.uint(0)
+		return nil
+	}
+
+	w.uint(len(list) + 1)
+	for _, e := range list {
+		var n Node
+		if e != nil {
+			n = e
+		}
+		if err := w.node(n); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type binaryReader struct {
+	data  []byte
+	err   error
+	files []string // file names
+	table []*CommentGroup
+}
+
+func (r *binaryReader) uint() int {
+	if r.err != nil {
+		return 0
+	}
+
+	n, size := binary.Uvarint(r.data)
+	if size <= 0 || n > math.MaxInt32 {
+		r.err = errBinaryTruncated
+		return 0
+	}
+	r.data = r.data[size:]
+	return int(n)
+}
+
+// len reads a length, which can't exceed the remaining data
+func (r *binaryReader) len() int {
+	n := r.uint()
+	if n > len(r.data) {
+		r.err = errBinaryTruncated
+		return 0
+	}
+	return n
+}
+
+func (r *binaryReader) int() int {
+	if r.err != nil {
+		return 0
+	}
+
+	n, size := binary.Varint(r.data)
+	if size <= 0 {
+		r.err = errBinaryTruncated
+		return 0
+	}
+	r.data = r.data[size:]
+	return int(n)
+}
+
+func (r *binaryReader) byte() byte {
+	if r.err != nil {
+		return 0
+	}
+
+	if len(r.data) == 0 {
+		r.err = errBinaryTruncated
+		return 0
+	}
+	b := r.data[0]
+	r.data = r.data[1:]
+	return b
+}
+
+func (r *binaryReader) string() string {
+	n := r.len()
+	if r.err != nil {
+		return ""
+	}
+
+	s := string(r.data[:n])
+	r.data = r.data[n:]
+	return s
+}
+
+func (r *binaryReader) pos() token.Pos {
+	var p token.Pos
+	switch i := r.uint(); {
+	case i == 0:
+	case i <= len(r.files):
+		p.Filename = r.files[i-1]
+	default:
+		if r.err == nil {
+			r.err = errors.New("ast: invalid file name index")
+		}
+	}
+
+	p.Offset = r.int()
+	p.Line = r.int()
+	p.Column = r.int()
+	return p
+}
+
+func (r *binaryReader) token() token.Token {
+	return token.Token{Type: token.Type(r.int()), Pos: r.pos(), Text: r.string()}
+}
+
+func (r *binaryReader) group() *CommentGroup {
+	i := r.uint()
+	switch {
+	case i == 0:
+		return nil
+	case i > len(r.table):
+		if r.err == nil {
+			r.err = errors.New("ast: invalid comment group index")
+		}
+		return nil
+	}
+	return r.table[i-1]
+}
+
+func (r *binaryReader) directives() []*CommentDirective {
+	n := r.len()
+	if n == 0 {
+		return nil
+	}
+
+	ds := make([]*CommentDirective, n)
+	for i := range ds {
+		ds[i] = &CommentDirective{Pos: r.pos(), Name: r.string(), Value: r.string()}
+	}
+	return ds
+}
+
+func (r *binaryReader) node() Node {
+	switch tag := r.byte(); tag {
+	case tagNil:
+		return nil
+	case tagObjectList:
+		return r.objectList()
+	case tagLiteral:
+		lit := &LiteralType{Token: r.token(), LineComment: r.group()}
+		if n := r.node(); n != nil {
+			if t, ok := n.(*Template); ok {
+				lit.Template = t
+			} else if r.err == nil {
+				r.err = errors.New("ast: invalid template")
+			}
+		}
+		return lit
+	case tagList:
+		l := &ListType{Lbrack: r.pos(), Rbrack: r.pos()}
+		style := r.uint()
+		l.Newlines = style&listNewlines != 0
+		l.TrailingComma = style&listTrailingComma != 0
+		if n := r.len(); n > 0 {
+			l.List = make([]Node, n)
+			for i := range l.List {
+				l.List[i] = r.node()
+			}
+		}
+		return l
+	case tagObject:
+		o := &ObjectType{Lbrace: r.pos(), Rbrace: r.pos(), TrailComment: r.group()}
+		switch r.byte() {
+		case tagNil:
+		case tagObjectList:
+			o.List = r.objectList()
+		default:
+			if r.err == nil {
+				r.err = errors.New("ast: invalid object")
+			}
+		}
+		return o
+	case tagTemplate:
+		return &Template{Start: r.pos(), Parts: r.exprs()}
+	case tagTemplateText:
+		return &TemplateText{Start: r.pos(), Text: r.string()}
+	case tagVariable:
+		return &Variable{NamePos: r.pos(), Name: r.string()}
+	case tagCall:
+		c := &Call{NamePos: r.pos(), Name: r.string(), Lparen: r.pos(), Rparen: r.pos()}
+		c.Args = r.exprs()
+		return c
+	case tagBinary:
+		b := &Binary{OpPos: r.pos(), Op: token.Type(r.int())}
+		if e := r.exprs(); len(e) == 2 {
+			b.X, b.Y = e[0], e[1]
+		} else {
+			r.invalid()
+		}
+		return b
+	case tagUnary:
+		u := &Unary{OpPos: r.pos(), Op: token.Type(r.int())}
+		if e := r.exprs(); len(e) == 1 {
+			u.X = e[0]
+		} else {
+			r.invalid()
+		}
+		return u
+	case tagConditional:
+		c := &Conditional{Question: r.pos(), Colon: r.pos()}
+		if e := r.exprs(); len(e) == 3 {
+			c.Cond, c.True, c.False = e[0], e[1], e[2]
+		} else {
+			r.invalid()
+		}
+		return c
+	case tagFor:
+		f := &ForDirective{For: r.pos(), EndFor: r.pos(), Key: r.string(), Value: r.string()}
+		if e := r.exprs(); len(e) == 1 {
+			f.Collection = e[0]
+		} else {
+			r.invalid()
+		}
+		f.Body = r.exprs()
+		return f
+	case tagIf:
+		i := &IfDirective{If: r.pos(), EndIf: r.pos()}
+		if e := r.exprs(); len(e) == 1 {
+			i.Cond = e[0]
+		} else {
+			r.invalid()
+		}
+		i.True = r.exprs()
+		i.Else = r.exprs()
+		return i
+	default:
+		if r.err == nil {
+			r.err = fmt.Errorf("ast: invalid node tag %d", tag)
+		}
+		return nil
+	}
+}
+
+// exprs reads expressions written by binaryWriter.exprs
+func (r *binaryReader) exprs() []Expr {
+	n := r.len()
+	if n == 0 {
+		return nil
+	}
+
+	list := make([]Expr, 0, n-1)
+	for i := 0; i < n-1 && r.err == nil; i++ {
+		n := r.node()
+		if n == nil {
+			list = append(list, nil)
+			continue
+		}
+
+		e, ok := n.(Expr)
+		if !ok {
+			r.invalid()
+			return nil
+		}
+		list = append(list, e)
+	}
+	return list
+}
+
+func (r *binaryReader) invalid() {
+	if r.err == nil {
+		r.err = errors.New("ast: invalid expression")
+	}
+}
+
+func (r *binaryReader) objectList() *ObjectList {
+	l := &ObjectList{}
+	n := r.len()
+	for i := 0; i < n && r.err == nil; i++ {
+		item := &ObjectItem{Keys: make([]*ObjectKey, r.len())}
+		for j := range item.Keys {
+			item.Keys[j] = &ObjectKey{Token: r.token()}
+		}
+		item.Assign = r.pos()
+		item.Val = r.node()
+		item.LeadComment = r.group()
+		item.LineComment = r.group()
+		item.Directives = r.directives()
+		l.Add(item)
+	}
+	return l
+}
@@ -0,0 +1,113 @@
+package ast_test
+
+import (
+	"bytes"
+	"encoding/gob"
+	"reflect"
+	"testing"
+
+	"github.com/fatih/hcl/ast"
+	"github.com/fatih/hcl/parser"
+)
+
+const binarySrc = `#hcl:since=2.0
+
+// the service
+service "web" {
+  port  = 80 # http
+  tags  = ["a", 1.5, true] /* line */
+  empty {}
+  // end of web
+}
+
+/* standalone */
+name = "app"
+url  = "http://${var.host}:%{ if var.tls }443%{ else }80%{ endif }/${join("/", list(-1, x + 1))}"
+`
+
+func TestBinary(t *testing.T) {
+	f, err := parser.Parse([]byte(binarySrc))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// file names are encoded once
+	ast.Walk(f, func(n ast.Node) (ast.Node, bool) {
+		if k, ok := n.(*ast.ObjectKey); ok {
+			k.Token.Pos.Filename = "config.hcl"
+		}
+		return n, true
+	})
+
+	data, err := f.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if n := bytes.Count(data, []byte("config.hcl")); n != 1 {
+		t.Errorf("want the file name once, got %d times", n)
+	}
+
+	got := &ast.File{}
+	if err := got.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(got, f) {
+		t.Errorf("decoded file differs from the original")
+	}
+
+	// comment groups are shared between the items and the file
+	service := got.Node.(*ast.ObjectList).Items[0]
+	if service.LeadComment != got.Comments[1] {
+		t.Errorf("lead comment isn't shared with the comments of the file")
+	}
+}
+
+func TestBinaryGob(t *testing.T) {
+	f, err := parser.Parse([]byte(binarySrc))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(f); err != nil {
+		t.Fatal(err)
+	}
+
+	got := &ast.File{}
+	if err := gob.NewDecoder(&buf).Decode(got); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(got, f) {
+		t.Errorf("decoded file differs from the original")
+	}
+}
+
+func TestBinaryInvalid(t *testing.T) {
+	f, err := parser.Parse([]byte(binarySrc))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := f.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// every truncation and corruption is an error, not a panic
+	for i := 0; i < len(data); i++ {
+		if err := new(ast.File).UnmarshalBinary(data[:i]); err == nil {
+			t.Errorf("no error for data truncated to %d bytes", i)
+		}
+
+		corrupted := append([]byte(nil), data...)
+		corrupted[i] ^= 0xff
+		new(ast.File).UnmarshalBinary(corrupted)
+	}
+
+	if _, err := (&ast.File{Node: &ast.Comment{}}).MarshalBinary(); err == nil {
+		t.Error("no error for a comment")
+	}
+}
@@ -0,0 +1,76 @@
+package ast
+
+import "sort"
+
+// CommentMap maps the nodes of a file to their comment groups, like the
+// CommentMap of go/ast, i.e. to move comments along with the nodes when
+// rewriting a file. Lead and line comments belong to their items and
+// literals, trailing comments to their objects. All other comments belong
+// to the innermost object containing them, or to the file.
+type CommentMap map[Node][]*CommentGroup
+
+// NewCommentMap returns the comment map of f.
+func NewCommentMap(f *File) CommentMap {
+	m := make(CommentMap)
+	attached := make(map[*CommentGroup]bool)
+	add := func(n Node, g *CommentGroup) {
+		if g != nil && !attached[g] {
+			m[n] = append(m[n], g)
+			attached[g] = true
+		}
+	}
+
+	var objects []*ObjectType
+	if f.Node != nil {
+		Walk(f.Node, func(n Node) (Node, bool) {
+			switch t := n.(type) {
+			case *ObjectItem:
+				add(t, t.LeadComment)
+				add(t, t.LineComment)
+			case *LiteralType:
+				add(t, t.LineComment)
+			case *ObjectType:
+				add(t, t.TrailComment)
+				objects = append(objects, t)
+				return n, t.List != nil
+			}
+			return n, true
+		})
+	}
+
+	for _, g := range f.Comments {
+		if attached[g] {
+			continue
+		}
+
+		// objects are in pre-order, so the last one containing the group
+		// is the innermost one
+		var owner Node = f
+		for _, o := range objects {
+			if o.Lbrace.Offset < g.Pos().Offset && g.Pos().Offset < o.Rbrace.Offset {
+				owner = o
+			}
+		}
+		add(owner, g)
+	}
+
+	for _, groups := range m {
+		sortGroups(groups)
+	}
+	return m
+}
+
+// Comments returns all comment groups of the map, sorted by their position.
+func (m CommentMap) Comments() []*CommentGroup {
+	var list []*CommentGroup
+	for _, groups := range m {
+		list = append(list, groups...)
+	}
+
+	sortGroups(list)
+	return list
+}
+
+func sortGroups(list []*CommentGroup) {
+	sort.Slice(list, func(i, j int) bool { return list[i].Pos().Offset < list[j].Pos().Offset })
+}
@@ -0,0 +1,79 @@
+package ast_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/fatih/hcl/ast"
+	"github.com/fatih/hcl/parser"
+)
+
+func TestCommentMap(t *testing.T) {
+	src := `// file header
+
+// lead of a
+a = 1 // line of a
+
+service "web" {
+  // lead of port
+  port = 80
+
+  // floating in service
+
+  hosts = [
+    "a", // line of "a"
+  ]
+  // trailing of service
+}
+`
+	f, err := parser.Parse([]byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := ast.NewCommentMap(f)
+	texts := func(n ast.Node) []string {
+		var res []string
+		for _, g := range m[n] {
+			for _, c := range g.List {
+				res = append(res, c.Text)
+			}
+		}
+		return res
+	}
+
+	items := f.Node.(*ast.ObjectList).Items
+	service := items[1].Val.(*ast.ObjectType)
+	hosts := service.List.Items[1].Val.(*ast.ListType)
+
+	cases := []struct {
+		node ast.Node
+		want []string
+	}{
+		{f, []string{"// file header"}},
+		{items[0], []string{"// lead of a", "// line of a"}},
+		{service.List.Items[0], []string{"// lead of port"}},
+		{service, []string{"// floating in service", "// trailing of service"}},
+		{hosts.List[0], []string{`// line of "a"`}},
+	}
+
+	for _, c := range cases {
+		if got := texts(c.node); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("%T at %s: want %q, got %q", c.node, c.node.Pos(), c.want, got)
+		}
+	}
+
+	if service.TrailComment == nil || service.TrailComment.List[0].Text != "// trailing of service" {
+		t.Errorf("unexpected trail comment: %#v", service.TrailComment)
+	}
+
+	all := m.Comments()
+	if len(all) != len(f.Comments) {
+		t.Fatalf("want %d comment groups, got %d", len(f.Comments), len(all))
+	}
+	for i, g := range all {
+		if g != f.Comments[i] {
+			t.Errorf("comment group %d: want %q, got %q", i, f.Comments[i].List[0].Text, g.List[0].Text)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package ast
+
+import (
+	"strings"
+
+	"github.com/fatih/hcl/token"
+)
+
+// CommentDirective is a machine readable comment controlling tools such as
+// linters, of the form "#hcl:name" or "#hcl:name=value", i.e.
+// #hcl:disable-rule=duplicate-key. Like Go's //go: directives, there's no
+// space after the comment marker. "//hcl:" works as well.
+type CommentDirective struct {
+	Pos   token.Pos // position of the comment
+	Name  string    // i.e. "disable-rule"
+	Value string    // i.e. "duplicate-key", empty if there's no "="
+}
+
+// ParseDirective returns the directive of the comment c, or false if c isn't
+// a directive.
+func ParseDirective(c *Comment) (*CommentDirective, bool) {
+	text := strings.TrimRight(c.Text, " \t\r\n")
+	switch {
+	case strings.HasPrefix(text, "#hcl:"):
+		text = text[len("#hcl:"):]
+	case strings.HasPrefix(text, "//hcl:"):
+		text = text[len("//hcl:"):]
+	default:
+		return nil, false
+	}
+
+	name, value := text, ""
+	if i := strings.Index(text, "="); i >= 0 {
+		name, value = text[:i], strings.TrimSpace(text[i+1:])
+	}
+
+	if name == "" || strings.ContainsAny(name, " \t") {
+		return nil, false
+	}
+	return &CommentDirective{Pos: c.Start, Name: name, Value: value}, true
+}
+
+// Directives returns the directives of the comments of the group.
+func (c *CommentGroup) Directives() []*CommentDirective {
+	var ds []*CommentDirective
+	for _, comment := range c.List {
+		if d, ok := ParseDirective(comment); ok {
+			ds = append(ds, d)
+		}
+	}
+	return ds
+}
+
+// Directive returns the last directive of the item with the given name, or
+// nil if there's none.
+func (o *ObjectItem) Directive(name string) *CommentDirective {
+	for i := len(o.Directives) - 1; i >= 0; i-- {
+		if o.Directives[i].Name == name {
+			return o.Directives[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package ast_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/fatih/hcl/ast"
+	"github.com/fatih/hcl/parser"
+)
+
+func TestParseDirective(t *testing.T) {
+	cases := []struct {
+		text     string
+		expected string // name=value, empty if not a directive
+	}{
+		{"#hcl:disable-rule=duplicate-key\n", "disable-rule=duplicate-key"},
+		{"//hcl:since=2.0", "since=2.0"},
+		{"#hcl:deprecated", "deprecated="},
+		{"#hcl:since = 2.0", ""},
+		{"# hcl:since=2.0", ""},
+		{"#hcl:", ""},
+		{"/* hcl:since=2.0 */", ""},
+		{"# a comment", ""},
+	}
+
+	for _, c := range cases {
+		var got string
+		if d, ok := ast.ParseDirective(&ast.Comment{Text: c.text}); ok {
+			got = d.Name + "=" + d.Value
+		}
+
+		if got != c.expected {
+			t.Errorf("%q: want: %q, got: %q", c.text, c.expected, got)
+		}
+	}
+}
+
+func TestDirectives(t *testing.T) {
+	src := `#hcl:since=2.0
+
+# the service
+#hcl:disable-rule=duplicate-key
+service "web" {
+  port = 80 #hcl:deprecated
+  host = "a"
+}
+`
+
+	f, err := parser.Parse([]byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	str := func(ds []*ast.CommentDirective) string {
+		var s string
+		for _, d := range ds {
+			s += fmt.Sprintf("%s %s=%s;", d.Pos, d.Name, d.Value)
+		}
+		return s
+	}
+
+	if got := str(f.Directives); got != "1:1 since=2.0;" {
+		t.Errorf("file: got: %s", got)
+	}
+
+	service := f.Node.(*ast.ObjectList).Items[0]
+	if got := str(service.Directives); got != "4:1 disable-rule=duplicate-key;" {
+		t.Errorf("service: got: %s", got)
+	}
+
+	items := service.Val.(*ast.ObjectType).List.Items
+	if d := items[0].Directive("deprecated"); d == nil || d.Pos.String() != "6:13" {
+		t.Errorf("port: want deprecated at 6:13, got: %v", d)
+	}
+
+	if d := items[1].Directive("deprecated"); d != nil {
+		t.Errorf("host: unexpected directive: %v", d)
+	}
+}
@@ -0,0 +1,239 @@
+package ast
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/fatih/hcl/token"
+)
+
+// Dump returns a human-readable representation of the tree of n, i.e. for
+// debugging the parser and for golden tests. Every node is a line with its
+// kind, its source range or position and its token or name, indented by two
+// spaces per level below its parent. Comments are listed below the nodes
+// they're attached to; the comments of a file attached to no node follow
+// the file. Positions are line:column, without file names, so the output only
+// depends on the source.
+//
+//	File 1:1-2:1
+//	  ObjectList 1:1-1:10
+//	    ObjectItem 1:1-1:10 assign=1:6
+//	      ObjectKey 1:1-1:5 IDENT "port"
+//	      LiteralType 1:8-1:10 NUMBER "80"
+func Dump(n Node) string {
+	d := &dumper{attached: make(map[*CommentGroup]bool)}
+	if f, ok := n.(*File); ok {
+		Walk(f, func(n Node) (Node, bool) {
+			for _, g := range attachedComments(n) {
+				d.attached[g] = true
+			}
+			return n, true
+		})
+	}
+
+	d.node(n)
+	return d.buf.String()
+}
+
+type dumper struct {
+	buf      bytes.Buffer
+	depth    int
+	attached map[*CommentGroup]bool // comments of a file attached to nodes
+}
+
+// attachedComments returns the comment groups attached to n
+func attachedComments(n Node) []*CommentGroup {
+	var groups []*CommentGroup
+	switch t := n.(type) {
+	case *ObjectItem:
+		groups = append(groups, t.LeadComment, t.LineComment)
+	case *LiteralType:
+		groups = append(groups, t.LineComment)
+	case *ObjectType:
+		groups = append(groups, t.TrailComment)
+	}
+	return groups
+}
+
+// line writes a line of the node kind at the current depth, followed by its
+// source range and the given details
+func (d *dumper) line(kind string, n Node, details ...string) {
+	d.buf.WriteString(strings.Repeat("  ", d.depth))
+	d.buf.WriteString(kind)
+	if n != nil {
+		d.buf.WriteString(" " + dumpRange(n))
+	}
+	for _, s := range details {
+		if s != "" {
+			d.buf.WriteString(" " + s)
+		}
+	}
+	d.buf.WriteByte('\n')
+}
+
+// children dumps the nodes one level deeper, below a label if it isn't empty
+func (d *dumper) children(label string, nodes ...Node) {
+	d.depth++
+	if label != "" {
+		d.line(label, nil)
+		d.depth++
+	}
+
+	for _, n := range nodes {
+		d.node(n)
+	}
+
+	if label != "" {
+		d.depth--
+	}
+	d.depth--
+}
+
+func (d *dumper) comments(label string, g *CommentGroup) {
+	if g != nil {
+		d.children(label, g)
+	}
+}
+
+func (d *dumper) exprs(label string, list []Expr) {
+	nodes := make([]Node, 0, len(list))
+	for _, e := range list {
+		nodes = append(nodes, e)
+	}
+	d.children(label, nodes...)
+}
+
+// directives dumps the comment directives one level deeper
+func (d *dumper) directives(list []*CommentDirective) {
+	d.depth++
+	for _, dir := range list {
+		value := ""
+		if dir.Value != "" {
+			value = fmt.Sprintf("%q", dir.Value)
+		}
+		d.line("CommentDirective", nil, dumpPos(dir.Pos), dir.Name, value)
+	}
+	d.depth--
+}
+
+func (d *dumper) node(n Node) {
+	switch t := n.(type) {
+	case nil:
+		d.line("nil", nil)
+	case *File:
+		d.line("File", t)
+		d.children("", t.Node)
+		d.directives(t.Directives)
+
+		var standalone []Node
+		for _, g := range t.Comments {
+			if !d.attached[g] {
+				standalone = append(standalone, g)
+			}
+		}
+		if len(standalone) > 0 {
+			d.children("Comments", standalone...)
+		}
+	case *ObjectList:
+		d.line("ObjectList", t)
+		nodes := make([]Node, 0, len(t.Items))
+		for _, item := range t.Items {
+			nodes = append(nodes, item)
+		}
+		d.children("", nodes...)
+	case *ObjectItem:
+		var assign string
+		if t.Assign.IsValid() {
+			assign = "assign=" + dumpPos(t.Assign)
+		}
+		d.line("ObjectItem", t, assign)
+		d.comments("LeadComment", t.LeadComment)
+		for _, k := range t.Keys {
+			d.children("", k)
+		}
+		d.children("", t.Val)
+		d.comments("LineComment", t.LineComment)
+		d.directives(t.Directives)
+	case *ObjectKey:
+		d.line("ObjectKey", t, t.Token.Type.String(), fmt.Sprintf("%q", t.Token.Text))
+	case *LiteralType:
+		d.line("LiteralType", t, t.Token.Type.String(), fmt.Sprintf("%q", t.Token.Text))
+		if t.Template != nil {
+			d.children("", t.Template)
+		}
+		d.comments("LineComment", t.LineComment)
+	case *ListType:
+		var flags []string
+		if t.Newlines {
+			flags = append(flags, "newlines")
+		}
+		if t.TrailingComma {
+			flags = append(flags, "trailing-comma")
+		}
+		d.line("ListType", t, flags...)
+		d.children("", t.List...)
+	case *ObjectType:
+		d.line("ObjectType", t)
+		if t.List != nil {
+			d.children("", t.List)
+		}
+		d.comments("TrailComment", t.TrailComment)
+	case *CommentGroup:
+		d.line("CommentGroup", t)
+		nodes := make([]Node, 0, len(t.List))
+		for _, c := range t.List {
+			nodes = append(nodes, c)
+		}
+		d.children("", nodes...)
+	case *Comment:
+		d.line("Comment", t, fmt.Sprintf("%q", t.Text))
+	case *Template:
+		d.line("Template", t)
+		d.exprs("", t.Parts)
+	case *TemplateText:
+		d.line("TemplateText", t, fmt.Sprintf("%q", t.Text))
+	case *Variable:
+		d.line("Variable", t, t.Name)
+	case *Call:
+		d.line("Call", t, t.Name)
+		d.exprs("", t.Args)
+	case *Binary:
+		d.line("Binary", t, t.Op.String())
+		d.exprs("", []Expr{t.X, t.Y})
+	case *Unary:
+		d.line("Unary", t, t.Op.String())
+		d.exprs("", []Expr{t.X})
+	case *Conditional:
+		d.line("Conditional", t)
+		d.exprs("", []Expr{t.Cond, t.True, t.False})
+	case *ForDirective:
+		d.line("ForDirective", t, t.Key, t.Value)
+		d.exprs("", []Expr{t.Collection})
+		d.exprs("Body", t.Body)
+	case *IfDirective:
+		d.line("IfDirective", t)
+		d.exprs("", []Expr{t.Cond})
+		d.exprs("True", t.True)
+		if len(t.Else) > 0 {
+			d.exprs("Else", t.Else)
+		}
+	default:
+		d.line(fmt.Sprintf("%T", n), n)
+	}
+}
+
+// dumpRange returns the source range of n, or its position if it has no end
+func dumpRange(n Node) string {
+	r, ok := n.(Ranger)
+	if pos := n.Pos(); !ok || !pos.IsValid() {
+		return dumpPos(n.Pos())
+	}
+	return dumpPos(n.Pos()) + "-" + dumpPos(r.End())
+}
+
+// dumpPos returns pos as line:column without the file name
+func dumpPos(pos token.Pos) string {
+	pos.Filename = ""
+	return pos.String()
+}
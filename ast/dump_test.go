@@ -0,0 +1,75 @@
+package ast_test
+
+import (
+	"testing"
+
+	"github.com/fatih/hcl/ast"
+	"github.com/fatih/hcl/parser"
+)
+
+func TestDump(t *testing.T) {
+	src := `# the name
+name = "${a ? upper(b) : -1}" # line
+
+items = "%{ for k, v in list }${v}%{ endfor }"
+
+service "web" {
+  ports = [80, 443]
+}
+
+# end
+`
+	f, err := parser.Parse([]byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `File 2:1-11:1
+  ObjectList 2:1-8:2
+    ObjectItem 2:1-2:30 assign=2:6
+      LeadComment
+        CommentGroup 1:1-1:11
+          Comment 1:1-1:11 "# the name"
+      ObjectKey 2:1-2:5 IDENT "name"
+      LiteralType 2:8-2:30 STRING "\"${a ? upper(b) : -1}\""
+        Template 2:9
+          Conditional 2:11
+            Variable 2:11 a
+            Call 2:15 upper
+              Variable 2:21 b
+            Unary 2:26 SUB
+              LiteralType 2:27-2:28 NUMBER "1"
+      LineComment
+        CommentGroup 2:31-2:37
+          Comment 2:31-2:37 "# line"
+    ObjectItem 4:1-4:47 assign=4:7
+      ObjectKey 4:1-4:6 IDENT "items"
+      LiteralType 4:9-4:47 STRING "\"%{ for k, v in list }${v}%{ endfor }\""
+        Template 4:10
+          ForDirective 4:10 k v
+            Variable 4:25 list
+            Body
+              Variable 4:33 v
+    ObjectItem 6:1-8:2
+      ObjectKey 6:1-6:8 IDENT "service"
+      ObjectKey 6:9-6:14 STRING "\"web\""
+      ObjectType 6:15-8:2
+        ObjectList 7:3-7:20
+          ObjectItem 7:3-7:20 assign=7:9
+            ObjectKey 7:3-7:8 IDENT "ports"
+            ListType 7:11-7:20
+              LiteralType 7:12-7:14 NUMBER "80"
+              LiteralType 7:16-7:19 NUMBER "443"
+  Comments
+    CommentGroup 10:1-10:6
+      Comment 10:1-10:6 "# end"
+`
+	if got := ast.Dump(f); got != want {
+		t.Errorf("want:\n%s\ngot:\n%s", want, got)
+	}
+
+	// nodes without positions
+	if got, want := ast.Dump(&ast.ObjectKey{}), "ObjectKey - ILLEGAL \"\"\n"; got != want {
+		t.Errorf("want %q, got %q", want, got)
+	}
+}
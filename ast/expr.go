@@ -0,0 +1,144 @@
+package ast
+
+import "github.com/fatih/hcl/token"
+
+// Expr is an element of a template, such as the text or the expression of an
+// interpolation.
+type Expr interface {
+	Node
+	expr()
+}
+
+func (Template) node()     {}
+func (TemplateText) node() {}
+func (Variable) node()     {}
+func (Call) node()         {}
+func (Binary) node()       {}
+func (Unary) node()        {}
+func (Conditional) node()  {}
+func (ForDirective) node() {}
+func (IfDirective) node()  {}
+
+func (Template) expr()     {}
+func (TemplateText) expr() {}
+func (Variable) expr()     {}
+func (Call) expr()         {}
+func (Binary) expr()       {}
+func (Unary) expr()        {}
+func (Conditional) expr()  {}
+func (ForDirective) expr() {}
+func (IfDirective) expr()  {}
+func (LiteralType) expr()  {}
+
+// Template represents the content of a string literal which might contain
+// interpolations, such as "web-${var.index}".
+type Template struct {
+	Start token.Pos // position of the first character after the quote
+	Parts []Expr    // *TemplateText and interpolated expressions in lexical order
+}
+
+func (t *Template) Pos() token.Pos {
+	return t.Start
+}
+
+// TemplateText represents the plain text between the interpolations of a
+// template. Escape sequences are already resolved.
+type TemplateText struct {
+	Start token.Pos // position of the first character
+	Text  string
+}
+
+func (t *TemplateText) Pos() token.Pos {
+	return t.Start
+}
+
+// Variable represents a reference to a variable inside of an interpolation,
+// such as var.foo or aws_instance.web.*.id
+type Variable struct {
+	NamePos token.Pos // position of the name
+	Name    string
+}
+
+func (v *Variable) Pos() token.Pos {
+	return v.NamePos
+}
+
+// Call represents a function call inside of an interpolation, such as
+// upper(var.name)
+type Call struct {
+	NamePos token.Pos // position of the function name
+	Name    string
+	Lparen  token.Pos // position of "("
+	Args    []Expr    // arguments in lexical order
+	Rparen  token.Pos // position of ")"
+}
+
+func (c *Call) Pos() token.Pos {
+	return c.NamePos
+}
+
+// Binary represents a binary expression, such as var.port + 1
+type Binary struct {
+	X     Expr       // left operand
+	OpPos token.Pos  // position of Op
+	Op    token.Type // operator
+	Y     Expr       // right operand
+}
+
+func (b *Binary) Pos() token.Pos {
+	return b.X.Pos()
+}
+
+// Unary represents a unary expression, such as -var.offset or !var.enabled
+type Unary struct {
+	OpPos token.Pos  // position of Op
+	Op    token.Type // operator
+	X     Expr       // operand
+}
+
+func (u *Unary) Pos() token.Pos {
+	return u.OpPos
+}
+
+// Conditional represents a conditional expression, such as
+// var.enabled ? 1 : 0
+type Conditional struct {
+	Cond     Expr      // condition
+	Question token.Pos // position of "?"
+	True     Expr      // value if the condition is true
+	Colon    token.Pos // position of ":"
+	False    Expr      // value if the condition is false
+}
+
+func (c *Conditional) Pos() token.Pos {
+	return c.Cond.Pos()
+}
+
+// ForDirective represents a for directive of a template, such as
+// %{ for name in var.names }${name} %{ endfor }
+type ForDirective struct {
+	For        token.Pos // position of "%{" of the for directive
+	Key        string    // name of the key variable, might be empty
+	Value      string    // name of the value variable
+	Collection Expr      // list or map to iterate over
+	Body       []Expr    // template parts repeated for every element
+	EndFor     token.Pos // position of "%{" of the endfor directive
+}
+
+func (f *ForDirective) Pos() token.Pos {
+	return f.For
+}
+
+// IfDirective represents an if directive of a template, such as
+// %{ if var.enabled }on%{ else }off%{ endif }
+type IfDirective struct {
+	If    token.Pos // position of "%{" of the if directive
+	Cond  Expr      // condition
+	True  []Expr    // template parts used if the condition is true
+	Else  []Expr    // template parts used otherwise, might be empty
+	EndIf token.Pos // position of "%{" of the endif directive
+}
+
+func (i *IfDirective) Pos() token.Pos {
+	return i.If
+}
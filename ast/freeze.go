@@ -0,0 +1,293 @@
+package ast
+
+import "fmt"
+
+// Frozen is an immutable syntax tree, which can be shared by any number of
+// goroutines without locking. It's never modified: edits return a new tree,
+// which shares all nodes not on the path to the edited node with the old one.
+type Frozen struct {
+	file *File
+}
+
+// Freeze returns an immutable copy of f. f itself is not modified and can
+// still be changed without affecting the result.
+func Freeze(f *File) *Frozen {
+	return &Frozen{file: Copy(f).(*File)}
+}
+
+// File returns the syntax tree. It must not be modified, use Copy for a tree
+// to modify or Replace to edit the frozen tree.
+func (fr *Frozen) File() *File {
+	return fr.file
+}
+
+// Copy returns a deep copy of the syntax tree, which can be modified.
+func (fr *Frozen) Copy() *File {
+	return Copy(fr.file).(*File)
+}
+
+// Replace returns a frozen tree with the node old, which must be part of the
+// tree, replaced by a copy of the node new. Only the ancestors of old are
+// copied, all other nodes are shared with fr, which is unchanged.
+func (fr *Frozen) Replace(old, new Node) (*Frozen, error) {
+	if old == nil || new == nil {
+		return nil, fmt.Errorf("can't replace %T with %T", old, new)
+	}
+
+	n, ok := replace(fr.file, old, Copy(new))
+	if !ok {
+		return nil, fmt.Errorf("%T at %s is not part of the tree", old, old.Pos())
+	}
+	return &Frozen{file: n.(*File)}, nil
+}
+
+// replace returns n with old replaced by new, copying the nodes on the path
+// to old only. It reports false if old isn't part of n.
+func replace(n, old, new Node) (Node, bool) {
+	if n == old {
+		return new, true
+	}
+
+	switch t := n.(type) {
+	case *File:
+		if m, ok := replace(t.Node, old, new); ok {
+			f := *t
+			f.Node = m
+			return &f, true
+		}
+	case *ObjectList:
+		for i, item := range t.Items {
+			m, ok := replace(item, old, new)
+			if !ok {
+				continue
+			}
+
+			item, ok := m.(*ObjectItem)
+			if !ok {
+				return nil, false
+			}
+
+			l := &ObjectList{Items: append([]*ObjectItem(nil), t.Items...)}
+			l.Items[i] = item
+			return l, true
+		}
+	case *ObjectItem:
+		for i, k := range t.Keys {
+			m, ok := replace(k, old, new)
+			if !ok {
+				continue
+			}
+
+			key, ok := m.(*ObjectKey)
+			if !ok {
+				return nil, false
+			}
+
+			item := *t
+			item.Keys = append([]*ObjectKey(nil), t.Keys...)
+			item.Keys[i] = key
+			return &item, true
+		}
+
+		if m, ok := replace(t.Val, old, new); ok {
+			item := *t
+			item.Val = m
+			return &item, true
+		}
+	case *ListType:
+		for i, elem := range t.List {
+			if m, ok := replace(elem, old, new); ok {
+				l := *t
+				l.List = append([]Node(nil), t.List...)
+				l.List[i] = m
+				return &l, true
+			}
+		}
+	case *ObjectType:
+		if t.List == nil {
+			break
+		}
+
+		if m, ok := replace(t.List, old, new); ok {
+			list, ok := m.(*ObjectList)
+			if !ok {
+				return nil, false
+			}
+
+			o := *t
+			o.List = list
+			return &o, true
+		}
+	}
+	return nil, false
+}
+
+// Copy returns a deep copy of n. Comment groups shared between nodes, such as
+// the lead comments of items and the comments of a file, are shared by the
+// copies as well.
+func Copy(n Node) Node {
+	c := &copier{groups: make(map[*CommentGroup]*CommentGroup)}
+	return c.node(n)
+}
+
+type copier struct {
+	groups map[*CommentGroup]*CommentGroup
+}
+
+func (c *copier) group(g *CommentGroup) *CommentGroup {
+	if g == nil {
+		return nil
+	}
+
+	if cp, ok := c.groups[g]; ok {
+		return cp
+	}
+
+	cp := &CommentGroup{List: make([]*Comment, len(g.List))}
+	for i, comment := range g.List {
+		cc := *comment
+		cp.List[i] = &cc
+	}
+	c.groups[g] = cp
+	return cp
+}
+
+func (c *copier) directives(ds []*CommentDirective) []*CommentDirective {
+	if ds == nil {
+		return nil
+	}
+
+	cp := make([]*CommentDirective, len(ds))
+	for i, d := range ds {
+		cd := *d
+		cp[i] = &cd
+	}
+	return cp
+}
+
+func (c *copier) exprs(list []Expr) []Expr {
+	if list == nil {
+		return nil
+	}
+
+	cp := make([]Expr, len(list))
+	for i, e := range list {
+		cp[i] = c.expr(e)
+	}
+	return cp
+}
+
+func (c *copier) expr(e Expr) Expr {
+	if e == nil {
+		return nil
+	}
+	return c.node(e).(Expr)
+}
+
+func (c *copier) node(n Node) Node {
+	switch t := n.(type) {
+	case nil:
+		return nil
+	case *File:
+		f := &File{Directives: c.directives(t.Directives), EndPos: t.EndPos}
+		if t.Comments != nil {
+			f.Comments = make([]*CommentGroup, len(t.Comments))
+			for i, g := range t.Comments {
+				f.Comments[i] = c.group(g)
+			}
+		}
+		f.Node = c.node(t.Node)
+		return f
+	case *ObjectList:
+		l := &ObjectList{}
+		if t.Items != nil {
+			l.Items = make([]*ObjectItem, len(t.Items))
+			for i, item := range t.Items {
+				l.Items[i] = c.node(item).(*ObjectItem)
+			}
+		}
+		return l
+	case *ObjectItem:
+		item := *t
+		if t.Keys != nil {
+			item.Keys = make([]*ObjectKey, len(t.Keys))
+			for i, k := range t.Keys {
+				key := *k
+				item.Keys[i] = &key
+			}
+		}
+		item.Val = c.node(t.Val)
+		item.LeadComment = c.group(t.LeadComment)
+		item.LineComment = c.group(t.LineComment)
+		item.Directives = c.directives(t.Directives)
+		return &item
+	case *ObjectKey:
+		k := *t
+		return &k
+	case *LiteralType:
+		lit := *t
+		lit.LineComment = c.group(t.LineComment)
+		if t.Template != nil {
+			lit.Template = c.node(t.Template).(*Template)
+		}
+		return &lit
+	case *ListType:
+		l := *t
+		if t.List != nil {
+			l.List = make([]Node, len(t.List))
+			for i, elem := range t.List {
+				l.List[i] = c.node(elem)
+			}
+		}
+		return &l
+	case *ObjectType:
+		o := *t
+		if t.List != nil {
+			o.List = c.node(t.List).(*ObjectList)
+		}
+		o.TrailComment = c.group(t.TrailComment)
+		return &o
+	case *Comment:
+		cc := *t
+		return &cc
+	case *CommentGroup:
+		return c.group(t)
+	case *Template:
+		tmpl := *t
+		tmpl.Parts = c.exprs(t.Parts)
+		return &tmpl
+	case *TemplateText:
+		text := *t
+		return &text
+	case *Variable:
+		v := *t
+		return &v
+	case *Call:
+		call := *t
+		call.Args = c.exprs(t.Args)
+		return &call
+	case *Binary:
+		b := *t
+		b.X, b.Y = c.expr(t.X), c.expr(t.Y)
+		return &b
+	case *Unary:
+		u := *t
+		u.X = c.expr(t.X)
+		return &u
+	case *Conditional:
+		cond := *t
+		cond.Cond, cond.True, cond.False = c.expr(t.Cond), c.expr(t.True), c.expr(t.False)
+		return &cond
+	case *ForDirective:
+		f := *t
+		f.Collection = c.expr(t.Collection)
+		f.Body = c.exprs(t.Body)
+		return &f
+	case *IfDirective:
+		i := *t
+		i.Cond = c.expr(t.Cond)
+		i.True, i.Else = c.exprs(t.True), c.exprs(t.Else)
+		return &i
+	}
+	panic(fmt.Sprintf("ast: can't copy %T", n))
+}
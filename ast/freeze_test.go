@@ -0,0 +1,108 @@
+package ast_test
+
+import (
+	"bytes"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/fatih/hcl/ast"
+	"github.com/fatih/hcl/parser"
+	"github.com/fatih/hcl/printer"
+	"github.com/fatih/hcl/token"
+)
+
+func TestCopy(t *testing.T) {
+	f, err := parser.Parse([]byte(binarySrc))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cp := ast.Copy(f).(*ast.File)
+	if !reflect.DeepEqual(cp, f) {
+		t.Fatal("copy differs from the original")
+	}
+
+	service := cp.Node.(*ast.ObjectList).Items[0]
+	if service == f.Node.(*ast.ObjectList).Items[0] {
+		t.Error("items are shared")
+	}
+
+	if service.LeadComment != cp.Comments[1] {
+		t.Error("lead comment isn't shared with the comments of the copy")
+	}
+}
+
+func TestFreeze(t *testing.T) {
+	f, err := parser.Parse([]byte("a {\n  b = 1\n}\n\nc {\n  d = 2\n}"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fr := ast.Freeze(f)
+
+	// changing the original doesn't change the frozen tree
+	f.Node.(*ast.ObjectList).Items = nil
+	if len(fr.File().Node.(*ast.ObjectList).Items) != 2 {
+		t.Fatal("frozen tree changed with the original")
+	}
+
+	items := fr.File().Node.(*ast.ObjectList).Items
+	b := items[0].Val.(*ast.ObjectType).List.Items[0]
+	edited, err := fr.Replace(b.Val, &ast.LiteralType{Token: token.Token{Type: token.NUMBER, Text: "3"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	print := func(f *ast.File) string {
+		var buf bytes.Buffer
+		if err := printer.Fprint(&buf, f); err != nil {
+			t.Fatal(err)
+		}
+		return buf.String()
+	}
+
+	if got := print(fr.File()); got != "a = {\n  b = 1\n}\n\nc = {\n  d = 2\n}" {
+		t.Errorf("frozen tree was modified:\n%s", got)
+	}
+
+	if got := print(edited.File()); got != "a = {\n  b = 3\n}\n\nc = {\n  d = 2\n}" {
+		t.Errorf("unexpected edited tree:\n%s", got)
+	}
+
+	// the untouched block is shared
+	if edited.File().Node.(*ast.ObjectList).Items[1] != items[1] {
+		t.Error("unchanged item was copied")
+	}
+
+	if _, err := edited.Replace(b, b); err == nil {
+		t.Error("no error for a node of another tree")
+	}
+}
+
+func TestFreezeConcurrent(t *testing.T) {
+	f, err := parser.Parse([]byte(binarySrc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fr := ast.Freeze(f)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				var buf bytes.Buffer
+				printer.Fprint(&buf, fr.File())
+
+				service := fr.File().Node.(*ast.ObjectList).Items[0]
+				if _, err := fr.Replace(service, ast.Copy(service)); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,55 @@
+package ast
+
+import (
+	"github.com/fatih/hcl/token"
+)
+
+// OutlineEntry summarizes a block or an attribute of a file, without its
+// value.
+type OutlineEntry struct {
+	Name     string    // block type or attribute name
+	Labels   []string  // labels of a block, unquoted
+	Block    bool      // whether the item has an object value
+	Pos      token.Pos // position of the first key
+	Children []*OutlineEntry
+}
+
+// Outline returns the hierarchical summary of the items of f: the block
+// types, labels and attribute names with their positions. Items with object
+// values, such as `service "web" {}` and `limits = {}`, are blocks whose
+// items are their children.
+func (f *File) Outline() []*OutlineEntry {
+	list, ok := f.Node.(*ObjectList)
+	if !ok {
+		return nil
+	}
+	return outline(list)
+}
+
+func outline(list *ObjectList) []*OutlineEntry {
+	entries := make([]*OutlineEntry, 0, len(list.Items))
+	for _, item := range list.Items {
+		if len(item.Keys) == 0 {
+			continue
+		}
+
+		entry := &OutlineEntry{
+			Name: item.Keys[0].Token.Value(),
+			Pos:  item.Pos(),
+		}
+
+		for _, k := range item.Keys[1:] {
+			entry.Labels = append(entry.Labels, k.Token.Value())
+		}
+
+		if obj, ok := item.Val.(*ObjectType); ok {
+			entry.Block = true
+			if obj.List != nil {
+				entry.Children = outline(obj.List)
+			}
+		}
+
+		entries = append(entries, entry)
+	}
+	return entries
+}
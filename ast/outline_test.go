@@ -0,0 +1,53 @@
+package ast_test
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/fatih/hcl/ast"
+	"github.com/fatih/hcl/parser"
+)
+
+func TestOutline(t *testing.T) {
+	src := `name = "app"
+
+service "http" "web" {
+  port = 80
+
+  limits = {
+    cpu = 2
+  }
+}
+
+"quoted key" = [1, 2]
+`
+	f, err := parser.Parse([]byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	var print func(entries []*ast.OutlineEntry, indent string)
+	print = func(entries []*ast.OutlineEntry, indent string) {
+		for _, e := range entries {
+			got = append(got, fmt.Sprintf("%s%s %q block=%t %s", indent, e.Name, e.Labels, e.Block, e.Pos))
+			print(e.Children, indent+"  ")
+		}
+	}
+	print(f.Outline(), "")
+
+	expected := []string{
+		`name [] block=false 1:1`,
+		`service ["http" "web"] block=true 3:1`,
+		`  port [] block=false 4:3`,
+		`  limits [] block=true 6:3`,
+		`    cpu [] block=false 7:5`,
+		`quoted key [] block=false 11:1`,
+	}
+
+	if !reflect.DeepEqual(expected, got) {
+		t.Errorf("\nwant:\n%s\ngot:\n%s", strings.Join(expected, "\n"), strings.Join(got, "\n"))
+	}
+}
@@ -0,0 +1,63 @@
+package ast
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/fatih/hcl/token"
+)
+
+// PathKey returns key as an element of a path, such as the paths of the
+// decoder errors and of package merge. Keys containing dots or quotes and
+// empty keys are quoted, i.e. `labels."app.kubernetes.io/name"`.
+func PathKey(key string) string {
+	if key == "" || strings.ContainsAny(key, `."`) {
+		return strconv.Quote(key)
+	}
+	return key
+}
+
+// JoinPath returns the path of the unquoted keys, joined by dots and quoted
+// with PathKey.
+func JoinPath(keys []string) string {
+	elems := make([]string, len(keys))
+	for i, k := range keys {
+		elems[i] = PathKey(k)
+	}
+	return strings.Join(elems, ".")
+}
+
+// SplitPath returns the unquoted keys of path, the inverse of JoinPath. Keys
+// are separated by dots outside of quotes. An empty path has no keys.
+func SplitPath(path string) []string {
+	if path == "" {
+		return nil
+	}
+
+	var keys []string
+	var elem []byte
+	quoted := false
+	for i := 0; i < len(path); i++ {
+		ch := path[i]
+		switch {
+		case quoted && ch == '\\' && i+1 < len(path):
+			elem = append(elem, ch)
+			i++
+			ch = path[i]
+		case ch == '"':
+			quoted = !quoted
+		case ch == '.' && !quoted:
+			keys = append(keys, pathKey(elem))
+			elem = elem[:0]
+			continue
+		}
+		elem = append(elem, ch)
+	}
+	return append(keys, pathKey(elem))
+}
+
+// pathKey returns the key of the path element elem, which is unquoted if it
+// is quoted
+func pathKey(elem []byte) string {
+	return token.Token{Type: token.STRING, Text: string(elem)}.Value()
+}
@@ -0,0 +1,30 @@
+package ast
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPath(t *testing.T) {
+	cases := []struct {
+		keys []string
+		path string
+	}{
+		{nil, ""},
+		{[]string{"service", "web", "port"}, "service.web.port"},
+		{[]string{"labels", "app.kubernetes.io/name"}, `labels."app.kubernetes.io/name"`},
+		{[]string{"a/b:c", "d e"}, "a/b:c.d e"},
+		{[]string{`say "hi"`, ""}, `"say \"hi\"".""`},
+		{[]string{"a.b.c"}, `"a.b.c"`},
+	}
+
+	for _, c := range cases {
+		if path := JoinPath(c.keys); path != c.path {
+			t.Errorf("JoinPath(%q): want %q, got %q", c.keys, c.path, path)
+		}
+
+		if keys := SplitPath(c.path); !reflect.DeepEqual(keys, c.keys) {
+			t.Errorf("SplitPath(%q): want %q, got %q", c.path, c.keys, keys)
+		}
+	}
+}
@@ -2,41 +2,262 @@ package ast
 
 import "fmt"
 
+// WalkFunc is the function called by Walk for every node. It returns the node
+// replacing n, n itself to keep it, and whether Walk continues with the
+// children of the returned node.
+type WalkFunc func(n Node) (Node, bool)
+
 // Walk traverses an AST in depth-first order: It starts by calling fn(node);
-// node must not be nil.  If f returns true, Walk invokes f recursively for
-// each of the non-nil children of node, followed by a call of f(nil).
-func Walk(node Node, fn func(Node) bool) {
-	if !fn(node) {
-		return
+// node must not be nil.  If fn returns true, Walk invokes fn recursively for
+// each of the non-nil children of the returned node, followed by a call of
+// fn(nil), whose result is ignored.
+//
+// Walk returns the rewritten tree, the node returned by fn(node) with its
+// rewritten children. The tree of node isn't modified: the nodes whose
+// children are replaced are copied, all other nodes are shared by both trees.
+// Returning nil removes an item of an object list, an element of a list or
+// a part of a template, other nodes are kept. Nodes must be replaced by nodes
+// of the same kind, i.e. an *ObjectItem by an *ObjectItem and an expression
+// of a template by an Expr, otherwise Walk panics.
+func Walk(node Node, fn WalkFunc) Node {
+	rewritten, ok := fn(node)
+	if !ok || rewritten == nil {
+		return rewritten
 	}
 
-	switch n := node.(type) {
+	switch n := rewritten.(type) {
 	case *File:
-		Walk(n.Node, fn)
+		if m := walkNode(n.Node, fn); m != n.Node {
+			f := *n
+			f.Node = m
+			rewritten = &f
+		}
 	case *ObjectList:
-		for _, item := range n.Items {
-			Walk(item, fn)
+		if items, changed := walkItems(n.Items, fn); changed {
+			rewritten = &ObjectList{Items: items}
 		}
 	case *ObjectKey:
 		// nothing to do
 	case *ObjectItem:
-		for _, k := range n.Keys {
-			Walk(k, fn)
+		keys, changed := walkKeys(n.Keys, fn)
+		if val := walkNode(n.Val, fn); changed || val != n.Val {
+			item := *n
+			item.Keys = keys
+			item.Val = val
+			rewritten = &item
 		}
-		Walk(n.Val, fn)
 	case *LiteralType:
-		// nothing to do
+		if n.Template == nil {
+			break
+		}
+
+		if tmpl := walkNode(n.Template, fn); tmpl != Node(n.Template) {
+			lit := *n
+			lit.Template = as(n.Template, tmpl).(*Template)
+			rewritten = &lit
+		}
 	case *ListType:
-		for _, l := range n.List {
-			Walk(l, fn)
+		if list, changed := walkNodes(n.List, fn); changed {
+			l := *n
+			l.List = list
+			rewritten = &l
 		}
 	case *ObjectType:
-		for _, l := range n.List.Items {
-			Walk(l, fn)
+		if n.List == nil {
+			break
+		}
+
+		if list := walkNode(n.List, fn); list != Node(n.List) {
+			o := *n
+			o.List = as(n.List, list).(*ObjectList)
+			rewritten = &o
+		}
+	case *Comment, *CommentGroup:
+		// nothing to do
+	case *Template:
+		if parts, changed := walkExprs(n.Parts, fn); changed {
+			tmpl := *n
+			tmpl.Parts = parts
+			rewritten = &tmpl
+		}
+	case *TemplateText, *Variable:
+		// nothing to do
+	case *Call:
+		if args, changed := walkExprs(n.Args, fn); changed {
+			c := *n
+			c.Args = args
+			rewritten = &c
+		}
+	case *Binary:
+		x, y := walkExpr(n.X, fn), walkExpr(n.Y, fn)
+		if x != n.X || y != n.Y {
+			b := *n
+			b.X, b.Y = x, y
+			rewritten = &b
+		}
+	case *Unary:
+		if x := walkExpr(n.X, fn); x != n.X {
+			u := *n
+			u.X = x
+			rewritten = &u
+		}
+	case *Conditional:
+		cond, t, f := walkExpr(n.Cond, fn), walkExpr(n.True, fn), walkExpr(n.False, fn)
+		if cond != n.Cond || t != n.True || f != n.False {
+			c := *n
+			c.Cond, c.True, c.False = cond, t, f
+			rewritten = &c
+		}
+	case *ForDirective:
+		coll := walkExpr(n.Collection, fn)
+		if body, changed := walkExprs(n.Body, fn); changed || coll != n.Collection {
+			d := *n
+			d.Collection = coll
+			d.Body = body
+			rewritten = &d
+		}
+	case *IfDirective:
+		cond := walkExpr(n.Cond, fn)
+		t, tChanged := walkExprs(n.True, fn)
+		f, fChanged := walkExprs(n.Else, fn)
+		if cond != n.Cond || tChanged || fChanged {
+			d := *n
+			d.Cond, d.True, d.Else = cond, t, f
+			rewritten = &d
 		}
 	default:
 		fmt.Printf(" unknown type: %T\n", n)
 	}
 
 	fn(nil)
+	return rewritten
+}
+
+// walkNode walks the child n, if any, and returns its replacement. Removed
+// children are kept.
+func walkNode(n Node, fn WalkFunc) Node {
+	if n == nil {
+		return nil
+	}
+
+	if m := Walk(n, fn); m != nil {
+		return m
+	}
+	return n
+}
+
+// walkExpr walks the expression e, if any, and returns its replacement
+func walkExpr(e Expr, fn WalkFunc) Expr {
+	if e == nil {
+		return nil
+	}
+	return as(e, walkNode(e, fn)).(Expr)
+}
+
+// walkNodes walks the elements of a list and returns the remaining ones and
+// whether any of them is replaced or removed. The list is copied only then.
+func walkNodes(list []Node, fn WalkFunc) ([]Node, bool) {
+	var out []Node
+	changed := false
+	for i, elem := range list {
+		m := Walk(elem, fn)
+		if !changed && m != elem {
+			changed = true
+			out = append(make([]Node, 0, len(list)), list[:i]...)
+		}
+		if changed && m != nil {
+			out = append(out, m)
+		}
+	}
+
+	if !changed {
+		return list, false
+	}
+	return out, true
+}
+
+// walkItems walks the items of an object list like walkNodes
+func walkItems(items []*ObjectItem, fn WalkFunc) ([]*ObjectItem, bool) {
+	var out []*ObjectItem
+	changed := false
+	for i, item := range items {
+		m := Walk(item, fn)
+		if !changed && m != Node(item) {
+			changed = true
+			out = append(make([]*ObjectItem, 0, len(items)), items[:i]...)
+		}
+		if changed && m != nil {
+			out = append(out, as(item, m).(*ObjectItem))
+		}
+	}
+
+	if !changed {
+		return items, false
+	}
+	return out, true
+}
+
+// walkKeys walks the keys of an item and returns them and whether any of
+// them is replaced. Keys can't be removed.
+func walkKeys(keys []*ObjectKey, fn WalkFunc) ([]*ObjectKey, bool) {
+	var out []*ObjectKey
+	for i, k := range keys {
+		m := walkNode(k, fn)
+		if out == nil && m != Node(k) {
+			out = append([]*ObjectKey(nil), keys...)
+		}
+		if out != nil {
+			out[i] = as(k, m).(*ObjectKey)
+		}
+	}
+
+	if out == nil {
+		return keys, false
+	}
+	return out, true
+}
+
+// walkExprs walks the parts of a template or the arguments of a call like
+// walkNodes
+func walkExprs(list []Expr, fn WalkFunc) ([]Expr, bool) {
+	var out []Expr
+	changed := false
+	for i, e := range list {
+		m := Walk(e, fn)
+		if !changed && m != Node(e) {
+			changed = true
+			out = append(make([]Expr, 0, len(list)), list[:i]...)
+		}
+		if changed && m != nil {
+			out = append(out, as(e, m).(Expr))
+		}
+	}
+
+	if !changed {
+		return list, false
+	}
+	return out, true
+}
+
+// as returns the replacement m of the node n, and panics if it's a different
+// kind of node and can't take the place of n.
+func as(n, m Node) Node {
+	ok := true
+	switch n.(type) {
+	case *ObjectItem:
+		_, ok = m.(*ObjectItem)
+	case *ObjectKey:
+		_, ok = m.(*ObjectKey)
+	case *ObjectList:
+		_, ok = m.(*ObjectList)
+	case *Template:
+		_, ok = m.(*Template)
+	case Expr:
+		_, ok = m.(Expr)
+	}
+
+	if !ok {
+		panic(fmt.Sprintf("ast: Walk can't replace %T with %T", n, m))
+	}
+	return m
 }
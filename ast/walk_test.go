@@ -0,0 +1,136 @@
+package ast_test
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/fatih/hcl/ast"
+	"github.com/fatih/hcl/parser"
+	"github.com/fatih/hcl/printer"
+	"github.com/fatih/hcl/token"
+)
+
+func TestWalkRewrite(t *testing.T) {
+	src := `service "web" {
+  password = "hunter2"
+  debug = true
+  ports = [80, 443]
+}
+`
+	f, err := parser.Parse([]byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	n := ast.Walk(f, func(n ast.Node) (ast.Node, bool) {
+		switch t := n.(type) {
+		case *ast.ObjectItem:
+			switch t.Keys[0].Token.Text {
+			case "password":
+				// redact the secret
+				item := *t
+				item.Val = &ast.LiteralType{
+					Token: token.Token{Type: token.STRING, Text: `"<redacted>"`},
+				}
+				return &item, false
+			case "debug":
+				return nil, false
+			}
+		case *ast.ObjectList:
+			// add a default to the block
+			if _, ok := t.Items[0].Val.(*ast.LiteralType); ok {
+				list := &ast.ObjectList{Items: append([]*ast.ObjectItem(nil), t.Items...)}
+				list.Add(&ast.ObjectItem{
+					Keys: []*ast.ObjectKey{{Token: token.Token{Type: token.IDENT, Text: "region"}}},
+					Val:  &ast.LiteralType{Token: token.Token{Type: token.STRING, Text: `"eu"`}},
+				})
+				return list, true
+			}
+		case *ast.LiteralType:
+			if t.Token.Text == "443" {
+				return nil, false
+			}
+		}
+		return n, true
+	})
+
+	var got []string
+	block := n.(*ast.File).Node.(*ast.ObjectList).Items[0].Val.(*ast.ObjectType)
+	for _, item := range block.List.Items {
+		var buf bytes.Buffer
+		if err := printer.Fprint(&buf, item); err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, buf.String())
+	}
+
+	want := []string{`password = "<redacted>"`, `ports = [80]`, `region = "eu"`}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("want %q, got %q", want, got)
+	}
+
+	// the original tree is unchanged
+	var buf bytes.Buffer
+	if err := printer.Fprint(&buf, f); err != nil {
+		t.Fatal(err)
+	}
+	if out, _ := printer.Format([]byte(src)); buf.String() != string(out) {
+		t.Errorf("original tree changed:\n%s", buf.String())
+	}
+}
+
+func TestWalkShares(t *testing.T) {
+	f, err := parser.Parse([]byte("a = 1\nb = [2, 3]\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	n := ast.Walk(f, func(n ast.Node) (ast.Node, bool) {
+		return n, true
+	})
+	if n != ast.Node(f) {
+		t.Errorf("unchanged tree is copied")
+	}
+
+	items := f.Node.(*ast.ObjectList).Items
+	n = ast.Walk(f, func(n ast.Node) (ast.Node, bool) {
+		if lit, ok := n.(*ast.LiteralType); ok && lit.Token.Text == "3" {
+			cp := *lit
+			cp.Token.Text = "4"
+			return &cp, false
+		}
+		return n, true
+	})
+
+	got := n.(*ast.File).Node.(*ast.ObjectList).Items
+	if got[0] != items[0] {
+		t.Errorf("unchanged item is copied")
+	}
+	if got[1] == items[1] {
+		t.Errorf("changed item is not copied")
+	}
+	if text := items[1].Val.(*ast.ListType).List[1].(*ast.LiteralType).Token.Text; text != "3" {
+		t.Errorf("original tree changed to %s", text)
+	}
+}
+
+func TestWalkPanics(t *testing.T) {
+	f, err := parser.Parse([]byte("a = 1\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("replacing an item with a literal doesn't panic")
+		}
+	}()
+
+	ast.Walk(f, func(n ast.Node) (ast.Node, bool) {
+		if _, ok := n.(*ast.ObjectItem); ok {
+			return &ast.LiteralType{}, false
+		}
+		return n, true
+	})
+}
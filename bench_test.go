@@ -0,0 +1,193 @@
+package hcl
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fatih/hcl/parser"
+)
+
+type benchConfig struct {
+	Name     string            `hcl:"name"`
+	Version  int               `hcl:"version"`
+	Debug    bool              `hcl:"debug"`
+	Timeout  time.Duration     `hcl:"timeout"`
+	Tags     []string          `hcl:"tags"`
+	Labels   map[string]string `hcl:"labels"`
+	Services []benchService    `hcl:"service"`
+}
+
+type benchService struct {
+	benchCommon `hcl:",squash"`
+
+	Name    string `hcl:",key"`
+	Port    int    `hcl:"port"`
+	Host    string `hcl:"host"`
+	Enabled bool
+}
+
+type benchCommon struct {
+	Owner string `hcl:"owner"`
+	Team  string `hcl:"team"`
+}
+
+const benchSource = `name = "app"
+version = 3
+debug = false
+timeout = "30s"
+tags = ["a", "b", "c"]
+
+labels {
+  env = "prod"
+  region = "eu"
+}
+
+service "web" {
+  port = 80
+  host = "example.com"
+  owner = "alice"
+  enabled = true
+}
+
+service "db" {
+  port = 5432
+  host = "db.internal"
+  team = "storage"
+}
+`
+
+// BenchmarkDecode decodes a small config, the common case of services
+// decoding many configs
+func BenchmarkDecode(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		var out benchConfig
+		if err := Decode(&out, benchSource); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkDecodeObject decodes a parsed tree, without the parser
+func BenchmarkDecodeObject(b *testing.B) {
+	f, err := parser.Parse([]byte(benchSource))
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var out benchConfig
+		if err := DecodeObject(&out, f); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkDecodeObjectParallel(b *testing.B) {
+	f, err := parser.Parse([]byte(benchSource))
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			var out benchConfig
+			if err := DecodeObject(&out, f); err != nil {
+				b.Error(err)
+				return
+			}
+		}
+	})
+}
+
+func BenchmarkDecodeInterface(b *testing.B) {
+	f, err := parser.Parse([]byte(benchSource))
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var out interface{}
+		if err := DecodeObject(&out, f); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkMarshal(b *testing.B) {
+	var in benchConfig
+	if err := Decode(&in, benchSource); err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := Marshal(&in); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkCheckTypes(b *testing.B) {
+	f, err := parser.Parse([]byte(benchSource))
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if errs := CheckTypes(f, &benchConfig{}); len(errs) > 0 {
+			b.Fatal(errs)
+		}
+	}
+}
+
+func BenchmarkStructFields(b *testing.B) {
+	t := reflect.TypeOf(benchService{})
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		structFields(t)
+	}
+}
+
+// TestFieldCache decodes concurrently, for the race detector, and checks
+// that the cached fields are the ones of the type
+func TestFieldCache(t *testing.T) {
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var out benchConfig
+			if err := Decode(&out, benchSource); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	typ := reflect.TypeOf(benchService{})
+	if !reflect.DeepEqual(structFields(typ), typeFields(typ)) {
+		t.Errorf("cached fields differ:\n%v\n%v", structFields(typ), typeFields(typ))
+	}
+
+	var names []string
+	for _, f := range structFields(typ) {
+		names = append(names, f.name)
+	}
+	if got := strings.Join(names, " "); got != "owner team Name port host Enabled" {
+		t.Errorf("unexpected fields %s", got)
+	}
+}
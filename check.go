@@ -0,0 +1,366 @@
+package hcl
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/fatih/hcl/ast"
+	"github.com/fatih/hcl/convert"
+	"github.com/fatih/hcl/schema"
+	"github.com/fatih/hcl/token"
+)
+
+// CheckTypes reports every value of the syntax tree n, which can't be decoded
+// into its destination, without decoding it. typ is a *schema.Schema, a
+// reflect.Type or a value of the type n would be decoded into, i.e.
+// (*Config)(nil). Unlike DecodeObject, it doesn't stop at the first problem
+// and doesn't allocate destination values.
+//
+// For a schema, the diagnostics of values of the wrong type are returned.
+// Otherwise the errors are of type *parser.PosError, with the messages
+// returned by DecodeObject.
+func (c *DecoderConfig) CheckTypes(n ast.Node, typ interface{}) []error {
+	var t reflect.Type
+	switch v := typ.(type) {
+	case *schema.Schema:
+		var errs []error
+		for _, diag := range v.Validate(n) {
+			if diag.Summary == "Incorrect attribute type" || diag.Summary == "Unexpected value" {
+				errs = append(errs, diag)
+			}
+		}
+		return errs
+	case reflect.Type:
+		t = v
+	default:
+		t = reflect.TypeOf(typ)
+	}
+
+	if t == nil {
+		return []error{fmt.Errorf("expected a type, got: %v", typ)}
+	}
+
+	// nothing is decoded, so there are no blocks to report
+	config := *c
+	config.OnBlock = nil
+	config.Warn = nil
+
+	ch := &checker{decoder: decoder{config: &config}}
+	ch.check("root", n, t)
+	return ch.errs
+}
+
+// CheckTypes checks the types of n with DefaultDecoderConfig.
+func CheckTypes(n ast.Node, typ interface{}) []error {
+	return DefaultDecoderConfig.CheckTypes(n, typ)
+}
+
+// checker mirrors the decoder for types instead of values
+type checker struct {
+	decoder
+	errs []error
+}
+
+func (c *checker) errorf(pos token.Pos, format string, args ...interface{}) {
+	c.errs = append(c.errs, posErrorf(pos, format, args...))
+}
+
+func (c *checker) check(name string, n ast.Node, t reflect.Type) {
+	if f, ok := n.(*ast.File); ok {
+		c.check(name, f.Node, t)
+		return
+	}
+
+	// the node is up to the UnmarshalHCL method, which isn't called, raw
+	// values take any node and null resets any value
+	if isUnmarshaler(t) || isNull(n) || isRaw(t) {
+		return
+	}
+
+	if t == orderedMapType {
+		if _, ok := ast.ObjectListOf(n); !ok {
+			c.errorf(n.Pos(), "%s: cannot decode %s into %s", name, nodeName(n), t)
+		} else if _, err := c.orderedValue(name, n); err != nil {
+			c.errs = append(c.errs, err)
+		}
+		return
+	}
+
+	if lit, ok := n.(*ast.LiteralType); ok && (lit.Token.Type == token.STRING || lit.Token.Type == token.HEREDOC) && c.config.converter(t) != nil {
+		if v, err := c.literal(lit); err != nil {
+			c.errs = append(c.errs, err)
+		} else {
+			c.checkValue(name, lit.Pos(), v, t)
+		}
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Interface:
+		if t.NumMethod() != 0 {
+			break
+		}
+
+		if _, err := c.value(name, n); err != nil {
+			c.errs = append(c.errs, err)
+		}
+		return
+	case reflect.Ptr:
+		c.check(name, n, t.Elem())
+		return
+	case reflect.Slice:
+		c.checkSlice(name, []ast.Node{n}, t)
+		return
+	case reflect.Map:
+		if list, ok := ast.ObjectListOf(n); ok {
+			c.checkMap(name, list, t)
+			return
+		}
+	case reflect.Struct:
+		if item, ok := labelItem(n); ok {
+			if _, ok := keyField(t); ok {
+				n = labelBody(item)
+			}
+		}
+
+		if list, ok := ast.ObjectListOf(n); ok {
+			c.checkStruct(name, list, t)
+			return
+		}
+	}
+
+	lit, ok := n.(*ast.LiteralType)
+	if !ok {
+		c.errorf(n.Pos(), "%s: cannot decode %s into %s", name, nodeName(n), t)
+		return
+	}
+
+	if lit.Token.Type == token.UNIT {
+		c.checkUnit(name, lit, t)
+		return
+	}
+	if (lit.Token.Type == token.NUMBER || lit.Token.Type == token.FLOAT) && isBig(t) {
+		if _, err := bigNumber(lit, t); err != nil {
+			c.errorf(lit.Pos(), "%s: %s", name, err)
+		}
+		return
+	}
+
+	v, err := c.literal(lit)
+	if err != nil {
+		c.errs = append(c.errs, err)
+		return
+	}
+	c.checkValue(name, lit.Pos(), v, t)
+}
+
+func (c *checker) checkNodes(name string, nodes []ast.Node, t reflect.Type) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t.Kind() == reflect.Slice && c.config.converter(t) == nil && !isUnmarshaler(t) && t != orderedMapType {
+		c.checkSlice(name, nodes, t)
+		return
+	}
+
+	for _, n := range nodes {
+		c.check(name, n, t)
+	}
+}
+
+func (c *checker) checkSlice(name string, nodes []ast.Node, t reflect.Type) {
+	i := 0
+	for _, n := range nodes {
+		switch n := n.(type) {
+		case *ast.ListType:
+			for _, elem := range n.List {
+				c.check(fmt.Sprintf("%s[%d]", name, i), elem, t.Elem())
+				i++
+			}
+		case *ast.LiteralType:
+			if n.Token.Type == token.NULL {
+				continue
+			}
+
+			if n.Token.Type == token.UNIT {
+				c.checkUnit(fmt.Sprintf("%s[%d]", name, i), n, t.Elem())
+				i++
+				continue
+			}
+
+			v, err := c.literal(n)
+			if err != nil {
+				c.errs = append(c.errs, err)
+				i++
+				continue
+			}
+
+			values, ok := v.([]interface{})
+			if !ok {
+				values = []interface{}{v}
+			}
+
+			for _, v := range values {
+				c.checkValue(fmt.Sprintf("%s[%d]", name, i), n.Pos(), v, t.Elem())
+				i++
+			}
+		default:
+			c.check(fmt.Sprintf("%s[%d]", name, i), n, t.Elem())
+			i++
+		}
+	}
+}
+
+func (c *checker) checkMap(name string, list *ast.ObjectList, t reflect.Type) {
+	if t.Key().Kind() != reflect.String {
+		c.errorf(list.Pos(), "%s: map key must be a string, got: %s", name, t.Key())
+		return
+	}
+
+	keys, values := fields(list)
+	for _, key := range keys {
+		c.checkNodes(name+"."+ast.PathKey(key), values[key], t.Elem())
+	}
+}
+
+func (c *checker) checkStruct(name string, list *ast.ObjectList, t reflect.Type) {
+	keys, values := fields(list)
+	for _, field := range structFields(t) {
+		key := field.name
+		if key == "-" || field.filled {
+			continue
+		}
+
+		key = c.matchKey(field, key, keys, values)
+		nodes, ok := values[key]
+		switch {
+		case !ok:
+		case !field.settable:
+			if err := c.skipField(name+"."+ast.PathKey(key), nodes[0].Pos(), field.StructField); err != nil {
+				c.errs = append(c.errs, err)
+			}
+		case field.Tag.Get("parse") != "":
+			c.checkParse(name+"."+ast.PathKey(key), nodes, field.StructField)
+		default:
+			c.checkNodes(name+"."+ast.PathKey(key), nodes, field.Type)
+		}
+	}
+}
+
+// checkParse checks whether the parser of field accepts the strings of
+// nodes, by decoding them into a value of its type
+func (c *checker) checkParse(name string, nodes []ast.Node, field reflect.StructField) {
+	parser, fn, err := c.fieldParser(name, nodes[0].Pos(), field)
+	if err == nil {
+		err = c.parseNodes(name, parser, fn, nodes, reflect.New(field.Type).Elem())
+	}
+	if err != nil {
+		c.errs = append(c.errs, err)
+	}
+}
+
+// checkValue checks whether decodeValue can decode v into a value of type t
+func (c *checker) checkValue(name string, pos token.Pos, v interface{}, t reflect.Type) {
+	mismatch := func() {
+		c.errorf(pos, "%s: cannot decode %s into %s", name, convert.TypeName(v), t)
+	}
+
+	if v == nil {
+		return
+	}
+
+	if s, ok := v.(string); ok {
+		if conv := c.config.converter(t); conv != nil {
+			if err := c.convertString(name, pos, conv, s, reflect.New(t).Elem()); err != nil {
+				c.errs = append(c.errs, err)
+			}
+			return
+		}
+	}
+
+	switch t.Kind() {
+	case reflect.Interface:
+		if t.NumMethod() != 0 {
+			mismatch()
+		}
+	case reflect.Ptr:
+		c.checkValue(name, pos, v, t.Elem())
+	case reflect.String:
+		if _, err := convert.ToString(v); err != nil {
+			mismatch()
+		}
+	case reflect.Bool:
+		if _, err := convert.ToBool(v); err != nil {
+			mismatch()
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if _, err := convert.ToInt(v, t.Bits()); err != nil {
+			c.convertError(name, pos, err, t, mismatch)
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if _, err := convert.ToUint(v, t.Bits()); err != nil {
+			c.convertError(name, pos, err, t, mismatch)
+		}
+	case reflect.Float32, reflect.Float64:
+		if _, err := convert.ToFloat64(v); err != nil {
+			mismatch()
+		}
+	case reflect.Slice:
+		for i, elem := range convert.ToList(v) {
+			c.checkValue(fmt.Sprintf("%s[%d]", name, i), pos, elem, t.Elem())
+		}
+	case reflect.Map:
+		m, ok := v.(map[string]interface{})
+		if !ok || t.Key().Kind() != reflect.String {
+			mismatch()
+			return
+		}
+
+		for k, elem := range m {
+			c.checkValue(name+"."+ast.PathKey(k), pos, elem, t.Elem())
+		}
+	case reflect.Struct:
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			mismatch()
+			return
+		}
+
+		for _, field := range structFields(t) {
+			key := field.name
+			if key == "-" || field.filled {
+				continue
+			}
+
+			elem, ok := m[key]
+			switch {
+			case !ok:
+			case !field.settable:
+				if err := c.skipField(name+"."+ast.PathKey(key), pos, field.StructField); err != nil {
+					c.errs = append(c.errs, err)
+				}
+			default:
+				c.checkValue(name+"."+ast.PathKey(key), pos, elem, field.Type)
+			}
+		}
+	default:
+		mismatch()
+	}
+}
+
+// checkUnit checks whether decodeUnit can decode lit into a value of type t.
+// Units are decoded into values of basic types, so it just decodes them.
+func (c *checker) checkUnit(name string, lit *ast.LiteralType, t reflect.Type) {
+	if err := c.decodeUnit(name, lit, reflect.New(t).Elem()); err != nil {
+		c.errs = append(c.errs, err)
+	}
+}
+
+func (c *checker) convertError(name string, pos token.Pos, err error, t reflect.Type, mismatch func()) {
+	if e, ok := err.(*convert.RangeError); ok {
+		c.errorf(pos, "%s: %d overflows %s", name, e.Value, t)
+		return
+	}
+	mismatch()
+}
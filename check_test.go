@@ -0,0 +1,103 @@
+package hcl
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/fatih/hcl/parser"
+	"github.com/fatih/hcl/schema"
+)
+
+func TestCheckTypes(t *testing.T) {
+	cases := []struct {
+		src  string
+		typ  interface{}
+		errs []string
+	}{
+		{`Port = 80 hostname = "a" tags = ["b"]`, service{}, nil},
+		{`Port = 80`, (*service)(nil), nil},
+		{`a = [1, 2.5, "c"] b { c = true }`, reflect.TypeOf(map[string]interface{}{}), nil},
+		{
+			`Port = "x" tags { a = 1 } enabled = "yes"`,
+			&service{},
+			[]string{
+				`At 1:8: root.Port: cannot decode string into int`,
+				`At 1:17: root.tags[0]: cannot decode object into string`,
+				`At 1:37: root.enabled: cannot decode string into bool`,
+			},
+		},
+		{
+			`a = 300 b = -1 c = [1, "x"]`,
+			map[string][]int8{},
+			[]string{
+				`At 1:5: root.a[0]: 300 overflows int8`,
+				`At 1:24: root.c[1]: cannot decode string into int8`,
+			},
+		},
+		{
+			`service "web" { Port = "x" } service "db" { Port = "y" }`,
+			struct{ Service map[string]*service }{},
+			[]string{
+				`At 1:24: root.service.web.Port: cannot decode string into int`,
+				`At 1:52: root.service.db.Port: cannot decode string into int`,
+			},
+		},
+		{
+			`port = "80" host = 1 extra = true`,
+			&schema.Schema{Attributes: []*schema.Attribute{
+				{Name: "port", Type: schema.Number},
+				{Name: "host", Type: schema.String, Required: true},
+			}},
+			[]string{
+				`At 1:8: Incorrect attribute type: The attribute "port" must be of type number, got: string.`,
+				`At 1:20: Incorrect attribute type: The attribute "host" must be of type string, got: number.`,
+			},
+		},
+	}
+
+	for _, c := range cases {
+		f, err := parser.Parse([]byte(c.src))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var errs []string
+		for _, err := range CheckTypes(f, c.typ) {
+			errs = append(errs, err.Error())
+		}
+
+		if strings.Join(errs, "\n") != strings.Join(c.errs, "\n") {
+			t.Errorf("%s: want:\n%s\n\ngot:\n%s", c.src, strings.Join(c.errs, "\n"), strings.Join(errs, "\n"))
+		}
+	}
+}
+
+// CheckTypes reports the same first error as Decode
+func TestCheckTypesDecodeErrors(t *testing.T) {
+	cases := []struct {
+		src string
+		out interface{}
+	}{
+		{`a = "b"`, &map[string]int{}},
+		{`a = 300`, &map[string]int8{}},
+		{`a = -1`, &map[string]uint{}},
+		{`a = [1]`, &map[string]int{}},
+		{`a { b = 1 }`, &map[string]string{}},
+		{`a = ["x"]`, &map[string][]int{}},
+		{`a = 1`, &map[int]int{}},
+	}
+
+	for _, c := range cases {
+		f, err := parser.Parse([]byte(c.src))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		errs := CheckTypes(f, c.out)
+		err = DecodeObject(c.out, f)
+		if len(errs) != 1 || err == nil || errs[0].Error() != err.Error() {
+			t.Errorf("%s: want: %v, got: %v", c.src, err, errs)
+		}
+	}
+}
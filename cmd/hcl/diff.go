@@ -0,0 +1,83 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/fatih/hcl/diff"
+)
+
+// runDiff runs the diff command. The exit code is 0 if there are no
+// differences, 1 if there are differences and 2 if an error occurred.
+func runDiff(args []string) int {
+	flags := flag.NewFlagSet("diff", flag.ContinueOnError)
+	jsonOut := flags.Bool("json", false, "print the changes as JSON")
+	quiet := flags.Bool("q", false, "don't print anything, only set the exit code")
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: hcl diff [flags] old.hcl new.hcl\n")
+		flags.PrintDefaults()
+	}
+
+	files, err := parseArgs(flags, args)
+	if err != nil {
+		return 2
+	}
+
+	if len(files) != 2 {
+		flags.Usage()
+		return 2
+	}
+
+	a, err := parseFile(files[0])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	b, err := parseFile(files[1])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	changes := diff.Files(a, b)
+
+	switch {
+	case *quiet:
+	case *jsonOut:
+		type jsonChange struct {
+			Kind string   `json:"kind"`
+			Path []string `json:"path"`
+			Old  string   `json:"old,omitempty"`
+			New  string   `json:"new,omitempty"`
+		}
+
+		out := make([]jsonChange, 0, len(changes))
+		for _, c := range changes {
+			out = append(out, jsonChange{
+				Kind: c.Kind.String(),
+				Path: c.Path,
+				Old:  diff.Format(c.Old),
+				New:  diff.Format(c.New),
+			})
+		}
+
+		enc, err := json.MarshalIndent(out, "", "  ")
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 2
+		}
+		fmt.Println(string(enc))
+	default:
+		for _, c := range changes {
+			fmt.Println(c)
+		}
+	}
+
+	if len(changes) > 0 {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/fatih/hcl/ast"
+	"github.com/fatih/hcl/merge"
+	"github.com/fatih/hcl/printer"
+)
+
+// runGet runs the get command. It merges the files like the merge command and
+// prints the value at the given path, i.e. service.web.port.
+func runGet(args []string) int {
+	flags := flag.NewFlagSet("get", flag.ContinueOnError)
+	explain := flags.Bool("explain", false, "report which file set the value and which files it overrode")
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: hcl get [flags] key file...\n")
+		flags.PrintDefaults()
+	}
+
+	positional, err := parseArgs(flags, args)
+	if err != nil {
+		return 2
+	}
+
+	if len(positional) < 2 {
+		flags.Usage()
+		return 2
+	}
+
+	path := positional[0]
+	var files []merge.File
+	for _, name := range positional[1:] {
+		f, err := parseFile(name)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		files = append(files, merge.File{Name: name, File: f})
+	}
+
+	res, prov, err := merge.DefaultConfig.MergeFiles(files...)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	item := lookup(res.Node.(*ast.ObjectList), ast.SplitPath(path))
+	if item == nil {
+		fmt.Fprintf(os.Stderr, "hcl: %s is not set\n", path)
+		return 1
+	}
+
+	var buf bytes.Buffer
+	if err := printer.Fprint(&buf, item.Val); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	buf.WriteByte('\n')
+
+	if *explain {
+		for i, pos := range prov.Explain(path) {
+			if i == 0 {
+				fmt.Fprintf(&buf, "# set by %s\n", pos)
+			} else {
+				fmt.Fprintf(&buf, "# overrides %s\n", pos)
+			}
+		}
+	}
+
+	os.Stdout.Write(buf.Bytes())
+	return 0
+}
+
+// lookup returns the first item matching keys in list. The keys of an item,
+// i.e. service "web", match multiple keys.
+func lookup(list *ast.ObjectList, keys []string) *ast.ObjectItem {
+	for _, item := range list.Items {
+		if len(item.Keys) > len(keys) {
+			continue
+		}
+
+		match := true
+		for i, k := range item.Keys {
+			if k.Token.Value() != keys[i] {
+				match = false
+				break
+			}
+		}
+		if !match {
+			continue
+		}
+
+		if len(item.Keys) == len(keys) {
+			return item
+		}
+
+		if obj, ok := item.Val.(*ast.ObjectType); ok && obj.List != nil {
+			if found := lookup(obj.List, keys[len(item.Keys):]); found != nil {
+				return found
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+// Command hcl provides tools to work with HCL (HashiCorp Configuration
+// Language) files.
+//
+// Usage:
+//
+//	hcl <command> [flags] [arguments]
+//
+// The commands are:
+//
+//	diff    report the semantic differences between two files
+//	get     print a value of merged files and where it was set
+//	merge   merge override files on top of a base file
+//	secrets report passwords, tokens and other secrets in files
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/fatih/hcl/ast"
+	"github.com/fatih/hcl/parser"
+)
+
+type command struct {
+	name  string
+	usage string
+	run   func(args []string) int
+}
+
+var commands = []command{
+	{"diff", "report the semantic differences between two files", runDiff},
+	{"get", "print a value of merged files and where it was set", runGet},
+	{"merge", "merge override files on top of a base file", runMerge},
+	{"secrets", "report passwords, tokens and other secrets in files", runSecrets},
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	for _, c := range commands {
+		if c.name == os.Args[1] {
+			os.Exit(c.run(os.Args[2:]))
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "hcl: unknown command %q\n", os.Args[1])
+	usage()
+	os.Exit(2)
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage: hcl <command> [flags] [arguments]\n\ncommands:\n")
+	for _, c := range commands {
+		fmt.Fprintf(os.Stderr, "  %-8s%s\n", c.name, c.usage)
+	}
+}
+
+// parseFile parses the file with the given name
+func parseFile(name string) (*ast.File, error) {
+	src, err := ioutil.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := parser.Parse(src)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", name, err)
+	}
+	return f, nil
+}
+
+// parseArgs parses the flags of args, which may be interspersed with the
+// positional arguments, and returns the positional arguments.
+func parseArgs(flags *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := flags.Parse(args); err != nil {
+			return nil, err
+		}
+
+		if flags.NArg() == 0 {
+			return positional, nil
+		}
+
+		positional = append(positional, flags.Arg(0))
+		args = flags.Args()[1:]
+	}
+}
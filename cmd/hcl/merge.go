@@ -0,0 +1,80 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/fatih/hcl/merge"
+	"github.com/fatih/hcl/printer"
+)
+
+// runMerge runs the merge command. Every override is merged on top of the
+// result of the previous files.
+func runMerge(args []string) int {
+	flags := flag.NewFlagSet("merge", flag.ContinueOnError)
+	output := flags.String("o", "", "write the result to this file instead of stdout")
+	shallow := flags.Bool("shallow", false, "replace nested objects instead of merging them")
+	appendLists := flags.Bool("append", false, "append lists instead of replacing them")
+	strict := flags.Bool("error-on-conflict", false, "fail if an override changes a value")
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: hcl merge [flags] base.hcl override.hcl...\n")
+		flags.PrintDefaults()
+	}
+
+	files, err := parseArgs(flags, args)
+	if err != nil {
+		return 2
+	}
+
+	if len(files) < 2 {
+		flags.Usage()
+		return 2
+	}
+
+	cfg := &merge.Config{
+		Deep:            !*shallow,
+		AppendLists:     *appendLists,
+		ErrorOnConflict: *strict,
+	}
+
+	res, err := parseFile(files[0])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	for _, name := range files[1:] {
+		f, err := parseFile(name)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+
+		res, err = cfg.Merge(res, f)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %s\n", name, err)
+			return 1
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := printer.Fprint(&buf, res); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	buf.WriteByte('\n')
+
+	if *output == "" {
+		os.Stdout.Write(buf.Bytes())
+		return 0
+	}
+
+	if err := ioutil.WriteFile(*output, buf.Bytes(), 0644); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
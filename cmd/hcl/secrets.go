@@ -0,0 +1,55 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/fatih/hcl/secrets"
+)
+
+// runSecrets runs the secrets command. The exit code is 0 if no secrets are
+// found, 1 if there are secrets and 2 if an error occurred, so it can be used
+// as a pre-commit hook.
+func runSecrets(args []string) int {
+	flags := flag.NewFlagSet("secrets", flag.ContinueOnError)
+	mask := flags.Bool("mask", false, "print the files with the secrets masked")
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: hcl secrets [flags] file...\n")
+		flags.PrintDefaults()
+	}
+
+	files, err := parseArgs(flags, args)
+	if err != nil {
+		return 2
+	}
+
+	if len(files) == 0 {
+		flags.Usage()
+		return 2
+	}
+
+	code := 0
+	for _, name := range files {
+		f, err := parseFile(name)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 2
+		}
+
+		if *mask {
+			if err := secrets.Fprint(os.Stdout, f); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				return 2
+			}
+			fmt.Println()
+			continue
+		}
+
+		for _, finding := range secrets.Find(f) {
+			fmt.Printf("%s:%s\n", name, finding)
+			code = 1
+		}
+	}
+	return code
+}
@@ -0,0 +1,78 @@
+// Command hcl2json converts HCL (HashiCorp Configuration Language) files
+// into JSON, i.e. for services written in other languages.
+//
+// Usage:
+//
+//	hcl2json [path ...]
+//
+// Without paths, it converts the standard input. With several paths, the
+// files are converted one after another. Blocks are converted like hcl.ToJSON
+// does: their labels become nested objects and repeated blocks arrays. The
+// exit code is 1 if a file can't be read, parsed or converted.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/fatih/hcl"
+)
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: hcl2json [path ...]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		if err := convert("<standard input>", os.Stdin, os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	code := 0
+	for _, name := range flag.Args() {
+		if err := convertFile(name); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			code = 1
+		}
+	}
+	os.Exit(code)
+}
+
+func convertFile(name string) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return convert(name, f, os.Stdout)
+}
+
+// convert writes the JSON of the HCL read from in to out
+func convert(name string, in io.Reader, out io.Writer) error {
+	src, err := ioutil.ReadAll(in)
+	if err != nil {
+		return err
+	}
+
+	f, err := hcl.Parse(src)
+	if err != nil {
+		return fmt.Errorf("%s: %s", name, err)
+	}
+
+	res, err := hcl.ToJSON(f)
+	if err != nil {
+		return fmt.Errorf("%s: %s", name, err)
+	}
+
+	_, err = fmt.Fprintf(out, "%s\n", res)
+	return err
+}
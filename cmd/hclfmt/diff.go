@@ -0,0 +1,140 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// context is the number of unchanged lines around the changes of a hunk
+const context = 3
+
+// edit is a line of a diff: ' ' for unchanged lines, '-' for removed and
+// '+' for added ones
+type edit struct {
+	op   byte
+	line string
+}
+
+// unifiedDiff returns the differences of the lines of a and b in the
+// unified format, with the file names oldName and newName
+func unifiedDiff(oldName, newName string, a, b []byte) []byte {
+	edits := diffLines(splitLines(a), splitLines(b))
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "--- %s\n+++ %s\n", oldName, newName)
+
+	// line numbers of the next edit in a and b
+	oldLine, newLine := 1, 1
+	for i := 0; i < len(edits); {
+		if edits[i].op == ' ' {
+			oldLine++
+			newLine++
+			i++
+			continue
+		}
+
+		// extend the hunk to the context before the change and up to the
+		// context after the last change closer than twice the context
+		start := i - context
+		if start < 0 {
+			start = 0
+		}
+
+		end := i
+		for unchanged := 0; end < len(edits) && unchanged <= 2*context; end++ {
+			if edits[end].op == ' ' {
+				unchanged++
+			} else {
+				unchanged = 0
+			}
+		}
+		for end > i && edits[end-1].op == ' ' {
+			end--
+		}
+		if end += context; end > len(edits) {
+			end = len(edits)
+		}
+
+		oldStart, newStart := oldLine-(i-start), newLine-(i-start)
+		var oldCount, newCount int
+		var hunk bytes.Buffer
+		for _, e := range edits[start:end] {
+			if e.op != '+' {
+				oldCount++
+			}
+			if e.op != '-' {
+				newCount++
+			}
+			fmt.Fprintf(&hunk, "%c%s\n", e.op, e.line)
+		}
+
+		fmt.Fprintf(&buf, "@@ -%s +%s @@\n", hunkRange(oldStart, oldCount), hunkRange(newStart, newCount))
+		buf.Write(hunk.Bytes())
+
+		oldLine += oldCount - (i - start)
+		newLine += newCount - (i - start)
+		i = end
+	}
+	return buf.Bytes()
+}
+
+// hunkRange formats the start and the length of a range of a hunk
+func hunkRange(start, count int) string {
+	if count == 0 {
+		start-- // empty ranges refer to the line before
+	}
+	if count == 1 {
+		return fmt.Sprint(start)
+	}
+	return fmt.Sprintf("%d,%d", start, count)
+}
+
+func splitLines(src []byte) []string {
+	s := strings.TrimSuffix(string(src), "\n")
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, "\n")
+}
+
+// diffLines returns the edits turning a into b, based on their longest
+// common subsequence
+func diffLines(a, b []string) []edit {
+	// lcs[i][j] is the length of the longest common subsequence of a[i:]
+	// and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			switch {
+			case a[i] == b[j]:
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			case lcs[i+1][j] >= lcs[i][j+1]:
+				lcs[i][j] = lcs[i+1][j]
+			default:
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var edits []edit
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			edits = append(edits, edit{' ', a[i]})
+			i++
+			j++
+		case j == len(b) || i < len(a) && lcs[i+1][j] >= lcs[i][j+1]:
+			edits = append(edits, edit{'-', a[i]})
+			i++
+		default:
+			edits = append(edits, edit{'+', b[j]})
+			j++
+		}
+	}
+	return edits
+}
@@ -0,0 +1,208 @@
+// Command hclfmt formats HCL (HashiCorp Configuration Language) files in the
+// canonical style of package printer.
+//
+// Usage:
+//
+//	hclfmt [flags] [path ...]
+//
+// Without paths, it formats the standard input. Directories are processed
+// recursively, formatting all files with the extension ".hcl". By default,
+// the formatted source is printed to the standard output.
+//
+// The flags are:
+//
+//	-comments style
+//		convert the comments to the style #, // or /*
+//	-d	print diffs of the files whose formatting differs
+//	-l	list the files whose formatting differs
+//	-w	write the formatted source back to the files
+//
+// The exit code is 1 if -d or -l found files whose formatting differs, so it
+// can enforce the style in CI, and 2 if a file can't be read or parsed. Up to
+// ten syntax errors are reported per file, with the lines containing them.
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/fatih/hcl/parser"
+	"github.com/fatih/hcl/printer"
+)
+
+var (
+	list  = flag.Bool("l", false, "list the files whose formatting differs")
+	write = flag.Bool("w", false, "write the formatted source back to the files")
+	diffs = flag.Bool("d", false, "print diffs of the files whose formatting differs")
+
+	comments = flag.String("comments", "", "convert the comments to the `style` #, // or /*")
+)
+
+var commentStyles = map[string]printer.CommentStyle{
+	"":   printer.KeepComments,
+	"#":  printer.HashComments,
+	"//": printer.SlashComments,
+	"/*": printer.BlockComments,
+}
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: hclfmt [flags] [path ...]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if _, ok := commentStyles[*comments]; !ok {
+		fmt.Fprintf(os.Stderr, "hclfmt: unknown comment style %q\n", *comments)
+		os.Exit(2)
+	}
+
+	if flag.NArg() == 0 {
+		if *write {
+			fmt.Fprintln(os.Stderr, "hclfmt: cannot use -w with the standard input")
+			os.Exit(2)
+		}
+
+		changed, err := process("<standard input>", os.Stdin, os.Stdout)
+		os.Exit(exitCode(changed, err))
+	}
+
+	code := 0
+	for _, path := range flag.Args() {
+		err := filepath.Walk(path, func(name string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+
+			// named files are formatted regardless of their extension
+			if info.IsDir() || name != path && filepath.Ext(name) != ".hcl" {
+				return nil
+			}
+
+			if c := exitCode(processFile(name)); c > code {
+				code = c
+			}
+			return nil
+		})
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			code = 2
+		}
+	}
+	os.Exit(code)
+}
+
+// exitCode returns the exit code for the result of processing a file and
+// reports the error, if any
+func exitCode(changed bool, err error) int {
+	switch {
+	case err != nil:
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	case changed && (*list || *diffs):
+		return 1
+	}
+	return 0
+}
+
+func processFile(name string) (bool, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+
+	return process(name, f, os.Stdout)
+}
+
+// process formats the source read from in and writes the result to out, or
+// the file name with -w. It reports whether the formatting changed.
+func process(name string, in io.Reader, out io.Writer) (bool, error) {
+	src, err := ioutil.ReadAll(in)
+	if err != nil {
+		return false, err
+	}
+
+	res, errs := format(src)
+	if len(errs) > 0 {
+		return false, syntaxErrors(name, src, errs)
+	}
+
+	changed := !bytes.Equal(src, res)
+	if *list && changed {
+		fmt.Fprintln(out, name)
+	}
+
+	if *write && changed {
+		info, err := os.Stat(name)
+		if err != nil {
+			return false, err
+		}
+
+		if err := ioutil.WriteFile(name, res, info.Mode().Perm()); err != nil {
+			return false, err
+		}
+	}
+
+	if *diffs && changed {
+		out.Write(unifiedDiff(name+".orig", name, src, res))
+	}
+
+	if !*list && !*write && !*diffs {
+		_, err = out.Write(res)
+	}
+	return changed, err
+}
+
+// maxErrors is the number of syntax errors reported per file
+const maxErrors = 10
+
+// format formats src, which ends with a newline afterwards. It returns up to
+// maxErrors syntax errors.
+func format(src []byte) ([]byte, []error) {
+	config := parser.DefaultConfig
+	config.MaxErrors = maxErrors
+	f, errs := config.ParseWithRecovery(src)
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	cfg := printer.DefaultConfig
+	cfg.CommentStyle = commentStyles[*comments]
+
+	var buf bytes.Buffer
+	if err := cfg.Fprint(&buf, f); err != nil {
+		return nil, []error{err}
+	}
+
+	res := buf.Bytes()
+	if len(res) > 0 && res[len(res)-1] != '\n' {
+		res = append(res, '\n')
+	}
+	return res, nil
+}
+
+// syntaxErrors returns the errors of the file name as one error, showing the
+// line of each syntax error
+func syntaxErrors(name string, src []byte, errs []error) error {
+	var buf bytes.Buffer
+	for i, err := range errs {
+		if i > 0 {
+			buf.WriteByte('\n')
+		}
+		fmt.Fprintf(&buf, "%s: %s", name, err)
+
+		if pe, ok := err.(*parser.PosError); ok {
+			if se, ok := pe.Err.(*parser.ParseError); ok {
+				fmt.Fprintf(&buf, "\n%s", se.Snippet(src))
+			}
+		}
+	}
+	return errors.New(buf.String())
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestMain runs the command instead of the tests if the test binary is
+// started by run, so the tests see its output and exit code
+func TestMain(m *testing.M) {
+	if os.Getenv("HCLFMT_TEST_MAIN") == "1" {
+		main()
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// run runs hclfmt with args and the standard input stdin
+func run(t *testing.T, stdin string, args ...string) (stdout, stderr string, code int) {
+	cmd := exec.Command(os.Args[0], args...)
+	cmd.Env = append(os.Environ(), "HCLFMT_TEST_MAIN=1")
+	cmd.Stdin = strings.NewReader(stdin)
+
+	var out, errOut bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &out, &errOut
+	err := cmd.Run()
+	if e, ok := err.(*exec.ExitError); ok {
+		code = e.ExitCode()
+	} else if err != nil {
+		t.Fatal(err)
+	}
+	return out.String(), errOut.String(), code
+}
+
+func TestCommand(t *testing.T) {
+	golden, err := ioutil.ReadFile("testdata/unformatted.golden")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		args   []string
+		stdin  string
+		stdout string
+		stderr string
+		code   int
+	}{
+		{nil, "a=1", "a = 1\n", "", 0},
+		{[]string{"-comments", "//"}, "# c\na=1", "// c\na = 1\n", "", 0},
+		{[]string{"testdata/unformatted.hcl"}, "", string(golden), "", 0},
+		{[]string{"testdata/formatted.hcl"}, "", "a = 1\n\nb = {\n  c = 2\n}\n", "", 0},
+		{[]string{"-l", "testdata/formatted.hcl"}, "", "", "", 0},
+		{[]string{"-l", "testdata/unformatted.hcl", "testdata/formatted.hcl"}, "", "testdata/unformatted.hcl\n", "", 1},
+		{[]string{"-l", "testdata/dir"}, "", "testdata/dir/nested/nested.hcl\n", "", 1},
+		{[]string{"-d", "testdata/formatted.hcl"}, "", "", "", 0},
+		{
+			[]string{"-d", "testdata/unformatted.hcl"}, "",
+			"--- testdata/unformatted.hcl.orig\n+++ testdata/unformatted.hcl\n@@ -1,4 +1,5 @@\n" +
+				"-a    =   1\n-b {\n-c = \"x\" # comment\n+a = 1\n+\n+b = {\n+  c = \"x\" # comment\n }\n",
+			"", 1,
+		},
+		{
+			[]string{"testdata/invalid.hcl"}, "", "",
+			"testdata/invalid.hcl: At 2:1: list opened at 1:5 not terminated, expected: ] got: EOF\n\n^\n", 2,
+		},
+		{[]string{"testdata/missing.hcl"}, "", "", "lstat testdata/missing.hcl: no such file or directory\n", 2},
+		{[]string{"-comments", "x"}, "", "", "hclfmt: unknown comment style \"x\"\n", 2},
+		{[]string{"-w"}, "a=1", "", "hclfmt: cannot use -w with the standard input\n", 2},
+	}
+
+	for _, c := range cases {
+		stdout, stderr, code := run(t, c.stdin, c.args...)
+		if stdout != c.stdout || stderr != c.stderr || code != c.code {
+			t.Errorf("%q: want exit code %d, output:\n%s\nerrors:\n%s\ngot exit code %d, output:\n%s\nerrors:\n%s",
+				c.args, c.code, c.stdout, c.stderr, code, stdout, stderr)
+		}
+	}
+}
+
+func TestCommandWrite(t *testing.T) {
+	dir, err := ioutil.TempDir("", "hclfmt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	src, err := ioutil.ReadFile("testdata/unformatted.hcl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	name := filepath.Join(dir, "a.hcl")
+	if err := ioutil.WriteFile(name, src, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if stdout, stderr, code := run(t, "", "-w", dir); stdout != "" || stderr != "" || code != 0 {
+		t.Fatalf("unexpected exit code %d, output %q, errors %q", code, stdout, stderr)
+	}
+
+	res, err := ioutil.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	golden, err := ioutil.ReadFile("testdata/unformatted.golden")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(res, golden) {
+		t.Errorf("want:\n%s\ngot:\n%s", golden, res)
+	}
+	info, err := os.Stat(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("the mode of the file changed to %v", info.Mode())
+	}
+
+	// the file is formatted now
+	if stdout, _, code := run(t, "", "-l", name); stdout != "" || code != 0 {
+		t.Errorf("unexpected exit code %d, output %q", code, stdout)
+	}
+}
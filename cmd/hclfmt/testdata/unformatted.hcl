@@ -0,0 +1,4 @@
+a    =   1
+b {
+c = "x" # comment
+}
@@ -0,0 +1,176 @@
+// Command hcllint checks HCL (HashiCorp Configuration Language) files with
+// the rules of package lint.
+//
+// Usage:
+//
+//	hcllint [flags] [path ...]
+//
+// Without paths, it checks the standard input. Directories are processed
+// recursively, checking all files with the extension ".hcl". Issues are
+// printed one per line as "file:line:column: message (rule)".
+//
+// The flags are:
+//
+//	-disable rules
+//		comma-separated names of rules not to run
+//	-enable rules
+//		comma-separated names of the rules to run, instead of all of them
+//	-list	list the names of the rules and exit
+//
+// The exit code is 1 if issues were found and 2 if a file can't be read or
+// parsed. Rules of third parties are run by a copy of this command importing
+// the packages registering them.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fatih/hcl/lint"
+	"github.com/fatih/hcl/parser"
+)
+
+var (
+	enable  = flag.String("enable", "", "comma-separated names of the `rules` to run, instead of all of them")
+	disable = flag.String("disable", "", "comma-separated names of `rules` not to run")
+	list    = flag.Bool("list", false, "list the names of the rules and exit")
+)
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: hcllint [flags] [path ...]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if *list {
+		for _, r := range lint.Rules() {
+			fmt.Println(r.Name())
+		}
+		return
+	}
+
+	rules, err := selectRules(*enable, *disable)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "hcllint: %s\n", err)
+		os.Exit(2)
+	}
+
+	if flag.NArg() == 0 {
+		found, err := check("<standard input>", os.Stdin, os.Stdout, rules)
+		os.Exit(exitCode(found, err))
+	}
+
+	code := 0
+	for _, path := range flag.Args() {
+		err := filepath.Walk(path, func(name string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+
+			// named files are checked regardless of their extension
+			if info.IsDir() || name != path && filepath.Ext(name) != ".hcl" {
+				return nil
+			}
+
+			if c := exitCode(checkFile(name, rules)); c > code {
+				code = c
+			}
+			return nil
+		})
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			code = 2
+		}
+	}
+	os.Exit(code)
+}
+
+// selectRules returns the registered rules named by enable, or all of them,
+// without the ones named by disable
+func selectRules(enable, disable string) ([]lint.Rule, error) {
+	rules := lint.Rules()
+	if enable != "" {
+		rules = nil
+		for _, name := range strings.Split(enable, ",") {
+			r := lint.Lookup(strings.TrimSpace(name))
+			if r == nil {
+				return nil, fmt.Errorf("unknown rule %q", name)
+			}
+			rules = append(rules, r)
+		}
+	}
+
+	skip := make(map[string]bool)
+	if disable != "" {
+		for _, name := range strings.Split(disable, ",") {
+			name = strings.TrimSpace(name)
+			if lint.Lookup(name) == nil {
+				return nil, fmt.Errorf("unknown rule %q", name)
+			}
+			skip[name] = true
+		}
+	}
+
+	var res []lint.Rule
+	for _, r := range rules {
+		if !skip[r.Name()] {
+			res = append(res, r)
+		}
+	}
+	if len(res) == 0 {
+		return nil, fmt.Errorf("no rules to run")
+	}
+	return res, nil
+}
+
+// exitCode returns the exit code for the result of checking a file and
+// reports the error, if any
+func exitCode(found bool, err error) int {
+	switch {
+	case err != nil:
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	case found:
+		return 1
+	}
+	return 0
+}
+
+func checkFile(name string, rules []lint.Rule) (bool, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+
+	return check(name, f, os.Stdout, rules)
+}
+
+// check prints the issues of the HCL read from in to out and reports whether
+// there were any
+func check(name string, in io.Reader, out io.Writer, rules []lint.Rule) (bool, error) {
+	src, err := ioutil.ReadAll(in)
+	if err != nil {
+		return false, err
+	}
+
+	f, err := parser.Parse(src)
+	if err != nil {
+		return false, fmt.Errorf("%s: %s", name, err)
+	}
+
+	issues := lint.Lint(f, rules...)
+	for _, issue := range issues {
+		issue.Pos.Filename = name
+		if _, err := fmt.Fprintln(out, issue); err != nil {
+			return false, err
+		}
+	}
+	return len(issues) > 0, nil
+}
@@ -0,0 +1,63 @@
+//go:build js && wasm
+// +build js,wasm
+
+// Command hclwasm exposes the HCL parser and printer to JavaScript, i.e. to
+// validate config files in the browser. It only depends on the lexer, parser
+// and printer packages and compiles with TinyGo as well:
+//
+//	GOOS=js GOARCH=wasm go build -o hcl.wasm ./cmd/hclwasm
+//	tinygo build -o hcl.wasm -target wasm ./cmd/hclwasm
+//
+// It registers the global functions hclValidate(src) and hclFormat(src).
+// Both return an object with the properties "error", which is null if src
+// is valid, and "line" and "column" of the error. hclFormat additionally
+// returns the formatted source as "output".
+package main
+
+import (
+	"syscall/js"
+
+	"github.com/fatih/hcl/parser"
+	"github.com/fatih/hcl/printer"
+)
+
+func main() {
+	js.Global().Set("hclValidate", js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		_, err := parser.Parse([]byte(source(args)))
+		return result(err)
+	}))
+
+	js.Global().Set("hclFormat", js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		out, err := printer.Format([]byte(source(args)))
+		res := result(err)
+		if err == nil {
+			res["output"] = string(out)
+		}
+		return res
+	}))
+
+	// keep the functions available
+	select {}
+}
+
+func source(args []js.Value) string {
+	if len(args) == 0 {
+		return ""
+	}
+	return args[0].String()
+}
+
+// result returns the JavaScript object reporting err
+func result(err error) map[string]interface{} {
+	res := map[string]interface{}{"error": nil}
+	if err == nil {
+		return res
+	}
+
+	res["error"] = err.Error()
+	if perr, ok := err.(*parser.PosError); ok {
+		res["line"] = perr.Pos.Line
+		res["column"] = perr.Pos.Column
+	}
+	return res
+}
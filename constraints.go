@@ -0,0 +1,156 @@
+package hcl
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fatih/hcl/token"
+)
+
+// constraint is an option of the "hcl" tag constraining the decoded value of
+// its field, i.e. min=1 of `hcl:"port,min=1"`
+type constraint struct {
+	name string // min, max or oneof
+	arg  string
+}
+
+func (c constraint) String() string {
+	return c.name + "=" + c.arg
+}
+
+// constraints returns the constraints of the options of f
+func (f *structField) constraints() []constraint {
+	var cs []constraint
+	for _, o := range f.options {
+		if i := strings.Index(o, "="); i > 0 {
+			cs = append(cs, constraint{name: o[:i], arg: o[i+1:]})
+		}
+	}
+	return cs
+}
+
+// checkConstraints checks the value rv of field, decoded from the value at
+// pos, against the constraints of its tag. min and max bound numbers, and the
+// length of strings, slices and maps; oneof lists the allowed strings or
+// numbers separated by "|". The bounds of time.Duration fields are durations,
+// i.e. max=1h. Nil pointers aren't checked.
+func (d *decoder) checkConstraints(name string, pos token.Pos, field *structField, rv reflect.Value) error {
+	cs := field.constraints()
+	if len(cs) == 0 {
+		return nil
+	}
+
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return nil
+		}
+		rv = rv.Elem()
+	}
+
+	for _, c := range cs {
+		var violation string
+		var err error
+		switch c.name {
+		case "min", "max":
+			violation, err = checkBound(c, rv)
+		case "oneof":
+			violation, err = checkOneOf(c, rv)
+		default:
+			err = fmt.Errorf("unknown constraint %s", c.name)
+		}
+
+		if err != nil {
+			return posErrorf(pos, "%s: field %s has an invalid constraint %s: %s", name, field.Name, c, err)
+		}
+		if violation != "" {
+			return posErrorf(pos, "%s: %s", name, violation)
+		}
+	}
+	return nil
+}
+
+// checkBound checks the constraint min or max of rv and returns the
+// violation, if any, or an error if c doesn't apply to rv
+func checkBound(c constraint, rv reflect.Value) (string, error) {
+	var v, bound float64
+	var err error
+	length := false
+
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v = float64(rv.Int())
+		if rv.Type() == durationType {
+			var d time.Duration
+			d, err = time.ParseDuration(c.arg)
+			bound = float64(d)
+		} else {
+			bound, err = strconv.ParseFloat(c.arg, 64)
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		v = float64(rv.Uint())
+		bound, err = strconv.ParseFloat(c.arg, 64)
+	case reflect.Float32, reflect.Float64:
+		v = rv.Float()
+		bound, err = strconv.ParseFloat(c.arg, 64)
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		length = true
+		v = float64(rv.Len())
+		var n int
+		n, err = strconv.Atoi(c.arg)
+		if err == nil && n < 0 {
+			err = fmt.Errorf("negative length")
+		}
+		bound = float64(n)
+	default:
+		return "", fmt.Errorf("want a number, string, slice or map, got: %s", rv.Type())
+	}
+	if err != nil {
+		if ne, ok := err.(*strconv.NumError); ok {
+			err = ne.Err
+		}
+		return "", err
+	}
+
+	if c.name == "min" && v >= bound || c.name == "max" && v <= bound {
+		return "", nil
+	}
+
+	what := fmt.Sprint(rv.Interface())
+	if length {
+		what = "length " + strconv.Itoa(rv.Len())
+	}
+	if c.name == "min" {
+		return what + " is less than the minimum " + c.arg, nil
+	}
+	return what + " is greater than the maximum " + c.arg, nil
+}
+
+// checkOneOf checks the constraint oneof of rv like checkBound
+func checkOneOf(c constraint, rv reflect.Value) (string, error) {
+	var v string
+	switch rv.Kind() {
+	case reflect.String:
+		v = rv.String()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		v = fmt.Sprint(rv.Interface())
+	default:
+		return "", fmt.Errorf("want a string or a number, got: %s", rv.Type())
+	}
+
+	allowed := strings.Split(c.arg, "|")
+	for _, a := range allowed {
+		if a == v {
+			return "", nil
+		}
+	}
+
+	if rv.Kind() == reflect.String {
+		v = strconv.Quote(v)
+	}
+	return v + " is not one of " + strings.Join(allowed, ", "), nil
+}
@@ -0,0 +1,88 @@
+package hcl
+
+import (
+	"testing"
+	"time"
+)
+
+type constrained struct {
+	Name    string        `hcl:"name,required"`
+	Port    int           `hcl:"port,min=1,max=65535"`
+	Mode    string        `hcl:"mode,oneof=fast|safe"`
+	Ratio   *float64      `hcl:"ratio,min=0,max=1"`
+	Timeout time.Duration `hcl:"timeout,max=1m"`
+	Tags    []string      `hcl:"tags,min=1,max=2"`
+	Level   uint          `hcl:"level,oneof=1|2|3"`
+}
+
+func TestDecodeConstraints(t *testing.T) {
+	cases := []struct {
+		src string
+		err string
+	}{
+		{`name = "a" port = 80 mode = "fast" ratio = 0.5 timeout = "30s" tags = ["x"] level = 2`, ""},
+		{`name = "a"`, ""},
+		{`port = 80`, `At 1:1: root.name: missing required attribute`},
+		{"name = \"a\"\nport = 0", `At 2:8: root.port: 0 is less than the minimum 1`},
+		{"name = \"a\"\nport = 70000", `At 2:8: root.port: 70000 is greater than the maximum 65535`},
+		{"name = \"a\"\nport = 8080\nport = 0", `At 3:8: root.port: 0 is less than the minimum 1`},
+		{`name = "a" mode = "slow"`, `At 1:19: root.mode: "slow" is not one of fast, safe`},
+		{`name = "a" ratio = 1.5`, `At 1:20: root.ratio: 1.5 is greater than the maximum 1`},
+		{`name = "a" timeout = "2m"`, `At 1:22: root.timeout: 2m0s is greater than the maximum 1m`},
+		{`name = "a" tags = []`, `At 1:19: root.tags: length 0 is less than the minimum 1`},
+		{`name = "a" tags = ["x", "y"] tags = ["z"]`, `At 1:37: root.tags: length 3 is greater than the maximum 2`},
+		{`name = "a" level = 4`, `At 1:20: root.level: 4 is not one of 1, 2, 3`},
+	}
+
+	for _, c := range cases {
+		var out constrained
+		err := Decode(&out, c.src)
+		if c.err == "" && err != nil || c.err != "" && (err == nil || err.Error() != c.err) {
+			t.Errorf("%s:\nwant: %s\ngot:  %v", c.src, c.err, err)
+		}
+	}
+}
+
+func TestDecodeConstraintsBlocks(t *testing.T) {
+	type service struct {
+		Name string `hcl:",key"`
+		Port int    `hcl:"port,required"`
+	}
+	var out struct {
+		Services []service `hcl:"service"`
+	}
+
+	src := "service \"web\" {\n  port = 80\n}\n\nservice \"db\" {\n  host = \"x\"\n}\n"
+	err := Decode(&out, src)
+	if err == nil || err.Error() != `At 6:3: root.service[1].port: missing required attribute` {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestDecodeConstraintsInvalid(t *testing.T) {
+	cases := []struct {
+		out interface{}
+		src string
+		err string
+	}{
+		{&struct {
+			Port int `hcl:"port,min=x"`
+		}{}, "port = 1", `At 1:8: root.port: field Port has an invalid constraint min=x: invalid syntax`},
+		{&struct {
+			Port int `hcl:"port,step=2"`
+		}{}, "port = 1", `At 1:8: root.port: field Port has an invalid constraint step=2: unknown constraint step`},
+		{&struct {
+			Port struct{} `hcl:"port,max=1"`
+		}{}, "port {}", `At 1:6: root.port: field Port has an invalid constraint max=1: want a number, string, slice or map, got: struct {}`},
+		{&struct {
+			Port []int `hcl:"port,oneof=1|2"`
+		}{}, "port = 1", `At 1:8: root.port: field Port has an invalid constraint oneof=1|2: want a string or a number, got: []int`},
+	}
+
+	for _, c := range cases {
+		err := Decode(c.out, c.src)
+		if err == nil || err.Error() != c.err {
+			t.Errorf("%s:\nwant: %s\ngot:  %v", c.src, c.err, err)
+		}
+	}
+}
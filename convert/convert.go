@@ -0,0 +1,187 @@
+// Package convert coerces Go values decoded from HCL (HashiCorp Configuration
+// Language) with the rules of the decoder of package hcl, i.e. to apply the
+// same coercions to values decoded into an empty interface.
+//
+// Values are the ones the decoder produces for an empty interface: string,
+// int, int64, float64, bool, []interface{} and map[string]interface{}.
+// Strings convert to numbers and bools if they contain one, numbers and bools
+// convert to strings.
+package convert
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/fatih/hcl/value"
+)
+
+// TypeError is returned if a value can't be converted to a type.
+type TypeError struct {
+	Value interface{}
+	Type  string // name of the wanted type, i.e. "int"
+}
+
+func (e *TypeError) Error() string {
+	return fmt.Sprintf("cannot convert %s to %s", TypeName(e.Value), e.Type)
+}
+
+// RangeError is returned if a number is out of the range of an integer type.
+type RangeError struct {
+	Value int64
+	Type  string // name of the wanted type, i.e. "int8"
+}
+
+func (e *RangeError) Error() string {
+	return fmt.Sprintf("%d overflows %s", e.Value, e.Type)
+}
+
+// ToString converts v to a string. Numbers and bools are formatted.
+func ToString(v interface{}) (string, error) {
+	val, err := primitive(v, value.String, "string")
+	if err != nil {
+		return "", err
+	}
+	return val.AsString(), nil
+}
+
+// ToBool converts v to a bool. The only strings converting are "true" and
+// "false".
+func ToBool(v interface{}) (bool, error) {
+	val, err := primitive(v, value.Bool, "bool")
+	if err != nil {
+		return false, err
+	}
+	return val.True(), nil
+}
+
+// ToFloat64 converts v to a float64. Strings are parsed.
+func ToFloat64(v interface{}) (float64, error) {
+	val, err := primitive(v, value.Number, "float64")
+	if err != nil {
+		return 0, err
+	}
+	return val.AsFloat64(), nil
+}
+
+// ToInt converts v to a signed integer of the given bit size, as in
+// strconv.ParseInt. 0 is the size of int. Floats must not have a fractional
+// part. Numbers out of range are a *RangeError.
+func ToInt(v interface{}, bitSize int) (int64, error) {
+	name := intName("int", bitSize)
+	i, err := integer(v, name)
+	if err != nil {
+		return 0, err
+	}
+
+	bits := uint(size(bitSize))
+	if bits < 64 && (i < -1<<(bits-1) || i >= 1<<(bits-1)) {
+		return 0, &RangeError{Value: i, Type: name}
+	}
+	return i, nil
+}
+
+// ToUint converts v to an unsigned integer of the given bit size, as in
+// strconv.ParseUint. 0 is the size of uint. Negative numbers are a
+// *RangeError.
+func ToUint(v interface{}, bitSize int) (uint64, error) {
+	name := intName("uint", bitSize)
+	i, err := integer(v, name)
+	if err != nil {
+		return 0, err
+	}
+
+	bits := uint(size(bitSize))
+	if i < 0 || bits < 64 && uint64(i) >= 1<<bits {
+		return 0, &RangeError{Value: i, Type: name}
+	}
+	return uint64(i), nil
+}
+
+// ToList converts v to a list. Lists are returned as they are, other values
+// become a list of one element, as the decoder does for slices.
+func ToList(v interface{}) []interface{} {
+	if list, ok := v.([]interface{}); ok {
+		return list
+	}
+	return []interface{}{v}
+}
+
+// ToScalar converts a list of one element to the element, the reverse of
+// ToList. Other values than lists are returned as they are.
+func ToScalar(v interface{}) (interface{}, error) {
+	list, ok := v.([]interface{})
+	if !ok {
+		return v, nil
+	}
+
+	if len(list) != 1 {
+		return nil, &TypeError{Value: v, Type: "a single value"}
+	}
+	return list[0], nil
+}
+
+// ToMap converts v to a map. Only maps convert, as the decoder decodes maps
+// from objects only.
+func ToMap(v interface{}) (map[string]interface{}, error) {
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, &TypeError{Value: v, Type: "object"}
+	}
+	return m, nil
+}
+
+// TypeName returns the name of the type of v as used in error messages, i.e.
+// "string", "number", "list", "object" or "null".
+func TypeName(v interface{}) string {
+	val, err := value.FromGo(v)
+	switch {
+	case v == nil:
+		return "null"
+	case err != nil:
+		return fmt.Sprintf("%T", v)
+	case val.Type().Kind() == value.MapKind:
+		return "object"
+	}
+	return val.Type().FriendlyName()
+}
+
+// primitive converts a primitive Go value to a known value of type want
+func primitive(v interface{}, want value.Type, name string) (value.Value, error) {
+	val, err := value.FromGo(v)
+	if err != nil || !val.Type().IsPrimitive() || val.IsNull() {
+		return value.Value{}, &TypeError{Value: v, Type: name}
+	}
+
+	if val, err = value.Convert(val, want); err != nil {
+		return value.Value{}, &TypeError{Value: v, Type: name}
+	}
+	return val, nil
+}
+
+func integer(v interface{}, name string) (int64, error) {
+	val, err := primitive(v, value.Number, name)
+	if err != nil {
+		return 0, err
+	}
+
+	i, err := val.AsInt64()
+	if err != nil {
+		return 0, &TypeError{Value: v, Type: name}
+	}
+	return i, nil
+}
+
+// size returns the bit size of an integer type, 0 is the size of int
+func size(bitSize int) int {
+	if bitSize == 0 {
+		return strconv.IntSize
+	}
+	return bitSize
+}
+
+func intName(prefix string, bitSize int) string {
+	if bitSize == 0 {
+		return prefix
+	}
+	return fmt.Sprintf("%s%d", prefix, bitSize)
+}
@@ -0,0 +1,93 @@
+package convert
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestConvert(t *testing.T) {
+	cases := []struct {
+		fn       func(interface{}) (interface{}, error)
+		in       interface{}
+		expected interface{}
+		err      string
+	}{
+		{str, "a", "a", ""},
+		{str, 42, "42", ""},
+		{str, 1.5, "1.5", ""},
+		{str, true, "true", ""},
+		{str, []interface{}{"a"}, nil, "cannot convert list to string"},
+		{str, map[string]interface{}{}, nil, "cannot convert object to string"},
+		{str, nil, nil, "cannot convert null to string"},
+		{boolean, "true", true, ""},
+		{boolean, "yes", nil, `cannot convert string to bool`},
+		{boolean, 1, nil, `cannot convert number to bool`},
+		{float, "1.5", 1.5, ""},
+		{float, 2, 2.0, ""},
+		{float, "x", nil, "cannot convert string to float64"},
+		{int8, "42", int64(42), ""},
+		{int8, 2.0, int64(2), ""},
+		{int8, 2.5, nil, "cannot convert number to int8"},
+		{int8, 300, nil, "300 overflows int8"},
+		{int8, -128, int64(-128), ""},
+		{uint0, -1, nil, "-1 overflows uint"},
+		{uint0, "0x10", uint64(16), ""},
+		{scalar, []interface{}{1}, 1, ""},
+		{scalar, "a", "a", ""},
+		{scalar, []interface{}{1, 2}, nil, "cannot convert list to a single value"},
+	}
+
+	for _, c := range cases {
+		got, err := c.fn(c.in)
+		if c.err != "" {
+			if err == nil || err.Error() != c.err {
+				t.Errorf("%#v: want error %q, got: %v", c.in, c.err, err)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("%#v: %s", c.in, err)
+			continue
+		}
+
+		if !reflect.DeepEqual(got, c.expected) {
+			t.Errorf("%#v: want: %#v, got: %#v", c.in, c.expected, got)
+		}
+	}
+}
+
+func TestErrorTypes(t *testing.T) {
+	if _, err := ToInt(300, 8); !isRangeError(err) {
+		t.Errorf("want a *RangeError, got: %#v", err)
+	}
+
+	if _, err := ToInt("x", 8); isRangeError(err) {
+		t.Errorf("want a *TypeError, got: %#v", err)
+	} else if e, ok := err.(*TypeError); !ok || e.Value != "x" || e.Type != "int8" {
+		t.Errorf("unexpected type error: %#v", err)
+	}
+}
+
+func TestToList(t *testing.T) {
+	if got := ToList("a"); !reflect.DeepEqual(got, []interface{}{"a"}) {
+		t.Errorf("want a list of one, got: %#v", got)
+	}
+
+	list := []interface{}{1, 2}
+	if got := ToList(list); !reflect.DeepEqual(got, list) {
+		t.Errorf("want the list, got: %#v", got)
+	}
+}
+
+func isRangeError(err error) bool {
+	_, ok := err.(*RangeError)
+	return ok
+}
+
+func str(v interface{}) (interface{}, error)     { return ToString(v) }
+func boolean(v interface{}) (interface{}, error) { return ToBool(v) }
+func float(v interface{}) (interface{}, error)   { return ToFloat64(v) }
+func int8(v interface{}) (interface{}, error)    { return ToInt(v, 8) }
+func uint0(v interface{}) (interface{}, error)   { return ToUint(v, 0) }
+func scalar(v interface{}) (interface{}, error)  { return ToScalar(v) }
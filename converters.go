@@ -0,0 +1,92 @@
+package hcl
+
+import (
+	"errors"
+	"net"
+	"net/url"
+	"path/filepath"
+	"reflect"
+	"time"
+
+	"github.com/fatih/hcl/token"
+)
+
+// Converter converts a string into a value of the type it's registered for
+// in DecoderConfig.Converters, i.e. "10s" into a time.Duration, or of the
+// fields it's the parser of, see DecoderConfig.RegisterParser.
+type Converter func(s string) (interface{}, error)
+
+var (
+	timeType = reflect.TypeOf(time.Time{})
+	ipType   = reflect.TypeOf(net.IP{})
+	urlType  = reflect.TypeOf(url.URL{})
+)
+
+// builtinConverters convert strings into types the decoder can't decode
+// strings into otherwise
+var builtinConverters = map[reflect.Type]Converter{
+	durationType: func(s string) (interface{}, error) {
+		return time.ParseDuration(s)
+	},
+	timeType: func(s string) (interface{}, error) {
+		return time.Parse(time.RFC3339, s)
+	},
+	ipType: func(s string) (interface{}, error) {
+		ip := net.ParseIP(s)
+		if ip == nil {
+			return nil, errors.New("invalid IP address")
+		}
+		return ip, nil
+	},
+	urlType: func(s string) (interface{}, error) {
+		u, err := url.Parse(s)
+		if err != nil {
+			return nil, err
+		}
+		return *u, nil
+	},
+	pathType: ParsePath,
+	globType: ParseGlob,
+}
+
+// converter returns the converter of strings into values of type t, or nil
+func (c *DecoderConfig) converter(t reflect.Type) Converter {
+	if conv, ok := c.Converters[t]; ok {
+		return conv
+	}
+	return builtinConverters[t]
+}
+
+// convertString decodes the string s into rv with conv
+func (d *decoder) convertString(name string, pos token.Pos, conv Converter, s string, rv reflect.Value) error {
+	v, err := conv(s)
+	if err != nil {
+		return posErrorf(pos, "%s: cannot convert %q into %s: %s", name, s, rv.Type(), err)
+	}
+
+	val := reflect.ValueOf(v)
+	if !val.IsValid() || !val.Type().AssignableTo(rv.Type()) {
+		return posErrorf(pos, "%s: converter into %s returned %T", name, rv.Type(), v)
+	}
+	rv.Set(val)
+	return nil
+}
+
+// formatString returns the string a builtin converter converts into the value
+// of rv, so values of these types are encoded as strings
+func formatString(rv reflect.Value) (string, bool) {
+	switch rv.Type() {
+	case durationType:
+		return time.Duration(rv.Int()).String(), true
+	case timeType:
+		return rv.Interface().(time.Time).Format(time.RFC3339Nano), true
+	case ipType:
+		return rv.Interface().(net.IP).String(), true
+	case urlType:
+		u := rv.Interface().(url.URL)
+		return u.String(), true
+	case pathType, globType:
+		return filepath.ToSlash(rv.String()), true
+	}
+	return "", false
+}
@@ -0,0 +1,146 @@
+package hcl
+
+import (
+	"errors"
+	"net"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fatih/hcl/parser"
+)
+
+type endpoint struct {
+	Timeout  time.Duration   `hcl:"timeout"`
+	Retries  []time.Duration `hcl:"retries"`
+	Expires  *time.Time      `hcl:"expires"`
+	Address  net.IP          `hcl:"address"`
+	Allowed  []net.IP        `hcl:"allowed"`
+	URL      *url.URL        `hcl:"url"`
+	Mirror   url.URL         `hcl:"mirror"`
+	Unparsed string          `hcl:"unparsed"`
+}
+
+func TestDecodeConverters(t *testing.T) {
+	src := `timeout = "1m30s"
+retries = ["1s", "5s"]
+expires = "2026-01-02T15:04:05Z"
+address = "10.0.0.1"
+allowed = ["::1", "192.168.0.1"]
+url = "https://example.com/api?v=1"
+mirror = "https://mirror.example.com"
+unparsed = "10s"
+`
+
+	var out endpoint
+	if err := Decode(&out, src); err != nil {
+		t.Fatal(err)
+	}
+
+	expires := time.Date(2026, 1, 2, 15, 4, 5, 0, time.UTC)
+	want := endpoint{
+		Timeout:  90 * time.Second,
+		Retries:  []time.Duration{time.Second, 5 * time.Second},
+		Expires:  &expires,
+		Address:  net.ParseIP("10.0.0.1"),
+		Allowed:  []net.IP{net.ParseIP("::1"), net.ParseIP("192.168.0.1")},
+		URL:      &url.URL{Scheme: "https", Host: "example.com", Path: "/api", RawQuery: "v=1"},
+		Mirror:   url.URL{Scheme: "https", Host: "mirror.example.com"},
+		Unparsed: "10s",
+	}
+	if !reflect.DeepEqual(out, want) {
+		t.Errorf("want %+v\ngot  %+v", want, out)
+	}
+
+	// encoded as strings again
+	res, err := Marshal(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var again endpoint
+	if err := Decode(&again, string(res)); err != nil {
+		t.Fatalf("%s\n%s", err, res)
+	}
+	if !reflect.DeepEqual(again, want) {
+		t.Errorf("round trip: want %+v\ngot  %+v", want, again)
+	}
+}
+
+func TestDecodeConvertersError(t *testing.T) {
+	cases := []struct {
+		src string
+		err string
+	}{
+		{`timeout = "soon"`, `At 1:11: root.timeout: cannot convert "soon" into time.Duration: time: invalid duration "soon"`},
+		{`expires = "tomorrow"`, `At 1:11: root.expires: cannot convert "tomorrow" into time.Time: `},
+		{`allowed = ["::1", "localhost"]`, `At 1:19: root.allowed[1]: cannot convert "localhost" into net.IP: invalid IP address`},
+		{`url = "http://[::1"`, `At 1:7: root.url: cannot convert "http://[::1" into url.URL: `},
+	}
+
+	for _, c := range cases {
+		var out endpoint
+		err := Decode(&out, c.src)
+		if err == nil || !strings.HasPrefix(err.Error(), c.err) {
+			t.Errorf("%s: want error %q, got: %v", c.src, c.err, err)
+		}
+
+		f, perr := parser.Parse([]byte(c.src))
+		if perr != nil {
+			t.Fatal(perr)
+		}
+		if errs := CheckTypes(f, out); len(errs) != 1 || !strings.HasPrefix(errs[0].Error(), c.err) {
+			t.Errorf("%s: want check error %q, got: %v", c.src, c.err, errs)
+		}
+	}
+}
+
+func TestDecoderConfigConverters(t *testing.T) {
+	type config struct {
+		Network *net.IPNet `hcl:"network"`
+		Level   level      `hcl:"level"`
+	}
+
+	dc := &DecoderConfig{Converters: map[reflect.Type]Converter{
+		reflect.TypeOf(net.IPNet{}): func(s string) (interface{}, error) {
+			_, n, err := net.ParseCIDR(s)
+			if err != nil {
+				return nil, err
+			}
+			return *n, nil
+		},
+		reflect.TypeOf(level(0)): func(s string) (interface{}, error) {
+			for i, name := range []string{"debug", "info", "error"} {
+				if s == name {
+					return level(i), nil
+				}
+			}
+			return nil, errors.New("unknown level")
+		},
+	}}
+
+	var out config
+	if err := dc.Decode(&out, `network = "10.0.0.0/8"
+level = "error"`); err != nil {
+		t.Fatal(err)
+	}
+	if out.Network.String() != "10.0.0.0/8" || out.Level != 2 {
+		t.Errorf("unexpected result: %v %d", out.Network, out.Level)
+	}
+
+	err := dc.Decode(&out, `level = "fatal"`)
+	if err == nil || err.Error() != `At 1:9: root.level: cannot convert "fatal" into hcl.level: unknown level` {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	// the type returned by a converter must match
+	dc.Converters[reflect.TypeOf(level(0))] = func(s string) (interface{}, error) { return s, nil }
+	err = dc.Decode(&out, `level = "info"`)
+	if err == nil || err.Error() != "At 1:9: root.level: converter into hcl.level returned string" {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+type level int
@@ -0,0 +1,144 @@
+package hcl
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/fatih/hcl/ast"
+)
+
+// ErrNotFound is wrapped by the errors of DecodePath and ExtractPath for a
+// path without items, so optional blocks can be told from invalid ones with
+// errors.Is.
+var ErrNotFound = errors.New("not found")
+
+// DecodePath parses src and decodes the value at path into out with
+// DefaultDecoderConfig, see DecoderConfig.DecodePath.
+func DecodePath(out interface{}, path string, src string) error {
+	return DefaultDecoderConfig.DecodePath(out, path, src)
+}
+
+// ExtractPath decodes the value at path of f into out with
+// DefaultDecoderConfig and removes it from f, see DecoderConfig.ExtractPath.
+func ExtractPath(out interface{}, path string, f *ast.File) error {
+	return DefaultDecoderConfig.ExtractPath(out, path, f)
+}
+
+// DecodePath parses src and decodes only the value at path into out, i.e.
+// the body of backend {} for the path "backend", or the one of service "web"
+// {} for "service.web", so large files don't materialize the values of all
+// other items. The value is decoded like a struct field of the key at path:
+// the items with the same keys are merged or appended, and the remaining
+// labels of blocks nest objects, i.e. "service" decodes into a
+// map[string]Service or a []Service with a ",key" field. Keys are separated by
+// dots like the paths of errors, see ast.SplitPath. A path without items is
+// an error wrapping ErrNotFound.
+func (c *DecoderConfig) DecodePath(out interface{}, path string, src string) error {
+	f, err := c.parse(src)
+	if err != nil {
+		return err
+	}
+	return c.decodePath(out, path, src, f, false)
+}
+
+// ExtractPath decodes the value at path of f into out like DecodePath, and
+// removes its items from f, so f keeps the rest of the tree for later
+// processing, i.e. by DecodeObject with another schema or by the printer. f
+// is only modified if the decoding succeeds.
+func (c *DecoderConfig) ExtractPath(out interface{}, path string, f *ast.File) error {
+	return c.decodePath(out, path, "", f, true)
+}
+
+// decodePath decodes the value at path of f, parsed from src if it's known,
+// into out
+func (c *DecoderConfig) decodePath(out interface{}, path, src string, f *ast.File, remove bool) error {
+	keys := ast.SplitPath(path)
+	if len(keys) == 0 {
+		return errors.New("path is empty")
+	}
+
+	list, ok := f.Node.(*ast.ObjectList)
+	if !ok {
+		return fmt.Errorf("root: expected an object list, got: %T", f.Node)
+	}
+
+	name := "root." + ast.JoinPath(keys)
+	matches := lookupPath(list, keys)
+	if len(matches) == 0 {
+		return fmt.Errorf("%s: %w", name, ErrNotFound)
+	}
+
+	nodes := make([]ast.Node, len(matches))
+	for i, m := range matches {
+		nodes[i] = m.value()
+	}
+
+	err := c.run(out, func(d *decoder, rv reflect.Value) error {
+		d.src = src
+		return d.decodeNodes(name, nodes, rv)
+	})
+	if err != nil || !remove {
+		return err
+	}
+
+	for _, m := range matches {
+		items := m.list.Items[:0]
+		for _, item := range m.list.Items {
+			if item != m.item {
+				items = append(items, item)
+			}
+		}
+		m.list.Items = items
+	}
+	return nil
+}
+
+// pathMatch is an item at a path
+type pathMatch struct {
+	list *ast.ObjectList // list containing the item
+	item *ast.ObjectItem
+	keys int // number of keys of the item on the path
+}
+
+// value returns the value of the item at the path, with the remaining keys
+// nested into objects like labelBody
+func (m pathMatch) value() ast.Node {
+	if m.keys == len(m.item.Keys) {
+		return m.item.Val
+	}
+
+	rest := m.item.Keys[m.keys:]
+	return &ast.ObjectType{
+		Lbrace: rest[0].Pos(),
+		List: &ast.ObjectList{
+			Items: []*ast.ObjectItem{{Keys: rest, Val: m.item.Val}},
+		},
+	}
+}
+
+// lookupPath returns the items of list at the unquoted keys of path, in
+// source order. An item matches if its keys start with the path, i.e.
+// service "web" "v2" {} for "service.web", or if the path starts with its
+// keys and an item of its object matches the rest of the path.
+func lookupPath(list *ast.ObjectList, path []string) []pathMatch {
+	var matches []pathMatch
+	for _, item := range list.Items {
+		n := 0
+		for n < len(item.Keys) && n < len(path) && item.Keys[n].Token.Value() == path[n] {
+			n++
+		}
+
+		switch {
+		case n == 0 || n < len(item.Keys) && n < len(path):
+			// the keys differ
+		case n == len(path):
+			matches = append(matches, pathMatch{list: list, item: item, keys: n})
+		default:
+			if obj, ok := item.Val.(*ast.ObjectType); ok && obj.List != nil {
+				matches = append(matches, lookupPath(obj.List, path[n:])...)
+			}
+		}
+	}
+	return matches
+}
@@ -0,0 +1,128 @@
+package hcl
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/fatih/hcl/printer"
+)
+
+const decodePathSource = `name = "app"
+
+backend "s3" {
+  bucket = "state"
+}
+
+service "web" {
+  port = 80
+}
+
+service {
+  db {
+    port = 5432
+  }
+}
+`
+
+func TestDecodePath(t *testing.T) {
+	type service struct {
+		Name string `hcl:",key"`
+		Port int    `hcl:"port"`
+	}
+
+	var web service
+	if err := DecodePath(&web, "service.web", decodePathSource); err != nil {
+		t.Fatal(err)
+	}
+	if web.Port != 80 {
+		t.Errorf("unexpected service: %+v", web)
+	}
+
+	var services map[string]service
+	if err := DecodePath(&services, "service", decodePathSource); err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]service{"web": {"web", 80}, "db": {"db", 5432}}; !reflect.DeepEqual(services, want) {
+		t.Errorf("want %+v, got %+v", want, services)
+	}
+
+	var backend map[string]map[string]string
+	if err := DecodePath(&backend, "backend", decodePathSource); err != nil {
+		t.Fatal(err)
+	}
+	if backend["s3"]["bucket"] != "state" {
+		t.Errorf("unexpected backend: %v", backend)
+	}
+
+	var bucket string
+	if err := DecodePath(&bucket, "backend.s3.bucket", decodePathSource); err != nil || bucket != "state" {
+		t.Errorf("unexpected bucket %q: %v", bucket, err)
+	}
+
+	var port string
+	err := DecodePath(&port, "service.db.port", `{"service": {"db": {"port": [1]}}}`)
+	if err == nil || err.Error() != "At 1:29: root.service.db.port: cannot decode list into string" {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestDecodePathNotFound(t *testing.T) {
+	var out interface{}
+	for _, path := range []string{"backend.gcs", "name.x", "services", `"service.web"`} {
+		err := DecodePath(&out, path, decodePathSource)
+		if !errors.Is(err, ErrNotFound) {
+			t.Errorf("%s: unexpected error: %v", path, err)
+		}
+	}
+
+	err := DecodePath(&out, "backend.gcs", decodePathSource)
+	if err == nil || err.Error() != "root.backend.gcs: not found" {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	if err := DecodePath(&out, "", decodePathSource); err == nil {
+		t.Error("expected an error for the empty path")
+	}
+}
+
+func TestExtractPath(t *testing.T) {
+	f, err := Parse([]byte(decodePathSource))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a failed decode leaves f unchanged
+	var bucket []int
+	if err := ExtractPath(&bucket, "backend.s3.bucket", f); err == nil {
+		t.Fatal("expected an error decoding a string into an int")
+	}
+
+	var backend map[string]interface{}
+	if err := ExtractPath(&backend, "backend", f); err != nil {
+		t.Fatal(err)
+	}
+	var port int
+	if err := ExtractPath(&port, "service.db.port", f); err != nil || port != 5432 {
+		t.Fatalf("unexpected port %d: %v", port, err)
+	}
+
+	var rest strings.Builder
+	if err := printer.Fprint(&rest, f); err != nil {
+		t.Fatal(err)
+	}
+	want := `name = "app"
+
+service "web" {
+  port = 80
+}
+
+service = {
+  db = {
+  }
+}`
+	if rest.String() != want {
+		t.Errorf("want:\n%s\ngot:\n%s", want, rest.String())
+	}
+}
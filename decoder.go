@@ -0,0 +1,1484 @@
+// Package hcl decodes HCL (HashiCorp Configuration Language) source into Go
+// values, similar to encoding/json.
+package hcl
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fatih/hcl/ast"
+	"github.com/fatih/hcl/convert"
+	jsonparser "github.com/fatih/hcl/json/parser"
+	"github.com/fatih/hcl/parser"
+	"github.com/fatih/hcl/token"
+)
+
+// DefaultDecoderConfig decodes strings with interpolations as they are, only
+// resolving their escapes.
+var DefaultDecoderConfig = DecoderConfig{}
+
+// Resolver resolves a string containing interpolations or directives, such
+// as "${var.password}", into the value assigned instead of the string. It
+// might evaluate the template with package eval or look up secrets.
+type Resolver func(t *ast.Template) (interface{}, error)
+
+// DecoderConfig configures the decoding of syntax trees into Go values.
+type DecoderConfig struct {
+	// Resolver, if not nil, is called for every string containing
+	// interpolations or directives before it's assigned. Otherwise such
+	// strings are assigned as they are, except for the escapes "$${" and
+	// "%%{", which stand for "${" and "%{" either way.
+	Resolver Resolver
+
+	// FoldCase matches keys to struct fields case-insensitively even if the
+	// field has a tag, i.e. for configurations migrated from systems
+	// ignoring case. An exact match is still preferred. Decode also parses
+	// the keywords true and false case-insensitively, see parser.Config.
+	FoldCase bool
+
+	// Duplicates is the handling of attributes assigned more than once in
+	// the same object by Decode and the other functions parsing the source,
+	// for HCL and JSON alike. See parser.DuplicatePolicy.
+	Duplicates parser.DuplicatePolicy
+
+	// Syntax is the syntax version of the HCL source parsed by Decode and
+	// the other functions parsing the source, i.e. parser.Experimental for
+	// null literals. JSON input has null either way.
+	Syntax parser.SyntaxVersion
+
+	// OnBlock, if not nil, is called for every block of an object decoded
+	// into a struct, map or empty interface, before the blocks of the object
+	// are decoded, i.e. to count blocks or reject experimental ones. typ is
+	// the block type and labels are the unquoted labels, i.e. "service" and
+	// ["web"] for service "web" {}. An error stops the decoding.
+	OnBlock func(typ string, labels []string, item *ast.ObjectItem) error
+
+	// Fields is the handling of struct fields matching a key, which can't
+	// be set: unexported fields and fields of func, chan and unsafe pointer
+	// types, including pointers, slices and maps of them.
+	Fields FieldPolicy
+
+	// Warn, if not nil, is called with the warnings of WarnFields. They're
+	// of type *parser.PosError.
+	Warn func(err error)
+
+	// Converters convert strings into values of the types they're
+	// registered for, i.e. to decode "10.0.0.0/8" into a *net.IPNet. They
+	// take precedence over the builtin conversions of strings into
+	// time.Duration, RFC 3339 time.Time, net.IP, url.URL, Path and Glob.
+	Converters map[reflect.Type]Converter
+
+	// Parsers maps names to the parsers of strings decoded into the struct
+	// fields with the tag parse:"name". See RegisterParser.
+	Parsers map[string]Converter
+
+	// Strict reports the keys of objects decoded into structs, which don't
+	// match any field, i.e. typos like porrt = 8080, with an
+	// *UnusedKeysError. Keys collected by fields with the tag options
+	// ",unknown" and ",unused" are used.
+	Strict bool
+
+	// MaxValues, MaxSliceLen and MaxMapSize limit the number of decoded
+	// values, i.e. elements, entries and fields, the length of slices and
+	// the size of maps, so untrusted input parsing fine can't allocate
+	// unbounded Go values. Decoding stops at the first limit exceeded with
+	// a *parser.PosError of a *parser.LimitError. Zero means no limit.
+	MaxValues   int
+	MaxSliceLen int
+	MaxMapSize  int
+
+	// Logger, if not nil, records every decode with its duration, and the
+	// parse of Decode like parser.Config.Logger.
+	Logger parser.Logger
+}
+
+// FieldPolicy is the handling of struct fields the decoder can't set.
+type FieldPolicy int
+
+const (
+	SkipFields   FieldPolicy = iota // skip them silently
+	WarnFields                      // skip them and report them to DecoderConfig.Warn
+	RejectFields                    // fail with an error
+)
+
+// Decode parses src, which is HCL or JSON as detected by Parse, and decodes
+// it into out. See DecoderConfig.DecodeObject.
+func (c *DecoderConfig) Decode(out interface{}, src string) error {
+	f, err := c.parse(src)
+	if err != nil {
+		return err
+	}
+
+	return c.run(out, func(d *decoder, rv reflect.Value) error {
+		d.src = src
+		return d.decode("root", f, rv)
+	})
+}
+
+// DecodeObject decodes the syntax tree n into out, which must be a non-nil
+// pointer.
+//
+// Objects are decoded into structs and maps with string keys, lists into
+// slices and literals into bools, numbers and strings. The keys of an item
+// with multiple keys, such as `service "web" {}`, decode as nested objects.
+// A struct field is decoded from the item with the name given by its "hcl"
+// tag, or otherwise its own name, matched case-insensitively if there's no
+// exact match. A tag of "-" skips the field. Unexported fields and fields of
+// func and chan types are never set, see DecoderConfig.Fields. Items with
+// the same key are appended to slices and merged into structs and maps.
+// The fields of embedded structs without a name in their tag, and of struct
+// fields with the tag option ",squash", are decoded from the items of the
+// struct itself, so shared sections can be composed into many structs.
+//
+// A string field with the tag option ",key" receives the label of a block
+// decoded into its struct, i.e. "web" of service "web" {} decoded into a
+// slice of structs, or the key of a map entry. A field of type []string with
+// the tag option ",decodedFields" receives the names of the fields decoded
+// from items, which tells fields set to their zero value from missing ones.
+//
+// Like encoding/json, pointers are allocated as needed, through any number
+// of indirections, i.e. for fields of the types **T, *[]T, []*T or
+// map[string]*T. Existing pointers are decoded into, including non-nil
+// pointers stored in interfaces. Types implementing Unmarshaler decode
+// themselves, the strings of fields with a parse tag are decoded by the
+// parser of that name, see RegisterParser. Fields of type RawMessage or
+// ast.Node capture their value undecoded, for decoding later.
+//
+// The tag options ",required", "min=N", "max=N" and "oneof=a|b" constrain
+// fields, i.e. `hcl:"port,min=1,max=65535"`: a required field must be
+// assigned in every object decoded into its struct, min and max bound numbers
+// and the lengths of strings, slices and maps, and oneof lists the allowed
+// values. A violation is an error at the position of the value.
+//
+// Blocks not matching any field of a struct, such as blocks added by newer
+// versions of an application, are ignored unless the struct has a field of
+// type []*UnknownBlock with the tag option ",unknown", which collects them.
+// A map with string keys and the tag option ",unused" collects the values of
+// all other keys matching no field, DecoderConfig.Strict reports them.
+//
+// Decoding into an empty interface results in string, int, float64, bool,
+// []interface{} and map[string]interface{} values. Errors are of type
+// *parser.PosError.
+func (c *DecoderConfig) DecodeObject(out interface{}, n ast.Node) error {
+	return c.run(out, func(d *decoder, rv reflect.Value) error {
+		return d.decode("root", n, rv)
+	})
+}
+
+// run checks that out is a non-nil pointer and calls decode with a new
+// decoder for the value out points to, then reports the unused keys and logs
+// the decode
+func (c *DecoderConfig) run(out interface{}, decode func(d *decoder, rv reflect.Value) error) error {
+	rv := reflect.ValueOf(out)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("out must be a non-nil pointer, got: %T", out)
+	}
+
+	start := time.Now()
+	d := &decoder{config: c}
+	err := decode(d, rv.Elem())
+	if err == nil && len(d.unused) > 0 {
+		// the keys of nested objects are found first
+		sort.SliceStable(d.unused, func(i, j int) bool {
+			return d.unused[i].Pos.Offset < d.unused[j].Pos.Offset
+		})
+		err = &UnusedKeysError{Errors: d.unused}
+	}
+	if c.Logger != nil {
+		c.Logger.Debug("hcl: decoded", "phase", "decode", "duration", time.Since(start),
+			"type", rv.Elem().Type().String(), "error", err)
+	}
+	return err
+}
+
+// parse parses src with the syntax, the keyword case folding, the duplicate
+// policy and the logger of c, or as JSON if it starts with "{"
+func (c *DecoderConfig) parse(src string) (*ast.File, error) {
+	if !jsonparser.IsJSON([]byte(src)) {
+		return (&parser.Config{Syntax: c.Syntax, FoldCase: c.FoldCase, Duplicates: c.Duplicates, Logger: c.Logger}).Parse([]byte(src))
+	}
+
+	start := time.Now()
+	f, err := jsonparser.Parse([]byte(src))
+	if err == nil {
+		if err = parser.ResolveDuplicates(f, c.Duplicates); err != nil {
+			f = nil
+		}
+	}
+	if c.Logger != nil {
+		c.Logger.Debug("hcl: parsed", "phase", "parse", "duration", time.Since(start),
+			"bytes", len(src), "format", "json", "error", err)
+	}
+	return f, err
+}
+
+// Parse parses src into a syntax tree, as JSON with package json/parser if
+// its first character other than white space is "{", otherwise as HCL. Both
+// formats result in the same trees, i.e. a JSON object of objects parses
+// like the equivalent HCL blocks.
+func Parse(src []byte) (*ast.File, error) {
+	return DefaultDecoderConfig.parse(string(src))
+}
+
+// Parse parses src like the package function Parse, with the keyword case
+// folding, the duplicate policy and the logger of c, so a tree decoded with
+// DecodeObject decodes like src with Decode.
+func (c *DecoderConfig) Parse(src []byte) (*ast.File, error) {
+	return c.parse(string(src))
+}
+
+// Decode parses src and decodes it into out with DefaultDecoderConfig.
+func Decode(out interface{}, src string) error {
+	return DefaultDecoderConfig.Decode(out, src)
+}
+
+// DecodeObject decodes n into out with DefaultDecoderConfig.
+func DecodeObject(out interface{}, n ast.Node) error {
+	return DefaultDecoderConfig.DecodeObject(out, n)
+}
+
+// DecodeStrict parses src and decodes it into out with DefaultDecoderConfig
+// like Decode, but reports the keys which no struct field is decoded from
+// with an *UnusedKeysError, see DecoderConfig.Strict.
+func DecodeStrict(out interface{}, src string) error {
+	c := DefaultDecoderConfig
+	c.Strict = true
+	return c.Decode(out, src)
+}
+
+// UnusedKeysError is the error of a strict decode for the keys of objects
+// decoded into structs, which don't match any field. The values of all other
+// keys are decoded.
+type UnusedKeysError struct {
+	Errors []*parser.PosError // one for every item, in the order of the source
+}
+
+func (e *UnusedKeysError) Error() string {
+	msgs := make([]string, 0, len(e.Errors))
+	for _, err := range e.Errors {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// UnknownBlock is a block of an object decoded into a struct, which doesn't
+// match any field of the struct. It's collected into the field with the tag
+// option ",unknown", i.e. `hcl:",unknown"`.
+type UnknownBlock struct {
+	Type   string   // block type, i.e. "service"
+	Labels []string // unquoted labels, i.e. "web"
+	Item   *ast.ObjectItem
+}
+
+var (
+	unknownBlocksType = reflect.TypeOf([]*UnknownBlock(nil))
+	stringsType       = reflect.TypeOf([]string(nil))
+)
+
+type decoder struct {
+	config *DecoderConfig
+
+	// labels are the label keys of the blocks passed to OnBlock
+	labels map[*ast.ObjectKey]bool
+
+	// unused are the errors of the keys not decoded by a strict decode
+	unused []*parser.PosError
+
+	// values is the number of decoded values, see DecoderConfig.MaxValues
+	values int
+
+	// src is the source of the tree, if known, for the Src of RawMessages
+	src string
+}
+
+func (d *decoder) decode(name string, n ast.Node, rv reflect.Value) error {
+	if f, ok := n.(*ast.File); ok {
+		return d.decode(name, f.Node, rv)
+	}
+
+	// raw values are captured as they are, null included
+	if isRaw(rv.Type()) {
+		return d.decodeRaw(n, rv)
+	}
+
+	// null resets the value, i.e. to a nil pointer, slice or map
+	if isNull(n) {
+		rv.Set(reflect.Zero(rv.Type()))
+		return nil
+	}
+
+	if rv.CanAddr() && isUnmarshaler(rv.Type()) {
+		return d.unmarshal(name, n, rv)
+	}
+	if rv.Type() == orderedMapType {
+		return d.decodeOrdered(name, n, rv)
+	}
+
+	// strings converted into slices and structs, such as net.IP, aren't
+	// decoded as lists and objects
+	if lit, ok := n.(*ast.LiteralType); ok && (lit.Token.Type == token.STRING || lit.Token.Type == token.HEREDOC) && d.config.converter(rv.Type()) != nil {
+		v, err := d.literal(lit)
+		if err != nil {
+			return err
+		}
+		return d.decodeValue(name, lit.Pos(), v, rv)
+	}
+
+	switch rv.Kind() {
+	case reflect.Interface:
+		// like encoding/json, a non-nil pointer in an interface is decoded
+		// into, instead of replaced
+		if e := rv.Elem(); e.Kind() == reflect.Ptr && !e.IsNil() {
+			return d.decode(name, n, e)
+		}
+		if rv.NumMethod() != 0 {
+			break
+		}
+
+		v, err := d.value(name, n)
+		if err != nil {
+			return err
+		}
+
+		if v != nil {
+			rv.Set(reflect.ValueOf(v))
+		}
+		return nil
+	case reflect.Ptr:
+		if rv.IsNil() {
+			rv.Set(reflect.New(rv.Type().Elem()))
+		}
+		return d.decode(name, n, rv.Elem())
+	case reflect.Slice:
+		return d.decodeSlice(name, []ast.Node{n}, rv)
+	case reflect.Map:
+		if list, ok := ast.ObjectListOf(n); ok {
+			return d.decodeMap(name, list, rv)
+		}
+	case reflect.Struct:
+		// the label of a block is the key of a struct with a key field
+		if item, ok := labelItem(n); ok {
+			if _, ok := keyField(rv.Type()); ok {
+				setKey(rv, item.Keys[0].Token.Value())
+				n = labelBody(item)
+			}
+		}
+
+		if list, ok := ast.ObjectListOf(n); ok {
+			return d.decodeStruct(name, list, rv)
+		}
+	}
+
+	// everything else, including strings resolving into lists and objects,
+	// is decoded from the value of the literal
+	lit, ok := n.(*ast.LiteralType)
+	if !ok {
+		return posErrorf(n.Pos(), "%s: cannot decode %s into %s", name, nodeName(n), rv.Type())
+	}
+
+	if lit.Token.Type == token.UNIT {
+		return d.decodeUnit(name, lit, rv)
+	}
+	if (lit.Token.Type == token.NUMBER || lit.Token.Type == token.FLOAT) && isBig(rv.Type()) {
+		return d.decodeBig(name, lit, rv)
+	}
+	if lit.Token.Type == token.NUMBER || lit.Token.Type == token.FLOAT {
+		if ok, err := d.decodeNumber(name, lit, rv); ok {
+			return err
+		}
+	}
+
+	v, err := d.literal(lit)
+	if err != nil {
+		return err
+	}
+	return d.decodeValue(name, lit.Pos(), v, rv)
+}
+
+// decodeNodes decodes the values of all items with the same key into rv.
+// They're appended to slices, otherwise decoded one after another, which
+// merges objects.
+func (d *decoder) decodeNodes(name string, nodes []ast.Node, rv reflect.Value) error {
+	if len(nodes) == 1 && isNull(nodes[0]) {
+		return d.decode(name, nodes[0], rv)
+	}
+
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			rv.Set(reflect.New(rv.Type().Elem()))
+		}
+		rv = rv.Elem()
+	}
+
+	if rv.Kind() == reflect.Slice && d.config.converter(rv.Type()) == nil && !isUnmarshaler(rv.Type()) && rv.Type() != orderedMapType {
+		return d.decodeSlice(name, nodes, rv)
+	}
+
+	// repeated items decoded into an empty interface become a list, see
+	// repeatedValue
+	if len(nodes) > 1 && rv.Kind() == reflect.Interface && rv.NumMethod() == 0 && rv.Elem().Kind() != reflect.Ptr {
+		v, err := d.repeatedValue(name, nodes, d.value)
+		if err != nil {
+			return err
+		}
+		rv.Set(reflect.ValueOf(&v).Elem())
+		return nil
+	}
+
+	for _, n := range nodes {
+		if err := d.decode(name, n, rv); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// decodeSlice decodes the elements of all lists of nodes into rv. Other
+// nodes are decoded as a single element.
+func (d *decoder) decodeSlice(name string, nodes []ast.Node, rv reflect.Value) error {
+	slice := reflect.MakeSlice(rv.Type(), 0, len(nodes))
+	add := func(decode func(elem reflect.Value) error) error {
+		if err := d.limitLen(name, nodes[0].Pos(), "MaxSliceLen", slice.Len()+1); err != nil {
+			return err
+		}
+		if err := d.countValue(name, nodes[0].Pos()); err != nil {
+			return err
+		}
+
+		elem := reflect.New(rv.Type().Elem()).Elem()
+		if err := decode(elem); err != nil {
+			return err
+		}
+		slice = reflect.Append(slice, elem)
+		return nil
+	}
+
+	for _, n := range nodes {
+		switch t := n.(type) {
+		case *ast.ListType:
+			for _, elem := range t.List {
+				elemName := fmt.Sprintf("%s[%d]", name, slice.Len())
+				err := add(func(rv reflect.Value) error { return d.decode(elemName, elem, rv) })
+				if err != nil {
+					return err
+				}
+			}
+		case *ast.LiteralType:
+			// a null item adds no elements, null elements of lists are
+			// zero values
+			if t.Token.Type == token.NULL {
+				continue
+			}
+
+			if t.Token.Type == token.UNIT {
+				elemName := fmt.Sprintf("%s[%d]", name, slice.Len())
+				if err := add(func(rv reflect.Value) error { return d.decodeUnit(elemName, t, rv) }); err != nil {
+					return err
+				}
+				continue
+			}
+			if t.Token.Type == token.NUMBER || t.Token.Type == token.FLOAT {
+				elemName := fmt.Sprintf("%s[%d]", name, slice.Len())
+				if err := add(func(rv reflect.Value) error { return d.decode(elemName, t, rv) }); err != nil {
+					return err
+				}
+				continue
+			}
+
+			v, err := d.literal(t)
+			if err != nil {
+				return err
+			}
+
+			values, ok := v.([]interface{})
+			if !ok {
+				values = []interface{}{v}
+			}
+
+			for _, v := range values {
+				elemName := fmt.Sprintf("%s[%d]", name, slice.Len())
+				err := add(func(rv reflect.Value) error { return d.decodeValue(elemName, t.Pos(), v, rv) })
+				if err != nil {
+					return err
+				}
+			}
+		default:
+			elemName := fmt.Sprintf("%s[%d]", name, slice.Len())
+			if err := add(func(rv reflect.Value) error { return d.decode(elemName, n, rv) }); err != nil {
+				return err
+			}
+		}
+	}
+
+	rv.Set(slice)
+	return nil
+}
+
+func (d *decoder) decodeMap(name string, list *ast.ObjectList, rv reflect.Value) error {
+	t := rv.Type()
+	if t.Key().Kind() != reflect.String {
+		return posErrorf(list.Pos(), "%s: map key must be a string, got: %s", name, t.Key())
+	}
+
+	if rv.IsNil() {
+		rv.Set(reflect.MakeMap(t))
+	}
+
+	if err := d.onBlock(list); err != nil {
+		return err
+	}
+
+	keys, values := fields(list)
+	for _, key := range keys {
+		// decode into a copy of the existing value, so objects are merged
+		elem := reflect.New(t.Elem()).Elem()
+		if existing := rv.MapIndex(reflect.ValueOf(key).Convert(t.Key())); existing.IsValid() {
+			elem.Set(existing)
+		} else if err := d.limitLen(name, values[key][0].Pos(), "MaxMapSize", rv.Len()+1); err != nil {
+			return err
+		}
+		if err := d.countValue(name+"."+ast.PathKey(key), values[key][0].Pos()); err != nil {
+			return err
+		}
+
+		if err := d.decodeNodes(name+"."+ast.PathKey(key), values[key], elem); err != nil {
+			return err
+		}
+		setKey(elem, key)
+		rv.SetMapIndex(reflect.ValueOf(key).Convert(t.Key()), elem)
+	}
+	return nil
+}
+
+func (d *decoder) decodeStruct(name string, list *ast.ObjectList, rv reflect.Value) error {
+	if err := d.onBlock(list); err != nil {
+		return err
+	}
+
+	keys, values := fields(list)
+	used := make(map[string]bool)
+	var unknown, unused, decoded reflect.Value
+	var decodedNames []string
+
+	for _, field := range structFields(rv.Type()) {
+		if field.option("key") {
+			if field.Type.Kind() != reflect.String {
+				return posErrorf(list.Pos(), "%s: field %s with the option key must be a string, got: %s",
+					name, field.Name, field.Type)
+			}
+			continue
+		}
+
+		if field.option("decodedFields") {
+			if field.Type != stringsType {
+				return posErrorf(list.Pos(), "%s: field %s with the option decodedFields must be of type %s, got: %s",
+					name, field.Name, stringsType, field.Type)
+			}
+			if field.settable {
+				decoded = fieldByIndex(rv, field.Index)
+			}
+			continue
+		}
+
+		if field.option("unknown") {
+			if field.Type != unknownBlocksType {
+				return posErrorf(list.Pos(), "%s: field %s with the option unknown must be of type %s, got: %s",
+					name, field.Name, unknownBlocksType, field.Type)
+			}
+			unknown = fieldByIndex(rv, field.Index)
+			continue
+		}
+
+		if field.option("unused") {
+			if field.Type.Kind() != reflect.Map || field.Type.Key().Kind() != reflect.String {
+				return posErrorf(list.Pos(), "%s: field %s with the option unused must be a map with string keys, got: %s",
+					name, field.Name, field.Type)
+			}
+			unused = fieldByIndex(rv, field.Index)
+			continue
+		}
+
+		if field.option("squash") {
+			return posErrorf(list.Pos(), "%s: field %s with the option squash must be a struct, got: %s",
+				name, field.Name, field.Type)
+		}
+
+		key := field.name
+		if key == "-" {
+			continue
+		}
+
+		key = d.matchKey(field, key, keys, values)
+		nodes, ok := values[key]
+		if !ok {
+			if field.option("required") {
+				return posErrorf(list.Pos(), "%s: missing required attribute", name+"."+ast.PathKey(key))
+			}
+			continue
+		}
+		used[key] = true
+
+		if !field.settable {
+			if err := d.skipField(name+"."+ast.PathKey(key), nodes[0].Pos(), field.StructField); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := d.countValue(name+"."+ast.PathKey(key), nodes[0].Pos()); err != nil {
+			return err
+		}
+
+		parser, fn, err := d.fieldParser(name+"."+ast.PathKey(key), nodes[0].Pos(), field.StructField)
+		if err != nil {
+			return err
+		}
+
+		if parser != "" {
+			err = d.parseNodes(name+"."+ast.PathKey(key), parser, fn, nodes, fieldByIndex(rv, field.Index))
+		} else {
+			err = d.decodeNodes(name+"."+ast.PathKey(key), nodes, fieldByIndex(rv, field.Index))
+		}
+		if err != nil {
+			return err
+		}
+
+		// the last value wins, unless they're appended
+		pos := nodes[len(nodes)-1].Pos()
+		if err := d.checkConstraints(name+"."+ast.PathKey(key), pos, &field, fieldByIndex(rv, field.Index)); err != nil {
+			return err
+		}
+		decodedNames = append(decodedNames, field.Name)
+	}
+
+	if decoded.IsValid() {
+		// blocks merged into the struct add their fields
+		for _, fieldName := range decodedNames {
+			if !containsString(decoded.Interface().([]string), fieldName) {
+				decoded.Set(reflect.Append(decoded, reflect.ValueOf(fieldName)))
+			}
+		}
+	}
+
+	// the remaining items are unknown blocks, leftovers or unused
+	var leftovers []string
+	seen := make(map[string]bool)
+	for _, item := range list.Items {
+		if len(item.Keys) == 0 || used[item.Keys[0].Token.Value()] {
+			continue
+		}
+		key := item.Keys[0].Token.Value()
+
+		switch {
+		case unknown.IsValid() && isBlock(item):
+			block := &UnknownBlock{Type: key, Item: item}
+			for _, k := range item.Keys[1:] {
+				block.Labels = append(block.Labels, k.Token.Value())
+			}
+			unknown.Set(reflect.Append(unknown, reflect.ValueOf(block)))
+		case unused.IsValid():
+			if !seen[key] {
+				seen[key] = true
+				leftovers = append(leftovers, key)
+			}
+		case d.config.Strict:
+			d.unused = append(d.unused, &parser.PosError{
+				Pos: item.Pos(),
+				Err: fmt.Errorf("%s: unknown key", name+"."+ast.PathKey(key)),
+			})
+		}
+	}
+
+	if len(leftovers) == 0 {
+		return nil
+	}
+
+	if unused.IsNil() {
+		unused.Set(reflect.MakeMap(unused.Type()))
+	}
+	for _, key := range leftovers {
+		// decode into a copy of the existing value like decodeMap
+		k := reflect.ValueOf(key).Convert(unused.Type().Key())
+		elem := reflect.New(unused.Type().Elem()).Elem()
+		if existing := unused.MapIndex(k); existing.IsValid() {
+			elem.Set(existing)
+		} else if err := d.limitLen(name, values[key][0].Pos(), "MaxMapSize", unused.Len()+1); err != nil {
+			return err
+		}
+		if err := d.countValue(name+"."+ast.PathKey(key), values[key][0].Pos()); err != nil {
+			return err
+		}
+
+		if err := d.decodeNodes(name+"."+ast.PathKey(key), values[key], elem); err != nil {
+			return err
+		}
+		unused.SetMapIndex(k, elem)
+	}
+	return nil
+}
+
+// onBlock calls the OnBlock hook for every block of list. The remaining keys
+// of an item with labels are nested into objects by fields, so the items of
+// these objects, whose first key is a label, aren't blocks on their own.
+func (d *decoder) onBlock(list *ast.ObjectList) error {
+	if d.config.OnBlock == nil {
+		return nil
+	}
+
+	if d.labels == nil {
+		d.labels = make(map[*ast.ObjectKey]bool)
+	}
+
+	for _, item := range list.Items {
+		if len(item.Keys) == 0 || d.labels[item.Keys[0]] {
+			continue
+		}
+
+		labels := make([]string, 0, len(item.Keys)-1)
+		for _, k := range item.Keys[1:] {
+			d.labels[k] = true
+			labels = append(labels, k.Token.Value())
+		}
+
+		if !isBlock(item) {
+			continue
+		}
+
+		if err := d.config.OnBlock(item.Keys[0].Token.Value(), labels, item); err != nil {
+			if _, ok := err.(*parser.PosError); ok {
+				return err
+			}
+			return &parser.PosError{Pos: item.Pos(), Err: err}
+		}
+	}
+	return nil
+}
+
+// value returns the Go value of n, as decoded into an empty interface
+func (d *decoder) value(name string, n ast.Node) (interface{}, error) {
+	switch t := n.(type) {
+	case *ast.File:
+		return d.value(name, t.Node)
+	case *ast.ObjectType:
+		if t.List == nil {
+			return map[string]interface{}{}, nil
+		}
+		return d.value(name, t.List)
+	case *ast.ObjectList:
+		if err := d.onBlock(t); err != nil {
+			return nil, err
+		}
+
+		m := make(map[string]interface{})
+		keys, values := fields(t)
+		if err := d.limitLen(name, t.Pos(), "MaxMapSize", len(keys)); err != nil {
+			return nil, err
+		}
+		for _, key := range keys {
+			if err := d.countValue(name+"."+ast.PathKey(key), values[key][0].Pos()); err != nil {
+				return nil, err
+			}
+			v, err := d.repeatedValue(name+"."+ast.PathKey(key), values[key], d.value)
+			if err != nil {
+				return nil, err
+			}
+			m[key] = v
+		}
+		return m, nil
+	case *ast.ListType:
+		if err := d.limitLen(name, t.Pos(), "MaxSliceLen", len(t.List)); err != nil {
+			return nil, err
+		}
+
+		list := make([]interface{}, 0, len(t.List))
+		for i, elem := range t.List {
+			if err := d.countValue(name, elem.Pos()); err != nil {
+				return nil, err
+			}
+			v, err := d.value(fmt.Sprintf("%s[%d]", name, i), elem)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, v)
+		}
+		return list, nil
+	case *ast.LiteralType:
+		return d.literal(t)
+	}
+
+	return nil, posErrorf(n.Pos(), "%s: cannot decode %s", name, nodeName(n))
+}
+
+// repeatedValue returns the value of the items with the same key, each
+// decoded by value. Like ToJSON does, the values of repeated items become a
+// list, with repeated lists concatenated, and the labels of blocks are merged
+// into one object, i.e. service "web" {} service "db" {}.
+func (d *decoder) repeatedValue(name string, nodes []ast.Node, value func(string, ast.Node) (interface{}, error)) (interface{}, error) {
+	// the blocks with labels are grouped into one list of their labels
+	var groups []ast.Node
+	for _, n := range nodes {
+		item, ok := labelItem(n)
+		if !ok {
+			groups = append(groups, n)
+			continue
+		}
+
+		if last := len(groups) - 1; last >= 0 {
+			if labels, ok := groups[last].(*ast.ObjectList); ok {
+				labels.Add(item)
+				continue
+			}
+		}
+		groups = append(groups, &ast.ObjectList{Items: []*ast.ObjectItem{item}})
+	}
+
+	var res interface{}
+	repeated := false
+	for i, n := range groups {
+		v, err := value(name, n)
+		if err != nil {
+			return nil, err
+		}
+
+		list, isList := res.([]interface{})
+		elems, ok := v.([]interface{})
+		switch {
+		case i == 0:
+			res = v
+		case repeated:
+			res = append(list, v)
+		case isList && ok:
+			res = append(list, elems...)
+		default:
+			res = []interface{}{res, v}
+			repeated = true
+		}
+	}
+	return res, nil
+}
+
+// literal returns the Go value of a literal. Strings containing
+// interpolations are resolved with the configured Resolver, if any.
+func (d *decoder) literal(lit *ast.LiteralType) (interface{}, error) {
+	text := lit.Token.Text
+	switch lit.Token.Type {
+	case token.NUMBER, token.FLOAT:
+		return number(lit)
+	case token.BOOL:
+		return strings.EqualFold(text, "true"), nil
+	case token.NULL:
+		return nil, nil
+	case token.UNIT:
+		return text, nil
+	case token.STRING:
+		s, err := strconv.Unquote(text)
+		if err != nil {
+			return nil, posErrorf(lit.Pos(), "invalid string %s", text)
+		}
+
+		if d.config.Resolver == nil || !strings.Contains(s, "{") {
+			return unescape(s), nil
+		}
+
+		pos := lit.Pos()
+		pos.Offset++
+		pos.Column++
+
+		t, err := parser.ParseTemplate(text[1:len(text)-1], pos)
+		if err != nil {
+			return nil, err
+		}
+		return d.template(lit, t)
+	case token.HEREDOC:
+		s := lit.Token.HeredocValue()
+		if d.config.Resolver == nil || !strings.Contains(s, "{") {
+			return unescape(s), nil
+		}
+
+		t, err := parser.ParseHeredoc(lit.Token)
+		if err != nil {
+			return nil, err
+		}
+		return d.template(lit, t)
+	}
+
+	return nil, posErrorf(lit.Pos(), "unsupported literal %s", lit.Token.Type)
+}
+
+// template resolves the template t of the string literal lit with the
+// Resolver
+func (d *decoder) template(lit *ast.LiteralType, t *ast.Template) (interface{}, error) {
+	if !isText(t) {
+		v, err := d.config.Resolver(t)
+		if err != nil {
+			if _, ok := err.(*parser.PosError); ok {
+				return nil, err
+			}
+			return nil, &parser.PosError{Pos: lit.Pos(), Err: err}
+		}
+		return v, nil
+	}
+
+	// resolve escaped "$${" sequences
+	var buf []string
+	for _, part := range t.Parts {
+		buf = append(buf, part.(*ast.TemplateText).Text)
+	}
+	return strings.Join(buf, ""), nil
+}
+
+// unescape resolves the escaped "$${" and "%%{" sequences of a string which
+// isn't resolved with a Resolver, as the escapes stand for "${" and "%{"
+// with or without one. Interpolations are kept as they are.
+func unescape(s string) string {
+	s = strings.Replace(s, "$${", "${", -1)
+	return strings.Replace(s, "%%{", "%{", -1)
+}
+
+// decodeValue decodes a Go value, as returned by value or a Resolver, into
+// rv.
+func (d *decoder) decodeValue(name string, pos token.Pos, v interface{}, rv reflect.Value) error {
+	mismatch := func() error {
+		return posErrorf(pos, "%s: cannot decode %s into %s", name, convert.TypeName(v), rv.Type())
+	}
+
+	if v == nil {
+		rv.Set(reflect.Zero(rv.Type()))
+		return nil
+	}
+
+	if s, ok := v.(string); ok {
+		if conv := d.config.converter(rv.Type()); conv != nil {
+			return d.convertString(name, pos, conv, s, rv)
+		}
+	}
+
+	switch rv.Kind() {
+	case reflect.Interface:
+		if e := rv.Elem(); e.Kind() == reflect.Ptr && !e.IsNil() {
+			return d.decodeValue(name, pos, v, e)
+		}
+		if rv.NumMethod() != 0 {
+			return mismatch()
+		}
+		rv.Set(reflect.ValueOf(v))
+	case reflect.Ptr:
+		if rv.IsNil() {
+			rv.Set(reflect.New(rv.Type().Elem()))
+		}
+		return d.decodeValue(name, pos, v, rv.Elem())
+	case reflect.String:
+		s, err := convert.ToString(v)
+		if err != nil {
+			return mismatch()
+		}
+		rv.SetString(s)
+	case reflect.Bool:
+		b, err := convert.ToBool(v)
+		if err != nil {
+			return mismatch()
+		}
+		rv.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := convert.ToInt(v, rv.Type().Bits())
+		if err != nil {
+			return convertError(name, pos, err, rv.Type(), mismatch)
+		}
+		rv.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		i, err := convert.ToUint(v, rv.Type().Bits())
+		if err != nil {
+			return convertError(name, pos, err, rv.Type(), mismatch)
+		}
+		rv.SetUint(i)
+	case reflect.Float32, reflect.Float64:
+		f, err := convert.ToFloat64(v)
+		if err != nil {
+			return mismatch()
+		}
+		rv.SetFloat(f)
+	case reflect.Slice:
+		list := convert.ToList(v)
+		if err := d.limitLen(name, pos, "MaxSliceLen", len(list)); err != nil {
+			return err
+		}
+
+		slice := reflect.MakeSlice(rv.Type(), len(list), len(list))
+		for i, elem := range list {
+			if err := d.countValue(name, pos); err != nil {
+				return err
+			}
+			if err := d.decodeValue(fmt.Sprintf("%s[%d]", name, i), pos, elem, slice.Index(i)); err != nil {
+				return err
+			}
+		}
+		rv.Set(slice)
+	case reflect.Map:
+		m, ok := v.(map[string]interface{})
+		if !ok || rv.Type().Key().Kind() != reflect.String {
+			return mismatch()
+		}
+
+		if err := d.limitLen(name, pos, "MaxMapSize", rv.Len()+len(m)); err != nil {
+			return err
+		}
+		if rv.IsNil() {
+			rv.Set(reflect.MakeMap(rv.Type()))
+		}
+
+		for k, elem := range m {
+			if err := d.countValue(name+"."+ast.PathKey(k), pos); err != nil {
+				return err
+			}
+			ev := reflect.New(rv.Type().Elem()).Elem()
+			if err := d.decodeValue(name+"."+ast.PathKey(k), pos, elem, ev); err != nil {
+				return err
+			}
+			rv.SetMapIndex(reflect.ValueOf(k).Convert(rv.Type().Key()), ev)
+		}
+	case reflect.Struct:
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return mismatch()
+		}
+
+		for _, field := range structFields(rv.Type()) {
+			key := field.name
+			if key == "-" || field.filled {
+				continue
+			}
+
+			elem, ok := m[key]
+			if !ok {
+				if field.option("required") {
+					return posErrorf(pos, "%s: missing required attribute", name+"."+ast.PathKey(key))
+				}
+				continue
+			}
+
+			if !field.settable {
+				if err := d.skipField(name+"."+ast.PathKey(key), pos, field.StructField); err != nil {
+					return err
+				}
+				continue
+			}
+
+			if err := d.countValue(name+"."+ast.PathKey(key), pos); err != nil {
+				return err
+			}
+			if err := d.decodeValue(name+"."+ast.PathKey(key), pos, elem, fieldByIndex(rv, field.Index)); err != nil {
+				return err
+			}
+			if err := d.checkConstraints(name+"."+ast.PathKey(key), pos, &field, fieldByIndex(rv, field.Index)); err != nil {
+				return err
+			}
+		}
+	default:
+		return mismatch()
+	}
+
+	return nil
+}
+
+// countValue counts a decoded element, entry or field against
+// DecoderConfig.MaxValues
+func (d *decoder) countValue(name string, pos token.Pos) error {
+	d.values++
+	if max := d.config.MaxValues; max > 0 && d.values > max {
+		return limitError(name, pos, "MaxValues", max)
+	}
+	return nil
+}
+
+// limitLen returns an error if the length n of the slice or map name
+// exceeds the limit, MaxSliceLen or MaxMapSize
+func (d *decoder) limitLen(name string, pos token.Pos, limit string, n int) error {
+	max := d.config.MaxSliceLen
+	if limit == "MaxMapSize" {
+		max = d.config.MaxMapSize
+	}
+
+	if max > 0 && n > max {
+		return limitError(name, pos, limit, max)
+	}
+	return nil
+}
+
+func limitError(name string, pos token.Pos, limit string, max int) error {
+	return &parser.PosError{Pos: pos, Err: fmt.Errorf("%s: %w", name, &parser.LimitError{Limit: limit, Max: max})}
+}
+
+// fields groups the values of the items of list by their first key, in order
+// of appearance. The value of an item with multiple keys is nested into
+// objects with the remaining keys.
+func fields(list *ast.ObjectList) ([]string, map[string][]ast.Node) {
+	var keys []string
+	values := make(map[string][]ast.Node)
+	for _, item := range list.Items {
+		if len(item.Keys) == 0 {
+			continue
+		}
+
+		key := item.Keys[0].Token.Value()
+		if _, ok := values[key]; !ok {
+			keys = append(keys, key)
+		}
+
+		values[key] = append(values[key], labelBody(item))
+	}
+	return keys, values
+}
+
+// labelBody returns the value of item below its first key. The remaining
+// keys of an item with labels are nested into an object, whose Lbrace is the
+// position of the next label, so labelItem tells it from the objects of the
+// source.
+func labelBody(item *ast.ObjectItem) ast.Node {
+	if len(item.Keys) < 2 {
+		return item.Val
+	}
+
+	return &ast.ObjectType{
+		Lbrace: item.Keys[1].Pos(),
+		List: &ast.ObjectList{
+			Items: []*ast.ObjectItem{{Keys: item.Keys[1:], Val: item.Val}},
+		},
+	}
+}
+
+// fieldName returns the name of the item a struct field is decoded from
+func fieldName(field reflect.StructField) string {
+	tag := field.Tag.Get("hcl")
+	if i := strings.Index(tag, ","); i >= 0 {
+		tag = tag[:i]
+	}
+
+	if tag == "" {
+		return field.Name
+	}
+	return tag
+}
+
+// structField is a field of structFields with the results of inspecting its
+// tag and type, which are computed once per struct type
+type structField struct {
+	reflect.StructField
+	name     string   // see fieldName
+	tagged   bool     // the field has an "hcl" tag
+	options  []string // options of the "hcl" tag, i.e. "squash"
+	settable bool     // see settable
+	filled   bool     // see filledByDecoder
+}
+
+// option reports whether the "hcl" tag of f contains the option opt
+func (f *structField) option(opt string) bool {
+	for _, o := range f.options {
+		if o == opt {
+			return true
+		}
+	}
+	return false
+}
+
+// fieldCache maps struct types to their structFields, like the field cache
+// of encoding/json, so decoding many values of a type inspects the type once
+var fieldCache sync.Map // map[reflect.Type][]structField
+
+// structFields returns the fields of the struct type t decoded from items,
+// which must not be modified. The fields of embedded structs without a name
+// in their tag and of structs with the tag option ",squash" are promoted:
+// they're decoded from the items of t in place of the struct. Like in Go, a
+// field hides promoted fields of the same name at a deeper level, the first
+// of the fields at the same level wins. The Index of a promoted field is the
+// index sequence for fieldByIndex.
+func structFields(t reflect.Type) []structField {
+	if fields, ok := fieldCache.Load(t); ok {
+		return fields.([]structField)
+	}
+
+	fields, _ := fieldCache.LoadOrStore(t, typeFields(t))
+	return fields.([]structField)
+}
+
+// typeFields returns the structFields of t, without the cache
+func typeFields(t reflect.Type) []structField {
+	type candidate struct {
+		field reflect.StructField
+		depth int
+	}
+
+	var all []candidate
+	var walk func(t reflect.Type, index []int, seen map[reflect.Type]bool)
+	walk = func(t reflect.Type, index []int, seen map[reflect.Type]bool) {
+		seen[t] = true
+		defer delete(seen, t)
+
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			field.Index = append(index[:len(index):len(index)], i)
+			if st := squashed(field); st != nil && !seen[st] {
+				walk(st, field.Index, seen)
+				continue
+			}
+			all = append(all, candidate{field, len(index)})
+		}
+	}
+	walk(t, nil, make(map[reflect.Type]bool))
+
+	depth := make(map[string]int)
+	for _, c := range all {
+		key := fieldName(c.field)
+		if d, ok := depth[key]; !ok || c.depth < d {
+			depth[key] = c.depth
+		}
+	}
+
+	fields := make([]structField, 0, len(all))
+	taken := make(map[string]bool)
+	for _, c := range all {
+		key := fieldName(c.field)
+		if key != "-" && !filledByDecoder(c.field) {
+			if c.depth != depth[key] || taken[key] {
+				continue
+			}
+			taken[key] = true
+		}
+
+		tag := c.field.Tag.Get("hcl")
+		fields = append(fields, structField{
+			StructField: c.field,
+			name:        key,
+			tagged:      tag != "",
+			options:     strings.Split(tag, ",")[1:],
+			settable:    settable(c.field),
+			filled:      filledByDecoder(c.field),
+		})
+	}
+	return fields
+}
+
+// squashed returns the struct type whose fields are promoted in place of
+// field, nil if the field is decoded from an item of its own. Embedded
+// pointers to unexported structs can't be allocated and aren't promoted.
+func squashed(field reflect.StructField) reflect.Type {
+	tag := field.Tag.Get("hcl")
+	if !tagOption(field, "squash") && (!field.Anonymous || tag != "" && !strings.HasPrefix(tag, ",")) {
+		return nil
+	}
+
+	t := field.Type
+	if t.Kind() == reflect.Ptr {
+		if field.PkgPath != "" {
+			return nil
+		}
+		t = t.Elem()
+	}
+
+	if t.Kind() != reflect.Struct || t == timeType || t == urlType || isBig(t) || isUnmarshaler(t) {
+		return nil
+	}
+	return t
+}
+
+// fieldByIndex returns the field of the struct rv with the index sequence of
+// a field of structFields, allocating nil pointers to embedded structs
+func fieldByIndex(rv reflect.Value, index []int) reflect.Value {
+	for i, x := range index {
+		if i > 0 && rv.Kind() == reflect.Ptr {
+			if rv.IsNil() {
+				rv.Set(reflect.New(rv.Type().Elem()))
+			}
+			rv = rv.Elem()
+		}
+		rv = rv.Field(x)
+	}
+	return rv
+}
+
+// matchKey returns the key of values matching the name key of field. Fields
+// without a tag, or any field with FoldCase, also match keys differing in
+// case only.
+func (d *decoder) matchKey(field structField, key string, keys []string, values map[string][]ast.Node) string {
+	if _, ok := values[key]; ok || field.tagged && !d.config.FoldCase {
+		return key
+	}
+
+	for _, k := range keys {
+		if strings.EqualFold(k, key) {
+			return k
+		}
+	}
+	return key
+}
+
+// settable reports whether the decoder sets field. Unexported fields and
+// fields of func, chan and unsafe pointer types are never set.
+func settable(field reflect.StructField) bool {
+	if field.PkgPath != "" {
+		return false
+	}
+
+	// types can be recursive, i.e. type list []list
+	t := field.Type
+	seen := make(map[reflect.Type]bool)
+	for !seen[t] && (t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Map) {
+		seen[t] = true
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Func, reflect.Chan, reflect.UnsafePointer:
+		return false
+	}
+	return true
+}
+
+// skipField applies the FieldPolicy to field, which isn't settable but
+// matches the key of a value
+func (d *decoder) skipField(name string, pos token.Pos, field reflect.StructField) error {
+	reason := "is unexported"
+	if field.PkgPath == "" {
+		reason = "has the unsupported type " + field.Type.String()
+	}
+	err := posErrorf(pos, "%s: field %s %s", name, field.Name, reason)
+
+	switch d.config.Fields {
+	case RejectFields:
+		return err
+	case WarnFields:
+		if d.config.Warn != nil {
+			d.config.Warn(err)
+		}
+	}
+	return nil
+}
+
+// tagOption reports whether the "hcl" tag of field contains the option opt
+func tagOption(field reflect.StructField, opt string) bool {
+	for _, o := range strings.Split(field.Tag.Get("hcl"), ",")[1:] {
+		if o == opt {
+			return true
+		}
+	}
+	return false
+}
+
+// filledByDecoder reports whether field is filled by the decoder instead of
+// decoded from the item of its name: it collects the items which don't match
+// the other fields, with the tag option ",unknown" or ",unused", or holds the
+// label of its block, with ",key", or the decoded fields, with
+// ",decodedFields"
+func filledByDecoder(field reflect.StructField) bool {
+	return tagOption(field, "unknown") || tagOption(field, "unused") ||
+		tagOption(field, "key") || tagOption(field, "decodedFields")
+}
+
+// keyField returns the field of the struct type t, or of the struct t points
+// to, with the tag option ",key"
+func keyField(t reflect.Type) (structField, bool) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return structField{}, false
+	}
+
+	for _, field := range structFields(t) {
+		if field.option("key") {
+			return field, true
+		}
+	}
+	return structField{}, false
+}
+
+// setKey sets the field with the tag option ",key" of the struct rv, or of
+// the struct rv points to, to key
+func setKey(rv reflect.Value, key string) {
+	for rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return
+	}
+
+	if field, ok := keyField(rv.Type()); ok && field.Type.Kind() == reflect.String && field.settable {
+		fieldByIndex(rv, field.Index).SetString(key)
+	}
+}
+
+// isNull reports whether n is the literal null
+func isNull(n ast.Node) bool {
+	lit, ok := n.(*ast.LiteralType)
+	return ok && lit.Token.Type == token.NULL
+}
+
+// labelItem returns the item of an object nested into the value of an item
+// with labels by fields, whose first key is the next label
+func labelItem(n ast.Node) (*ast.ObjectItem, bool) {
+	obj, ok := n.(*ast.ObjectType)
+	if !ok || obj.List == nil || len(obj.List.Items) != 1 {
+		return nil, false
+	}
+
+	item := obj.List.Items[0]
+	if len(item.Keys) == 0 || obj.Lbrace != item.Keys[0].Pos() {
+		return nil, false
+	}
+	return item, true
+}
+
+// isBlock reports whether item is a block, such as service "web" {}, rather
+// than an attribute with an object value
+func isBlock(item *ast.ObjectItem) bool {
+	_, ok := item.Val.(*ast.ObjectType)
+	return ok && !item.Assign.IsValid()
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// isText reports whether t consists of plain text only
+func isText(t *ast.Template) bool {
+	for _, part := range t.Parts {
+		if _, ok := part.(*ast.TemplateText); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func nodeName(n ast.Node) string {
+	switch t := n.(type) {
+	case *ast.ObjectList, *ast.ObjectType:
+		return "object"
+	case *ast.ListType:
+		return "list"
+	case *ast.LiteralType:
+		return strings.ToLower(t.Token.Type.String())
+	}
+	return fmt.Sprintf("%T", n)
+}
+
+// convertError returns the error of a failed conversion to the type t, or
+// the result of mismatch for type errors
+func convertError(name string, pos token.Pos, err error, t reflect.Type, mismatch func() error) error {
+	if e, ok := err.(*convert.RangeError); ok {
+		return posErrorf(pos, "%s: %d overflows %s", name, e.Value, t)
+	}
+	return mismatch()
+}
+
+func posErrorf(pos token.Pos, format string, args ...interface{}) error {
+	return &parser.PosError{Pos: pos, Err: fmt.Errorf(format, args...)}
+}
@@ -0,0 +1,1123 @@
+package hcl
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/fatih/hcl/ast"
+	"github.com/fatih/hcl/eval"
+	"github.com/fatih/hcl/parser"
+)
+
+type service struct {
+	Port    int
+	Host    string   `hcl:"hostname"`
+	Tags    []string `hcl:"tags"`
+	Enabled bool     `hcl:"enabled"`
+	Ignored string   `hcl:"-"`
+	secret  string
+}
+
+func TestDecode(t *testing.T) {
+	cases := []struct {
+		src      string
+		expected interface{}
+	}{
+		{`a = "b"`, map[string]string{"a": "b"}},
+		{`a = 1 b = 2`, map[string]int{"a": 1, "b": 2}},
+		{`a = 1.5`, map[string]float64{"a": 1.5}},
+		{`a = 1`, map[string]float64{"a": 1}},
+		{`a = 42`, map[string]string{"a": "42"}},
+		{`a = "42"`, map[string]uint8{"a": 42}},
+		{`a = [1, 2] a = [3]`, map[string][]int{"a": {1, 2, 3}}},
+		{`a = 1`, map[string][]int{"a": {1}}},
+		{
+			`a = "b" c = [1, 2.5, "d"] e { f = true }`,
+			map[string]interface{}{
+				"a": "b",
+				"c": []interface{}{1, 2.5, "d"},
+				"e": map[string]interface{}{"f": true},
+			},
+		},
+		{
+			`service "web" { port = 80 } service "db" { port = 5432 }`,
+			map[string]map[string]map[string]int{
+				"service": {"web": {"port": 80}, "db": {"port": 5432}},
+			},
+		},
+		{
+			`Port = 80
+hostname = "localhost"
+tags = ["a", "b"]
+enabled = true
+Ignored = "x"
+secret = "y"`,
+			service{Port: 80, Host: "localhost", Tags: []string{"a", "b"}, Enabled: true},
+		},
+		{
+			`service "web" { Port = 80 } service "db" { Port = 5432 }`,
+			struct{ Service map[string]*service }{
+				map[string]*service{"web": {Port: 80}, "db": {Port: 5432}},
+			},
+		},
+		{
+			`service { Port = 80 } service { Port = 81 }`,
+			struct{ Service []service }{[]service{{Port: 80}, {Port: 81}}},
+		},
+		{
+			`service { Port = 80 } service { hostname = "a" }`,
+			struct{ Service service }{service{Port: 80, Host: "a"}},
+		},
+		{`a = "${var.foo}"`, map[string]string{"a": "${var.foo}"}},
+		{`a = 1 a = 2`, map[string]interface{}{"a": []interface{}{1, 2}}},
+		{`a = [1] a = [2, 3]`, map[string]interface{}{"a": []interface{}{1, 2, 3}}},
+		{
+			`d { e = 1 } d { e = 2 }`,
+			map[string]interface{}{"d": []interface{}{
+				map[string]interface{}{"e": 1},
+				map[string]interface{}{"e": 2},
+			}},
+		},
+		{
+			`d { e = 1 } d { e = 2 }`,
+			map[string][]interface{}{"d": {
+				map[string]interface{}{"e": 1},
+				map[string]interface{}{"e": 2},
+			}},
+		},
+		{
+			`d "x" { e = 1 } d "y" { e = 2 } d "x" { e = 3 }`,
+			map[string]interface{}{"d": map[string]interface{}{
+				"x": []interface{}{
+					map[string]interface{}{"e": 1},
+					map[string]interface{}{"e": 3},
+				},
+				"y": map[string]interface{}{"e": 2},
+			}},
+		},
+		{
+			`a = 1 a = true a = "b"`,
+			struct{ A interface{} }{[]interface{}{1, true, "b"}},
+		},
+	}
+
+	for _, c := range cases {
+		out := reflect.New(reflect.TypeOf(c.expected))
+		if err := Decode(out.Interface(), c.src); err != nil {
+			t.Errorf("%s: %s", c.src, err)
+			continue
+		}
+
+		if got := out.Elem().Interface(); !reflect.DeepEqual(got, c.expected) {
+			t.Errorf("%s:\nwant: %#v\ngot:  %#v", c.src, c.expected, got)
+		}
+	}
+}
+
+func TestDecodeError(t *testing.T) {
+	cases := []struct {
+		src string
+		out interface{}
+		err string
+	}{
+		{`a = "b"`, &map[string]int{}, `At 1:5: root.a: cannot decode string into int`},
+		{`a = 300`, &map[string]int8{}, `At 1:5: root.a: 300 overflows int8`},
+		{`a = -1`, &map[string]uint{}, `At 1:5: root.a: -1 overflows uint`},
+		{`a = [1]`, &map[string]int{}, `At 1:5: root.a: cannot decode list into int`},
+		{`a { b = 1 }`, &map[string]string{}, `At 1:3: root.a: cannot decode object into string`},
+		{`a = ["x"]`, &map[string][]int{}, `At 1:6: root.a[0]: cannot decode string into int`},
+		{`Port = "x"`, &service{}, `At 1:8: root.Port: cannot decode string into int`},
+		{`a = 1`, &map[int]int{}, `At 1:1: root: map key must be a string, got: int`},
+		{`a = 1`, map[string]int{}, `out must be a non-nil pointer, got: map[string]int`},
+	}
+
+	for _, c := range cases {
+		err := Decode(c.out, c.src)
+		if err == nil || err.Error() != c.err {
+			t.Errorf("%s: want: %q got: %v", c.src, c.err, err)
+		}
+	}
+}
+
+func TestDecoderConfigResolver(t *testing.T) {
+	vars := map[string]interface{}{
+		"var.port":  int64(8080),
+		"var.hosts": []interface{}{"a", "b"},
+		"var.name":  "web",
+	}
+
+	var resolved []string
+	config := &DecoderConfig{
+		Resolver: func(t *ast.Template) (interface{}, error) {
+			resolved = append(resolved, t.Pos().String())
+			return eval.Eval(t, vars)
+		},
+	}
+
+	var out struct {
+		Name  string   `hcl:"name"`
+		Port  int      `hcl:"port"`
+		Hosts []string `hcl:"hosts"`
+		Plain string   `hcl:"plain"`
+		Raw   string   `hcl:"raw"`
+	}
+
+	src := `name = "${var.name}-1"
+port = "${var.port}"
+hosts = "${var.hosts}"
+plain = "no interpolation"
+raw = "$${not.resolved}"
+`
+	if err := config.Decode(&out, src); err != nil {
+		t.Fatal(err)
+	}
+
+	if out.Name != "web-1" || out.Port != 8080 || !reflect.DeepEqual(out.Hosts, []string{"a", "b"}) {
+		t.Errorf("unexpected result: %+v", out)
+	}
+
+	if out.Plain != "no interpolation" || out.Raw != "${not.resolved}" {
+		t.Errorf("unexpected result: %+v", out)
+	}
+
+	if want := []string{"1:9", "2:9", "3:10"}; !reflect.DeepEqual(resolved, want) {
+		t.Errorf("want resolved: %v got: %v", want, resolved)
+	}
+}
+
+func TestDecoderConfigResolverError(t *testing.T) {
+	config := &DecoderConfig{
+		Resolver: func(t *ast.Template) (interface{}, error) {
+			return nil, errors.New("secret not found")
+		},
+	}
+
+	var out map[string]string
+	err := config.Decode(&out, "a = 1\nb = \"${secret.db}\"")
+	if err == nil || err.Error() != "At 2:5: secret not found" {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	// errors of the resolver with a position are returned as they are
+	config.Resolver = func(t *ast.Template) (interface{}, error) {
+		return eval.Eval(t, nil)
+	}
+
+	err = config.Decode(&out, `a = "x${unknown}"`)
+	if _, ok := err.(*parser.PosError); !ok || err.Error() != "At 1:9: unknown variable unknown" {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestDecodeUnknownBlocks(t *testing.T) {
+	var out struct {
+		Port    int             `hcl:"port"`
+		Unknown []*UnknownBlock `hcl:",unknown"`
+	}
+
+	src := `port = 80
+service "web" "v2" { image = "a" }
+cache { size = 1 }
+extra = { a = 1 }
+name = "x"
+`
+	if err := Decode(&out, src); err != nil {
+		t.Fatal(err)
+	}
+
+	if out.Port != 80 || len(out.Unknown) != 2 {
+		t.Fatalf("unexpected result: %+v", out)
+	}
+
+	service, cache := out.Unknown[0], out.Unknown[1]
+	if service.Type != "service" || !reflect.DeepEqual(service.Labels, []string{"web", "v2"}) || service.Item.Pos().String() != "2:1" {
+		t.Errorf("unexpected block: %+v", service)
+	}
+
+	if cache.Type != "cache" || cache.Labels != nil {
+		t.Errorf("unexpected block: %+v", cache)
+	}
+
+	var invalid struct {
+		Unknown []ast.Node `hcl:",unknown"`
+	}
+	err := Decode(&invalid, `a {}`)
+	if err == nil || err.Error() != "At 1:1: root: field Unknown with the option unknown must be of type []*hcl.UnknownBlock, got: []ast.Node" {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestDecodeStrict(t *testing.T) {
+	type service struct {
+		Port int `hcl:"port"`
+	}
+	var out struct {
+		Name     string              `hcl:"name"`
+		Services map[string]*service `hcl:"service"`
+		Unknown  []*UnknownBlock     `hcl:",unknown"`
+	}
+
+	src := `name = "app"
+nmae = "typo"
+
+service "web" {
+  porrt = 8080
+  port = 80
+}
+
+plugin "x" {}
+`
+	err := DecodeStrict(&out, src)
+	uerr, ok := err.(*UnusedKeysError)
+	if !ok {
+		t.Fatalf("want *UnusedKeysError, got %v", err)
+	}
+
+	want := "At 2:1: root.nmae: unknown key\nAt 5:3: root.service.web.porrt: unknown key"
+	if uerr.Error() != want {
+		t.Errorf("want error:\n%s\ngot:\n%s", want, uerr)
+	}
+
+	// the other keys are decoded
+	if out.Name != "app" || out.Services["web"].Port != 80 || len(out.Unknown) != 1 {
+		t.Errorf("unexpected result: %+v", out)
+	}
+
+	// and not reported without Strict
+	if err := Decode(&out, src); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestDecodeUnused(t *testing.T) {
+	type config struct {
+		Name   string                 `hcl:"name"`
+		Extra  map[string]interface{} `hcl:",unused"`
+		Blocks []*UnknownBlock        `hcl:",unknown"`
+	}
+
+	src := `name = "app"
+port = 80
+tags = ["a", "b"]
+cache { size = 1 }
+`
+	var out config
+	if err := DecodeStrict(&out, src); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]interface{}{"port": 80, "tags": []interface{}{"a", "b"}}
+	if out.Name != "app" || !reflect.DeepEqual(out.Extra, want) || len(out.Blocks) != 1 {
+		t.Errorf("unexpected result: %#v", out)
+	}
+
+	// the leftovers are encoded as items again
+	res, err := Marshal(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var again config
+	if err := Decode(&again, string(res)); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(again.Extra, want) {
+		t.Errorf("want leftovers %#v after encoding, got %#v", want, again.Extra)
+	}
+
+	var invalid struct {
+		Extra []string `hcl:",unused"`
+	}
+	err = Decode(&invalid, `a = 1`)
+	if err == nil || err.Error() != "At 1:1: root: field Extra with the option unused must be a map with string keys, got: []string" {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+type embeddedLogging struct {
+	Level string `hcl:"level"`
+	Name  string `hcl:"name"`
+}
+
+type EmbeddedNetwork struct {
+	Port int `hcl:"port"`
+}
+
+type embeddedConfig struct {
+	embeddedLogging
+	*EmbeddedNetwork
+	Name  string       `hcl:"name"`
+	Cache cachedConfig `hcl:",squash"`
+}
+
+type cachedConfig struct {
+	Size  int `hcl:"size"`
+	Other struct {
+		Size int `hcl:"size"`
+	} `hcl:"other"`
+}
+
+func TestDecodeEmbedded(t *testing.T) {
+	src := `level = "debug"
+name = "app"
+port = 80
+size = 2
+other { size = 3 }
+`
+	var out embeddedConfig
+	if err := DecodeStrict(&out, src); err != nil {
+		t.Fatal(err)
+	}
+
+	if out.Level != "debug" || out.Name != "app" || out.embeddedLogging.Name != "" {
+		t.Errorf("unexpected embedded fields: %+v", out.embeddedLogging)
+	}
+	if out.EmbeddedNetwork == nil || out.Port != 80 {
+		t.Errorf("unexpected embedded pointer: %+v", out.EmbeddedNetwork)
+	}
+	if out.Cache.Size != 2 || out.Cache.Other.Size != 3 {
+		t.Errorf("unexpected squashed field: %+v", out.Cache)
+	}
+
+	// embedded pointers are only allocated for their items
+	var empty embeddedConfig
+	if err := Decode(&empty, `level = "info"`); err != nil {
+		t.Fatal(err)
+	}
+	if empty.EmbeddedNetwork != nil {
+		t.Errorf("want nil embedded pointer, got %+v", empty.EmbeddedNetwork)
+	}
+
+	res, err := Marshal(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var again embeddedConfig
+	if err := Decode(&again, string(res)); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(again, out) {
+		t.Errorf("want %+v after encoding, got %+v\n%s", out, again, res)
+	}
+
+	f, err := parser.Parse([]byte(`port = "x"`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if errs := CheckTypes(f, &embeddedConfig{}); len(errs) != 1 {
+		t.Errorf("want a type error for the promoted field, got %v", errs)
+	}
+
+	var invalid struct {
+		Tags []string `hcl:",squash"`
+	}
+	err = Decode(&invalid, `a = 1`)
+	if err == nil || err.Error() != "At 1:1: root: field Tags with the option squash must be a struct, got: []string" {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+type keyedService struct {
+	Name    string   `hcl:",key"`
+	Port    int      `hcl:"port"`
+	Debug   bool     `hcl:"debug"`
+	Decoded []string `hcl:",decodedFields"`
+}
+
+type keyedConfig struct {
+	Services []keyedService           `hcl:"service"`
+	Backends map[string]*keyedService `hcl:"backend"`
+	Main     keyedService             `hcl:"main"`
+}
+
+func TestDecodeKey(t *testing.T) {
+	src := `service "web" { port = 80 }
+service "db" { debug = false }
+backend "cache" { port = 6379 }
+main { port = 8080 }
+main { debug = true }
+`
+	var out keyedConfig
+	if err := DecodeStrict(&out, src); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := keyedConfig{
+		Services: []keyedService{
+			{Name: "web", Port: 80, Decoded: []string{"Port"}},
+			{Name: "db", Decoded: []string{"Debug"}},
+		},
+		Backends: map[string]*keyedService{
+			"cache": {Name: "cache", Port: 6379, Decoded: []string{"Port"}},
+		},
+		Main: keyedService{Port: 8080, Debug: true, Decoded: []string{"Port", "Debug"}},
+	}
+	if !reflect.DeepEqual(expected, out) {
+		t.Errorf("\nwant: %+v\ngot:  %+v", expected, out)
+	}
+
+	res, err := Marshal(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var again keyedConfig
+	if err := Decode(&again, string(res)); err != nil {
+		t.Fatal(err)
+	}
+	if again.Services[1].Name != "db" || again.Backends["cache"].Name != "cache" || again.Main.Name != "" {
+		t.Errorf("unexpected keys after encoding: %+v\n%s", again, res)
+	}
+
+	f, err := parser.Parse([]byte(`service "web" { port = "x" }`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if errs := CheckTypes(f, &keyedConfig{}); len(errs) != 1 {
+		t.Errorf("want a type error in the labeled block, got %v", errs)
+	}
+
+	var invalid struct {
+		Name int `hcl:",key"`
+	}
+	err = Decode(&invalid, `a = 1`)
+	if err == nil || err.Error() != "At 1:1: root: field Name with the option key must be a string, got: int" {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestDecodeDuplicates(t *testing.T) {
+	type config struct {
+		Port int      `hcl:"port"`
+		Tags []string `hcl:"tags"`
+	}
+
+	sources := []string{
+		"port = 1\ntags = [\"a\"]\nport = 2\ntags = [\"b\"]",
+		`{"port": 1, "tags": ["a"], "port": 2, "tags": ["b"]}`,
+	}
+
+	cases := []struct {
+		policy parser.DuplicatePolicy
+		want   config
+		err    string
+	}{
+		{parser.KeepDuplicates, config{2, []string{"a", "b"}}, ""},
+		{parser.FirstWins, config{1, []string{"a"}}, ""},
+		{parser.LastWins, config{2, []string{"b"}}, ""},
+		{parser.ErrorOnDuplicates, config{}, "duplicate attribute port, first defined at"},
+	}
+
+	for i, src := range sources {
+		for _, c := range cases {
+			var out config
+			err := (&DecoderConfig{Duplicates: c.policy}).Decode(&out, src)
+			if c.err != "" {
+				want := []string{"At 3:1: " + c.err + " 1:1", "At 1:28: " + c.err + " 1:2"}[i]
+				if err == nil || err.Error() != want {
+					t.Errorf("%s: %s: want error %q, got: %v", src, c.policy, want, err)
+				}
+				continue
+			}
+			if err != nil {
+				t.Errorf("%s: %s: %s", src, c.policy, err)
+				continue
+			}
+			if !reflect.DeepEqual(c.want, out) {
+				t.Errorf("%s: %s: want %+v, got %+v", src, c.policy, c.want, out)
+			}
+		}
+	}
+
+	var out struct {
+		Port []int `hcl:"port"`
+	}
+	if err := (&DecoderConfig{Duplicates: parser.AppendDuplicates}).Decode(&out, "port = 1\nport = [2, 3]"); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(out.Port, []int{1, 2, 3}) {
+		t.Errorf("unexpected ports %v", out.Port)
+	}
+}
+
+func TestDecodeNull(t *testing.T) {
+	type config struct {
+		Name   *string           `hcl:"name"`
+		Count  int               `hcl:"count"`
+		Tags   []string          `hcl:"tags"`
+		Ports  []int             `hcl:"ports"`
+		Meta   map[string]string `hcl:"meta"`
+		Limits map[string]*int   `hcl:"limits"`
+		Extra  interface{}       `hcl:"extra"`
+	}
+
+	src := `name = null
+count = null
+tags = null
+ports = [80, null, 443]
+meta = null
+limits = {
+  cpu = null
+  mem = 2
+}
+extra = null
+`
+	name, two := "web", 2
+	out := config{
+		Name:  &name,
+		Count: 5,
+		Tags:  []string{"a"},
+		Meta:  map[string]string{"a": "b"},
+		Extra: 1,
+	}
+	dc := &DecoderConfig{Syntax: parser.Experimental, Strict: true}
+	if err := dc.Decode(&out, src); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := config{
+		Ports:  []int{80, 0, 443},
+		Limits: map[string]*int{"cpu": nil, "mem": &two},
+	}
+	if !reflect.DeepEqual(expected, out) {
+		t.Errorf("\nwant: %+v\ngot:  %+v", expected, out)
+	}
+
+	var v interface{}
+	if err := dc.Decode(&v, `a = null b = [null, 1]`); err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]interface{}{"a": nil, "b": []interface{}{nil, 1}}; !reflect.DeepEqual(want, v) {
+		t.Errorf("want %v, got %v", want, v)
+	}
+
+	f, err := (&parser.Config{Syntax: parser.Experimental}).Parse([]byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if errs := CheckTypes(f, &config{}); len(errs) != 0 {
+		t.Errorf("unexpected type errors: %v", errs)
+	}
+}
+
+func TestDecoderConfigFoldCase(t *testing.T) {
+	type config struct {
+		Service struct {
+			Enabled bool `hcl:"enabled"`
+		} `hcl:"service"`
+		Debug bool `hcl:"debug"`
+	}
+
+	src := `SERVICE {
+  Enabled = TRUE
+}
+debug = False
+`
+	var out config
+	if err := (&DecoderConfig{FoldCase: true}).Decode(&out, src); err != nil {
+		t.Fatal(err)
+	}
+
+	if !out.Service.Enabled || out.Debug {
+		t.Errorf("unexpected result: %+v", out)
+	}
+
+	// tagged fields match exactly without FoldCase
+	out = config{}
+	if err := Decode(&out, "SERVICE {\n  enabled = true\n}"); err != nil {
+		t.Fatal(err)
+	}
+
+	if out.Service.Enabled {
+		t.Errorf("unexpected result: %+v", out)
+	}
+
+	// an exact match is preferred
+	var exact struct {
+		Name string `hcl:"name"`
+	}
+	if err := (&DecoderConfig{FoldCase: true}).Decode(&exact, "NAME = \"a\"\nname = \"b\""); err != nil {
+		t.Fatal(err)
+	}
+
+	if exact.Name != "b" {
+		t.Errorf("want: b got: %s", exact.Name)
+	}
+}
+
+func TestDecoderConfigOnBlock(t *testing.T) {
+	src := `service "web" "v1" {
+  port = 80
+
+  check {
+    path = "/"
+  }
+}
+
+service "db" {
+  port = 5432
+}
+
+name = "app"
+settings = {
+  debug = true
+}
+`
+	var blocks []string
+	config := &DecoderConfig{
+		OnBlock: func(typ string, labels []string, item *ast.ObjectItem) error {
+			blocks = append(blocks, fmt.Sprintf("%s %v %s", typ, labels, item.Pos()))
+			return nil
+		},
+	}
+
+	var out struct {
+		Service map[string]interface{} `hcl:"service"`
+		Name    string                 `hcl:"name"`
+	}
+	if err := config.Decode(&out, src); err != nil {
+		t.Fatal(err)
+	}
+
+	// the blocks of an object are reported before any of them is decoded
+	want := []string{"service [web v1] 1:1", "service [db] 9:1", "check [] 4:3"}
+	if !reflect.DeepEqual(blocks, want) {
+		t.Errorf("want: %q got: %q", want, blocks)
+	}
+
+	// errors stop the decoding
+	config.OnBlock = func(typ string, labels []string, item *ast.ObjectItem) error {
+		if typ == "check" {
+			return errors.New("check blocks are experimental")
+		}
+		return nil
+	}
+
+	err := config.Decode(&out, src)
+	if err == nil || err.Error() != "At 4:3: check blocks are experimental" {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestDecodeUnits(t *testing.T) {
+	type limits struct {
+		Timeout  time.Duration  `hcl:"timeout"`
+		Interval *time.Duration `hcl:"interval"`
+		Memory   int            `hcl:"memory"`
+		Disk     uint64         `hcl:"disk"`
+		Ratio    float64        `hcl:"ratio"`
+		Raw      string         `hcl:"raw"`
+		Any      interface{}    `hcl:"any"`
+		Sizes    []int          `hcl:"sizes"`
+	}
+
+	src := `timeout = 1m30s
+interval = 500ms
+memory = 512k
+disk = 1GiB
+ratio = 80%
+raw = 10s
+any = 5m
+sizes = [1KiB, 2k]
+`
+	f, err := (&parser.Config{Syntax: parser.Experimental}).Parse([]byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out limits
+	if err := DecodeObject(&out, f); err != nil {
+		t.Fatal(err)
+	}
+
+	interval := 500 * time.Millisecond
+	want := limits{
+		Timeout:  90 * time.Second,
+		Interval: &interval,
+		Memory:   512000,
+		Disk:     1 << 30,
+		Ratio:    0.8,
+		Raw:      "10s",
+		Any:      "5m",
+		Sizes:    []int{1024, 2000},
+	}
+	if !reflect.DeepEqual(out, want) {
+		t.Errorf("want: %+v\ngot:  %+v", want, out)
+	}
+
+	if errs := CheckTypes(f, (*limits)(nil)); len(errs) != 0 {
+		t.Errorf("unexpected errors: %v", errs)
+	}
+
+	errCases := []struct {
+		src string
+		err string
+	}{
+		{"memory = 10q", "At 1:10: root.memory: unknown unit \"q\" of 10q"},
+		{"memory = 10%", "At 1:10: root.memory: cannot decode number into int"},
+		{"timeout = 10k", "At 1:11: root.timeout: invalid duration 10k"},
+		{"sizes = [1k, 2q]", "At 1:14: root.sizes[1]: unknown unit \"q\" of 2q"},
+	}
+
+	for _, c := range errCases {
+		f, err := (&parser.Config{Syntax: parser.Experimental}).Parse([]byte(c.src))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var out limits
+		err = DecodeObject(&out, f)
+		if err == nil || err.Error() != c.err {
+			t.Errorf("%s: want error %q, got: %v", c.src, c.err, err)
+		}
+
+		errs := CheckTypes(f, (*limits)(nil))
+		if len(errs) != 1 || errs[0].Error() != c.err {
+			t.Errorf("%s: want check error %q, got: %v", c.src, c.err, errs)
+		}
+	}
+}
+
+func TestDecoderConfigFields(t *testing.T) {
+	type config struct {
+		Name     string
+		secret   string
+		OnReload func()       `hcl:"on_reload"`
+		Events   *chan string `hcl:"events"`
+		mu       chan bool
+	}
+
+	src := `name = "app"
+secret = "s3cr3t"
+on_reload = "restart"
+`
+	cases := []struct {
+		policy   FieldPolicy
+		warnings []string
+		err      string
+	}{
+		{SkipFields, nil, ""},
+		{WarnFields, []string{
+			"At 2:10: root.secret: field secret is unexported",
+			"At 3:13: root.on_reload: field OnReload has the unsupported type func()",
+		}, ""},
+		{RejectFields, nil, "At 2:10: root.secret: field secret is unexported"},
+	}
+
+	for _, c := range cases {
+		var warnings []string
+		dc := &DecoderConfig{
+			Fields: c.policy,
+			Warn:   func(err error) { warnings = append(warnings, err.Error()) },
+		}
+
+		var out config
+		err := dc.Decode(&out, src)
+		if c.err != "" {
+			if err == nil || err.Error() != c.err {
+				t.Errorf("policy %d: want error %q, got: %v", c.policy, c.err, err)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("policy %d: %s", c.policy, err)
+			continue
+		}
+
+		if out.Name != "app" || out.secret != "" || out.OnReload != nil {
+			t.Errorf("policy %d: unexpected result: %+v", c.policy, out)
+		}
+
+		if !reflect.DeepEqual(warnings, c.warnings) {
+			t.Errorf("policy %d: want warnings %q, got: %q", c.policy, c.warnings, warnings)
+		}
+	}
+
+	// CheckTypes reports rejected fields too
+	f, err := parser.Parse([]byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dc := &DecoderConfig{Fields: RejectFields}
+	if errs := dc.CheckTypes(f, (*config)(nil)); len(errs) != 2 {
+		t.Errorf("want 2 errors, got: %v", errs)
+	}
+}
+
+// phaseLogger records the messages and phases logged
+type phaseLogger []string
+
+func (l *phaseLogger) Debug(msg string, args ...interface{}) {
+	for i := 0; i+1 < len(args); i += 2 {
+		if args[i] == "phase" {
+			msg += " " + args[i+1].(string)
+		}
+	}
+	*l = append(*l, msg)
+}
+
+func TestDecoderConfigLimits(t *testing.T) {
+	src := `hosts = ["a", "b", "c"]
+tags {
+  x = 1
+  y = 2
+}
+extra = [1, 2, 3]
+`
+	type config struct {
+		Hosts []string               `hcl:"hosts"`
+		Tags  map[string]int         `hcl:"tags"`
+		Extra interface{}            `hcl:"extra"`
+		Rest  map[string]interface{} `hcl:",unused"`
+	}
+
+	cases := []struct {
+		config DecoderConfig
+		err    string
+	}{
+		{DecoderConfig{MaxValues: 11, MaxSliceLen: 3, MaxMapSize: 2}, ""},
+		{DecoderConfig{MaxValues: 10}, "At 6:16: root.extra: resource limit exceeded: more than 10 decoded values"},
+		{DecoderConfig{MaxSliceLen: 2}, "At 1:9: root.hosts: resource limit exceeded: more than 2 elements in a slice"},
+		{DecoderConfig{MaxMapSize: 1}, "At 4:7: root.tags: resource limit exceeded: more than 1 entries in a map"},
+	}
+
+	for _, c := range cases {
+		var out config
+		err := c.config.Decode(&out, src)
+		if c.err == "" {
+			if err != nil {
+				t.Errorf("%+v: unexpected error: %v", c.config, err)
+			}
+			continue
+		}
+
+		var limitErr *parser.LimitError
+		if err == nil || err.Error() != c.err || !errors.As(err, &limitErr) {
+			t.Errorf("%+v: want error %q, got %v", c.config, c.err, err)
+		}
+	}
+
+	// values decoded into empty interfaces count as well
+	var out interface{}
+	err := (&DecoderConfig{MaxSliceLen: 2}).Decode(&out, `a = [1, 2, 3]`)
+	if err == nil || err.Error() != "At 1:5: root.a: resource limit exceeded: more than 2 elements in a slice" {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestDecoderConfigLogger(t *testing.T) {
+	var l phaseLogger
+	var out service
+	if err := (&DecoderConfig{Logger: &l}).Decode(&out, "port = 80"); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"hcl: parsed parse", "hcl: decoded decode"}
+	if !reflect.DeepEqual([]string(l), want) {
+		t.Errorf("want %q, got: %q", want, l)
+	}
+}
+
+func TestDecodeQuotedKeys(t *testing.T) {
+	type config struct {
+		Key    string         `hcl:"my.key/with:chars"`
+		Labels map[string]int `hcl:"labels"`
+	}
+
+	src := `"my.key/with:chars" = "value"
+labels {
+  "app.kubernetes.io/name" = 1
+  "a/b" = 2
+}`
+
+	var out config
+	if err := Decode(&out, src); err != nil {
+		t.Fatal(err)
+	}
+
+	want := config{Key: "value", Labels: map[string]int{"app.kubernetes.io/name": 1, "a/b": 2}}
+	if !reflect.DeepEqual(out, want) {
+		t.Errorf("want %+v, got %+v", want, out)
+	}
+
+	// paths of errors quote keys containing dots
+	err := Decode(&out, `labels { "app.kubernetes.io/name" = "web" }`)
+	if err == nil || err.Error() != `At 1:37: root.labels."app.kubernetes.io/name": cannot decode string into int` {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestDecodePointers(t *testing.T) {
+	type server struct {
+		Name string `hcl:"name"`
+		Port *int   `hcl:"port"`
+	}
+
+	type config struct {
+		Primary  **server            `hcl:"primary"`
+		Servers  *[]server           `hcl:"servers"`
+		Replicas []*server           `hcl:"replicas"`
+		Backups  *[]*server          `hcl:"backups"`
+		Regions  map[string]**server `hcl:"region"`
+		Retries  ***int              `hcl:"retries"`
+		Tags     *[]*string          `hcl:"tags"`
+	}
+
+	src := `primary { name = "a" port = 80 }
+servers { name = "b" }
+servers { name = "c" }
+replicas { name = "d" }
+backups { name = "e" }
+region "eu" { name = "f" }
+retries = 3
+tags = ["x", "y"]
+`
+
+	var out config
+	if err := Decode(&out, src); err != nil {
+		t.Fatal(err)
+	}
+
+	if p := **out.Primary; p.Name != "a" || *p.Port != 80 {
+		t.Errorf("unexpected primary: %+v", p)
+	}
+	if s := *out.Servers; len(s) != 2 || s[0].Name != "b" || s[1].Name != "c" {
+		t.Errorf("unexpected servers: %+v", s)
+	}
+	if len(out.Replicas) != 1 || out.Replicas[0].Name != "d" {
+		t.Errorf("unexpected replicas: %+v", out.Replicas)
+	}
+	if b := *out.Backups; len(b) != 1 || b[0].Name != "e" {
+		t.Errorf("unexpected backups: %+v", b)
+	}
+	if r := out.Regions["eu"]; r == nil || (**r).Name != "f" {
+		t.Errorf("unexpected regions: %+v", out.Regions)
+	}
+	if ***out.Retries != 3 {
+		t.Errorf("unexpected retries: %d", ***out.Retries)
+	}
+	if tags := *out.Tags; len(tags) != 2 || *tags[0] != "x" || *tags[1] != "y" {
+		t.Errorf("unexpected tags: %v", tags)
+	}
+
+	// existing pointers are decoded into, also through interfaces
+	primary := *out.Primary
+	if err := Decode(&out, `primary { port = 443 }`); err != nil {
+		t.Fatal(err)
+	}
+	if *out.Primary != primary || primary.Name != "a" || *primary.Port != 443 {
+		t.Errorf("expected the existing primary to be updated, got: %+v", **out.Primary)
+	}
+
+	s := &server{Name: "g"}
+	var v interface{} = s
+	if err := Decode(&v, `port = 8080`); err != nil {
+		t.Fatal(err)
+	}
+	if v != s || s.Name != "g" || *s.Port != 8080 {
+		t.Errorf("expected the server in the interface to be updated, got: %+v", v)
+	}
+}
+
+func TestDecodeHeredoc(t *testing.T) {
+	var out struct {
+		Script  string `hcl:"script"`
+		Service struct {
+			Motd string `hcl:"motd"`
+		} `hcl:"service"`
+		Greet string   `hcl:"greet"`
+		Lines []string `hcl:"lines"`
+	}
+
+	src := "script = <<EOF\n#!/bin/sh\necho \"\\n\"\nEOF\n" +
+		"service {\n  motd = <<-EOT\n    Welcome!\n      Be nice.\n    EOT\n}\n" +
+		"greet = <<EOF\nhello ${var.name}\nEOF\n" +
+		"lines = [<<A\none\nA\n, \"two\"]\n"
+
+	config := &DecoderConfig{
+		Resolver: func(t *ast.Template) (interface{}, error) {
+			return eval.Eval(t, map[string]interface{}{"var.name": "web"})
+		},
+	}
+	if err := config.Decode(&out, src); err != nil {
+		t.Fatal(err)
+	}
+
+	if out.Script != "#!/bin/sh\necho \"\\n\"\n" || out.Service.Motd != "Welcome!\n  Be nice.\n" || out.Greet != "hello web\n" {
+		t.Errorf("unexpected result: %+v", out)
+	}
+	if !reflect.DeepEqual(out.Lines, []string{"one\n", "two"}) {
+		t.Errorf("unexpected lines: %q", out.Lines)
+	}
+}
+
+func TestDecodeJSON(t *testing.T) {
+	type config struct {
+		Name    string                    `hcl:"name"`
+		Service map[string]service        `hcl:"service"`
+		Rules   []map[string]interface{}  `hcl:"rule"`
+		Limits  map[string]map[string]int `hcl:"limits"`
+	}
+
+	hclSrc := `name = "app"
+service "web" { Port = 80 tags = ["a"] }
+service "db" { Port = 5432 }
+rule { allow = true }
+rule { allow = false }
+limits "cpu" { max = 2 }
+`
+	jsonSrc := `
+{
+  "name": "app",
+  "service": {"web": {"Port": 80, "tags": ["a"]}, "db": {"Port": 5432}},
+  "rule": [{"allow": true}, {"allow": false}],
+  "limits": {"cpu": {"max": 2}}
+}`
+
+	var want, got config
+	if err := Decode(&want, hclSrc); err != nil {
+		t.Fatal(err)
+	}
+	if err := Decode(&got, jsonSrc); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("want %+v, got %+v", want, got)
+	}
+
+	err := Decode(&got, `{"name": 1, "service": {"web": {"Port": "x"}}}`)
+	if err == nil || err.Error() != `At 1:41: root.service.web.Port: cannot decode string into int` {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestDecodeBOM(t *testing.T) {
+	type config struct {
+		Name string `hcl:"name"`
+		Port int    `hcl:"port"`
+	}
+
+	want := config{Name: "é", Port: 80}
+	for _, src := range []string{
+		"\ufeffname = \"\u00e9\"\r\nport = 80\r\n",
+		"\ufeffname = \"\\u00e9\"\r\nport = 80\r\n",
+		"\ufeff{\"name\": \"\\u00e9\", \"port\": 80}",
+	} {
+		var got config
+		if err := Decode(&got, src); err != nil {
+			t.Errorf("%q: %s", src, err)
+		} else if got != want {
+			t.Errorf("%q: want %+v, got %+v", src, want, got)
+		}
+	}
+
+	var out config
+	err := Decode(&out, "\ufeffname = \"a\"\r\nport = \"x\"\r\n")
+	if err == nil || err.Error() != `At 2:8: root.port: cannot decode string into int` {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
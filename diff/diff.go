@@ -0,0 +1,226 @@
+// Package diff computes the semantic differences between two HCL (HashiCorp
+// Configuration Language) syntax trees. Formatting and comments are ignored.
+package diff
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/fatih/hcl/ast"
+	"github.com/fatih/hcl/printer"
+	"github.com/fatih/hcl/token"
+)
+
+// Kind is the kind of a Change.
+type Kind int
+
+const (
+	Added Kind = iota
+	Removed
+	Changed
+)
+
+func (k Kind) String() string {
+	switch k {
+	case Added:
+		return "added"
+	case Removed:
+		return "removed"
+	case Changed:
+		return "changed"
+	}
+	return "kind(" + strconv.Itoa(int(k)) + ")"
+}
+
+// Change is a single difference between two syntax trees.
+type Change struct {
+	Kind Kind
+
+	// Path contains the keys leading to the changed value, string keys are
+	// unquoted. The keys of a block, such as `service "web"`, are all part of
+	// the path.
+	Path []string
+
+	Old ast.Node // the old value, nil if Kind is Added
+	New ast.Node // the new value, nil if Kind is Removed
+}
+
+// String returns the change in a diff like form, i.e. for a modified, an
+// added and a removed value:
+//
+//	~ bar = 1 => 2
+//	+ service.web.port = 8080
+//	- foo = "bar"
+func (c Change) String() string {
+	path := ast.JoinPath(c.Path)
+	switch c.Kind {
+	case Added:
+		return fmt.Sprintf("+ %s = %s", path, Format(c.New))
+	case Removed:
+		return fmt.Sprintf("- %s = %s", path, Format(c.Old))
+	default:
+		return fmt.Sprintf("~ %s = %s => %s", path, Format(c.Old), Format(c.New))
+	}
+}
+
+// Format returns the formatted HCL of a value.
+func Format(n ast.Node) string {
+	if n == nil {
+		return ""
+	}
+
+	var buf bytes.Buffer
+	if err := printer.Fprint(&buf, n); err != nil {
+		return fmt.Sprintf("<%s>", err)
+	}
+	return buf.String()
+}
+
+// Files returns the changes needed to turn a into b. Changes of nested objects
+// are reported for each of their items instead of for the whole object. Items
+// with the same keys are matched in the order of their appearance.
+func Files(a, b *ast.File) []Change {
+	return Nodes(a.Node, b.Node)
+}
+
+// Nodes returns the changes needed to turn the node a into b.
+func Nodes(a, b ast.Node) []Change {
+	var d differ
+	d.value(nil, a, b)
+	return d.changes
+}
+
+type differ struct {
+	changes []Change
+}
+
+func (d *differ) add(kind Kind, path []string, a, b ast.Node) {
+	d.changes = append(d.changes, Change{
+		Kind: kind,
+		Path: path,
+		Old:  a,
+		New:  b,
+	})
+}
+
+func (d *differ) value(path []string, a, b ast.Node) {
+	la, aok := ast.ObjectListOf(a)
+	lb, bok := ast.ObjectListOf(b)
+	if aok && bok {
+		d.list(path, la, lb)
+		return
+	}
+
+	if !Equal(a, b) {
+		d.add(Changed, path, a, b)
+	}
+}
+
+func (d *differ) list(path []string, a, b *ast.ObjectList) {
+	bItems := make(map[string][]*ast.ObjectItem)
+	for _, item := range b.Items {
+		k := key(item)
+		bItems[k] = append(bItems[k], item)
+	}
+
+	seen := make(map[string]int)
+	for _, item := range a.Items {
+		k := key(item)
+		i := seen[k]
+		seen[k]++
+
+		p := itemPath(path, item)
+		if i >= len(bItems[k]) {
+			d.add(Removed, p, item.Val, nil)
+			continue
+		}
+
+		d.value(p, item.Val, bItems[k][i].Val)
+	}
+
+	added := make(map[string]int)
+	for _, item := range b.Items {
+		k := key(item)
+		added[k]++
+		if added[k] > seen[k] {
+			d.add(Added, itemPath(path, item), nil, item.Val)
+		}
+	}
+}
+
+// Equal reports whether the values a and b are semantically equal, regardless
+// of their positions, formatting and comments. Literals are compared by their
+// values, so "x" equals a heredoc of x and 16 equals 0x10.
+func Equal(a, b ast.Node) bool {
+	if la, ok := ast.ObjectListOf(a); ok {
+		lb, ok := ast.ObjectListOf(b)
+		return ok && len(Nodes(la, lb)) == 0
+	}
+
+	switch x := a.(type) {
+	case *ast.LiteralType:
+		y, ok := b.(*ast.LiteralType)
+		return ok && equalLiterals(x.Token, y.Token)
+	case *ast.ListType:
+		y, ok := b.(*ast.ListType)
+		if !ok || len(x.List) != len(y.List) {
+			return false
+		}
+
+		for i := range x.List {
+			if !Equal(x.List[i], y.List[i]) {
+				return false
+			}
+		}
+		return true
+	}
+
+	return false
+}
+
+// equalLiterals reports whether the literals a and b stand for the same
+// value: strings and heredocs with the same value, numbers of the same value,
+// such as 0x10 and 16 or 1 and 1.0, and keywords regardless of their case
+func equalLiterals(a, b token.Token) bool {
+	switch {
+	case isString(a.Type) && isString(b.Type):
+		return a.Value() == b.Value()
+	case isNumber(a.Type) && isNumber(b.Type):
+		x, err := a.BigFloat()
+		if err != nil {
+			break
+		}
+		y, err := b.BigFloat()
+		if err != nil {
+			break
+		}
+		return x.Cmp(y) == 0
+	case a.Type.IsKeyword() && a.Type == b.Type:
+		return strings.EqualFold(a.Text, b.Text)
+	}
+	return a.Type == b.Type && a.Text == b.Text
+}
+
+func isString(t token.Type) bool {
+	return t == token.STRING || t == token.HEREDOC
+}
+
+func isNumber(t token.Type) bool {
+	return t == token.NUMBER || t == token.FLOAT
+}
+
+func itemPath(path []string, item *ast.ObjectItem) []string {
+	p := make([]string, len(path), len(path)+len(item.Keys))
+	copy(p, path)
+	for _, k := range item.Keys {
+		p = append(p, k.Token.Value())
+	}
+	return p
+}
+
+// key returns a key to match the items with the same keys
+func key(item *ast.ObjectItem) string {
+	return strings.Join(itemPath(nil, item), "\x00")
+}
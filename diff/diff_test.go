@@ -0,0 +1,85 @@
+package diff
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/fatih/hcl/parser"
+)
+
+func TestFiles(t *testing.T) {
+	cases := []struct {
+		a, b    string
+		changes []string
+	}{
+		{
+			`foo = "bar"`,
+			`foo    =    "bar" # formatting and comments are ignored`,
+			nil,
+		},
+		{
+			`foo = 1`,
+			`foo = 2`,
+			[]string{"~ foo = 1 => 2"},
+		},
+		{
+			`foo = 1`,
+			`bar = true`,
+			[]string{"- foo = 1", "+ bar = true"},
+		},
+		{
+			`service "web" { port = 80 }`,
+			`service "web" {
+			   port = 8080
+			   tags = ["a"]
+			 }`,
+			[]string{"~ service.web.port = 80 => 8080", `+ service.web.tags = ["a"]`},
+		},
+		{
+			`a { b = 1 }`,
+			`a = { b = 1 }`,
+			nil,
+		},
+		{
+			`list = [1, 2]`,
+			`list = [1, 2, 3]`,
+			[]string{"~ list = [1, 2] => [1, 2, 3]"},
+		},
+		{
+			"p = 1\np = 2",
+			"p = 1\np = 3\np = 4",
+			[]string{"~ p = 2 => 3", "+ p = 4"},
+		},
+		{
+			`s = "x\n" n = 16 f = 1 l = [1.5e3]`,
+			"s = <<EOF\nx\nEOF\nn = 0x10\nf = 1.0\nl = [1500]",
+			nil,
+		},
+		{
+			`a = "1" b = 1 c = true`,
+			`a = 1 b = 1.5 c = TRUE`,
+			[]string{`~ a = "1" => 1`, "~ b = 1 => 1.5"},
+		},
+	}
+
+	for _, c := range cases {
+		a, err := parser.Parse([]byte(c.a))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		b, err := (&parser.Config{FoldCase: true}).Parse([]byte(c.b))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var changes []string
+		for _, change := range Files(a, b) {
+			changes = append(changes, change.String())
+		}
+
+		if !reflect.DeepEqual(changes, c.changes) {
+			t.Errorf("want: %q got: %q", c.changes, changes)
+		}
+	}
+}
@@ -0,0 +1,434 @@
+// Package edit changes HCL (HashiCorp Configuration Language) source with
+// minimal edits. Instead of printing the whole syntax tree, every change
+// replaces the byte range of the affected nodes only, so formatting and
+// comments of the remaining source are preserved exactly.
+package edit
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/fatih/hcl/ast"
+	"github.com/fatih/hcl/parser"
+	"github.com/fatih/hcl/printer"
+	"github.com/fatih/hcl/token"
+)
+
+// Editor edits HCL source. Paths consist of the keys of the items separated
+// by dots, i.e. "service.web.port" is the port of the block
+// service "web" { port = 80 }. Keys containing dots are quoted, see
+// ast.SplitPath.
+type Editor struct {
+	src []byte
+}
+
+// New returns an editor of src. It returns an error if src is invalid.
+func New(src []byte) (*Editor, error) {
+	if _, err := parser.Parse(src); err != nil {
+		return nil, err
+	}
+	return &Editor{src: src}, nil
+}
+
+// Bytes returns the edited source.
+func (e *Editor) Bytes() []byte {
+	return e.src
+}
+
+// SetAttribute sets the value of the attribute at path. If it doesn't exist,
+// it's added as the last item of the enclosing block, which must exist. The
+// value is a Go value (string, number, bool, slice or map with string keys)
+// or an ast.Node.
+func (e *Editor) SetAttribute(path string, value interface{}) error {
+	f, err := parser.Parse(e.src)
+	if err != nil {
+		return err
+	}
+
+	keys := split(path)
+	if len(keys) == 0 {
+		return fmt.Errorf("empty path")
+	}
+
+	val, err := format(value)
+	if err != nil {
+		return err
+	}
+
+	if item := find(f.Node.(*ast.ObjectList), keys); item != nil {
+		if _, ok := item.Val.(*ast.ObjectType); ok && item.Assign.Line == 0 {
+			return fmt.Errorf("%s: %s is a block", item.Pos(), path)
+		}
+
+		start := item.Val.Pos().Offset
+		e.replace(start, end(item.Val), indent(val, e.lineIndent(start)))
+		return nil
+	}
+
+	list, closing, err := e.block(f, keys[:len(keys)-1])
+	if err != nil {
+		return err
+	}
+
+	name := keys[len(keys)-1]
+	return e.insert(list, closing, keyText(name)+" = "+val, false)
+}
+
+// RemoveBlock removes the blocks at path, along with their comments.
+func (e *Editor) RemoveBlock(path string) error {
+	return e.remove(path, true)
+}
+
+// RemoveAttribute removes the attributes at path, along with their comments,
+// i.e. all port attributes of the blocks service "web" for
+// "service.web.port".
+func (e *Editor) RemoveAttribute(path string) error {
+	return e.remove(path, false)
+}
+
+// remove removes the blocks or attributes at path
+func (e *Editor) remove(path string, block bool) error {
+	f, err := parser.Parse(e.src)
+	if err != nil {
+		return err
+	}
+
+	kind := "attribute"
+	if block {
+		kind = "block"
+	}
+
+	var items []*ast.ObjectItem
+	collect(f.Node.(*ast.ObjectList), split(path), &items)
+	if len(items) == 0 {
+		return fmt.Errorf("no %s %s", kind, path)
+	}
+
+	// check all items before the source changes
+	for _, item := range items {
+		_, ok := item.Val.(*ast.ObjectType)
+		switch {
+		case block && !ok:
+			return fmt.Errorf("%s: %s is not a block", item.Pos(), path)
+		case !block && ok && item.Assign.Line == 0:
+			return fmt.Errorf("%s: %s is a block", item.Pos(), path)
+		}
+	}
+
+	// remove from the end, so the offsets of the remaining items are valid
+	for i := len(items) - 1; i >= 0; i-- {
+		item := items[i]
+
+		start := item.Pos().Offset
+		if item.LeadComment != nil {
+			start = item.LeadComment.Pos().Offset
+		}
+
+		stop := end(item.Val)
+		if item.LineComment != nil {
+			stop = end(item.LineComment)
+		}
+
+		start, stop = e.expandLines(start, stop)
+		e.replace(start, stop, "")
+	}
+	return nil
+}
+
+// AppendBlock appends the block n after the last item of the block at path,
+// or of the file if path is empty. n is printed with package printer.
+func (e *Editor) AppendBlock(path string, n *ast.ObjectItem) error {
+	f, err := parser.Parse(e.src)
+	if err != nil {
+		return err
+	}
+
+	list, closing, err := e.block(f, split(path))
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	if err := printer.Fprint(&buf, n); err != nil {
+		return err
+	}
+	return e.insert(list, closing, buf.String(), true)
+}
+
+// block returns the list of the block at keys and the offset of its closing
+// brace, or -1 for the file
+func (e *Editor) block(f *ast.File, keys []string) (*ast.ObjectList, int, error) {
+	list := f.Node.(*ast.ObjectList)
+	if len(keys) == 0 {
+		return list, -1, nil
+	}
+
+	item := find(list, keys)
+	if item == nil {
+		return nil, 0, fmt.Errorf("no block %s", ast.JoinPath(keys))
+	}
+
+	obj, ok := item.Val.(*ast.ObjectType)
+	if !ok {
+		return nil, 0, fmt.Errorf("%s: %s is not a block", item.Pos(), ast.JoinPath(keys))
+	}
+	return obj.List, obj.Rbrace.Offset, nil
+}
+
+// insert inserts text as a new item after the last item of list, whose
+// block is closed at the given offset, or -1 for the file. Blocks are
+// separated from the previous item by an empty line.
+func (e *Editor) insert(list *ast.ObjectList, closing int, text string, block bool) error {
+	var ind string
+	switch {
+	case len(list.Items) > 0:
+		ind = e.lineIndent(list.Items[0].Pos().Offset)
+	case closing >= 0:
+		ind = e.lineIndent(closing) + "  "
+	}
+
+	text = ind + indent(text, ind) + "\n"
+	if block && len(list.Items) > 0 {
+		text = "\n" + text
+	}
+
+	if closing < 0 {
+		// end of the file
+		src := bytes.TrimRight(e.src, " \t\r\n")
+		if len(src) > 0 {
+			text = "\n" + text
+		}
+		e.src = append(src, text...)
+		return nil
+	}
+
+	// before the line of the closing brace, or break the line if the brace
+	// follows other text
+	start := closing
+	for start > 0 && (e.src[start-1] == ' ' || e.src[start-1] == '\t') {
+		start--
+	}
+
+	if start > 0 && e.src[start-1] != '\n' {
+		text = "\n" + text + e.lineIndent(start)
+	}
+	e.replace(start, start, text)
+	return nil
+}
+
+// replace replaces the bytes from start to end with text
+func (e *Editor) replace(start, end int, text string) {
+	src := make([]byte, 0, len(e.src)-(end-start)+len(text))
+	src = append(src, e.src[:start]...)
+	src = append(src, text...)
+	src = append(src, e.src[end:]...)
+	e.src = src
+}
+
+// lineIndent returns the whitespace at the beginning of the line containing
+// offset
+func (e *Editor) lineIndent(offset int) string {
+	start := bytes.LastIndexByte(e.src[:offset], '\n') + 1
+	stop := start
+	for stop < len(e.src) && (e.src[stop] == ' ' || e.src[stop] == '\t') {
+		stop++
+	}
+	return string(e.src[start:stop])
+}
+
+// expandLines expands the range from start to end to whole lines, if there's
+// only whitespace before and after the range on its lines. An empty line
+// before the range is included, if it's followed by another one.
+func (e *Editor) expandLines(start, end int) (int, int) {
+	s := start
+	for s > 0 && (e.src[s-1] == ' ' || e.src[s-1] == '\t') {
+		s--
+	}
+
+	t := end
+	for t < len(e.src) && (e.src[t] == ' ' || e.src[t] == '\t' || e.src[t] == '\r') {
+		t++
+	}
+
+	if (s > 0 && e.src[s-1] != '\n') || (t < len(e.src) && e.src[t] != '\n') {
+		return start, end
+	}
+
+	if t < len(e.src) {
+		t++ // newline
+	}
+
+	// don't leave two empty lines, or an empty line at the end
+	if s > 1 && e.src[s-2] == '\n' && (t == len(e.src) || e.src[t] == '\n') {
+		s--
+	}
+	return s, t
+}
+
+// find returns the first item matching keys in list
+func find(list *ast.ObjectList, keys []string) *ast.ObjectItem {
+	var items []*ast.ObjectItem
+	collect(list, keys, &items)
+	if len(items) == 0 {
+		return nil
+	}
+	return items[0]
+}
+
+// collect appends all items matching keys in list to items. The keys of an
+// item, i.e. service "web", match multiple keys of the path.
+func collect(list *ast.ObjectList, keys []string, items *[]*ast.ObjectItem) {
+	for _, item := range list.Items {
+		if len(item.Keys) > len(keys) {
+			continue
+		}
+
+		match := true
+		for i, k := range item.Keys {
+			if k.Token.Value() != keys[i] {
+				match = false
+				break
+			}
+		}
+		if !match {
+			continue
+		}
+
+		if len(item.Keys) == len(keys) {
+			*items = append(*items, item)
+			continue
+		}
+
+		if obj, ok := item.Val.(*ast.ObjectType); ok && obj.List != nil {
+			collect(obj.List, keys[len(item.Keys):], items)
+		}
+	}
+}
+
+// end returns the offset after the last byte of n
+func end(n ast.Node) int {
+	switch t := n.(type) {
+	case *ast.LiteralType:
+		return t.Token.Pos.Offset + len(t.Token.Text)
+	case *ast.ListType:
+		return t.Rbrack.Offset + 1
+	case *ast.ObjectType:
+		return t.Rbrace.Offset + 1
+	case *ast.CommentGroup:
+		last := t.List[len(t.List)-1]
+		return last.Start.Offset + len(strings.TrimRight(last.Text, "\n"))
+	case *ast.ObjectItem:
+		return end(t.Val)
+	}
+	return n.Pos().Offset
+}
+
+// format returns the HCL text of a Go value or node
+func format(v interface{}) (string, error) {
+	n, ok := v.(ast.Node)
+	if !ok {
+		var err error
+		if n, err = node(reflect.ValueOf(v)); err != nil {
+			return "", err
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := printer.Fprint(&buf, n); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// node returns the syntax tree of a Go value
+func node(rv reflect.Value) (ast.Node, error) {
+	lit := func(typ token.Type, text string) ast.Node {
+		return &ast.LiteralType{Token: token.Token{Type: typ, Text: text}}
+	}
+
+	switch rv.Kind() {
+	case reflect.String:
+		return lit(token.STRING, strconv.Quote(rv.String())), nil
+	case reflect.Bool:
+		return lit(token.BOOL, strconv.FormatBool(rv.Bool())), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return lit(token.NUMBER, strconv.FormatInt(rv.Int(), 10)), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return lit(token.NUMBER, strconv.FormatUint(rv.Uint(), 10)), nil
+	case reflect.Float32, reflect.Float64:
+		return lit(token.FLOAT, strconv.FormatFloat(rv.Float(), 'f', -1, 64)), nil
+	case reflect.Interface, reflect.Ptr:
+		if !rv.IsNil() {
+			return node(rv.Elem())
+		}
+	case reflect.Slice, reflect.Array:
+		list := &ast.ListType{}
+		for i := 0; i < rv.Len(); i++ {
+			elem, err := node(rv.Index(i))
+			if err != nil {
+				return nil, err
+			}
+			list.Add(elem)
+		}
+		return list, nil
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			break
+		}
+
+		keys := make([]string, 0, rv.Len())
+		for _, k := range rv.MapKeys() {
+			keys = append(keys, k.String())
+		}
+		sort.Strings(keys)
+
+		obj := &ast.ObjectType{List: &ast.ObjectList{}}
+		for _, k := range keys {
+			val, err := node(rv.MapIndex(reflect.ValueOf(k).Convert(rv.Type().Key())))
+			if err != nil {
+				return nil, err
+			}
+
+			obj.List.Add(&ast.ObjectItem{
+				Keys: []*ast.ObjectKey{{Token: token.Token{Type: token.IDENT, Text: keyText(k)}}},
+				Val:  val,
+			})
+		}
+		return obj, nil
+	}
+
+	if !rv.IsValid() {
+		return nil, fmt.Errorf("can't set a nil value")
+	}
+	return nil, fmt.Errorf("unsupported value of type %s", rv.Type())
+}
+
+// indent indents all non-empty lines of text except the first one with ind
+func indent(text, ind string) string {
+	lines := strings.Split(text, "\n")
+	for i := 1; i < len(lines); i++ {
+		if lines[i] != "" {
+			lines[i] = ind + lines[i]
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// keyText returns the key name, quoted if it's not a valid identifier
+func keyText(name string) string {
+	for i, ch := range name {
+		if ch == '_' || ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z' || i > 0 && (ch >= '0' && ch <= '9' || ch == '-') {
+			continue
+		}
+		return strconv.Quote(name)
+	}
+	return name
+}
+
+func split(path string) []string {
+	return ast.SplitPath(path)
+}
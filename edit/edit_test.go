@@ -0,0 +1,263 @@
+package edit
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/fatih/hcl/ast"
+	"github.com/fatih/hcl/token"
+)
+
+const src = `# global settings
+name    = "app"   # the name
+version = 1
+
+service "web" {
+    # the port
+    port = 80
+
+    tags = ["a",   "b"]
+}
+
+/* legacy */
+service "old" {
+    port = 81
+}
+`
+
+func TestSetAttribute(t *testing.T) {
+	cases := []struct {
+		path     string
+		value    interface{}
+		expected string
+	}{
+		{"name", "new", `# global settings
+name    = "new"   # the name
+version = 1
+`},
+		{"service.web.port", 8080, `    # the port
+    port = 8080
+`},
+		{"service.web.tags", []string{"c"}, `    tags = ["c"]
+}`},
+		{"service.web.host", "localhost", `    tags = ["a",   "b"]
+    host = "localhost"
+}`},
+		{"debug", true, `    port = 81
+}
+debug = true
+`},
+		{"service.web.limits", map[string]interface{}{"cpu": 1.5, "memory-mb": 512}, `    tags = ["a",   "b"]
+    limits = {
+      cpu = 1.5
+
+      memory-mb = 512
+    }
+}`},
+	}
+
+	for _, c := range cases {
+		e, err := New([]byte(src))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err := e.SetAttribute(c.path, c.value); err != nil {
+			t.Errorf("%s: %s", c.path, err)
+			continue
+		}
+
+		if !strings.Contains(string(e.Bytes()), c.expected) {
+			t.Errorf("%s: expected to contain:\n%s\ngot:\n%s", c.path, c.expected, e.Bytes())
+		}
+	}
+}
+
+func TestSetAttributeErrors(t *testing.T) {
+	cases := []struct {
+		path  string
+		value interface{}
+		err   string
+	}{
+		{"service.web", 1, "5:1: service.web is a block"},
+		{"database.port", 1, "no block database"},
+		{"name.port", 1, "2:1: name is not a block"},
+		{"name", nil, "can't set a nil value"},
+		{"name", struct{}{}, "unsupported value of type struct {}"},
+	}
+
+	for _, c := range cases {
+		e, err := New([]byte(src))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = e.SetAttribute(c.path, c.value)
+		if err == nil || err.Error() != c.err {
+			t.Errorf("%s: want error %q, got: %v", c.path, c.err, err)
+		}
+
+		if string(e.Bytes()) != src {
+			t.Errorf("%s: source changed on error", c.path)
+		}
+	}
+}
+
+func TestRemoveBlock(t *testing.T) {
+	e, err := New([]byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := e.RemoveBlock("service.old"); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `# global settings
+name    = "app"   # the name
+version = 1
+
+service "web" {
+    # the port
+    port = 80
+
+    tags = ["a",   "b"]
+}
+`
+	if string(e.Bytes()) != expected {
+		t.Errorf("\nwant:\n%s\ngot:\n%s", expected, e.Bytes())
+	}
+
+	if err := e.RemoveBlock("service.web"); err != nil {
+		t.Fatal(err)
+	}
+
+	expected = `# global settings
+name    = "app"   # the name
+version = 1
+`
+	if string(e.Bytes()) != expected {
+		t.Errorf("\nwant:\n%s\ngot:\n%s", expected, e.Bytes())
+	}
+
+	if err := e.RemoveBlock("service.web"); err == nil || err.Error() != "no block service.web" {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	if err := e.RemoveBlock("name"); err == nil || err.Error() != "2:1: name is not a block" {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestRemoveAttribute(t *testing.T) {
+	e, err := New([]byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := e.RemoveAttribute("service.web.port"); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.RemoveAttribute("name"); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `version = 1
+
+service "web" {
+
+    tags = ["a",   "b"]
+}
+
+/* legacy */
+service "old" {
+    port = 81
+}
+`
+	if string(e.Bytes()) != expected {
+		t.Errorf("\nwant:\n%s\ngot:\n%s", expected, e.Bytes())
+	}
+
+	errCases := []struct {
+		path string
+		err  string
+	}{
+		{"name", "no attribute name"},
+		{"service.old", "9:1: service.old is a block"},
+	}
+	for _, c := range errCases {
+		before := string(e.Bytes())
+		if err := e.RemoveAttribute(c.path); err == nil || err.Error() != c.err {
+			t.Errorf("%s: want error %q, got: %v", c.path, c.err, err)
+		}
+		if string(e.Bytes()) != before {
+			t.Errorf("%s: source changed on error", c.path)
+		}
+	}
+}
+
+func TestAppendBlock(t *testing.T) {
+	e, err := New([]byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	block := &ast.ObjectItem{
+		Keys: []*ast.ObjectKey{
+			{Token: token.Token{Type: token.IDENT, Text: "health"}},
+		},
+		Val: &ast.ObjectType{List: &ast.ObjectList{Items: []*ast.ObjectItem{{
+			Keys: []*ast.ObjectKey{{Token: token.Token{Type: token.IDENT, Text: "path"}}},
+			Val:  &ast.LiteralType{Token: token.Token{Type: token.STRING, Text: `"/health"`}},
+		}}}},
+	}
+
+	if err := e.AppendBlock("service.web", block); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `    tags = ["a",   "b"]
+
+    health = {
+      path = "/health"
+    }
+}`
+	if !strings.Contains(string(e.Bytes()), expected) {
+		t.Errorf("expected to contain:\n%s\ngot:\n%s", expected, e.Bytes())
+	}
+
+	if err := e.AppendBlock("", block); err != nil {
+		t.Fatal(err)
+	}
+
+	expected = `    port = 81
+}
+
+health = {
+  path = "/health"
+}
+`
+	if !strings.Contains(string(e.Bytes()), expected) {
+		t.Errorf("expected to contain:\n%s\ngot:\n%s", expected, e.Bytes())
+	}
+}
+
+func TestInsertEmptyBlock(t *testing.T) {
+	e, err := New([]byte("a {}\nb {\n}\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := e.SetAttribute("a.x", 1); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := e.SetAttribute("b.y", 2); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "a {\n  x = 1\n}\nb {\n  y = 2\n}\n"
+	if string(e.Bytes()) != expected {
+		t.Errorf("\nwant: %q\ngot:  %q", expected, e.Bytes())
+	}
+}
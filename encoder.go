@@ -0,0 +1,425 @@
+package hcl
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/fatih/hcl/ast"
+	"github.com/fatih/hcl/printer"
+	"github.com/fatih/hcl/token"
+)
+
+// Marshal returns the HCL encoding of v. See Encoder.Encode.
+func Marshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := NewEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// An Encoder writes Go values as HCL to an output stream.
+type Encoder struct {
+	w     io.Writer
+	style printer.CommentStyle
+}
+
+// NewEncoder returns a new encoder writing to w.
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{w: w}
+}
+
+// SetCommentStyle sets the syntax of the comments written by the encoder,
+// i.e. printer.SlashComments for tools which don't accept # comments. The
+// default is printer.HashComments.
+func (e *Encoder) SetCommentStyle(style printer.CommentStyle) {
+	e.style = style
+}
+
+// Encode writes the HCL encoding of v to the stream. v must be a struct or a
+// map with string keys, or a pointer to one, whose fields or entries become
+// the items of the file.
+//
+// Struct fields are named like for decoding, by their hcl tag or the name of
+// the field. Fields tagged with "-" or the option unknown, fields the decoder
+// can't set and nil pointers, interfaces, slices and maps are skipped. The
+// entries of a map with the option unused are written as items of the struct.
+// Structs are written as blocks and slices of structs as repeated blocks.
+// Maps, including OrderedMaps, are written as blocks, entries which are
+// blocks themselves as blocks labeled with the key, i.e. service "web" {}.
+// All other slices are written as lists. The keys of maps are sorted, the
+// ones of OrderedMaps are kept in order. Values of time.Duration, time.Time,
+// net.IP and url.URL are written as the strings the decoder converts back,
+// big.Int and big.Float as numbers with all their digits. RawMessages and
+// ast.Nodes are written verbatim. The "doc" tag of a field is written as the
+// comment of its first item, see SetCommentStyle.
+func (e *Encoder) Encode(v interface{}) error {
+	rv := indirect(reflect.ValueOf(v))
+	if !rv.IsValid() || rv.Kind() != reflect.Struct && rv.Kind() != reflect.Map && rv.Type() != orderedMapType {
+		return fmt.Errorf("root: cannot encode %T as a file, expected a struct or a map", v)
+	}
+
+	list := &ast.ObjectList{}
+	if err := encodeFields(list, "root", rv); err != nil {
+		return err
+	}
+
+	cfg := printer.DefaultConfig
+	cfg.CommentStyle = e.style
+	if err := cfg.Fprint(e.w, list); err != nil {
+		return err
+	}
+	_, err := io.WriteString(e.w, "\n")
+	return err
+}
+
+// encodeFields adds the items of the fields of a struct or the entries of a
+// map to list
+func encodeFields(list *ast.ObjectList, name string, rv reflect.Value) error {
+	if rv.Type() == orderedMapType {
+		return encodeOrdered(list, name, rv)
+	}
+
+	if rv.Kind() == reflect.Map {
+		if rv.Type().Key().Kind() != reflect.String {
+			return fmt.Errorf("%s: map key must be a string, got: %s", name, rv.Type().Key())
+		}
+
+		keys := rv.MapKeys()
+		sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+		for _, k := range keys {
+			n := len(list.Items)
+			if err := encodeItems(list, name+"."+ast.PathKey(k.String()), k.String(), rv.MapIndex(k)); err != nil {
+				return err
+			}
+
+			// the key of an entry is the key of its struct already
+			if _, ok := keyField(rv.Type().Elem()); ok {
+				for _, item := range list.Items[n:] {
+					item.Keys = item.Keys[:1]
+				}
+			}
+		}
+		return nil
+	}
+
+	for _, field := range structFields(rv.Type()) {
+		fv, err := rv.FieldByIndexErr(field.Index)
+		if err != nil {
+			continue // in a nil embedded struct
+		}
+
+		key := field.name
+		if field.option("unused") && field.settable {
+			// the leftovers are items of the object again
+			if err := encodeFields(list, name, fv); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if key == "-" || field.filled || !field.settable {
+			continue
+		}
+
+		n := len(list.Items)
+		if err := encodeItems(list, name+"."+ast.PathKey(key), key, fv); err != nil {
+			return err
+		}
+
+		if doc := field.Tag.Get("doc"); doc != "" && len(list.Items) > n {
+			list.Items[n].LeadComment = docComment(doc)
+		}
+	}
+	return nil
+}
+
+// docComment returns the lead comment of a field with the doc tag doc, a #
+// comment per line the printer converts to the style of the encoder
+func docComment(doc string) *ast.CommentGroup {
+	g := &ast.CommentGroup{}
+	for _, line := range strings.Split(doc, "\n") {
+		text := "#"
+		if line = strings.TrimSpace(line); line != "" {
+			text += " " + line
+		}
+		g.List = append(g.List, &ast.Comment{Text: text})
+	}
+	return g
+}
+
+// encodeItems adds the items of the value rv with the given key to list: a
+// block for structs and maps, repeated items for slices of structs and
+// repeatable lists, and an attribute otherwise
+func encodeItems(list *ast.ObjectList, name, key string, rv reflect.Value) error {
+	if n, ok, err := rawNode(name, rv); ok {
+		if n != nil {
+			list.Add(&ast.ObjectItem{Keys: []*ast.ObjectKey{{Token: keyToken(key)}}, Val: n})
+		}
+		return err
+	}
+
+	rv = indirect(rv)
+	if !rv.IsValid() || (rv.Kind() == reflect.Slice || rv.Kind() == reflect.Map) && rv.IsNil() {
+		return nil
+	}
+
+	keys := []*ast.ObjectKey{{Token: keyToken(key)}}
+	if s, ok := formatString(rv); ok {
+		list.Add(&ast.ObjectItem{Keys: keys, Val: &ast.LiteralType{Token: token.Token{Type: token.STRING, Text: quote(s)}}})
+		return nil
+	}
+
+	kind := rv.Kind()
+	if rv.Type() == orderedMapType {
+		kind = reflect.Map
+	}
+
+	switch kind {
+	case reflect.Struct:
+		if isBig(rv.Type()) {
+			break
+		}
+
+		obj := &ast.ObjectType{List: &ast.ObjectList{}}
+		if err := encodeFields(obj.List, name, rv); err != nil {
+			return err
+		}
+
+		// the key field is the label of the block
+		if field, ok := keyField(rv.Type()); ok && field.Type.Kind() == reflect.String {
+			if fv, err := rv.FieldByIndexErr(field.Index); err == nil && fv.String() != "" {
+				keys = append(keys, &ast.ObjectKey{Token: token.Token{Type: token.STRING, Text: strconv.Quote(fv.String())}})
+			}
+		}
+		list.Add(&ast.ObjectItem{Keys: keys, Val: obj})
+		return nil
+	case reflect.Map:
+		obj := &ast.ObjectType{List: &ast.ObjectList{}}
+		if err := encodeFields(obj.List, name, rv); err != nil {
+			return err
+		}
+
+		// entries which are all blocks are labeled with their keys
+		if len(obj.List.Items) == 0 || !allBlocks(obj.List) {
+			list.Add(&ast.ObjectItem{Keys: keys, Val: obj})
+			return nil
+		}
+
+		for _, item := range obj.List.Items {
+			if label := &item.Keys[0].Token; label.Type == token.IDENT {
+				label.Type, label.Text = token.STRING, strconv.Quote(label.Text)
+			}
+			item.Keys = append(keys[:1:1], item.Keys...)
+			list.Add(item)
+		}
+		return nil
+	case reflect.Slice, reflect.Array:
+		if !isStruct(rv.Type().Elem()) && !repeatable(rv) {
+			break
+		}
+
+		for i := 0; i < rv.Len(); i++ {
+			if err := encodeItems(list, fmt.Sprintf("%s[%d]", name, i), key, rv.Index(i)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	val, err := encodeNode(name, rv)
+	if err != nil {
+		return err
+	}
+	list.Add(&ast.ObjectItem{Keys: keys, Val: val})
+	return nil
+}
+
+// encodeNode returns the node of a value of an attribute or a list
+func encodeNode(name string, rv reflect.Value) (ast.Node, error) {
+	lit := func(typ token.Type, text string) ast.Node {
+		return &ast.LiteralType{Token: token.Token{Type: typ, Text: text}}
+	}
+
+	if n, ok, err := rawNode(name, rv); ok && (n != nil || err != nil) {
+		return n, err
+	}
+
+	rv = indirect(rv)
+	if !rv.IsValid() {
+		return nil, fmt.Errorf("%s: cannot encode nil", name)
+	}
+
+	if s, ok := formatString(rv); ok {
+		return lit(token.STRING, quote(s)), nil
+	}
+	if isBig(rv.Type()) {
+		return encodeBig(name, rv)
+	}
+
+	if rv.Type() == orderedMapType {
+		obj := &ast.ObjectType{List: &ast.ObjectList{}}
+		if err := encodeOrdered(obj.List, name, rv); err != nil {
+			return nil, err
+		}
+		return obj, nil
+	}
+
+	switch rv.Kind() {
+	case reflect.String:
+		return lit(token.STRING, quote(rv.String())), nil
+	case reflect.Bool:
+		return lit(token.BOOL, strconv.FormatBool(rv.Bool())), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return lit(token.NUMBER, strconv.FormatInt(rv.Int(), 10)), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return lit(token.NUMBER, strconv.FormatUint(rv.Uint(), 10)), nil
+	case reflect.Float32, reflect.Float64:
+		f := rv.Float()
+		if math.IsInf(f, 0) || math.IsNaN(f) {
+			return nil, fmt.Errorf("%s: cannot encode %v", name, f)
+		}
+
+		// floats keep their fraction, so they decode as floats again
+		text := strconv.FormatFloat(f, 'f', -1, rv.Type().Bits())
+		if !strings.Contains(text, ".") {
+			text += ".0"
+		}
+		return lit(token.FLOAT, text), nil
+	case reflect.Slice, reflect.Array:
+		list := &ast.ListType{}
+		for i := 0; i < rv.Len(); i++ {
+			n, err := encodeNode(fmt.Sprintf("%s[%d]", name, i), rv.Index(i))
+			if err != nil {
+				return nil, err
+			}
+			list.Add(n)
+		}
+		return list, nil
+	case reflect.Struct, reflect.Map:
+		obj := &ast.ObjectType{List: &ast.ObjectList{}}
+		if err := encodeFields(obj.List, name, rv); err != nil {
+			return nil, err
+		}
+		return obj, nil
+	}
+
+	return nil, fmt.Errorf("%s: cannot encode %s", name, rv.Type())
+}
+
+// indirect dereferences pointers and interfaces, it returns the zero Value
+// for nil
+func indirect(rv reflect.Value) reflect.Value {
+	for rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return reflect.Value{}
+		}
+		rv = rv.Elem()
+	}
+	return rv
+}
+
+// isStruct reports whether t is a struct or a pointer to one, which isn't
+// encoded as a string
+func isStruct(t reflect.Type) bool {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct && t != timeType && t != urlType && !isBig(t)
+}
+
+// repeatable reports whether the elements of the list of empty interfaces rv
+// are written as repeated items, as lists of the default syntax can't hold
+// bools, objects and lists. Repeated items decode into the same list again,
+// unless the first two are lists, which are concatenated, or blocks with
+// labels follow each other, whose labels are merged into one object.
+func repeatable(rv reflect.Value) bool {
+	if rv.Type().Elem().Kind() != reflect.Interface || rv.Len() < 2 {
+		return false
+	}
+
+	repeat := false
+	for i := 0; i < rv.Len(); i++ {
+		elem := indirect(rv.Index(i))
+		switch {
+		case !elem.IsValid():
+			return false
+		case i == 1 && isList(elem) && isList(indirect(rv.Index(0))):
+			return false
+		case i > 0 && isLabeled(elem) && isLabeled(indirect(rv.Index(i-1))):
+			return false
+		}
+		repeat = repeat || elem.Kind() == reflect.Bool || isList(elem) || isObject(elem)
+	}
+	return repeat
+}
+
+// isList reports whether the value rv is encoded as a list
+func isList(rv reflect.Value) bool {
+	if _, ok := formatString(rv); ok || rv.Type() == orderedMapType {
+		return false
+	}
+	return rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array
+}
+
+// isObject reports whether the value rv is encoded as an object
+func isObject(rv reflect.Value) bool {
+	return rv.Type() == orderedMapType || rv.Kind() == reflect.Map || isStruct(rv.Type())
+}
+
+// isLabeled reports whether the value rv is encoded as blocks with labels by
+// encodeItems: a struct with a key field or a map of objects only
+func isLabeled(rv reflect.Value) bool {
+	if _, ok := keyField(rv.Type()); ok {
+		return true
+	}
+
+	var values []reflect.Value
+	switch {
+	case rv.Type() == orderedMapType:
+		for _, item := range rv.Interface().(OrderedMap) {
+			values = append(values, reflect.ValueOf(item.Value))
+		}
+	case rv.Kind() == reflect.Map:
+		for _, k := range rv.MapKeys() {
+			values = append(values, rv.MapIndex(k))
+		}
+	}
+
+	labeled := false
+	for _, v := range values {
+		if v = indirect(v); v.IsValid() {
+			if !isObject(v) {
+				return false
+			}
+			labeled = true
+		}
+	}
+	return labeled
+}
+
+// allBlocks reports whether all items of list are blocks
+func allBlocks(list *ast.ObjectList) bool {
+	for _, item := range list.Items {
+		if _, ok := item.Val.(*ast.ObjectType); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// keyToken returns the token of a key, quoted unless it's an identifier.
+// Keys aren't templates, unlike the strings of values quoted with quote, so
+// they keep "${".
+func keyToken(key string) token.Token {
+	if token.IsIdent(key) {
+		return token.Token{Type: token.IDENT, Text: key}
+	}
+	return token.Token{Type: token.STRING, Text: strconv.Quote(key)}
+}
@@ -0,0 +1,198 @@
+package hcl
+
+import (
+	"bytes"
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/fatih/hcl/ast"
+	"github.com/fatih/hcl/printer"
+)
+
+type encodedServer struct {
+	Name    string            `hcl:"name"`
+	Ports   []int             `hcl:"ports"`
+	Weight  float64           `hcl:"weight"`
+	Enabled bool              `hcl:"enabled"`
+	Labels  map[string]string `hcl:"labels"`
+	Ignored string            `hcl:"-"`
+	secret  string
+}
+
+type encodedConfig struct {
+	Region   string                    `hcl:"region"`
+	Debug    *bool                     `hcl:"debug"`
+	Server   encodedServer             `hcl:"server"`
+	Backends []encodedServer           `hcl:"backend"`
+	Services map[string]*encodedServer `hcl:"service"`
+}
+
+func TestMarshal(t *testing.T) {
+	in := encodedConfig{
+		Region: "eu-west-1",
+		Server: encodedServer{
+			Name:    "main",
+			Ports:   []int{80, 443},
+			Weight:  1,
+			Enabled: true,
+			Labels:  map[string]string{"tier": "web", "app.kubernetes.io/name": "main"},
+			Ignored: "ignored",
+			secret:  "secret",
+		},
+		Backends: []encodedServer{{Name: "a", Weight: 0.5}, {Name: "b"}},
+		Services: map[string]*encodedServer{
+			"web": {Name: "web"},
+			"db":  {Name: "db", Ports: []int{5432}},
+		},
+	}
+
+	out, err := Marshal(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got encodedConfig
+	if err := Decode(&got, string(out)); err != nil {
+		t.Fatalf("%s\n\n%s", err, out)
+	}
+
+	in.Server.Ignored, in.Server.secret = "", ""
+	if !reflect.DeepEqual(in, got) {
+		t.Errorf("want: %+v\ngot:  %+v\n\n%s", in, got, out)
+	}
+
+	for _, want := range []string{
+		`region = "eu-west-1"`,
+		`ports = [80, 443]`,
+		`weight = 1.0`,
+		`"app.kubernetes.io/name" = "main"`,
+		`service "db" {`,
+		`service "web" {`,
+	} {
+		if !bytes.Contains(out, []byte(want)) {
+			t.Errorf("output doesn't contain %s:\n\n%s", want, out)
+		}
+	}
+
+	if bytes.Contains(out, []byte("debug")) || bytes.Contains(out, []byte("ignored")) {
+		t.Errorf("output contains skipped fields:\n\n%s", out)
+	}
+
+	if n := bytes.Count(out, []byte("backend ")); n != 2 {
+		t.Errorf("want 2 backend blocks, got %d:\n\n%s", n, out)
+	}
+}
+
+func TestEncoder(t *testing.T) {
+	var buf bytes.Buffer
+	in := map[string]interface{}{
+		"a": 1,
+		"b": []interface{}{"x", 2.5},
+		"c": map[string]interface{}{"d": true},
+		// lists which can't be attributes are written as repeated items
+		"e": []interface{}{1, true},
+		"f": []interface{}{map[string]interface{}{"g": 1}, map[string]interface{}{"g": 2}},
+		"g": []interface{}{map[string]interface{}{}, []interface{}{}, []interface{}{1}},
+	}
+	if err := NewEncoder(&buf).Encode(&in); err != nil {
+		t.Fatal(err)
+	}
+
+	var got map[string]interface{}
+	if err := Decode(&got, buf.String()); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(in, got) {
+		t.Errorf("want: %#v\ngot:  %#v\n\n%s", in, got, buf.String())
+	}
+}
+
+func TestEncoderCommentStyle(t *testing.T) {
+	type service struct {
+		Port int `hcl:"port" doc:"The port to listen on."`
+	}
+	in := struct {
+		Region   string    `hcl:"region" doc:"The region.\nDefaults to AWS_REGION."`
+		Services []service `hcl:"service" doc:"A service."`
+	}{"eu", []service{{80}, {81}}}
+
+	cases := []struct {
+		style printer.CommentStyle
+		want  string
+	}{
+		{printer.KeepComments, "# The region.\n# Defaults to AWS_REGION.\nregion = \"eu\"\n\n# A service.\nservice = {\n  # The port to listen on.\n  port = 80\n}\n\nservice = {\n  # The port to listen on.\n  port = 81\n}\n"},
+		{printer.SlashComments, "// The region.\n// Defaults to AWS_REGION.\nregion = \"eu\"\n\n// A service.\nservice = {\n  // The port to listen on.\n  port = 80\n}\n\nservice = {\n  // The port to listen on.\n  port = 81\n}\n"},
+		{printer.BlockComments, "/* The region. */\n/* Defaults to AWS_REGION. */\nregion = \"eu\"\n\n/* A service. */\nservice = {\n  /* The port to listen on. */\n  port = 80\n}\n\nservice = {\n  /* The port to listen on. */\n  port = 81\n}\n"},
+	}
+
+	for _, c := range cases {
+		var buf bytes.Buffer
+		e := NewEncoder(&buf)
+		e.SetCommentStyle(c.style)
+		if err := e.Encode(in); err != nil {
+			t.Fatal(err)
+		}
+
+		if buf.String() != c.want {
+			t.Errorf("style %d: want:\n%s\ngot:\n%s", c.style, c.want, buf.String())
+		}
+	}
+}
+
+func TestMarshalErrors(t *testing.T) {
+	cases := []struct {
+		v   interface{}
+		err string
+	}{
+		{[]int{1}, "root: cannot encode []int as a file, expected a struct or a map"},
+		{nil, "root: cannot encode <nil> as a file, expected a struct or a map"},
+		{map[int]string{1: "a"}, "root: map key must be a string, got: int"},
+		{map[string]interface{}{"a": []interface{}{nil}}, "root.a[0]: cannot encode nil"},
+		{struct{ C complex128 }{}, "root.C: cannot encode complex128"},
+	}
+
+	for _, c := range cases {
+		_, err := Marshal(c.v)
+		if err == nil || err.Error() != c.err {
+			t.Errorf("%#v: want error %q, got: %v", c.v, c.err, err)
+		}
+	}
+}
+
+func TestMarshalTemplates(t *testing.T) {
+	type config struct {
+		Command string            `hcl:"command"`
+		Args    []string          `hcl:"args"`
+		Env     map[string]string `hcl:"env"`
+		Max     uint64            `hcl:"max"`
+	}
+
+	in := config{
+		Command: "echo ${HOME}",
+		Args:    []string{"%{ if x }", "$${y}"},
+		Env:     map[string]string{"${KEY}": "${VALUE}"},
+		Max:     math.MaxUint64,
+	}
+
+	res, err := Marshal(&in)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// strings are escaped, they aren't interpolated when decoded again
+	called := false
+	decoder := &DecoderConfig{Resolver: func(*ast.Template) (interface{}, error) {
+		called = true
+		return "resolved", nil
+	}}
+
+	var out config
+	if err := decoder.Decode(&out, string(res)); err != nil {
+		t.Fatalf("%s\n%s", err, res)
+	}
+	if called || !reflect.DeepEqual(out, in) {
+		t.Errorf("round trip differs:\n%s\nwant: %+v\ngot:  %+v", res, in, out)
+	}
+}
@@ -0,0 +1,60 @@
+package hcl
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/fatih/hcl/printer"
+	"github.com/fatih/hcl/token"
+)
+
+// QuoteString returns s as an HCL string literal, i.e. for HCL generated
+// with text/template. Quotes, backslashes and control characters are
+// escaped, as are interpolations and directives, so the literal stands for
+// s itself whatever s contains: "${var.x}" becomes "$${var.x}", which
+// evaluates and decodes to ${var.x} again, with or without a Resolver.
+func QuoteString(s string) string {
+	return quote(s)
+}
+
+// EscapeHeredoc returns s as the body of a heredoc closed by marker, with
+// interpolations and directives escaped like QuoteString does. A line of s
+// consisting of the marker, which would close the heredoc early, is an
+// error, as is a marker which isn't an identifier.
+func EscapeHeredoc(s, marker string) (string, error) {
+	if !token.IsIdent(marker) {
+		return "", fmt.Errorf("invalid heredoc marker %q", marker)
+	}
+
+	for i, line := range strings.Split(s, "\n") {
+		if strings.TrimSpace(strings.TrimSuffix(line, "\r")) == marker {
+			return "", fmt.Errorf("line %d of the heredoc is its marker %s", i+1, marker)
+		}
+	}
+
+	s = strings.Replace(s, "${", "$${", -1)
+	s = strings.Replace(s, "%{", "%%{", -1)
+	return s, nil
+}
+
+// FormatValue returns the HCL expression of v, i.e. to insert values into
+// HCL generated with text/template. Values are written like Marshal writes
+// attributes, with strings quoted by QuoteString; nil is null.
+func FormatValue(v interface{}) (string, error) {
+	if v == nil {
+		return "null", nil
+	}
+
+	n, err := encodeNode("value", reflect.ValueOf(v))
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	if err := printer.Fprint(&buf, n); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,160 @@
+package hcl
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"text/template"
+
+	"github.com/fatih/hcl/ast"
+	"github.com/fatih/hcl/eval"
+	"github.com/fatih/hcl/parser"
+)
+
+func TestQuoteString(t *testing.T) {
+	cases := []struct {
+		s    string
+		want string
+	}{
+		{`web`, `"web"`},
+		{`say "hi"`, `"say \"hi\""`},
+		{`C:\dir`, `"C:\\dir"`},
+		{"a\nb\t\x00", `"a\nb\t\x00"`},
+		{`${var.secret}`, `"$${var.secret}"`},
+		{`%{ if x }`, `"%%{ if x }"`},
+		{`$${x}`, `"$$${x}"`},
+		{`%%{x}`, `"%%%{x}"`},
+		{`é`, `"é"`},
+	}
+
+	for _, c := range cases {
+		got := QuoteString(c.s)
+		if got != c.want {
+			t.Errorf("%q: want %s, got %s", c.s, c.want, got)
+			continue
+		}
+
+		// the literal evaluates to the string again
+		f, err := parser.Parse([]byte("a = " + got))
+		if err != nil {
+			t.Errorf("%q: %s", c.s, err)
+			continue
+		}
+		v, err := eval.Eval(f, nil)
+		if err != nil {
+			t.Errorf("%q: %s", c.s, err)
+			continue
+		}
+		if v := v.(map[string]interface{})["a"]; v != c.s {
+			t.Errorf("%q: evaluates to %q", c.s, v)
+		}
+
+		// and decodes to it, with or without a resolver
+		resolver := &DecoderConfig{Resolver: func(*ast.Template) (interface{}, error) {
+			return "resolved", nil
+		}}
+		for _, config := range []*DecoderConfig{&DefaultDecoderConfig, resolver} {
+			var out struct {
+				A string `hcl:"a"`
+			}
+			if err := config.Decode(&out, "a = "+got); err != nil || out.A != c.s {
+				t.Errorf("%q: decodes to %q, %v", c.s, out.A, err)
+			}
+		}
+	}
+}
+
+func TestEscapeHeredoc(t *testing.T) {
+	got, err := EscapeHeredoc("echo ${HOME}\n%{x}", "EOT")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "echo $${HOME}\n%%{x}"; got != want {
+		t.Errorf("want %q, got %q", want, got)
+	}
+
+	errCases := []struct {
+		s, marker string
+		err       string
+	}{
+		{"a\nEOT\nb", "EOT", "line 2 of the heredoc is its marker EOT"},
+		{"a\n  EOT", "EOT", "line 2 of the heredoc is its marker EOT"},
+		{"a", "E-T", `invalid heredoc marker "E-T"`},
+		{"a", "", `invalid heredoc marker ""`},
+	}
+	for _, c := range errCases {
+		_, err := EscapeHeredoc(c.s, c.marker)
+		if err == nil || err.Error() != c.err {
+			t.Errorf("%q: want error %q, got: %v", c.s, c.err, err)
+		}
+	}
+}
+
+func TestFormatValue(t *testing.T) {
+	cases := []struct {
+		v    interface{}
+		want string
+	}{
+		{nil, `null`},
+		{"${x}", `"$${x}"`},
+		{42, `42`},
+		{1.5, `1.5`},
+		{true, `true`},
+		{[]string{"a", "${b}"}, `["a", "$${b}"]`},
+		{map[string]int{"b": 2, "a": 1}, "{\n  a = 1\n\n  b = 2\n}"},
+	}
+
+	for _, c := range cases {
+		got, err := FormatValue(c.v)
+		if err != nil {
+			t.Errorf("%v: %s", c.v, err)
+			continue
+		}
+		if got != c.want {
+			t.Errorf("%v: want %q, got %q", c.v, c.want, got)
+		}
+	}
+
+	if _, err := FormatValue(make(chan int)); err == nil {
+		t.Error("expected an error for a channel")
+	}
+}
+
+// TestTemplateInjection checks that user strings inserted into a template
+// with the helpers don't change the structure of the generated file.
+func TestTemplateInjection(t *testing.T) {
+	tmpl := template.Must(template.New("").Funcs(template.FuncMap{
+		"quote":   QuoteString,
+		"heredoc": EscapeHeredoc,
+		"value":   FormatValue,
+	}).Parse(`name = {{quote .Name}}
+tags = {{value .Tags}}
+script = <<EOT
+{{heredoc .Script "EOT"}}
+EOT
+`))
+
+	data := map[string]interface{}{
+		"Name":   "x\"\nadmin = true\n#",
+		"Tags":   []string{"\"]\nadmin = true"},
+		"Script": "echo ${secret}\nEOF",
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := parser.Parse(buf.Bytes())
+	if err != nil {
+		t.Fatalf("%s\n%s", err, buf.String())
+	}
+
+	var keys []string
+	for _, item := range f.Node.(*ast.ObjectList).Items {
+		keys = append(keys, item.Keys[0].Token.Text)
+	}
+	if got := strings.Join(keys, " "); got != "name tags script" {
+		t.Errorf("unexpected items %s of:\n%s", got, buf.String())
+	}
+}
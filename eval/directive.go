@@ -0,0 +1,102 @@
+package eval
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/fatih/hcl/ast"
+	"github.com/fatih/hcl/convert"
+)
+
+// forDirective evaluates the body of a for directive once for every element
+// of its collection. Maps are iterated in the order of their keys.
+func (s *Scope) forDirective(f *ast.ForDirective) (interface{}, error) {
+	coll, err := s.Eval(f.Collection)
+	if err != nil {
+		return nil, err
+	}
+
+	if u := unknown(f, coll); u != nil {
+		return u, nil
+	}
+
+	var keys, values []interface{}
+	switch t := coll.(type) {
+	case []interface{}:
+		for i, v := range t {
+			keys = append(keys, int64(i))
+			values = append(values, v)
+		}
+	case map[string]interface{}:
+		names := make([]string, 0, len(t))
+		for k := range t {
+			names = append(names, k)
+		}
+		sort.Strings(names)
+
+		for _, k := range names {
+			keys = append(keys, k)
+			values = append(values, t[k])
+		}
+	default:
+		return nil, posErrorf(f.Collection.Pos(), "can't iterate over %s", convert.TypeName(coll))
+	}
+
+	var buf []string
+	for i := range values {
+		inner := &Scope{
+			Variables: make(map[string]interface{}, len(s.Variables)+2),
+			Functions: s.Functions,
+			partial:   s.partial,
+		}
+		for k, v := range s.Variables {
+			inner.Variables[k] = v
+		}
+
+		inner.Variables[f.Value] = values[i]
+		if f.Key != "" {
+			inner.Variables[f.Key] = keys[i]
+		}
+
+		v, err := inner.parts(f.Body)
+		if err != nil {
+			return nil, err
+		}
+
+		if u := unknown(f, v); u != nil {
+			return u, nil
+		}
+		buf = append(buf, v.(string))
+	}
+
+	return strings.Join(buf, ""), nil
+}
+
+// ifDirective evaluates the parts of an if directive selected by its
+// condition.
+func (s *Scope) ifDirective(i *ast.IfDirective) (interface{}, error) {
+	cond, err := s.Eval(i.Cond)
+	if err != nil {
+		return nil, err
+	}
+
+	if u := unknown(i, cond); u != nil {
+		return u, nil
+	}
+
+	b, ok := cond.(bool)
+	if !ok {
+		return nil, posErrorf(i.Cond.Pos(), "condition must be a bool, got: %s", convert.TypeName(cond))
+	}
+
+	parts := i.Else
+	if b {
+		parts = i.True
+	}
+
+	v, err := s.parts(parts)
+	if u, ok := v.(*Unknown); ok {
+		u.Node = i
+	}
+	return v, err
+}
@@ -0,0 +1,58 @@
+package eval
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDirectives(t *testing.T) {
+	cases := []struct {
+		text     string
+		expected interface{}
+	}{
+		{`%{ for x in list }${x},%{ endfor }`, "a,b,"},
+		{`%{ for i, x in list }${i}=${x} %{ endfor }`, "0=a 1=b "},
+		{`%{ for k, v in var.nested }${k}:${v}%{ endfor }`, "key:value"},
+		{`%{ for id in aws_instance.web.*.id }[${upper(id)}]%{ endfor }`, "[I-1][I-2]"},
+		{`%{ for x in list }%{ for y in list }${x}${y} %{ endfor }%{ endfor }`, "aa ab ba bb "},
+		{`%{ if var.port > 80 }high%{ endif }`, "high"},
+		{`%{ if var.port < 80 }low%{ endif }`, ""},
+		{`%{ if var.name == "db" }db%{ else }${var.name}%{ endif }`, "web"},
+		{`%{ for x in list }%{ if x == "a" }first%{ else },${x}%{ endif }%{ endfor }`, "first,b"},
+		{`port ${var.port}%{ if true }!%{ endif }`, "port 8080!"},
+		{`%%{ if true }$${var.name}%{ if true }${var.name}%{ endif }`, "%{ if true }${var.name}web"},
+	}
+
+	s := testScope()
+	for _, c := range cases {
+		v, err := evalTemplate(s, c.text)
+		if err != nil {
+			t.Errorf("%s: %s", c.text, err)
+			continue
+		}
+
+		if !reflect.DeepEqual(v, c.expected) {
+			t.Errorf("%s: want: %#v got: %#v", c.text, c.expected, v)
+		}
+	}
+}
+
+func TestDirectivesError(t *testing.T) {
+	cases := []struct {
+		text string
+		err  string
+	}{
+		{`%{ for x in var.name }${x}%{ endfor }`, "At 1:13: can't iterate over string"},
+		{`%{ for x in list }${unknown}%{ endfor }`, "At 1:21: unknown variable unknown"},
+		{`%{ if var.port }x%{ endif }`, "At 1:7: condition must be a bool, got: number"},
+		{`%{ if false }x%{ else }${list}%{ endif }`, "At 1:26: can't interpolate list into a string"},
+	}
+
+	s := testScope()
+	for _, c := range cases {
+		_, err := evalTemplate(s, c.text)
+		if err == nil || err.Error() != c.err {
+			t.Errorf("%s: want: %q got: %v", c.text, c.err, err)
+		}
+	}
+}
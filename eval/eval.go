@@ -0,0 +1,306 @@
+// Package eval evaluates the interpolations of parsed HCL (HashiCorp
+// Configuration Language) values, such as "${var.foo}", against a scope of
+// variables.
+package eval
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/fatih/hcl/ast"
+	"github.com/fatih/hcl/convert"
+	"github.com/fatih/hcl/parser"
+	"github.com/fatih/hcl/token"
+	"github.com/fatih/hcl/value"
+)
+
+// Scope contains the variables available to interpolations.
+//
+// Values are represented with the Go types string, int64, float64, bool,
+// []interface{} and map[string]interface{}.
+type Scope struct {
+	// Variables maps variable names to their values. A reference such as
+	// var.foo.bar is looked up as a whole first. Otherwise the longest
+	// defined prefix is looked up and the remaining names select from nested
+	// maps, or lists for numeric names. A star selects from every element of
+	// a list.
+	Variables map[string]interface{}
+
+	// Functions maps function names to the functions callable from
+	// interpolations, such as upper(var.name). Use Register to add them.
+	Functions map[string]*Function
+
+	partial bool // whether undefined variables evaluate to *Unknown
+}
+
+// Eval evaluates n with the given variables. See Scope.Eval.
+func Eval(n ast.Node, vars map[string]interface{}) (interface{}, error) {
+	s := &Scope{Variables: vars}
+	return s.Eval(n)
+}
+
+// Eval evaluates the value n and all of its interpolations. Literals are
+// converted into their Go values, lists into []interface{} and objects into
+// map[string]interface{}. The keys of an item with multiple keys, such as
+// `service "web" {}`, create nested maps. Objects with the same keys are
+// merged, otherwise the last item wins.
+//
+// A string consisting of a single interpolation, such as "${var.list}",
+// evaluates to the value of the interpolation, otherwise to a string. Errors
+// are of type *parser.PosError.
+func (s *Scope) Eval(n ast.Node) (interface{}, error) {
+	switch t := n.(type) {
+	case *ast.File:
+		return s.Eval(t.Node)
+	case *ast.ObjectList:
+		return s.objectList(t)
+	case *ast.ObjectType:
+		if t.List == nil {
+			return map[string]interface{}{}, nil
+		}
+		return s.objectList(t.List)
+	case *ast.ListType:
+		list := make([]interface{}, 0, len(t.List))
+		for _, elem := range t.List {
+			v, err := s.Eval(elem)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, v)
+		}
+		return list, nil
+	case *ast.LiteralType:
+		return s.literal(t)
+	case *ast.Template:
+		return s.template(t)
+	case *ast.TemplateText:
+		return t.Text, nil
+	case *ast.Variable:
+		return s.variable(t)
+	case *ast.Call:
+		return s.call(t)
+	case *ast.Binary:
+		return s.binary(t)
+	case *ast.Unary:
+		return s.unary(t)
+	case *ast.Conditional:
+		return s.conditional(t)
+	case *ast.ForDirective:
+		return s.forDirective(t)
+	case *ast.IfDirective:
+		return s.ifDirective(t)
+	}
+
+	return nil, posErrorf(n.Pos(), "unsupported node %T", n)
+}
+
+func (s *Scope) objectList(list *ast.ObjectList) (interface{}, error) {
+	m := make(map[string]interface{})
+	for _, item := range list.Items {
+		v, err := s.Eval(item.Val)
+		if err != nil {
+			return nil, err
+		}
+
+		// nest the value in the keys, from the last to the first one
+		for i := len(item.Keys) - 1; i > 0; i-- {
+			v = map[string]interface{}{item.Keys[i].Token.Value(): v}
+		}
+
+		k := item.Keys[0].Token.Value()
+		m[k] = mergeValues(m[k], v)
+	}
+	return m, nil
+}
+
+// mergeValues merges b into a if both are maps, otherwise it returns b
+func mergeValues(a, b interface{}) interface{} {
+	ma, ok := a.(map[string]interface{})
+	if !ok {
+		return b
+	}
+
+	mb, ok := b.(map[string]interface{})
+	if !ok {
+		return b
+	}
+
+	for k, v := range mb {
+		ma[k] = mergeValues(ma[k], v)
+	}
+	return ma
+}
+
+func (s *Scope) literal(lit *ast.LiteralType) (interface{}, error) {
+	text := lit.Token.Text
+	switch lit.Token.Type {
+	case token.NUMBER, token.FLOAT:
+		// integers, including ones with an exponent like 1e6, are int64
+		if i, err := lit.Token.BigInt(); err == nil && lit.Token.Type == token.NUMBER {
+			if !i.IsInt64() {
+				return nil, posErrorf(lit.Pos(), "number %s overflows int64", text)
+			}
+			return i.Int64(), nil
+		}
+
+		f, err := lit.Token.BigFloat()
+		if err != nil {
+			return nil, posErrorf(lit.Pos(), "invalid number %s", text)
+		}
+		v, _ := f.Float64()
+		return v, nil
+	case token.BOOL:
+		return strings.EqualFold(text, "true"), nil
+	case token.NULL:
+		return nil, nil
+	case token.UNIT:
+		return text, nil
+	case token.STRING:
+		if len(text) < 2 || text[0] != '"' || text[len(text)-1] != '"' {
+			return nil, posErrorf(lit.Pos(), "invalid string %s", text)
+		}
+
+		pos := lit.Pos()
+		pos.Offset++
+		pos.Column++
+
+		t, err := parser.ParseTemplate(text[1:len(text)-1], pos)
+		if err != nil {
+			return nil, err
+		}
+		return s.template(t)
+	case token.HEREDOC:
+		t, err := parser.ParseHeredoc(lit.Token)
+		if err != nil {
+			return nil, err
+		}
+		return s.template(t)
+	}
+
+	return nil, posErrorf(lit.Pos(), "unsupported literal %s", lit.Token.Type)
+}
+
+func (s *Scope) template(t *ast.Template) (interface{}, error) {
+	if len(t.Parts) == 1 {
+		switch t.Parts[0].(type) {
+		case *ast.TemplateText, *ast.ForDirective, *ast.IfDirective:
+		default:
+			return s.Eval(t.Parts[0])
+		}
+	}
+
+	v, err := s.parts(t.Parts)
+	if u, ok := v.(*Unknown); ok {
+		u.Node = t
+	}
+	return v, err
+}
+
+// parts evaluates the given template parts and concatenates them. The result
+// is unknown if any of the parts is.
+func (s *Scope) parts(parts []ast.Expr) (interface{}, error) {
+	var buf []string
+	var values []interface{}
+	for _, part := range parts {
+		v, err := s.Eval(part)
+		if err != nil {
+			return nil, err
+		}
+
+		if _, ok := v.(*Unknown); ok {
+			values = append(values, v)
+			continue
+		}
+
+		str, err := String(v)
+		if err != nil {
+			return nil, &parser.PosError{Pos: part.Pos(), Err: err}
+		}
+		buf = append(buf, str)
+	}
+
+	if len(values) > 0 {
+		return unknown(nil, values...), nil
+	}
+	return strings.Join(buf, ""), nil
+}
+
+func (s *Scope) variable(v *ast.Variable) (interface{}, error) {
+	if val, ok := s.Variables[v.Name]; ok {
+		return val, nil
+	}
+
+	names := strings.Split(v.Name, ".")
+	for i := len(names) - 1; i > 0; i-- {
+		val, ok := s.Variables[strings.Join(names[:i], ".")]
+		if !ok {
+			continue
+		}
+
+		val, err := selectValue(val, names[i:])
+		if err != nil {
+			return nil, posErrorf(v.Pos(), "%s: %s", v.Name, err)
+		}
+		return val, nil
+	}
+
+	if s.partial {
+		return &Unknown{Node: v, Vars: []*ast.Variable{v}}, nil
+	}
+	return nil, posErrorf(v.Pos(), "unknown variable %s", v.Name)
+}
+
+// selectValue selects the value with the given names from v
+func selectValue(v interface{}, names []string) (interface{}, error) {
+	for i, name := range names {
+		switch t := v.(type) {
+		case *Unknown:
+			return t, nil
+		case map[string]interface{}:
+			val, ok := t[name]
+			if !ok {
+				return nil, fmt.Errorf("unknown key %q", name)
+			}
+			v = val
+		case []interface{}:
+			if name == "*" {
+				list := make([]interface{}, 0, len(t))
+				for _, elem := range t {
+					val, err := selectValue(elem, names[i+1:])
+					if err != nil {
+						return nil, err
+					}
+					list = append(list, val)
+				}
+				return list, nil
+			}
+
+			index, err := strconv.Atoi(name)
+			if err != nil || index < 0 || index >= len(t) {
+				return nil, fmt.Errorf("invalid index %q for list of length %d", name, len(t))
+			}
+			v = t[index]
+		default:
+			return nil, fmt.Errorf("can't select %q from %s", name, convert.TypeName(v))
+		}
+	}
+
+	return v, nil
+}
+
+// String returns the string form of a primitive value, as used to
+// interpolate it into a string.
+func String(v interface{}) (string, error) {
+	if val, err := value.FromGo(v); err == nil && val.Type().IsPrimitive() {
+		if str, err := value.Convert(val, value.String); err == nil {
+			return str.AsString(), nil
+		}
+	}
+
+	return "", fmt.Errorf("can't interpolate %s into a string", convert.TypeName(v))
+}
+
+func posErrorf(pos token.Pos, format string, args ...interface{}) error {
+	return &parser.PosError{Pos: pos, Err: fmt.Errorf(format, args...)}
+}
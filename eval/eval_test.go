@@ -0,0 +1,115 @@
+package eval
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/fatih/hcl/parser"
+)
+
+var vars = map[string]interface{}{
+	"var.name": "web",
+	"var.port": int64(8080),
+	"var":      map[string]interface{}{"nested": map[string]interface{}{"key": "value"}},
+	"list":     []interface{}{"a", "b"},
+	"aws_instance.web": []interface{}{
+		map[string]interface{}{"id": "i-1"},
+		map[string]interface{}{"id": "i-2"},
+	},
+}
+
+func TestEval(t *testing.T) {
+	cases := []struct {
+		src      string
+		expected interface{}
+	}{
+		{`v = "plain"`, "plain"},
+		{`v = "${var.name}"`, "web"},
+		{`v = "${var.port}"`, int64(8080)},
+		{`v = "${var.name}:${var.port}"`, "web:8080"},
+		{`v = "${var.nested.key}"`, "value"},
+		{`v = "${list.1}"`, "b"},
+		{`v = "${list}"`, []interface{}{"a", "b"}},
+		{`v = "${aws_instance.web.*.id}"`, []interface{}{"i-1", "i-2"}},
+		{`v = ["${var.name}", 1, 2.5]`, []interface{}{"web", int64(1), 2.5}},
+		{`v = true`, true},
+		{`v = null`, nil},
+		{`v = "${null}"`, nil},
+		{`v = [null]`, []interface{}{nil}},
+		{`v { port = "${var.port}" }`, map[string]interface{}{"port": int64(8080)}},
+	}
+
+	for _, c := range cases {
+		f, err := (&parser.Config{Syntax: parser.Experimental}).Parse([]byte(c.src))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		v, err := Eval(f, vars)
+		if err != nil {
+			t.Errorf("%s: %s", c.src, err)
+			continue
+		}
+
+		got := v.(map[string]interface{})["v"]
+		if !reflect.DeepEqual(got, c.expected) {
+			t.Errorf("%s: want: %#v got: %#v", c.src, c.expected, got)
+		}
+	}
+}
+
+func TestEvalNested(t *testing.T) {
+	f, err := parser.Parse([]byte(`
+service "web" { port = 80 }
+service "db" { port = 5432 }
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	v, err := Eval(f, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]interface{}{
+		"service": map[string]interface{}{
+			"web": map[string]interface{}{"port": int64(80)},
+			"db":  map[string]interface{}{"port": int64(5432)},
+		},
+	}
+
+	if !reflect.DeepEqual(v, expected) {
+		t.Errorf("want: %#v got: %#v", expected, v)
+	}
+}
+
+func TestEvalError(t *testing.T) {
+	cases := []struct {
+		src string
+		pos string
+	}{
+		{`v = "${unknown}"`, "1:8"},
+		{"v = 1\nw = \"x ${list}\"", "2:10"},
+		{`v = "${list.5}"`, "1:8"},
+		{`v = "${var.name.foo}"`, "1:8"},
+	}
+
+	for _, c := range cases {
+		f, err := parser.Parse([]byte(c.src))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = Eval(f, vars)
+		perr, ok := err.(*parser.PosError)
+		if !ok {
+			t.Errorf("%s: want: *parser.PosError got: %v", c.src, err)
+			continue
+		}
+
+		if perr.Pos.String() != c.pos {
+			t.Errorf("%s: want pos: %s got: %s", c.src, c.pos, perr)
+		}
+	}
+}
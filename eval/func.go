@@ -0,0 +1,266 @@
+package eval
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/fatih/hcl/ast"
+	"github.com/fatih/hcl/convert"
+)
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// Function is a Go function which can be called from interpolations. Create
+// it with Func.
+type Function struct {
+	fn reflect.Value
+}
+
+// Func wraps the Go function fn, so it can be called from interpolations. The
+// signature of fn defines the types of the arguments, variadic functions are
+// supported. fn must return a single value, optionally followed by an error.
+// Parameters and results must be of the types string, int, int64, float64,
+// bool, []string, []interface{}, map[string]string, map[string]interface{}
+// or interface{}.
+func Func(fn interface{}) (*Function, error) {
+	v := reflect.ValueOf(fn)
+	if v.Kind() != reflect.Func {
+		return nil, fmt.Errorf("expected a function, got: %T", fn)
+	}
+
+	t := v.Type()
+	for i := 0; i < t.NumIn(); i++ {
+		in := t.In(i)
+		if t.IsVariadic() && i == t.NumIn()-1 {
+			in = in.Elem()
+		}
+
+		if !supported(in) {
+			return nil, fmt.Errorf("unsupported parameter type %s", in)
+		}
+	}
+
+	switch {
+	case t.NumOut() == 1:
+	case t.NumOut() == 2 && t.Out(1) == errorType:
+	default:
+		return nil, errors.New("function must return a value and an optional error")
+	}
+
+	if !supported(t.Out(0)) {
+		return nil, fmt.Errorf("unsupported result type %s", t.Out(0))
+	}
+
+	return &Function{fn: v}, nil
+}
+
+// Register registers fn under the given name for the scope. It panics if fn
+// is not supported by Func.
+func (s *Scope) Register(name string, fn interface{}) {
+	f, err := Func(fn)
+	if err != nil {
+		panic("eval: " + name + ": " + err.Error())
+	}
+
+	if s.Functions == nil {
+		s.Functions = make(map[string]*Function)
+	}
+	s.Functions[name] = f
+}
+
+func (s *Scope) call(c *ast.Call) (interface{}, error) {
+	f, ok := s.Functions[c.Name]
+	if !ok {
+		return nil, posErrorf(c.Pos(), "unknown function %s", c.Name)
+	}
+
+	t := f.fn.Type()
+	n := t.NumIn()
+	if len(c.Args) != n && !(t.IsVariadic() && len(c.Args) >= n-1) {
+		return nil, posErrorf(c.Pos(), "%s: expected %d arguments, got: %d", c.Name, n, len(c.Args))
+	}
+
+	values := make([]interface{}, 0, len(c.Args))
+	for _, arg := range c.Args {
+		v, err := s.Eval(arg)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+	}
+
+	if u := unknown(c, values...); u != nil {
+		return u, nil
+	}
+
+	args := make([]reflect.Value, 0, len(c.Args))
+	for i, arg := range c.Args {
+		v := values[i]
+
+		var in reflect.Type
+		if t.IsVariadic() && i >= n-1 {
+			in = t.In(n - 1).Elem()
+		} else {
+			in = t.In(i)
+		}
+
+		rv, err := toGo(v, in)
+		if err != nil {
+			return nil, posErrorf(arg.Pos(), "%s: argument %d: %s", c.Name, i+1, err)
+		}
+		args = append(args, rv)
+	}
+
+	out := f.fn.Call(args)
+	if len(out) == 2 && !out[1].IsNil() {
+		return nil, posErrorf(c.Pos(), "%s: %s", c.Name, out[1].Interface())
+	}
+
+	return fromGo(out[0]), nil
+}
+
+func supported(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.String, reflect.Int, reflect.Int64, reflect.Float64, reflect.Bool:
+		return true
+	case reflect.Interface:
+		return t.NumMethod() == 0
+	case reflect.Slice:
+		k := t.Elem().Kind()
+		return k == reflect.String || k == reflect.Interface && t.Elem().NumMethod() == 0
+	case reflect.Map:
+		k := t.Elem().Kind()
+		return t.Key().Kind() == reflect.String &&
+			(k == reflect.String || k == reflect.Interface && t.Elem().NumMethod() == 0)
+	}
+	return false
+}
+
+// toGo converts the value v into a Go value of type t
+func toGo(v interface{}, t reflect.Type) (reflect.Value, error) {
+	mismatch := func() (reflect.Value, error) {
+		return reflect.Value{}, fmt.Errorf("expected %s, got: %s", typeName(t), convert.TypeName(v))
+	}
+
+	if v == nil {
+		return mismatch()
+	}
+
+	switch t.Kind() {
+	case reflect.Interface:
+		return reflect.ValueOf(&v).Elem(), nil
+	case reflect.String:
+		s, ok := v.(string)
+		if !ok {
+			return mismatch()
+		}
+		return reflect.ValueOf(s).Convert(t), nil
+	case reflect.Bool:
+		b, ok := v.(bool)
+		if !ok {
+			return mismatch()
+		}
+		return reflect.ValueOf(b).Convert(t), nil
+	case reflect.Int, reflect.Int64:
+		switch n := v.(type) {
+		case int64:
+			return reflect.ValueOf(n).Convert(t), nil
+		case float64:
+			if n == float64(int64(n)) {
+				return reflect.ValueOf(int64(n)).Convert(t), nil
+			}
+		}
+		return mismatch()
+	case reflect.Float64:
+		switch n := v.(type) {
+		case int64:
+			return reflect.ValueOf(float64(n)).Convert(t), nil
+		case float64:
+			return reflect.ValueOf(n).Convert(t), nil
+		}
+		return mismatch()
+	case reflect.Slice:
+		list, ok := v.([]interface{})
+		if !ok {
+			return mismatch()
+		}
+
+		s := reflect.MakeSlice(t, 0, len(list))
+		for _, elem := range list {
+			e, err := toGo(elem, t.Elem())
+			if err != nil {
+				return mismatch()
+			}
+			s = reflect.Append(s, e)
+		}
+		return s, nil
+	case reflect.Map:
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return mismatch()
+		}
+
+		res := reflect.MakeMap(t)
+		for k, elem := range m {
+			e, err := toGo(elem, t.Elem())
+			if err != nil {
+				return mismatch()
+			}
+			res.SetMapIndex(reflect.ValueOf(k), e)
+		}
+		return res, nil
+	}
+
+	return mismatch()
+}
+
+// fromGo converts a Go value returned by a function into a value
+func fromGo(v reflect.Value) interface{} {
+	switch v.Kind() {
+	case reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return fromGo(v.Elem())
+	case reflect.String:
+		return v.String()
+	case reflect.Bool:
+		return v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int()
+	case reflect.Float32, reflect.Float64:
+		return v.Float()
+	case reflect.Slice:
+		list := make([]interface{}, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			list = append(list, fromGo(v.Index(i)))
+		}
+		return list
+	case reflect.Map:
+		m := make(map[string]interface{}, v.Len())
+		for _, k := range v.MapKeys() {
+			m[k.String()] = fromGo(v.MapIndex(k))
+		}
+		return m
+	}
+
+	return v.Interface()
+}
+
+// typeName returns the name of a Go type as used in error messages
+func typeName(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Int, reflect.Int64, reflect.Float64:
+		return "number"
+	case reflect.Bool:
+		return "bool"
+	case reflect.Slice:
+		return "list"
+	case reflect.Map:
+		return "map"
+	}
+	return t.String()
+}
@@ -0,0 +1,100 @@
+package eval
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/fatih/hcl/parser"
+	"github.com/fatih/hcl/token"
+)
+
+func testScope() *Scope {
+	s := &Scope{Variables: vars}
+	s.Register("upper", strings.ToUpper)
+	s.Register("join", strings.Join)
+	s.Register("split", strings.Split)
+	s.Register("concat", func(lists ...[]interface{}) []interface{} {
+		var res []interface{}
+		for _, l := range lists {
+			res = append(res, l...)
+		}
+		return res
+	})
+	s.Register("add", func(a, b int) int { return a + b })
+	s.Register("fail", func() (string, error) { return "", errors.New("failed") })
+	return s
+}
+
+func evalTemplate(s *Scope, text string) (interface{}, error) {
+	tmpl, err := parser.ParseTemplate(text, token.Pos{Line: 1, Column: 1})
+	if err != nil {
+		return nil, err
+	}
+	return s.Eval(tmpl)
+}
+
+func TestCall(t *testing.T) {
+	cases := []struct {
+		text     string
+		expected interface{}
+	}{
+		{`${upper(var.name)}`, "WEB"},
+		{`${upper("a")}-${upper("b")}`, "A-B"},
+		{`${join(list, ",")}`, "a,b"},
+		{`${split("x,y", ",")}`, []interface{}{"x", "y"}},
+		{`${concat(list, split("c,d", ","))}`, []interface{}{"a", "b", "c", "d"}},
+		{`${concat()}`, []interface{}{}},
+		{`${add(var.port, 1)}`, int64(8081)},
+	}
+
+	s := testScope()
+	for _, c := range cases {
+		v, err := evalTemplate(s, c.text)
+		if err != nil {
+			t.Errorf("%s: %s", c.text, err)
+			continue
+		}
+
+		if !reflect.DeepEqual(v, c.expected) {
+			t.Errorf("%s: want: %#v got: %#v", c.text, c.expected, v)
+		}
+	}
+}
+
+func TestCallError(t *testing.T) {
+	cases := []struct {
+		text string
+		err  string
+	}{
+		{`${unknown()}`, "At 1:3: unknown function unknown"},
+		{`${upper()}`, "At 1:3: upper: expected 1 arguments, got: 0"},
+		{`${upper(1)}`, "At 1:9: upper: argument 1: expected string, got: number"},
+		{`${add(1, true)}`, "At 1:10: add: argument 2: expected number, got: bool"},
+		{`${fail()}`, "At 1:3: fail: failed"},
+	}
+
+	s := testScope()
+	for _, c := range cases {
+		_, err := evalTemplate(s, c.text)
+		if err == nil || err.Error() != c.err {
+			t.Errorf("%s: want: %q got: %v", c.text, c.err, err)
+		}
+	}
+}
+
+func TestFunc(t *testing.T) {
+	invalid := []interface{}{
+		"not a function",
+		func() {},
+		func(chan int) string { return "" },
+		func() (string, string) { return "", "" },
+	}
+
+	for _, fn := range invalid {
+		if _, err := Func(fn); err == nil {
+			t.Errorf("%T should not be supported", fn)
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package eval
+
+import (
+	"strings"
+
+	"github.com/fatih/hcl/ast"
+	"github.com/fatih/hcl/refs"
+)
+
+// LocalsBlock is the type of the blocks defining local values.
+const LocalsBlock = "locals"
+
+// WithLocals returns a copy of s in which the attributes of the top-level
+// locals blocks of f are defined as variables, so repeated values are
+// defined once:
+//
+//	locals {
+//	  region = "eu-west-1"
+//	  bucket = "logs-${local.region}"
+//	}
+//
+// The value of an attribute name is the variable local.name. Locals may
+// refer to the variables of s and to each other in any order, but not in a
+// cycle. They are evaluated with the evaluation mode of s, so with
+// PartialEval locals depending on undefined variables are unknown. Use
+// StripLocals to evaluate the rest of f without the locals blocks.
+func (s *Scope) WithLocals(f *ast.File) (*Scope, error) {
+	l := &locals{items: make(map[string]*ast.ObjectItem), state: make(map[string]int)}
+	for _, b := range f.Blocks(LocalsBlock) {
+		if len(b.Labels) > 0 {
+			return nil, posErrorf(b.Item.Pos(), "%s block can't have labels", LocalsBlock)
+		}
+
+		obj := b.Item.Val.(*ast.ObjectType)
+		if obj.List == nil {
+			continue
+		}
+
+		for _, item := range obj.List.Items {
+			if len(item.Keys) != 1 {
+				return nil, posErrorf(item.Pos(), "local values can't be blocks")
+			}
+
+			name := "local." + item.Keys[0].Token.Value()
+			if prev, ok := l.items[name]; ok {
+				return nil, posErrorf(item.Pos(), "%s already defined at %s", name, prev.Pos())
+			}
+			l.items[name] = item
+			l.names = append(l.names, name)
+		}
+	}
+
+	c := *s
+	c.Variables = make(map[string]interface{}, len(s.Variables)+len(l.names))
+	for k, v := range s.Variables {
+		c.Variables[k] = v
+	}
+	l.scope = &c
+
+	for _, name := range l.names {
+		if err := l.eval(name, nil); err != nil {
+			return nil, err
+		}
+	}
+	return &c, nil
+}
+
+// StripLocals returns a shallow copy of f without its top-level locals
+// blocks.
+func StripLocals(f *ast.File) *ast.File {
+	list, ok := f.Node.(*ast.ObjectList)
+	if !ok {
+		return f
+	}
+
+	stripped := &ast.ObjectList{}
+	for _, item := range list.Items {
+		if _, ok := item.Val.(*ast.ObjectType); ok && len(item.Keys) > 0 && item.Keys[0].Token.Value() == LocalsBlock {
+			continue
+		}
+		stripped.Add(item)
+	}
+
+	c := *f
+	c.Node = stripped
+	return &c
+}
+
+// states of the evaluation of a local value
+const (
+	unvisited = iota
+	visiting
+	done
+)
+
+// locals evaluates local values in the order of their dependencies
+type locals struct {
+	scope *Scope
+	items map[string]*ast.ObjectItem
+	names []string // in the order of the source
+	state map[string]int
+}
+
+// eval evaluates the local name after the locals it refers to. stack is the
+// chain of locals referring to name, to report cycles.
+func (l *locals) eval(name string, stack []string) error {
+	switch l.state[name] {
+	case done:
+		return nil
+	case visiting:
+		return posErrorf(l.items[name].Pos(), "cycle in locals: %s", cycle(stack, name))
+	}
+
+	l.state[name] = visiting
+	stack = append(stack, name)
+
+	item := l.items[name]
+	found, err := refs.Find(item.Val)
+	if err != nil {
+		return err
+	}
+
+	for _, r := range found {
+		if r.Kind != refs.Variable {
+			continue
+		}
+
+		if dep := localName(r.Name); l.items[dep] != nil {
+			if err := l.eval(dep, stack); err != nil {
+				return err
+			}
+		}
+	}
+
+	v, err := l.scope.Eval(item.Val)
+	if err != nil {
+		return err
+	}
+
+	l.scope.Variables[name] = v
+	l.state[name] = done
+	return nil
+}
+
+// localName returns the name of the local a variable such as local.tags.env
+// refers to, or "" if it doesn't refer to a local
+func localName(name string) string {
+	parts := strings.SplitN(name, ".", 3)
+	if len(parts) < 2 || parts[0] != "local" {
+		return ""
+	}
+	return parts[0] + "." + parts[1]
+}
+
+// cycle returns the part of stack starting at name, followed by name
+func cycle(stack []string, name string) string {
+	for i, n := range stack {
+		if n == name {
+			return strings.Join(append(stack[i:], name), " -> ")
+		}
+	}
+	return name
+}
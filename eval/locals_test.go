@@ -0,0 +1,121 @@
+package eval
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/fatih/hcl/parser"
+)
+
+func TestWithLocals(t *testing.T) {
+	f, err := parser.Parse([]byte(`
+locals {
+  bucket = "${local.prefix}-logs"
+  prefix = "${var.name}-${local.region}"
+}
+
+locals {
+  region = "eu-west-1"
+  tags   = { env = "prod" }
+}
+
+bucket = "${local.bucket}"
+env    = "${local.tags.env}"
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := &Scope{Variables: vars}
+	ls, err := s.WithLocals(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := s.Variables["local.region"]; ok {
+		t.Error("WithLocals modified the variables of the scope")
+	}
+
+	v, err := ls.Eval(StripLocals(f))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]interface{}{
+		"bucket": "web-eu-west-1-logs",
+		"env":    "prod",
+	}
+	if !reflect.DeepEqual(v, expected) {
+		t.Errorf("want: %#v got: %#v", expected, v)
+	}
+}
+
+func TestWithLocalsPartial(t *testing.T) {
+	f, err := parser.Parse([]byte(`
+locals {
+  name = "${var.undefined}"
+  port = 80
+}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := &Scope{partial: true}
+	ls, err := s.WithLocals(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if IsKnown(ls.Variables["local.name"]) {
+		t.Errorf("local.name: want unknown got: %#v", ls.Variables["local.name"])
+	}
+	if ls.Variables["local.port"] != int64(80) {
+		t.Errorf("local.port: want: 80 got: %#v", ls.Variables["local.port"])
+	}
+}
+
+func TestWithLocalsError(t *testing.T) {
+	cases := []struct {
+		src string
+		err string
+	}{
+		{
+			"locals {\n  a = \"${local.b}\"\n  b = \"${local.c}\"\n  c = \"${local.a}\"\n}",
+			"At 2:3: cycle in locals: local.a -> local.b -> local.c -> local.a",
+		},
+		{
+			"locals {\n  a = \"${local.a}\"\n}",
+			"At 2:3: cycle in locals: local.a -> local.a",
+		},
+		{
+			"locals {\n  a = 1\n}\nlocals {\n  a = 2\n}",
+			"At 5:3: local.a already defined at 2:3",
+		},
+		{
+			"locals {\n  a = \"${local.missing}\"\n}",
+			"At 2:10: unknown variable local.missing",
+		},
+		{
+			`locals "x" {}`,
+			"At 1:1: locals block can't have labels",
+		},
+		{
+			"locals {\n  a b {}\n}",
+			"At 2:3: local values can't be blocks",
+		},
+	}
+
+	for _, c := range cases {
+		f, err := parser.Parse([]byte(c.src))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = (&Scope{}).WithLocals(f)
+		if err == nil || !strings.Contains(err.Error(), c.err) {
+			t.Errorf("%q: want error: %s got: %v", c.src, c.err, err)
+		}
+	}
+}
@@ -0,0 +1,259 @@
+package eval
+
+import (
+	"math"
+	"reflect"
+
+	"github.com/fatih/hcl/ast"
+	"github.com/fatih/hcl/convert"
+	"github.com/fatih/hcl/token"
+)
+
+// binary evaluates a binary expression. Arithmetic operators are defined on
+// numbers, where the result is an integer if both operands are integers.
+// Equality is defined on all values, ordering on numbers and strings and the
+// logical operators on bools, which are evaluated with short-circuit.
+func (s *Scope) binary(b *ast.Binary) (interface{}, error) {
+	x, err := s.Eval(b.X)
+	if err != nil {
+		return nil, err
+	}
+
+	// short-circuit evaluation of the logical operators
+	if b.Op == token.AND || b.Op == token.OR {
+		xb, ok := x.(bool)
+		if _, unknown := x.(*Unknown); !ok && !unknown {
+			return nil, posErrorf(b.X.Pos(), "operator %s not defined on %s", opString(b.Op), convert.TypeName(x))
+		}
+
+		if ok && xb == (b.Op == token.OR) {
+			return xb, nil
+		}
+
+		y, err := s.Eval(b.Y)
+		if err != nil {
+			return nil, err
+		}
+
+		yb, ok := y.(bool)
+		if _, unknown := y.(*Unknown); !ok && !unknown {
+			return nil, posErrorf(b.Y.Pos(), "operator %s not defined on %s", opString(b.Op), convert.TypeName(y))
+		}
+
+		// a known operand might still determine the result
+		if ok && yb == (b.Op == token.OR) {
+			return yb, nil
+		}
+
+		if u := unknown(b, x, y); u != nil {
+			return u, nil
+		}
+		return yb, nil
+	}
+
+	y, err := s.Eval(b.Y)
+	if err != nil {
+		return nil, err
+	}
+
+	if u := unknown(b, x, y); u != nil {
+		return u, nil
+	}
+
+	switch b.Op {
+	case token.EQL:
+		return equal(x, y), nil
+	case token.NEQ:
+		return !equal(x, y), nil
+	case token.LSS, token.LEQ, token.GTR, token.GEQ:
+		return compare(b, x, y)
+	}
+
+	return arithmetic(b, x, y)
+}
+
+func (s *Scope) unary(u *ast.Unary) (interface{}, error) {
+	x, err := s.Eval(u.X)
+	if err != nil {
+		return nil, err
+	}
+
+	if xu := unknown(u, x); xu != nil {
+		return xu, nil
+	}
+
+	switch t := x.(type) {
+	case int64:
+		if u.Op == token.SUB {
+			return -t, nil
+		}
+	case float64:
+		if u.Op == token.SUB {
+			return -t, nil
+		}
+	case bool:
+		if u.Op == token.NOT {
+			return !t, nil
+		}
+	}
+
+	return nil, posErrorf(u.OpPos, "operator %s not defined on %s", opString(u.Op), convert.TypeName(x))
+}
+
+// conditional evaluates a conditional expression, only the selected value is
+// evaluated.
+func (s *Scope) conditional(c *ast.Conditional) (interface{}, error) {
+	cond, err := s.Eval(c.Cond)
+	if err != nil {
+		return nil, err
+	}
+
+	if u := unknown(c, cond); u != nil {
+		return u, nil
+	}
+
+	b, ok := cond.(bool)
+	if !ok {
+		return nil, posErrorf(c.Cond.Pos(), "condition must be a bool, got: %s", convert.TypeName(cond))
+	}
+
+	if b {
+		return s.Eval(c.True)
+	}
+	return s.Eval(c.False)
+}
+
+func arithmetic(b *ast.Binary, x, y interface{}) (interface{}, error) {
+	xi, xint := x.(int64)
+	yi, yint := y.(int64)
+	if xint && yint {
+		switch b.Op {
+		case token.ADD:
+			return xi + yi, nil
+		case token.SUB:
+			return xi - yi, nil
+		case token.MUL:
+			return xi * yi, nil
+		case token.QUO, token.MOD:
+			if yi == 0 {
+				return nil, posErrorf(b.OpPos, "division by zero")
+			}
+
+			if b.Op == token.QUO {
+				return xi / yi, nil
+			}
+			return xi % yi, nil
+		}
+	}
+
+	xf, xok := toFloat(x)
+	yf, yok := toFloat(y)
+	if !xok || !yok {
+		return nil, posErrorf(b.OpPos, "operator %s not defined on %s and %s",
+			opString(b.Op), convert.TypeName(x), convert.TypeName(y))
+	}
+
+	switch b.Op {
+	case token.ADD:
+		return xf + yf, nil
+	case token.SUB:
+		return xf - yf, nil
+	case token.MUL:
+		return xf * yf, nil
+	case token.QUO, token.MOD:
+		if yf == 0 {
+			return nil, posErrorf(b.OpPos, "division by zero")
+		}
+
+		if b.Op == token.QUO {
+			return xf / yf, nil
+		}
+		return math.Mod(xf, yf), nil
+	}
+
+	return nil, posErrorf(b.OpPos, "unsupported operator %s", opString(b.Op))
+}
+
+func compare(b *ast.Binary, x, y interface{}) (interface{}, error) {
+	var cmp int
+	xs, xstr := x.(string)
+	ys, ystr := y.(string)
+	xf, xnum := toFloat(x)
+	yf, ynum := toFloat(y)
+
+	switch {
+	case xstr && ystr:
+		cmp = compareValues(xs < ys, xs > ys)
+	case xnum && ynum:
+		cmp = compareValues(xf < yf, xf > yf)
+	default:
+		return nil, posErrorf(b.OpPos, "operator %s not defined on %s and %s",
+			opString(b.Op), convert.TypeName(x), convert.TypeName(y))
+	}
+
+	switch b.Op {
+	case token.LSS:
+		return cmp < 0, nil
+	case token.LEQ:
+		return cmp <= 0, nil
+	case token.GTR:
+		return cmp > 0, nil
+	}
+	return cmp >= 0, nil
+}
+
+func compareValues(less, greater bool) int {
+	switch {
+	case less:
+		return -1
+	case greater:
+		return 1
+	}
+	return 0
+}
+
+// equal reports whether x and y are equal, numbers are equal if they have the
+// same value regardless of being an integer or a float.
+func equal(x, y interface{}) bool {
+	xf, xnum := toFloat(x)
+	yf, ynum := toFloat(y)
+	if xnum && ynum {
+		return xf == yf
+	}
+	return reflect.DeepEqual(x, y)
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch t := v.(type) {
+	case int64:
+		return float64(t), true
+	case float64:
+		return t, true
+	}
+	return 0, false
+}
+
+var opStrings = map[token.Type]string{
+	token.ADD: "+",
+	token.SUB: "-",
+	token.MUL: "*",
+	token.QUO: "/",
+	token.MOD: "%",
+	token.EQL: "==",
+	token.NEQ: "!=",
+	token.LSS: "<",
+	token.GTR: ">",
+	token.LEQ: "<=",
+	token.GEQ: ">=",
+	token.AND: "&&",
+	token.OR:  "||",
+	token.NOT: "!",
+}
+
+// opString returns the source form of an operator
+func opString(op token.Type) string {
+	if s, ok := opStrings[op]; ok {
+		return s
+	}
+	return op.String()
+}
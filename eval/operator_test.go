@@ -0,0 +1,83 @@
+package eval
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestOperators(t *testing.T) {
+	cases := []struct {
+		text     string
+		expected interface{}
+	}{
+		{`${var.port + 1}`, int64(8081)},
+		{`${var.port - 80 * 2}`, int64(7920)},
+		{`${(1 + 2) * 3}`, int64(9)},
+		{`${7 / 2}`, int64(3)},
+		{`${7 % 3}`, int64(1)},
+		{`${7.0 / 2}`, 3.5},
+		{`${1 + 0.5}`, 1.5},
+		{`${-var.port}`, int64(-8080)},
+		{`${1 == 1.0}`, true},
+		{`${var.name == "web"}`, true},
+		{`${var.name != "web"}`, false},
+		{`${list == list}`, true},
+		{`${2 < 3}${2 <= 2}${3 > 4}${3 >= 4}`, "truetruefalsefalse"},
+		{`${"a" < "b"}`, true},
+		{`${true && !false}`, true},
+		{`${false || 1 > 0}`, true},
+		{`port = ${var.port + 1}`, "port = 8081"},
+		{`${var.port > 80 ? "high" : "low"}`, "high"},
+		{`${false ? 1 : true ? 2 : 3}`, int64(2)},
+		{`${var.name == "db" ? 5432 : var.port + 1}`, int64(8081)},
+	}
+
+	s := testScope()
+	for _, c := range cases {
+		v, err := evalTemplate(s, c.text)
+		if err != nil {
+			t.Errorf("%s: %s", c.text, err)
+			continue
+		}
+
+		if !reflect.DeepEqual(v, c.expected) {
+			t.Errorf("%s: want: %#v got: %#v", c.text, c.expected, v)
+		}
+	}
+}
+
+func TestOperatorsShortCircuit(t *testing.T) {
+	s := testScope()
+	for _, text := range []string{`${false && unknown}`, `${true || fail()}`, `${true ? 1 : unknown}`, `${false ? fail() : 1}`} {
+		if _, err := evalTemplate(s, text); err != nil {
+			t.Errorf("%s: right operand should not be evaluated: %s", text, err)
+		}
+	}
+}
+
+func TestOperatorsError(t *testing.T) {
+	cases := []struct {
+		text string
+		err  string
+	}{
+		{`${var.name + 1}`, "At 1:12: operator + not defined on string and number"},
+		{`${1 / 0}`, "At 1:5: division by zero"},
+		{`${1.5 % 0}`, "At 1:7: division by zero"},
+		{`${-var.name}`, "At 1:3: operator - not defined on string"},
+		{`${!1}`, "At 1:3: operator ! not defined on number"},
+		{`${1 && true}`, "At 1:3: operator && not defined on number"},
+		{`${false || "x"}`, "At 1:12: operator || not defined on string"},
+		{`${list < 1}`, "At 1:8: operator < not defined on list and number"},
+		{`${-var}`, "At 1:3: operator - not defined on object"},
+		{`${var.port ? 1 : 2}`, "At 1:3: condition must be a bool, got: number"},
+		{`${true ? unknown : 2}`, "At 1:10: unknown variable unknown"},
+	}
+
+	s := testScope()
+	for _, c := range cases {
+		_, err := evalTemplate(s, c.text)
+		if err == nil || err.Error() != c.err {
+			t.Errorf("%s: want: %q got: %v", c.text, c.err, err)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package eval
+
+import (
+	"sort"
+
+	"github.com/fatih/hcl/ast"
+)
+
+// Unknown is the value of an expression which depends on variables not
+// defined in the scope. It's only returned by partial evaluation, so the
+// expression can be evaluated again once all of its inputs are available.
+type Unknown struct {
+	Node ast.Node        // expression which couldn't be evaluated
+	Vars []*ast.Variable // undefined variables the expression depends on
+}
+
+// PartialEval evaluates n with the given variables. See Scope.PartialEval.
+func PartialEval(n ast.Node, vars map[string]interface{}) (interface{}, error) {
+	s := &Scope{Variables: vars}
+	return s.PartialEval(n)
+}
+
+// PartialEval evaluates n like Eval, but references to undefined variables
+// don't result in an error. Instead every expression depending on them
+// evaluates to an *Unknown value, which might be nested in lists and maps.
+// Logical operators are resolved if the known operand determines the
+// result, such as in "${false && var.undefined}". Use Unknowns to list the
+// variables which remain undefined.
+func (s *Scope) PartialEval(n ast.Node) (interface{}, error) {
+	p := *s
+	p.partial = true
+	return p.Eval(n)
+}
+
+// Unknowns returns the undefined variables of all *Unknown values of v,
+// including those nested in lists and maps, in lexical order. It returns nil
+// if v is fully known.
+func Unknowns(v interface{}) []*ast.Variable {
+	var vars []*ast.Variable
+	switch t := v.(type) {
+	case *Unknown:
+		vars = append(vars, t.Vars...)
+	case []interface{}:
+		for _, elem := range t {
+			vars = append(vars, Unknowns(elem)...)
+		}
+	case map[string]interface{}:
+		for _, elem := range t {
+			vars = append(vars, Unknowns(elem)...)
+		}
+		sortVars(vars)
+	}
+	return vars
+}
+
+// IsKnown reports whether v doesn't contain any *Unknown values.
+func IsKnown(v interface{}) bool {
+	return len(Unknowns(v)) == 0
+}
+
+// unknown returns an *Unknown value for n if any of the given values is
+// unknown, with the undefined variables of all of them. Otherwise it returns
+// nil.
+func unknown(n ast.Node, values ...interface{}) *Unknown {
+	var u *Unknown
+	for _, v := range values {
+		vu, ok := v.(*Unknown)
+		if !ok {
+			continue
+		}
+
+		if u == nil {
+			u = &Unknown{Node: n}
+		}
+		u.Vars = append(u.Vars, vu.Vars...)
+	}
+	return u
+}
+
+// sortVars sorts the variables by their position
+func sortVars(vars []*ast.Variable) {
+	sort.Sort(byPos(vars))
+}
+
+type byPos []*ast.Variable
+
+func (b byPos) Len() int      { return len(b) }
+func (b byPos) Swap(i, j int) { b[i], b[j] = b[j], b[i] }
+func (b byPos) Less(i, j int) bool {
+	return b[i].NamePos.Offset < b[j].NamePos.Offset
+}
@@ -0,0 +1,108 @@
+package eval
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/fatih/hcl/parser"
+	"github.com/fatih/hcl/token"
+)
+
+func TestPartialEval(t *testing.T) {
+	cases := []struct {
+		text     string
+		expected interface{}
+		unknowns []string
+	}{
+		{`${var.name}`, "web", nil},
+		{`${missing}`, nil, []string{"missing"}},
+		{`${var.port + missing}`, nil, []string{"missing"}},
+		{`${a}-${var.name}-${b}`, nil, []string{"a", "b"}},
+		{`${upper(missing)}`, nil, []string{"missing"}},
+		{`${-missing}`, nil, []string{"missing"}},
+		{`${missing ? 1 : 2}`, nil, []string{"missing"}},
+		{`${true ? var.name : missing}`, "web", nil},
+		{`${false && missing}`, false, nil},
+		{`${missing || true}`, true, nil},
+		{`${missing && true}`, nil, []string{"missing"}},
+		{`${a == b}`, nil, []string{"a", "b"}},
+		{`%{ for x in missing }${x}%{ endfor }`, nil, []string{"missing"}},
+		{`%{ for x in list }${x}${missing}%{ endfor }`, nil, []string{"missing"}},
+		{`%{ if missing }x%{ endif }`, nil, []string{"missing"}},
+		{`%{ if true }${missing}%{ endif }`, nil, []string{"missing"}},
+	}
+
+	s := testScope()
+	for _, c := range cases {
+		tmpl, err := parser.ParseTemplate(c.text, token.Pos{Line: 1, Column: 1})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		v, err := s.PartialEval(tmpl)
+		if err != nil {
+			t.Errorf("%s: %s", c.text, err)
+			continue
+		}
+
+		var names []string
+		for _, u := range Unknowns(v) {
+			names = append(names, u.Name)
+		}
+
+		if !reflect.DeepEqual(names, c.unknowns) {
+			t.Errorf("%s: want unknowns: %v got: %v", c.text, c.unknowns, names)
+		}
+
+		if c.unknowns == nil && !reflect.DeepEqual(v, c.expected) {
+			t.Errorf("%s: want: %#v got: %#v", c.text, c.expected, v)
+		}
+	}
+}
+
+func TestPartialEvalFile(t *testing.T) {
+	f, err := parser.Parse([]byte(`
+name = "${var.name}"
+port = "${input.port}"
+hosts = ["a", "${input.host}"]
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	v, err := PartialEval(f, vars)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := v.(map[string]interface{})
+	if m["name"] != "web" {
+		t.Errorf("want: web got: %#v", m["name"])
+	}
+
+	if IsKnown(v) {
+		t.Fatal("value should not be known")
+	}
+
+	unknowns := Unknowns(v)
+	if len(unknowns) != 2 || unknowns[0].Name != "input.port" || unknowns[1].Name != "input.host" {
+		t.Fatalf("unexpected unknowns: %v", unknowns)
+	}
+
+	// the unknown value is evaluated once the input is available
+	u := m["port"].(*Unknown)
+	s := &Scope{Variables: map[string]interface{}{"input.port": int64(80)}}
+	port, err := s.Eval(u.Node)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if port != int64(80) {
+		t.Errorf("want: 80 got: %#v", port)
+	}
+
+	// unknown variables are still an error for Eval
+	if _, err := Eval(f, vars); err == nil {
+		t.Error("Eval should fail with unknown variables")
+	}
+}
@@ -0,0 +1,92 @@
+package hcl
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"sync"
+
+	"github.com/fatih/hcl/ast"
+	"github.com/fatih/hcl/parser"
+)
+
+// ParseFiles parses the HCL files concurrently and returns a single syntax
+// tree with the items, comments and directives of all files, in the order
+// of the sorted paths, so the result doesn't depend on the order of the
+// arguments. Items with the same key in different files are merged by the
+// decoder like items of a single file. The positions of the nodes contain
+// the name of their file, as do the positions of errors. The error of the
+// first file in the order of the sorted paths failing to parse is returned.
+func (c *DecoderConfig) ParseFiles(paths ...string) (*ast.File, error) {
+	paths = append([]string(nil), paths...)
+	sort.Strings(paths)
+
+	files := make([]*ast.File, len(paths))
+	errs := make([]error, len(paths))
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, runtime.GOMAXPROCS(0))
+	for i, path := range paths {
+		wg.Add(1)
+		go func(i int, path string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			files[i], errs[i] = c.parseFile(path)
+		}(i, path)
+	}
+	wg.Wait()
+
+	res := &ast.File{Node: &ast.ObjectList{}}
+	for i, f := range files {
+		if errs[i] != nil {
+			return nil, errs[i]
+		}
+
+		list, ok := f.Node.(*ast.ObjectList)
+		if !ok {
+			return nil, fmt.Errorf("%s: expected an object list, got: %T", paths[i], f.Node)
+		}
+		for _, item := range list.Items {
+			res.Node.(*ast.ObjectList).Add(item)
+		}
+		res.Comments = append(res.Comments, f.Comments...)
+		res.Directives = append(res.Directives, f.Directives...)
+	}
+	return res, nil
+}
+
+// ParseDir parses the files with the extension ".hcl" in the directory dir,
+// not in its subdirectories, sorted by name. See ParseFiles.
+func (c *DecoderConfig) ParseDir(dir string) (*ast.File, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.hcl"))
+	if err != nil {
+		return nil, err
+	}
+	return c.ParseFiles(paths...)
+}
+
+// parseFile parses the file path with the syntax, the keyword case folding,
+// the duplicate policy and the logger of c
+func (c *DecoderConfig) parseFile(path string) (*ast.File, error) {
+	src, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return (&parser.Config{Syntax: c.Syntax, FoldCase: c.FoldCase, Duplicates: c.Duplicates, Logger: c.Logger, Filename: path}).Parse(src)
+}
+
+// ParseFiles parses the files with DefaultDecoderConfig. See
+// DecoderConfig.ParseFiles.
+func ParseFiles(paths ...string) (*ast.File, error) {
+	return DefaultDecoderConfig.ParseFiles(paths...)
+}
+
+// ParseDir parses the files with the extension ".hcl" in dir with
+// DefaultDecoderConfig. See DecoderConfig.ParseFiles.
+func ParseDir(dir string) (*ast.File, error) {
+	return DefaultDecoderConfig.ParseDir(dir)
+}
@@ -0,0 +1,84 @@
+package hcl
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/fatih/hcl/ast"
+)
+
+func TestParseDir(t *testing.T) {
+	dir, err := ioutil.TempDir("", "hcl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"b.hcl":        "service \"db\" {\n  port = 5432\n}\n",
+		"a.hcl":        "region = \"eu\"\n\nservice \"web\" {\n  port = 80\n}\n",
+		"c.txt":        "not = hcl = at all",
+		"sub/d.hcl":    "region = \"us\"",
+		"broken/x.hcl": "a = [1 2}",
+	}
+	for name, src := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	f, err := ParseDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out struct {
+		Region  string                    `hcl:"region"`
+		Service map[string]map[string]int `hcl:"service"`
+	}
+	if err := DecodeObject(&out, f); err != nil {
+		t.Fatal(err)
+	}
+	if out.Region != "eu" || !reflect.DeepEqual(out.Service, map[string]map[string]int{"web": {"port": 80}, "db": {"port": 5432}}) {
+		t.Errorf("unexpected result: %+v", out)
+	}
+
+	// every node records its file
+	var names []string
+	ast.Walk(f, func(n ast.Node) (ast.Node, bool) {
+		if lit, ok := n.(*ast.LiteralType); ok {
+			names = append(names, filepath.Base(lit.Pos().Filename))
+		}
+		return n, true
+	})
+	if want := []string{"a.hcl", "a.hcl", "b.hcl"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("want the files %q of the literals, got %q", want, names)
+	}
+
+	// the files are merged in the order of their names, not of the arguments
+	f, err = ParseFiles(filepath.Join(dir, "sub", "d.hcl"), filepath.Join(dir, "b.hcl"), filepath.Join(dir, "a.hcl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var order []string
+	for _, item := range f.Node.(*ast.ObjectList).Items {
+		order = append(order, filepath.Base(item.Pos().Filename))
+	}
+	if want := []string{"a.hcl", "a.hcl", "b.hcl", "d.hcl"}; !reflect.DeepEqual(order, want) {
+		t.Errorf("want the files %q of the items, got %q", want, order)
+	}
+
+	_, err = ParseDir(filepath.Join(dir, "broken"))
+	name := filepath.Join(dir, "broken", "x.hcl")
+	want := "At " + name + ":1:9: list opened at " + name + ":1:5 not terminated, expected: ] got: RBRACE"
+	if err == nil || err.Error() != want {
+		t.Errorf("want error %q, got %v", want, err)
+	}
+}
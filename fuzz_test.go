@@ -0,0 +1,107 @@
+//go:build go1.18
+// +build go1.18
+
+package hcl
+
+import (
+	"testing"
+
+	"github.com/fatih/hcl/hcltest"
+	"github.com/fatih/hcl/parser"
+	"github.com/fatih/hcl/scanner"
+	"github.com/fatih/hcl/token"
+)
+
+// controlSources are seeds with control bytes, which the scanner must reject
+// or keep, but never swallow
+var controlSources = []string{
+	"A\x00=0",
+	"A=0\x00",
+	"a = \"\x01\x1b\"",
+	"a\x7f = 1",
+	"a = 1\r\nb = \"\t\"\r\n",
+	"# \x0c\nb = 2",
+	"a = <<EOF\n\x02\nEOF\n",
+	"\x00",
+}
+
+// FuzzRoundTrip checks that formatting a source doesn't change its syntax
+// tree and that encoding its decoded value doesn't change the value.
+func FuzzRoundTrip(f *testing.F) {
+	for _, src := range roundTripSources(f) {
+		f.Add(src)
+	}
+
+	for _, src := range controlSources {
+		f.Add([]byte(src))
+	}
+
+	for _, c := range hcltest.Corpus {
+		f.Add([]byte(c.Src))
+	}
+
+	f.Fuzz(func(t *testing.T, src []byte) {
+		if err := hcltest.CheckPrint(src); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := hcltest.CheckEncode(Decode, Marshal, src); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+type fuzzConfig struct {
+	Name     string            `hcl:"name"`
+	Port     int               `hcl:"port"`
+	Enabled  bool              `hcl:"enabled"`
+	Weight   float64           `hcl:"weight"`
+	Tags     []string          `hcl:"tags"`
+	Labels   map[string]string `hcl:"labels"`
+	Services []struct {
+		Name  string `hcl:",key"`
+		Image string `hcl:"image"`
+		Ports []int  `hcl:"ports"`
+	} `hcl:"service"`
+	Rest map[string]interface{} `hcl:",unused"`
+}
+
+// FuzzDecode checks that every source terminates in the scanner, the parser
+// and the decoder with either a value or an error, never with a panic or a
+// hang.
+func FuzzDecode(f *testing.F) {
+	for _, src := range roundTripSources(f) {
+		f.Add(src)
+	}
+
+	for _, c := range hcltest.Corpus {
+		f.Add([]byte(c.Src))
+	}
+
+	for _, src := range controlSources {
+		f.Add([]byte(src))
+	}
+
+	for seed := int64(0); seed < 8; seed++ {
+		f.Add(hcltest.GenerateFile(seed, 256))
+	}
+
+	config := &DecoderConfig{MaxValues: 10000, MaxSliceLen: 1000, MaxMapSize: 1000}
+	f.Fuzz(func(t *testing.T, src []byte) {
+		// every token but EOF consumes at least a byte of the source
+		s := scanner.New(src)
+		for n := 0; s.Scan().Type != token.EOF; n++ {
+			if n > len(src) {
+				t.Fatalf("scanner doesn't terminate on %q", src)
+			}
+		}
+
+		parser.ParseWithRecovery(src)
+
+		var v interface{}
+		config.Decode(&v, string(src))
+
+		var out fuzzConfig
+		config.Decode(&out, string(src))
+	})
+}
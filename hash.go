@@ -0,0 +1,85 @@
+package hcl
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+
+	"github.com/fatih/hcl/ast"
+	"github.com/fatih/hcl/token"
+)
+
+// Hash returns the hex encoded SHA-256 digest of the semantic content of n.
+// It ignores formatting, comments and the order of keys, so files only
+// differing in these have the same hash. Items with multiple keys equal
+// nested blocks, i.e. `a "b" {}` equals `a { b {} }`, and numbers are
+// compared by their value. The order of items with the same key and of list
+// elements is significant.
+func Hash(n ast.Node) string {
+	h := sha256.New()
+	writeHash(h, n)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// writeHash writes the canonical form of n to w
+func writeHash(w io.Writer, n ast.Node) {
+	if f, ok := n.(*ast.File); ok {
+		n = f.Node
+	}
+
+	if list, ok := ast.ObjectListOf(n); ok {
+		keys, values := fields(list)
+		sort.Strings(keys)
+
+		io.WriteString(w, "{")
+		for _, k := range keys {
+			io.WriteString(w, strconv.Quote(k)+":[")
+			for _, v := range values[k] {
+				writeHash(w, v)
+				io.WriteString(w, ",")
+			}
+			io.WriteString(w, "]")
+		}
+		io.WriteString(w, "}")
+		return
+	}
+
+	switch t := n.(type) {
+	case *ast.ListType:
+		io.WriteString(w, "[")
+		for _, elem := range t.List {
+			writeHash(w, elem)
+			io.WriteString(w, ",")
+		}
+		io.WriteString(w, "]")
+	case *ast.LiteralType:
+		// units decode to their text, tag them so 10s differs from "10s"
+		if t.Token.Type == token.UNIT {
+			io.WriteString(w, "u"+strconv.Quote(t.Token.Text))
+			return
+		}
+
+		d := &decoder{config: &DefaultDecoderConfig}
+		v, err := d.literal(t)
+		if err != nil {
+			io.WriteString(w, t.Token.Text)
+			return
+		}
+
+		switch v := v.(type) {
+		case string:
+			io.WriteString(w, "s"+strconv.Quote(v))
+		case int:
+			io.WriteString(w, "n"+strconv.Itoa(v))
+		case float64:
+			io.WriteString(w, "n"+strconv.FormatFloat(v, 'g', -1, 64))
+		default:
+			fmt.Fprintf(w, "%T:%v", v, v)
+		}
+	default:
+		fmt.Fprintf(w, "%T", n)
+	}
+}
@@ -0,0 +1,54 @@
+package hcl
+
+import (
+	"testing"
+
+	"github.com/fatih/hcl/ast"
+	"github.com/fatih/hcl/parser"
+)
+
+func TestHash(t *testing.T) {
+	cases := []struct {
+		A, B  string
+		Equal bool
+	}{
+		{`a = 1`, `a=1 # comment`, true},
+		{"a = 1\nb = 2", "b = 2\na = 1", true},
+		{`a = "x"`, `"a" = "x"`, true},
+		{`a = 1`, `a = 1.0`, true},
+		{`a = 0x10`, `a = 16`, true},
+		{`a "b" { c = 1 }`, `a { b { c = 1 } }`, true},
+		{"a = [1, 2]", "a = [\n  1,\n  2,\n]", true},
+		{`a = 1`, `a = 2`, false},
+		{`a = 1`, `a = "1"`, false},
+		{`a = true`, `a = "true"`, false},
+		{`a = 10s`, `a = "10s"`, false},
+		{`a = 10s`, `a = 10s`, true},
+		{`a = 10s`, `a = 10m`, false},
+		{`a = [1, 2]`, `a = [2, 1]`, false},
+		{"a { b = 1 }\na { b = 2 }", "a { b = 2 }\na { b = 1 }", false},
+		{`a { b = 1 }`, `a { c = 1 }`, false},
+	}
+
+	// the experimental syntax parses the unit literals
+	config := &parser.Config{Syntax: parser.Experimental}
+	for _, c := range cases {
+		a, err := config.Parse([]byte(c.A))
+		if err != nil {
+			t.Fatalf("%q: %s", c.A, err)
+		}
+
+		b, err := config.Parse([]byte(c.B))
+		if err != nil {
+			t.Fatalf("%q: %s", c.B, err)
+		}
+
+		if equal := Hash(a) == Hash(b); equal != c.Equal {
+			t.Errorf("%q and %q: want equal: %t, got: %t", c.A, c.B, c.Equal, equal)
+		}
+	}
+
+	if h := Hash(&ast.ObjectList{}); len(h) != 64 {
+		t.Errorf("expected a hex encoded SHA-256 digest, got: %q", h)
+	}
+}
@@ -0,0 +1,303 @@
+package hcltest
+
+// Case is a single case of the conformance corpus.
+type Case struct {
+	Name string // name of the case, derived from the original fixture
+	Src  string // HCL source
+	Err  bool   // whether parsing Src must fail
+	Tree string // expected syntax tree as returned by Tree, if Err is false
+
+	// ErrPos and ErrMsg are the position, i.e. "1:13", and the message of
+	// the *parser.PosError of parsing Src, if Err is true
+	ErrPos string
+	ErrMsg string
+}
+
+// Corpus is the conformance corpus. Every implementation producing the syntax
+// trees of package ast is expected to pass all of its cases.
+var Corpus = []Case{
+	{
+		Name: "array_comment",
+		Src: `foo = [
+    "1",
+    "2", # comment
+]
+`,
+		Tree: `item foo =
+  list
+    literal STRING "1"
+    literal STRING "2"
+`,
+	},
+	{
+		Name: "assign_colon",
+		Src: `resource = [{
+	"foo": {
+		"bar": {},
+		"baz": [1, 2, "foo"],
+	}
+}]
+`,
+		Err:    true,
+		ErrPos: "1:13",
+		ErrMsg: "unexpected token while parsing list: LBRACE",
+	},
+	{
+		Name: "assign_deep",
+		Src: `resource = [{
+	foo = [{
+		bar = {}
+	}]
+}]
+`,
+		Err:    true,
+		ErrPos: "1:13",
+		ErrMsg: "unexpected token while parsing list: LBRACE",
+	},
+	{
+		Name: "comment",
+		Src: `// Foo
+
+/* Bar */
+
+/*
+/*
+Baz
+*/
+
+# Another
+
+# Multiple
+# Lines
+
+foo = "bar"
+`,
+		Tree: `item foo =
+  literal STRING "bar"
+`,
+	},
+	{
+		Name: "comment_single",
+		Src: `# Hello
+`,
+		Tree: ``,
+	},
+	{
+		Name: "complex",
+		Src: `variable "foo" {
+	default = "bar"
+	description = "bar"
+}
+
+provider "aws" {
+	access_key = "foo"
+	secret_key = "bar"
+}
+
+provider "do" {
+	api_key = "${var.foo}"
+}
+
+resource "aws_security_group" "firewall" {
+	count = 5
+}
+
+resource aws_instance "web" {
+	ami = "${var.foo}"
+	security_groups = [
+		"foo", 
+		"${aws_security_group.firewall.foo}",
+	]
+	network_interface = {
+		device_index = 0
+		description = "Main network interface"
+	}
+}
+
+resource "aws_instance" "db" {
+	security_groups = "${aws_security_group.firewall.*.id}"
+	VPC = "foo"
+	depends_on = ["aws_instance.web"]
+}
+
+output "web_ip" {
+	value = "${aws_instance.web.private_ip}"
+}
+`,
+		Tree: `item variable "foo"
+  object
+    item default =
+      literal STRING "bar"
+    item description =
+      literal STRING "bar"
+item provider "aws"
+  object
+    item access_key =
+      literal STRING "foo"
+    item secret_key =
+      literal STRING "bar"
+item provider "do"
+  object
+    item api_key =
+      literal STRING "${var.foo}"
+item resource "aws_security_group" "firewall"
+  object
+    item count =
+      literal NUMBER 5
+item resource aws_instance "web"
+  object
+    item ami =
+      literal STRING "${var.foo}"
+    item security_groups =
+      list
+        literal STRING "foo"
+        literal STRING "${aws_security_group.firewall.foo}"
+    item network_interface =
+      object
+        item device_index =
+          literal NUMBER 0
+        item description =
+          literal STRING "Main network interface"
+item resource "aws_instance" "db"
+  object
+    item security_groups =
+      literal STRING "${aws_security_group.firewall.*.id}"
+    item VPC =
+      literal STRING "foo"
+    item depends_on =
+      list
+        literal STRING "aws_instance.web"
+item output "web_ip"
+  object
+    item value =
+      literal STRING "${aws_instance.web.private_ip}"
+`,
+	},
+	{
+		Name: "complex_key",
+		Src: `foo.bar = "baz"
+`,
+		Err:    true,
+		ErrPos: "1:4",
+		ErrMsg: "expected: IDENT | STRING | ASSIGN | LBRACE got: PERIOD",
+	},
+	{
+		Name: "empty",
+		Src:  ``,
+		Tree: ``,
+	},
+	{
+		Name: "list",
+		Src: `foo = [1, 2, "foo"]
+`,
+		Tree: `item foo =
+  list
+    literal NUMBER 1
+    literal NUMBER 2
+    literal STRING "foo"
+`,
+	},
+	{
+		Name: "list_comma",
+		Src: `foo = [1, 2, "foo",]
+`,
+		Tree: `item foo =
+  list
+    literal NUMBER 1
+    literal NUMBER 2
+    literal STRING "foo"
+`,
+	},
+	{
+		Name: "multiple",
+		Src: `foo = "bar"
+key = 7
+`,
+		Tree: `item foo =
+  literal STRING "bar"
+item key =
+  literal NUMBER 7
+`,
+	},
+	{
+		Name: "old",
+		Src: `default = {
+    "eu-west-1": "ami-b1cf19c6",
+}
+`,
+		Err:    true,
+		ErrPos: "2:16",
+		ErrMsg: "illegal char",
+	},
+	{
+		Name: "structure",
+		Src: `// This is a test structure for the lexer
+foo bar "baz" {
+	key = 7
+	foo = "bar"
+}
+`,
+		Tree: `item foo bar "baz"
+  object
+    item key =
+      literal NUMBER 7
+    item foo =
+      literal STRING "bar"
+`,
+	},
+	{
+		Name: "structure_basic",
+		Src: `foo {
+	value = 7
+	"value" = 8
+	"complex::value" = 9
+}
+`,
+		Tree: `item foo
+  object
+    item value =
+      literal NUMBER 7
+    item "value" =
+      literal NUMBER 8
+    item "complex::value" =
+      literal NUMBER 9
+`,
+	},
+	{
+		Name: "structure_empty",
+		Src: `resource "foo" "bar" {}
+`,
+		Tree: `item resource "foo" "bar"
+  object
+`,
+	},
+	{
+		Name: "types",
+		Src: `foo = "bar"
+bar = 7
+baz = [1,2,3]
+foo = -12
+bar = 3.14159
+foo = true
+bar = false
+`,
+		Tree: `item foo =
+  literal STRING "bar"
+item bar =
+  literal NUMBER 7
+item baz =
+  list
+    literal NUMBER 1
+    literal NUMBER 2
+    literal NUMBER 3
+item foo =
+  literal NUMBER -12
+item bar =
+  literal FLOAT 3.14159
+item foo =
+  literal BOOL true
+item bar =
+  literal BOOL false
+`,
+	},
+}
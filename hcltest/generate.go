@@ -0,0 +1,214 @@
+package hcltest
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"strings"
+)
+
+// Generator generates random HCL documents, i.e. for benchmarks and as seeds
+// of fuzz tests. The documents only depend on the seed and the settings, so
+// benchmarks are reproducible.
+type Generator struct {
+	// MaxDepth is the maximum nesting of blocks and objects, 1 for top level
+	// attributes only. Zero means 4.
+	MaxDepth int
+}
+
+// GenerateFile returns a valid HCL document of at least size bytes generated
+// from seed with the default settings of Generator. See Generator.File.
+func GenerateFile(seed int64, size int) []byte {
+	return (&Generator{}).File(seed, size)
+}
+
+// File returns a valid HCL document of at least size bytes generated from
+// seed, with blocks with and without labels, nested objects, lists, strings
+// with escapes and interpolations, heredocs, numbers, bools and comments.
+// Items are added at the top level until the document has size bytes, so it
+// exceeds size by at most one item.
+func (g *Generator) File(seed int64, size int) []byte {
+	maxDepth := g.MaxDepth
+	if maxDepth <= 0 {
+		maxDepth = 4
+	}
+
+	gen := &generator{rand: rand.New(rand.NewSource(seed)), maxDepth: maxDepth}
+	for gen.buf.Len() < size {
+		gen.item(0)
+	}
+	return gen.buf.Bytes()
+}
+
+var (
+	genKeys = []string{
+		"name", "port", "enabled", "region", "image", "count", "timeout",
+		"tags", "labels", "address", "description", "retries", "weight",
+	}
+	genBlocks = []string{"service", "resource", "provider", "module", "variable", "check"}
+	genWords  = []string{"web", "db", "cache", "eu-west-1", "v2", "primary", "a b", "x.y"}
+)
+
+type generator struct {
+	rand     *rand.Rand
+	maxDepth int
+	buf      bytes.Buffer
+}
+
+// item writes an attribute or a block at depth, followed by an empty line at
+// the top level
+func (g *generator) item(depth int) {
+	indent := strings.Repeat("  ", depth)
+	if g.rand.Intn(5) == 0 {
+		g.comment(indent)
+	}
+
+	if depth+1 < g.maxDepth && g.rand.Intn(3) == 0 {
+		g.block(depth)
+	} else {
+		g.attribute(depth)
+	}
+
+	if depth == 0 {
+		g.buf.WriteString("\n")
+	}
+}
+
+func (g *generator) comment(indent string) {
+	switch g.rand.Intn(3) {
+	case 0:
+		fmt.Fprintf(&g.buf, "%s# %s\n", indent, g.pick(genWords))
+	case 1:
+		fmt.Fprintf(&g.buf, "%s// %s\n", indent, g.pick(genWords))
+	default:
+		fmt.Fprintf(&g.buf, "%s/* %s\n%s   %s */\n", indent, g.pick(genWords), indent, g.pick(genWords))
+	}
+}
+
+func (g *generator) block(depth int) {
+	indent := strings.Repeat("  ", depth)
+	g.buf.WriteString(indent + g.pick(genBlocks))
+	for i, n := 0, g.rand.Intn(3); i < n; i++ {
+		fmt.Fprintf(&g.buf, " %q", g.pick(genWords))
+	}
+
+	n := g.rand.Intn(5)
+	if n == 0 {
+		g.buf.WriteString(" {}\n")
+		return
+	}
+
+	g.buf.WriteString(" {\n")
+	for i := 0; i < n; i++ {
+		g.item(depth + 1)
+	}
+	g.buf.WriteString(indent + "}\n")
+}
+
+func (g *generator) attribute(depth int) {
+	indent := strings.Repeat("  ", depth)
+	fmt.Fprintf(&g.buf, "%s%s = ", indent, g.pick(genKeys))
+
+	heredoc := false
+	switch g.rand.Intn(8) {
+	case 0:
+		g.list()
+	case 1:
+		if depth+1 < g.maxDepth {
+			g.object(depth)
+			break
+		}
+		g.scalar()
+	case 2:
+		g.heredoc()
+		heredoc = true
+	default:
+		g.scalar()
+	}
+
+	// the marker of a heredoc must be on a line of its own
+	if !heredoc && g.rand.Intn(6) == 0 {
+		fmt.Fprintf(&g.buf, " # %s", g.pick(genWords))
+	}
+	g.buf.WriteString("\n")
+}
+
+// object writes an object value, such as { a = 1 }
+func (g *generator) object(depth int) {
+	n := g.rand.Intn(4)
+	if n == 0 {
+		g.buf.WriteString("{}")
+		return
+	}
+
+	g.buf.WriteString("{\n")
+	for i := 0; i < n; i++ {
+		g.attribute(depth + 1)
+	}
+	g.buf.WriteString(strings.Repeat("  ", depth) + "}")
+}
+
+// list writes a list of strings and numbers, the elements accepted by every
+// syntax version
+func (g *generator) list() {
+	n := g.rand.Intn(5)
+	g.buf.WriteString("[")
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			g.buf.WriteString(", ")
+		}
+
+		if g.rand.Intn(2) == 0 {
+			g.number()
+		} else {
+			g.str()
+		}
+	}
+	g.buf.WriteString("]")
+}
+
+func (g *generator) scalar() {
+	switch g.rand.Intn(4) {
+	case 0:
+		g.number()
+	case 1:
+		if g.rand.Intn(2) == 0 {
+			g.buf.WriteString("true")
+		} else {
+			g.buf.WriteString("false")
+		}
+	default:
+		g.str()
+	}
+}
+
+func (g *generator) number() {
+	switch g.rand.Intn(3) {
+	case 0:
+		fmt.Fprintf(&g.buf, "%d", g.rand.Intn(65536))
+	case 1:
+		fmt.Fprintf(&g.buf, "-%d", g.rand.Intn(100))
+	default:
+		fmt.Fprintf(&g.buf, "%d.%d", g.rand.Intn(100), g.rand.Intn(100))
+	}
+}
+
+// str writes a string, which might contain escapes or an interpolation
+func (g *generator) str() {
+	switch g.rand.Intn(5) {
+	case 0:
+		fmt.Fprintf(&g.buf, `"%s-${var.%s}"`, g.pick(genWords), g.pick(genKeys))
+	case 1:
+		fmt.Fprintf(&g.buf, `"%s\t\"%s\"\n"`, g.pick(genWords), g.pick(genWords))
+	default:
+		fmt.Fprintf(&g.buf, "%q", g.pick(genWords))
+	}
+}
+
+func (g *generator) heredoc() {
+	fmt.Fprintf(&g.buf, "<<EOT\n%s\n%s\nEOT", g.pick(genWords), g.pick(genWords))
+}
+
+func (g *generator) pick(list []string) string {
+	return list[g.rand.Intn(len(list))]
+}
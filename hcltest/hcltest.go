@@ -0,0 +1,133 @@
+// Package hcltest provides utilities for testing HCL (HashiCorp Configuration
+// Language) implementations and the projects using them.
+package hcltest
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/fatih/hcl/ast"
+	"github.com/fatih/hcl/parser"
+)
+
+// ParseFunc parses the given source and returns its abstract syntax tree.
+// parser.Parse is a ParseFunc.
+type ParseFunc func(src []byte) (*ast.File, error)
+
+// Check parses every case of the conformance Corpus with parse and returns an
+// error for each case with an unexpected result: a different tree, or for
+// the error cases an error other than a *parser.PosError with the expected
+// position and message.
+func Check(parse ParseFunc) []error {
+	var errs []error
+	for _, c := range Corpus {
+		f, err := parse([]byte(c.Src))
+		if c.Err {
+			if err := checkError(c, err); err != nil {
+				errs = append(errs, err)
+			}
+			continue
+		}
+
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: unexpected parse error: %s", c.Name, err))
+			continue
+		}
+
+		if tree := Tree(f); tree != c.Tree {
+			errs = append(errs, fmt.Errorf("%s: tree mismatch\n\nwant:\n%s\ngot:\n%s", c.Name, c.Tree, tree))
+		}
+	}
+
+	return errs
+}
+
+// checkError returns an error if err isn't the parse error of the error case
+// c, at its position and with its message
+func checkError(c Case, err error) error {
+	if err == nil {
+		return fmt.Errorf("%s: expected a parse error", c.Name)
+	}
+
+	var pe *parser.PosError
+	if !errors.As(err, &pe) {
+		return fmt.Errorf("%s: expected a *parser.PosError, got %T: %s", c.Name, err, err)
+	}
+	if pos, msg := pe.Pos.String(), pe.Err.Error(); pos != c.ErrPos || msg != c.ErrMsg {
+		return fmt.Errorf("%s: error mismatch\n\nwant: %s: %s\ngot:  %s: %s", c.Name, c.ErrPos, c.ErrMsg, pos, msg)
+	}
+	return nil
+}
+
+// Run runs Check as part of a test and reports every failed case.
+func Run(t testing.TB, parse ParseFunc) {
+	for _, err := range Check(parse) {
+		t.Error(err)
+	}
+}
+
+// Tree returns a textual representation of the syntax tree of f, which is
+// independent of positions, comments and formatting. Each node is written on
+// its own line and children are indented by two spaces:
+//
+//	item foo =
+//	  literal STRING "bar"
+//	item service "web"
+//	  object
+//	    item tags =
+//	      list
+//	        literal NUMBER 1
+//
+// Keys and literals are written as they appear in the source.
+func Tree(f *ast.File) string {
+	var buf bytes.Buffer
+	if f != nil && f.Node != nil {
+		tree(&buf, f.Node, 0, true)
+	}
+	return buf.String()
+}
+
+// tree writes the tree of n to buf. If objectAssign is false, the optional
+// assignment of object values isn't written.
+func tree(buf *bytes.Buffer, n ast.Node, depth int, objectAssign bool) {
+	indent := strings.Repeat("  ", depth)
+	switch t := n.(type) {
+	case *ast.ObjectList:
+		for _, item := range t.Items {
+			tree(buf, item, depth, objectAssign)
+		}
+	case *ast.ObjectItem:
+		keys := make([]string, 0, len(t.Keys))
+		for _, k := range t.Keys {
+			keys = append(keys, k.Token.Text)
+		}
+
+		_, object := t.Val.(*ast.ObjectType)
+		assign := ""
+		if t.Assign.IsValid() && (objectAssign || !object) {
+			assign = " ="
+		}
+
+		fmt.Fprintf(buf, "%sitem %s%s\n", indent, strings.Join(keys, " "), assign)
+		if t.Val != nil {
+			tree(buf, t.Val, depth+1, objectAssign)
+		}
+	case *ast.ObjectType:
+		fmt.Fprintf(buf, "%sobject\n", indent)
+		if t.List != nil {
+			tree(buf, t.List, depth+1, objectAssign)
+		}
+	case *ast.ListType:
+		fmt.Fprintf(buf, "%slist\n", indent)
+		for _, l := range t.List {
+			tree(buf, l, depth+1, objectAssign)
+		}
+	case *ast.LiteralType:
+		fmt.Fprintf(buf, "%sliteral %s %s\n", indent, t.Token.Type, t.Token.Text)
+	default:
+		fmt.Fprintf(buf, "%sunknown %T\n", indent, n)
+	}
+}
@@ -0,0 +1,197 @@
+package hcltest
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/fatih/hcl/ast"
+	"github.com/fatih/hcl/parser"
+	"github.com/fatih/hcl/token"
+)
+
+func TestCorpus(t *testing.T) {
+	Run(t, parser.Parse)
+}
+
+func TestCheck(t *testing.T) {
+	// a parser which never fails and returns empty files
+	empty := func(src []byte) (*ast.File, error) {
+		return &ast.File{Node: &ast.ObjectList{}}, nil
+	}
+
+	errs := Check(empty)
+	if len(errs) == 0 {
+		t.Fatal("expected errors for a non conforming parser")
+	}
+
+	failed := 0
+	for _, c := range Corpus {
+		if c.Err || c.Tree != "" {
+			failed++
+		}
+	}
+
+	if len(errs) != failed {
+		t.Errorf("want: %d errors got: %d", failed, len(errs))
+	}
+}
+
+func TestCheckErrors(t *testing.T) {
+	// parsers which fail every case, with other errors than the expected
+	cases := []struct {
+		name  string
+		parse ParseFunc
+	}{
+		{"plain", func(src []byte) (*ast.File, error) {
+			return nil, errors.New("illegal char")
+		}},
+		{"position", func(src []byte) (*ast.File, error) {
+			return nil, &parser.PosError{Pos: token.Pos{Line: 9, Column: 9}, Err: errors.New("illegal char")}
+		}},
+	}
+
+	for _, c := range cases {
+		if errs := Check(c.parse); len(errs) != len(Corpus) {
+			t.Errorf("%s: want: %d errors got: %d", c.name, len(Corpus), len(errs))
+		}
+	}
+}
+
+func TestMustParse(t *testing.T) {
+	f := MustParse(t, `foo = "bar"`)
+	if tree := Tree(f); tree != "item foo =\n  literal STRING \"bar\"\n" {
+		t.Errorf("unexpected tree: %q", tree)
+	}
+}
+
+func TestAssertDecodes(t *testing.T) {
+	decode := func(out interface{}, src string) error {
+		p, ok := out.(*map[string]string)
+		if !ok {
+			return errors.New("unexpected type")
+		}
+
+		*p = map[string]string{"src": src}
+		return nil
+	}
+
+	AssertDecodes(t, decode, "foo", map[string]string{"src": "foo"})
+}
+
+func TestNormalize(t *testing.T) {
+	cases := []struct {
+		a, b string
+	}{
+		{"foo    =   1\n\n\n", "foo = 1"},
+		{"a {\n b = 2\n}", "a = {\n      b = 2 \n}\n"},
+		{"not { valid   \r\n\n", "not { valid"},
+	}
+
+	for _, c := range cases {
+		a, b := Normalize([]byte(c.a)), Normalize([]byte(c.b))
+		if string(a) != string(b) {
+			t.Errorf("want: %q and %q to be equal after normalization, got: %q and %q", c.a, c.b, a, b)
+		}
+	}
+}
+
+func TestDir(t *testing.T) {
+	d := NewDir(t).
+		File("main.hcl", "foo = 1").
+		File("nested/dir/other.hcl", "bar = 2")
+
+	src, err := ioutil.ReadFile(d.Join("nested/dir/other.hcl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(src) != "bar = 2" {
+		t.Errorf("unexpected content: %q", src)
+	}
+
+	AssertGolden(t, d.Join("main.hcl"), []byte("foo    = 1\n"))
+
+	d.Remove()
+	if _, err := os.Stat(d.Path); !os.IsNotExist(err) {
+		t.Errorf("directory should be removed, got: %v", err)
+	}
+}
+
+func TestRunRoundTrip(t *testing.T) {
+	fixtures, err := filepath.Glob(filepath.Join("..", "parser", "test-fixtures", "*.hcl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var sources [][]byte
+	for _, file := range fixtures {
+		src, err := ioutil.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sources = append(sources, src)
+	}
+
+	RunRoundTrip(t, nil, nil, sources...)
+}
+
+func TestCheckRoundTrip(t *testing.T) {
+	if err := CheckPrint([]byte("foo {\n  bar = [1, \"a\"]\n}\n")); err != nil {
+		t.Error(err)
+	}
+
+	decode := func(out interface{}, src string) error {
+		*out.(*interface{}) = src
+		return nil
+	}
+	identity := func(v interface{}) ([]byte, error) {
+		return []byte(v.(string)), nil
+	}
+	truncate := func(v interface{}) ([]byte, error) {
+		return []byte(v.(string)[1:]), nil
+	}
+
+	if err := CheckEncode(decode, identity, []byte("foo")); err != nil {
+		t.Error(err)
+	}
+
+	if err := CheckEncode(decode, truncate, []byte("foo")); err == nil {
+		t.Error("expected an error for an encoder changing the value")
+	}
+}
+
+func TestGenerateFile(t *testing.T) {
+	for seed := int64(0); seed < 20; seed++ {
+		src := GenerateFile(seed, 4096)
+		if len(src) < 4096 {
+			t.Errorf("seed %d: want at least 4096 bytes, got %d", seed, len(src))
+		}
+
+		if !bytes.Equal(src, GenerateFile(seed, 4096)) {
+			t.Errorf("seed %d: different documents for the same seed", seed)
+		}
+
+		MustParse(t, string(src))
+		if err := CheckPrint(src); err != nil {
+			t.Error(err)
+		}
+	}
+
+	if bytes.Equal(GenerateFile(1, 1024), GenerateFile(2, 1024)) {
+		t.Error("same documents for different seeds")
+	}
+}
+
+func TestGeneratorMaxDepth(t *testing.T) {
+	src := (&Generator{MaxDepth: 1}).File(1, 4096)
+	f := MustParse(t, string(src))
+	for _, item := range f.Node.(*ast.ObjectList).Items {
+		if _, ok := item.Val.(*ast.ObjectType); ok {
+			t.Fatalf("nested object at %s with a MaxDepth of 1", item.Pos())
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package hcltest
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/fatih/hcl/ast"
+	"github.com/fatih/hcl/parser"
+	"github.com/fatih/hcl/printer"
+)
+
+// Update controls whether AssertGolden rewrites the golden files instead of
+// comparing against them. Set it from a flag of your test, for example:
+//
+//	func init() { flag.BoolVar(&hcltest.Update, "update", false, "update golden files") }
+var Update = false
+
+// DecodeFunc decodes the given HCL source into out, which is a pointer.
+type DecodeFunc func(out interface{}, src string) error
+
+// MustParse parses src and stops the test if it contains any error.
+func MustParse(t testing.TB, src string) *ast.File {
+	f, err := parser.Parse([]byte(src))
+	if err != nil {
+		t.Fatalf("parse: %s\n\n%s", err, src)
+	}
+	return f
+}
+
+// AssertDecodes decodes src with decode into a new value of the type of want
+// and fails the test if the result is not deeply equal to want.
+func AssertDecodes(t testing.TB, decode DecodeFunc, src string, want interface{}) {
+	out := reflect.New(reflect.TypeOf(want))
+	if err := decode(out.Interface(), src); err != nil {
+		t.Fatalf("decode: %s\n\n%s", err, src)
+	}
+
+	if got := out.Elem().Interface(); !reflect.DeepEqual(got, want) {
+		t.Errorf("decoded value mismatch\n\nwant: %#v\n\ngot: %#v", want, got)
+	}
+}
+
+// AssertGolden compares got against the content of the golden file at path.
+// Both are normalized with Normalize before the comparison, so only semantic
+// or structural differences fail the test. If Update is true the golden file
+// is rewritten with got instead.
+func AssertGolden(t testing.TB, path string, got []byte) {
+	if Update {
+		if err := ioutil.WriteFile(path, got, 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	want, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if w, g := Normalize(want), Normalize(got); !bytes.Equal(w, g) {
+		t.Errorf("%s: golden file mismatch\n\nwant:\n%s\n\ngot:\n%s", path, w, g)
+	}
+}
+
+// Normalize returns the canonical formatting of src. If src can't be parsed
+// only line endings, trailing whitespace and trailing blank lines are
+// normalized.
+func Normalize(src []byte) []byte {
+	if res, err := printer.Format(src); err == nil {
+		src = res
+	}
+
+	src = bytes.Replace(src, []byte("\r\n"), []byte("\n"), -1)
+	lines := bytes.Split(src, []byte("\n"))
+	for i, line := range lines {
+		lines[i] = bytes.TrimRight(line, " \t")
+	}
+
+	return bytes.TrimRight(bytes.Join(lines, []byte("\n")), "\n")
+}
+
+// Dir is a temporary directory of configuration files.
+type Dir struct {
+	Path string // path of the directory
+
+	t testing.TB
+}
+
+// NewDir creates a new temporary directory. Call Remove once it's not needed
+// anymore.
+func NewDir(t testing.TB) *Dir {
+	path, err := ioutil.TempDir("", "hcltest")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &Dir{Path: path, t: t}
+}
+
+// File writes src to the file with the given slash separated name, relative
+// to the directory. Parent directories are created as needed. It returns the
+// directory, so calls can be chained.
+func (d *Dir) File(name, src string) *Dir {
+	path := d.Join(name)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		d.t.Fatal(err)
+	}
+
+	if err := ioutil.WriteFile(path, []byte(src), 0644); err != nil {
+		d.t.Fatal(err)
+	}
+
+	return d
+}
+
+// Join returns the path of the file with the given slash separated name,
+// relative to the directory.
+func (d *Dir) Join(name string) string {
+	return filepath.Join(d.Path, filepath.FromSlash(name))
+}
+
+// Remove removes the directory and all of its files.
+func (d *Dir) Remove() {
+	if err := os.RemoveAll(d.Path); err != nil {
+		d.t.Error(err)
+	}
+}
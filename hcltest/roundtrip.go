@@ -0,0 +1,105 @@
+package hcltest
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/fatih/hcl/ast"
+	"github.com/fatih/hcl/parser"
+	"github.com/fatih/hcl/printer"
+)
+
+// EncodeFunc encodes v, a value decoded into an empty interface, as HCL
+// source.
+type EncodeFunc func(v interface{}) ([]byte, error)
+
+// CheckPrint parses src, formats it with package printer and parses the
+// result again. It returns an error if the formatted source doesn't parse or
+// its syntax tree differs from the one of src, as returned by Tree, except for
+// the assignment of objects, which is optional and added by the printer.
+// Sources which don't parse are ignored.
+func CheckPrint(src []byte) error {
+	f, err := parser.Parse(src)
+	if err != nil {
+		return nil
+	}
+
+	res, err := printer.Format(src)
+	if err != nil {
+		return fmt.Errorf("format: %s\n\n%s", err, src)
+	}
+
+	formatted, err := parser.Parse(res)
+	if err != nil {
+		return fmt.Errorf("parse formatted: %s\n\n%s", err, res)
+	}
+
+	if want, got := shape(f), shape(formatted); want != got {
+		return fmt.Errorf("formatting changed the tree\n\nsource:\n%s\n\nformatted:\n%s\n\nwant:\n%s\ngot:\n%s", src, res, want, got)
+	}
+	return nil
+}
+
+// shape returns the tree of f without the assignments of objects
+func shape(f *ast.File) string {
+	var buf bytes.Buffer
+	if f.Node != nil {
+		tree(&buf, f.Node, 0, false)
+	}
+	return buf.String()
+}
+
+// CheckEncode decodes src into an empty interface with decode, encodes the
+// value with encode and decodes the result again. It returns an error if the
+// encoded source doesn't decode or its value isn't deeply equal to the one
+// of src. Sources which don't decode are ignored.
+func CheckEncode(decode DecodeFunc, encode EncodeFunc, src []byte) error {
+	var want interface{}
+	if err := decode(&want, string(src)); err != nil {
+		return nil
+	}
+
+	res, err := encode(want)
+	if err != nil {
+		return fmt.Errorf("encode: %s\n\n%#v", err, want)
+	}
+
+	var got interface{}
+	if err := decode(&got, string(res)); err != nil {
+		return fmt.Errorf("decode encoded: %s\n\n%s", err, res)
+	}
+
+	if !reflect.DeepEqual(want, got) {
+		return fmt.Errorf("encoding changed the value\n\nsource:\n%s\n\nencoded:\n%s\n\nwant: %#v\n\ngot: %#v", src, res, want, got)
+	}
+	return nil
+}
+
+// RunRoundTrip runs CheckPrint and, if decode and encode aren't nil,
+// CheckEncode for every source of the conformance Corpus and the given
+// sources as part of a test, i.e. the fixtures of a project.
+func RunRoundTrip(t testing.TB, decode DecodeFunc, encode EncodeFunc, sources ...[]byte) {
+	check := func(src []byte) {
+		if err := CheckPrint(src); err != nil {
+			t.Error(err)
+		}
+
+		if decode == nil || encode == nil {
+			return
+		}
+
+		if err := CheckEncode(decode, encode, src); err != nil {
+			t.Error(err)
+		}
+	}
+
+	for _, c := range Corpus {
+		check([]byte(c.Src))
+	}
+
+	for _, src := range sources {
+		check(src)
+	}
+}
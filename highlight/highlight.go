@@ -0,0 +1,215 @@
+// Package highlight classifies the source ranges of HCL (HashiCorp
+// Configuration Language) source text, so editors and viewers can highlight
+// it without implementing their own grammar.
+package highlight
+
+import (
+	"unicode/utf8"
+
+	"github.com/fatih/hcl/ast"
+	"github.com/fatih/hcl/parser"
+	"github.com/fatih/hcl/scanner"
+	"github.com/fatih/hcl/token"
+)
+
+// Class is the highlight class of a source range.
+type Class int
+
+const (
+	Invalid       Class = iota // illegal characters
+	BlockType                  // first key of a block: `resource` "aws_instance" {}
+	Label                      // remaining keys of a block: resource `"aws_instance"` {}
+	AttributeName              // key of an assignment: `foo` = "bar"
+	String                     // "bar"
+	Interpolation              // ${var.foo} or %{ if var.foo }, inside of a string
+	Number                     // 42, 4.2
+	Bool                       // true, false, null
+	Comment                    // # comment
+	Operator                   // = { } [ ] , . + -
+)
+
+var classes = [...]string{
+	Invalid:       "Invalid",
+	BlockType:     "BlockType",
+	Label:         "Label",
+	AttributeName: "AttributeName",
+	String:        "String",
+	Interpolation: "Interpolation",
+	Number:        "Number",
+	Bool:          "Bool",
+	Comment:       "Comment",
+	Operator:      "Operator",
+}
+
+func (c Class) String() string {
+	if 0 <= c && int(c) < len(classes) {
+		return classes[c]
+	}
+	return "Invalid"
+}
+
+// Range is a classified range of the source text, from Pos (inclusive) to End
+// (exclusive).
+type Range struct {
+	Class Class
+	Pos   token.Pos
+	End   token.Pos
+}
+
+// Classify returns the classified ranges of src in source order. Ranges do not
+// overlap, whitespace is not covered by any range. Syntax errors don't stop
+// the classification, keys which can't be associated with an object item are
+// classified as AttributeName.
+func Classify(src []byte) []Range {
+	keys := make(map[int]Class)
+	f, _ := parser.ParseWithRecovery(src)
+	ast.Walk(f, func(n ast.Node) (ast.Node, bool) {
+		item, ok := n.(*ast.ObjectItem)
+		if !ok {
+			return n, true
+		}
+
+		for i, k := range item.Keys {
+			switch {
+			case item.Assign.IsValid():
+				keys[k.Pos().Offset] = AttributeName
+			case i == 0:
+				keys[k.Pos().Offset] = BlockType
+			default:
+				keys[k.Pos().Offset] = Label
+			}
+		}
+		return n, true
+	})
+
+	var ranges []Range
+	s := scanner.New(src)
+	s.Error = func(pos token.Pos, msg string) {} // reported by the parser
+	for {
+		tok := s.Scan()
+		if tok.Type == token.EOF {
+			break
+		}
+
+		var class Class
+		switch tok.Type {
+		case token.IDENT:
+			class = AttributeName
+		case token.STRING, token.HEREDOC:
+			class = String
+		case token.NUMBER, token.FLOAT, token.UNIT:
+			class = Number
+		case token.BOOL, token.NULL:
+			class = Bool
+		case token.COMMENT:
+			class = Comment
+		default:
+			if tok.Type.IsOperator() {
+				class = Operator
+			}
+		}
+
+		if c, ok := keys[tok.Pos.Offset]; ok {
+			class = c
+		}
+
+		if class == String {
+			ranges = append(ranges, stringRanges(tok)...)
+			continue
+		}
+
+		ranges = append(ranges, Range{
+			Class: class,
+			Pos:   tok.Pos,
+			End:   advance(tok.Pos, tok.Text),
+		})
+	}
+
+	return ranges
+}
+
+// stringRanges splits a string token into String and Interpolation ranges.
+// Directives such as "%{ if var.enabled }" are Interpolation ranges as well.
+func stringRanges(tok token.Token) []Range {
+	var ranges []Range
+	text := tok.Text
+	pos := tok.Pos
+
+	add := func(class Class, s string) {
+		if s == "" {
+			return
+		}
+		end := advance(pos, s)
+		ranges = append(ranges, Range{Class: class, Pos: pos, End: end})
+		pos = end
+	}
+
+	start := 0
+	for i := 0; i < len(text); i++ {
+		if text[i] == '\\' {
+			i++ // skip escaped character
+			continue
+		}
+
+		if text[i] != '$' && text[i] != '%' {
+			continue
+		}
+
+		// skip escaped "$${" and "%%{" sequences
+		j := i + 1
+		for j < len(text) && text[j] == text[i] {
+			j++
+		}
+
+		if j >= len(text) || text[j] != '{' {
+			i = j - 1
+			continue
+		}
+
+		if j > i+1 {
+			i = j
+			continue
+		}
+
+		// find the closing brace of the interpolation
+		depth := 0
+		for ; j < len(text); j++ {
+			if text[j] == '{' {
+				depth++
+			} else if text[j] == '}' {
+				depth--
+				if depth == 0 {
+					break
+				}
+			}
+		}
+
+		if j == len(text) {
+			break // not terminated, leave it as a string
+		}
+
+		add(String, text[start:i])
+		add(Interpolation, text[i:j+1])
+		start = j + 1
+		i = j
+	}
+
+	add(String, text[start:])
+	return ranges
+}
+
+// advance returns the position immediately after text starting at pos.
+func advance(pos token.Pos, text string) token.Pos {
+	pos.Offset += len(text)
+	for len(text) > 0 {
+		r, size := utf8.DecodeRuneInString(text)
+		text = text[size:]
+		if r == '\n' {
+			pos.Line++
+			pos.Column = 1
+			continue
+		}
+		pos.Column++
+	}
+	return pos
+}
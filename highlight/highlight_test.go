@@ -0,0 +1,112 @@
+package highlight
+
+import (
+	"testing"
+)
+
+func TestClassify(t *testing.T) {
+	src := `# comment
+resource "aws_instance" web {
+  ami     = "ami-${var.id}-x"
+  count   = 2
+  enabled = true
+  tags    = ["a"]
+}
+`
+	expected := []struct {
+		class Class
+		text  string
+	}{
+		{Comment, "# comment"},
+		{BlockType, "resource"},
+		{Label, `"aws_instance"`},
+		{Label, "web"},
+		{Operator, "{"},
+		{AttributeName, "ami"},
+		{Operator, "="},
+		{String, `"ami-`},
+		{Interpolation, "${var.id}"},
+		{String, `-x"`},
+		{AttributeName, "count"},
+		{Operator, "="},
+		{Number, "2"},
+		{AttributeName, "enabled"},
+		{Operator, "="},
+		{Bool, "true"},
+		{AttributeName, "tags"},
+		{Operator, "="},
+		{Operator, "["},
+		{String, `"a"`},
+		{Operator, "]"},
+		{Operator, "}"},
+	}
+
+	ranges := Classify([]byte(src))
+	if len(ranges) != len(expected) {
+		t.Fatalf("want: %d ranges got: %d: %v", len(expected), len(ranges), ranges)
+	}
+
+	for i, r := range ranges {
+		text := src[r.Pos.Offset:r.End.Offset]
+		if r.Class != expected[i].class || text != expected[i].text {
+			t.Errorf("range %d: want: %s %q got: %s %q", i, expected[i].class, expected[i].text, r.Class, text)
+		}
+	}
+}
+
+func TestClassifyPositions(t *testing.T) {
+	src := "/* multi\nline */ foo = \"bar\""
+
+	ranges := Classify([]byte(src))
+	if len(ranges) != 4 {
+		t.Fatalf("want: 4 ranges got: %d", len(ranges))
+	}
+
+	if end := ranges[0].End; end.Line != 2 || end.Column != 8 {
+		t.Errorf("comment end: want: 2:8 got: %s", end)
+	}
+
+	if pos := ranges[1].Pos; pos.Line != 2 || pos.Column != 9 {
+		t.Errorf("key pos: want: 2:9 got: %s", pos)
+	}
+}
+
+func TestClassifyInvalid(t *testing.T) {
+	// should not stop on syntax errors
+	ranges := Classify([]byte("foo = \nbar { baz = 1 }"))
+	if len(ranges) == 0 {
+		t.Fatal("no ranges returned")
+	}
+
+	if ranges[len(ranges)-1].Class != Operator {
+		t.Errorf("last range should be an operator, got: %s", ranges[len(ranges)-1].Class)
+	}
+}
+
+func TestClassifyEscapes(t *testing.T) {
+	src := `a = "$${x}%{ if y }z%{ endif }"`
+	expected := []struct {
+		class Class
+		text  string
+	}{
+		{AttributeName, "a"},
+		{Operator, "="},
+		{String, `"$${x}`},
+		{Interpolation, "%{ if y }"},
+		{String, "z"},
+		{Interpolation, "%{ endif }"},
+		{String, `"`},
+	}
+
+	ranges := Classify([]byte(src))
+	if len(ranges) != len(expected) {
+		t.Fatalf("want: %d ranges got: %d: %v", len(expected), len(ranges), ranges)
+	}
+
+	for i, r := range ranges {
+		text := src[r.Pos.Offset:r.End.Offset]
+		if r.Class != expected[i].class || text != expected[i].text {
+			t.Errorf("range %d: want: %s %q got: %s %q", i, expected[i].class, expected[i].text, r.Class, text)
+		}
+	}
+}
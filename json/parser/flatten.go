@@ -0,0 +1,97 @@
+package parser
+
+import (
+	"strconv"
+
+	"github.com/fatih/hcl/ast"
+	"github.com/fatih/hcl/token"
+)
+
+// flatten replaces the items of list whose values are non-empty objects of
+// objects with one block per member, whose keys are the keys of the item
+// followed by the name of the member, and the items whose values are
+// non-empty lists of objects with one block per element. The values are
+// flattened recursively, so the tree matches the one of the equivalent HCL.
+func flatten(list *ast.ObjectList) {
+	var items []*ast.ObjectItem
+	for _, item := range list.Items {
+		items = append(items, flattenItem(item)...)
+	}
+	list.Items = items
+}
+
+func flattenItem(item *ast.ObjectItem) []*ast.ObjectItem {
+	var items []*ast.ObjectItem
+	switch v := item.Val.(type) {
+	case *ast.ObjectType:
+		if !objectMembers(v.List.Items) {
+			flatten(v.List)
+			return []*ast.ObjectItem{item}
+		}
+
+		for _, member := range v.List.Items {
+			keys := make([]*ast.ObjectKey, 0, len(item.Keys)+len(member.Keys))
+			keys = append(append(keys, item.Keys...), label(member.Keys[0]))
+			items = append(items, flattenItem(&ast.ObjectItem{Keys: keys, Val: member.Val})...)
+		}
+	case *ast.ListType:
+		if !objectElements(v.List) {
+			flattenElements(v)
+			return []*ast.ObjectItem{item}
+		}
+
+		for _, elem := range v.List {
+			items = append(items, flattenItem(&ast.ObjectItem{Keys: item.Keys, Val: elem})...)
+		}
+	default:
+		items = append(items, item)
+	}
+	return items
+}
+
+// label returns the key k quoted, as the keys following the type of a block
+// are labels
+func label(k *ast.ObjectKey) *ast.ObjectKey {
+	if k.Token.Type != token.IDENT {
+		return k
+	}
+
+	tok := k.Token
+	tok.Type = token.STRING
+	tok.Text = strconv.Quote(tok.Text)
+	return &ast.ObjectKey{Token: tok}
+}
+
+// flattenElements flattens the objects nested in the elements of l
+func flattenElements(l *ast.ListType) {
+	for _, elem := range l.List {
+		switch t := elem.(type) {
+		case *ast.ObjectType:
+			flatten(t.List)
+		case *ast.ListType:
+			flattenElements(t)
+		}
+	}
+}
+
+// objectMembers reports whether items isn't empty and all of its values are
+// objects
+func objectMembers(items []*ast.ObjectItem) bool {
+	for _, item := range items {
+		if _, ok := item.Val.(*ast.ObjectType); !ok {
+			return false
+		}
+	}
+	return len(items) > 0
+}
+
+// objectElements reports whether elems isn't empty and all of them are
+// objects
+func objectElements(elems []ast.Node) bool {
+	for _, elem := range elems {
+		if _, ok := elem.(*ast.ObjectType); !ok {
+			return false
+		}
+	}
+	return len(elems) > 0
+}
@@ -0,0 +1,216 @@
+// Package parser parses JSON into the syntax trees of HCL (HashiCorp
+// Configuration Language), so the decoder, the printer and other tools
+// work the same for both formats.
+//
+// The source must be a JSON object. Its members become items, with their
+// names as keys and the position of ":" as the position of the
+// assignment. Members whose values are objects of objects, or lists of
+// objects, are flattened into blocks, like the equivalent HCL:
+//
+//	{"service": {"web": {"port": 80}}}
+//
+// parses like
+//
+//	service "web" { port = 80 }
+//
+// Strings containing interpolations are parsed as templates, like the HCL
+// parser does.
+package parser
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/fatih/hcl/ast"
+	hclparser "github.com/fatih/hcl/parser"
+	"github.com/fatih/hcl/token"
+)
+
+// Parse parses the JSON object src. Errors are of type
+// *hclparser.PosError, wrapping a *hclparser.ParseError.
+func Parse(src []byte) (*ast.File, error) {
+	p := &parser{sc: newScanner(src)}
+	return p.parse()
+}
+
+// IsJSON reports whether src is JSON rather than HCL, i.e. whether its first
+// character other than white space and a byte order mark is "{", which can't
+// start an HCL file.
+func IsJSON(src []byte) bool {
+	for _, c := range bytes.TrimPrefix(src, bom) {
+		switch c {
+		case ' ', '\t', '\r', '\n':
+			continue
+		case '{':
+			return true
+		}
+		return false
+	}
+	return false
+}
+
+type parser struct {
+	sc  *scanner
+	tok token.Token // last scanned token
+}
+
+func (p *parser) parse() (*ast.File, error) {
+	if err := p.expect(token.LBRACE); err != nil {
+		return nil, err
+	}
+
+	obj, err := p.object()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.tok.Type != token.EOF {
+		return nil, p.unexpected(token.EOF)
+	}
+
+	flatten(obj.List)
+	return &ast.File{Node: obj.List, EndPos: p.tok.Pos}, nil
+}
+
+// object parses the members of an object, after its "{"
+func (p *parser) object() (*ast.ObjectType, error) {
+	o := &ast.ObjectType{Lbrace: p.tok.Pos, List: &ast.ObjectList{}}
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+
+	for p.tok.Type != token.RBRACE {
+		if len(o.List.Items) > 0 {
+			if p.tok.Type != token.COMMA {
+				return nil, p.unexpected(token.COMMA, token.RBRACE)
+			}
+			if err := p.next(); err != nil {
+				return nil, err
+			}
+		}
+
+		if p.tok.Type != token.STRING {
+			return nil, p.unexpected(token.STRING)
+		}
+		item := &ast.ObjectItem{Keys: []*ast.ObjectKey{objectKey(p.tok)}}
+
+		if err := p.expect(token.COLON); err != nil {
+			return nil, err
+		}
+		item.Assign = p.tok.Pos
+
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+
+		var err error
+		if item.Val, err = p.value(); err != nil {
+			return nil, err
+		}
+		o.List.Add(item)
+	}
+
+	o.Rbrace = p.tok.Pos
+	return o, p.next()
+}
+
+// list parses the elements of a list, after its "["
+func (p *parser) list() (*ast.ListType, error) {
+	l := &ast.ListType{Lbrack: p.tok.Pos}
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+
+	for p.tok.Type != token.RBRACK {
+		if len(l.List) > 0 {
+			if p.tok.Type != token.COMMA {
+				return nil, p.unexpected(token.COMMA, token.RBRACK)
+			}
+			if err := p.next(); err != nil {
+				return nil, err
+			}
+		}
+
+		elem, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		l.Add(elem)
+	}
+
+	l.Rbrack = p.tok.Pos
+	return l, p.next()
+}
+
+// value parses the value starting with the current token and scans the
+// token following it
+func (p *parser) value() (ast.Node, error) {
+	switch p.tok.Type {
+	case token.LBRACE:
+		return p.object()
+	case token.LBRACK:
+		return p.list()
+	case token.STRING, token.NUMBER, token.FLOAT, token.BOOL, token.NULL:
+		lit := &ast.LiteralType{Token: p.tok}
+		if text := lit.Token.Text; lit.Token.Type == token.STRING && (strings.Contains(text, "${") || strings.Contains(text, "%{")) {
+			pos := lit.Token.Pos
+			pos.Offset++
+			pos.Column++
+			if t, err := hclparser.ParseTemplate(text[1:len(text)-1], pos); err == nil {
+				lit.Template = t
+			}
+		}
+		return lit, p.next()
+	}
+
+	return nil, p.unexpected(token.STRING, token.NUMBER, token.FLOAT, token.BOOL, token.NULL, token.LBRACE, token.LBRACK)
+}
+
+// objectKey returns the key of a member with the name tok. Names which are
+// identifiers are unquoted, like the keys of HCL items.
+func objectKey(tok token.Token) *ast.ObjectKey {
+	if name, err := strconv.Unquote(tok.Text); err == nil && token.IsIdent(name) {
+		tok.Type = token.IDENT
+		tok.Text = name
+	}
+	return &ast.ObjectKey{Token: tok}
+}
+
+// next scans the next token
+func (p *parser) next() error {
+	var err error
+	p.tok, err = p.sc.scan()
+	return err
+}
+
+// expect scans the next token, which must be of type typ
+func (p *parser) expect(typ token.Type) error {
+	if err := p.next(); err != nil {
+		return err
+	}
+	if p.tok.Type != typ {
+		return p.unexpected(typ)
+	}
+	return nil
+}
+
+// unexpected returns the error for the current token, which isn't of any of
+// the expected types
+func (p *parser) unexpected(expected ...token.Type) error {
+	names := make([]string, len(expected))
+	for i, typ := range expected {
+		names[i] = typ.String()
+	}
+	return syntaxError(p.tok, expected, fmt.Sprintf("expected: %s got: %s", strings.Join(names, " | "), p.tok.Type))
+}
+
+// syntaxError returns the error msg at the token tok, like the errors of the
+// HCL parser
+func syntaxError(tok token.Token, expected []token.Type, msg string) error {
+	return &hclparser.PosError{
+		Pos: tok.Pos,
+		Err: &hclparser.ParseError{Pos: tok.Pos, Token: tok, Expected: expected, Msg: msg},
+	}
+}
@@ -0,0 +1,156 @@
+package parser
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/fatih/hcl/ast"
+	hclparser "github.com/fatih/hcl/parser"
+	"github.com/fatih/hcl/printer"
+	"github.com/fatih/hcl/token"
+)
+
+func TestParse(t *testing.T) {
+	cases := []struct {
+		json string
+		hcl  string
+	}{
+		{`{}`, ``},
+		{`{"a": "b", "c": 1, "d": -1.5e3, "e": true}`, `a = "b" c = 1 d = -1.5e3 e = true`},
+		{`{"a": [1, "x", 2.5]}`, `a = [1, "x", 2.5]`},
+		{`{"tags": {"env": "prod", "team": "web"}}`, `tags = { env = "prod" team = "web" }`},
+		{`{"service": {"web": {"port": 80}}}`, `service "web" { port = 80 }`},
+		{
+			`{"service": {"web": {"port": 80}, "db": {"port": 5432}}}`,
+			`service "web" { port = 80 } service "db" { port = 5432 }`,
+		},
+		{`{"a": {"b": {"c": {"d": 1}}}}`, `a "b" "c" { d = 1 }`},
+		{`{"rule": [{"allow": true}, {"allow": false}]}`, `rule { allow = true } rule { allow = false }`},
+		{`{"service": [{"web": {"port": 80}}]}`, `service "web" { port = 80 }`},
+		{`{"a": {"b": {"c": 1}}}`, `a "b" { c = 1 }`},
+		{`{"empty": {}, "none": []}`, `empty = {} none = []`},
+		{`{"app.io/name": "x", "true": 1}`, `"app.io/name" = "x" "true" = 1`},
+		{`{"a": "\/\u00e9\t"}`, `a = "/é\t"`},
+		{`{"a": "${var.name}"}`, `a = "${var.name}"`},
+		{`{"a": null, "b": [1, null], "null": 1}`, `a = null b = [1, null] null = 1`},
+	}
+
+	for _, c := range cases {
+		f, err := Parse([]byte(c.json))
+		if err != nil {
+			t.Errorf("%s: %s", c.json, err)
+			continue
+		}
+
+		// null is a literal of the experimental syntax only
+		config := &hclparser.DefaultConfig
+		if strings.Contains(c.hcl, "null") {
+			config = &hclparser.Config{Syntax: hclparser.Experimental}
+		}
+		want, err := config.Parse([]byte(c.hcl))
+		if err != nil {
+			t.Fatalf("%s: %s", c.hcl, err)
+		}
+
+		if got, want := format(t, f), format(t, want); got != want {
+			t.Errorf("%s:\nwant:\n%s\ngot:\n%s", c.json, want, got)
+		}
+	}
+}
+
+func format(t *testing.T, f *ast.File) string {
+	var buf bytes.Buffer
+	if err := printer.Fprint(&buf, f); err != nil {
+		t.Fatal(err)
+	}
+	return buf.String()
+}
+
+func TestParsePositions(t *testing.T) {
+	f, err := Parse([]byte("{\n  \"port\": 80,\n  \"name\": \"${var.name}\"\n}\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	items := f.Node.(*ast.ObjectList).Items
+	port, name := items[0], items[1]
+	if port.Keys[0].Token.Type != token.IDENT || port.Keys[0].Token.Text != "port" {
+		t.Errorf("unexpected key: %#v", port.Keys[0].Token)
+	}
+
+	for _, c := range []struct {
+		pos  token.Pos
+		want string
+	}{
+		{port.Pos(), "2:3"},
+		{port.Assign, "2:9"},
+		{port.Val.Pos(), "2:11"},
+		{name.Val.Pos(), "3:11"},
+		{f.EndPos, "5:1"},
+	} {
+		if c.pos.String() != c.want {
+			t.Errorf("want position %s, got %s", c.want, c.pos)
+		}
+	}
+
+	if lit := name.Val.(*ast.LiteralType); lit.Template == nil {
+		t.Error("the template of the string isn't parsed")
+	} else if pos := lit.Template.Parts[0].Pos(); pos.String() != "3:14" {
+		t.Errorf("want template position 3:14, got %s", pos)
+	}
+}
+
+func TestParseError(t *testing.T) {
+	cases := []struct {
+		src string
+		err string
+	}{
+		{``, `At 1:1: expected: LBRACE got: EOF`},
+		{`[1]`, `At 1:1: expected: LBRACE got: LBRACK`},
+		{`{"a": 1`, `At 1:8: expected: COMMA | RBRACE got: EOF`},
+		{`{"a" 1}`, `At 1:6: expected: COLON got: NUMBER`},
+		{`{"a": 1,}`, `At 1:9: expected: STRING got: RBRACE`},
+		{`{a: 1}`, `At 1:2: invalid literal a`},
+		{`{"a": [1 2]}`, `At 1:10: expected: COMMA | RBRACK got: NUMBER`},
+		{`{"a": 1} {}`, `At 1:10: expected: EOF got: LBRACE`},
+		{`{"a": 01}`, `At 1:7: number has a leading zero`},
+		{`{"a": 1.}`, `At 1:7: fraction has no digits`},
+		{`{"a": 1e}`, `At 1:7: exponent has no digits`},
+		{`{"a": -}`, `At 1:7: number has no digits`},
+		{`{"a": "x}`, `At 1:7: literal not terminated`},
+		{`{"a": "\q"}`, `At 1:7: invalid string "\q"`},
+		{"{\n  \"a\": @}", `At 2:8: illegal character '@'`},
+	}
+
+	for _, c := range cases {
+		_, err := Parse([]byte(c.src))
+		if err == nil || err.Error() != c.err {
+			t.Errorf("%q: want: %q got: %v", c.src, c.err, err)
+			continue
+		}
+
+		if _, ok := err.(*hclparser.PosError).Err.(*hclparser.ParseError); !ok {
+			t.Errorf("%q: want a *ParseError, got %T", c.src, err.(*hclparser.PosError).Err)
+		}
+	}
+}
+
+func TestIsJSON(t *testing.T) {
+	cases := []struct {
+		src  string
+		json bool
+	}{
+		{`{}`, true},
+		{"\n\t {\"a\": 1}", true},
+		{`a = 1`, false},
+		{`# {`, false},
+		{``, false},
+	}
+
+	for _, c := range cases {
+		if got := IsJSON([]byte(c.src)); got != c.json {
+			t.Errorf("%q: want %v, got %v", c.src, c.json, got)
+		}
+	}
+}
@@ -0,0 +1,210 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/fatih/hcl/token"
+)
+
+// scanner splits JSON source into the tokens of package token. Strings are
+// quoted like HCL strings, numbers are of type NUMBER or FLOAT.
+type scanner struct {
+	src []byte
+	pos token.Pos // position of the next character
+}
+
+func newScanner(src []byte) *scanner {
+	s := &scanner{src: src, pos: token.Pos{Line: 1, Column: 1}}
+	if bytes.HasPrefix(src, bom) {
+		s.pos.Offset = len(bom) // like the HCL scanner, it takes no column
+	}
+	return s
+}
+
+// bom is the UTF-8 byte order mark
+var bom = []byte("\xef\xbb\xbf")
+
+// scan returns the next token, EOF at the end of the source
+func (s *scanner) scan() (token.Token, error) {
+	s.skipWhitespace()
+
+	tok := token.Token{Pos: s.pos}
+	if s.pos.Offset >= len(s.src) {
+		tok.Type = token.EOF
+		return tok, nil
+	}
+
+	start := s.pos.Offset
+	c := s.src[start]
+	switch {
+	case c == '{', c == '}', c == '[', c == ']', c == ':', c == ',':
+		tok.Type = punctuation[c]
+		s.advance(1)
+	case c == '"':
+		if err := s.scanString(&tok); err != nil {
+			return tok, err
+		}
+		return tok, nil
+	case c == '-' || isDigit(c):
+		if err := s.scanNumber(&tok); err != nil {
+			return tok, err
+		}
+	case isLetter(c):
+		n := 0
+		for start+n < len(s.src) && isLetter(s.src[start+n]) {
+			n++
+		}
+		s.advance(n)
+
+		switch word := string(s.src[start:s.pos.Offset]); word {
+		case "true", "false":
+			tok.Type = token.BOOL
+		case "null":
+			tok.Type = token.NULL
+		default:
+			return tok, s.errorf(tok.Pos, "invalid literal %s", word)
+		}
+	default:
+		r, _ := utf8.DecodeRune(s.src[start:])
+		return tok, s.errorf(tok.Pos, "illegal character %q", r)
+	}
+
+	tok.Text = string(s.src[start:s.pos.Offset])
+	return tok, nil
+}
+
+var punctuation = map[byte]token.Type{
+	'{': token.LBRACE,
+	'}': token.RBRACE,
+	'[': token.LBRACK,
+	']': token.RBRACK,
+	':': token.COLON,
+	',': token.COMMA,
+}
+
+// scanString scans a string. Its text is kept if it means the same as an
+// HCL string, otherwise it's quoted again, i.e. for the escape "\/".
+func (s *scanner) scanString(tok *token.Token) error {
+	tok.Type = token.STRING
+
+	start := s.pos.Offset
+	end := start + 1
+	for {
+		if end >= len(s.src) || s.src[end] == '\n' {
+			return s.errorf(tok.Pos, "literal not terminated")
+		}
+		if s.src[end] == '\\' {
+			end += 2
+			continue
+		}
+		if s.src[end] == '"' {
+			break
+		}
+		end++
+	}
+
+	text := string(s.src[start : end+1])
+	var v string
+	if err := json.Unmarshal([]byte(text), &v); err != nil {
+		return s.errorf(tok.Pos, "invalid string %s", text)
+	}
+
+	if u, err := strconv.Unquote(text); err != nil || u != v {
+		text = strconv.Quote(v)
+	}
+
+	tok.Text = text
+	s.advance(end + 1 - start)
+	return nil
+}
+
+// scanNumber scans a number, which is a FLOAT if it has a fraction or an
+// exponent
+func (s *scanner) scanNumber(tok *token.Token) error {
+	tok.Type = token.NUMBER
+
+	start := s.pos.Offset
+	i := start
+	if s.src[i] == '-' {
+		i++
+	}
+
+	digits := func() int {
+		n := 0
+		for i < len(s.src) && isDigit(s.src[i]) {
+			i++
+			n++
+		}
+		return n
+	}
+
+	switch n := digits(); {
+	case n == 0:
+		return s.errorf(tok.Pos, "number has no digits")
+	case n > 1 && s.src[i-n] == '0':
+		return s.errorf(tok.Pos, "number has a leading zero")
+	}
+
+	if i < len(s.src) && s.src[i] == '.' {
+		tok.Type = token.FLOAT
+		i++
+		if digits() == 0 {
+			return s.errorf(tok.Pos, "fraction has no digits")
+		}
+	}
+
+	if i < len(s.src) && (s.src[i] == 'e' || s.src[i] == 'E') {
+		tok.Type = token.FLOAT
+		i++
+		if i < len(s.src) && (s.src[i] == '+' || s.src[i] == '-') {
+			i++
+		}
+		if digits() == 0 {
+			return s.errorf(tok.Pos, "exponent has no digits")
+		}
+	}
+
+	s.advance(i - start)
+	return nil
+}
+
+func (s *scanner) skipWhitespace() {
+	for s.pos.Offset < len(s.src) {
+		switch s.src[s.pos.Offset] {
+		case ' ', '\t', '\r', '\n':
+			s.advance(1)
+		default:
+			return
+		}
+	}
+}
+
+// advance moves the position n bytes forward, counting lines and characters
+func (s *scanner) advance(n int) {
+	for _, c := range s.src[s.pos.Offset : s.pos.Offset+n] {
+		switch {
+		case c == '\n':
+			s.pos.Line++
+			s.pos.Column = 1
+		case utf8.RuneStart(c):
+			s.pos.Column++
+		}
+	}
+	s.pos.Offset += n
+}
+
+func (s *scanner) errorf(pos token.Pos, format string, args ...interface{}) error {
+	return syntaxError(token.Token{Type: token.ILLEGAL, Pos: pos}, nil, fmt.Sprintf(format, args...))
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+func isLetter(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
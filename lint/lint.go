@@ -0,0 +1,169 @@
+// Package lint checks HCL (HashiCorp Configuration Language) syntax trees
+// with pluggable rules, such as the built-in rules reporting duplicate keys
+// and empty blocks. Rules are registered by name, so third parties can add
+// their own rules to the ones run by Lint and the hcllint command:
+//
+//	func init() {
+//		lint.Register(myRule{})
+//	}
+//
+// An item with the directive "#hcl:disable-rule=name" in its lead or line
+// comment disables the rule for the item, including the items of its block,
+// a comment of the file which doesn't belong to an item for the whole file.
+package lint
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+
+	"github.com/fatih/hcl/ast"
+	"github.com/fatih/hcl/token"
+)
+
+// Issue is a problem reported by a rule.
+type Issue struct {
+	Pos  token.Pos
+	Rule string // name of the rule, set by Lint if the rule leaves it empty
+	Msg  string
+}
+
+func (i Issue) String() string {
+	return fmt.Sprintf("%s: %s (%s)", i.Pos, i.Msg, i.Rule)
+}
+
+// Rule checks a syntax tree, usually a *ast.File, and returns the issues
+// found in it.
+type Rule interface {
+	// Name returns the name of the rule, i.e. "duplicate-key", which
+	// disables it in directives.
+	Name() string
+
+	Check(n ast.Node) []Issue
+}
+
+var (
+	mu    sync.RWMutex
+	rules = make(map[string]Rule)
+)
+
+// Register registers the rule r, which is run by Lint unless rules are given.
+// It panics if a rule with the same name is already registered, or if the
+// name is empty.
+func Register(r Rule) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	name := r.Name()
+	if name == "" {
+		panic("lint: rule without a name")
+	}
+	if _, ok := rules[name]; ok {
+		panic("lint: rule " + name + " registered twice")
+	}
+	rules[name] = r
+}
+
+// Lookup returns the registered rule with the given name, or nil.
+func Lookup(name string) Rule {
+	mu.RLock()
+	defer mu.RUnlock()
+	return rules[name]
+}
+
+// Rules returns the registered rules, sorted by name.
+func Rules() []Rule {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	list := make([]Rule, 0, len(rules))
+	for _, r := range rules {
+		list = append(list, r)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Name() < list[j].Name()
+	})
+	return list
+}
+
+// Lint checks n with the given rules, or with the registered rules if none
+// are given, and returns the issues sorted by their position. Issues of items
+// disabling their rule with a directive are left out.
+func Lint(n ast.Node, rs ...Rule) []Issue {
+	if len(rs) == 0 {
+		rs = Rules()
+	}
+
+	disabled := disabledRanges(n)
+
+	var issues []Issue
+	for _, r := range rs {
+		for _, issue := range r.Check(n) {
+			if issue.Rule == "" {
+				issue.Rule = r.Name()
+			}
+			if !isDisabled(disabled, issue) {
+				issues = append(issues, issue)
+			}
+		}
+	}
+
+	sort.SliceStable(issues, func(i, j int) bool {
+		return issues[i].Pos.Offset < issues[j].Pos.Offset
+	})
+	return issues
+}
+
+// disabledRange is the source range of an item disabling a rule
+type disabledRange struct {
+	rule       string
+	start, end int
+}
+
+// disabledRanges returns the ranges of the items of n disabling rules, and
+// of the whole file for the directives of the file
+func disabledRanges(n ast.Node) []disabledRange {
+	var ranges []disabledRange
+	if f, ok := n.(*ast.File); ok {
+		for _, d := range f.Directives {
+			if d.Name == "disable-rule" {
+				ranges = append(ranges, disabledRange{rule: d.Value, start: 0, end: math.MaxInt32})
+			}
+		}
+	}
+
+	ast.Walk(n, func(n ast.Node) (ast.Node, bool) {
+		item, ok := n.(*ast.ObjectItem)
+		if !ok {
+			return n, true
+		}
+
+		for _, d := range item.Directives {
+			if d.Name != "disable-rule" {
+				continue
+			}
+
+			start := item.Pos().Offset
+			if item.LeadComment != nil {
+				start = item.LeadComment.Pos().Offset
+			}
+			end := item.End().Offset
+			if item.LineComment != nil {
+				end = item.LineComment.End().Offset
+			}
+			ranges = append(ranges, disabledRange{rule: d.Value, start: start, end: end})
+		}
+		return n, true
+	})
+	return ranges
+}
+
+func isDisabled(ranges []disabledRange, issue Issue) bool {
+	for _, r := range ranges {
+		if r.rule == issue.Rule && r.start <= issue.Pos.Offset && issue.Pos.Offset < r.end {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,96 @@
+package lint
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/fatih/hcl/ast"
+	"github.com/fatih/hcl/parser"
+)
+
+// upperRule reports keys with upper case letters
+type upperRule struct{}
+
+func (upperRule) Name() string { return "lower-case" }
+
+func (upperRule) Check(n ast.Node) []Issue {
+	var issues []Issue
+	ast.Walk(n, func(n ast.Node) (ast.Node, bool) {
+		if k, ok := n.(*ast.ObjectKey); ok && strings.ToLower(k.Token.Text) != k.Token.Text {
+			issues = append(issues, Issue{Pos: k.Pos(), Msg: "key " + k.Token.Text + " isn't lower case"})
+		}
+		return n, true
+	})
+	return issues
+}
+
+func TestRegister(t *testing.T) {
+	Register(upperRule{})
+	defer func() {
+		mu.Lock()
+		delete(rules, "lower-case")
+		mu.Unlock()
+	}()
+
+	if Lookup("lower-case") == nil {
+		t.Fatal("rule lower-case isn't registered")
+	}
+
+	var names []string
+	for _, r := range Rules() {
+		names = append(names, r.Name())
+	}
+	expected := "block-assignment duplicate-block duplicate-key empty-block lower-case"
+	if got := strings.Join(names, " "); got != expected {
+		t.Errorf("want rules %s, got %s", expected, got)
+	}
+
+	f, err := parser.Parse([]byte("Name = 1\nname = 2\nname = 3"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	issues := Lint(f)
+	if got := fmt.Sprint(issues); got != "[1:1: key Name isn't lower case (lower-case) 3:1: duplicate key name, first defined at 2:1 (duplicate-key)]" {
+		t.Errorf("unexpected issues %s", got)
+	}
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("expected a panic registering a rule twice")
+			}
+		}()
+		Register(upperRule{})
+	}()
+}
+
+func TestDisableRule(t *testing.T) {
+	src := `a = 1
+a = 2 #hcl:disable-rule=duplicate-key
+
+#hcl:disable-rule=empty-block
+service {
+  nested {}
+}
+
+other {}
+`
+	f, err := parser.Parse([]byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	issues := Lint(f)
+	if got := fmt.Sprint(issues); got != "[9:1: empty block other (empty-block)]" {
+		t.Errorf("unexpected issues %s", got)
+	}
+
+	f, err = parser.Parse([]byte("#hcl:disable-rule=empty-block\n\na {}\nb {}"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if issues := Lint(f); len(issues) != 0 {
+		t.Errorf("unexpected issues %s", issues)
+	}
+}
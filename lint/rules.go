@@ -0,0 +1,139 @@
+package lint
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fatih/hcl/ast"
+)
+
+func init() {
+	Register(listRule{"duplicate-key", duplicateKeys})
+	Register(listRule{"empty-block", emptyBlocks})
+	Register(listRule{"block-assignment", blockAssignments})
+	Register(listRule{"duplicate-block", duplicateBlocks})
+}
+
+// listRule is a rule checking each object list of a tree on its own
+type listRule struct {
+	name  string
+	check func(list *ast.ObjectList) []Issue
+}
+
+func (r listRule) Name() string { return r.name }
+
+func (r listRule) Check(n ast.Node) []Issue {
+	var issues []Issue
+	ast.Walk(n, func(n ast.Node) (ast.Node, bool) {
+		if list, ok := n.(*ast.ObjectList); ok {
+			issues = append(issues, r.check(list)...)
+		}
+		return n, true
+	})
+	return issues
+}
+
+// duplicateKeys reports attributes assigned more than once, whose last
+// value silently wins
+func duplicateKeys(list *ast.ObjectList) []Issue {
+	var issues []Issue
+	first := make(map[string]*ast.ObjectItem)
+	for _, item := range list.Items {
+		if !isAttribute(item) {
+			continue
+		}
+
+		key := keyValue(item)
+		if f, ok := first[key]; ok {
+			issues = append(issues, Issue{
+				Pos: item.Pos(),
+				Msg: fmt.Sprintf("duplicate key %s, first defined at %s", keyText(item), f.Pos()),
+			})
+			continue
+		}
+		first[key] = item
+	}
+	return issues
+}
+
+// emptyBlocks reports blocks without items or comments
+func emptyBlocks(list *ast.ObjectList) []Issue {
+	var issues []Issue
+	for _, item := range list.Items {
+		obj, ok := item.Val.(*ast.ObjectType)
+		if !ok || item.Assign.IsValid() || obj.TrailComment != nil {
+			continue
+		}
+
+		if obj.List == nil || len(obj.List.Items) == 0 {
+			issues = append(issues, Issue{
+				Pos: item.Pos(),
+				Msg: fmt.Sprintf("empty block %s", keyText(item)),
+			})
+		}
+	}
+	return issues
+}
+
+// blockAssignments reports blocks written as assignments of objects, the
+// deprecated block syntax of HCL 1
+func blockAssignments(list *ast.ObjectList) []Issue {
+	var issues []Issue
+	for _, item := range list.Items {
+		if _, ok := item.Val.(*ast.ObjectType); ok && item.Assign.IsValid() {
+			key := keyText(item)
+			issues = append(issues, Issue{
+				Pos: item.Assign,
+				Msg: fmt.Sprintf("deprecated block assignment, write %s { ... } instead of %s = { ... }", key, key),
+			})
+		}
+	}
+	return issues
+}
+
+// duplicateBlocks reports labeled blocks defined more than once, which are
+// merged into one, with the later attributes overriding the earlier ones
+func duplicateBlocks(list *ast.ObjectList) []Issue {
+	var issues []Issue
+	first := make(map[string]*ast.ObjectItem)
+	for _, item := range list.Items {
+		if _, ok := item.Val.(*ast.ObjectType); !ok || item.Assign.IsValid() || len(item.Keys) < 2 {
+			continue
+		}
+
+		key := keyValue(item)
+		if f, ok := first[key]; ok {
+			issues = append(issues, Issue{
+				Pos: item.Pos(),
+				Msg: fmt.Sprintf("duplicate block %s, first defined at %s", keyText(item), f.Pos()),
+			})
+			continue
+		}
+		first[key] = item
+	}
+	return issues
+}
+
+// isAttribute reports whether item assigns a value other than an object
+func isAttribute(item *ast.ObjectItem) bool {
+	_, ok := item.Val.(*ast.ObjectType)
+	return item.Assign.IsValid() && !ok
+}
+
+// keyValue returns the unquoted keys of item, i.e. to compare them
+func keyValue(item *ast.ObjectItem) string {
+	keys := make([]string, len(item.Keys))
+	for i, k := range item.Keys {
+		keys[i] = k.Token.Value()
+	}
+	return ast.JoinPath(keys)
+}
+
+// keyText returns the keys of item as written, i.e. service "web"
+func keyText(item *ast.ObjectItem) string {
+	keys := make([]string, len(item.Keys))
+	for i, k := range item.Keys {
+		keys[i] = k.Token.Text
+	}
+	return strings.Join(keys, " ")
+}
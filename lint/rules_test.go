@@ -0,0 +1,71 @@
+package lint
+
+import (
+	"testing"
+
+	"github.com/fatih/hcl/parser"
+)
+
+func TestRules(t *testing.T) {
+	cases := []struct {
+		rule   string
+		src    string
+		issues []string
+	}{
+		{
+			"duplicate-key",
+			"a = 1\nb = 2\na = 3\nservice {\n  b = 1\n  b = 2\n}",
+			[]string{
+				"3:1: duplicate key a, first defined at 1:1 (duplicate-key)",
+				"6:3: duplicate key b, first defined at 5:3 (duplicate-key)",
+			},
+		},
+		{
+			"duplicate-key",
+			"a = 1\n\"a\" = 2\nb {}\nb {}",
+			[]string{
+				`2:1: duplicate key "a", first defined at 1:1 (duplicate-key)`,
+			},
+		},
+		{
+			"empty-block",
+			"a {}\nb \"x\" {\n  # todo\n}\nc {\n  d = 1\n}\ne = {}",
+			[]string{
+				"1:1: empty block a (empty-block)",
+			},
+		},
+		{
+			"block-assignment",
+			"service = {\n  port = 80\n}\nservice {\n  port = 81\n}",
+			[]string{
+				"1:9: deprecated block assignment, write service { ... } instead of service = { ... } (block-assignment)",
+			},
+		},
+		{
+			"duplicate-block",
+			"service \"web\" {\n}\nservice \"db\" {\n}\nservice \"web\" {\n}\nstage {}\nstage {}",
+			[]string{
+				`5:1: duplicate block service "web", first defined at 1:1 (duplicate-block)`,
+			},
+		},
+	}
+
+	for _, c := range cases {
+		f, err := parser.Parse([]byte(c.src))
+		if err != nil {
+			t.Errorf("%s: %s", c.rule, err)
+			continue
+		}
+
+		issues := Lint(f, Lookup(c.rule))
+		if len(issues) != len(c.issues) {
+			t.Errorf("%s: want %d issues, got %v", c.rule, len(c.issues), issues)
+			continue
+		}
+		for i, issue := range issues {
+			if issue.String() != c.issues[i] {
+				t.Errorf("%s:\nwant: %s\ngot:  %s", c.rule, c.issues[i], issue)
+			}
+		}
+	}
+}
@@ -0,0 +1,299 @@
+// Package loader loads HCL (HashiCorp Configuration Language) files composed
+// of other files, i.e. to split large configurations across files.
+//
+// A file includes other files with top-level include attributes, whose value
+// is a path or glob pattern, or a list of them, relative to the directory of
+// the file:
+//
+//	include = "common.hcl"
+//	include = ["services/*.hcl", "/etc/app/local.hcl"]
+//
+// The included files are merged in the order of the patterns, the files
+// matching a glob in lexical order, and the including file is merged on top
+// of them, so it overrides what it includes.
+//
+// LoadDir loads all files of a directory. Like the override files of
+// Terraform, files named override.hcl or ending in _override.hcl are merged
+// on top of all other files, i.e. to change shared files locally:
+//
+//	# main.hcl
+//	service "web" {
+//	  port  = 80
+//	  image = "web:1"
+//	}
+//
+//	# local_override.hcl, only changing the port
+//	service "web" {
+//	  port = 8080
+//	}
+package loader
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/fatih/hcl/ast"
+	"github.com/fatih/hcl/merge"
+	"github.com/fatih/hcl/parser"
+	"github.com/fatih/hcl/token"
+)
+
+// DefaultConfig parses files with parser.DefaultConfig and merges them with
+// merge.DefaultConfig.
+var DefaultConfig = Config{}
+
+// A Config controls how Load parses and merges files.
+type Config struct {
+	// Parser parses the files, parser.DefaultConfig if nil.
+	Parser *parser.Config
+
+	// Merge merges the files, merge.DefaultConfig if nil.
+	Merge *merge.Config
+
+	// MaxDepth limits the nesting of includes: a file loaded directly is at
+	// depth 0, the files it includes at depth 1 and so on. Including a file
+	// deeper is an error listing the include chain. Zero means no limit.
+	MaxDepth int
+}
+
+// Load loads the file filename with all files it includes, directly or
+// indirectly, merged into a single syntax tree without the include
+// attributes, along with the provenance of its items. The names of the files
+// in the provenance and in errors are joined to the directory of the file
+// including them.
+//
+// A file included more than once is merged at its first include only.
+// Including a file which includes the file again, a cycle, is an error of
+// type *parser.PosError at the position of the include, as are include
+// attributes which aren't strings or lists of strings, paths without glob
+// patterns matching no file and includes nested deeper than MaxDepth. The
+// positions of parse errors contain the name of the file.
+func (c *Config) Load(filename string) (*ast.File, *merge.Provenance, error) {
+	l := &loader{config: c, loaded: make(map[string]string)}
+	if err := l.load(filename, nil); err != nil {
+		return nil, nil, err
+	}
+	return l.merge()
+}
+
+// Load loads the file filename and the files it includes with DefaultConfig.
+func Load(filename string) (*ast.File, *merge.Provenance, error) {
+	return DefaultConfig.Load(filename)
+}
+
+// LoadDir loads the files of the directory dir with the extension .hcl, and
+// the files they include, like Load. The files are merged in lexical order,
+// followed by the override files, see IsOverride, so their items replace the
+// ones of the other files attribute by attribute with the default merge.
+// The provenance records which file, override or not, defines an item.
+func (c *Config) LoadDir(dir string) (*ast.File, *merge.Provenance, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.hcl"))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var overrides []string
+	l := &loader{config: c, loaded: make(map[string]string)}
+	for _, path := range paths {
+		if IsOverride(path) {
+			overrides = append(overrides, path)
+			continue
+		}
+		if err := l.load(path, nil); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	for _, path := range overrides {
+		if err := l.load(path, nil); err != nil {
+			return nil, nil, err
+		}
+	}
+	return l.merge()
+}
+
+// LoadDir loads the files of the directory dir with DefaultConfig.
+func LoadDir(dir string) (*ast.File, *merge.Provenance, error) {
+	return DefaultConfig.LoadDir(dir)
+}
+
+// IsOverride reports whether the file filename is an override file, named
+// override.hcl or ending in _override.hcl.
+func IsOverride(filename string) bool {
+	base := filepath.Base(filename)
+	return base == "override.hcl" || strings.HasSuffix(base, "_override.hcl")
+}
+
+type loader struct {
+	config *Config
+
+	// files are the loaded files in the order they're merged
+	files []merge.File
+
+	// loaded are the names of the loaded files by their absolute paths,
+	// stack the absolute paths of the files being loaded, each including the
+	// next
+	loaded map[string]string
+	stack  []string
+}
+
+// merge merges the loaded files
+func (l *loader) merge() (*ast.File, *merge.Provenance, error) {
+	mc := l.config.Merge
+	if mc == nil {
+		mc = &merge.DefaultConfig
+	}
+	return mc.MergeFiles(l.files...)
+}
+
+// load adds the files included by filename, followed by filename itself, to
+// the files to merge. from is the position of the include, if any.
+func (l *loader) load(filename string, from *token.Pos) error {
+	abs, err := filepath.Abs(filename)
+	if err != nil {
+		return err
+	}
+
+	for i, s := range l.stack {
+		if s == abs {
+			return &parser.PosError{Pos: *from, Err: fmt.Errorf("include cycle: %s", l.chain(i, filename))}
+		}
+	}
+	if _, ok := l.loaded[abs]; ok {
+		return nil
+	}
+	if max := l.config.MaxDepth; max > 0 && len(l.stack) > max {
+		return &parser.PosError{Pos: *from, Err: fmt.Errorf("include depth exceeds %d: %s", max, l.chain(0, filename))}
+	}
+	l.loaded[abs] = filename
+
+	src, err := ioutil.ReadFile(filename)
+	if err != nil {
+		if from != nil {
+			return &parser.PosError{Pos: *from, Err: err}
+		}
+		return err
+	}
+
+	pc := l.config.Parser
+	if pc == nil {
+		pc = &parser.DefaultConfig
+	}
+
+	f, err := pc.Parse(src)
+	if err != nil {
+		if e, ok := err.(*parser.PosError); ok {
+			pe := *e
+			pe.Pos.Filename = filename
+			return &pe
+		}
+		return err
+	}
+
+	l.stack = append(l.stack, abs)
+	defer func() { l.stack = l.stack[:len(l.stack)-1] }()
+
+	list, ok := f.Node.(*ast.ObjectList)
+	if !ok {
+		return fmt.Errorf("%s: expected an object list, got: %T", filename, f.Node)
+	}
+
+	rest := &ast.ObjectList{}
+	for _, item := range list.Items {
+		if !isInclude(item) {
+			rest.Add(item)
+			continue
+		}
+
+		if err := l.include(filename, item); err != nil {
+			return err
+		}
+	}
+
+	l.files = append(l.files, merge.File{Name: filename, File: &ast.File{Node: rest, Comments: f.Comments}})
+	return nil
+}
+
+// chain returns the names of the files of the stack from i on, followed by
+// filename, each including the next
+func (l *loader) chain(i int, filename string) string {
+	var names []string
+	for _, s := range l.stack[i:] {
+		names = append(names, l.loaded[s])
+	}
+	names = append(names, filename)
+	return strings.Join(names, " -> ")
+}
+
+// include loads the files matching the patterns of the include attribute
+// item of the file filename
+func (l *loader) include(filename string, item *ast.ObjectItem) error {
+	var lits []*ast.LiteralType
+	switch v := item.Val.(type) {
+	case *ast.LiteralType:
+		lits = append(lits, v)
+	case *ast.ListType:
+		for _, elem := range v.List {
+			lit, ok := elem.(*ast.LiteralType)
+			if !ok {
+				return l.errorf(filename, elem.Pos(), "include must be a string or a list of strings")
+			}
+			lits = append(lits, lit)
+		}
+	default:
+		return l.errorf(filename, item.Val.Pos(), "include must be a string or a list of strings")
+	}
+
+	for _, lit := range lits {
+		if lit.Token.Type != token.STRING {
+			return l.errorf(filename, lit.Pos(), "include must be a string or a list of strings")
+		}
+
+		pattern, err := strconv.Unquote(lit.Token.Text)
+		if err != nil {
+			return l.errorf(filename, lit.Pos(), "invalid include %s", lit.Token.Text)
+		}
+		if !filepath.IsAbs(pattern) {
+			pattern = filepath.Join(filepath.Dir(filename), pattern)
+		}
+
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return l.errorf(filename, lit.Pos(), "invalid include %s: %s", lit.Token.Text, err)
+		}
+
+		// a path without a pattern must exist, the error of reading it
+		// says why not
+		if len(matches) == 0 && !hasMeta(pattern) {
+			matches = []string{pattern}
+		}
+
+		for _, match := range matches {
+			pos := lit.Pos()
+			pos.Filename = filename
+			if err := l.load(match, &pos); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (l *loader) errorf(filename string, pos token.Pos, format string, args ...interface{}) error {
+	pos.Filename = filename
+	return &parser.PosError{Pos: pos, Err: fmt.Errorf(format, args...)}
+}
+
+// isInclude reports whether item is an include attribute
+func isInclude(item *ast.ObjectItem) bool {
+	return len(item.Keys) == 1 && item.Assign.IsValid() && item.Keys[0].Token.Text == "include"
+}
+
+// hasMeta reports whether path contains any of the special characters of
+// filepath.Match
+func hasMeta(path string) bool {
+	return strings.ContainsAny(path, "*?[")
+}
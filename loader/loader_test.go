@@ -0,0 +1,248 @@
+package loader
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/fatih/hcl/printer"
+)
+
+// writeFiles writes the files to a temporary directory and returns it
+func writeFiles(t *testing.T, files map[string]string) string {
+	dir, err := ioutil.TempDir("", "loader")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, src := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestLoad(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"main.hcl": `include = ["common.hcl", "services/*.hcl"]
+region = "eu-west-1"
+
+service "web" {
+  port = 8080
+}
+`,
+		"common.hcl": `region = "us-east-1"
+timeout = 30
+`,
+		"services/web.hcl": `include = "../common.hcl"
+
+service "web" {
+  port = 80
+  image = "web:1"
+}
+`,
+		"services/db.hcl": `service "db" {
+  port = 5432
+}
+`,
+	})
+	defer os.RemoveAll(dir)
+
+	main := filepath.Join(dir, "main.hcl")
+	f, prov, err := Load(main)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := printer.Fprint(&buf, f); err != nil {
+		t.Fatal(err)
+	}
+	res, err := printer.Format(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `region = "eu-west-1"
+
+timeout = 30
+
+service "db" {
+  port = 5432
+}
+
+service "web" {
+  port  = 8080
+  image = "web:1"
+}
+`
+	if strings.TrimSpace(string(res)) != strings.TrimSpace(expected) {
+		t.Errorf("want:\n%s\ngot:\n%s", expected, res)
+	}
+
+	var origins []string
+	for _, pos := range prov.Explain("service.web.port") {
+		origins = append(origins, filepath.Base(pos.Filename))
+	}
+	if want := []string{"main.hcl", "web.hcl"}; !reflect.DeepEqual(origins, want) {
+		t.Errorf("want origins %q, got %q", want, origins)
+	}
+}
+
+func TestLoadDir(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"main.hcl": `include = "shared/common.hcl"
+
+service "web" {
+  port  = 80
+  image = "web:1"
+}
+`,
+		"shared/common.hcl": `region = "us-east-1"
+`,
+		"services.hcl": `service "db" {
+  port = 5432
+}
+`,
+		"a_override.hcl": `service "web" {
+  port = 8080
+}
+`,
+		"override.hcl": `region = "eu-west-1"
+`,
+		"notes.txt": `not = "loaded"`,
+	})
+	defer os.RemoveAll(dir)
+
+	f, prov, err := LoadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := printer.Fprint(&buf, f); err != nil {
+		t.Fatal(err)
+	}
+	res, err := printer.Format(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `region = "eu-west-1"
+
+service "web" {
+  port  = 8080
+  image = "web:1"
+}
+
+service "db" {
+  port = 5432
+}
+`
+	if strings.TrimSpace(string(res)) != strings.TrimSpace(expected) {
+		t.Errorf("want:\n%s\ngot:\n%s", expected, res)
+	}
+
+	for path, want := range map[string][]string{
+		"service.web.port":  {"a_override.hcl", "main.hcl"},
+		"service.web.image": {"main.hcl"},
+		"region":            {"override.hcl", "common.hcl"},
+	} {
+		var origins []string
+		for _, pos := range prov.Explain(path) {
+			origins = append(origins, filepath.Base(pos.Filename))
+		}
+		if !reflect.DeepEqual(origins, want) {
+			t.Errorf("%s: want origins %q, got %q", path, want, origins)
+		}
+	}
+
+	for name, want := range map[string]bool{
+		"override.hcl":           true,
+		"dev/local_override.hcl": true,
+		"overrides.hcl":          false,
+		"myoverride.hcl":         false,
+	} {
+		if got := IsOverride(name); got != want {
+			t.Errorf("IsOverride(%q) = %t, want %t", name, got, want)
+		}
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"a.hcl":       `include = "b.hcl"`,
+		"b.hcl":       "x = 1\ninclude = \"a.hcl\"",
+		"self.hcl":    `include = ["self.hcl"]`,
+		"missing.hcl": `include = "nope.hcl"`,
+		"glob.hcl":    `include = "none/*.hcl"`,
+		"number.hcl":  `include = 1`,
+		"broken.hcl":  `include = "syntax.hcl"`,
+		"syntax.hcl":  `a = [1 2}`,
+	})
+	defer os.RemoveAll(dir)
+
+	cases := []struct {
+		file string
+		err  string // without the directory
+	}{
+		{"a.hcl", "At b.hcl:2:11: include cycle: a.hcl -> b.hcl -> a.hcl"},
+		{"self.hcl", "At self.hcl:1:12: include cycle: self.hcl -> self.hcl"},
+		{"missing.hcl", "At missing.hcl:1:11: open nope.hcl: no such file or directory"},
+		{"glob.hcl", ""},
+		{"number.hcl", "At number.hcl:1:11: include must be a string or a list of strings"},
+		{"broken.hcl", "At syntax.hcl:1:9: list opened at 1:5 not terminated, expected: ] got: RBRACE"},
+	}
+
+	for _, c := range cases {
+		_, _, err := Load(filepath.Join(dir, c.file))
+		got := ""
+		if err != nil {
+			got = strings.Replace(err.Error(), dir+string(filepath.Separator), "", -1)
+		}
+		if got != c.err {
+			t.Errorf("%s: want error %q, got %q", c.file, c.err, got)
+		}
+	}
+}
+
+func TestLoadMaxDepth(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"a.hcl": `include = "b.hcl"`,
+		"b.hcl": `include = "c.hcl"`,
+		"c.hcl": `include = "d.hcl"`,
+		"d.hcl": `x = 1`,
+	})
+	defer os.RemoveAll(dir)
+
+	cases := []struct {
+		max int
+		err string // without the directory
+	}{
+		{0, ""},
+		{3, ""},
+		{2, "At c.hcl:1:11: include depth exceeds 2: a.hcl -> b.hcl -> c.hcl -> d.hcl"},
+		{1, "At b.hcl:1:11: include depth exceeds 1: a.hcl -> b.hcl -> c.hcl"},
+	}
+
+	for _, c := range cases {
+		cfg := Config{MaxDepth: c.max}
+		_, _, err := cfg.Load(filepath.Join(dir, "a.hcl"))
+		got := ""
+		if err != nil {
+			got = strings.Replace(err.Error(), dir+string(filepath.Separator), "", -1)
+		}
+		if got != c.err {
+			t.Errorf("MaxDepth %d: want error %q, got %q", c.max, c.err, got)
+		}
+	}
+}
@@ -0,0 +1,223 @@
+// Package lsp provides the building blocks to implement a language server for
+// HCL (HashiCorp Configuration Language). Syntax trees with errors can be
+// obtained with parser.ParseWithRecovery.
+package lsp
+
+import (
+	"bytes"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/fatih/hcl/ast"
+	"github.com/fatih/hcl/printer"
+	"github.com/fatih/hcl/token"
+)
+
+// NodeAt returns the path of nodes enclosing the given position, starting
+// with node itself and ending with the innermost node. Only the Offset of pos
+// is used. It returns nil if pos is not inside node.
+func NodeAt(node ast.Node, pos token.Pos) []ast.Node {
+	var path []ast.Node
+	ast.Walk(node, func(n ast.Node) (ast.Node, bool) {
+		if n == nil {
+			return n, false
+		}
+
+		if _, ok := n.(*ast.File); !ok {
+			if pos.Offset < n.Pos().Offset || pos.Offset >= ast.End(n).Offset {
+				return n, false
+			}
+		}
+
+		path = append(path, n)
+		return n, true
+	})
+
+	return path
+}
+
+// SymbolKind specifies the kind of a Symbol.
+type SymbolKind int
+
+const (
+	Attribute SymbolKind = iota // foo = "bar"
+	Block                       // foo "bar" { ... }
+)
+
+func (k SymbolKind) String() string {
+	if k == Block {
+		return "Block"
+	}
+	return "Attribute"
+}
+
+// Symbol describes a single object item of a document.
+type Symbol struct {
+	Name     string // the keys of the item, separated by a space
+	Kind     SymbolKind
+	Pos      token.Pos // position of the first key
+	End      token.Pos // position immediately after the value
+	Children []Symbol  // items of a nested object, if any
+}
+
+// Symbols returns the symbols of all object items of the given file, nested
+// objects are returned as children of their parent item.
+func Symbols(f *ast.File) []Symbol {
+	list, ok := f.Node.(*ast.ObjectList)
+	if !ok {
+		return nil
+	}
+	return symbols(list)
+}
+
+func symbols(list *ast.ObjectList) []Symbol {
+	var syms []Symbol
+	for _, item := range list.Items {
+		keys := make([]string, 0, len(item.Keys))
+		for _, k := range item.Keys {
+			keys = append(keys, k.Token.Text)
+		}
+
+		sym := Symbol{
+			Name: strings.Join(keys, " "),
+			Kind: Attribute,
+			Pos:  item.Pos(),
+			End:  item.End(),
+		}
+
+		if !item.Assign.IsValid() {
+			sym.Kind = Block
+		}
+
+		if obj, ok := item.Val.(*ast.ObjectType); ok && obj.List != nil {
+			sym.Children = symbols(obj.List)
+		}
+
+		syms = append(syms, sym)
+	}
+
+	return syms
+}
+
+// TextEdit describes the replacement of the source text between Pos
+// (inclusive) and End (exclusive) with NewText.
+type TextEdit struct {
+	Pos     token.Pos
+	End     token.Pos
+	NewText string
+}
+
+// FormatEdits formats src and returns the edits which are needed to turn src
+// into its formatted version. It returns no edits if src is already
+// formatted. Only whole lines are replaced.
+func FormatEdits(src []byte) ([]TextEdit, error) {
+	res, err := printer.Format(src)
+	if err != nil {
+		return nil, err
+	}
+
+	if bytes.Equal(src, res) {
+		return nil, nil
+	}
+
+	a := bytes.SplitAfter(src, []byte{'\n'})
+	b := bytes.SplitAfter(res, []byte{'\n'})
+
+	// skip the lines which are the same at the beginning and the end
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && bytes.Equal(a[prefix], b[prefix]) {
+		prefix++
+	}
+
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix &&
+		bytes.Equal(a[len(a)-1-suffix], b[len(b)-1-suffix]) {
+		suffix++
+	}
+
+	edit := TextEdit{
+		Pos:     linePos(a, prefix),
+		End:     linePos(a, len(a)-suffix),
+		NewText: string(bytes.Join(b[prefix:len(b)-suffix], nil)),
+	}
+
+	return []TextEdit{edit}, nil
+}
+
+// linePos returns the position of the beginning of the given line, where
+// lines are the lines of a source text including their newlines.
+func linePos(lines [][]byte, line int) token.Pos {
+	pos := token.Pos{Line: line + 1, Column: 1}
+	for _, l := range lines[:line] {
+		pos.Offset += len(l)
+	}
+	return pos
+}
+
+// Position is a position of a document as the language server protocol
+// describes it: Line and Character start at 0, and Character counts the
+// UTF-16 code units of the line before the position.
+type Position struct {
+	Line      int
+	Character int
+}
+
+// PositionOf returns the protocol position of pos in src. Only the Offset of
+// pos is used.
+func PositionOf(src []byte, pos token.Pos) Position {
+	off := pos.Offset
+	if off > len(src) {
+		off = len(src)
+	}
+
+	line := src[:off]
+	start := bytes.LastIndexByte(line, '\n') + 1
+	return Position{
+		Line:      bytes.Count(line, []byte{'\n'}),
+		Character: utf16Len(line[start:]),
+	}
+}
+
+// Offset returns the byte offset of p in src. A Character beyond the end of
+// its line is the end of the line, a Line beyond the end of src is the end of
+// src.
+func (p Position) Offset(src []byte) int {
+	off := 0
+	for i := 0; i < p.Line; i++ {
+		n := bytes.IndexByte(src[off:], '\n')
+		if n < 0 {
+			return len(src)
+		}
+		off += n + 1
+	}
+
+	for n := 0; off < len(src) && src[off] != '\n'; {
+		r, size := utf8.DecodeRune(src[off:])
+		n += utf16Units(r)
+		if n > p.Character {
+			break
+		}
+		off += size
+	}
+	return off
+}
+
+// utf16Len returns the number of UTF-16 code units of b
+func utf16Len(b []byte) int {
+	n := 0
+	for len(b) > 0 {
+		r, size := utf8.DecodeRune(b)
+		n += utf16Units(r)
+		b = b[size:]
+	}
+	return n
+}
+
+// utf16Units returns the number of UTF-16 code units of r, runes beyond the
+// Basic Multilingual Plane are encoded as surrogate pairs
+func utf16Units(r rune) int {
+	if r > 0xFFFF {
+		return 2
+	}
+	return 1
+}
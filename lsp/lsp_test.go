@@ -0,0 +1,194 @@
+package lsp
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/fatih/hcl/ast"
+	"github.com/fatih/hcl/parser"
+	"github.com/fatih/hcl/printer"
+	"github.com/fatih/hcl/token"
+)
+
+const src = `foo = "bar"
+
+service "web" {
+  port = 8080
+  tags = ["a", "b"]
+}
+`
+
+func TestNodeAt(t *testing.T) {
+	f, err := parser.Parse([]byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		offset int
+		last   reflect.Type
+		text   string
+	}{
+		{1, reflect.TypeOf(&ast.ObjectKey{}), "foo"},
+		{7, reflect.TypeOf(&ast.LiteralType{}), `"bar"`},
+		{strings.Index(src, "8080") + 2, reflect.TypeOf(&ast.LiteralType{}), "8080"},
+		{strings.Index(src, `"b"`), reflect.TypeOf(&ast.LiteralType{}), `"b"`},
+		{strings.Index(src, "port"), reflect.TypeOf(&ast.ObjectKey{}), "port"},
+		{strings.Index(src, "{"), reflect.TypeOf(&ast.ObjectType{}), ""},
+	}
+
+	for _, c := range cases {
+		path := NodeAt(f, token.Pos{Offset: c.offset})
+		if len(path) == 0 {
+			t.Errorf("offset %d: no node found", c.offset)
+			continue
+		}
+
+		n := path[len(path)-1]
+		if reflect.TypeOf(n) != c.last {
+			t.Errorf("offset %d: want: %s got: %T", c.offset, c.last, n)
+			continue
+		}
+
+		var text string
+		switch n := n.(type) {
+		case *ast.ObjectKey:
+			text = n.Token.Text
+		case *ast.LiteralType:
+			text = n.Token.Text
+		}
+
+		if text != c.text {
+			t.Errorf("offset %d: want: %q got: %q", c.offset, c.text, text)
+		}
+	}
+}
+
+func TestSymbols(t *testing.T) {
+	f, err := parser.Parse([]byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	syms := Symbols(f)
+	if len(syms) != 2 {
+		t.Fatalf("want: 2 symbols got: %d", len(syms))
+	}
+
+	if syms[0].Name != "foo" || syms[0].Kind != Attribute {
+		t.Errorf("want: foo Attribute got: %s %s", syms[0].Name, syms[0].Kind)
+	}
+
+	if syms[1].Name != `service "web"` || syms[1].Kind != Block {
+		t.Errorf("want: service \"web\" Block got: %s %s", syms[1].Name, syms[1].Kind)
+	}
+
+	if syms[1].Pos.Line != 3 || syms[1].End.Offset != len(src)-1 {
+		t.Errorf("wrong range: %s - %d", syms[1].Pos, syms[1].End.Offset)
+	}
+
+	var children []string
+	for _, c := range syms[1].Children {
+		children = append(children, c.Name)
+	}
+
+	if !reflect.DeepEqual(children, []string{"port", "tags"}) {
+		t.Errorf("want: [port tags] got: %v", children)
+	}
+}
+
+func TestFormatEdits(t *testing.T) {
+	unformatted := "foo = 1\n\nbar {\nbaz    =     \"qux\"\n}\n\nend = true"
+
+	edits, err := FormatEdits([]byte(unformatted))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(edits) != 1 {
+		t.Fatalf("want: 1 edit got: %d", len(edits))
+	}
+
+	e := edits[0]
+	if e.Pos.Line != 3 {
+		t.Errorf("edit should start at line 3, got: %s", e.Pos)
+	}
+
+	res := unformatted[:e.Pos.Offset] + e.NewText + unformatted[e.End.Offset:]
+	formatted, err := printer.Format([]byte(unformatted))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if res != string(formatted) {
+		t.Errorf("applied edits:\n%s\n\nwant:\n%s", res, formatted)
+	}
+
+	edits, err = FormatEdits(formatted)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(edits) != 0 {
+		t.Errorf("formatted source should not have edits, got: %v", edits)
+	}
+}
+
+func TestSymbolsMultiline(t *testing.T) {
+	src := "doc = <<EOF\nhello\nEOF\n\nname = \"héllo 😀\"\n"
+	f, err := parser.Parse([]byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	syms := Symbols(f)
+	if len(syms) != 2 {
+		t.Fatalf("want: 2 symbols got: %d", len(syms))
+	}
+
+	cases := []struct {
+		pos  token.Pos
+		want Position
+	}{
+		{syms[0].End, Position{Line: 2, Character: 3}},
+		{syms[1].Pos, Position{Line: 4, Character: 0}},
+		{syms[1].End, Position{Line: 4, Character: 17}},
+	}
+
+	for _, c := range cases {
+		got := PositionOf([]byte(src), c.pos)
+		if got != c.want {
+			t.Errorf("%s: want: %+v got: %+v", c.pos, c.want, got)
+		}
+		if off := got.Offset([]byte(src)); off != c.pos.Offset {
+			t.Errorf("%+v: want offset: %d got: %d", got, c.pos.Offset, off)
+		}
+	}
+
+	if end := syms[0].End; end.Line != 3 || end.Column != 4 {
+		t.Errorf("wrong heredoc end: %s", end)
+	}
+}
+
+func TestPositionOffset(t *testing.T) {
+	src := []byte("a = \"😀x\"\nb = 1")
+
+	cases := []struct {
+		pos    Position
+		offset int
+	}{
+		{Position{0, 0}, 0},
+		{Position{0, 5}, 5},
+		{Position{0, 7}, 9},
+		{Position{0, 100}, 11},
+		{Position{1, 4}, 16},
+		{Position{5, 0}, len(src)},
+	}
+
+	for _, c := range cases {
+		if off := c.pos.Offset(src); off != c.offset {
+			t.Errorf("%+v: want: %d got: %d", c.pos, c.offset, off)
+		}
+	}
+}
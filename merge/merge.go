@@ -0,0 +1,200 @@
+// Package merge merges HCL (HashiCorp Configuration Language) syntax trees,
+// i.e. to layer environment specific overrides on top of a base config.
+package merge
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fatih/hcl/ast"
+	"github.com/fatih/hcl/diff"
+	"github.com/fatih/hcl/token"
+)
+
+// DefaultConfig merges nested objects key by key, replaces lists and lets the
+// override win on conflicts.
+var DefaultConfig = Config{
+	Deep: true,
+}
+
+// A Config controls the merge strategy of Merge.
+type Config struct {
+	// Deep merges nested objects item by item. Otherwise an object of the
+	// override replaces the object of the base entirely.
+	Deep bool
+
+	// AppendLists appends the elements of a list of the override to the list
+	// of the base, instead of replacing it.
+	AppendLists bool
+
+	// ErrorOnConflict returns a *ConflictError if the override changes a
+	// value of the base, instead of replacing it.
+	ErrorOnConflict bool
+
+	// prov records the definitions of the override file filename, if set
+	prov     *Provenance
+	filename string
+}
+
+// ConflictError is returned if the base and the override define different
+// values for the same keys and Config.ErrorOnConflict is set.
+type ConflictError struct {
+	Path     []string  // keys of the conflicting item, string keys are unquoted
+	Base     token.Pos // position of the item in the base
+	Override token.Pos // position of the item in the override
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("conflicting values for %q: base at %s, override at %s",
+		ast.JoinPath(e.Path), e.Base, e.Override)
+}
+
+// Merge merges override on top of base and returns the result. Items are
+// matched by their keys, items with the same keys are matched in the order of
+// their appearance. Unmatched items of the override are appended. The inputs
+// are not modified, but the result shares nodes with them.
+//
+// The result contains the lead and line comments of the items, but no
+// standalone comments, as their positions are not meaningful anymore.
+func (c *Config) Merge(base, override *ast.File) (*ast.File, error) {
+	a, ok := base.Node.(*ast.ObjectList)
+	if !ok {
+		return nil, fmt.Errorf("base: expected an object list, got: %T", base.Node)
+	}
+
+	b, ok := override.Node.(*ast.ObjectList)
+	if !ok {
+		return nil, fmt.Errorf("override: expected an object list, got: %T", override.Node)
+	}
+
+	list, err := c.list(nil, a, b)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ast.File{Node: list}, nil
+}
+
+// Merge merges override on top of base with the default config.
+func Merge(base, override *ast.File) (*ast.File, error) {
+	return DefaultConfig.Merge(base, override)
+}
+
+func (c *Config) list(path []string, a, b *ast.ObjectList) (*ast.ObjectList, error) {
+	res := &ast.ObjectList{}
+	index := make(map[string][]int) // key -> indices into res.Items
+	for _, item := range a.Items {
+		k := key(item)
+		index[k] = append(index[k], len(res.Items))
+		res.Add(item)
+	}
+
+	seen := make(map[string]int)
+	for _, item := range b.Items {
+		k := key(item)
+		i := seen[k]
+		seen[k]++
+
+		if i >= len(index[k]) {
+			if c.prov != nil {
+				c.prov.add(itemPath(path, item), item, c.filename)
+			}
+			res.Add(item)
+			continue
+		}
+
+		j := index[k][i]
+		merged, err := c.item(path, res.Items[j], item)
+		if err != nil {
+			return nil, err
+		}
+		res.Items[j] = merged
+	}
+
+	return res, nil
+}
+
+func (c *Config) item(path []string, a, b *ast.ObjectItem) (*ast.ObjectItem, error) {
+	p := itemPath(path, b)
+
+	// copy the item of the base, so it keeps its comments, except for the
+	// line comment of the override, which belongs to its value
+	res := *a
+	if b.LineComment != nil {
+		res.LineComment = b.LineComment
+	}
+
+	// record the definition of the override, the nested items of merged
+	// objects are recorded while merging them
+	record := func(replaced bool) {
+		if c.prov == nil {
+			return
+		}
+
+		if replaced {
+			c.prov.reset(p)
+			c.prov.add(p, b, c.filename)
+			return
+		}
+
+		pos := b.Pos()
+		pos.Filename = c.filename
+		key := ast.JoinPath(p)
+		c.prov.origins[key] = append(c.prov.origins[key], pos)
+	}
+
+	switch {
+	case c.Deep && isObject(a.Val) && isObject(b.Val):
+		record(false)
+		oa, ob := a.Val.(*ast.ObjectType), b.Val.(*ast.ObjectType)
+		la, _ := ast.ObjectListOf(oa)
+		lb, _ := ast.ObjectListOf(ob)
+		list, err := c.list(p, la, lb)
+		if err != nil {
+			return nil, err
+		}
+
+		obj := *oa
+		obj.List = list
+		res.Val = &obj
+	case c.AppendLists && isList(a.Val) && isList(b.Val):
+		record(false)
+		la, lb := a.Val.(*ast.ListType), b.Val.(*ast.ListType)
+		list := *la
+		list.List = append(append([]ast.Node{}, la.List...), lb.List...)
+		res.Val = &list
+	case diff.Equal(a.Val, b.Val):
+		record(false)
+	case c.ErrorOnConflict:
+		return nil, &ConflictError{Path: p, Base: a.Pos(), Override: b.Pos()}
+	default:
+		record(true)
+		res.Val, res.LineComment = b.Val, b.LineComment
+	}
+
+	return &res, nil
+}
+
+func isObject(n ast.Node) bool {
+	_, ok := n.(*ast.ObjectType)
+	return ok
+}
+
+func isList(n ast.Node) bool {
+	_, ok := n.(*ast.ListType)
+	return ok
+}
+
+func itemPath(path []string, item *ast.ObjectItem) []string {
+	p := make([]string, len(path), len(path)+len(item.Keys))
+	copy(p, path)
+	for _, k := range item.Keys {
+		p = append(p, k.Token.Value())
+	}
+	return p
+}
+
+// key returns a key to match the items with the same keys
+func key(item *ast.ObjectItem) string {
+	return strings.Join(itemPath(nil, item), "\x00")
+}
@@ -0,0 +1,206 @@
+package merge
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/fatih/hcl/parser"
+	"github.com/fatih/hcl/printer"
+)
+
+func TestMerge(t *testing.T) {
+	cases := []struct {
+		cfg            Config
+		base, override string
+		expected       string
+		conflict       bool
+	}{
+		{
+			DefaultConfig,
+			"foo = 1\nbar = 2",
+			"bar = 3\nbaz = 4",
+			"foo = 1\n\nbar = 3\n\nbaz = 4",
+			false,
+		},
+		{
+			DefaultConfig,
+			"service \"web\" {\n  port = 80\n  host = \"a\"\n}",
+			"service \"web\" {\n  port = 8080\n}",
+			"service \"web\" {\n  port = 8080\n  host = \"a\"\n}",
+			false,
+		},
+		{
+			Config{},
+			"service \"web\" {\n  port = 80\n  host = \"a\"\n}",
+			"service \"web\" {\n  port = 8080\n}",
+			"service \"web\" {\n  port = 8080\n}",
+			false,
+		},
+		{
+			DefaultConfig,
+			"# lead\nfoo = 1 # base\nbar = 2 # replaced",
+			"foo = 3 # override\nbar = 4",
+			"# lead\nfoo = 3 # override\n\nbar = 4",
+			false,
+		},
+		{
+			DefaultConfig,
+			"a {\n  b = 1\n}\nc = 1",
+			"a {\n  b = 2 # override\n} # block\nc = 1 # equal",
+			"a {\n  b = 2 # override\n} # block\n\nc = 1 # equal",
+			false,
+		},
+		{
+			Config{AppendLists: true},
+			`list = ["a"]`,
+			`list = ["b"]`,
+			`list = ["a", "b"]`,
+			false,
+		},
+		{
+			DefaultConfig,
+			`list = ["a"]`,
+			`list = ["b"]`,
+			`list = ["b"]`,
+			false,
+		},
+		{
+			Config{Deep: true, ErrorOnConflict: true},
+			"a {\n  b = 1\n}",
+			"a {\n  b = 1\n  c = 2\n}",
+			"a {\n  b = 1\n  c = 2\n}",
+			false,
+		},
+		{
+			Config{Deep: true, ErrorOnConflict: true},
+			"a {\n  b = 1\n}",
+			"a {\n  b = 2\n}",
+			"",
+			true,
+		},
+	}
+
+	for _, c := range cases {
+		base, err := parser.Parse([]byte(c.base))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		override, err := parser.Parse([]byte(c.override))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		res, err := c.cfg.Merge(base, override)
+		if c.conflict {
+			if _, ok := err.(*ConflictError); !ok {
+				t.Errorf("want: *ConflictError got: %v", err)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var buf bytes.Buffer
+		if err := printer.Fprint(&buf, res); err != nil {
+			t.Fatal(err)
+		}
+
+		expected, err := printer.Format([]byte(c.expected))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if buf.String() != string(expected) {
+			t.Errorf("want:\n%s\n\ngot:\n%s", expected, buf.String())
+		}
+	}
+}
+
+func TestMergeDoesNotModifyInputs(t *testing.T) {
+	base, err := parser.Parse([]byte("a {\n  b = 1\n}"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	override, err := parser.Parse([]byte("a {\n  b = 2\n}"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Merge(base, override); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	printer.Fprint(&buf, base)
+	if buf.String() != "a = {\n  b = 1\n}" {
+		t.Errorf("base was modified:\n%s", buf.String())
+	}
+}
+
+func TestMergeFiles(t *testing.T) {
+	parse := func(name, src string) File {
+		f, err := parser.Parse([]byte(src))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return File{Name: name, File: f}
+	}
+
+	base := parse("base.hcl", "service \"web\" {\n  port = 80\n  host = \"a\"\n}\n\ndb {\n  user = \"x\"\n}")
+	prod := parse("prod.hcl", "service \"web\" {\n  port = 8080\n}\n\nregion = \"eu\"")
+	local := parse("local.hcl", "service \"web\" {\n  port = 9090\n}")
+
+	cfg := DefaultConfig
+	res, prov, err := cfg.MergeFiles(base, prod, local)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	printer.Fprint(&buf, res)
+	if expected := "service \"web\" {\n  port = 9090\n  host = \"a\"\n}\n\ndb = {\n  user = \"x\"\n}\n\nregion = \"eu\""; buf.String() != expected {
+		t.Errorf("want:\n%s\n\ngot:\n%s", expected, buf.String())
+	}
+
+	cases := []struct {
+		path     string
+		expected []string
+	}{
+		{"service.web.port", []string{"local.hcl:2:3", "prod.hcl:2:3", "base.hcl:2:3"}},
+		{"service.web.host", []string{"base.hcl:3:3"}},
+		{"service.web", []string{"local.hcl:1:1", "prod.hcl:1:1", "base.hcl:1:1"}},
+		{"region", []string{"prod.hcl:5:1"}},
+		{"missing", []string{}},
+	}
+
+	for _, c := range cases {
+		var got []string
+		for _, pos := range prov.Explain(c.path) {
+			got = append(got, pos.String())
+		}
+
+		if strings.Join(got, " ") != strings.Join(c.expected, " ") {
+			t.Errorf("%s: want: %v, got: %v", c.path, c.expected, got)
+		}
+	}
+
+	// replaced objects drop the definitions of their nested items
+	cfg.Deep = false
+	_, prov, err = cfg.MergeFiles(base, parse("db.hcl", "db {\n  password = \"y\"\n}"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := prov.Explain("db.user"); len(got) != 0 {
+		t.Errorf("db.user: want no definitions, got: %v", got)
+	}
+
+	if got := prov.Explain("db.password"); len(got) != 1 || got[0].String() != "db.hcl:2:3" {
+		t.Errorf("db.password: want db.hcl:2:3, got: %v", got)
+	}
+}
@@ -0,0 +1,99 @@
+package merge
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/fatih/hcl/ast"
+	"github.com/fatih/hcl/token"
+)
+
+// File is a syntax tree with the name of the file it was parsed from.
+type File struct {
+	Name string
+	*ast.File
+}
+
+// Provenance records which files define the items of a merged file. Items
+// are identified by their path, the unquoted keys of the item and of the
+// enclosing items joined by ast.JoinPath, i.e. "service.web.port" or
+// `labels."app.kubernetes.io/name"`.
+type Provenance struct {
+	origins map[string][]token.Pos
+}
+
+// Explain returns the positions of the definitions of the item at path,
+// starting with the one in effect, followed by the ones it overrode. Lists
+// appended with Config.AppendLists and merged objects are in effect in all
+// of their definitions. The positions contain the file names.
+func (p *Provenance) Explain(path string) []token.Pos {
+	origins := p.origins[path]
+	res := make([]token.Pos, 0, len(origins))
+	for i := len(origins) - 1; i >= 0; i-- {
+		res = append(res, origins[i])
+	}
+	return res
+}
+
+// Paths returns the paths of all items of the merged file, sorted.
+func (p *Provenance) Paths() []string {
+	paths := make([]string, 0, len(p.origins))
+	for path := range p.origins {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// add records the definition of item at path and of its nested items
+func (p *Provenance) add(path []string, item *ast.ObjectItem, filename string) {
+	pos := item.Pos()
+	pos.Filename = filename
+
+	key := ast.JoinPath(path)
+	p.origins[key] = append(p.origins[key], pos)
+
+	if obj, ok := item.Val.(*ast.ObjectType); ok && obj.List != nil {
+		for _, nested := range obj.List.Items {
+			p.add(itemPath(path, nested), nested, filename)
+		}
+	}
+}
+
+// reset removes the definitions of the nested items of the item at path, if
+// its value is replaced
+func (p *Provenance) reset(path []string) {
+	prefix := ast.JoinPath(path) + "."
+	for k := range p.origins {
+		if strings.HasPrefix(k, prefix) {
+			delete(p.origins, k)
+		}
+	}
+}
+
+// MergeFiles merges every file on top of the result of the previous ones and
+// returns the result along with the provenance of its items.
+func (c *Config) MergeFiles(files ...File) (*ast.File, *Provenance, error) {
+	prov := &Provenance{origins: make(map[string][]token.Pos)}
+	if len(files) == 0 {
+		return &ast.File{Node: &ast.ObjectList{}}, prov, nil
+	}
+
+	res := files[0].File
+	if list, ok := res.Node.(*ast.ObjectList); ok {
+		for _, item := range list.Items {
+			prov.add(itemPath(nil, item), item, files[0].Name)
+		}
+	}
+
+	for _, f := range files[1:] {
+		mc := *c
+		mc.prov, mc.filename = prov, f.Name
+
+		var err error
+		if res, err = mc.Merge(res, f.File); err != nil {
+			return nil, nil, err
+		}
+	}
+	return res, prov, nil
+}
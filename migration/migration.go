@@ -0,0 +1,350 @@
+// Package migration upgrades HCL (HashiCorp Configuration Language) config
+// files between versions of their format. Migrations are registered for
+// every version and transform the syntax tree, so comments of the items are
+// preserved.
+package migration
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strconv"
+	"unicode"
+
+	"github.com/fatih/hcl/ast"
+	"github.com/fatih/hcl/parser"
+	"github.com/fatih/hcl/printer"
+	"github.com/fatih/hcl/token"
+)
+
+// A Step is a single transformation of a file.
+type Step interface {
+	Apply(f *ast.File) error
+}
+
+// StepFunc is a function used as a Step.
+type StepFunc func(f *ast.File) error
+
+// Apply calls fn(f).
+func (fn StepFunc) Apply(f *ast.File) error {
+	return fn(f)
+}
+
+// Migration upgrades a file from the previous version to Version.
+type Migration struct {
+	Version     int
+	Description string
+	Steps       []Step
+}
+
+// Migrator applies the registered migrations to files. The version of a
+// file is stored in the top level attribute VersionKey, files without it are
+// of version 0.
+type Migrator struct {
+	VersionKey string
+	migrations []*Migration
+}
+
+// New returns a migrator storing the version of files in the given key.
+func New(versionKey string) *Migrator {
+	return &Migrator{VersionKey: versionKey}
+}
+
+// Register registers the steps upgrading a file to the given version. It
+// panics if a migration for the version is already registered.
+func (m *Migrator) Register(version int, description string, steps ...Step) {
+	for _, mig := range m.migrations {
+		if mig.Version == version {
+			panic(fmt.Sprintf("migration: version %d registered twice", version))
+		}
+	}
+
+	m.migrations = append(m.migrations, &Migration{
+		Version:     version,
+		Description: description,
+		Steps:       steps,
+	})
+	sort.Sort(byVersion(m.migrations))
+}
+
+// Latest returns the latest registered version.
+func (m *Migrator) Latest() int {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+// Migrate applies all migrations newer than the version of f in order and
+// updates its version. It returns the versions before and after the
+// migration. f is modified in place.
+func (m *Migrator) Migrate(f *ast.File) (from, to int, err error) {
+	list, ok := f.Node.(*ast.ObjectList)
+	if !ok {
+		return 0, 0, fmt.Errorf("expected an object list, got: %T", f.Node)
+	}
+
+	from, err = m.version(list)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	to = from
+	for _, mig := range m.migrations {
+		if mig.Version <= from {
+			continue
+		}
+
+		for i, step := range mig.Steps {
+			if err := step.Apply(f); err != nil {
+				return from, to, fmt.Errorf("migration to version %d, step %d: %s", mig.Version, i+1, err)
+			}
+		}
+		to = mig.Version
+	}
+
+	if to != from {
+		m.setVersion(f.Node.(*ast.ObjectList), to)
+	}
+	return from, to, nil
+}
+
+// MigrateFile migrates the file at path in place. It reports whether the
+// file was changed.
+func (m *Migrator) MigrateFile(path string) (bool, error) {
+	src, err := ioutil.ReadFile(path)
+	if err != nil {
+		return false, err
+	}
+
+	f, err := parser.Parse(src)
+	if err != nil {
+		return false, err
+	}
+
+	from, to, err := m.Migrate(f)
+	if err != nil || from == to {
+		return false, err
+	}
+
+	var buf bytes.Buffer
+	if err := printer.Fprint(&buf, f); err != nil {
+		return false, err
+	}
+	buf.WriteByte('\n')
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return false, err
+	}
+
+	if err := ioutil.WriteFile(path, buf.Bytes(), info.Mode()); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (m *Migrator) version(list *ast.ObjectList) (int, error) {
+	for _, item := range list.Items {
+		if len(item.Keys) != 1 || item.Keys[0].Token.Value() != m.VersionKey {
+			continue
+		}
+
+		lit, ok := item.Val.(*ast.LiteralType)
+		if !ok {
+			return 0, fmt.Errorf("%s: %s must be a number", item.Pos(), m.VersionKey)
+		}
+
+		v, err := strconv.Atoi(lit.Token.Value())
+		if err != nil {
+			return 0, fmt.Errorf("%s: %s must be a number, got: %s", lit.Pos(), m.VersionKey, lit.Token.Text)
+		}
+		return v, nil
+	}
+	return 0, nil
+}
+
+func (m *Migrator) setVersion(list *ast.ObjectList, version int) {
+	val := &ast.LiteralType{Token: token.Token{Type: token.NUMBER, Text: strconv.Itoa(version)}}
+	for _, item := range list.Items {
+		if len(item.Keys) == 1 && item.Keys[0].Token.Value() == m.VersionKey {
+			val.Token.Pos = item.Val.Pos()
+			item.Val = val
+			return
+		}
+	}
+
+	// prepend the version, so it's the first item of the file
+	list.Items = append([]*ast.ObjectItem{{
+		Keys: []*ast.ObjectKey{{Token: token.Token{Type: token.IDENT, Text: m.VersionKey}}},
+		Val:  val,
+	}}, list.Items...)
+}
+
+type byVersion []*Migration
+
+func (b byVersion) Len() int           { return len(b) }
+func (b byVersion) Swap(i, j int)      { b[i], b[j] = b[j], b[i] }
+func (b byVersion) Less(i, j int) bool { return b[i].Version < b[j].Version }
+
+// Rename renames the key at the end of path to name. A path consists of keys
+// separated by dots, where "*" matches any key, i.e. "service.*.port"
+// matches the port of all service blocks with a label. Keys containing dots
+// are quoted, see ast.SplitPath.
+func Rename(path, name string) Step {
+	return StepFunc(func(f *ast.File) error {
+		for _, m := range find(f, path) {
+			m.item.Keys[m.key].Token = keyToken(name, m.item.Keys[m.key].Token.Pos)
+		}
+		return nil
+	})
+}
+
+// Move moves the items at path into the block dest, which is a path relative
+// to the object containing the item. Missing blocks are created.
+func Move(path, dest string) Step {
+	return StepFunc(func(f *ast.File) error {
+		for _, m := range find(f, path) {
+			if m.key != len(m.item.Keys)-1 {
+				return fmt.Errorf("%s: can't move a part of the keys of %s", m.item.Pos(), path)
+			}
+
+			list, err := block(m.list, split(dest))
+			if err != nil {
+				return err
+			}
+
+			remove(m.list, m.item)
+			list.Add(m.item)
+		}
+		return nil
+	})
+}
+
+// Convert replaces the values of the items at path with the result of fn,
+// i.e. to change the format of a value.
+func Convert(path string, fn func(ast.Node) (ast.Node, error)) Step {
+	return StepFunc(func(f *ast.File) error {
+		for _, m := range find(f, path) {
+			if m.key != len(m.item.Keys)-1 {
+				continue // the value belongs to a nested key
+			}
+
+			val, err := fn(m.item.Val)
+			if err != nil {
+				return fmt.Errorf("%s: %s", m.item.Pos(), err)
+			}
+			m.item.Val = val
+		}
+		return nil
+	})
+}
+
+// Delete removes the items at path.
+func Delete(path string) Step {
+	return StepFunc(func(f *ast.File) error {
+		for _, m := range find(f, path) {
+			remove(m.list, m.item)
+		}
+		return nil
+	})
+}
+
+// match is an item matching a path
+type match struct {
+	list *ast.ObjectList // list containing the item
+	item *ast.ObjectItem
+	key  int // index of the key matching the end of the path
+}
+
+func find(f *ast.File, path string) []match {
+	list, ok := f.Node.(*ast.ObjectList)
+	if !ok {
+		return nil
+	}
+	return findList(list, split(path))
+}
+
+func findList(list *ast.ObjectList, path []string) []match {
+	var matches []match
+	for _, item := range list.Items {
+		matches = append(matches, findItem(list, item, path)...)
+	}
+	return matches
+}
+
+func findItem(list *ast.ObjectList, item *ast.ObjectItem, path []string) []match {
+	for i, key := range item.Keys {
+		if path[i] != "*" && path[i] != key.Token.Value() {
+			return nil
+		}
+
+		if i == len(path)-1 {
+			return []match{{list: list, item: item, key: i}}
+		}
+	}
+
+	obj, ok := item.Val.(*ast.ObjectType)
+	if !ok || obj.List == nil {
+		return nil
+	}
+	return findList(obj.List, path[len(item.Keys):])
+}
+
+// block returns the list of the block at path in list, creating it if
+// necessary
+func block(list *ast.ObjectList, path []string) (*ast.ObjectList, error) {
+	if len(path) == 0 {
+		return list, nil
+	}
+
+	for _, item := range list.Items {
+		if len(item.Keys) != 1 || item.Keys[0].Token.Value() != path[0] {
+			continue
+		}
+
+		obj, ok := item.Val.(*ast.ObjectType)
+		if !ok {
+			return nil, fmt.Errorf("%s: %s is not a block", item.Pos(), path[0])
+		}
+
+		if obj.List == nil {
+			obj.List = &ast.ObjectList{}
+		}
+		return block(obj.List, path[1:])
+	}
+
+	obj := &ast.ObjectType{List: &ast.ObjectList{}}
+	list.Add(&ast.ObjectItem{
+		Keys: []*ast.ObjectKey{{Token: keyToken(path[0], token.Pos{})}},
+		Val:  obj,
+	})
+	return block(obj.List, path[1:])
+}
+
+func remove(list *ast.ObjectList, item *ast.ObjectItem) {
+	for i, it := range list.Items {
+		if it == item {
+			list.Items = append(list.Items[:i], list.Items[i+1:]...)
+			return
+		}
+	}
+}
+
+// keyToken returns the token of a key, which is quoted if it's not a valid
+// identifier
+func keyToken(name string, pos token.Pos) token.Token {
+	for i, ch := range name {
+		if ch == '_' || unicode.IsLetter(ch) || i > 0 && unicode.IsDigit(ch) {
+			continue
+		}
+		return token.Token{Type: token.STRING, Pos: pos, Text: strconv.Quote(name)}
+	}
+	return token.Token{Type: token.IDENT, Pos: pos, Text: name}
+}
+
+func split(path string) []string {
+	return ast.SplitPath(path)
+}
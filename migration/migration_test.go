@@ -0,0 +1,175 @@
+package migration
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/fatih/hcl/ast"
+	"github.com/fatih/hcl/parser"
+	"github.com/fatih/hcl/printer"
+	"github.com/fatih/hcl/token"
+)
+
+func testMigrator() *Migrator {
+	m := New("config_version")
+	m.Register(2, "move listen settings into the server block",
+		Move("listen_port", "server"),
+		Rename("server.listen_port", "port"),
+	)
+	m.Register(1, "rename hosts",
+		Rename("service.*.hosts", "addresses"),
+		Delete("legacy"),
+	)
+	m.Register(3, "timeouts are strings with units",
+		Convert("service.*.timeout", func(n ast.Node) (ast.Node, error) {
+			lit := n.(*ast.LiteralType)
+			return &ast.LiteralType{
+				Token: token.Token{Type: token.STRING, Pos: lit.Pos(), Text: `"` + lit.Token.Text + `s"`},
+			}, nil
+		}),
+	)
+	return m
+}
+
+func TestMigrate(t *testing.T) {
+	src := `# The port to listen on
+listen_port = 8080 # default
+
+legacy = true
+
+service "web" {
+  # all hosts
+  hosts   = ["a", "b"]
+  timeout = 30
+}
+`
+
+	f, err := parser.Parse([]byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := testMigrator()
+	from, to, err := m.Migrate(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if from != 0 || to != 3 || m.Latest() != 3 {
+		t.Errorf("want versions 0 -> 3 got: %d -> %d", from, to)
+	}
+
+	var buf bytes.Buffer
+	if err := printer.Fprint(&buf, f); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+
+	for _, want := range []string{
+		"config_version = 3",
+		"# all hosts\n  addresses = [\"a\", \"b\"]",
+		`timeout   = "30s"`,
+		"server = {\n  # The port to listen on\n  port = 8080 # default\n}",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output doesn't contain %q:\n%s", want, out)
+		}
+	}
+
+	if strings.Contains(out, "legacy") || strings.Contains(out, "hosts =") {
+		t.Errorf("unexpected output:\n%s", out)
+	}
+
+	// migrating again doesn't change anything
+	from, to, err = m.Migrate(f)
+	if err != nil || from != 3 || to != 3 {
+		t.Errorf("want versions 3 -> 3 got: %d -> %d, %v", from, to, err)
+	}
+}
+
+func TestMigratePartial(t *testing.T) {
+	f, err := parser.Parse([]byte("config_version = 2\nservice \"web\" { timeout = 5 }"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	from, to, err := testMigrator().Migrate(f)
+	if err != nil || from != 2 || to != 3 {
+		t.Fatalf("want versions 2 -> 3 got: %d -> %d, %v", from, to, err)
+	}
+
+	var buf bytes.Buffer
+	printer.Fprint(&buf, f)
+	if !strings.Contains(buf.String(), `timeout = "5s"`) || strings.Contains(buf.String(), "server") {
+		t.Errorf("unexpected output:\n%s", buf.String())
+	}
+}
+
+func TestMigrateError(t *testing.T) {
+	m := New("version")
+	m.Register(1, "fails", StepFunc(func(f *ast.File) error { return os.ErrInvalid }))
+
+	f, _ := parser.Parse([]byte(`a = 1`))
+	if _, _, err := m.Migrate(f); err == nil || err.Error() != "migration to version 1, step 1: invalid argument" {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	f, _ = parser.Parse([]byte(`version = "x"`))
+	if _, _, err := m.Migrate(f); err == nil {
+		t.Error("Migrate should fail for invalid versions")
+	}
+
+	f, _ = parser.Parse([]byte("a = 1\nb { a = 2 }"))
+	if err := Move("a", "b.a").Apply(f); err == nil || err.Error() != `2:5: a is not a block` {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestMigrateFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "migration")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "config.hcl")
+	if err := ioutil.WriteFile(path, []byte("legacy = 1\nservice \"a\" {\n  hosts = []\n}\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	m := testMigrator()
+	changed, err := m.MigrateFile(path)
+	if err != nil || !changed {
+		t.Fatalf("want changed file got: %v %v", changed, err)
+	}
+
+	out, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "config_version = 3\n\nservice \"a\" {\n  addresses = []\n}\n"
+	if string(out) != expected {
+		t.Errorf("\nwant:\n%s\ngot:\n%s", expected, out)
+	}
+
+	if changed, err := m.MigrateFile(path); err != nil || changed {
+		t.Errorf("want unchanged file got: %v %v", changed, err)
+	}
+}
+
+func TestRegisterTwice(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Register should panic")
+		}
+	}()
+
+	m := New("version")
+	m.Register(1, "a")
+	m.Register(1, "b")
+}
@@ -0,0 +1,183 @@
+// Package normalize rewrites semantically equivalent spellings in HCL
+// (HashiCorp Configuration Language) syntax trees into a canonical form, i.e.
+// before hashing or diffing files written by different people or tools.
+package normalize
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/fatih/hcl/ast"
+	"github.com/fatih/hcl/schema"
+	"github.com/fatih/hcl/token"
+)
+
+// Options selects the spellings Normalize rewrites. Nothing is rewritten by
+// the zero Options.
+type Options struct {
+	// Keys unquotes the names of attributes and blocks which are
+	// identifiers, "port" = 80 becomes port = 80, and quotes the labels of
+	// blocks, service web {} becomes service "web" {}.
+	Keys bool
+
+	// Bools writes the keywords true, false and null in lower case, i.e. of
+	// files parsed with parser.Config.FoldCase.
+	Bools bool
+
+	// Numbers writes numbers with an integral value as decimal integers,
+	// 1.0, 1e3 and 0x1f become 1, 1000 and 31, and other numbers in their
+	// shortest form, 1.50 becomes 1.5. Integers and floats decode into
+	// different values of empty interfaces, so with a Schema only the
+	// numbers of attributes of type schema.Number and of lists and objects
+	// of numbers are rewritten.
+	Numbers bool
+
+	// Schema, if not nil, restricts Numbers to the attributes it declares
+	// as numbers.
+	Schema *schema.Schema
+}
+
+// Normalize rewrites the spellings of n selected by opts in place and
+// returns n. Use ast.Copy to keep the original tree.
+func Normalize(n ast.Node, opts Options) ast.Node {
+	if f, ok := n.(*ast.File); ok {
+		Normalize(f.Node, opts)
+		return n
+	}
+
+	nz := &normalizer{opts: opts}
+	if opts.Schema != nil {
+		nz.object(n, opts.Schema)
+	} else {
+		nz.node(n, true)
+	}
+	return n
+}
+
+type normalizer struct {
+	opts Options
+}
+
+// node normalizes n and its children, including their numbers if numbers is
+// set
+func (nz *normalizer) node(n ast.Node, numbers bool) {
+	ast.Walk(n, func(n ast.Node) (ast.Node, bool) {
+		switch t := n.(type) {
+		case *ast.ObjectItem:
+			nz.keys(t)
+		case *ast.LiteralType:
+			nz.literal(t, numbers)
+		}
+		return n, true
+	})
+}
+
+// object normalizes the items of the object n described by s, nil for an
+// object of any content
+func (nz *normalizer) object(n ast.Node, s *schema.Schema) {
+	list, ok := ast.ObjectListOf(n)
+	if !ok {
+		nz.node(n, false)
+		return
+	}
+
+	for _, item := range list.Items {
+		nz.keys(item)
+		if len(item.Keys) == 0 || s == nil {
+			nz.node(item.Val, false)
+			continue
+		}
+
+		name := item.Keys[0].Token.Value()
+		if block := findBlock(s, name); block != nil && len(item.Keys) == len(block.Labels)+1 {
+			nz.object(item.Val, block.Body)
+			continue
+		}
+
+		attr := findAttribute(s, name)
+		if attr == nil {
+			nz.node(item.Val, false)
+			continue
+		}
+
+		switch t := item.Val.(type) {
+		case *ast.LiteralType:
+			nz.literal(t, attr.Type == schema.Number)
+		case *ast.ListType, *ast.ObjectType:
+			// the elements of lists and the values of objects
+			ast.Walk(t, func(n ast.Node) (ast.Node, bool) {
+				if item, ok := n.(*ast.ObjectItem); ok {
+					nz.keys(item)
+				}
+				if lit, ok := n.(*ast.LiteralType); ok {
+					nz.literal(lit, attr.Elem == schema.Number)
+				}
+				return n, true
+			})
+		default:
+			nz.node(t, false)
+		}
+	}
+}
+
+// keys normalizes the keys of item
+func (nz *normalizer) keys(item *ast.ObjectItem) {
+	if !nz.opts.Keys {
+		return
+	}
+
+	for i, k := range item.Keys {
+		tok := &k.Token
+		switch {
+		case i == 0 && tok.Type == token.STRING && token.IsIdent(tok.Value()):
+			tok.Type, tok.Text = token.IDENT, tok.Value()
+		case i > 0 && tok.Type == token.IDENT:
+			tok.Type, tok.Text = token.STRING, strconv.Quote(tok.Text)
+		}
+	}
+}
+
+// literal normalizes the bool or, if numbers is set, the number lit
+func (nz *normalizer) literal(lit *ast.LiteralType, numbers bool) {
+	tok := &lit.Token
+	switch tok.Type {
+	case token.BOOL, token.NULL:
+		if nz.opts.Bools {
+			tok.Text = strings.ToLower(tok.Text)
+		}
+	case token.NUMBER, token.FLOAT:
+		if !nz.opts.Numbers || !numbers {
+			return
+		}
+
+		if i, err := tok.BigInt(); err == nil {
+			tok.Type, tok.Text = token.NUMBER, i.String()
+			return
+		}
+		if f, err := tok.BigFloat(); err == nil {
+			text := f.Text('g', -1)
+			if !strings.ContainsAny(text, ".e") {
+				text += ".0"
+			}
+			tok.Type, tok.Text = token.FLOAT, text
+		}
+	}
+}
+
+func findAttribute(s *schema.Schema, name string) *schema.Attribute {
+	for _, a := range s.Attributes {
+		if a.Name == name {
+			return a
+		}
+	}
+	return nil
+}
+
+func findBlock(s *schema.Schema, typ string) *schema.Block {
+	for _, b := range s.Blocks {
+		if b.Type == typ {
+			return b
+		}
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package normalize
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/fatih/hcl/parser"
+	"github.com/fatih/hcl/printer"
+	"github.com/fatih/hcl/schema"
+)
+
+func TestNormalize(t *testing.T) {
+	src := `"port" = 8080.0
+
+"a b" = TRUE
+
+proxy = Null
+
+size = 0x1F
+
+ratio = 1.50
+
+service web {
+  "count" = 1e3
+}
+
+tags = {
+  "x" = 2.0
+}
+`
+	cases := []struct {
+		name     string
+		opts     Options
+		expected string
+	}{
+		{"none", Options{}, src},
+		{
+			"all",
+			Options{Keys: true, Bools: true, Numbers: true},
+			`port = 8080
+
+"a b" = true
+
+proxy = null
+
+size = 31
+
+ratio = 1.5
+
+service "web" {
+  count = 1000
+}
+
+tags = {
+  x = 2
+}
+`,
+		},
+		{
+			"schema",
+			Options{Numbers: true, Schema: &schema.Schema{
+				Attributes: []*schema.Attribute{
+					{Name: "port", Type: schema.Number},
+					{Name: "ratio", Type: schema.Any},
+					{Name: "tags", Type: schema.Object, Elem: schema.Number},
+				},
+				Blocks: []*schema.Block{
+					{Type: "service", Labels: []string{"name"}, Body: &schema.Schema{
+						Attributes: []*schema.Attribute{{Name: "count", Type: schema.Number}},
+					}},
+				},
+			}},
+			`"port" = 8080
+
+"a b" = TRUE
+
+proxy = Null
+
+size = 0x1F
+
+ratio = 1.50
+
+service web {
+  "count" = 1000
+}
+
+tags = {
+  "x" = 2
+}
+`,
+		},
+	}
+
+	for _, c := range cases {
+		f, err := (&parser.Config{Syntax: parser.Experimental, FoldCase: true}).Parse([]byte(src))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var buf bytes.Buffer
+		if err := printer.Fprint(&buf, Normalize(f, c.opts)); err != nil {
+			t.Fatal(err)
+		}
+
+		if got := buf.String(); strings.TrimSpace(got) != strings.TrimSpace(c.expected) {
+			t.Errorf("%s: want:\n%s\ngot:\n%s", c.name, c.expected, got)
+		}
+	}
+}
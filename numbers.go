@@ -0,0 +1,125 @@
+package hcl
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/fatih/hcl/ast"
+	"github.com/fatih/hcl/token"
+)
+
+var (
+	bigIntType   = reflect.TypeOf(big.Int{})
+	bigFloatType = reflect.TypeOf(big.Float{})
+)
+
+// number returns the value of a NUMBER or FLOAT literal as decoded into an
+// empty interface: an int for integers, including ones with an exponent
+// like 1e6, and a float64 otherwise. Integers exceeding int are an error,
+// they can be decoded into a *big.Int, a float or an unsigned integer large
+// enough, see decodeNumber.
+func number(lit *ast.LiteralType) (interface{}, error) {
+	text := lit.Token.Text
+	if lit.Token.Type == token.NUMBER {
+		if i, err := lit.Token.BigInt(); err == nil {
+			if !i.IsInt64() || strconv.IntSize == 32 && int64(int32(i.Int64())) != i.Int64() {
+				return nil, posErrorf(lit.Pos(), "number %s overflows int", text)
+			}
+			return int(i.Int64()), nil
+		}
+	}
+
+	f, err := lit.Token.BigFloat()
+	if err != nil {
+		return nil, posErrorf(lit.Pos(), "invalid number %s", text)
+	}
+	v, _ := f.Float64()
+	return v, nil
+}
+
+// decodeNumber decodes a NUMBER or FLOAT literal into a float or an unsigned
+// integer directly, so numbers beyond the ints of number, such as 1e20 or the
+// largest uint64, only overflow if they exceed rv. It reports whether the
+// literal is decoded, other kinds, fractions and negative numbers are left to
+// decodeValue.
+func (d *decoder) decodeNumber(name string, lit *ast.LiteralType, rv reflect.Value) (bool, error) {
+	switch rv.Kind() {
+	case reflect.Float32, reflect.Float64:
+		f, err := lit.Token.BigFloat()
+		if err != nil {
+			return true, posErrorf(lit.Pos(), "invalid number %s", lit.Token.Text)
+		}
+
+		v, _ := f.Float64()
+		if math.IsInf(v, 0) || rv.OverflowFloat(v) {
+			return true, posErrorf(lit.Pos(), "%s: %s overflows %s", name, lit.Token.Text, rv.Type())
+		}
+		rv.SetFloat(v)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		i, err := lit.Token.BigInt()
+		if err != nil || i.Sign() < 0 {
+			return false, nil
+		}
+
+		if !i.IsUint64() || rv.OverflowUint(i.Uint64()) {
+			return true, posErrorf(lit.Pos(), "%s: %s overflows %s", name, lit.Token.Text, rv.Type())
+		}
+		rv.SetUint(i.Uint64())
+	default:
+		return false, nil
+	}
+	return true, nil
+}
+
+// isBig reports whether numbers are decoded into t with all their digits
+func isBig(t reflect.Type) bool {
+	return t == bigIntType || t == bigFloatType
+}
+
+// bigNumber returns the *big.Int or *big.Float of the big type t a NUMBER or
+// FLOAT literal stands for
+func bigNumber(lit *ast.LiteralType, t reflect.Type) (interface{}, error) {
+	if t == bigIntType {
+		return lit.Token.BigInt()
+	}
+	return lit.Token.BigFloat()
+}
+
+// decodeBig decodes a NUMBER or FLOAT literal into a big.Int or big.Float,
+// keeping all digits of the literal
+func (d *decoder) decodeBig(name string, lit *ast.LiteralType, rv reflect.Value) error {
+	v, err := bigNumber(lit, rv.Type())
+	if err != nil {
+		return posErrorf(lit.Pos(), "%s: %s", name, err)
+	}
+	rv.Set(reflect.ValueOf(v).Elem())
+	return nil
+}
+
+// encodeBig returns the literal of a big.Int or big.Float with all its
+// digits
+func encodeBig(name string, rv reflect.Value) (ast.Node, error) {
+	p := reflect.New(rv.Type())
+	p.Elem().Set(rv)
+
+	tok := token.Token{Type: token.NUMBER}
+	switch v := p.Interface().(type) {
+	case *big.Int:
+		tok.Text = v.String()
+	case *big.Float:
+		if v.IsInf() {
+			return nil, fmt.Errorf("%s: cannot encode %s", name, v)
+		}
+
+		// floats keep their fraction, so they decode as floats again
+		tok.Type, tok.Text = token.FLOAT, v.Text('g', -1)
+		if !strings.ContainsAny(tok.Text, ".e") {
+			tok.Text += ".0"
+		}
+	}
+	return &ast.LiteralType{Token: tok}, nil
+}
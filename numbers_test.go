@@ -0,0 +1,159 @@
+package hcl
+
+import (
+	"math"
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDecodeNumbers(t *testing.T) {
+	src := `hex = 0x1F
+octal = 0o17
+legacy = 017
+exp = 1e6
+fraction = 2.5E-3
+negative = -0x10
+`
+
+	var out map[string]interface{}
+	if err := Decode(&out, src); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]interface{}{
+		"hex":      31,
+		"octal":    15,
+		"legacy":   15,
+		"exp":      1000000,
+		"fraction": 0.0025,
+		"negative": -16,
+	}
+	if !reflect.DeepEqual(out, want) {
+		t.Errorf("want %#v\ngot  %#v", want, out)
+	}
+
+	var typed struct {
+		Exp int64 `hcl:"exp"`
+		Hex uint8 `hcl:"hex"`
+	}
+	if err := Decode(&typed, src); err != nil {
+		t.Fatal(err)
+	}
+	if typed.Exp != 1000000 || typed.Hex != 31 {
+		t.Errorf("unexpected values %+v", typed)
+	}
+}
+
+func TestDecodeBig(t *testing.T) {
+	type config struct {
+		Supply *big.Int   `hcl:"supply"`
+		Limit  big.Int    `hcl:"limit"`
+		Rate   *big.Float `hcl:"rate"`
+		IDs    []*big.Int `hcl:"ids"`
+	}
+
+	src := `supply = 123456789012345678901234567890
+limit = 1e30
+rate = 0.1000000000000000000001
+ids = [0xffffffffffffffffff, -1]
+`
+
+	var out config
+	if err := Decode(&out, src); err != nil {
+		t.Fatal(err)
+	}
+
+	if s := out.Supply.String(); s != "123456789012345678901234567890" {
+		t.Errorf("want all digits of supply, got %s", s)
+	}
+	if s := out.Limit.String(); s != "1"+strings.Repeat("0", 30) {
+		t.Errorf("unexpected limit %s", s)
+	}
+	if s := out.Rate.Text('g', -1); s != "0.1000000000000000000001" {
+		t.Errorf("want all digits of rate, got %s", s)
+	}
+	if len(out.IDs) != 2 || out.IDs[0].Text(16) != strings.Repeat("f", 18) || out.IDs[1].Int64() != -1 {
+		t.Errorf("unexpected ids %v", out.IDs)
+	}
+
+	// encoded with all digits
+	res, err := Marshal(&out)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var back config
+	if err := Decode(&back, string(res)); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(back, out) {
+		t.Errorf("round trip differs:\n%s", res)
+	}
+
+	// integers exceeding int need a big.Int
+	var m map[string]interface{}
+	if err := Decode(&m, "supply = 123456789012345678901234567890"); err == nil || !strings.Contains(err.Error(), "overflows int") {
+		t.Errorf("want overflow error, got %v", err)
+	}
+
+	if err := Decode(&out, "supply = 2.5"); err == nil || err.Error() != "At 1:10: root.supply: 2.5 is not an integer" {
+		t.Errorf("want error for a fraction, got %v", err)
+	}
+}
+
+func TestDecodeLargeNumbers(t *testing.T) {
+	type config struct {
+		Max    uint64    `hcl:"max"`
+		Big    float64   `hcl:"big"`
+		Exp    float64   `hcl:"exp"`
+		Floats []float64 `hcl:"floats"`
+		Small  float32   `hcl:"small"`
+	}
+
+	src := `max = 18446744073709551615
+big = 12345678901234567890
+exp = 1e20
+floats = [1e20, 1]
+small = 1.5
+`
+
+	var out config
+	if err := Decode(&out, src); err != nil {
+		t.Fatal(err)
+	}
+	want := config{math.MaxUint64, 12345678901234567890, 1e20, []float64{1e20, 1}, 1.5}
+	if !reflect.DeepEqual(out, want) {
+		t.Errorf("want %+v\ngot  %+v", want, out)
+	}
+
+	// what Marshal writes decodes back
+	res, err := Marshal(&out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var back config
+	if err := Decode(&back, string(res)); err != nil {
+		t.Fatalf("%s\n%s", err, res)
+	}
+	if !reflect.DeepEqual(back, out) {
+		t.Errorf("round trip differs:\n%s", res)
+	}
+
+	cases := []struct {
+		src string
+		err string
+	}{
+		{"max = 18446744073709551616", "At 1:7: root.max: 18446744073709551616 overflows uint64"},
+		{"max = -1", "At 1:7: root.max: -1 overflows uint64"},
+		{"exp = 1E400", "At 1:7: root.exp: 1E400 overflows float64"},
+		{"small = 1e39", "At 1:9: root.small: 1e39 overflows float32"},
+	}
+	for _, c := range cases {
+		var out config
+		if err := Decode(&out, c.src); err == nil || err.Error() != c.err {
+			t.Errorf("%s:\nwant: %s\ngot:  %v", c.src, c.err, err)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package hcl
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/fatih/hcl/ast"
+)
+
+// OrderedMap is an object with its keys in the order of the source, i.e. for
+// configuration driving an ordered pipeline, whose order a
+// map[string]interface{} loses. Values are decoded like into an empty
+// interface, except that nested objects are OrderedMaps too. The values of a
+// repeated key become a list, at the position of the first occurrence of the
+// key. The encoder writes the items in the order of the map.
+type OrderedMap []MapItem
+
+// MapItem is an item of an OrderedMap.
+type MapItem struct {
+	Key   string
+	Value interface{}
+}
+
+var orderedMapType = reflect.TypeOf(OrderedMap(nil))
+
+// Get returns the value of key and whether m contains it.
+func (m OrderedMap) Get(key string) (interface{}, bool) {
+	if i := m.index(key); i >= 0 {
+		return m[i].Value, true
+	}
+	return nil, false
+}
+
+// Keys returns the keys of m in order.
+func (m OrderedMap) Keys() []string {
+	keys := make([]string, len(m))
+	for i, item := range m {
+		keys[i] = item.Key
+	}
+	return keys
+}
+
+// index returns the index of the item with the given key, or -1
+func (m OrderedMap) index(key string) int {
+	for i, item := range m {
+		if item.Key == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// decodeOrdered decodes the object n into the OrderedMap rv, merging it
+// with the items rv has already
+func (d *decoder) decodeOrdered(name string, n ast.Node, rv reflect.Value) error {
+	if _, ok := ast.ObjectListOf(n); !ok {
+		return posErrorf(n.Pos(), "%s: cannot decode %s into %s", name, nodeName(n), rv.Type())
+	}
+
+	v, err := d.orderedValue(name, n)
+	if err != nil {
+		return err
+	}
+	rv.Set(reflect.ValueOf(mergeOrdered(rv.Interface(), v)))
+	return nil
+}
+
+// orderedValue returns the Go value of n like value does, with OrderedMaps
+// for objects
+func (d *decoder) orderedValue(name string, n ast.Node) (interface{}, error) {
+	switch t := n.(type) {
+	case *ast.File:
+		return d.orderedValue(name, t.Node)
+	case *ast.ObjectType:
+		if t.List == nil {
+			return OrderedMap{}, nil
+		}
+		return d.orderedValue(name, t.List)
+	case *ast.ObjectList:
+		if err := d.onBlock(t); err != nil {
+			return nil, err
+		}
+
+		keys, values := fields(t)
+		if err := d.limitLen(name, t.Pos(), "MaxMapSize", len(keys)); err != nil {
+			return nil, err
+		}
+
+		m := make(OrderedMap, 0, len(keys))
+		for _, key := range keys {
+			if err := d.countValue(name+"."+ast.PathKey(key), values[key][0].Pos()); err != nil {
+				return nil, err
+			}
+
+			v, err := d.repeatedValue(name+"."+ast.PathKey(key), values[key], d.orderedValue)
+			if err != nil {
+				return nil, err
+			}
+			m = append(m, MapItem{Key: key, Value: v})
+		}
+		return m, nil
+	case *ast.ListType:
+		if err := d.limitLen(name, t.Pos(), "MaxSliceLen", len(t.List)); err != nil {
+			return nil, err
+		}
+
+		list := make([]interface{}, 0, len(t.List))
+		for i, elem := range t.List {
+			if err := d.countValue(name, elem.Pos()); err != nil {
+				return nil, err
+			}
+			v, err := d.orderedValue(fmt.Sprintf("%s[%d]", name, i), elem)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, v)
+		}
+		return list, nil
+	}
+	return d.value(name, n)
+}
+
+// mergeOrdered merges the OrderedMap b into a, otherwise b replaces a
+func mergeOrdered(a, b interface{}) interface{} {
+	ma, ok := a.(OrderedMap)
+	if !ok {
+		return b
+	}
+
+	mb, ok := b.(OrderedMap)
+	if !ok {
+		return b
+	}
+
+	for _, item := range mb {
+		if i := ma.index(item.Key); i >= 0 {
+			ma[i].Value = mergeOrdered(ma[i].Value, item.Value)
+		} else {
+			ma = append(ma, item)
+		}
+	}
+	return ma
+}
+
+// encodeOrdered adds the items of the OrderedMap rv to list, in order
+func encodeOrdered(list *ast.ObjectList, name string, rv reflect.Value) error {
+	for _, item := range rv.Interface().(OrderedMap) {
+		if err := encodeItems(list, name+"."+ast.PathKey(item.Key), item.Key, reflect.ValueOf(item.Value)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package hcl
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/fatih/hcl/parser"
+)
+
+func TestDecodeOrderedMap(t *testing.T) {
+	src := `stage "fetch" {
+  retries = 3
+}
+
+stage "build" {
+  image = "golang"
+  env = {
+    Z = "1"
+    A = "2"
+  }
+}
+
+stage "fetch" {
+  timeout = 10
+}
+
+name = "pipeline"
+steps = ["lint", "test"]
+`
+	var out OrderedMap
+	if err := Decode(&out, src); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := OrderedMap{
+		{"stage", OrderedMap{
+			{"fetch", []interface{}{OrderedMap{{"retries", 3}}, OrderedMap{{"timeout", 10}}}},
+			{"build", OrderedMap{
+				{"image", "golang"},
+				{"env", OrderedMap{{"Z", "1"}, {"A", "2"}}},
+			}},
+		}},
+		{"name", "pipeline"},
+		{"steps", []interface{}{"lint", "test"}},
+	}
+	if !reflect.DeepEqual(expected, out) {
+		t.Errorf("\nwant: %v\ngot:  %v", expected, out)
+	}
+
+	if want := []string{"stage", "name", "steps"}; !reflect.DeepEqual(want, out.Keys()) {
+		t.Errorf("want keys %q, got %q", want, out.Keys())
+	}
+	if v, ok := out.Get("name"); !ok || v != "pipeline" {
+		t.Errorf("unexpected name: %v, %t", v, ok)
+	}
+	if _, ok := out.Get("missing"); ok {
+		t.Error("expected no value of a missing key")
+	}
+
+	// the encoder keeps the order of the map
+	res, err := Marshal(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var again OrderedMap
+	if err := Decode(&again, string(res)); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(expected, again) {
+		t.Errorf("unexpected result after encoding:\n%s", res)
+	}
+	if i, j := strings.Index(string(res), "build"), strings.Index(string(res), "fetch"); i < j {
+		t.Errorf("want fetch before build:\n%s", res)
+	}
+}
+
+func TestDecodeOrderedMapField(t *testing.T) {
+	var out struct {
+		Pipeline OrderedMap `hcl:"pipeline"`
+		Name     string     `hcl:"name"`
+	}
+
+	src := "pipeline {\n  lint = true\n}\npipeline {\n  test = true\n  build = true\n}\nname = \"ci\""
+	if err := DecodeStrict(&out, src); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"lint", "test", "build"}; !reflect.DeepEqual(want, out.Pipeline.Keys()) {
+		t.Errorf("want keys %q, got %q", want, out.Pipeline.Keys())
+	}
+
+	var invalid struct {
+		Pipeline OrderedMap `hcl:"pipeline"`
+	}
+	err := Decode(&invalid, `pipeline = [1]`)
+	if err == nil || err.Error() != "At 1:12: root.pipeline: cannot decode list into hcl.OrderedMap" {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	f, err := parser.Parse([]byte(`pipeline = "x"`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if errs := CheckTypes(f, &invalid); len(errs) != 1 {
+		t.Errorf("want a type error, got %v", errs)
+	}
+}
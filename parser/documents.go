@@ -0,0 +1,143 @@
+package parser
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+
+	"github.com/fatih/hcl/ast"
+	"github.com/fatih/hcl/scanner"
+	"github.com/fatih/hcl/token"
+)
+
+// ParseDocuments parses the HCL documents of src, separated by lines of
+// "---", with DefaultConfig. See Config.ParseDocuments.
+func ParseDocuments(src []byte) ([]*ast.File, error) {
+	return DefaultConfig.ParseDocuments(src)
+}
+
+// ParseDocuments parses the documents of src, separated by lines consisting
+// of "---", i.e. snapshots of a configuration appended to a log, and returns
+// a syntax tree per document. See DocumentReader.
+func (c *Config) ParseDocuments(src []byte) ([]*ast.File, error) {
+	src, err := Transcode(src, c.Encoding)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []*ast.File
+	r := c.NewDocumentReader(bytes.NewReader(src))
+	for {
+		f, err := r.Next()
+		if err == io.EOF {
+			return files, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, f)
+	}
+}
+
+// DocumentReader reads the documents of a stream of UTF-8 HCL documents
+// separated by lines consisting of "---", one at a time, so streams of any
+// size can be read.
+//
+// A line of "---" within a heredoc or a block comment, which isn't
+// terminated before the line, is part of the document. Documents consisting
+// of white space only, such as before a leading separator, are skipped. The
+// positions of the syntax trees and errors are positions in the stream.
+type DocumentReader struct {
+	config *Config
+	r      *bufio.Reader
+	pos    token.Pos // position of the start of the next document
+	err    error     // error reading the stream, returned after the last document
+}
+
+// NewDocumentReader returns a DocumentReader parsing the documents of r with
+// DefaultConfig.
+func NewDocumentReader(r io.Reader) *DocumentReader {
+	return DefaultConfig.NewDocumentReader(r)
+}
+
+// NewDocumentReader returns a DocumentReader parsing the documents of r with
+// the syntax of c. The Encoding of c is ignored, the stream must be UTF-8.
+func (c *Config) NewDocumentReader(r io.Reader) *DocumentReader {
+	return &DocumentReader{
+		config: c,
+		r:      bufio.NewReader(r),
+		pos:    token.Pos{Filename: c.Filename, Line: 1, Column: 1},
+	}
+}
+
+// Next returns the syntax tree of the next document, or io.EOF after the
+// last one. A syntax error doesn't end the stream, the documents after the
+// broken one can still be read.
+func (d *DocumentReader) Next() (*ast.File, error) {
+	for {
+		if d.err != nil {
+			return nil, d.err
+		}
+
+		src, start := d.document()
+		if len(bytes.TrimSpace(src)) == 0 {
+			continue
+		}
+
+		p := d.config.newParser(src)
+		p.sc.StartAt(start)
+		return p.Parse()
+	}
+}
+
+// document reads the next document and its separator, and returns the
+// document and the position of its start
+func (d *DocumentReader) document() ([]byte, token.Pos) {
+	start := d.pos
+	var src []byte
+	for {
+		line, err := d.r.ReadBytes('\n')
+		d.pos.Offset += len(line)
+		if len(line) > 0 && line[len(line)-1] == '\n' {
+			d.pos.Line++
+		}
+
+		if isSeparator(line) && !unterminated(src) {
+			return src, start
+		}
+		src = append(src, line...)
+
+		if err != nil {
+			d.err = err
+			if len(bytes.TrimSpace(src)) > 0 {
+				// the error is returned by the next call of Next
+				return src, start
+			}
+			return nil, start
+		}
+	}
+}
+
+// isSeparator reports whether line is a line of "---"
+func isSeparator(line []byte) bool {
+	return string(bytes.TrimRight(line, " \t\r\n")) == "---"
+}
+
+// unterminated reports whether src ends within a heredoc or a block comment,
+// which a separator can't end
+func unterminated(src []byte) bool {
+	var errPos token.Pos
+	sc := scanner.New(src)
+	sc.Error = func(pos token.Pos, msg string) {
+		errPos = pos
+	}
+
+	var last token.Token
+	for tok := sc.Scan(); tok.Type != token.EOF; tok = sc.Scan() {
+		last = tok
+	}
+
+	// the token of an unterminated heredoc or comment reaches the end
+	return errPos.IsValid() && (last.Type == token.HEREDOC || last.Type == token.COMMENT) &&
+		last.Pos.Offset+len(last.Text) >= len(src)
+}
@@ -0,0 +1,88 @@
+package parser
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/fatih/hcl/ast"
+)
+
+func TestParseDocuments(t *testing.T) {
+	src := `---
+a = 1
+---
+b = <<EOT
+---
+EOT
+/* a comment
+---
+*/
+c = 2
+---   
+
+---
+d { e = true }
+`
+	files, err := ParseDocuments([]byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []struct {
+		keys []string
+		pos  string
+	}{
+		{[]string{"a"}, "2:1"},
+		{[]string{"b", "c"}, "4:1"},
+		{[]string{"d"}, "14:1"},
+	}
+	if len(files) != len(expected) {
+		t.Fatalf("want %d documents, got %d", len(expected), len(files))
+	}
+
+	for i, f := range files {
+		items := f.Node.(*ast.ObjectList).Items
+		var keys []string
+		for _, item := range items {
+			keys = append(keys, item.Keys[0].Token.Text)
+		}
+		if strings.Join(keys, " ") != strings.Join(expected[i].keys, " ") {
+			t.Errorf("document %d: want keys %q, got %q", i, expected[i].keys, keys)
+		}
+		if pos := items[0].Pos().String(); pos != expected[i].pos {
+			t.Errorf("document %d: want position %s, got %s", i, expected[i].pos, pos)
+		}
+	}
+
+	// the offset and column of the value are positions in the stream
+	lit := files[2].Node.(*ast.ObjectList).Items[0].Val.(*ast.ObjectType).List.Items[0].Val
+	if pos := lit.Pos(); pos.Offset != strings.Index(src, "true") || pos.Column != 9 {
+		t.Errorf("unexpected position of true: %#v", pos)
+	}
+}
+
+func TestDocumentReader(t *testing.T) {
+	r := NewDocumentReader(strings.NewReader("a = 1\n---\nb = [\n---\nc = 3"))
+
+	if _, err := r.Next(); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := r.Next()
+	if err == nil || err.Error() != "At 4:1: list opened at 3:5 not terminated, expected: ] got: EOF" {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	f, err := r.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pos := f.Node.Pos().String(); pos != "5:1" {
+		t.Errorf("want the last document at 5:1, got %s", pos)
+	}
+
+	if _, err := r.Next(); err != io.EOF {
+		t.Errorf("want io.EOF, got %v", err)
+	}
+}
@@ -0,0 +1,169 @@
+package parser
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/fatih/hcl/ast"
+	"github.com/fatih/hcl/token"
+)
+
+// DuplicatePolicy is the handling of attributes assigned more than once in
+// the same object, i.e. a = 1 followed by a = 2. Assignments of objects, such
+// as a = { b = 1 }, and blocks aren't attributes, they're merged by the
+// decoder.
+type DuplicatePolicy int
+
+const (
+	// KeepDuplicates keeps all assignments in the tree. The decoder appends
+	// their values to slices, otherwise the last value wins.
+	KeepDuplicates DuplicatePolicy = iota
+
+	// ErrorOnDuplicates fails with a *PosError of a *DuplicateError.
+	ErrorOnDuplicates
+
+	// FirstWins and LastWins keep the first or the last assignment only.
+	FirstWins
+	LastWins
+
+	// AppendDuplicates replaces the assignments by one assignment of a list,
+	// at the position of the first one, of their values, with the elements
+	// of lists appended one by one: a = [1] and a = 2 become a = [1, 2].
+	AppendDuplicates
+)
+
+var duplicatePolicies = [...]string{
+	KeepDuplicates:    "KeepDuplicates",
+	ErrorOnDuplicates: "ErrorOnDuplicates",
+	FirstWins:         "FirstWins",
+	LastWins:          "LastWins",
+	AppendDuplicates:  "AppendDuplicates",
+}
+
+func (d DuplicatePolicy) String() string {
+	if 0 <= d && int(d) < len(duplicatePolicies) {
+		return duplicatePolicies[d]
+	}
+	return "DuplicatePolicy(" + strconv.Itoa(int(d)) + ")"
+}
+
+// DuplicateError is the error of a PosError for an attribute assigned more
+// than once with the policy ErrorOnDuplicates. The PosError has the position
+// of the repeated assignment.
+type DuplicateError struct {
+	Key   string    // unquoted key of the attribute
+	First token.Pos // position of the first assignment
+}
+
+func (e *DuplicateError) Error() string {
+	return fmt.Sprintf("duplicate attribute %s, first defined at %s", e.Key, e.First)
+}
+
+// ResolveDuplicates applies policy to the attributes of n assigned more than
+// once, modifying n in place, i.e. for trees of package json/parser, which
+// have no Config. With ErrorOnDuplicates, it returns the error of the first
+// repeated assignment and leaves n unchanged.
+func ResolveDuplicates(n ast.Node, policy DuplicatePolicy) error {
+	if errs := resolveDuplicates(n, policy); len(errs) > 0 {
+		return errs[0]
+	}
+	return nil
+}
+
+// resolveDuplicates applies policy to n and returns the errors of all
+// repeated assignments with ErrorOnDuplicates, in source order
+func resolveDuplicates(n ast.Node, policy DuplicatePolicy) []error {
+	if policy == KeepDuplicates {
+		return nil
+	}
+
+	var dups []*PosError
+	ast.Walk(n, func(n ast.Node) (ast.Node, bool) {
+		list, ok := n.(*ast.ObjectList)
+		if !ok {
+			return n, true
+		}
+
+		groups := make(map[string][]*ast.ObjectItem)
+		var keys []string
+		for _, item := range list.Items {
+			if key, ok := attributeKey(item); ok {
+				if groups[key] == nil {
+					keys = append(keys, key)
+				}
+				groups[key] = append(groups[key], item)
+			}
+		}
+
+		drop := make(map[*ast.ObjectItem]bool)
+		for _, key := range keys {
+			items := groups[key]
+			if len(items) < 2 {
+				continue
+			}
+
+			switch policy {
+			case ErrorOnDuplicates:
+				for _, item := range items[1:] {
+					dups = append(dups, &PosError{Pos: item.Pos(), Err: &DuplicateError{Key: key, First: items[0].Pos()}})
+				}
+			case FirstWins:
+				for _, item := range items[1:] {
+					drop[item] = true
+				}
+			case LastWins:
+				for _, item := range items[:len(items)-1] {
+					drop[item] = true
+				}
+			case AppendDuplicates:
+				combined := &ast.ListType{}
+				for _, item := range items {
+					if l, ok := item.Val.(*ast.ListType); ok {
+						combined.List = append(combined.List, l.List...)
+					} else {
+						combined.List = append(combined.List, item.Val)
+					}
+				}
+				for _, item := range items[1:] {
+					drop[item] = true
+				}
+				items[0].Val = combined
+			}
+		}
+
+		if len(drop) > 0 {
+			kept := list.Items[:0]
+			for _, item := range list.Items {
+				if !drop[item] {
+					kept = append(kept, item)
+				}
+			}
+			list.Items = kept
+		}
+		return list, true
+	})
+
+	// the objects are walked before the objects inside of them
+	sort.SliceStable(dups, func(i, j int) bool {
+		return dups[i].Pos.Offset < dups[j].Pos.Offset
+	})
+
+	var errs []error
+	for _, err := range dups {
+		errs = append(errs, err)
+	}
+	return errs
+}
+
+// attributeKey returns the unquoted key of item, if it's an attribute
+func attributeKey(item *ast.ObjectItem) (string, bool) {
+	if !item.Assign.IsValid() || len(item.Keys) != 1 {
+		return "", false
+	}
+	if _, ok := item.Val.(*ast.ObjectType); ok {
+		return "", false
+	}
+
+	return item.Keys[0].Token.Value(), true
+}
@@ -0,0 +1,97 @@
+package parser
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/fatih/hcl/ast"
+)
+
+func TestDuplicates(t *testing.T) {
+	src := `a = 1
+b = [2]
+"a" = 3
+c = { x = 1 }
+c = { y = 2 }
+d {
+  e = 1
+  e = 2
+}
+b = 4
+`
+	cases := []struct {
+		policy DuplicatePolicy
+		want   string
+	}{
+		{KeepDuplicates, "a=1 b=[2] a=3 c={} c={} d{e=1 e=2} b=4"},
+		{FirstWins, "a=1 b=[2] c={} c={} d{e=1}"},
+		{LastWins, "a=3 c={} c={} d{e=2} b=4"},
+		{AppendDuplicates, "a=[1,3] b=[2,4] c={} c={} d{e=[1,2]}"},
+	}
+
+	for _, c := range cases {
+		f, err := (&Config{Duplicates: c.policy}).Parse([]byte(src))
+		if err != nil {
+			t.Errorf("%s: %s", c.policy, err)
+			continue
+		}
+		if got := itemsString(f.Node.(*ast.ObjectList)); got != c.want {
+			t.Errorf("%s:\nwant: %s\ngot:  %s", c.policy, c.want, got)
+		}
+	}
+}
+
+func TestDuplicatesError(t *testing.T) {
+	src := "a = 1\nb {\n  c = 1\n  c = 2\n}\n\"a\" = 3"
+	_, err := (&Config{Duplicates: ErrorOnDuplicates}).Parse([]byte(src))
+	if err == nil || err.Error() != "At 4:3: duplicate attribute c, first defined at 3:3" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var dup *DuplicateError
+	if !errors.As(err, &dup) || dup.Key != "c" || dup.First.Line != 3 {
+		t.Errorf("unexpected error: %#v", dup)
+	}
+
+	f, errs := (&Config{Duplicates: ErrorOnDuplicates}).ParseWithRecovery([]byte(src))
+	if f == nil || len(errs) != 2 {
+		t.Fatalf("want a tree and 2 errors, got %v", errs)
+	}
+	if errs[1].Error() != "At 6:1: duplicate attribute a, first defined at 1:1" {
+		t.Errorf("unexpected error: %s", errs[1])
+	}
+
+	if _, err := (&Config{Duplicates: ErrorOnDuplicates}).Parse([]byte("a = 1\nb = 1\nc {}\nc {}")); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+}
+
+// itemsString returns the items of list in a compact form for comparisons
+func itemsString(list *ast.ObjectList) string {
+	var items []string
+	for _, item := range list.Items {
+		items = append(items, itemString(item))
+	}
+	return strings.Join(items, " ")
+}
+
+func itemString(item *ast.ObjectItem) string {
+	key := strings.Trim(item.Keys[0].Token.Text, `"`)
+	switch v := item.Val.(type) {
+	case *ast.ObjectType:
+		if item.Assign.IsValid() {
+			return key + "={}"
+		}
+		return key + "{" + itemsString(v.List) + "}"
+	case *ast.ListType:
+		var elems []string
+		for _, elem := range v.List {
+			elems = append(elems, elem.(*ast.LiteralType).Token.Text)
+		}
+		return key + "=[" + strings.Join(elems, ",") + "]"
+	case *ast.LiteralType:
+		return key + "=" + v.Token.Text
+	}
+	return key
+}
@@ -0,0 +1,100 @@
+package parser
+
+import (
+	"bytes"
+	"errors"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/fatih/hcl/ast"
+)
+
+// Encoding is the character encoding of source text.
+type Encoding int
+
+const (
+	// DetectEncoding decodes source starting with a UTF-16 byte order mark
+	// as UTF-16 and any other source as UTF-8.
+	DetectEncoding Encoding = iota
+
+	UTF8
+	UTF16LE
+	UTF16BE
+)
+
+func (e Encoding) String() string {
+	switch e {
+	case DetectEncoding:
+		return "detect"
+	case UTF8:
+		return "UTF-8"
+	case UTF16LE:
+		return "UTF-16LE"
+	case UTF16BE:
+		return "UTF-16BE"
+	}
+	return "unknown"
+}
+
+var (
+	bomUTF16LE = []byte{0xff, 0xfe}
+	bomUTF16BE = []byte{0xfe, 0xff}
+)
+
+// Transcode returns src in the encoding enc as UTF-8. The byte order mark of
+// UTF-16 source is removed.
+func Transcode(src []byte, enc Encoding) ([]byte, error) {
+	if enc == DetectEncoding {
+		switch {
+		case bytes.HasPrefix(src, bomUTF16LE):
+			enc = UTF16LE
+		case bytes.HasPrefix(src, bomUTF16BE):
+			enc = UTF16BE
+		default:
+			enc = UTF8
+		}
+	}
+
+	switch enc {
+	case UTF8:
+		return src, nil
+	case UTF16LE, UTF16BE:
+	default:
+		return nil, errors.New("unknown encoding " + enc.String())
+	}
+
+	if len(src)%2 != 0 {
+		return nil, errors.New("invalid " + enc.String() + " source: odd number of bytes")
+	}
+
+	units := make([]uint16, 0, len(src)/2)
+	for i := 0; i < len(src); i += 2 {
+		if enc == UTF16LE {
+			units = append(units, uint16(src[i])|uint16(src[i+1])<<8)
+		} else {
+			units = append(units, uint16(src[i])<<8|uint16(src[i+1]))
+		}
+	}
+
+	if len(units) > 0 && units[0] == 0xfeff {
+		units = units[1:]
+	}
+
+	runes := utf16.Decode(units)
+	buf := make([]byte, 0, len(runes))
+	var tmp [utf8.UTFMax]byte
+	for _, r := range runes {
+		n := utf8.EncodeRune(tmp[:], r)
+		buf = append(buf, tmp[:n]...)
+	}
+	return buf, nil
+}
+
+// ParseWithEncoding parses src in the encoding enc. Positions of the syntax
+// tree refer to the source transcoded to UTF-8. It calls Config.Parse with
+// the default settings and the given encoding.
+func ParseWithEncoding(src []byte, enc Encoding) (*ast.File, error) {
+	c := DefaultConfig
+	c.Encoding = enc
+	return c.Parse(src)
+}
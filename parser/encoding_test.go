@@ -0,0 +1,71 @@
+package parser
+
+import (
+	"testing"
+	"unicode/utf16"
+
+	"github.com/fatih/hcl/ast"
+)
+
+// utf16Bytes encodes s as UTF-16 with a byte order mark
+func utf16Bytes(s string, bigEndian bool) []byte {
+	var buf []byte
+	for _, u := range utf16.Encode([]rune("\ufeff" + s)) {
+		if bigEndian {
+			buf = append(buf, byte(u>>8), byte(u))
+		} else {
+			buf = append(buf, byte(u), byte(u>>8))
+		}
+	}
+	return buf
+}
+
+func TestTranscode(t *testing.T) {
+	src := "name = \"ünïcödé 𝄞\""
+
+	var cases = []struct {
+		src []byte
+		enc Encoding
+		err string
+	}{
+		{[]byte(src), DetectEncoding, ""},
+		{[]byte(src), UTF8, ""},
+		{utf16Bytes(src, false), DetectEncoding, ""},
+		{utf16Bytes(src, true), DetectEncoding, ""},
+		{utf16Bytes(src, false), UTF16LE, ""},
+		{utf16Bytes(src, true)[2:], UTF16BE, ""},
+		{utf16Bytes(src, false)[1:], UTF16LE, "invalid UTF-16LE source: odd number of bytes"},
+	}
+
+	for _, c := range cases {
+		out, err := Transcode(c.src, c.enc)
+		if c.err != "" {
+			if err == nil || err.Error() != c.err {
+				t.Errorf("%s: want error %q, got: %v", c.enc, c.err, err)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("%s: %s", c.enc, err)
+			continue
+		}
+		equals(t, src, string(out))
+	}
+}
+
+func TestParseUTF16(t *testing.T) {
+	f, err := Parse(utf16Bytes("a = 1\nb = \"ü\"\n", false))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	items := f.Node.(*ast.ObjectList).Items
+	equals(t, 2, len(items))
+	equals(t, `"ü"`, items[1].Val.(*ast.LiteralType).Token.Text)
+
+	_, err = ParseWithEncoding(utf16Bytes("a = [", true), UTF16BE)
+	if err == nil {
+		t.Fatal("expected a syntax error")
+	}
+}
@@ -0,0 +1,115 @@
+package parser
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/fatih/hcl/token"
+)
+
+// PosError is a parse error that contains a position. The Err of syntax
+// errors found by the scanner or the parser is a *ParseError.
+type PosError struct {
+	Pos token.Pos
+	Err error
+}
+
+func (e *PosError) Error() string {
+	return fmt.Sprintf("At %s: %s", e.Pos, e.Err)
+}
+
+// Unwrap returns e.Err, i.e. to get the *ParseError with errors.As.
+func (e *PosError) Unwrap() error {
+	return e.Err
+}
+
+// ParseError is a syntax error with the token found and the tokens expected
+// instead, i.e. for editors and tools embedding the parser. Its Error method
+// returns the message without the position, which the PosError adds.
+type ParseError struct {
+	Pos token.Pos
+
+	// Token is the offending token, the zero Token for errors of the
+	// scanner, which are about single characters.
+	Token token.Token
+
+	// Expected are the tokens valid at the position, if they are known.
+	Expected []token.Type
+
+	Msg string
+}
+
+func (e *ParseError) Error() string {
+	return e.Msg
+}
+
+// Snippet returns the line of src containing the error, followed by a line
+// with a caret pointing at the column of the error:
+//
+//	a = [1 2}
+//	        ^
+//
+// src must be the parsed source, after any transcoding of Config.Encoding.
+func (e *ParseError) Snippet(src []byte) string {
+	offset := e.Pos.Offset
+	if offset > len(src) {
+		offset = len(src)
+	}
+
+	start := bytes.LastIndexByte(src[:offset], '\n') + 1
+	end := bytes.IndexByte(src[offset:], '\n')
+	if end < 0 {
+		end = len(src)
+	} else {
+		end += offset
+	}
+	line := strings.TrimSuffix(string(src[start:end]), "\r")
+
+	// the caret is indented with the tabs of the line, so it lines up
+	// regardless of the tab width
+	var caret bytes.Buffer
+	for _, ch := range string(src[start:offset]) {
+		if ch == '\t' {
+			caret.WriteByte('\t')
+		} else {
+			caret.WriteByte(' ')
+		}
+	}
+	caret.WriteByte('^')
+	return line + "\n" + caret.String()
+}
+
+// syntaxError returns a *PosError with a *ParseError for the unexpected token
+// tok.
+func syntaxError(tok token.Token, expected []token.Type, format string, args ...interface{}) error {
+	return &PosError{Pos: tok.Pos, Err: &ParseError{
+		Pos:      tok.Pos,
+		Token:    tok,
+		Expected: expected,
+		Msg:      fmt.Sprintf(format, args...),
+	}}
+}
+
+// LimitError is the error of a PosError for input exceeding a limit of
+// Config, or of the hcl.DecoderConfig limits MaxValues, MaxSliceLen and
+// MaxMapSize.
+type LimitError struct {
+	Limit string     // name of the limit, i.e. MaxDepth or MaxRepeat
+	Max   int        // value of the limit
+	Token token.Type // repeated token for MaxRepeat
+}
+
+func (e *LimitError) Error() string {
+	switch e.Limit {
+	case "MaxRepeat":
+		return fmt.Sprintf("resource limit exceeded: more than %d consecutive %s tokens", e.Max, e.Token)
+	case "MaxValues":
+		return fmt.Sprintf("resource limit exceeded: more than %d decoded values", e.Max)
+	case "MaxSliceLen":
+		return fmt.Sprintf("resource limit exceeded: more than %d elements in a slice", e.Max)
+	case "MaxMapSize":
+		return fmt.Sprintf("resource limit exceeded: more than %d entries in a map", e.Max)
+	}
+	return fmt.Sprintf("resource limit exceeded: more than %d nested objects and lists", e.Max)
+}
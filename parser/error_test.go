@@ -0,0 +1,93 @@
+package parser
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/fatih/hcl/token"
+)
+
+func TestParseError(t *testing.T) {
+	cases := []struct {
+		src      string
+		err      string
+		tok      token.Type
+		expected []token.Type
+		snippet  string
+	}{
+		{
+			"a = 1,",
+			"At 1:6: expected: IDENT | STRING | ASSIGN | LBRACE got: COMMA",
+			token.COMMA,
+			[]token.Type{token.IDENT, token.STRING, token.ASSIGN, token.LBRACE},
+			"a = 1,\n     ^",
+		},
+		{
+			"a {\n\tb = [1 2}\n}",
+			"At 2:10: list opened at 2:6 not terminated, expected: ] got: RBRACE",
+			token.RBRACE,
+			[]token.Type{token.RBRACK},
+			"\tb = [1 2}\n\t        ^",
+		},
+		{
+			"a = 1\r\nb = }",
+			"At 2:5: unexpected token while parsing value: RBRACE",
+			token.RBRACE,
+			[]token.Type{token.NUMBER, token.FLOAT, token.UNIT, token.BOOL, token.NULL, token.STRING, token.HEREDOC, token.LBRACE, token.LBRACK},
+			"b = }\n    ^",
+		},
+		{
+			"a b = 1",
+			"At 1:5: nested object expected: LBRACE got: ASSIGN",
+			token.ASSIGN,
+			[]token.Type{token.LBRACE},
+			"a b = 1\n    ^",
+		},
+		{
+			// errors of the scanner have no token
+			"a = \"b\"\nc = \"d",
+			"At 2:7: literal opened at 2:5 not terminated, expected: \"",
+			token.ILLEGAL,
+			nil,
+			"c = \"d\n      ^",
+		},
+		{
+			"a = 1\nb = @",
+			"At 2:5: illegal char",
+			token.ILLEGAL,
+			nil,
+			"b = @\n    ^",
+		},
+	}
+
+	for _, c := range cases {
+		_, err := Parse([]byte(c.src))
+		if err == nil || err.Error() != c.err {
+			t.Errorf("%q: want error %q, got: %v", c.src, c.err, err)
+			continue
+		}
+
+		var pe *ParseError
+		if !errors.As(err, &pe) {
+			t.Errorf("%q: expected a *ParseError, got: %#v", c.src, err)
+			continue
+		}
+
+		equals(t, c.tok, pe.Token.Type)
+		equals(t, c.expected, pe.Expected)
+		equals(t, c.snippet, pe.Snippet([]byte(c.src)))
+	}
+}
+
+func TestParseErrorRecovery(t *testing.T) {
+	_, errs := ParseWithRecovery([]byte("a = @\nb = [1 2}\nc = 1"))
+	if len(errs) != 3 {
+		t.Fatalf("want 3 errors, got: %v", errs)
+	}
+
+	for _, err := range errs {
+		if _, ok := err.(*PosError).Err.(*ParseError); !ok {
+			t.Errorf("expected a *ParseError, got: %#v", err)
+		}
+	}
+}
@@ -0,0 +1,30 @@
+//go:build go1.18
+// +build go1.18
+
+package parser
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+func FuzzParse(f *testing.F) {
+	files, err := filepath.Glob(filepath.Join("test-fixtures", "*.hcl"))
+	if err != nil {
+		f.Fatal(err)
+	}
+
+	for _, file := range files {
+		src, err := ioutil.ReadFile(file)
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(src)
+	}
+
+	f.Fuzz(func(t *testing.T, src []byte) {
+		Parse(src)
+		ParseWithRecovery(src)
+	})
+}
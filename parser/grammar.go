@@ -0,0 +1,107 @@
+package parser
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/fatih/hcl/token"
+)
+
+// Production is a rule of a grammar. The expression is written in EBNF as
+// used by the Go specification: terminals are quoted tokens or the names of
+// token classes in upper case, i.e. IDENT, and nonterminals are the names of
+// other productions.
+type Production struct {
+	Name string
+	Expr string
+}
+
+// Grammar describes the syntax accepted by the parser, i.e. to generate
+// documentation or editor grammars. Comments may appear between any tokens
+// and aren't part of the productions.
+type Grammar struct {
+	Syntax      SyntaxVersion
+	Tokens      []token.Type // kinds of tokens used by the productions
+	Productions []Production // starting with the production of a file
+}
+
+// Grammar returns the grammar accepted by the parser for the syntax version
+// v.
+func (v SyntaxVersion) Grammar() *Grammar {
+	elems := `NUMBER | FLOAT | STRING | HEREDOC`
+	if v.Supports(ListBools) {
+		elems += ` | BOOL`
+	}
+	if v.Supports(NullLiterals) {
+		elems += ` | NULL`
+	}
+	if v.Supports(NestedLists) {
+		elems += ` | List`
+	}
+
+	// adjacent strings are only folded on the same line, which can't be
+	// expressed in EBNF
+	value := `NUMBER | FLOAT | BOOL | STRING | HEREDOC | Object | List`
+	tokens := []token.Type{
+		token.IDENT, token.NUMBER, token.FLOAT, token.BOOL, token.NULL, token.STRING, token.HEREDOC,
+		token.LBRACE, token.RBRACE, token.LBRACK, token.RBRACK,
+		token.ASSIGN, token.COMMA, token.COMMENT,
+	}
+	if v.Supports(StringConcat) {
+		value = `NUMBER | FLOAT | BOOL | STRING { [ "+" ] STRING } | HEREDOC | Object | List`
+		elems = strings.Replace(elems, `STRING`, `STRING { "+" STRING }`, 1)
+		tokens = append(tokens, token.ADD)
+	}
+	if v.Supports(UnitLiterals) {
+		value = strings.Replace(value, `FLOAT`, `FLOAT | UNIT`, 1)
+		elems = strings.Replace(elems, `FLOAT`, `FLOAT | UNIT`, 1)
+		tokens = append(tokens, token.UNIT)
+	}
+	if v.Supports(NullLiterals) {
+		value = strings.Replace(value, `BOOL`, `BOOL | NULL`, 1)
+	}
+
+	var list string
+	switch {
+	case v == V1Lenient:
+		// bools are skipped, as is the opening bracket of a nested list
+		elems = `NUMBER | FLOAT | STRING | HEREDOC | NULL | BOOL | "["`
+		list = `"[" { ListElem | "," } "]"`
+	case v.Supports(OptionalListCommas):
+		list = `"[" [ ListElem { ListElem | "," } ] "]"`
+	default:
+		list = `"[" [ ListElem { "," [ ListElem ] } ] "]"`
+	}
+
+	return &Grammar{
+		Syntax: v,
+		Tokens: tokens,
+		Productions: []Production{
+			{"File", `ObjectList`},
+			{"ObjectList", `{ ObjectItem }`},
+			{"ObjectItem", `ObjectKey "=" Value | ObjectKey { ObjectKey } Object`},
+			{"ObjectKey", `IDENT | STRING | NULL`},
+			{"Object", `"{" ObjectList "}"`},
+			{"Value", value},
+			{"List", list},
+			{"ListElem", elems},
+		},
+	}
+}
+
+// EBNF returns the productions of g in EBNF, one per line.
+func (g *Grammar) EBNF() string {
+	width := 0
+	for _, p := range g.Productions {
+		if len(p.Name) > width {
+			width = len(p.Name)
+		}
+	}
+
+	var buf bytes.Buffer
+	for _, p := range g.Productions {
+		fmt.Fprintf(&buf, "%-*s = %s .\n", width, p.Name, p.Expr)
+	}
+	return buf.String()
+}
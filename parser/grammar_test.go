@@ -0,0 +1,236 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/fatih/hcl/scanner"
+	"github.com/fatih/hcl/token"
+)
+
+func TestGrammarEBNF(t *testing.T) {
+	expected := `File       = ObjectList .
+ObjectList = { ObjectItem } .
+ObjectItem = ObjectKey "=" Value | ObjectKey { ObjectKey } Object .
+ObjectKey  = IDENT | STRING | NULL .
+Object     = "{" ObjectList "}" .
+Value      = NUMBER | FLOAT | BOOL | STRING | HEREDOC | Object | List .
+List       = "[" [ ListElem { "," [ ListElem ] } ] "]" .
+ListElem   = NUMBER | FLOAT | STRING | HEREDOC .
+`
+
+	if got := V1Strict.Grammar().EBNF(); got != expected {
+		t.Errorf("want:\n%s\ngot:\n%s", expected, got)
+	}
+}
+
+// TestGrammar checks that the grammars accept the same sources as the parser.
+func TestGrammar(t *testing.T) {
+	sources := []string{
+		`a = 1`,
+		`a = "b" c = 1.5 d = true`,
+		`"a" = 1`,
+		`a "b" c { d = 1 }`,
+		`a { b { } }`,
+		`a = { b = [] }`,
+		`a b = 1`,
+		`a = 1 # comment`,
+		`= 1`,
+		`a = }`,
+		`a = [1, 2]`,
+		`a = [1, 2,]`,
+		`a = [1 2]`,
+		`a = [1,, 2]`,
+		`a = [, 1]`,
+		`a = [true]`,
+		`a = [[1], [2]]`,
+		`a = [{}]`,
+		`a = [1`,
+		`a = "b" "c" d = 1`,
+		`a = "b" + "c" + "d"`,
+		`a = ["b" + "c", "d"]`,
+		`a = "b" +`,
+		`a = "b" + 1`,
+		`a = 1 + 2`,
+		`a = {b = 10s}`,
+		`a = [1.5GB, 80%, -1h30m]`,
+		`a = 0x1F`,
+		`a = null`,
+		`a = [1, null]`,
+		`a = [null true]`,
+		`null = 1`,
+		`null "a" { b = null }`,
+		"a = <<EOF\nb\nEOF\nc = [<<-EOT\n  d\n  EOT\n, 1]",
+	}
+
+	for _, v := range []SyntaxVersion{V1Lenient, V1Strict, Experimental} {
+		g := v.Grammar()
+		prods := make(map[string]*grammarNode)
+		for _, p := range g.Productions {
+			prods[p.Name] = parseGrammarExpr(t, p.Expr)
+		}
+
+		for _, src := range sources {
+			_, err := (&Config{Syntax: v}).Parse([]byte(src))
+
+			toks := grammarTokens(src, v)
+			accepted := false
+			for _, end := range prods["File"].match(prods, toks, 0) {
+				accepted = accepted || end == len(toks)
+			}
+
+			if accepted != (err == nil) {
+				t.Errorf("%s %s: grammar accepts: %t, parser error: %v", v, src, accepted, err)
+			}
+		}
+
+		// all symbols are defined
+		for _, p := range g.Productions {
+			for _, sym := range strings.Fields(p.Expr) {
+				if _, ok := prods[sym]; ok || strings.Trim(sym, "|[]{}()") == "" || strings.HasPrefix(sym, `"`) {
+					continue
+				}
+
+				found := false
+				for _, typ := range g.Tokens {
+					found = found || typ.String() == sym
+				}
+				if !found {
+					t.Errorf("%s: undefined symbol %s in %s", v, sym, p.Name)
+				}
+			}
+		}
+	}
+}
+
+func grammarTokens(src string, v SyntaxVersion) []token.Token {
+	s := scanner.New([]byte(src))
+	s.Error = func(token.Pos, string) {}
+	s.Units = v.Supports(UnitLiterals)
+
+	var toks []token.Token
+	for {
+		tok := s.Scan()
+		switch tok.Type {
+		case token.EOF:
+			return toks
+		case token.COMMENT:
+			continue
+		}
+		toks = append(toks, tok)
+	}
+}
+
+// grammarNode is an EBNF expression: an alternative ('|'), sequence ('s'),
+// option ('['), repetition ('{'), terminal ('"') or name ('n')
+type grammarNode struct {
+	kind byte
+	text string
+	list []*grammarNode
+}
+
+func parseGrammarExpr(t *testing.T, expr string) *grammarNode {
+	fields := strings.Fields(expr)
+	var alt func() *grammarNode
+	alt = func() *grammarNode {
+		n := &grammarNode{kind: '|'}
+		for {
+			seq := &grammarNode{kind: 's'}
+			for len(fields) > 0 && !strings.Contains("|)]}", fields[0]) {
+				f := fields[0]
+				fields = fields[1:]
+				switch f {
+				case "(", "[", "{":
+					inner := alt()
+					fields = fields[1:] // closing bracket
+					if f == "(" {
+						seq.list = append(seq.list, inner)
+					} else {
+						seq.list = append(seq.list, &grammarNode{kind: f[0], list: []*grammarNode{inner}})
+					}
+				default:
+					if strings.HasPrefix(f, `"`) {
+						seq.list = append(seq.list, &grammarNode{kind: '"', text: strings.Trim(f, `"`)})
+					} else {
+						seq.list = append(seq.list, &grammarNode{kind: 'n', text: f})
+					}
+				}
+			}
+			n.list = append(n.list, seq)
+
+			if len(fields) == 0 || fields[0] != "|" {
+				return n
+			}
+			fields = fields[1:]
+		}
+	}
+
+	n := alt()
+	if len(fields) > 0 {
+		t.Fatalf("invalid expression %s", expr)
+	}
+	return n
+}
+
+// match returns the positions after all possible matches of n in toks
+// starting at i
+func (n *grammarNode) match(prods map[string]*grammarNode, toks []token.Token, i int) []int {
+	switch n.kind {
+	case '"':
+		if i < len(toks) && toks[i].Text == n.text {
+			return []int{i + 1}
+		}
+	case 'n':
+		if p, ok := prods[n.text]; ok {
+			return p.match(prods, toks, i)
+		}
+		if i < len(toks) && toks[i].Type.String() == n.text {
+			return []int{i + 1}
+		}
+	case '|':
+		var res []int
+		for _, alt := range n.list {
+			res = union(res, alt.match(prods, toks, i))
+		}
+		return res
+	case 's':
+		res := []int{i}
+		for _, elem := range n.list {
+			var next []int
+			for _, j := range res {
+				next = union(next, elem.match(prods, toks, j))
+			}
+			res = next
+		}
+		return res
+	case '[':
+		return union([]int{i}, n.list[0].match(prods, toks, i))
+	case '{':
+		res, todo := []int{i}, []int{i}
+		for len(todo) > 0 {
+			j := todo[0]
+			todo = todo[1:]
+			for _, k := range n.list[0].match(prods, toks, j) {
+				if len(union(res, []int{k})) > len(res) {
+					res = append(res, k)
+					todo = append(todo, k)
+				}
+			}
+		}
+		return res
+	}
+	return nil
+}
+
+func union(a, b []int) []int {
+	for _, j := range b {
+		found := false
+		for _, i := range a {
+			found = found || i == j
+		}
+		if !found {
+			a = append(a, j)
+		}
+	}
+	return a
+}
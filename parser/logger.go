@@ -0,0 +1,10 @@
+package parser
+
+// Logger records the phases of loading a configuration, i.e. to diagnose
+// slow loads in production. *slog.Logger implements it. The message names the
+// phase, such as "hcl: parsed", and args are alternating keys and values,
+// such as "duration" with a time.Duration, "bytes" and "tokens" with ints or
+// "cache" with "hit" or "miss".
+type Logger interface {
+	Debug(msg string, args ...interface{})
+}
@@ -5,6 +5,7 @@ package parser
 import (
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/fatih/hcl/ast"
 	"github.com/fatih/hcl/scanner"
@@ -18,9 +19,33 @@ type Parser struct {
 	tok       token.Token
 	commaPrev token.Token
 
-	comments    []*ast.CommentGroup
-	leadComment *ast.CommentGroup // last lead comment
-	lineComment *ast.CommentGroup // last line comment
+	comments     []*ast.CommentGroup
+	leadComment  *ast.CommentGroup // last lead comment
+	lineComment  *ast.CommentGroup // last line comment
+	trailComment *ast.CommentGroup // last comment right before a "}"
+
+	discardComments bool
+
+	tokens int // number of tokens scanned, for Config.Logger
+
+	// recover enables error recovery, errs collects the errors found while
+	// recovering and depth is the current object nesting level.
+	recover   bool
+	errs      []error
+	maxErrors int
+	depth     int
+
+	syntax SyntaxVersion
+
+	// limits of Config, the current nesting and number of repeated tokens.
+	// Once a limit is exceeded, limitErr is set and only EOF is scanned.
+	maxDepth  int
+	maxRepeat int
+	nesting   int
+	repeat    int
+	limitErr  error
+
+	scanErr error // first error of the scanner, if not recovering
 
 	enableTrace bool
 	indent      int
@@ -28,15 +53,51 @@ type Parser struct {
 }
 
 func newParser(src []byte) *Parser {
-	return &Parser{
+	p := &Parser{
 		sc: scanner.New(src),
 	}
+	p.sc.Error = p.scanError
+	return p
+}
+
+// addError collects the error err while recovering. Once Config.MaxErrors
+// errors are collected, further errors are dropped and only EOF is scanned.
+func (p *Parser) addError(err error) {
+	if p.maxErrors > 0 && len(p.errs) >= p.maxErrors {
+		return
+	}
+	p.errs = append(p.errs, err)
+}
+
+// valueTokens are the tokens starting a value
+var valueTokens = []token.Type{
+	token.NUMBER, token.FLOAT, token.UNIT, token.BOOL, token.NULL, token.STRING, token.HEREDOC, token.LBRACE, token.LBRACK,
+}
+
+// scanError records an error of the scanner. All errors are collected while
+// recovering, otherwise Parse returns the first one.
+func (p *Parser) scanError(pos token.Pos, msg string) {
+	err := &PosError{Pos: pos, Err: &ParseError{Pos: pos, Msg: msg}}
+	if p.recover {
+		p.addError(err)
+	} else if p.scanErr == nil {
+		p.scanErr = err
+	}
 }
 
 // Parse returns the fully parsed source and returns the abstract syntax tree.
+// It calls Config.Parse with the default settings.
 func Parse(src []byte) (*ast.File, error) {
-	p := newParser(src)
-	return p.Parse()
+	return DefaultConfig.Parse(src)
+}
+
+// ParseWithRecovery parses the source like Parse, but instead of stopping at
+// the first syntax error it skips to the beginning of the next object item
+// and continues. It always returns the (possibly partial) abstract syntax
+// tree, along with every error encountered. Errors are of type *PosError.
+// It calls Config.ParseWithRecovery with the default settings.
+func ParseWithRecovery(src []byte) (*ast.File, []error) {
+	return DefaultConfig.ParseWithRecovery(src)
 }
 
 var errEofToken = errors.New("EOF token found")
@@ -46,19 +107,60 @@ func (p *Parser) Parse() (*ast.File, error) {
 	f := &ast.File{}
 	var err error
 	f.Node, err = p.objectList()
+	if p.limitErr != nil {
+		return nil, p.limitErr
+	}
+	if p.scanErr != nil {
+		return nil, p.scanErr
+	}
 	if err != nil {
 		return nil, err
 	}
 
 	f.Comments = p.comments
+	f.Directives = p.fileDirectives(f)
+	f.EndPos = p.tok.Pos // objectList stops at EOF
 	return f, nil
 }
 
+// fileDirectives returns the directives of the comments, which aren't the
+// lead or line comment of an item
+func (p *Parser) fileDirectives(f *ast.File) []*ast.CommentDirective {
+	attached := make(map[*ast.CommentGroup]bool)
+	ast.Walk(f.Node, func(n ast.Node) (ast.Node, bool) {
+		switch t := n.(type) {
+		case *ast.ObjectItem:
+			attached[t.LeadComment] = true
+			attached[t.LineComment] = true
+		case *ast.LiteralType:
+			attached[t.LineComment] = true
+		}
+		return n, true
+	})
+
+	var ds []*ast.CommentDirective
+	for _, c := range p.comments {
+		if !attached[c] {
+			ds = append(ds, c.Directives()...)
+		}
+	}
+	return ds
+}
+
 func (p *Parser) objectList() (*ast.ObjectList, error) {
 	defer un(trace(p, "ParseObjectList"))
 	node := &ast.ObjectList{}
 
 	for {
+		// a closing brace ends a nested object, leave it to objectType
+		if p.depth > 0 {
+			tok := p.scan()
+			p.unscan()
+			if tok.Type == token.RBRACE {
+				break
+			}
+		}
+
 		n, err := p.objectItem()
 		if err == errEofToken {
 			break // we are finished
@@ -67,7 +169,16 @@ func (p *Parser) objectList() (*ast.ObjectList, error) {
 		// we don't return a nil node, because might want to use already
 		// collected items.
 		if err != nil {
-			return node, err
+			if !p.recover || p.limitErr != nil {
+				return node, err
+			}
+
+			if _, ok := err.(*PosError); !ok {
+				err = &PosError{Pos: p.tok.Pos, Err: err}
+			}
+			p.addError(err)
+			p.synchronize()
+			continue
 		}
 
 		node.Add(n)
@@ -75,6 +186,44 @@ func (p *Parser) objectList() (*ast.ObjectList, error) {
 	return node, nil
 }
 
+// synchronize skips the tokens of an erroneous object item. It stops right
+// before an identifier or string which starts a new line outside of any
+// brackets, before the brace closing the current object or at EOF.
+func (p *Parser) synchronize() {
+	defer un(trace(p, "Synchronize"))
+
+	line := p.tok.Pos.Line
+	depth := 0
+
+	tok := p.tok
+	p.n = 0 // the offending token is consumed, if it was unscanned
+	for {
+		switch tok.Type {
+		case token.EOF:
+			p.unscan()
+			return
+		case token.LBRACE, token.LBRACK:
+			depth++
+		case token.RBRACE, token.RBRACK:
+			if depth == 0 && tok.Type == token.RBRACE && p.depth > 0 {
+				p.unscan()
+				return
+			}
+
+			if depth > 0 {
+				depth--
+			}
+		case token.IDENT, token.STRING:
+			if depth == 0 && tok.Pos.Line > line {
+				p.unscan()
+				return
+			}
+		}
+
+		tok = p.scan()
+	}
+}
+
 func (p *Parser) consumeComment() (comment *ast.Comment, endline int) {
 	endline = p.tok.Pos.Line
 
@@ -90,6 +239,7 @@ func (p *Parser) consumeComment() (comment *ast.Comment, endline int) {
 
 	comment = &ast.Comment{Start: p.tok.Pos, Text: p.tok.Text}
 	p.tok = p.sc.Scan()
+	p.tokens++
 	return
 }
 
@@ -149,6 +299,12 @@ func (p *Parser) objectItem() (*ast.ObjectItem, error) {
 		p.lineComment = nil
 	}
 	p.unscan()
+
+	for _, c := range []*ast.CommentGroup{o.LeadComment, o.LineComment} {
+		if c != nil {
+			o.Directives = append(o.Directives, c.Directives()...)
+		}
+	}
 	return o, nil
 }
 
@@ -161,29 +317,43 @@ func (p *Parser) objectKey() ([]*ast.ObjectKey, error) {
 		tok := p.scan()
 		switch tok.Type {
 		case token.EOF:
+			// a file may end after an item, but not after a key
+			if keyCount > 0 {
+				return nil, syntaxError(p.tok, []token.Type{token.IDENT, token.STRING, token.ASSIGN, token.LBRACE},
+					"expected: IDENT | STRING | ASSIGN | LBRACE got: EOF")
+			}
 			return nil, errEofToken
 		case token.ASSIGN:
 			// assignment or object only, but not nested objects. this is not
 			// allowed: `foo bar = {}`
 			if keyCount > 1 {
-				return nil, fmt.Errorf("nested object expected: LBRACE got: %s", p.tok.Type)
+				return nil, syntaxError(p.tok, []token.Type{token.LBRACE}, "nested object expected: LBRACE got: %s", p.tok.Type)
 			}
 
 			if keyCount == 0 {
-				return nil, errors.New("no keys found!!!")
+				return nil, syntaxError(p.tok, []token.Type{token.IDENT, token.STRING}, "no keys found")
 			}
 
 			return keys, nil
 		case token.LBRACE:
-			// object
+			// object, which needs a key like an assignment
+			if keyCount == 0 {
+				return nil, syntaxError(p.tok, []token.Type{token.IDENT, token.STRING}, "no keys found")
+			}
 			return keys, nil
 		case token.IDENT, token.STRING:
 			keyCount++
 			keys = append(keys, &ast.ObjectKey{Token: p.tok})
-		case token.ILLEGAL:
-			fmt.Println("illegal")
+		case token.NULL:
+			// null is a keyword of values only, keys named null stay
+			// identifiers
+			keyCount++
+			tok := p.tok
+			tok.Type = token.IDENT
+			keys = append(keys, &ast.ObjectKey{Token: tok})
 		default:
-			return nil, fmt.Errorf("expected: IDENT | STRING | ASSIGN | LBRACE got: %s", p.tok.Type)
+			return nil, syntaxError(p.tok, []token.Type{token.IDENT, token.STRING, token.ASSIGN, token.LBRACE},
+				"expected: IDENT | STRING | ASSIGN | LBRACE got: %s", p.tok.Type)
 		}
 	}
 }
@@ -195,19 +365,22 @@ func (p *Parser) object() (ast.Node, error) {
 	tok := p.scan()
 
 	switch tok.Type {
-	case token.NUMBER, token.FLOAT, token.BOOL, token.STRING:
-		return p.literalType()
+	case token.NUMBER, token.FLOAT, token.BOOL, token.STRING, token.UNIT, token.HEREDOC:
+		return p.literalType(true)
+	case token.NULL:
+		if !p.syntax.Supports(NullLiterals) {
+			return nil, syntaxError(tok, nil, "%s are not supported by syntax %s", NullLiterals, p.syntax)
+		}
+		return p.literalType(false)
 	case token.LBRACE:
 		return p.objectType()
 	case token.LBRACK:
 		return p.listType()
 	case token.COMMENT:
 		// implement comment
-	case token.EOF:
-		return nil, errEofToken
 	}
 
-	return nil, fmt.Errorf("Unknown token: %+v", tok)
+	return nil, syntaxError(tok, valueTokens, "unexpected token while parsing value: %s", tok.Type)
 }
 
 // objectType parses an object type and returns a ObjectType AST
@@ -219,14 +392,27 @@ func (p *Parser) objectType() (*ast.ObjectType, error) {
 		Lbrace: p.tok.Pos,
 	}
 
-	l, err := p.objectList()
+	if err := p.nest(); err != nil {
+		return nil, err
+	}
+	defer p.unnest()
 
-	// if we hit RBRACE, we are good to go (means we parsed all Items), if it's
-	// not a RBRACE, it's an syntax error and we just return it.
-	if err != nil && p.tok.Type != token.RBRACE {
+	p.depth++
+	l, err := p.objectList()
+	p.depth--
+	if err != nil {
 		return nil, err
 	}
 
+	// objectList stops right before the RBRACE, consume it
+	if p.tok.Type == token.RBRACE {
+		o.TrailComment, p.trailComment = p.trailComment, nil
+		p.scan()
+	} else {
+		return nil, syntaxError(p.tok, []token.Type{token.RBRACE},
+			"object opened at %s not terminated, expected: } got: %s", o.Lbrace, p.tok.Type)
+	}
+
 	o.List = l
 	o.Rbrace = p.tok.Pos // advanced via parseObjectList
 	return o, nil
@@ -241,21 +427,81 @@ func (p *Parser) listType() (*ast.ListType, error) {
 		Lbrack: p.tok.Pos,
 	}
 
+	if err := p.nest(); err != nil {
+		return nil, err
+	}
+	defer p.unnest()
+
+	// needComma is set after an element, if the syntax requires a comma
+	// before the next one. commas counts the commas between elements.
+	needComma := false
+	commas := 0
 	for {
 		tok := p.scan()
+
+		// a line comment after an element without a comma belongs to it
+		if p.lineComment != nil && len(l.List) > 0 {
+			lit, ok := l.List[len(l.List)-1].(*ast.LiteralType)
+			if ok && lit.LineComment == nil && p.lineComment.Pos().Line == ast.End(lit).Line {
+				lit.LineComment = p.lineComment
+				p.lineComment = nil
+			}
+		}
+
 		switch tok.Type {
-		case token.NUMBER, token.FLOAT, token.STRING:
-			node, err := p.literalType()
+		case token.NUMBER, token.FLOAT, token.STRING, token.HEREDOC, token.UNIT, token.BOOL, token.NULL, token.LBRACK:
+			if needComma {
+				return nil, syntaxError(tok, []token.Type{token.COMMA, token.RBRACK}, "expected: COMMA | RBRACK got: %s", tok.Type)
+			}
+
+			var node ast.Node
+			var err error
+			switch tok.Type {
+			case token.LBRACK:
+				if ok, ferr := p.feature(NestedLists); ok {
+					node, err = p.listType()
+				} else {
+					err = ferr
+				}
+			case token.BOOL:
+				if ok, ferr := p.feature(ListBools); ok {
+					node, err = p.literalType(false)
+				} else {
+					err = ferr
+				}
+			case token.NULL:
+				if ok, ferr := p.feature(NullLiterals); ok {
+					node, err = p.literalType(false)
+				} else {
+					err = ferr
+				}
+			default:
+				node, err = p.literalType(false)
+			}
 			if err != nil {
 				return nil, err
 			}
 
-			l.Add(node)
+			// elements of unsupported features are skipped by V1Lenient
+			if node != nil {
+				l.TrailingComma = false
+				l.Add(node)
+				needComma = !p.syntax.Supports(OptionalListCommas)
+			}
 		case token.COMMA:
+			if len(l.List) == 0 && p.syntax != V1Lenient {
+				return nil, syntaxError(tok, nil, "unexpected comma before the first list element")
+			}
+			needComma = false
+			if len(l.List) > 0 && !l.TrailingComma {
+				l.TrailingComma = true
+				commas++
+			}
+
 			// get next list item or we are at the end
 			// do a look-ahead for line comment
 			p.scan()
-			if p.lineComment != nil {
+			if p.lineComment != nil && len(l.List) > 0 {
 				lit, ok := l.List[len(l.List)-1].(*ast.LiteralType)
 				if ok {
 					lit.LineComment = p.lineComment
@@ -265,30 +511,102 @@ func (p *Parser) listType() (*ast.ListType, error) {
 			}
 			p.unscan()
 			continue
-		case token.BOOL:
-			// TODO(arslan) should we support? not supported by HCL yet
-		case token.LBRACK:
-			// TODO(arslan) should we support nested lists? Even though it's
-			// written in README of HCL, it's not a part of the grammar
-			// (not defined in parse.y)
 		case token.RBRACK:
 			// finished
 			l.Rbrack = p.tok.Pos
+			if l.TrailingComma {
+				commas--
+			}
+			l.Newlines = len(l.List) > 1 && commas == 0
 			return l, nil
+		case token.EOF, token.RBRACE:
+			// most likely the closing bracket is missing
+			return nil, syntaxError(tok, []token.Type{token.RBRACK},
+				"list opened at %s not terminated, expected: ] got: %s", l.Lbrack, tok.Type)
 		default:
-			return nil, fmt.Errorf("unexpected token while parsing list: %s", tok.Type)
+			return nil, syntaxError(tok, []token.Type{token.NUMBER, token.FLOAT, token.UNIT, token.BOOL, token.NULL, token.STRING, token.HEREDOC, token.LBRACK, token.COMMA, token.RBRACK},
+				"unexpected token while parsing list: %s", tok.Type)
 		}
 
 	}
 }
 
-// literalType parses a literal type and returns a LiteralType AST
-func (p *Parser) literalType() (*ast.LiteralType, error) {
+// feature reports whether the syntax supports the feature f of the current
+// list element. Otherwise V1Lenient skips the element, as it did before
+// syntax versions existed, and other versions report an error.
+func (p *Parser) feature(f Feature) (bool, error) {
+	if p.syntax.Supports(f) {
+		return true, nil
+	}
+
+	if p.syntax != V1Lenient {
+		return false, syntaxError(p.tok, nil, "%s are not supported by syntax %s", f, p.syntax)
+	}
+	return false, nil
+}
+
+// literalType parses a literal type and returns a LiteralType AST. If
+// adjacent is set, adjacent strings are folded too, see concat.
+func (p *Parser) literalType(adjacent bool) (*ast.LiteralType, error) {
 	defer un(trace(p, "ParseLiteral"))
 
-	return &ast.LiteralType{
+	lit := &ast.LiteralType{
 		Token: p.tok,
-	}, nil
+	}
+
+	if lit.Token.Type == token.STRING && p.syntax.Supports(StringConcat) {
+		if err := p.concat(lit, adjacent); err != nil {
+			return nil, err
+		}
+	}
+
+	text := lit.Token.Text
+	if lit.Token.Type == token.STRING && len(text) >= 2 && (strings.Contains(text, "${") || strings.Contains(text, "%{")) {
+		pos := lit.Token.Pos
+		pos.Offset++
+		pos.Column++
+		if t, err := ParseTemplate(text[1:len(text)-1], pos); err == nil {
+			lit.Template = t
+		}
+	}
+	if lit.Token.Type == token.HEREDOC && (strings.Contains(text, "${") || strings.Contains(text, "%{")) {
+		if t, err := ParseHeredoc(lit.Token); err == nil {
+			lit.Template = t
+		}
+	}
+	return lit, nil
+}
+
+// concat folds the strings following the string literal lit into it. Strings
+// joined with "+" are folded anywhere, adjacent strings only if adjacent is
+// set and they're on the same line, as a string on the next line is the key
+// of the next item or the next element of a list. The folded string is
+// positioned at the first one, the original tokens are kept in lit.Parts.
+func (p *Parser) concat(lit *ast.LiteralType, adjacent bool) error {
+	parts := []token.Token{lit.Token}
+	line := lit.Token.Pos.Line
+	for {
+		tok := p.scan()
+		switch {
+		case tok.Type == token.ADD:
+			parts = append(parts, tok)
+			if tok = p.scan(); tok.Type != token.STRING {
+				return syntaxError(tok, []token.Type{token.STRING}, "expected: STRING after + got: %s", tok.Type)
+			}
+		case tok.Type == token.STRING && adjacent && tok.Pos.Line == line:
+		default:
+			p.unscan()
+			if len(parts) > 1 {
+				lit.Parts = parts
+			}
+			return nil
+		}
+
+		text := lit.Token.Text
+		lit.Token.Text = text[:len(text)-1] + tok.Text[1:]
+		parts = append(parts, tok)
+		line = tok.Pos.Line
+	}
 }
 
 // scan returns the next token from the underlying scanner. If a token has
@@ -304,7 +622,16 @@ func (p *Parser) scan() token.Token {
 	// Otherwise read the next token from the scanner and Save it to the buffer
 	// in case we unscan later.
 	prev := p.tok
+	if p.limitErr != nil || p.maxErrors > 0 && len(p.errs) >= p.maxErrors {
+		p.tok = token.Token{Type: token.EOF, Pos: prev.Pos}
+		return p.tok
+	}
 	p.tok = p.sc.Scan()
+	p.tokens++
+	for p.discardComments && p.tok.Type == token.COMMENT {
+		p.tok = p.sc.Scan()
+		p.tokens++
+	}
 
 	if p.tok.Type == token.COMMENT {
 		var comment *ast.CommentGroup
@@ -316,9 +643,10 @@ func (p *Parser) scan() token.Token {
 			// The comment is on same line as the previous token; it
 			// cannot be a lead comment but may be a line comment.
 			comment, endline = p.consumeCommentGroup(0)
-			if p.tok.Pos.Line != endline {
-				// The next token is on a different line, thus
-				// the last comment group is a line comment.
+			if p.tok.Pos.Line != endline || p.tok.Type == token.EOF {
+				// The next token is on a different line, or the
+				// source ends without a newline, thus the last
+				// comment group is a line comment.
 				p.lineComment = comment
 			}
 		}
@@ -340,8 +668,13 @@ func (p *Parser) scan() token.Token {
 			}
 		}
 
+		if endline+1 == p.tok.Pos.Line && p.tok.Type == token.RBRACE {
+			p.trailComment = comment
+		}
+
 	}
 
+	p.countRepeat(prev)
 	return p.tok
 }
 
@@ -350,6 +683,45 @@ func (p *Parser) unscan() {
 	p.n = 1
 }
 
+// nest enters an object or list, unnest leaves it
+func (p *Parser) nest() error {
+	p.nesting++
+	if p.maxDepth > 0 && p.nesting > p.maxDepth {
+		return p.limit(&LimitError{Limit: "MaxDepth", Max: p.maxDepth})
+	}
+	return nil
+}
+
+func (p *Parser) unnest() {
+	p.nesting--
+}
+
+// countRepeat counts the consecutive equal operators ending with the current
+// token, prev is the token scanned before
+func (p *Parser) countRepeat(prev token.Token) {
+	if p.maxRepeat <= 0 {
+		return
+	}
+
+	if !p.tok.Type.IsOperator() || p.tok.Type != prev.Type {
+		p.repeat = 1
+		return
+	}
+
+	p.repeat++
+	if p.repeat > p.maxRepeat {
+		p.limit(&LimitError{Limit: "MaxRepeat", Max: p.maxRepeat, Token: p.tok.Type})
+	}
+}
+
+// limit records the exceeded limit err, which stops parsing
+func (p *Parser) limit(err *LimitError) error {
+	if p.limitErr == nil {
+		p.limitErr = &PosError{Pos: p.tok.Pos, Err: err}
+	}
+	return p.limitErr
+}
+
 // ----------------------------------------------------------------------------
 // Parsing support
 
@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 	"reflect"
 	"runtime"
+	"strings"
 	"testing"
 
 	"github.com/fatih/hcl/ast"
@@ -22,10 +23,13 @@ func TestType(t *testing.T) {
 		{token.FLOAT, `foo = 123.12`},
 		{token.FLOAT, `foo = -123.12`},
 		{token.BOOL, `foo = true`},
+		{token.NULL, `foo = null`},
+		{token.HEREDOC, "foo = <<EOF\nfoo\nEOF"},
+		{token.HEREDOC, "foo = <<-EOF\n  foo\n  EOF\n"},
 	}
 
 	for _, l := range literals {
-		p := newParser([]byte(l.src))
+		p := (&Config{Syntax: Experimental}).newParser([]byte(l.src))
 		item, err := p.objectItem()
 		if err != nil {
 			t.Error(err)
@@ -63,10 +67,14 @@ func TestListType(t *testing.T) {
 			`foo = ["123", 123]`,
 			[]token.Type{token.STRING, token.NUMBER},
 		},
+		{
+			"foo = [<<EOF\n123\nEOF\n, 123]",
+			[]token.Type{token.HEREDOC, token.NUMBER},
+		},
 	}
 
 	for _, l := range literals {
-		p := newParser([]byte(l.src))
+		p := (&Config{Syntax: Experimental}).newParser([]byte(l.src))
 		item, err := p.objectItem()
 		if err != nil {
 			t.Error(err)
@@ -177,10 +185,14 @@ func TestObjectKey(t *testing.T) {
 		{[]token.Type{token.STRING}, `"foo" {}`},
 		{[]token.Type{token.STRING}, `"foo" = {}`},
 		{[]token.Type{token.STRING}, `"foo" = "${var.bar}`},
+		{[]token.Type{token.STRING}, `"my.key/with:chars" = "value"`},
+		{[]token.Type{token.STRING}, `"a \"b\"" = 1`},
 		{[]token.Type{token.IDENT, token.IDENT}, `foo bar {}`},
 		{[]token.Type{token.IDENT, token.STRING}, `foo "bar" {}`},
 		{[]token.Type{token.STRING, token.IDENT}, `"foo" bar {}`},
 		{[]token.Type{token.IDENT, token.IDENT, token.IDENT}, `foo bar baz {}`},
+		{[]token.Type{token.IDENT}, `null = 1`},
+		{[]token.Type{token.IDENT, token.STRING}, `null "a" {}`},
 	}
 
 	for _, k := range keys {
@@ -205,6 +217,7 @@ func TestObjectKey(t *testing.T) {
 		{`foo bar = {}`},
 		{`foo []`},
 		{`12 {}`},
+		{`{}`},
 	}
 
 	for _, k := range errKeys {
@@ -291,6 +304,302 @@ func TestParse(t *testing.T) {
 	}
 }
 
+func TestParseWithRecovery(t *testing.T) {
+	cases := []struct {
+		src   string
+		keys  []string
+		lines []int
+	}{
+		{
+			"foo = 1\nbar = \nbaz = 2",
+			[]string{"foo"},
+			[]int{3},
+		},
+		{
+			"foo = 1\nbar 12 {}\nbaz = 2",
+			[]string{"foo", "baz"},
+			[]int{2},
+		},
+		{
+			"a {\n  b = ]\n  c = 1\n}\nd = 2",
+			[]string{"a", "d"},
+			[]int{2},
+		},
+		{
+			"a = 1\n}\nb = 2\n}",
+			[]string{"a", "b"},
+			[]int{2, 4},
+		},
+	}
+
+	for _, c := range cases {
+		f, errs := ParseWithRecovery([]byte(c.src))
+		if f == nil {
+			t.Fatalf("%q: file should not be nil", c.src)
+		}
+
+		keys := []string{}
+		for _, item := range f.Node.(*ast.ObjectList).Items {
+			keys = append(keys, item.Keys[0].Token.Text)
+		}
+		equals(t, c.keys, keys)
+
+		lines := []int{}
+		for _, err := range errs {
+			perr, ok := err.(*PosError)
+			if !ok {
+				t.Fatalf("%q: error should be of type *PosError, got: %T", c.src, err)
+			}
+			lines = append(lines, perr.Pos.Line)
+		}
+		equals(t, c.lines, lines)
+	}
+}
+
+func TestParseMaxErrors(t *testing.T) {
+	src := []byte("a = 1,\nb = }\nc = 3\nd = ]\ne = 5\nf = }\ng = 7")
+
+	_, errs := ParseWithRecovery(src)
+	equals(t, 4, len(errs))
+
+	f, errs := (&Config{MaxErrors: 2}).ParseWithRecovery(src)
+	lines := []int{}
+	for _, err := range errs {
+		lines = append(lines, err.(*PosError).Pos.Line)
+	}
+	equals(t, []int{1, 2}, lines)
+
+	// parsing stops at the last error
+	keys := []string{}
+	for _, item := range f.Node.(*ast.ObjectList).Items {
+		keys = append(keys, item.Keys[0].Token.Text)
+	}
+	equals(t, []string{"a"}, keys)
+}
+
+func TestParseEOF(t *testing.T) {
+	cases := []struct {
+		src  string
+		keys []string
+		end  token.Pos
+		err  string
+	}{
+		{"a = 1", []string{"a"}, token.Pos{Offset: 5, Line: 1, Column: 6}, ""},
+		{"a = 1\nb {}", []string{"a", "b"}, token.Pos{Offset: 10, Line: 2, Column: 5}, ""},
+		{"a = 1\n", []string{"a"}, token.Pos{Offset: 6, Line: 2, Column: 1}, ""},
+		{"a = 1\n\n  \t\n  ", []string{"a"}, token.Pos{Offset: 13, Line: 4, Column: 3}, ""},
+		{"a = 1\r\n", []string{"a"}, token.Pos{Offset: 7, Line: 2, Column: 1}, ""},
+		{"a = 1 # one", []string{"a"}, token.Pos{Offset: 11, Line: 1, Column: 12}, ""},
+		{"", []string{}, token.Pos{Offset: 0, Line: 1, Column: 1}, ""},
+
+		// files ending within an item or a token
+		{"a", nil, token.Pos{}, "At 1:2: expected: IDENT | STRING | ASSIGN | LBRACE got: EOF"},
+		{"a = 1\nb =", nil, token.Pos{}, "At 2:4: unexpected token while parsing value: EOF"},
+		{"a = \"b", nil, token.Pos{}, `At 1:7: literal opened at 1:5 not terminated, expected: "`},
+		{"a = 1e", nil, token.Pos{}, "At 1:7: exponent has no digits"},
+		{"a = 1 /* one", nil, token.Pos{}, "At 1:13: comment opened at 1:7 not terminated, expected: */"},
+	}
+
+	for _, c := range cases {
+		f, err := Parse([]byte(c.src))
+		if c.err != "" {
+			if err == nil || err.Error() != c.err {
+				t.Errorf("%q: want error %q, got: %v", c.src, c.err, err)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("%q: %s", c.src, err)
+			continue
+		}
+
+		keys := []string{}
+		for _, item := range f.Node.(*ast.ObjectList).Items {
+			keys = append(keys, item.Keys[0].Token.Text)
+		}
+		equals(t, c.keys, keys)
+		equals(t, c.end, f.EndPos)
+	}
+}
+
+func TestParseUnclosed(t *testing.T) {
+	cases := []struct {
+		src string
+		err string
+	}{
+		{
+			"a {\n  b = 1\n",
+			"At 3:1: object opened at 1:3 not terminated, expected: } got: EOF",
+		},
+		{
+			"a {\n  b {\n    c = 1\n  }\n",
+			"At 5:1: object opened at 1:3 not terminated, expected: } got: EOF",
+		},
+		{
+			"a = [1, 2\n",
+			"At 2:1: list opened at 1:5 not terminated, expected: ] got: EOF",
+		},
+		{
+			"a {\n  b = [1\n}",
+			"At 3:1: list opened at 2:7 not terminated, expected: ] got: RBRACE",
+		},
+		{
+			"a {\n  b = <<EOF\n  c\n  EOF\n}\n",
+			"At 6:1: heredoc opened at 2:7 not terminated, expected: EOF on a line of its own (the marker on line 4 is indented, use <<-EOF)",
+		},
+	}
+
+	for _, c := range cases {
+		_, err := Parse([]byte(c.src))
+		if err == nil {
+			t.Errorf("%q: expected an error", c.src)
+			continue
+		}
+		equals(t, c.err, err.Error())
+	}
+
+	_, errs := ParseWithRecovery([]byte("a = \"abc\n"))
+	if len(errs) == 0 {
+		t.Fatal("expected an error for an unterminated string")
+	}
+	equals(t, `At 1:9: literal opened at 1:5 not terminated, expected: "`, errs[0].Error())
+}
+
+func TestParseLimits(t *testing.T) {
+	cases := []struct {
+		config Config
+		src    string
+		err    string
+	}{
+		{Config{MaxDepth: 2}, `a { b { c = 1 } }`, ""},
+		{Config{MaxDepth: 2}, `a { b { c { d = 1 } } }`, "At 1:11: resource limit exceeded: more than 2 nested objects and lists"},
+		{Config{MaxDepth: 2}, `a { b = [1] }`, ""},
+		{Config{Syntax: Experimental, MaxDepth: 2}, `a = [[[1]]]`, "At 1:7: resource limit exceeded: more than 2 nested objects and lists"},
+		{Config{MaxRepeat: 3}, `a = [1,,, 2]`, ""},
+		{Config{MaxRepeat: 3}, "a = [1,,\n,/* c */,, 2]", "At 2:9: resource limit exceeded: more than 3 consecutive COMMA tokens"},
+		{Config{MaxRepeat: 3}, `a = ["a" "b" "c" "d" "e"]`, ""},
+		{Config{}, "a = [" + strings.Repeat(",", 10000) + "]", ""},
+	}
+
+	for _, c := range cases {
+		_, err := c.config.Parse([]byte(c.src))
+		if c.err == "" {
+			if err != nil {
+				t.Errorf("%s: %s", c.src, err)
+			}
+			continue
+		}
+
+		if err == nil || err.Error() != c.err {
+			t.Errorf("%s: want error %q, got: %v", c.src, c.err, err)
+			continue
+		}
+
+		if _, ok := err.(*PosError).Err.(*LimitError); !ok {
+			t.Errorf("%s: want a *LimitError, got: %T", c.src, err.(*PosError).Err)
+		}
+	}
+
+	// recovery stops at the limit
+	config := &Config{MaxDepth: 1}
+	_, errs := config.ParseWithRecovery([]byte("a { b { c = 1 } }\nd = ]\ne = ]"))
+	if len(errs) != 1 {
+		t.Fatalf("want 1 error, got: %v", errs)
+	}
+}
+
+func TestListStyle(t *testing.T) {
+	cases := []struct {
+		src                     string
+		newlines, trailingComma bool
+	}{
+		{`a = [1, 2]`, false, false},
+		{`a = [1, 2,]`, false, true},
+		{"a = [\n  1,\n  2,\n]", false, true},
+		{"a = [\n  1\n  2\n]", true, false},
+		{"a = [\n  1\n  2,\n]", true, true},
+		{"a = [\n  1,\n  2\n  3\n]", false, false},
+		{`a = [1]`, false, false},
+		{`a = [1,]`, false, true},
+		{`a = []`, false, false},
+	}
+
+	for _, c := range cases {
+		f, err := (&Config{Syntax: Experimental}).Parse([]byte(c.src))
+		if err != nil {
+			t.Fatalf("%s: %s", c.src, err)
+		}
+
+		l := f.Node.(*ast.ObjectList).Items[0].Val.(*ast.ListType)
+		if l.Newlines != c.newlines || l.TrailingComma != c.trailingComma {
+			t.Errorf("%q: want newlines %t, trailing comma %t, got %t, %t",
+				c.src, c.newlines, c.trailingComma, l.Newlines, l.TrailingComma)
+		}
+	}
+}
+
+func TestParseComments(t *testing.T) {
+	src := `// lead
+a = 1 # line
+b {
+  c = 2
+
+  // trail
+}
+d {
+  e = 3
+  /* line */ }
+`
+	f, err := Parse([]byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	items := f.Node.(*ast.ObjectList).Items
+	equals(t, 4, len(f.Comments))
+	equals(t, "// lead", items[0].LeadComment.List[0].Text)
+	equals(t, "# line", items[0].LineComment.List[0].Text)
+	equals(t, "// trail", items[1].Val.(*ast.ObjectType).TrailComment.List[0].Text)
+	equals(t, (*ast.CommentGroup)(nil), items[2].Val.(*ast.ObjectType).TrailComment)
+
+	// the end of the source ends the line of a line comment too
+	for _, src := range []string{"a = 1 # c", "a {\n  b = 1\n} # c"} {
+		f, err := Parse([]byte(src))
+		if err != nil {
+			t.Fatal(err)
+		}
+		item := f.Node.(*ast.ObjectList).Items[0]
+		if item.LineComment == nil || item.LineComment.List[0].Text != "# c" {
+			t.Errorf("%q: want the line comment # c, got %v", src, item.LineComment)
+		}
+	}
+
+	// a line comment after a list element belongs to it with or without a comma
+	for _, src := range []string{"a = [1, # c\n2]", "a = [1 # c\n2]", "a = [1 # c\n]"} {
+		f, err := Parse([]byte(src))
+		if err != nil {
+			t.Fatal(err)
+		}
+		item := f.Node.(*ast.ObjectList).Items[0]
+		lit := item.Val.(*ast.ListType).List[0].(*ast.LiteralType)
+		if lit.LineComment == nil || lit.LineComment.List[0].Text != "# c" || item.LineComment != nil {
+			t.Errorf("%q: want the line comment # c on the element, got %v", src, lit.LineComment)
+		}
+	}
+
+	f, err = (&Config{DiscardComments: true}).Parse([]byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	items = f.Node.(*ast.ObjectList).Items
+	equals(t, 0, len(f.Comments))
+	equals(t, 3, len(items))
+	equals(t, (*ast.CommentGroup)(nil), items[0].LeadComment)
+	equals(t, (*ast.CommentGroup)(nil), items[1].Val.(*ast.ObjectType).TrailComment)
+}
+
 // equals fails the test if exp is not equal to act.
 func equals(tb testing.TB, exp, act interface{}) {
 	if !reflect.DeepEqual(exp, act) {
@@ -0,0 +1,260 @@
+package parser
+
+import (
+	"errors"
+	"time"
+
+	"github.com/fatih/hcl/ast"
+	"github.com/fatih/hcl/token"
+)
+
+// SyntaxVersion is a version of the HCL syntax. It pins the behavior of the
+// parser and printer, so new syntax features are only accepted if a version
+// supporting them is requested.
+type SyntaxVersion int
+
+const (
+	// V1Lenient is the syntax accepted by Parse. List elements don't need
+	// to be separated by commas, and bools and nested lists in lists are
+	// ignored.
+	V1Lenient SyntaxVersion = iota
+
+	// V1Strict is the HCL 1 grammar. List elements must be separated by
+	// commas, and bools and nested lists in lists are errors.
+	V1Strict
+
+	// Experimental enables all syntax extensions, which might change
+	// between releases.
+	Experimental
+)
+
+func (v SyntaxVersion) String() string {
+	switch v {
+	case V1Lenient:
+		return "v1-lenient"
+	case V1Strict:
+		return "v1-strict"
+	case Experimental:
+		return "experimental"
+	}
+	return "unknown"
+}
+
+// Feature is a syntax feature supported by some syntax versions.
+type Feature int
+
+const (
+	OptionalListCommas Feature = iota // list elements without separating commas
+	ListBools                         // bools as list elements, i.e. [true, false]
+	NestedLists                       // lists as list elements, i.e. [[1], [2]]
+	StringConcat                      // folded strings, i.e. "a" "b" or "a" + "b"
+	UnitLiterals                      // numbers with units, i.e. 10s or 512k
+	NullLiterals                      // null values, i.e. a = null or [1, null]
+)
+
+func (f Feature) String() string {
+	switch f {
+	case OptionalListCommas:
+		return "optional list commas"
+	case ListBools:
+		return "bools in lists"
+	case NestedLists:
+		return "nested lists"
+	case StringConcat:
+		return "string concatenations"
+	case UnitLiterals:
+		return "unit literals"
+	case NullLiterals:
+		return "null literals"
+	}
+	return "unknown"
+}
+
+var features = map[SyntaxVersion][]Feature{
+	V1Lenient:    {OptionalListCommas},
+	V1Strict:     {},
+	Experimental: {OptionalListCommas, ListBools, NestedLists, StringConcat, UnitLiterals, NullLiterals},
+}
+
+// Supports reports whether v supports the feature f.
+func (v SyntaxVersion) Supports(f Feature) bool {
+	for _, feature := range features[v] {
+		if feature == f {
+			return true
+		}
+	}
+	return false
+}
+
+// Features returns the features supported by v.
+func (v SyntaxVersion) Features() []Feature {
+	return append([]Feature(nil), features[v]...)
+}
+
+// DefaultConfig is the configuration used by Parse and ParseWithRecovery.
+var DefaultConfig = Config{Syntax: V1Lenient}
+
+// A Config controls the syntax and encoding accepted by the parser.
+type Config struct {
+	Syntax SyntaxVersion
+
+	// Encoding is the encoding of the source. Source in UTF-16 is
+	// transcoded to UTF-8 before parsing, so positions refer to the
+	// transcoded source.
+	Encoding Encoding
+
+	// FoldCase makes the keywords true, false and null case-insensitive, i.e. for
+	// configurations migrated from systems ignoring case. The syntax tree
+	// and the printer keep the original text, such as TRUE.
+	FoldCase bool
+
+	// MaxDepth and MaxRepeat limit the nesting of objects and lists and the
+	// number of consecutive equal operators, such as commas, i.e. to keep
+	// services parsing untrusted input responsive. Parsing stops at the
+	// first limit exceeded with a *LimitError. Zero means no limit.
+	MaxDepth  int
+	MaxRepeat int
+
+	// DiscardComments drops all comments while scanning, i.e. for tools
+	// which only need the values. The syntax tree has no comments and
+	// directives then, so it can't be printed without losing them.
+	DiscardComments bool
+
+	// MaxErrors stops ParseWithRecovery after the given number of errors,
+	// i.e. to report only the first screenful of errors of a broken file.
+	// The tree contains the items parsed until then. Zero means no limit.
+	MaxErrors int
+
+	// Duplicates is the handling of attributes assigned more than once in
+	// the same object. The default keeps them all, see DuplicatePolicy.
+	Duplicates DuplicatePolicy
+
+	// Filename is the name of the parsed file, recorded in the positions of
+	// the syntax tree and of errors, i.e. to tell the files of a merged tree
+	// apart.
+	Filename string
+
+	// Logger, if not nil, records every parse with its duration, the size
+	// of the source and the number of tokens, i.e. to find slow
+	// configuration loads in production. See Logger.
+	Logger Logger
+}
+
+// Parse parses src with the syntax of c. See Parse.
+func (c *Config) Parse(src []byte) (*ast.File, error) {
+	start := time.Now()
+	src, err := Transcode(src, c.Encoding)
+	if err != nil {
+		return nil, err
+	}
+
+	p := c.newParser(src)
+	f, err := p.Parse()
+	if err == nil {
+		if errs := resolveDuplicates(f, c.Duplicates); len(errs) > 0 {
+			f, err = nil, errs[0]
+		}
+	}
+	c.log(p, len(src), start, err)
+	return f, err
+}
+
+// log records the parse of size bytes by p started at start, if c has a
+// logger
+func (c *Config) log(p *Parser, size int, start time.Time, err error) {
+	if c.Logger == nil {
+		return
+	}
+
+	c.Logger.Debug("hcl: parsed", "phase", "parse", "duration", time.Since(start),
+		"bytes", size, "tokens", p.tokens, "error", err)
+}
+
+func (c *Config) newParser(src []byte) *Parser {
+	p := newParser(src)
+	p.syntax = c.Syntax
+	p.sc.FoldKeywords = c.FoldCase
+	p.sc.Units = c.Syntax.Supports(UnitLiterals)
+	p.maxDepth = c.MaxDepth
+	p.maxRepeat = c.MaxRepeat
+	p.discardComments = c.DiscardComments
+	if c.Filename != "" {
+		p.sc.StartAt(token.Pos{Filename: c.Filename, Line: 1, Column: 1})
+	}
+	return p
+}
+
+// ParseWithRecovery parses src with the syntax of c. See ParseWithRecovery.
+func (c *Config) ParseWithRecovery(src []byte) (*ast.File, []error) {
+	start := time.Now()
+	src, err := Transcode(src, c.Encoding)
+	if err != nil {
+		return &ast.File{Node: &ast.ObjectList{}}, []error{err}
+	}
+
+	p := c.newParser(src)
+	p.recover = true
+	p.maxErrors = c.MaxErrors
+
+	f, err := p.Parse()
+	if err != nil {
+		p.addError(err)
+	}
+	if f != nil {
+		for _, err := range resolveDuplicates(f, c.Duplicates) {
+			p.addError(err)
+		}
+	}
+
+	if len(p.errs) > 0 {
+		c.log(p, len(src), start, p.errs[0])
+	} else {
+		c.log(p, len(src), start, nil)
+	}
+	return f, p.errs
+}
+
+// CheckSyntax returns an error for the first node of n using a feature not
+// supported by v, i.e. before printing a tree for older parsers.
+func CheckSyntax(n ast.Node, v SyntaxVersion) error {
+	var err error
+	ast.Walk(n, func(n ast.Node) (ast.Node, bool) {
+		if lit, ok := n.(*ast.LiteralType); ok && lit.Token.Type == token.NULL && !v.Supports(NullLiterals) {
+			err = &PosError{Pos: lit.Pos(), Err: errors.New(NullLiterals.String() + " are not supported by syntax " + v.String())}
+			return n, false
+		}
+		if lit, ok := n.(*ast.LiteralType); ok && len(lit.Parts) > 0 && !v.Supports(StringConcat) {
+			err = &PosError{Pos: lit.Pos(), Err: errors.New(StringConcat.String() + " are not supported by syntax " + v.String())}
+			return n, false
+		}
+
+		list, ok := n.(*ast.ListType)
+		if !ok {
+			return n, err == nil
+		}
+
+		if list.Newlines && !v.Supports(OptionalListCommas) {
+			err = &PosError{Pos: list.Pos(), Err: errors.New(OptionalListCommas.String() + " are not supported by syntax " + v.String())}
+			return n, false
+		}
+
+		for _, elem := range list.List {
+			f := Feature(-1)
+			switch t := elem.(type) {
+			case *ast.ListType:
+				f = NestedLists
+			case *ast.LiteralType:
+				if t.Token.Type == token.BOOL {
+					f = ListBools
+				}
+			}
+
+			if f >= 0 && !v.Supports(f) {
+				err = &PosError{Pos: elem.Pos(), Err: errors.New(f.String() + " are not supported by syntax " + v.String())}
+				return n, false
+			}
+		}
+		return n, true
+	})
+	return err
+}
@@ -0,0 +1,243 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fatih/hcl/ast"
+	"github.com/fatih/hcl/token"
+)
+
+func TestSyntaxVersions(t *testing.T) {
+	var literals = []struct {
+		src      string
+		syntax   SyntaxVersion
+		elements int
+		err      string
+	}{
+		{`a = [1, 2]`, V1Strict, 2, ""},
+		{`a = [1, 2,]`, V1Strict, 2, ""},
+		{`a = [1 2]`, V1Lenient, 2, ""},
+		{`a = [1 2]`, V1Strict, 0, "At 1:8: expected: COMMA | RBRACK got: NUMBER"},
+		{`a = [1 2]`, Experimental, 2, ""},
+		{`a = [true, 1]`, V1Lenient, 1, ""},
+		{`a = [true, 1]`, V1Strict, 0, "At 1:6: bools in lists are not supported by syntax v1-strict"},
+		{`a = [true, 1]`, Experimental, 2, ""},
+		{`a = [[1], [2, 3]]`, V1Strict, 0, "At 1:6: nested lists are not supported by syntax v1-strict"},
+		{`a = [[1], [2, 3]]`, Experimental, 2, ""},
+		{`a = [, 1]`, V1Lenient, 1, ""},
+		{`a = [, 1]`, V1Strict, 0, "At 1:6: unexpected comma before the first list element"},
+		{`a = ["b" + "c"]`, Experimental, 1, ""},
+		{`a = ["b" "c"]`, Experimental, 2, ""},
+		{`a = ["b" + "c"]`, V1Strict, 0, "At 1:10: unexpected token while parsing list: ADD"},
+		{`a = ["b" + 1]`, Experimental, 0, "At 1:12: expected: STRING after + got: NUMBER"},
+		{`a = [1, null]`, V1Lenient, 1, ""},
+		{`a = null`, V1Lenient, 0, "At 1:5: null literals are not supported by syntax v1-lenient"},
+		{`a = [1, null]`, Experimental, 2, ""},
+		{`a = [1, null]`, V1Strict, 0, "At 1:9: null literals are not supported by syntax v1-strict"},
+	}
+
+	for _, l := range literals {
+		c := &Config{Syntax: l.syntax}
+		f, err := c.Parse([]byte(l.src))
+		if l.err != "" {
+			if err == nil || err.Error() != l.err {
+				t.Errorf("%s %s: want error %q, got: %v", l.syntax, l.src, l.err, err)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("%s %s: %s", l.syntax, l.src, err)
+			continue
+		}
+
+		list := f.Node.(*ast.ObjectList).Items[0].Val.(*ast.ListType)
+		equals(t, l.elements, len(list.List))
+	}
+}
+
+func TestSyntaxSupports(t *testing.T) {
+	equals(t, true, V1Lenient.Supports(OptionalListCommas))
+	equals(t, false, V1Lenient.Supports(ListBools))
+	equals(t, false, V1Strict.Supports(OptionalListCommas))
+	equals(t, []Feature{OptionalListCommas, ListBools, NestedLists, StringConcat, UnitLiterals, NullLiterals}, Experimental.Features())
+	equals(t, false, V1Strict.Supports(NullLiterals))
+	equals(t, false, V1Lenient.Supports(NullLiterals))
+	equals(t, "experimental", Experimental.String())
+	equals(t, "nested lists", NestedLists.String())
+}
+
+func TestStringConcat(t *testing.T) {
+	cases := []struct {
+		src   string
+		text  string
+		parts int
+	}{
+		{`a = "foo"`, `"foo"`, 0},
+		{`a = "foo" "bar"`, `"foobar"`, 2},
+		{`a = "foo " + "bar"`, `"foo bar"`, 3},
+		{"a = \"one, \"\n  + \"two, \" +\n  \"three\"", `"one, two, three"`, 5},
+		{`a = "\"${b}" "\n"`, `"\"${b}\n"`, 2},
+		{"a = \"foo\"\n\"bar\" = 1", `"foo"`, 0},
+	}
+
+	for _, c := range cases {
+		f, err := (&Config{Syntax: Experimental}).Parse([]byte(c.src))
+		if err != nil {
+			t.Fatalf("%s: %s", c.src, err)
+		}
+
+		lit := f.Node.(*ast.ObjectList).Items[0].Val.(*ast.LiteralType)
+		equals(t, c.text, lit.Token.Text)
+		equals(t, c.parts, len(lit.Parts))
+		equals(t, token.Pos{Line: 1, Column: 5, Offset: 4}, lit.Pos())
+	}
+
+	if _, err := Parse([]byte(`a = "foo" + "bar"`)); err == nil {
+		t.Error("expected an error for syntax v1-lenient")
+	}
+}
+
+func TestUnitLiterals(t *testing.T) {
+	f, err := (&Config{Syntax: Experimental}).Parse([]byte("a = 10s\nb = [512k, 80%]"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	items := f.Node.(*ast.ObjectList).Items
+	lit := items[0].Val.(*ast.LiteralType)
+	equals(t, token.UNIT, lit.Token.Type)
+	equals(t, "10s", lit.Token.Text)
+
+	list := items[1].Val.(*ast.ListType)
+	equals(t, token.UNIT, list.List[1].(*ast.LiteralType).Token.Type)
+
+	if _, err := Parse([]byte("a = [10s]")); err == nil {
+		t.Error("expected an error for syntax v1-lenient")
+	}
+}
+
+func TestCheckSyntax(t *testing.T) {
+	f, err := (&Config{Syntax: Experimental}).Parse([]byte("a = [1]\nb {\n  c = [[true]]\n}"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	equals(t, nil, CheckSyntax(f, Experimental))
+
+	err = CheckSyntax(f, V1Strict)
+	if err == nil || err.Error() != "At 3:8: nested lists are not supported by syntax v1-strict" {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	f, err = (&Config{Syntax: Experimental}).Parse([]byte("a {\n  b = null\n}"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = CheckSyntax(f, V1Lenient)
+	if err == nil || err.Error() != "At 2:7: null literals are not supported by syntax v1-lenient" {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	f, err = (&Config{Syntax: Experimental}).Parse([]byte("a = \"b\" +\n  \"c\""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = CheckSyntax(f, V1Lenient)
+	if err == nil || err.Error() != "At 1:5: string concatenations are not supported by syntax v1-lenient" {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestConfigFoldCase(t *testing.T) {
+	src := []byte("a = TRUE\nb = False\nc = NULL")
+
+	f, err := (&Config{Syntax: Experimental, FoldCase: true}).Parse(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var types []token.Type
+	var texts []string
+	for _, item := range f.Node.(*ast.ObjectList).Items {
+		lit := item.Val.(*ast.LiteralType)
+		types = append(types, lit.Token.Type)
+		texts = append(texts, lit.Token.Text)
+	}
+
+	equals(t, []token.Type{token.BOOL, token.BOOL, token.NULL}, types)
+	equals(t, []string{"TRUE", "False", "NULL"}, texts)
+
+	if _, err := (&Config{Syntax: Experimental}).Parse(src); err == nil {
+		t.Error("expected an error without FoldCase")
+	}
+}
+
+// recordLogger records the messages and the keys and values of Debug
+type recordLogger []map[string]interface{}
+
+func (l *recordLogger) Debug(msg string, args ...interface{}) {
+	m := map[string]interface{}{"msg": msg}
+	for i := 0; i+1 < len(args); i += 2 {
+		m[args[i].(string)] = args[i+1]
+	}
+	*l = append(*l, m)
+}
+
+func TestConfigLogger(t *testing.T) {
+	var l recordLogger
+	config := &Config{Logger: &l}
+
+	if _, err := config.Parse([]byte("a = 1 # one")); err != nil {
+		t.Fatal(err)
+	}
+	config.ParseWithRecovery([]byte("a = ["))
+
+	equals(t, 2, len(l))
+	equals(t, "hcl: parsed", l[0]["msg"])
+	equals(t, 11, l[0]["bytes"])
+	equals(t, 5, l[0]["tokens"]) // a, =, 1, the comment and EOF
+	equals(t, nil, l[0]["error"])
+	if _, ok := l[0]["duration"].(time.Duration); !ok {
+		t.Errorf("duration is %T", l[0]["duration"])
+	}
+
+	if l[1]["error"] == nil {
+		t.Error("expected the error of the second parse")
+	}
+}
+
+func TestConfigFilename(t *testing.T) {
+	c := &Config{Syntax: V1Lenient, Filename: "main.hcl"}
+	f, err := c.Parse([]byte("a = \"x ${b} y\"\n\n# comment\nc {\n  d = <<EOT\ntext\nEOT\n}\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var missing []string
+	check := func(n ast.Node) {
+		if pos := n.Pos(); pos.Filename != "main.hcl" {
+			missing = append(missing, fmt.Sprintf("%T at %s", n, pos))
+		}
+	}
+	ast.Walk(f.Node, func(n ast.Node) (ast.Node, bool) {
+		if n != nil {
+			check(n)
+		}
+		return n, true
+	})
+	for _, g := range f.Comments {
+		check(g)
+	}
+	if len(missing) > 0 {
+		t.Errorf("nodes without the file name: %q", missing)
+	}
+
+	_, err = c.Parse([]byte("a = \"b"))
+	if err == nil || !strings.HasPrefix(err.Error(), "At main.hcl:1:") {
+		t.Errorf("want an error in main.hcl, got %v", err)
+	}
+}
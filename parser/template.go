@@ -0,0 +1,713 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/fatih/hcl/ast"
+	"github.com/fatih/hcl/token"
+)
+
+// ParseTemplate parses the content of a string literal, without the
+// surrounding quotes, into a template. Escape sequences of the text are
+// resolved, every "${...}" interpolation is parsed into an expression and
+// "%{ for }" and "%{ if }" directives into *ast.ForDirective and
+// *ast.IfDirective. The sequences "$${" and "%%{" result in the literal text
+// "${" and "%{". pos is the position of the first character of text.
+// Errors are of type *PosError.
+func ParseTemplate(text string, pos token.Pos) (*ast.Template, error) {
+	return parseTemplate(text, pos, true, 0)
+}
+
+// ParseHeredoc parses the body of the HEREDOC token tok into a template,
+// like ParseTemplate does for strings. Heredocs have no escape sequences
+// other than "$${" and "%%{", and the indentation of an indented heredoc is
+// removed from its text.
+func ParseHeredoc(tok token.Token) (*ast.Template, error) {
+	body, pos, indent := tok.HeredocBody()
+	return parseTemplate(body, pos, false, indent)
+}
+
+// parseTemplate parses text starting at pos into a template. escapes is
+// whether the text has the escape sequences of strings, and indent the
+// number of spaces and tabs removed from the start of its lines.
+func parseTemplate(text string, pos token.Pos, escapes bool, indent int) (*ast.Template, error) {
+	t := &ast.Template{Start: pos}
+
+	// open is the stack of directives which are not terminated yet
+	var open []*directive
+
+	// parts returns the list new parts are added to
+	parts := func() *[]ast.Expr {
+		if len(open) == 0 {
+			return &t.Parts
+		}
+		return open[len(open)-1].parts()
+	}
+
+	// addText adds the text between start and end as an unescaped text part
+	addText := func(start, end int) error {
+		if start == end {
+			return nil
+		}
+
+		raw := text[start:end]
+		unquoted := unindent(text, start, end, indent)
+		if escapes {
+			var err error
+			if unquoted, err = strconv.Unquote(`"` + raw + `"`); err != nil {
+				return &PosError{
+					Pos: advance(pos, text[:start]),
+					Err: fmt.Errorf("invalid escape sequence in %q", raw),
+				}
+			}
+		}
+		if unquoted == "" {
+			// the indentation of a heredoc
+			return nil
+		}
+
+		// merge with the previous text, which is split by escaped "${" and
+		// "%{" sequences
+		p := parts()
+		if n := len(*p); n > 0 {
+			if prev, ok := (*p)[n-1].(*ast.TemplateText); ok {
+				prev.Text += unquoted
+				return nil
+			}
+		}
+
+		*p = append(*p, &ast.TemplateText{
+			Start: advance(pos, text[:start]),
+			Text:  unquoted,
+		})
+		return nil
+	}
+
+	start := 0
+	for i := 0; i < len(text); i++ {
+		if escapes && text[i] == '\\' {
+			i++ // skip the escaped character
+			continue
+		}
+
+		if text[i] != '$' && text[i] != '%' {
+			continue
+		}
+
+		// "$${" and "%%{" are escapes for a literal "${" and "%{", the
+		// escaping character is dropped
+		j := i + 1
+		for j < len(text) && text[j] == text[i] {
+			j++
+		}
+
+		if j >= len(text) || text[j] != '{' {
+			i = j - 1
+			continue
+		}
+
+		if j > i+1 {
+			if err := addText(start, j-1); err != nil {
+				return nil, err
+			}
+			start = j
+			i = j
+			continue
+		}
+
+		if err := addText(start, i); err != nil {
+			return nil, err
+		}
+
+		end := interpolationEnd(text, i+2)
+		if end < 0 {
+			kind := "interpolation"
+			if text[i] == '%' {
+				kind = "directive"
+			}
+			return nil, &PosError{
+				Pos: advance(pos, text[:i]),
+				Err: fmt.Errorf("%s not terminated, expected: }", kind),
+			}
+		}
+
+		if text[i] == '%' {
+			d, err := parseDirective(text[i+2:end], advance(pos, text[:i]), advance(pos, text[:i+2]))
+			if err != nil {
+				return nil, err
+			}
+
+			if open, err = d.apply(parts(), open); err != nil {
+				return nil, err
+			}
+		} else {
+			expr, err := parseExpr(text[i+2:end], advance(pos, text[:i+2]))
+			if err != nil {
+				return nil, err
+			}
+			p := parts()
+			*p = append(*p, expr)
+		}
+
+		i = end
+		start = end + 1
+	}
+
+	if err := addText(start, len(text)); err != nil {
+		return nil, err
+	}
+
+	if len(open) > 0 {
+		d := open[len(open)-1]
+		return nil, &PosError{
+			Pos: d.pos,
+			Err: fmt.Errorf("%s directive not terminated, expected: %%{ end%s }", d.keyword, d.keyword),
+		}
+	}
+
+	return t, nil
+}
+
+// directive is a single parsed "%{...}" directive. Directives are combined
+// into *ast.ForDirective and *ast.IfDirective nodes by apply.
+type directive struct {
+	keyword string    // for, if, else, endfor or endif
+	pos     token.Pos // position of "%{"
+	node    ast.Expr  // node of a for or if directive
+	inElse  bool      // whether an open if directive is in its else branch
+}
+
+// parseDirective parses the content of a directive. pos is the position of
+// the "%{" and start the position of the first character of src.
+func parseDirective(src string, pos, start token.Pos) (*directive, error) {
+	p := &exprParser{sc: &exprScanner{src: src, pos: start}}
+	p.next()
+
+	if p.tok.Type != token.IDENT {
+		return nil, p.errorf("expected directive keyword, got: %s", p.tok.Type)
+	}
+
+	keyword := p.tok
+	d := &directive{keyword: keyword.Text, pos: pos}
+	p.next()
+
+	switch d.keyword {
+	case "for":
+		f := &ast.ForDirective{For: pos}
+		if p.tok.Type != token.IDENT {
+			return nil, p.errorf("expected: IDENT got: %s", p.tok.Type)
+		}
+		f.Value = p.tok.Text
+		p.next()
+
+		if p.tok.Type == token.COMMA {
+			p.next()
+			if p.tok.Type != token.IDENT {
+				return nil, p.errorf("expected: IDENT got: %s", p.tok.Type)
+			}
+			f.Key, f.Value = f.Value, p.tok.Text
+			p.next()
+		}
+
+		if p.tok.Type != token.IDENT || p.tok.Text != "in" {
+			return nil, p.errorf("expected: in got: %s", p.tok.Type)
+		}
+		p.next()
+
+		expr, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		f.Collection = expr
+		d.node = f
+	case "if":
+		expr, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		d.node = &ast.IfDirective{If: pos, Cond: expr}
+	case "else", "endfor", "endif":
+	default:
+		return nil, &PosError{Pos: keyword.Pos, Err: fmt.Errorf("unknown directive %q", d.keyword)}
+	}
+
+	if p.tok.Type != token.EOF {
+		return nil, p.errorf("unexpected token %s in directive", p.tok.Type)
+	}
+	return d, nil
+}
+
+// apply adds the directive to parts, the list of the innermost open
+// directive, and returns the updated stack of open directives.
+func (d *directive) apply(parts *[]ast.Expr, open []*directive) ([]*directive, error) {
+	var top *directive
+	if len(open) > 0 {
+		top = open[len(open)-1]
+	}
+
+	switch d.keyword {
+	case "for", "if":
+		*parts = append(*parts, d.node)
+		return append(open, d), nil
+	case "else":
+		if top == nil || top.keyword != "if" || top.inElse {
+			return nil, &PosError{Pos: d.pos, Err: errors.New("else directive without if")}
+		}
+		top.inElse = true
+		return open, nil
+	}
+
+	// endfor or endif
+	if top == nil || "end"+top.keyword != d.keyword {
+		return nil, &PosError{
+			Pos: d.pos,
+			Err: fmt.Errorf("%s directive without %s", d.keyword, d.keyword[len("end"):]),
+		}
+	}
+
+	switch n := top.node.(type) {
+	case *ast.ForDirective:
+		n.EndFor = d.pos
+	case *ast.IfDirective:
+		n.EndIf = d.pos
+	}
+	return open[:len(open)-1], nil
+}
+
+// parts returns the list the parts inside of the open directive are added to
+func (d *directive) parts() *[]ast.Expr {
+	switch n := d.node.(type) {
+	case *ast.ForDirective:
+		return &n.Body
+	case *ast.IfDirective:
+		if d.inElse {
+			return &n.Else
+		}
+		return &n.True
+	}
+	return nil
+}
+
+// interpolationEnd returns the index of the brace closing the interpolation
+// which starts at the given index, or -1 if it's not terminated. Braces inside
+// of quoted strings are skipped.
+func interpolationEnd(text string, start int) int {
+	depth := 1
+	quoted := false
+	for i := start; i < len(text); i++ {
+		switch ch := text[i]; {
+		case ch == '\\' && quoted:
+			i++
+		case ch == '"':
+			quoted = !quoted
+		case quoted:
+		case ch == '{':
+			depth++
+		case ch == '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// exprParser parses the expression of an interpolation.
+type exprParser struct {
+	sc  *exprScanner
+	tok token.Token // last read token
+}
+
+// parseExpr parses the given source as a single expression. pos is the
+// position of the first character of src.
+func parseExpr(src string, pos token.Pos) (ast.Expr, error) {
+	p := &exprParser{sc: &exprScanner{src: src, pos: pos}}
+	p.next()
+
+	expr, err := p.expr()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.tok.Type != token.EOF {
+		return nil, p.errorf("unexpected token %s in interpolation", p.tok.Type)
+	}
+	return expr, nil
+}
+
+func (p *exprParser) next() {
+	p.tok = p.sc.scan()
+}
+
+func (p *exprParser) errorf(format string, args ...interface{}) error {
+	if p.sc.err != nil {
+		return p.sc.err
+	}
+	return &PosError{Pos: p.tok.Pos, Err: fmt.Errorf(format, args...)}
+}
+
+// expr parses an expression
+func (p *exprParser) expr() (ast.Expr, error) {
+	cond, err := p.binary(token.LowestPrec + 1)
+	if err != nil {
+		return nil, err
+	}
+
+	if p.tok.Type != token.QUESTION {
+		return cond, nil
+	}
+
+	c := &ast.Conditional{Cond: cond, Question: p.tok.Pos}
+	p.next()
+
+	if c.True, err = p.expr(); err != nil {
+		return nil, err
+	}
+
+	if p.tok.Type != token.COLON {
+		return nil, p.errorf("expected: COLON got: %s", p.tok.Type)
+	}
+	c.Colon = p.tok.Pos
+	p.next()
+
+	if c.False, err = p.expr(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// binary parses a binary expression whose operators have at least the given
+// precedence
+func (p *exprParser) binary(prec int) (ast.Expr, error) {
+	x, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		op := p.tok
+		oprec := op.Type.Precedence()
+		if oprec < prec {
+			return x, nil
+		}
+		p.next()
+
+		y, err := p.binary(oprec + 1)
+		if err != nil {
+			return nil, err
+		}
+
+		x = &ast.Binary{X: x, OpPos: op.Pos, Op: op.Type, Y: y}
+	}
+}
+
+// unary parses a unary expression
+func (p *exprParser) unary() (ast.Expr, error) {
+	op := p.tok
+	switch op.Type {
+	case token.SUB, token.NOT:
+		p.next()
+		x, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return &ast.Unary{OpPos: op.Pos, Op: op.Type, X: x}, nil
+	}
+
+	return p.primary()
+}
+
+// primary parses a literal, a variable, a function call or a parenthesized
+// expression
+func (p *exprParser) primary() (ast.Expr, error) {
+	tok := p.tok
+	switch tok.Type {
+	case token.LPAREN:
+		p.next()
+		x, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+
+		if p.tok.Type != token.RPAREN {
+			return nil, p.errorf("expected: RPAREN got: %s", p.tok.Type)
+		}
+		p.next()
+		return x, nil
+	case token.NUMBER, token.FLOAT, token.BOOL, token.NULL, token.STRING:
+		p.next()
+		return &ast.LiteralType{Token: tok}, nil
+	case token.IDENT:
+		p.next()
+		if p.tok.Type == token.LPAREN {
+			return p.call(tok)
+		}
+		return &ast.Variable{NamePos: tok.Pos, Name: tok.Text}, nil
+	case token.EOF:
+		return nil, p.errorf("expected expression, got: EOF")
+	}
+
+	return nil, p.errorf("unexpected token %s in interpolation", tok.Type)
+}
+
+// call parses the arguments of a function call, the current token is the
+// opening parenthesis
+func (p *exprParser) call(name token.Token) (ast.Expr, error) {
+	c := &ast.Call{
+		NamePos: name.Pos,
+		Name:    name.Text,
+		Lparen:  p.tok.Pos,
+	}
+	p.next()
+
+	for p.tok.Type != token.RPAREN {
+		arg, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		c.Args = append(c.Args, arg)
+
+		switch p.tok.Type {
+		case token.COMMA:
+			p.next()
+		case token.RPAREN:
+		default:
+			return nil, p.errorf("expected: COMMA | RPAREN got: %s", p.tok.Type)
+		}
+	}
+
+	c.Rparen = p.tok.Pos
+	p.next()
+	return c, nil
+}
+
+// exprScanner scans the tokens of an interpolation. Unlike the HCL scanner
+// it treats dots, stars and dashes as part of an identifier, so a variable
+// such as aws_instance.web-1.*.id is a single IDENT token. Operators must be
+// separated from identifiers by whitespace therefore.
+type exprScanner struct {
+	src string
+	off int       // current offset into src
+	pos token.Pos // position of src[off]
+	err error     // first error encountered
+}
+
+func (s *exprScanner) peek() rune {
+	if s.off >= len(s.src) {
+		return -1
+	}
+	ch, _ := utf8.DecodeRuneInString(s.src[s.off:])
+	return ch
+}
+
+func (s *exprScanner) next() rune {
+	if s.off >= len(s.src) {
+		return -1
+	}
+
+	ch, size := utf8.DecodeRuneInString(s.src[s.off:])
+	s.pos = advance(s.pos, s.src[s.off:s.off+size])
+	s.off += size
+	return ch
+}
+
+func (s *exprScanner) scan() token.Token {
+	for isSpace(s.peek()) {
+		s.next()
+	}
+
+	start, pos := s.off, s.pos
+	typ := token.ILLEGAL
+
+	switch ch := s.next(); {
+	case ch < 0:
+		typ = token.EOF
+	case ch == '_' || unicode.IsLetter(ch):
+		for isIdentChar(s.peek()) {
+			s.next()
+		}
+
+		typ = token.Lookup(s.src[start:s.off])
+	case '0' <= ch && ch <= '9':
+		typ = s.scanNumber()
+	case ch == '"':
+		typ = s.scanString(pos)
+	case ch == '(':
+		typ = token.LPAREN
+	case ch == ')':
+		typ = token.RPAREN
+	case ch == ',':
+		typ = token.COMMA
+	case ch == '+':
+		typ = token.ADD
+	case ch == '-':
+		typ = token.SUB
+	case ch == '*':
+		typ = token.MUL
+	case ch == '/':
+		typ = token.QUO
+	case ch == '%':
+		typ = token.MOD
+	case ch == '=' && s.peek() == '=':
+		s.next()
+		typ = token.EQL
+	case ch == '!':
+		typ = token.NOT
+		if s.peek() == '=' {
+			s.next()
+			typ = token.NEQ
+		}
+	case ch == '<':
+		typ = token.LSS
+		if s.peek() == '=' {
+			s.next()
+			typ = token.LEQ
+		}
+	case ch == '>':
+		typ = token.GTR
+		if s.peek() == '=' {
+			s.next()
+			typ = token.GEQ
+		}
+	case ch == '&' && s.peek() == '&':
+		s.next()
+		typ = token.AND
+	case ch == '|' && s.peek() == '|':
+		s.next()
+		typ = token.OR
+	case ch == '?':
+		typ = token.QUESTION
+	case ch == ':':
+		typ = token.COLON
+	default:
+		if s.err == nil {
+			s.err = &PosError{Pos: pos, Err: fmt.Errorf("illegal character %q in interpolation", ch)}
+		}
+	}
+
+	return token.Token{
+		Type: typ,
+		Pos:  pos,
+		Text: s.src[start:s.off],
+	}
+}
+
+func (s *exprScanner) scanNumber() token.Type {
+	typ := token.NUMBER
+	for isDigit(s.peek()) {
+		s.next()
+	}
+
+	if s.peek() == '.' {
+		typ = token.FLOAT
+		s.next()
+		for isDigit(s.peek()) {
+			s.next()
+		}
+	}
+
+	if ch := s.peek(); ch == 'e' || ch == 'E' {
+		s.next()
+		if ch := s.peek(); ch == '+' || ch == '-' {
+			s.next()
+		}
+		for isDigit(s.peek()) {
+			s.next()
+		}
+	}
+
+	return typ
+}
+
+// scanString scans a quoted string, which might contain interpolations
+// itself. The opening quote is already consumed.
+func (s *exprScanner) scanString(pos token.Pos) token.Type {
+	braces := 0
+	for {
+		ch := s.next()
+		switch {
+		case ch < 0:
+			if s.err == nil {
+				s.err = &PosError{Pos: pos, Err: errors.New("literal not terminated")}
+			}
+			return token.STRING
+		case ch == '\\':
+			s.next()
+		case ch == '"' && braces == 0:
+			return token.STRING
+		case ch == '$' || ch == '%':
+			n := 1
+			for s.peek() == ch {
+				s.next()
+				n++
+			}
+
+			if s.peek() == '{' {
+				s.next()
+				if n == 1 {
+					braces++
+				}
+			}
+		case ch == '{' && braces > 0:
+			braces++
+		case ch == '}' && braces > 0:
+			braces--
+		}
+	}
+}
+
+func isSpace(ch rune) bool {
+	return ch == ' ' || ch == '\t' || ch == '\n' || ch == '\r'
+}
+
+func isDigit(ch rune) bool {
+	return '0' <= ch && ch <= '9'
+}
+
+func isIdentChar(ch rune) bool {
+	return ch == '_' || ch == '-' || ch == '.' || ch == '*' ||
+		unicode.IsLetter(ch) || unicode.IsDigit(ch)
+}
+
+// unindent returns the text between start and end with up to n spaces and
+// tabs removed from the start of its lines. The first line is kept if start
+// isn't at the start of a line.
+func unindent(text string, start, end, n int) string {
+	if n == 0 {
+		return text[start:end]
+	}
+
+	lines := strings.SplitAfter(text[start:end], "\n")
+	for i, line := range lines {
+		if i == 0 && start > 0 && text[start-1] != '\n' {
+			continue
+		}
+
+		j := 0
+		for j < n && j < len(line) && (line[j] == ' ' || line[j] == '\t') {
+			j++
+		}
+		lines[i] = line[j:]
+	}
+	return strings.Join(lines, "")
+}
+
+// advance returns the position immediately after text starting at pos.
+func advance(pos token.Pos, text string) token.Pos {
+	pos.Offset += len(text)
+	for _, ch := range text {
+		if ch == '\n' {
+			pos.Line++
+			pos.Column = 1
+			continue
+		}
+		pos.Column++
+	}
+	return pos
+}
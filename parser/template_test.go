@@ -0,0 +1,247 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/fatih/hcl/ast"
+	"github.com/fatih/hcl/token"
+)
+
+func TestParseTemplate(t *testing.T) {
+	cases := []struct {
+		text  string
+		parts []string
+	}{
+		{``, nil},
+		{`foo`, []string{`text "foo"`}},
+		{`foo\n\"bar\"`, []string{"text \"foo\\n\\\"bar\\\"\""}},
+		{`${var.foo}`, []string{"var var.foo"}},
+		{`web-${count.index}-x`, []string{`text "web-"`, "var count.index", `text "-x"`}},
+		{`${ aws_instance.web.*.id }`, []string{"var aws_instance.web.*.id"}},
+		{`${42}${-1.5}${true}`, []string{"lit NUMBER 42", "(SUB lit FLOAT 1.5)", "lit BOOL true"}},
+		{`${base_port + 1}`, []string{"(var base_port ADD lit NUMBER 1)"}},
+		{`${a + b * c - d}`, []string{"((var a ADD (var b MUL var c)) SUB var d)"}},
+		{`${(a + b) * c}`, []string{"((var a ADD var b) MUL var c)"}},
+		{`${a ? b : c}`, []string{"(var a ? var b : var c)"}},
+		{`${a == 1 ? "x" : b ? 2 : 3}`, []string{`((var a EQL lit NUMBER 1) ? lit STRING "x" : (var b ? lit NUMBER 2 : lit NUMBER 3))`}},
+		{`${a -1}`, []string{"(var a SUB lit NUMBER 1)"}},
+		{`${!a && b == 1 || c >= 2}`, []string{"(((NOT var a) AND (var b EQL lit NUMBER 1)) OR (var c GEQ lit NUMBER 2))"}},
+		{`${a != b}${a<=b}${a>b}${a % 2}${a / 2}`, []string{"(var a NEQ var b)", "(var a LEQ var b)", "(var a GTR var b)", "(var a MOD lit NUMBER 2)", "(var a QUO lit NUMBER 2)"}},
+		{`${"}"}`, []string{`lit STRING "}"`}},
+		{`${upper(var.name)}`, []string{"call upper(var var.name)"}},
+		{`${join(",", split("-", var.x), f())}`, []string{`call join(lit STRING ",", call split(lit STRING "-", var var.x), call f())`}},
+		{`a\\${b}`, []string{`text "a\\"`, "var b"}},
+		{`$${literal}`, []string{`text "${literal}"`}},
+		{`a%%{literal}b$$${x}`, []string{`text "a%{literal}b$${x}"`}},
+		{`$$ and %% ${x}`, []string{`text "$$ and %% "`, "var x"}},
+		{`${f("$${a}")}`, []string{`call f(lit STRING "$${a}")`}},
+		{`%{ for x in list }${x}%{ endfor }`, []string{"for x in var list {var x}"}},
+		{`a%{for k, v in m}${k}=${v}%{endfor}b`, []string{`text "a"`, `for k, v in var m {var k, text "=", var v}`, `text "b"`}},
+		{`%{ if a == 1 }x%{ endif }`, []string{`if (var a EQL lit NUMBER 1) {text "x"}`}},
+		{`%{ if a }x%{ else }y%{ endif }`, []string{`if var a {text "x"} else {text "y"}`}},
+		{`%{ for x in l }%{ if x }${x}%{ endif }%{ endfor }`, []string{"for x in var l {if var x {var x}}"}},
+	}
+
+	for _, c := range cases {
+		tmpl, err := ParseTemplate(c.text, token.Pos{Line: 1, Column: 2, Offset: 1})
+		if err != nil {
+			t.Errorf("%q: %s", c.text, err)
+			continue
+		}
+
+		var parts []string
+		for _, part := range tmpl.Parts {
+			parts = append(parts, exprString(part))
+		}
+
+		equals(t, c.parts, parts)
+	}
+}
+
+// exprString returns a short textual form of an expression for comparisons
+func exprString(e ast.Expr) string {
+	switch p := e.(type) {
+	case *ast.TemplateText:
+		return fmt.Sprintf("text %q", p.Text)
+	case *ast.Variable:
+		return "var " + p.Name
+	case *ast.LiteralType:
+		return fmt.Sprintf("lit %s %s", p.Token.Type, p.Token.Text)
+	case *ast.Call:
+		args := make([]string, 0, len(p.Args))
+		for _, arg := range p.Args {
+			args = append(args, exprString(arg))
+		}
+		return fmt.Sprintf("call %s(%s)", p.Name, strings.Join(args, ", "))
+	case *ast.Binary:
+		return fmt.Sprintf("(%s %s %s)", exprString(p.X), p.Op, exprString(p.Y))
+	case *ast.Unary:
+		return fmt.Sprintf("(%s %s)", p.Op, exprString(p.X))
+	case *ast.Conditional:
+		return fmt.Sprintf("(%s ? %s : %s)", exprString(p.Cond), exprString(p.True), exprString(p.False))
+	case *ast.ForDirective:
+		vars := p.Value
+		if p.Key != "" {
+			vars = p.Key + ", " + p.Value
+		}
+		return fmt.Sprintf("for %s in %s {%s}", vars, exprString(p.Collection), partsString(p.Body))
+	case *ast.IfDirective:
+		s := fmt.Sprintf("if %s {%s}", exprString(p.Cond), partsString(p.True))
+		if p.Else != nil {
+			s += fmt.Sprintf(" else {%s}", partsString(p.Else))
+		}
+		return s
+	}
+	return fmt.Sprintf("%T", e)
+}
+
+func partsString(parts []ast.Expr) string {
+	s := make([]string, 0, len(parts))
+	for _, part := range parts {
+		s = append(s, exprString(part))
+	}
+	return strings.Join(s, ", ")
+}
+
+func TestParseTemplatePos(t *testing.T) {
+	tmpl, err := ParseTemplate(`ab${ var.foo }`, token.Pos{Line: 3, Column: 5, Offset: 20})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	v := tmpl.Parts[1].(*ast.Variable)
+	equals(t, token.Pos{Line: 3, Column: 10, Offset: 25}, v.Pos())
+}
+
+func TestParseHeredoc(t *testing.T) {
+	cases := []struct {
+		text  string
+		parts string
+	}{
+		{"<<EOF\nhello\nEOF", `text "hello\n"`},
+		{"<<EOF\na\\n \"${b}\"\nEOF", `text "a\\n \"", var b, text "\"\n"`},
+		{"<<-EOF\n  a ${b}\n    c\n  EOF", `text "a ", var b, text "\n  c\n"`},
+		{"<<-EOF\n  ${b}  c\n  ${d}\n  EOF", `var b, text "  c\n", var d, text "\n"`},
+		{"<<-EOF\n  %{ if a }\n    b\n  %{ endif }\n  EOF", `if var a {text "\n  b\n"}, text "\n"`},
+	}
+
+	for _, c := range cases {
+		tmpl, err := ParseHeredoc(token.Token{Type: token.HEREDOC, Pos: token.Pos{Line: 1, Column: 5, Offset: 4}, Text: c.text})
+		if err != nil {
+			t.Errorf("%q: %s", c.text, err)
+			continue
+		}
+
+		if got := partsString(tmpl.Parts); got != c.parts {
+			t.Errorf("%q: want %s, got %s", c.text, c.parts, got)
+		}
+	}
+
+	tmpl, err := ParseHeredoc(token.Token{Type: token.HEREDOC, Pos: token.Pos{Line: 1, Column: 5, Offset: 4}, Text: "<<EOF\nab\n ${ c }\nEOF"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	equals(t, token.Pos{Line: 3, Column: 5, Offset: 17}, tmpl.Parts[1].Pos())
+
+	_, err = ParseHeredoc(token.Token{Type: token.HEREDOC, Pos: token.Pos{Line: 1, Column: 5, Offset: 4}, Text: "<<EOF\na\n ${b\nEOF"})
+	equals(t, "At 3:2: interpolation not terminated, expected: }", fmt.Sprint(err))
+}
+
+func TestParseTemplateError(t *testing.T) {
+	cases := []struct {
+		text   string
+		column int
+	}{
+		{`foo ${bar`, 5},
+		{`${}`, 3},
+		{`${a b}`, 5},
+		{`${a ! b}`, 5},
+		{`${"abc}`, 1},
+		{`\q`, 1},
+		{`${f(a b)}`, 7},
+		{`${f(a,}`, 7},
+		{`${(a + b}`, 9},
+		{`${a + }`, 7},
+		{`${a = b}`, 5},
+		{`${a ? b}`, 8},
+		{`${a ? b c}`, 9},
+		{`%{ for x in l }`, 1},
+		{`%{ if a }x%{ else }y%{ else }z%{ endif }`, 21},
+		{`%{ for x in l }%{ endif }`, 16},
+		{`%{ endfor }`, 1},
+		{`%{ while a }`, 4},
+		{`%{ for x l }`, 10},
+		{`%{ for x in }`, 13},
+		{`%{ if a b }`, 9},
+		{`%{ if a`, 1},
+	}
+
+	for _, c := range cases {
+		_, err := ParseTemplate(c.text, token.Pos{Line: 1, Column: 1})
+		perr, ok := err.(*PosError)
+		if !ok {
+			t.Errorf("%q: want: *PosError got: %v", c.text, err)
+			continue
+		}
+
+		if perr.Pos.Column != c.column {
+			t.Errorf("%q: want column: %d got: %s", c.text, c.column, perr)
+		}
+	}
+}
+
+func TestLiteralTemplate(t *testing.T) {
+	cases := []struct {
+		src   string
+		parts string // empty if there's no template
+	}{
+		{`a = "foo"`, ``},
+		{`a = 12`, ``},
+		{`a = "x-${upper(var.name)}"`, `text "x-", call upper(var var.name)`},
+		{`a = "%{ if b }c%{ endif }"`, `if var b {text "c"}`},
+		{`a = "${a b}"`, ``},
+		{`a = "${lookup(var.map, "}")}-${b}"`, `call lookup(var var.map, lit STRING "}"), text "-", var b`},
+		{"a = <<EOF\nx-${var.name}\nEOF", `text "x-", var var.name, text "\n"`},
+		{"a = <<EOF\nx-$${var.name}\nEOF", `text "x-${var.name}\n"`},
+		{"a = <<EOF\nplain\nEOF", ``},
+	}
+
+	for _, c := range cases {
+		f, err := Parse([]byte(c.src))
+		if err != nil {
+			t.Fatalf("%s: %s", c.src, err)
+		}
+
+		lit := f.Node.(*ast.ObjectList).Items[0].Val.(*ast.LiteralType)
+		if lit.Template == nil {
+			if c.parts != "" {
+				t.Errorf("%s: no template", c.src)
+			}
+			continue
+		}
+
+		if got := partsString(lit.Template.Parts); got != c.parts {
+			t.Errorf("%s: want %s, got %s", c.src, c.parts, got)
+		}
+	}
+
+	f, err := Parse([]byte(`a = "${b}"`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var v *ast.Variable
+	ast.Walk(f, func(n ast.Node) (ast.Node, bool) {
+		if n, ok := n.(*ast.Variable); ok {
+			v = n
+		}
+		return n, true
+	})
+
+	if v == nil {
+		t.Fatal("Walk didn't visit the template")
+	}
+	equals(t, token.Pos{Line: 1, Column: 8, Offset: 7}, v.Pos())
+}
@@ -0,0 +1,115 @@
+package hcl
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/fatih/hcl/ast"
+	"github.com/fatih/hcl/token"
+)
+
+// RegisterParser registers fn as the parser name of c, for the struct fields
+// with the tag parse:"name", i.e. `hcl:"schedule" parse:"cron"` for a field
+// of a type returned by fn. The values of these fields must be strings,
+// which fn validates and converts at decode time. The elements of slices
+// are parsed one by one.
+func (c *DecoderConfig) RegisterParser(name string, fn Converter) {
+	if c.Parsers == nil {
+		c.Parsers = make(map[string]Converter)
+	}
+	c.Parsers[name] = fn
+}
+
+// fieldParser returns the name and the parser of a field with a parse tag,
+// or an empty name if it has none
+func (d *decoder) fieldParser(name string, pos token.Pos, field reflect.StructField) (string, Converter, error) {
+	parser := field.Tag.Get("parse")
+	if parser == "" {
+		return "", nil, nil
+	}
+
+	fn, ok := d.config.Parsers[parser]
+	if !ok {
+		return "", nil, posErrorf(pos, "%s: unknown parser %q", name, parser)
+	}
+	return parser, fn, nil
+}
+
+// parseNodes decodes the strings of nodes into rv with the parser fn, into
+// the elements of rv if it's a slice
+func (d *decoder) parseNodes(name, parser string, fn Converter, nodes []ast.Node, rv reflect.Value) error {
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			rv.Set(reflect.New(rv.Type().Elem()))
+		}
+		rv = rv.Elem()
+	}
+
+	if rv.Kind() != reflect.Slice {
+		for _, n := range nodes {
+			if err := d.parseNode(name, parser, fn, n, rv); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	slice := reflect.MakeSlice(rv.Type(), 0, len(nodes))
+	for _, n := range nodes {
+		elems := []ast.Node{n}
+		if list, ok := n.(*ast.ListType); ok {
+			elems = list.List
+		}
+
+		for _, elem := range elems {
+			ev := reflect.New(rv.Type().Elem()).Elem()
+			if err := d.parseNode(fmt.Sprintf("%s[%d]", name, slice.Len()), parser, fn, elem, ev); err != nil {
+				return err
+			}
+			slice = reflect.Append(slice, ev)
+		}
+	}
+	rv.Set(slice)
+	return nil
+}
+
+// parseNode decodes the string n into rv with the parser fn
+func (d *decoder) parseNode(name, parser string, fn Converter, n ast.Node, rv reflect.Value) error {
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			rv.Set(reflect.New(rv.Type().Elem()))
+		}
+		rv = rv.Elem()
+	}
+
+	var v interface{}
+	if lit, ok := n.(*ast.LiteralType); ok && (lit.Token.Type == token.STRING || lit.Token.Type == token.HEREDOC) {
+		var err error
+		if v, err = d.literal(lit); err != nil {
+			return err
+		}
+	}
+
+	s, ok := v.(string)
+	if !ok {
+		return posErrorf(n.Pos(), "%s: parser %s expects a string, got %s", name, parser, nodeName(n))
+	}
+
+	v, err := fn(s)
+	if err != nil {
+		return posErrorf(n.Pos(), "%s: cannot parse %q as %s: %s", name, s, parser, err)
+	}
+
+	val := reflect.ValueOf(v)
+	switch {
+	case !val.IsValid():
+		return posErrorf(n.Pos(), "%s: parser %s returned nil", name, parser)
+	case val.Type().AssignableTo(rv.Type()):
+		rv.Set(val)
+	case val.Type().ConvertibleTo(rv.Type()) && val.Kind() == rv.Kind():
+		rv.Set(val.Convert(rv.Type()))
+	default:
+		return posErrorf(n.Pos(), "%s: parser %s returned %T, not %s", name, parser, v, rv.Type())
+	}
+	return nil
+}
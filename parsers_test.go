@@ -0,0 +1,109 @@
+package hcl
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/fatih/hcl/parser"
+)
+
+// schedule is a cron expression with five fields
+type schedule struct {
+	Fields []string
+}
+
+func parseCron(s string) (interface{}, error) {
+	fields := strings.Fields(s)
+	if len(fields) != 5 {
+		return nil, errors.New("expected 5 fields")
+	}
+	return schedule{Fields: fields}, nil
+}
+
+type job struct {
+	Name     string     `hcl:"name"`
+	Schedule schedule   `hcl:"schedule" parse:"cron"`
+	Backup   *schedule  `hcl:"backup" parse:"cron"`
+	Windows  []schedule `hcl:"windows" parse:"cron"`
+	Owner    userName   `hcl:"owner" parse:"lower"`
+}
+
+type userName string
+
+func parsersConfig() *DecoderConfig {
+	dc := &DecoderConfig{}
+	dc.RegisterParser("cron", parseCron)
+	dc.RegisterParser("lower", func(s string) (interface{}, error) { return strings.ToLower(s), nil })
+	return dc
+}
+
+func TestDecoderConfigParsers(t *testing.T) {
+	src := `name = "cleanup"
+schedule = "0 3 * * *"
+backup = "30 2 * * 0"
+windows = ["0 1 * * *"]
+windows = ["0 2 * * *", "0 4 * * *"]
+owner = "Ops"
+`
+
+	var out job
+	if err := parsersConfig().Decode(&out, src); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := strings.Join(out.Schedule.Fields, " "); got != "0 3 * * *" {
+		t.Errorf("unexpected schedule: %q", got)
+	}
+	if out.Backup == nil || out.Backup.Fields[0] != "30" {
+		t.Errorf("unexpected backup: %+v", out.Backup)
+	}
+	if len(out.Windows) != 3 || out.Windows[2].Fields[1] != "4" {
+		t.Errorf("unexpected windows: %+v", out.Windows)
+	}
+	if out.Owner != "ops" {
+		t.Errorf("unexpected owner: %q", out.Owner)
+	}
+}
+
+func TestDecoderConfigParsersError(t *testing.T) {
+	cases := []struct {
+		src string
+		err string
+	}{
+		{`schedule = "daily"`, `At 1:12: root.schedule: cannot parse "daily" as cron: expected 5 fields`},
+		{`windows = ["0 1 * * *", "hourly"]`, `At 1:25: root.windows[1]: cannot parse "hourly" as cron: expected 5 fields`},
+		{`schedule = 5`, `At 1:12: root.schedule: parser cron expects a string, got number`},
+		{`schedule { minute = 0 }`, `At 1:10: root.schedule: parser cron expects a string, got object`},
+	}
+
+	for _, c := range cases {
+		var out job
+		err := parsersConfig().Decode(&out, c.src)
+		if err == nil || err.Error() != c.err {
+			t.Errorf("%s: want error %q, got: %v", c.src, c.err, err)
+		}
+
+		f, perr := parser.Parse([]byte(c.src))
+		if perr != nil {
+			t.Fatal(perr)
+		}
+		if errs := parsersConfig().CheckTypes(f, out); len(errs) != 1 || errs[0].Error() != c.err {
+			t.Errorf("%s: want check error %q, got: %v", c.src, c.err, errs)
+		}
+	}
+
+	// parsers must be registered and return the type of the field
+	var out job
+	err := Decode(&out, `schedule = "0 3 * * *"`)
+	if err == nil || err.Error() != `At 1:12: root.schedule: unknown parser "cron"` {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	dc := parsersConfig()
+	dc.RegisterParser("cron", func(s string) (interface{}, error) { return s, nil })
+	err = dc.Decode(&out, `schedule = "0 3 * * *"`)
+	if err == nil || err.Error() != `At 1:12: root.schedule: parser cron returned string, not hcl.schedule` {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
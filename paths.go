@@ -0,0 +1,78 @@
+package hcl
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+)
+
+// Path is a file system path. Strings decoded into a Path are normalized by
+// ParsePath, so configurations can use "/" as the separator and "~" for the
+// home directory on every operating system. Paths are encoded with "/".
+type Path string
+
+// Glob is a file name pattern, such as "conf.d/*.hcl", with the syntax of
+// filepath.Match. Strings decoded into a Glob are normalized and validated
+// by ParseGlob. Globs are encoded with "/".
+type Glob string
+
+var (
+	pathType = reflect.TypeOf(Path(""))
+	globType = reflect.TypeOf(Glob(""))
+)
+
+// ParsePath returns s as a cleaned Path with the separators of the operating
+// system, i.e. `conf\app.hcl` for "conf/app.hcl" on Windows. A leading "~"
+// is replaced by the home directory of the current user. It's the converter
+// of strings into Paths, it can be registered as a parser for fields of type
+// string, i.e. RegisterParser("path", ParsePath) for `parse:"path"`.
+func ParsePath(s string) (interface{}, error) {
+	p, err := normalizePath(s)
+	return Path(p), err
+}
+
+// ParseGlob returns s as a Glob with the separators of the operating system
+// like ParsePath, and an error if it's not a valid pattern.
+func ParseGlob(s string) (interface{}, error) {
+	p, err := normalizePath(s)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := filepath.Match(p, ""); err != nil {
+		return nil, errors.New("invalid pattern")
+	}
+	return Glob(p), nil
+}
+
+// normalizePath returns s cleaned with the separators of the operating system
+// and "~" expanded. Empty strings are kept, i.e. for optional paths.
+func normalizePath(s string) (string, error) {
+	if s == "" {
+		return "", nil
+	}
+
+	p := filepath.FromSlash(s)
+	if p == "~" || strings.HasPrefix(p, "~"+string(filepath.Separator)) {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		p = home + p[1:]
+	}
+	return filepath.Clean(p), nil
+}
+
+// Match reports whether name matches the pattern, see filepath.Match.
+func (g Glob) Match(name string) bool {
+	ok, _ := filepath.Match(string(g), name)
+	return ok
+}
+
+// Files returns the names of the files matching the pattern, see
+// filepath.Glob.
+func (g Glob) Files() ([]string, error) {
+	return filepath.Glob(string(g))
+}
@@ -0,0 +1,89 @@
+package hcl
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type pathConfig struct {
+	Config  Path   `hcl:"config"`
+	Cache   *Path  `hcl:"cache"`
+	Include []Glob `hcl:"include"`
+	Log     string `hcl:"log" parse:"path"`
+}
+
+func TestDecodePaths(t *testing.T) {
+	t.Setenv("HOME", "/home/app")
+	t.Setenv("USERPROFILE", `C:\Users\app`)
+	home := "/home/app"
+	if filepath.Separator == '\\' {
+		home = `C:\Users\app`
+	}
+
+	src := `config = "./conf/../etc/app.hcl"
+cache = "~/.cache/app/"
+include = ["conf.d/*.hcl", "~"]
+log = "/var/log//app.log"
+`
+
+	c := DecoderConfig{}
+	c.RegisterParser("path", ParsePath)
+
+	var out pathConfig
+	if err := c.Decode(&out, src); err != nil {
+		t.Fatal(err)
+	}
+
+	cache := Path(filepath.Join(home, ".cache", "app"))
+	want := pathConfig{
+		Config:  Path(filepath.Join("etc", "app.hcl")),
+		Cache:   &cache,
+		Include: []Glob{Glob(filepath.Join("conf.d", "*.hcl")), Glob(home)},
+		Log:     filepath.FromSlash("/var/log/app.log"),
+	}
+	if !reflect.DeepEqual(out, want) {
+		t.Errorf("want %+v\ngot  %+v", want, out)
+	}
+
+	if !out.Include[0].Match(filepath.Join("conf.d", "web.hcl")) || out.Include[0].Match("web.hcl") {
+		t.Errorf("%s matches unexpected files", out.Include[0])
+	}
+
+	// encoded with slashes
+	res, err := Marshal(struct {
+		Config Path `hcl:"config"`
+		Glob   Glob `hcl:"glob"`
+	}{out.Config, out.Include[0]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := string(res); !strings.Contains(s, `"etc/app.hcl"`) || !strings.Contains(s, `"conf.d/*.hcl"`) {
+		t.Errorf("unexpected encoding:\n%s", s)
+	}
+}
+
+func TestDecodePathErrors(t *testing.T) {
+	var out pathConfig
+	err := Decode(&out, `include = ["conf.d/[a-"]`)
+	if want := `At 1:12: root.include[0]: cannot convert "conf.d/[a-" into hcl.Glob: invalid pattern`; err == nil || err.Error() != want {
+		t.Errorf("want error %q, got %v", want, err)
+	}
+
+	// the home directory is unknown
+	t.Setenv("HOME", "")
+	t.Setenv("USERPROFILE", "")
+	if err := Decode(&out, `config = "~/app.hcl"`); err == nil {
+		t.Errorf("want error for ~ without home directory")
+	}
+}
+
+func TestParsePathEmpty(t *testing.T) {
+	for _, fn := range []Converter{ParsePath, ParseGlob} {
+		v, err := fn("")
+		if err != nil || reflect.ValueOf(v).String() != "" {
+			t.Errorf("want empty value, got %q, %v", v, err)
+		}
+	}
+}
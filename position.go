@@ -0,0 +1,181 @@
+package hcl
+
+import (
+	"fmt"
+
+	"github.com/fatih/hcl/ast"
+	"github.com/fatih/hcl/token"
+)
+
+// Value is a decoded value with the position of its source, i.e. for policy
+// engines reporting where a violating value was written.
+type Value struct {
+	// Val is a string, int, float64 or bool, or []*Value for lists and
+	// map[string]*Value for objects.
+	Val interface{}
+
+	// Pos is the position of the literal, list or object. Values returned
+	// by a Resolver, and their elements, have the position of the string.
+	Pos token.Pos
+}
+
+// Interface returns the value without positions, as decoded into an empty
+// interface.
+func (v *Value) Interface() interface{} {
+	switch t := v.Val.(type) {
+	case []*Value:
+		list := make([]interface{}, len(t))
+		for i, elem := range t {
+			list[i] = elem.Interface()
+		}
+		return list
+	case map[string]*Value:
+		m := make(map[string]interface{}, len(t))
+		for k, elem := range t {
+			m[k] = elem.Interface()
+		}
+		return m
+	}
+	return v.Val
+}
+
+// Get returns the value at the given keys of nested objects, or nil if
+// there's none.
+func (v *Value) Get(keys ...string) *Value {
+	for _, k := range keys {
+		m, ok := v.Val.(map[string]*Value)
+		if !ok {
+			return nil
+		}
+
+		if v, ok = m[k]; !ok {
+			return nil
+		}
+	}
+	return v
+}
+
+// DecodeValue parses src and decodes it with positions. See
+// DecoderConfig.DecodeValueObject.
+func (c *DecoderConfig) DecodeValue(src string) (*Value, error) {
+	f, err := c.parse(src)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.DecodeValueObject(f)
+}
+
+// DecodeValueObject decodes n like DecodeObject into an empty interface, but
+// every value is annotated with its position. Items with the same key are
+// merged if their values are objects, otherwise the last one wins.
+func (c *DecoderConfig) DecodeValueObject(n ast.Node) (*Value, error) {
+	d := &decoder{config: c}
+	return d.posValue("root", n)
+}
+
+// DecodeValue parses src and decodes it with positions with
+// DefaultDecoderConfig.
+func DecodeValue(src string) (*Value, error) {
+	return DefaultDecoderConfig.DecodeValue(src)
+}
+
+// DecodeValueObject decodes n with positions with DefaultDecoderConfig.
+func DecodeValueObject(n ast.Node) (*Value, error) {
+	return DefaultDecoderConfig.DecodeValueObject(n)
+}
+
+// posValue returns the value of n annotated with positions
+func (d *decoder) posValue(name string, n ast.Node) (*Value, error) {
+	switch t := n.(type) {
+	case *ast.File:
+		return d.posValue(name, t.Node)
+	case *ast.ObjectType:
+		if t.List == nil {
+			return &Value{Val: map[string]*Value{}, Pos: t.Lbrace}, nil
+		}
+
+		v, err := d.posValue(name, t.List)
+		if err != nil {
+			return nil, err
+		}
+		v.Pos = t.Lbrace
+		return v, nil
+	case *ast.ObjectList:
+		if err := d.onBlock(t); err != nil {
+			return nil, err
+		}
+
+		m := make(map[string]*Value)
+		keys, values := fields(t)
+		for _, key := range keys {
+			for _, n := range values[key] {
+				v, err := d.posValue(name+"."+ast.PathKey(key), n)
+				if err != nil {
+					return nil, err
+				}
+				m[key] = mergePosValues(m[key], v)
+			}
+		}
+		return &Value{Val: m, Pos: t.Pos()}, nil
+	case *ast.ListType:
+		list := make([]*Value, 0, len(t.List))
+		for i, elem := range t.List {
+			v, err := d.posValue(fmt.Sprintf("%s[%d]", name, i), elem)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, v)
+		}
+		return &Value{Val: list, Pos: t.Lbrack}, nil
+	case *ast.LiteralType:
+		v, err := d.literal(t)
+		if err != nil {
+			return nil, err
+		}
+		return posValue(v, t.Pos()), nil
+	}
+
+	return nil, posErrorf(n.Pos(), "%s: cannot decode %s", name, nodeName(n))
+}
+
+// posValue annotates v and its elements with pos
+func posValue(v interface{}, pos token.Pos) *Value {
+	switch t := v.(type) {
+	case []interface{}:
+		list := make([]*Value, len(t))
+		for i, elem := range t {
+			list[i] = posValue(elem, pos)
+		}
+		return &Value{Val: list, Pos: pos}
+	case map[string]interface{}:
+		m := make(map[string]*Value, len(t))
+		for k, elem := range t {
+			m[k] = posValue(elem, pos)
+		}
+		return &Value{Val: m, Pos: pos}
+	}
+	return &Value{Val: v, Pos: pos}
+}
+
+// mergePosValues merges b into a if both are objects, otherwise it returns b
+func mergePosValues(a, b *Value) *Value {
+	if a == nil {
+		return b
+	}
+
+	ma, ok := a.Val.(map[string]*Value)
+	if !ok {
+		return b
+	}
+
+	mb, ok := b.Val.(map[string]*Value)
+	if !ok {
+		return b
+	}
+
+	for k, v := range mb {
+		ma[k] = mergePosValues(ma[k], v)
+	}
+	return a
+}
@@ -0,0 +1,91 @@
+package hcl
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/fatih/hcl/ast"
+	"github.com/fatih/hcl/eval"
+)
+
+func TestDecodeValue(t *testing.T) {
+	src := `name = "app"
+service "web" {
+  port  = 80
+  hosts = ["a", "b"]
+}
+service "db" {
+  port = 5432
+}
+`
+
+	v, err := DecodeValue(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		keys []string
+		val  interface{}
+		pos  string
+	}{
+		{[]string{"name"}, "app", "1:8"},
+		{[]string{"service", "web"}, nil, "2:15"},
+		{[]string{"service", "web", "port"}, 80, "3:11"},
+		{[]string{"service", "db", "port"}, 5432, "7:10"},
+		{[]string{"service", "web", "hosts"}, nil, "4:11"},
+	}
+
+	for _, c := range cases {
+		got := v.Get(c.keys...)
+		if got == nil {
+			t.Errorf("%v: not found", c.keys)
+			continue
+		}
+
+		if c.val != nil && got.Val != c.val {
+			t.Errorf("%v: want: %#v, got: %#v", c.keys, c.val, got.Val)
+		}
+
+		if got.Pos.String() != c.pos {
+			t.Errorf("%v: want position %s, got: %s", c.keys, c.pos, got.Pos)
+		}
+	}
+
+	if elem := v.Get("service", "web", "hosts").Val.([]*Value)[1]; elem.Val != "b" || elem.Pos.String() != "4:17" {
+		t.Errorf("unexpected list element: %#v", elem)
+	}
+
+	if v.Get("service", "missing") != nil || v.Get("name", "x") != nil {
+		t.Error("expected nil for missing keys")
+	}
+
+	// the values equal the ones decoded into an empty interface
+	var expected interface{}
+	if err := Decode(&expected, src); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(v.Interface(), expected) {
+		t.Errorf("want: %#v, got: %#v", expected, v.Interface())
+	}
+}
+
+func TestDecodeValueResolver(t *testing.T) {
+	config := &DecoderConfig{
+		Resolver: func(t *ast.Template) (interface{}, error) {
+			return eval.Eval(t, map[string]interface{}{"var.hosts": []interface{}{"a", "b"}})
+		},
+	}
+
+	v, err := config.DecodeValue(`hosts = "${var.hosts}"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, elem := range v.Get("hosts").Val.([]*Value) {
+		if elem.Pos.String() != "1:9" {
+			t.Errorf("want the position of the string, got: %s", elem.Pos)
+		}
+	}
+}
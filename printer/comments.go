@@ -0,0 +1,99 @@
+package printer
+
+import (
+	"strings"
+)
+
+// CommentStyle is the syntax of the comments printed by Config.Fprint.
+type CommentStyle int
+
+const (
+	// KeepComments prints comments as they are in the source.
+	KeepComments CommentStyle = iota
+
+	// HashComments prints comments as line comments starting with #.
+	HashComments
+
+	// SlashComments prints comments as line comments starting with //.
+	SlashComments
+
+	// BlockComments prints comments as /* */ comments.
+	BlockComments
+)
+
+// commentText returns the text of the comment converted to the style of the
+// config. Every line of a multiline block comment becomes a line comment of
+// its own, unless the comment is the line comment of an item, whose lines
+// are joined. Line comments containing */ can't be block comments and are
+// kept.
+func (p *printer) commentText(text string, lineComment bool) string {
+	style := p.cfg.CommentStyle
+	if style == KeepComments {
+		return text
+	}
+
+	var lines []string
+	switch {
+	case strings.HasPrefix(text, "#"):
+		lines = []string{text[1:]}
+	case strings.HasPrefix(text, "//"):
+		lines = []string{text[2:]}
+	case strings.HasPrefix(text, "/*"):
+		if style == BlockComments {
+			return text
+		}
+
+		body := strings.TrimSuffix(text[2:], "*/")
+		lines = strings.Split(body, "\n")
+		for i, l := range lines {
+			lines[i] = strings.TrimRight(l, " \t\r")
+			if i > 0 {
+				lines[i] = " " + strings.TrimLeft(lines[i], " \t*")
+			}
+		}
+
+		// drop the lines of the delimiters, i.e. of /*\n text\n*/
+		for len(lines) > 1 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+			lines = lines[:len(lines)-1]
+		}
+		if len(lines) > 1 && strings.TrimSpace(lines[0]) == "" {
+			lines = lines[1:]
+		}
+	default:
+		return text
+	}
+
+	if lineComment && len(lines) > 1 {
+		for i, l := range lines {
+			lines[i] = strings.TrimSpace(l)
+		}
+		lines = []string{" " + strings.Join(lines, " ")}
+	}
+
+	switch style {
+	case HashComments:
+		return prefixLines(lines, "#")
+	case SlashComments:
+		return prefixLines(lines, "//")
+	}
+
+	if strings.Contains(lines[0], "*/") {
+		return text
+	}
+	body := strings.TrimRight(lines[0], " \t")
+	if !strings.HasPrefix(body, " ") {
+		body = " " + body
+	}
+	return "/*" + body + " */"
+}
+
+// prefixLines returns the lines as line comments starting with prefix
+func prefixLines(lines []string, prefix string) string {
+	for i, l := range lines {
+		if strings.TrimSpace(l) == "" {
+			l = ""
+		}
+		lines[i] = prefix + l
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,124 @@
+//go:build go1.18
+// +build go1.18
+
+package printer
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/fatih/hcl/ast"
+	"github.com/fatih/hcl/parser"
+	"github.com/fatih/hcl/token"
+)
+
+// FuzzRoundTrip checks that formatting a source doesn't change its meaning,
+// i.e. parsing the formatted source yields an equivalent syntax tree.
+func FuzzRoundTrip(f *testing.F) {
+	files, err := filepath.Glob(filepath.Join(dataDir, "*.input"))
+	if err != nil {
+		f.Fatal(err)
+	}
+
+	for _, file := range files {
+		src, err := ioutil.ReadFile(file)
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(src)
+	}
+
+	f.Fuzz(func(t *testing.T, src []byte) {
+		node, err := parser.Parse(src)
+		if err != nil {
+			return
+		}
+
+		res, err := Format(src)
+		if err != nil {
+			t.Fatalf("format: %s", err)
+		}
+
+		formatted, err := parser.Parse(res)
+		if err != nil {
+			t.Fatalf("parse formatted: %s\n\n%s", err, res)
+		}
+
+		if !equalNodes(node.Node, formatted.Node, false) {
+			t.Fatalf("formatted source is not equivalent:\n\n%s\n\n%s", src, res)
+		}
+
+		// formatted source is formatted already
+		again, err := Format(res)
+		if err != nil {
+			t.Fatalf("format again: %s", err)
+		}
+
+		reformatted, err := parser.Parse(again)
+		if err != nil {
+			t.Fatalf("parse formatted again: %s\n\n%s", err, again)
+		}
+
+		if !bytes.Equal(res, again) || !equalNodes(formatted.Node, reformatted.Node, true) {
+			t.Fatalf("formatting is not idempotent:\n\n%s\n\n%s", res, again)
+		}
+	})
+}
+
+// equalNodes reports whether a and b are equivalent, regardless of their
+// positions and comments. Literals must have the same text if exact is set,
+// otherwise heredocs only need the same value, as the printer reindents
+// indented heredocs.
+func equalNodes(a, b ast.Node, exact bool) bool {
+	switch x := a.(type) {
+	case *ast.ObjectList:
+		y, ok := b.(*ast.ObjectList)
+		if !ok || len(x.Items) != len(y.Items) {
+			return false
+		}
+
+		for i := range x.Items {
+			if !equalNodes(x.Items[i], y.Items[i], exact) {
+				return false
+			}
+		}
+		return true
+	case *ast.ObjectItem:
+		y, ok := b.(*ast.ObjectItem)
+		if !ok || len(x.Keys) != len(y.Keys) {
+			return false
+		}
+
+		for i := range x.Keys {
+			if x.Keys[i].Token.Text != y.Keys[i].Token.Text {
+				return false
+			}
+		}
+		return equalNodes(x.Val, y.Val, exact)
+	case *ast.LiteralType:
+		y, ok := b.(*ast.LiteralType)
+		if ok && !exact && x.Token.Type == token.HEREDOC && y.Token.Type == token.HEREDOC {
+			return x.Token.HeredocValue() == y.Token.HeredocValue()
+		}
+		return ok && x.Token.Type == y.Token.Type && x.Token.Text == y.Token.Text
+	case *ast.ListType:
+		y, ok := b.(*ast.ListType)
+		if !ok || len(x.List) != len(y.List) {
+			return false
+		}
+
+		for i := range x.List {
+			if !equalNodes(x.List[i], y.List[i], exact) {
+				return false
+			}
+		}
+		return true
+	case *ast.ObjectType:
+		y, ok := b.(*ast.ObjectType)
+		return ok && equalNodes(x.List, y.List, exact)
+	}
+
+	return false
+}
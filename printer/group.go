@@ -0,0 +1,71 @@
+package printer
+
+import (
+	"sort"
+
+	"github.com/fatih/hcl/ast"
+	"github.com/fatih/hcl/token"
+)
+
+// groupBlocks returns a copy of the file or object list n with the items at
+// its top level ordered like Config.GroupBlocks describes. Other nodes are
+// returned as they are.
+func (p *printer) groupBlocks(n ast.Node, order []string) ast.Node {
+	switch t := n.(type) {
+	case *ast.File:
+		f := *t
+		f.Node = p.groupBlocks(t.Node, order)
+		return &f
+	case *ast.ObjectList:
+		// the standalone comments before an item are still the ones
+		// following the item before it in the source
+		p.after = make(map[*ast.ObjectItem]token.Pos)
+		var prev token.Pos
+		for _, item := range t.Items {
+			p.after[item] = prev
+			prev = item.Pos()
+			if o, ok := item.Val.(*ast.ObjectType); ok {
+				prev = o.Rbrace
+			}
+		}
+		p.after[nil] = prev
+
+		return &ast.ObjectList{Items: groupItems(t.Items, order)}
+	}
+	return n
+}
+
+// groupItems returns the attributes of items followed by its blocks, sorted
+// by the rank of their types
+func groupItems(items []*ast.ObjectItem, order []string) []*ast.ObjectItem {
+	rank := make(map[string]int)
+	for i, typ := range order {
+		if _, ok := rank[typ]; !ok {
+			rank[typ] = i
+		}
+	}
+
+	var attrs, blocks []*ast.ObjectItem
+	for _, item := range items {
+		if _, ok := item.Val.(*ast.ObjectType); !ok || len(item.Keys) == 0 {
+			attrs = append(attrs, item)
+			continue
+		}
+
+		typ := blockType(item)
+		if _, ok := rank[typ]; !ok {
+			rank[typ] = len(order) + len(rank)
+		}
+		blocks = append(blocks, item)
+	}
+
+	sort.SliceStable(blocks, func(i, j int) bool {
+		return rank[blockType(blocks[i])] < rank[blockType(blocks[j])]
+	})
+	return append(attrs, blocks...)
+}
+
+// blockType returns the unquoted type of the block item
+func blockType(item *ast.ObjectItem) string {
+	return item.Keys[0].Token.Value()
+}
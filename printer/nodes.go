@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"fmt"
 	"sort"
+	"strings"
 
 	"github.com/fatih/hcl/ast"
 	"github.com/fatih/hcl/token"
@@ -14,14 +15,24 @@ const (
 	newline  = byte('\n')
 	tab      = byte('\t')
 	infinity = 1 << 30 // offset or line
+
+	// unindented starts the lines of heredocs which indent keeps as they
+	// are, it's removed from the output
+	unindented = byte(0)
 )
 
 type printer struct {
 	cfg  Config
 	prev token.Pos
 
-	comments           []*ast.CommentGroup // may be nil, contains all comments
-	standaloneComments []*ast.CommentGroup // contains all standalone comments (not assigned to any node)
+	comments           []*ast.CommentGroup   // may be nil, contains all comments
+	standaloneComments []*ast.CommentGroup   // contains all standalone comments (not assigned to any node)
+	printed            map[*ast.Comment]bool // standalone comments already printed, if the items are reordered
+
+	// after is the position after which the standalone comments before the
+	// items of the top level start, if they're reordered. The one of nil is
+	// the start of the comments after the last item.
+	after map[*ast.ObjectItem]token.Pos
 
 	enableTrace bool
 	indentTrace int
@@ -38,13 +49,13 @@ func (b ByPosition) Less(i, j int) bool { return b[i].Pos().Before(b[j].Pos()) }
 func (p *printer) collectComments(node ast.Node) {
 	// first collect all comments. This is already stored in
 	// ast.File.(comments)
-	ast.Walk(node, func(nn ast.Node) bool {
+	ast.Walk(node, func(nn ast.Node) (ast.Node, bool) {
 		switch t := nn.(type) {
 		case *ast.File:
 			p.comments = t.Comments
-			return false
+			return nn, false
 		}
-		return true
+		return nn, true
 	})
 
 	standaloneComments := make(map[token.Pos]*ast.CommentGroup, 0)
@@ -55,7 +66,7 @@ func (p *printer) collectComments(node ast.Node) {
 	// next remove all lead and line comments from the overall comment map.
 	// This will give us comments which are standalone, comments which are not
 	// assigned to any kind of node.
-	ast.Walk(node, func(nn ast.Node) bool {
+	ast.Walk(node, func(nn ast.Node) (ast.Node, bool) {
 		switch t := nn.(type) {
 		case *ast.LiteralType:
 			if t.LineComment != nil {
@@ -83,7 +94,7 @@ func (p *printer) collectComments(node ast.Node) {
 			}
 		}
 
-		return true
+		return nn, true
 	})
 
 	for _, c := range standaloneComments {
@@ -106,6 +117,14 @@ func (p *printer) output(n interface{}) []byte {
 		var nextItem token.Pos
 		var commented bool
 		for {
+			var item *ast.ObjectItem
+			if index != len(t.Items) {
+				item = t.Items[index]
+			}
+			if pos, ok := p.after[item]; ok {
+				p.prev = pos
+			}
+
 			// TODO(arslan): refactor below comment printing, we have the same in objectType
 			for _, c := range p.standaloneComments {
 				for _, comment := range c.List {
@@ -115,13 +134,13 @@ func (p *printer) output(n interface{}) []byte {
 						nextItem = token.Pos{Offset: infinity, Line: infinity}
 					}
 
-					if comment.Pos().After(p.prev) && comment.Pos().Before(nextItem) {
+					if comment.Pos().After(p.prev) && comment.Pos().Before(nextItem) && !p.reprinted(comment) {
 						// if we hit the end add newlines so we can print the comment
 						if index == len(t.Items) {
 							buf.Write([]byte{newline, newline})
 						}
 
-						buf.WriteString(comment.Text)
+						buf.WriteString(p.commentText(comment.Text, false))
 
 						buf.WriteByte(newline)
 						if index != len(t.Items) {
@@ -147,7 +166,20 @@ func (p *printer) output(n interface{}) []byte {
 		p.prev = t.Pos()
 		buf.Write(p.objectItem(t))
 	case *ast.LiteralType:
-		buf.WriteString(t.Token.Text)
+		switch {
+		case p.cfg.Mask != nil && p.cfg.Mask(t):
+			buf.WriteString(Masked)
+		case t.Token.Type == token.HEREDOC && !strings.HasPrefix(t.Token.Text, "<<-"):
+			// the body and the closing marker of a heredoc can't be
+			// indented, except for indented heredocs
+			buf.WriteString(strings.Replace(t.Token.Text, "\n", "\n"+string(unindented), -1))
+		case t.Token.Type == token.HEREDOC:
+			buf.Write(p.indentedHeredoc(t.Token))
+		case folds(t):
+			buf.Write(p.parts(t.Parts))
+		default:
+			buf.WriteString(t.Token.Text)
+		}
 	case *ast.ListType:
 		buf.Write(p.list(t))
 	case *ast.ObjectType:
@@ -168,7 +200,7 @@ func (p *printer) objectItem(o *ast.ObjectItem) []byte {
 
 	if o.LeadComment != nil {
 		for _, comment := range o.LeadComment.List {
-			buf.WriteString(comment.Text)
+			buf.WriteString(p.commentText(comment.Text, false))
 			buf.WriteByte(newline)
 		}
 	}
@@ -186,16 +218,26 @@ func (p *printer) objectItem(o *ast.ObjectItem) []byte {
 
 	buf.Write(p.output(o.Val))
 
-	if o.Val.Pos().Line == o.Keys[0].Pos().Line && o.LineComment != nil {
+	if hasLineComment(o) {
 		buf.WriteByte(blank)
 		for _, comment := range o.LineComment.List {
-			buf.WriteString(comment.Text)
+			buf.WriteString(p.commentText(comment.Text, true))
 		}
 	}
 
 	return buf.Bytes()
 }
 
+// hasLineComment reports whether the line comment of item is written after
+// its value: the value starts on the line of the key, or the comment is on
+// the line the value ends, i.e. for values of items merged from another file
+func hasLineComment(item *ast.ObjectItem) bool {
+	if item.LineComment == nil {
+		return false
+	}
+	return item.Val.Pos().Line == item.Keys[0].Pos().Line || item.LineComment.Pos().Line == ast.End(item.Val).Line
+}
+
 // objectType returns the printable HCL form of an object type. An object type
 // begins with a brace and ends with a brace.
 func (p *printer) objectType(o *ast.ObjectType) []byte {
@@ -225,7 +267,7 @@ func (p *printer) objectType(o *ast.ObjectType) []byte {
 						buf.WriteByte(newline)
 					}
 
-					buf.Write(p.indent([]byte(comment.Text)))
+					buf.Write(p.indent([]byte(p.commentText(comment.Text, false))))
 					buf.WriteByte(newline)
 					if index != len(o.List.Items) {
 						buf.WriteByte(newline) // do not print on the end
@@ -342,7 +384,7 @@ func (p *printer) alignedItems(items []*ast.ObjectItem) []byte {
 	for i, item := range items {
 		if item.LeadComment != nil {
 			for _, comment := range item.LeadComment.List {
-				buf.WriteString(comment.Text)
+				buf.WriteString(p.commentText(comment.Text, false))
 				buf.WriteByte(newline)
 			}
 		}
@@ -365,13 +407,13 @@ func (p *printer) alignedItems(items []*ast.ObjectItem) []byte {
 		valLen := len(val)
 		buf.Write(val)
 
-		if item.Val.Pos().Line == item.Keys[0].Pos().Line && item.LineComment != nil {
+		if hasLineComment(item) {
 			for i := 0; i < longestValLen-valLen+1; i++ {
 				buf.WriteByte(blank)
 			}
 
 			for _, comment := range item.LineComment.List {
-				buf.WriteString(comment.Text)
+				buf.WriteString(p.commentText(comment.Text, true))
 			}
 		}
 
@@ -400,7 +442,12 @@ func (p *printer) list(l *ast.ListType) []byte {
 		}
 	}
 
+	// the separator style of the source, unless normalized
+	newlines := l.Newlines && !p.cfg.NormalizeLists
+	trailing := l.TrailingComma || p.cfg.NormalizeLists
+
 	for i, item := range l.List {
+		last := i == len(l.List)-1
 		if item.Pos().Line != l.Lbrack.Line {
 			// multiline list, add newline before we add each item
 			buf.WriteByte(newline)
@@ -408,7 +455,15 @@ func (p *printer) list(l *ast.ListType) []byte {
 			val := p.output(item)
 			curLen := len(val)
 			buf.Write(p.indent(val))
-			buf.WriteString(",")
+			if !newlines && (!last || trailing) {
+				if isHeredoc(item) {
+					// the closing marker must be on a line of its own
+					buf.WriteByte(newline)
+					buf.Write(p.indent([]byte(",")))
+				} else {
+					buf.WriteString(",")
+				}
+			}
 
 			if lit, ok := item.(*ast.LiteralType); ok && lit.LineComment != nil {
 				// if the next item doesn't have any comments, do not align
@@ -422,7 +477,7 @@ func (p *printer) list(l *ast.ListType) []byte {
 				}
 
 				for _, comment := range lit.LineComment.List {
-					buf.WriteString(comment.Text)
+					buf.WriteString(p.commentText(comment.Text, true))
 				}
 			}
 
@@ -431,8 +486,35 @@ func (p *printer) list(l *ast.ListType) []byte {
 			}
 		} else {
 			buf.Write(p.output(item))
-			if i != len(l.List)-1 {
+			if isHeredoc(item) {
+				// the closing marker must be on a line of its own, the
+				// following elements are on the next lines
+				buf.WriteByte(newline)
+				if !newlines && (!last || l.TrailingComma && !p.cfg.NormalizeLists) {
+					buf.Write(p.indent([]byte(",")))
+				}
+				if last && l.TrailingComma && !p.cfg.NormalizeLists {
+					buf.WriteByte(newline)
+				}
+				continue
+			}
+
+			switch {
+			case !last && !newlines, last && l.TrailingComma && !p.cfg.NormalizeLists:
 				buf.WriteString(",")
+			}
+
+			// a line comment ends the line, the following elements are on
+			// the next lines
+			if lit, ok := item.(*ast.LiteralType); ok && lit.LineComment != nil {
+				buf.WriteByte(blank)
+				for _, comment := range lit.LineComment.List {
+					buf.WriteString(p.commentText(comment.Text, true))
+				}
+				if last {
+					buf.WriteByte(newline)
+				}
+			} else if !last {
 				buf.WriteByte(blank)
 			}
 		}
@@ -457,7 +539,7 @@ func (p *printer) indent(buf []byte) []byte {
 	var res []byte
 	bol := true
 	for _, c := range buf {
-		if bol && c != '\n' {
+		if bol && c != '\n' && c != unindented {
 			res = append(res, prefix...)
 		}
 		res = append(res, c)
@@ -466,6 +548,69 @@ func (p *printer) indent(buf []byte) []byte {
 	return res
 }
 
+// parts returns the printable form of the tokens of a folded string, keeping
+// the line breaks of the source, with the continuation lines indented
+func (p *printer) parts(parts []token.Token) []byte {
+	var buf bytes.Buffer
+	for i, tok := range parts {
+		switch {
+		case i == 0:
+			buf.WriteString(tok.Text)
+		case tok.Pos.Line > parts[i-1].Pos.Line:
+			buf.WriteByte(newline)
+			buf.Write(p.indent([]byte(tok.Text)))
+		default:
+			buf.WriteByte(blank)
+			buf.WriteString(tok.Text)
+		}
+	}
+	return buf.Bytes()
+}
+
+// folds reports whether the parts of lit fold into its token, they don't if
+// the token was changed after parsing
+func folds(lit *ast.LiteralType) bool {
+	if len(lit.Parts) == 0 {
+		return false
+	}
+
+	var text string
+	for _, tok := range lit.Parts {
+		switch {
+		case tok.Type != token.STRING:
+		case text == "":
+			text = tok.Text
+		default:
+			text = text[:len(text)-1] + tok.Text[1:]
+		}
+	}
+	return text == lit.Token.Text
+}
+
+// indentedHeredoc returns the printable form of an indented heredoc, <<-EOF.
+// The common indentation of its body is replaced by one level deeper than
+// the item and its closing marker is aligned with the body, so formatting it
+// again doesn't change it.
+func (p *printer) indentedHeredoc(tok token.Token) []byte {
+	text := tok.Text
+	start := strings.IndexByte(text, '\n')
+	end := strings.LastIndexByte(text, '\n')
+	if start < 0 {
+		return []byte(text)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(text[:start+1])
+	buf.Write(p.indent([]byte(tok.HeredocValue() + strings.TrimLeft(text[end+1:], " \t"))))
+	return buf.Bytes()
+}
+
+// isHeredoc reports whether n is a heredoc
+func isHeredoc(n ast.Node) bool {
+	lit, ok := n.(*ast.LiteralType)
+	return ok && lit.Token.Type == token.HEREDOC
+}
+
 func lines(txt string) int {
 	endline := 1
 	for i := 0; i < len(txt); i++ {
@@ -507,3 +652,17 @@ func un(p *printer) {
 	p.indentTrace--
 	p.printTrace(")")
 }
+
+// reprinted reports whether the standalone comment c was printed already and
+// records it as printed. Only the top level items reordered by GroupBlocks
+// can see the same comment twice, otherwise it's always false.
+func (p *printer) reprinted(c *ast.Comment) bool {
+	if p.printed == nil {
+		return false
+	}
+	if p.printed[c] {
+		return true
+	}
+	p.printed[c] = true
+	return false
+}
@@ -17,9 +17,46 @@ var DefaultConfig = Config{
 // A Config node controls the output of Fprint.
 type Config struct {
 	SpacesWidth int // if set, it will use spaces instead of tabs for alignment
+
+	// Syntax is the syntax version of the output. Nodes using features
+	// not supported by it are an error, except for parser.V1Lenient, which
+	// prints all nodes.
+	Syntax parser.SyntaxVersion
+
+	// Mask, if set, reports whether the value of a literal is hidden in the
+	// output. Masked literals are printed as Masked, i.e. to hide secrets.
+	Mask func(lit *ast.LiteralType) bool
+
+	// NormalizeLists ignores the separator style of lists recorded by the
+	// parser: elements are separated by commas, multiline lists have a
+	// trailing comma and single line lists don't.
+	NormalizeLists bool
+
+	// GroupBlocks groups the blocks at the top level of a file by their
+	// type, i.e. to organize generated files consistently. The types in
+	// BlockOrder come first, in its order, followed by the other types in
+	// the order of their first block. Blocks of the same type and the
+	// attributes, which are printed before all blocks, keep their order.
+	GroupBlocks bool
+	BlockOrder  []string
+
+	// CommentStyle converts the comments to a single style, i.e. for tools
+	// which only accept # comments. The text of the comments is kept.
+	CommentStyle CommentStyle
 }
 
+// Masked is printed instead of the value of masked literals.
+const Masked = `"********"`
+
+// Fprint "pretty-prints" an HCL node to output. Items and keys are printed in
+// the order of the tree, unless GroupBlocks is set.
 func (c *Config) Fprint(output io.Writer, node ast.Node) error {
+	if c.Syntax != parser.V1Lenient {
+		if err := parser.CheckSyntax(node, c.Syntax); err != nil {
+			return err
+		}
+	}
+
 	p := &printer{
 		cfg:                *c,
 		comments:           make([]*ast.CommentGroup, 0),
@@ -28,8 +65,13 @@ func (c *Config) Fprint(output io.Writer, node ast.Node) error {
 	}
 
 	p.collectComments(node)
+	if c.GroupBlocks {
+		p.printed = make(map[*ast.Comment]bool)
+		node = p.groupBlocks(node, c.BlockOrder)
+	}
 
-	if _, err := output.Write(p.output(node)); err != nil {
+	out := bytes.Replace(p.output(node), []byte{unindented}, nil, -1)
+	if _, err := output.Write(out); err != nil {
 		return err
 	}
 
@@ -5,8 +5,10 @@ import (
 	"errors"
 	"flag"
 	"fmt"
+	"go/build"
 	"io/ioutil"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/fatih/hcl/parser"
@@ -20,33 +22,41 @@ const (
 
 type entry struct {
 	source, golden string
+	cfg            Config
 }
 
 // Use go test -update to create/update the respective golden files.
 var data = []entry{
-	{"complexhcl.input", "complexhcl.golden"},
-	{"list.input", "list.golden"},
-	{"comment.input", "comment.golden"},
-	{"comment_aligned.input", "comment_aligned.golden"},
-	{"comment_standalone.input", "comment_standalone.golden"},
+	{"complexhcl.input", "complexhcl.golden", Config{}},
+	{"list.input", "list.golden", Config{NormalizeLists: true}},
+	{"list.input", "list_style.golden", Config{}},
+	{"comment.input", "comment.golden", Config{}},
+	{"comment_aligned.input", "comment_aligned.golden", Config{}},
+	{"comment_standalone.input", "comment_standalone.golden", Config{}},
+	{"escape.input", "escape.golden", Config{}},
+	{"heredoc.input", "heredoc.golden", Config{}},
+	{"group.input", "group.golden", Config{GroupBlocks: true, BlockOrder: []string{"variable", "resource"}}},
+	{"comment.input", "comment_group.golden", Config{GroupBlocks: true}},
+	{"concat.input", "concat.golden", Config{Syntax: parser.Experimental}},
+	{"comment_eof.input", "comment_eof.golden", Config{}},
 }
 
 func TestFiles(t *testing.T) {
 	for _, e := range data {
 		source := filepath.Join(dataDir, e.source)
 		golden := filepath.Join(dataDir, e.golden)
-		check(t, source, golden)
+		check(t, source, golden, e.cfg)
 	}
 }
 
-func check(t *testing.T, source, golden string) {
+func check(t *testing.T, source, golden string, cfg Config) {
 	src, err := ioutil.ReadFile(source)
 	if err != nil {
 		t.Error(err)
 		return
 	}
 
-	res, err := format(src)
+	res, err := format(src, cfg)
 	if err != nil {
 		t.Error(err)
 		return
@@ -109,16 +119,15 @@ func diff(aname, bname string, a, b []byte) error {
 // format parses src, prints the corresponding AST, verifies the resulting
 // src is syntactically correct, and returns the resulting src or an error
 // if any.
-func format(src []byte) ([]byte, error) {
+func format(src []byte, cfg Config) ([]byte, error) {
 	// parse src
-	node, err := parser.Parse(src)
+	config := &parser.Config{Syntax: cfg.Syntax}
+	node, err := config.Parse(src)
 	if err != nil {
 		return nil, fmt.Errorf("parse: %s\n%s", err, src)
 	}
 
 	var buf bytes.Buffer
-
-	cfg := &Config{}
 	if err := cfg.Fprint(&buf, node); err != nil {
 		return nil, fmt.Errorf("print: %s", err)
 	}
@@ -126,7 +135,7 @@ func format(src []byte) ([]byte, error) {
 	// make sure formatted output is syntactically correct
 	res := buf.Bytes()
 
-	if _, err := parser.Parse(src); err != nil {
+	if _, err := config.Parse(src); err != nil {
 		return nil, fmt.Errorf("parse: %s\n%s", err, src)
 	}
 
@@ -141,3 +150,188 @@ func lineAt(text []byte, offs int) []byte {
 	}
 	return text[offs:i]
 }
+
+// TestWASMImports ensures the lexer, parser and printer don't depend on
+// reflection, file system access or the decoding packages in js and TinyGo
+// builds, see cmd/hclwasm.
+func TestWASMImports(t *testing.T) {
+	ctx := build.Default
+	ctx.GOOS, ctx.GOARCH = "js", "wasm"
+
+	forbidden := map[string]bool{"os": true, "io/ioutil": true, "reflect": true, "syscall": true}
+	allowed := map[string]bool{
+		"github.com/fatih/hcl/token":   true,
+		"github.com/fatih/hcl/ast":     true,
+		"github.com/fatih/hcl/scanner": true,
+		"github.com/fatih/hcl/parser":  true,
+		"github.com/fatih/hcl/printer": true,
+	}
+
+	for path := range allowed {
+		pkg, err := ctx.Import(path, "", 0)
+		if err != nil {
+			t.Skipf("can't import %s: %s", path, err)
+		}
+
+		for _, imp := range pkg.Imports {
+			if forbidden[imp] || strings.HasPrefix(imp, "github.com/fatih/hcl") && !allowed[imp] {
+				t.Errorf("%s imports %s", path, imp)
+			}
+		}
+	}
+}
+
+func TestListStyle(t *testing.T) {
+	src := "a = [\n  1\n  2\n]\n\nb = [1, 2,]"
+	f, err := (&parser.Config{Syntax: parser.Experimental}).Parse([]byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		cfg  Config
+		want string
+	}{
+		{Config{SpacesWidth: 2, Syntax: parser.Experimental}, src},
+		{Config{SpacesWidth: 2, Syntax: parser.Experimental, NormalizeLists: true}, "a = [\n  1,\n  2,\n]\n\nb = [1, 2]"},
+	}
+
+	for _, c := range cases {
+		var buf bytes.Buffer
+		if err := c.cfg.Fprint(&buf, f); err != nil {
+			t.Fatal(err)
+		}
+
+		if buf.String() != c.want {
+			t.Errorf("normalize %t: want: %q, got: %q", c.cfg.NormalizeLists, c.want, buf.String())
+		}
+	}
+
+	cfg := Config{Syntax: parser.V1Strict}
+	if err := cfg.Fprint(ioutil.Discard, f); err == nil {
+		t.Error("expected an error for lists without commas in v1-strict")
+	}
+}
+
+func TestIndentedHeredoc(t *testing.T) {
+	src := "a {\n motd = <<-M\n    hi\n      there\n    M\n}"
+	want := "a = {\n  motd = <<-M\n    hi\n      there\n    M\n}"
+
+	// formatting again doesn't indent the body and the marker further
+	res := []byte(src)
+	for i := 1; i <= 3; i++ {
+		var err error
+		if res, err = Format(res); err != nil {
+			t.Fatal(err)
+		}
+		if string(res) != want {
+			t.Errorf("pass %d: want: %q, got: %q", i, want, res)
+		}
+	}
+}
+
+func TestGroupBlocks(t *testing.T) {
+	src := "b {}\n\na = 1\n\nc {}\n\nb {\n  x = 1\n}\n\n\"d\" {}\n\nc {}"
+	f, err := parser.Parse([]byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		order []string
+		want  string
+	}{
+		{nil, "a = 1\n\nb = {\n}\n\nb = {\n  x = 1\n}\n\nc = {\n}\n\nc = {\n}\n\n\"d\" = {\n}"},
+		{[]string{"d", "c"}, "a = 1\n\n\"d\" = {\n}\n\nc = {\n}\n\nc = {\n}\n\nb = {\n}\n\nb = {\n  x = 1\n}"},
+	}
+
+	for _, c := range cases {
+		var buf bytes.Buffer
+		cfg := Config{SpacesWidth: 2, GroupBlocks: true, BlockOrder: c.order}
+		if err := cfg.Fprint(&buf, f); err != nil {
+			t.Fatal(err)
+		}
+
+		if buf.String() != c.want {
+			t.Errorf("order %q: want: %q, got: %q", c.order, c.want, buf.String())
+		}
+	}
+
+	// the tree isn't reordered
+	var buf bytes.Buffer
+	if err := Fprint(&buf, f); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(buf.String(), "b = {") {
+		t.Errorf("the tree was reordered: %q", buf.String())
+	}
+}
+
+func TestItemOrder(t *testing.T) {
+	src := "z = 1\n\nb \"y\" \"x\" {\n  n = 1\n  m = 2\n}\n\na = {\n  c = 3\n  b = 4\n}\n"
+	f, err := parser.Parse([]byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := Fprint(&buf, f); err != nil {
+		t.Fatal(err)
+	}
+	if want := strings.TrimSpace(src); buf.String() != want {
+		t.Errorf("want: %q, got: %q", want, buf.String())
+	}
+}
+
+func TestSyntax(t *testing.T) {
+	src := []byte("a = [[1], [true]]")
+	f, err := (&parser.Config{Syntax: parser.Experimental}).Parse(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	cfg := Config{SpacesWidth: 2, Syntax: parser.Experimental}
+	if err := cfg.Fprint(&buf, f); err != nil {
+		t.Fatal(err)
+	}
+
+	if buf.String() != string(src) {
+		t.Errorf("want: %q, got: %q", src, buf.String())
+	}
+
+	cfg.Syntax = parser.V1Strict
+	if err := cfg.Fprint(&buf, f); err == nil {
+		t.Error("expected an error for nested lists in v1-strict")
+	}
+}
+
+func TestCommentStyle(t *testing.T) {
+	src := "# lead\n// slash\na = 1 /* line\n two */\n\n/*\n * block\n * more\n */\nb {\n  c = 2 # c\n}\n\n// a */ b\nd = 3\n"
+	f, err := parser.Parse([]byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		style CommentStyle
+		want  string
+	}{
+		{KeepComments, "# lead\n// slash\na = 1 /* line\n two */\n\n/*\n * block\n * more\n */\nb = {\n  c = 2 # c\n}\n\n// a */ b\nd = 3"},
+		{HashComments, "# lead\n# slash\na = 1 # line two\n\n# block\n# more\nb = {\n  c = 2 # c\n}\n\n# a */ b\nd = 3"},
+		{SlashComments, "// lead\n// slash\na = 1 // line two\n\n// block\n// more\nb = {\n  c = 2 // c\n}\n\n// a */ b\nd = 3"},
+		{BlockComments, "/* lead */\n/* slash */\na = 1 /* line\n two */\n\n/*\n * block\n * more\n */\nb = {\n  c = 2 /* c */\n}\n\n// a */ b\nd = 3"},
+	}
+
+	for _, c := range cases {
+		var buf bytes.Buffer
+		cfg := Config{SpacesWidth: 2, CommentStyle: c.style}
+		if err := cfg.Fprint(&buf, f); err != nil {
+			t.Fatal(err)
+		}
+
+		if buf.String() != c.want {
+			t.Errorf("style %d: want: %q, got: %q", c.style, c.want, buf.String())
+		}
+	}
+}
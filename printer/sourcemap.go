@@ -0,0 +1,121 @@
+package printer
+
+import (
+	"bytes"
+	"sort"
+	"unicode/utf8"
+
+	"github.com/fatih/hcl/parser"
+	"github.com/fatih/hcl/scanner"
+	"github.com/fatih/hcl/token"
+)
+
+// Mapping maps a token of the formatted output to the same token of the
+// input.
+type Mapping struct {
+	Output token.Pos // start of the token in the output
+	Input  token.Pos // start of the token in the input
+	Len    int       // length of the token in the output in bytes
+
+	input string // text of the token in the input
+}
+
+// SourceMap maps positions of the formatted output to positions of the
+// input. The mappings are sorted by their output offset.
+type SourceMap []Mapping
+
+// Input returns the position of the input corresponding to the position of
+// the output. Positions inside of a token are mapped to the same character
+// of the token in the input, positions between tokens to the end of the
+// preceding token. It reports false for positions before the first token.
+func (m SourceMap) Input(pos token.Pos) (token.Pos, bool) {
+	i := sort.Search(len(m), func(i int) bool {
+		return m[i].Output.Offset > pos.Offset
+	}) - 1
+	if i < 0 {
+		return token.Pos{}, false
+	}
+
+	mp := m[i]
+	delta := pos.Offset - mp.Output.Offset
+	if delta > len(mp.input) || delta >= mp.Len {
+		delta = len(mp.input)
+	}
+	return advance(mp.Input, mp.input[:delta]), true
+}
+
+// FormatWithSourceMap formats src like Format and returns the mapping from
+// the formatted output to src.
+func (c *Config) FormatWithSourceMap(src []byte) ([]byte, SourceMap, error) {
+	node, err := parser.Parse(src)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := c.Fprint(&buf, node); err != nil {
+		return nil, nil, err
+	}
+
+	out := buf.Bytes()
+	return out, sourceMap(tokens(src), tokens(out)), nil
+}
+
+// FormatWithSourceMap formats src with the default settings and returns the
+// mapping from the formatted output to src.
+func FormatWithSourceMap(src []byte) ([]byte, SourceMap, error) {
+	return DefaultConfig.FormatWithSourceMap(src)
+}
+
+// sourceMap aligns the tokens of the input and output. The printer keeps the
+// order of the tokens, but might add or remove commas and change the text of
+// tokens, i.e. for masked values.
+func sourceMap(in, out []token.Token) SourceMap {
+	var m SourceMap
+	i, j := 0, 0
+	for i < len(in) && j < len(out) {
+		a, b := in[i], out[j]
+		switch {
+		case a.Type == b.Type:
+			m = append(m, Mapping{Output: b.Pos, Input: a.Pos, Len: len(b.Text), input: a.Text})
+			i++
+			j++
+		case b.Type == token.COMMA:
+			j++ // added by the printer
+		default:
+			i++ // removed by the printer
+		}
+	}
+	return m
+}
+
+// tokens returns all tokens of src
+func tokens(src []byte) []token.Token {
+	s := scanner.New(src)
+	s.Error = func(token.Pos, string) {}
+
+	var toks []token.Token
+	for {
+		tok := s.Scan()
+		if tok.Type == token.EOF {
+			return toks
+		}
+		toks = append(toks, tok)
+	}
+}
+
+// advance returns the position after text starting at pos
+func advance(pos token.Pos, text string) token.Pos {
+	for len(text) > 0 {
+		r, size := utf8.DecodeRuneInString(text)
+		text = text[size:]
+		pos.Offset += size
+		if r == '\n' {
+			pos.Line++
+			pos.Column = 1
+		} else {
+			pos.Column++
+		}
+	}
+	return pos
+}
@@ -0,0 +1,63 @@
+package printer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/fatih/hcl/token"
+)
+
+func TestFormatWithSourceMap(t *testing.T) {
+	src := `a    =   1
+list = [1,2,
+  3]
+
+service   "web"   {
+      port=80 # http
+}
+`
+	out, m, err := FormatWithSourceMap([]byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		text  string // text of the output the position is at
+		input string // expected input position
+	}{
+		{"a ", "1:1"},
+		{"1\n", "1:10"},
+		{"3\n", "3:3"},
+		{"]\n", "3:4"},
+		{`"web"`, "5:11"},
+		{`eb"`, "5:13"},
+		{"80", "6:12"},
+		{"# http", "6:15"},
+	}
+
+	for _, c := range cases {
+		offset := strings.Index(string(out), c.text)
+		if offset < 0 {
+			t.Fatalf("%q not found in output:\n%s", c.text, out)
+		}
+
+		pos, ok := m.Input(outputPos(out, offset))
+		if !ok {
+			t.Errorf("%q: no input position", c.text)
+			continue
+		}
+
+		if pos.String() != c.input {
+			t.Errorf("%q: want: %s, got: %s", c.text, c.input, pos)
+		}
+	}
+
+	if _, ok := m.Input(token.Pos{Offset: -1}); ok {
+		t.Error("expected no input position before the first token")
+	}
+}
+
+// outputPos returns the position of offset in out
+func outputPos(out []byte, offset int) token.Pos {
+	return advance(token.Pos{Line: 1, Column: 1}, string(out[:offset]))
+}
@@ -0,0 +1,131 @@
+package hcl
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+
+	"github.com/fatih/hcl/ast"
+	"github.com/fatih/hcl/parser"
+	"github.com/fatih/hcl/printer"
+	"github.com/fatih/hcl/token"
+)
+
+// RawMessage is a value kept undecoded, like json.RawMessage, i.e. a section
+// of the configuration handed to a plugin, which decodes it later with its
+// own schema. Fields of type RawMessage receive the value of their item as it
+// is, null included. Fields of type ast.Node receive the node of the value
+// the same way.
+//
+// An item with labels, such as plugin "x" {}, is captured as an object of the
+// labels, like it's decoded into a map. Only the value of the last item of a
+// key is kept, a []RawMessage collects repeated blocks.
+type RawMessage struct {
+	// Src is the source text of the value, i.e. "{ a = 1 }". It's printed
+	// from Node if the decoder has no source, i.e. for DecodeObject, and
+	// for the objects of labels.
+	Src []byte
+
+	// Pos is the position of the value in its file, invalid for a value
+	// built by hand.
+	Pos token.Pos
+
+	// Node is the syntax tree of the value, nil for a value built by hand.
+	Node ast.Node
+}
+
+var (
+	rawMessageType = reflect.TypeOf(RawMessage{})
+	nodeType       = reflect.TypeOf((*ast.Node)(nil)).Elem()
+)
+
+// Decode decodes the value into out with DefaultDecoderConfig. The errors
+// have the positions of the original file, unless the value is built by
+// hand: then Src is parsed, it must be HCL.
+func (m *RawMessage) Decode(out interface{}) error {
+	n, err := m.node()
+	if err != nil {
+		return err
+	}
+	return DecodeObject(out, n)
+}
+
+// node returns the Node of m, or parses its Src
+func (m *RawMessage) node() (ast.Node, error) {
+	if m.Node != nil {
+		return m.Node, nil
+	}
+
+	// the source of a value is the value of an attribute
+	f, err := parser.Parse(append([]byte("v = "), m.Src...))
+	if err != nil {
+		return nil, err
+	}
+	list := f.Node.(*ast.ObjectList)
+	if len(list.Items) != 1 {
+		return nil, fmt.Errorf("raw message %q is not a single value", m.Src)
+	}
+	return list.Items[0].Val, nil
+}
+
+// decodeRaw assigns n to rv, which is a RawMessage or an ast.Node
+func (d *decoder) decodeRaw(n ast.Node, rv reflect.Value) error {
+	if rv.Type() == nodeType {
+		rv.Set(reflect.ValueOf(&n).Elem())
+		return nil
+	}
+
+	pos := n.Pos()
+	m := RawMessage{Pos: pos, Node: n}
+	start, end := pos.Offset, ast.End(n).Offset
+	if _, labels := labelItem(n); !labels && d.src != "" && pos.IsValid() && start < end && end <= len(d.src) {
+		m.Src = []byte(d.src[start:end])
+	} else {
+		var buf bytes.Buffer
+		if err := printer.Fprint(&buf, n); err != nil {
+			return err
+		}
+		m.Src = buf.Bytes()
+	}
+	rv.Set(reflect.ValueOf(m))
+	return nil
+}
+
+// isRaw reports whether values of type t are captured by decodeRaw
+func isRaw(t reflect.Type) bool {
+	return t == rawMessageType || t == nodeType
+}
+
+// rawNode returns the node a RawMessage or an ast.Node is encoded as,
+// verbatim, and whether rv is one. The node of a nil or empty value is nil.
+func rawNode(name string, rv reflect.Value) (ast.Node, bool, error) {
+	if !rv.IsValid() {
+		return nil, false, nil
+	}
+
+	for rv.Kind() == reflect.Ptr && rv.Type().Elem() == rawMessageType {
+		if rv.IsNil() {
+			return nil, true, nil
+		}
+		rv = rv.Elem()
+	}
+
+	switch rv.Type() {
+	case nodeType:
+		if rv.IsNil() {
+			return nil, true, nil
+		}
+		return rv.Interface().(ast.Node), true, nil
+	case rawMessageType:
+		m := rv.Interface().(RawMessage)
+		if m.Node == nil && len(m.Src) == 0 {
+			return nil, true, nil
+		}
+		n, err := m.node()
+		if err != nil {
+			return nil, true, fmt.Errorf("%s: %s", name, err)
+		}
+		return n, true, nil
+	}
+	return nil, false, nil
+}
@@ -0,0 +1,151 @@
+package hcl
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/fatih/hcl/ast"
+	"github.com/fatih/hcl/parser"
+)
+
+type pluginConfig struct {
+	Name   string       `hcl:"name"`
+	Plugin RawMessage   `hcl:"plugin"`
+	Args   *RawMessage  `hcl:"args"`
+	Hooks  []RawMessage `hcl:"hook"`
+	Node   ast.Node     `hcl:"node"`
+}
+
+const rawSource = `name = "app"
+
+plugin {
+  level = 3 # verbose
+}
+
+args = [1, "two"]
+
+hook "pre" {
+  cmd = "a"
+}
+
+hook "post" {
+  cmd = "b"
+}
+
+node = true
+`
+
+func TestDecodeRaw(t *testing.T) {
+	var out pluginConfig
+	if err := Decode(&out, rawSource); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := string(out.Plugin.Src); got != "{\n  level = 3 # verbose\n}" {
+		t.Errorf("unexpected source %q", got)
+	}
+	if out.Plugin.Pos.String() != "3:8" {
+		t.Errorf("unexpected position %s", out.Plugin.Pos)
+	}
+	if out.Args == nil || string(out.Args.Src) != `[1, "two"]` {
+		t.Errorf("unexpected args %+v", out.Args)
+	}
+	if len(out.Hooks) != 2 || string(out.Hooks[1].Src) != "{\n  \"post\" = {\n    cmd = \"b\"\n  }\n}" {
+		t.Errorf("unexpected hooks %q", out.Hooks)
+	}
+	if lit, ok := out.Node.(*ast.LiteralType); !ok || lit.Token.Text != "true" {
+		t.Errorf("unexpected node %#v", out.Node)
+	}
+
+	// the plugin decodes its section with its own schema
+	var plugin struct {
+		Level int `hcl:"level"`
+	}
+	if err := out.Plugin.Decode(&plugin); err != nil || plugin.Level != 3 {
+		t.Errorf("unexpected plugin %+v: %v", plugin, err)
+	}
+	var hook map[string]struct {
+		Cmd string `hcl:"cmd"`
+	}
+	if err := out.Hooks[0].Decode(&hook); err != nil || hook["pre"].Cmd != "a" {
+		t.Errorf("unexpected hook %+v: %v", hook, err)
+	}
+
+	var wrong struct {
+		Level bool `hcl:"level"`
+	}
+	err := out.Plugin.Decode(&wrong)
+	if err == nil || err.Error() != "At 4:11: root.level: cannot decode number into bool" {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	if errs := CheckTypes(mustParse(t, rawSource), &pluginConfig{}); len(errs) > 0 {
+		t.Errorf("unexpected errors: %v", errs)
+	}
+}
+
+func TestDecodeObjectRaw(t *testing.T) {
+	f := mustParse(t, "plugin {\n  level = 3\n}\n")
+
+	var out pluginConfig
+	if err := DecodeObject(&out, f); err != nil {
+		t.Fatal(err)
+	}
+	if got := string(out.Plugin.Src); got != "{\n  level = 3\n}" {
+		t.Errorf("unexpected source %q", got)
+	}
+	if out.Plugin.Node != f.Node.(*ast.ObjectList).Items[0].Val {
+		t.Error("the node isn't the node of the tree")
+	}
+}
+
+func TestEncodeRaw(t *testing.T) {
+	var in pluginConfig
+	if err := Decode(&in, rawSource); err != nil {
+		t.Fatal(err)
+	}
+	in.Hooks = nil
+	in.Node = nil
+
+	out, err := Marshal(&in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `name = "app"
+
+plugin = {
+  level = 3 # verbose
+}
+
+args = [1, "two"]
+`
+	if string(out) != want {
+		t.Errorf("want:\n%s\ngot:\n%s", want, out)
+	}
+
+	// values built by hand are parsed
+	in = pluginConfig{Name: "x", Plugin: RawMessage{Src: []byte(`{ a = [1, 2] }`)}}
+	if out, err = Marshal(&in); err != nil {
+		t.Fatal(err)
+	}
+	var back struct {
+		Plugin map[string][]int `hcl:"plugin"`
+	}
+	if err := Decode(&back, string(out)); err != nil || !reflect.DeepEqual(back.Plugin["a"], []int{1, 2}) {
+		t.Errorf("unexpected decode of %s: %+v, %v", out, back, err)
+	}
+
+	in.Plugin.Src = []byte(`{`)
+	if _, err := Marshal(&in); err == nil {
+		t.Error("expected an error for an invalid raw message")
+	}
+}
+
+func mustParse(t *testing.T, src string) *ast.File {
+	t.Helper()
+	f, err := parser.Parse([]byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
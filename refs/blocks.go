@@ -0,0 +1,192 @@
+package refs
+
+import (
+	"fmt"
+
+	"github.com/fatih/hcl/ast"
+	"github.com/fatih/hcl/token"
+)
+
+// BlockRef declares that the string values of the attributes named
+// Attribute refer to blocks of type BlockType by their label, i.e.
+// BlockRef{"backend", "backend"} for `backend = "prod"` referring to
+// `backend "prod" {}`.
+type BlockRef struct {
+	Attribute string
+	BlockType string
+}
+
+// Definition is a block with a single label, which can be referred to by
+// its type and label.
+type Definition struct {
+	Type string
+	Name string
+	Pos  token.Pos // position of the block, with the file name
+}
+
+// Problem is a duplicate definition or an unresolved reference.
+type Problem struct {
+	Pos token.Pos
+	Msg string
+}
+
+func (p Problem) String() string {
+	return fmt.Sprintf("%s: %s", p.Pos, p.Msg)
+}
+
+// Index indexes the definitions and references of blocks across multiple
+// files, such as the files of a config directory.
+type Index struct {
+	rules []BlockRef
+	defs  map[defKey][]Definition
+	order []defKey
+	refs  []blockRef
+}
+
+type defKey struct {
+	typ, name string
+}
+
+// blockRef is a string referring to a block
+type blockRef struct {
+	key defKey
+	pos token.Pos
+}
+
+// NewIndex returns an empty index resolving references with the given
+// rules.
+func NewIndex(rules ...BlockRef) *Index {
+	return &Index{
+		rules: rules,
+		defs:  make(map[defKey][]Definition),
+	}
+}
+
+// Add adds the definitions and references of the file f with the given
+// name to the index. The name is used as the file name of the positions.
+func (ix *Index) Add(filename string, f *ast.File) {
+	list, ok := f.Node.(*ast.ObjectList)
+	if !ok {
+		return
+	}
+	ix.add(filename, list)
+}
+
+func (ix *Index) add(filename string, list *ast.ObjectList) {
+	for _, item := range list.Items {
+		if len(item.Keys) == 0 {
+			continue
+		}
+
+		obj, isBlock := item.Val.(*ast.ObjectType)
+		if isBlock && len(item.Keys) == 2 {
+			key := defKey{item.Keys[0].Token.Value(), item.Keys[1].Token.Value()}
+			if _, ok := ix.defs[key]; !ok {
+				ix.order = append(ix.order, key)
+			}
+			ix.defs[key] = append(ix.defs[key], Definition{
+				Type: key.typ,
+				Name: key.name,
+				Pos:  withFile(item.Pos(), filename),
+			})
+		}
+
+		if isBlock {
+			if obj.List != nil {
+				ix.add(filename, obj.List)
+			}
+			continue
+		}
+
+		if len(item.Keys) != 1 {
+			continue
+		}
+
+		name := item.Keys[0].Token.Value()
+		for _, rule := range ix.rules {
+			if rule.Attribute == name {
+				ix.addRefs(filename, rule.BlockType, item.Val)
+			}
+		}
+	}
+}
+
+// addRefs adds the strings of n, which might be a list, as references to
+// blocks of the given type
+func (ix *Index) addRefs(filename, typ string, n ast.Node) {
+	switch t := n.(type) {
+	case *ast.LiteralType:
+		if t.Token.Type == token.STRING {
+			ix.refs = append(ix.refs, blockRef{
+				key: defKey{typ, t.Token.Value()},
+				pos: withFile(t.Pos(), filename),
+			})
+		}
+	case *ast.ListType:
+		for _, elem := range t.List {
+			ix.addRefs(filename, typ, elem)
+		}
+	}
+}
+
+// Lookup returns the first definition of the block with the given type and
+// label.
+func (ix *Index) Lookup(typ, name string) (Definition, bool) {
+	defs := ix.defs[defKey{typ, name}]
+	if len(defs) == 0 {
+		return Definition{}, false
+	}
+	return defs[0], true
+}
+
+// Definitions returns the definitions of blocks of the given type, in the
+// order they were added.
+func (ix *Index) Definitions(typ string) []Definition {
+	var defs []Definition
+	for _, key := range ix.order {
+		if key.typ == typ {
+			defs = append(defs, ix.defs[key]...)
+		}
+	}
+	return defs
+}
+
+// Check returns the duplicate definitions of blocks referred to by the
+// rules, followed by the unresolved references, in the order they were
+// added.
+func (ix *Index) Check() []Problem {
+	referred := make(map[string]bool)
+	for _, rule := range ix.rules {
+		referred[rule.BlockType] = true
+	}
+
+	var problems []Problem
+	for _, key := range ix.order {
+		defs := ix.defs[key]
+		if !referred[key.typ] {
+			continue
+		}
+
+		for _, def := range defs[1:] {
+			problems = append(problems, Problem{
+				Pos: def.Pos,
+				Msg: fmt.Sprintf("duplicate %s %q, first defined at %s", key.typ, key.name, defs[0].Pos),
+			})
+		}
+	}
+
+	for _, ref := range ix.refs {
+		if _, ok := ix.defs[ref.key]; !ok {
+			problems = append(problems, Problem{
+				Pos: ref.pos,
+				Msg: fmt.Sprintf("unresolved reference to %s %q", ref.key.typ, ref.key.name),
+			})
+		}
+	}
+	return problems
+}
+
+func withFile(pos token.Pos, filename string) token.Pos {
+	pos.Filename = filename
+	return pos
+}
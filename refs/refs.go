@@ -0,0 +1,179 @@
+// Package refs extracts the variables and functions referenced by the
+// interpolations of HCL (HashiCorp Configuration Language) syntax trees,
+// without evaluating them. It's useful to compute dependency graphs or the
+// inputs required by a configuration.
+//
+// An Index resolves attributes referring to named blocks by their label,
+// such as `backend = "prod"`, across the files of a configuration.
+package refs
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/fatih/hcl/ast"
+	"github.com/fatih/hcl/parser"
+	"github.com/fatih/hcl/token"
+)
+
+// Kind is the kind of a Reference.
+type Kind int
+
+const (
+	Variable Kind = iota
+	Function
+)
+
+func (k Kind) String() string {
+	switch k {
+	case Variable:
+		return "variable"
+	case Function:
+		return "function"
+	}
+	return "unknown"
+}
+
+// Reference is a reference to a variable or a function in an interpolation.
+type Reference struct {
+	Kind Kind
+	Name string    // name of the variable, such as var.foo, or function
+	Pos  token.Pos // position of the name
+}
+
+// Find returns all references of the interpolations of n in lexical order.
+// The strings of n are parsed as templates, the first parse error is
+// returned. Variables bound by the for directives of a template aren't
+// references.
+func Find(n ast.Node) ([]Reference, error) {
+	var refs []Reference
+	var err error
+
+	ast.Walk(n, func(n ast.Node) (ast.Node, bool) {
+		if err != nil {
+			return n, false
+		}
+
+		switch t := n.(type) {
+		case *ast.LiteralType:
+			var r []Reference
+			if r, err = literal(t); err == nil {
+				refs = append(refs, r...)
+			}
+			return n, false
+		case ast.Expr:
+			refs = append(refs, expr(t, nil)...)
+			return n, false
+		}
+		return n, true
+	})
+
+	if err != nil {
+		return nil, err
+	}
+	return refs, nil
+}
+
+// Names returns the sorted and unique names of all references of the given
+// kind.
+func Names(refs []Reference, kind Kind) []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, r := range refs {
+		if r.Kind != kind || seen[r.Name] {
+			continue
+		}
+		seen[r.Name] = true
+		names = append(names, r.Name)
+	}
+
+	sort.Strings(names)
+	return names
+}
+
+// literal returns the references of a string literal
+func literal(lit *ast.LiteralType) ([]Reference, error) {
+	if lit.Template != nil {
+		return expr(lit.Template, nil), nil
+	}
+
+	text := lit.Token.Text
+	if lit.Token.Type == token.HEREDOC && strings.Contains(text, "{") {
+		t, err := parser.ParseHeredoc(lit.Token)
+		if err != nil {
+			return nil, err
+		}
+		return expr(t, nil), nil
+	}
+
+	if lit.Token.Type != token.STRING || len(text) < 2 || !strings.Contains(text, "{") {
+		return nil, nil
+	}
+
+	pos := lit.Pos()
+	pos.Offset++
+	pos.Column++
+
+	t, err := parser.ParseTemplate(text[1:len(text)-1], pos)
+	if err != nil {
+		return nil, err
+	}
+	return expr(t, nil), nil
+}
+
+// expr returns the references of the expression e. bound contains the names
+// of the variables bound by the enclosing for directives.
+func expr(e ast.Expr, bound map[string]bool) []Reference {
+	var refs []Reference
+	switch t := e.(type) {
+	case *ast.LiteralType:
+		r, _ := literal(t)
+		refs = append(refs, r...)
+	case *ast.Template:
+		refs = append(refs, exprs(t.Parts, bound)...)
+	case *ast.Variable:
+		name := t.Name
+		if i := strings.Index(name, "."); i >= 0 {
+			name = name[:i]
+		}
+
+		if !bound[name] {
+			refs = append(refs, Reference{Kind: Variable, Name: t.Name, Pos: t.NamePos})
+		}
+	case *ast.Call:
+		refs = append(refs, Reference{Kind: Function, Name: t.Name, Pos: t.NamePos})
+		refs = append(refs, exprs(t.Args, bound)...)
+	case *ast.Binary:
+		refs = append(refs, expr(t.X, bound)...)
+		refs = append(refs, expr(t.Y, bound)...)
+	case *ast.Unary:
+		refs = append(refs, expr(t.X, bound)...)
+	case *ast.Conditional:
+		refs = append(refs, exprs([]ast.Expr{t.Cond, t.True, t.False}, bound)...)
+	case *ast.ForDirective:
+		refs = append(refs, expr(t.Collection, bound)...)
+
+		inner := make(map[string]bool, len(bound)+2)
+		for name := range bound {
+			inner[name] = true
+		}
+		inner[t.Value] = true
+		if t.Key != "" {
+			inner[t.Key] = true
+		}
+		refs = append(refs, exprs(t.Body, inner)...)
+	case *ast.IfDirective:
+		refs = append(refs, expr(t.Cond, bound)...)
+		refs = append(refs, exprs(t.True, bound)...)
+		refs = append(refs, exprs(t.Else, bound)...)
+	}
+	return refs
+}
+
+func exprs(list []ast.Expr, bound map[string]bool) []Reference {
+	var refs []Reference
+	for _, e := range list {
+		refs = append(refs, expr(e, bound)...)
+	}
+	return refs
+}
@@ -0,0 +1,161 @@
+package refs
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/fatih/hcl/parser"
+)
+
+func TestFind(t *testing.T) {
+	src := `resource "aws_instance" "web" {
+	ami   = "${var.ami}"
+	name  = "web-${upper(var.env)}-${count.index + 1}"
+	count = 2
+	tags  = ["${lookup(var.tags, "name")}", "plain"]
+	user  = "%{ for u in var.users }${u.name}:${var.group}%{ endfor }"
+}
+`
+	f, err := parser.Parse([]byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	refs, err := Find(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, r := range refs {
+		got = append(got, fmt.Sprintf("%s %s %s", r.Pos, r.Kind, r.Name))
+	}
+
+	expected := []string{
+		"2:13 variable var.ami",
+		"3:17 function upper",
+		"3:23 variable var.env",
+		"3:35 variable count.index",
+		"5:14 function lookup",
+		"5:21 variable var.tags",
+		"6:23 variable var.users",
+		"6:46 variable var.group",
+	}
+
+	if !reflect.DeepEqual(expected, got) {
+		t.Errorf("\nwant: %q\ngot:  %q", expected, got)
+	}
+
+	vars := Names(refs, Variable)
+	if want := []string{"count.index", "var.ami", "var.env", "var.group", "var.tags", "var.users"}; !reflect.DeepEqual(want, vars) {
+		t.Errorf("want: %v got: %v", want, vars)
+	}
+
+	funcs := Names(refs, Function)
+	if want := []string{"lookup", "upper"}; !reflect.DeepEqual(want, funcs) {
+		t.Errorf("want: %v got: %v", want, funcs)
+	}
+}
+
+func TestFindNested(t *testing.T) {
+	f, err := parser.Parse([]byte(`a = "${f("${inner}")}"`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	refs, err := Find(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(refs) != 2 || refs[1].Name != "inner" {
+		t.Errorf("unexpected references: %v", refs)
+	}
+}
+
+func TestFindError(t *testing.T) {
+	f, err := parser.Parse([]byte(`a = "ok"
+b = "${var.x +}"`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = Find(f)
+	perr, ok := err.(*parser.PosError)
+	if !ok {
+		t.Fatalf("want: *parser.PosError got: %v", err)
+	}
+
+	if perr.Pos.Line != 2 {
+		t.Errorf("want error on line 2 got: %s", perr)
+	}
+}
+
+func TestIndex(t *testing.T) {
+	files := []struct {
+		name, src string
+	}{
+		{"backends.hcl", `backend "prod" {
+  bucket = "prod"
+}
+
+backend "dev" {}
+`},
+		{"services.hcl", `service "web" {
+  backend    = "prod"
+  depends_on = ["db", "cache"]
+}
+
+service "db" {
+  backend = "staging"
+}
+`},
+		{"override.hcl", `backend "dev" {}`},
+	}
+
+	ix := NewIndex(
+		BlockRef{Attribute: "backend", BlockType: "backend"},
+		BlockRef{Attribute: "depends_on", BlockType: "service"},
+	)
+
+	for _, file := range files {
+		f, err := parser.Parse([]byte(file.src))
+		if err != nil {
+			t.Fatal(err)
+		}
+		ix.Add(file.name, f)
+	}
+
+	def, ok := ix.Lookup("backend", "prod")
+	if !ok || def.Pos.String() != "backends.hcl:1:1" {
+		t.Errorf("unexpected definition: %+v", def)
+	}
+
+	if _, ok := ix.Lookup("backend", "staging"); ok {
+		t.Error("unexpected definition of backend staging")
+	}
+
+	var names []string
+	for _, def := range ix.Definitions("service") {
+		names = append(names, def.Name)
+	}
+	if !reflect.DeepEqual([]string{"web", "db"}, names) {
+		t.Errorf("unexpected services: %q", names)
+	}
+
+	var got []string
+	for _, p := range ix.Check() {
+		got = append(got, p.String())
+	}
+
+	expected := []string{
+		`override.hcl:1:1: duplicate backend "dev", first defined at backends.hcl:5:1`,
+		`services.hcl:3:23: unresolved reference to service "cache"`,
+		`services.hcl:7:13: unresolved reference to backend "staging"`,
+	}
+
+	if !reflect.DeepEqual(expected, got) {
+		t.Errorf("\nwant: %q\ngot:  %q", expected, got)
+	}
+}
@@ -0,0 +1,184 @@
+package hcl
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/fatih/hcl/ast"
+	"github.com/fatih/hcl/convert"
+	"github.com/fatih/hcl/eval"
+	"github.com/fatih/hcl/parser"
+	"github.com/fatih/hcl/printer"
+	"github.com/fatih/hcl/token"
+)
+
+// RenderTemplate parses src, resolves all of its interpolations and
+// directives with the given scope and returns the resulting static HCL
+// source. See RenderFile.
+func RenderTemplate(src string, scope *eval.Scope) (string, error) {
+	f, err := parser.Parse([]byte(src))
+	if err != nil {
+		return "", err
+	}
+
+	if err := RenderFile(f, scope); err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	if err := printer.Fprint(&buf, f); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// RenderFile replaces every string of f containing interpolations or
+// directives with the value it evaluates to in the given scope. Lists and
+// maps are replaced by lists and objects. Strings are escaped, so the
+// result contains no interpolations anymore. Errors are of type
+// *parser.PosError.
+func RenderFile(f *ast.File, scope *eval.Scope) error {
+	n, err := render(f.Node, scope)
+	if err != nil {
+		return err
+	}
+
+	f.Node = n
+	return nil
+}
+
+// render returns the node replacing n
+func render(n ast.Node, scope *eval.Scope) (ast.Node, error) {
+	switch t := n.(type) {
+	case *ast.ObjectList:
+		for _, item := range t.Items {
+			val, err := render(item.Val, scope)
+			if err != nil {
+				return nil, err
+			}
+			item.Val = val
+		}
+	case *ast.ObjectType:
+		if t.List != nil {
+			if _, err := render(t.List, scope); err != nil {
+				return nil, err
+			}
+		}
+	case *ast.ListType:
+		for i, elem := range t.List {
+			val, err := render(elem, scope)
+			if err != nil {
+				return nil, err
+			}
+			t.List[i] = val
+		}
+	case *ast.LiteralType:
+		if t.Token.Type != token.STRING && t.Token.Type != token.HEREDOC || !strings.Contains(t.Token.Text, "{") {
+			return t, nil
+		}
+
+		var tmpl *ast.Template
+		var err error
+		if t.Token.Type == token.HEREDOC {
+			tmpl, err = parser.ParseHeredoc(t.Token)
+		} else {
+			text := t.Token.Text
+			pos := t.Pos()
+			pos.Offset++
+			pos.Column++
+			tmpl, err = parser.ParseTemplate(text[1:len(text)-1], pos)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if isText(tmpl) {
+			return t, nil
+		}
+
+		v, err := scope.Eval(tmpl)
+		if err != nil {
+			return nil, err
+		}
+
+		val, err := valueNode(v, t.Pos())
+		if err != nil {
+			return nil, &parser.PosError{Pos: t.Pos(), Err: err}
+		}
+
+		// keep the comment of list elements
+		if lit, ok := val.(*ast.LiteralType); ok {
+			lit.LineComment = t.LineComment
+		}
+		return val, nil
+	}
+
+	return n, nil
+}
+
+// valueNode returns the node representing v, a value returned by package
+// eval. All tokens are positioned at pos.
+func valueNode(v interface{}, pos token.Pos) (ast.Node, error) {
+	lit := func(typ token.Type, text string) ast.Node {
+		return &ast.LiteralType{Token: token.Token{Type: typ, Pos: pos, Text: text}}
+	}
+
+	switch t := v.(type) {
+	case string:
+		return lit(token.STRING, quote(t)), nil
+	case int64:
+		return lit(token.NUMBER, strconv.FormatInt(t, 10)), nil
+	case float64:
+		return lit(token.FLOAT, strconv.FormatFloat(t, 'f', -1, 64)), nil
+	case bool:
+		return lit(token.BOOL, strconv.FormatBool(t)), nil
+	case []interface{}:
+		list := &ast.ListType{Lbrack: pos, Rbrack: pos}
+		for _, elem := range t {
+			n, err := valueNode(elem, pos)
+			if err != nil {
+				return nil, err
+			}
+			list.Add(n)
+		}
+		return list, nil
+	case map[string]interface{}:
+		keys := make([]string, 0, len(t))
+		for k := range t {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		obj := &ast.ObjectType{Lbrace: pos, Rbrace: pos, List: &ast.ObjectList{}}
+		for _, k := range keys {
+			n, err := valueNode(t[k], pos)
+			if err != nil {
+				return nil, err
+			}
+
+			key := token.Token{Type: token.IDENT, Pos: pos, Text: k}
+			if !token.IsIdent(k) {
+				key.Type, key.Text = token.STRING, strconv.Quote(k)
+			}
+
+			obj.List.Add(&ast.ObjectItem{
+				Keys:   []*ast.ObjectKey{{Token: key}},
+				Assign: pos,
+				Val:    n,
+			})
+		}
+		return obj, nil
+	}
+
+	return nil, fmt.Errorf("can't render %s", convert.TypeName(v))
+}
+
+// quote returns s as a string literal, with "${" and "%{" escaped
+func quote(s string) string {
+	s = strings.Replace(s, "${", "$${", -1)
+	s = strings.Replace(s, "%{", "%%{", -1)
+	return strconv.Quote(s)
+}
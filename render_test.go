@@ -0,0 +1,89 @@
+package hcl
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/fatih/hcl/eval"
+	"github.com/fatih/hcl/parser"
+)
+
+func TestRenderTemplate(t *testing.T) {
+	scope := &eval.Scope{Variables: map[string]interface{}{
+		"var.name":  "web",
+		"var.port":  int64(8080),
+		"var.ratio": 0.5,
+		"var.zones": []interface{}{"a", "b"},
+		"var.tags":  map[string]interface{}{"env": "prod", "cost center": int64(42)},
+		"var.raw":   "${not_interpolated}",
+	}}
+	scope.Register("upper", strings.ToUpper)
+
+	src := `name = "${upper(var.name)}-1"
+port = "${var.port + 1}"
+ratio = "${var.ratio}"
+enabled = "${var.port > 80}"
+zones = "${var.zones}"
+tags = "${var.tags}"
+raw = "${var.raw}"
+static = "$${kept}"
+hosts = [
+  "%{ for z in var.zones }${z}.example.com %{ endfor }", # hosts
+]
+`
+
+	expected := `name = "WEB-1"
+
+port = 8081
+
+ratio = 0.5
+
+enabled = true
+
+zones = ["a", "b"]
+
+tags = {
+  "cost center" = 42
+
+  env = "prod"
+}
+
+raw = "$${not_interpolated}"
+
+static = "$${kept}"
+
+hosts = [
+  "a.example.com b.example.com ", # hosts
+]`
+
+	out, err := RenderTemplate(src, scope)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if out != expected {
+		t.Errorf("\nwant:\n%s\ngot:\n%s", expected, out)
+	}
+
+	// the result is static
+	f, err := parser.Parse([]byte(out))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	v, err := eval.Eval(f, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if raw := v.(map[string]interface{})["raw"]; raw != "${not_interpolated}" {
+		t.Errorf("want: ${not_interpolated} got: %v", raw)
+	}
+}
+
+func TestRenderTemplateError(t *testing.T) {
+	_, err := RenderTemplate("a = 1\nb = \"${unknown}\"", &eval.Scope{})
+	if err == nil || err.Error() != "At 2:8: unknown variable unknown" {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,37 @@
+package hcl
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/fatih/hcl/hcltest"
+)
+
+// roundTripSources returns the parser fixtures
+func roundTripSources(tb testing.TB) [][]byte {
+	files, err := filepath.Glob(filepath.Join("parser", "test-fixtures", "*.hcl"))
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	var sources [][]byte
+	for _, file := range files {
+		src, err := ioutil.ReadFile(file)
+		if err != nil {
+			tb.Fatal(err)
+		}
+		sources = append(sources, src)
+	}
+	return sources
+}
+
+func TestRoundTrip(t *testing.T) {
+	sources := append(roundTripSources(t),
+		[]byte(`a = [1, 2.5, true, "x"]`),
+		[]byte(`a = "${b} \"quoted\""`),
+		[]byte(`service "a" { port = 1 } service "b" { port = 2 }`),
+		[]byte(`"key with spaces" = { "x.y" = [] }`),
+	)
+	hcltest.RunRoundTrip(t, Decode, Marshal, sources...)
+}
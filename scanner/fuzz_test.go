@@ -0,0 +1,31 @@
+//go:build go1.18
+// +build go1.18
+
+package scanner
+
+import (
+	"testing"
+
+	"github.com/fatih/hcl/token"
+)
+
+func FuzzScan(f *testing.F) {
+	for _, list := range tokenLists {
+		for _, pair := range list {
+			f.Add([]byte(pair.text))
+		}
+	}
+
+	f.Fuzz(func(t *testing.T, src []byte) {
+		s := New(src)
+		s.Error = func(pos token.Pos, msg string) {}
+
+		// every scan consumes at least one byte, except for EOF
+		for i := 0; i <= len(src); i++ {
+			if tok := s.Scan(); tok.Type == token.EOF {
+				return
+			}
+		}
+		t.Fatalf("no EOF after scanning %d tokens", len(src)+1)
+	})
+}
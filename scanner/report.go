@@ -0,0 +1,16 @@
+//go:build !js && !tinygo
+// +build !js,!tinygo
+
+package scanner
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/fatih/hcl/token"
+)
+
+// report prints an error to os.Stderr
+func report(pos token.Pos, msg string) {
+	fmt.Fprintf(os.Stderr, "%s: %s\n", pos, msg)
+}
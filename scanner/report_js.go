@@ -0,0 +1,12 @@
+//go:build js || tinygo
+// +build js tinygo
+
+package scanner
+
+import "github.com/fatih/hcl/token"
+
+// report prints an error with the builtin println, so the scanner doesn't
+// depend on package os in WASM builds
+func report(pos token.Pos, msg string) {
+	println(pos.String() + ": " + msg)
+}
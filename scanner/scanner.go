@@ -3,22 +3,30 @@
 package scanner
 
 import (
+	"bufio"
 	"bytes"
 	"fmt"
-	"os"
+	"io"
 	"unicode"
 	"unicode/utf8"
 
 	"github.com/fatih/hcl/token"
 )
 
-// eof represents a marker rune for the end of the reader.
-const eof = rune(0)
+// eof represents a marker rune for the end of the reader. It's not a valid
+// rune, so it can't be confused with a NUL byte of the source.
+const eof = rune(-1)
 
 // Scanner defines a lexical scanner
 type Scanner struct {
-	buf *bytes.Buffer // Source buffer for advancing and scanning
-	src []byte        // Source buffer for immutable access
+	buf   *bufio.Reader // Source buffer for advancing and scanning
+	text  []byte        // text of the current token read so far
+	rerr  error         // first error reading the source, other than io.EOF
+	atEOF bool          // the end of the source was read
+
+	// bomChecked is set once a UTF-8 byte order mark at the start of the
+	// source is skipped, if there's one
+	bomChecked bool
 
 	// Source Position
 	srcPos  token.Pos // current position
@@ -31,12 +39,31 @@ type Scanner struct {
 	tokEnd   int // token text end  position
 
 	// Error is called for each error encountered. If no Error
-	// function is set, the error is reported to os.Stderr.
+	// function is set, the error is reported to os.Stderr, or printed with
+	// the builtin println for js and TinyGo builds.
 	Error func(pos token.Pos, msg string)
 
 	// ErrorCount is incremented by one for each error encountered.
 	ErrorCount int
 
+	// FoldKeywords makes the keywords true, false and null case-insensitive,
+	// i.e. TRUE is scanned as a BOOL. The text of the token is left as it is.
+	FoldKeywords bool
+
+	// Units enables numbers followed by a unit, i.e. 10s, 512k or 80%, which
+	// are scanned as a single UNIT token.
+	Units bool
+
+	// Interpolations splits strings at their interpolations and directives,
+	// i.e. for template engines: "a ${b} c" is scanned as the tokens
+	// TEMPLATE_TEXT "a , TEMPLATE_INTERP ${b} and TEMPLATE_TEXT  c". The
+	// quotes are part of the first and last text, so the texts of the
+	// tokens add up to the string. Strings without interpolations are
+	// scanned as STRING tokens.
+	Interpolations bool
+	inTemplate     bool      // between the parts of a string split at its interpolations
+	strPos         token.Pos // position of the opening quote of the last string
+
 	// tokPos is the start position of most recently scanned token; set by
 	// Scan. The Filename field is always left untouched by the Scanner.  If
 	// an error is reported (via Error) and Position is invalid, the scanner is
@@ -47,39 +74,86 @@ type Scanner struct {
 // New creates and initializes a new instance of Scanner using src as
 // its source content.
 func New(src []byte) *Scanner {
-	// even though we accept a src, we read from a io.Reader compatible type
-	// (*bytes.Buffer). So in the future we might easily change it to streaming
-	// read.
-	b := bytes.NewBuffer(src)
-	s := &Scanner{
-		buf: b,
-		src: src,
-	}
+	return NewReader(bytes.NewReader(src))
+}
+
+// NewReader returns a Scanner reading the source from r incrementally. Only
+// a small window of r and the text of the current token are kept in memory,
+// so files of any size can be scanned. An error reading r other than io.EOF
+// is reported like a syntax error and ends the source.
+func NewReader(r io.Reader) *Scanner {
+	s := &Scanner{buf: bufio.NewReader(r)}
 
 	// srcPosition always starts with 1
 	s.srcPos.Line = 1
 	return s
 }
 
-// next reads the next rune from the bufferred reader. Returns the rune(0) if
-// an error occurs (or io.EOF is returned).
+// StartAt sets the position of the first character of the source, i.e. to
+// scan a part of a larger source with positions in the larger source. It must
+// be called before the first call of Scan.
+func (s *Scanner) StartAt(pos token.Pos) {
+	s.srcPos = pos
+	s.srcPos.Column--
+	s.tokPos.Filename = pos.Filename
+}
+
+// bom is the UTF-8 byte order mark, which editors on Windows write at the
+// start of files
+const bom = "\xef\xbb\xbf"
+
+// skipBOM skips a byte order mark at the start of the source. Like the
+// invisible character it is, it takes no column: the first token is at
+// column 1 and offset 3, so offsets still refer to the source.
+func (s *Scanner) skipBOM() {
+	s.bomChecked = true
+	if b, _ := s.buf.Peek(len(bom)); string(b) == bom {
+		s.buf.Discard(len(bom))
+		s.srcPos.Offset += len(bom)
+	}
+}
+
+// next reads the next rune from the bufferred reader. Returns eof if an error
+// occurs (or io.EOF is returned).
 func (s *Scanner) next() rune {
 	ch, size, err := s.buf.ReadRune()
 	if err != nil {
-		// advance for error reporting
-		s.srcPos.Column++
+		// advance once for error reporting, so the EOF token and errors at
+		// the end of the source are positioned right after the last
+		// character, however often EOF is read
+		if !s.atEOF {
+			s.srcPos.Column++
+			s.atEOF = true
+		}
 		s.srcPos.Offset += size
 		s.lastCharLen = size
+		if err != io.EOF && s.rerr == nil {
+			s.rerr = err
+			s.err(err.Error())
+		}
 		return eof
 	}
 
 	if ch == utf8.RuneError && size == 1 {
+		// keep the invalid byte in the token text, read the rune again, so
+		// it can still be unread
+		s.buf.UnreadRune()
+		b, _ := s.buf.Peek(1)
+		s.buf.ReadRune()
+		s.text = append(s.text, b...)
+
 		s.srcPos.Column++
 		s.srcPos.Offset += size
 		s.lastCharLen = size
 		s.err("illegal UTF-8 encoding")
 		return ch
 	}
+	if ch < utf8.RuneSelf {
+		s.text = append(s.text, byte(ch))
+	} else {
+		var b [utf8.UTFMax]byte
+		s.text = append(s.text, b[:utf8.EncodeRune(b[:], ch)]...)
+	}
 
 	// remember last position
 	s.prevPos = s.srcPos
@@ -88,6 +162,11 @@ func (s *Scanner) next() rune {
 	s.lastCharLen = size
 	s.srcPos.Offset += size
 
+	if ch == 0 {
+		// reported like invalid UTF-8, as it isn't valid in any token
+		s.err("illegal character NUL")
+	}
+
 	if ch == '\n' {
 		s.srcPos.Line++
 		s.lastLineLen = s.srcPos.Column
@@ -104,6 +183,7 @@ func (s *Scanner) unread() {
 	if err := s.buf.UnreadRune(); err != nil {
 		panic(err) // this is user fault, we should catch it
 	}
+	s.text = s.text[:len(s.text)-s.lastCharLen]
 	s.srcPos = s.prevPos // put back last position
 }
 
@@ -120,17 +200,55 @@ func (s *Scanner) peek() rune {
 
 // Scan scans the next token and returns the token.
 func (s *Scanner) Scan() token.Token {
+	tok := s.scan()
+
+	// create token literal
+	var tokenText string
+	if s.tokStart >= 0 {
+		tokenText = string(s.text)
+	}
+	s.tokStart = s.tokEnd // ensure idempotency of tokenText() call
+
+	return token.Token{
+		Type: tok,
+		Pos:  s.tokPos,
+		Text: tokenText,
+	}
+}
+
+// ScanRaw scans the tokens up to the end of the source and calls next for
+// each one, with its type, text and position, until next returns false. It's
+// the low-level API of the scanner for parsers of dialects of HCL: the text
+// isn't copied into a string, it's only valid until next returns, and tokens
+// other than heredocs are scanned without allocating memory. The end of the
+// source is reported as a token.EOF token.
+func (s *Scanner) ScanRaw(next func(typ token.Type, text []byte, pos token.Pos) bool) {
+	for {
+		tok := s.scan()
+		s.tokStart = s.tokEnd
+		if !next(tok, s.text, s.tokPos) || tok == token.EOF {
+			return
+		}
+	}
+}
+
+// scan scans the next token, whose text is s.text, and returns its type
+func (s *Scanner) scan() token.Type {
+	if !s.bomChecked {
+		s.skipBOM()
+	}
 	ch := s.next()
 
-	// skip white space
-	for isWhitespace(ch) {
+	// skip white space, unless between the parts of a string
+	for isWhitespace(ch) && !s.inTemplate {
 		ch = s.next()
 	}
 
 	var tok token.Type
 
-	// token text markings
+	// token text markings, the text starts with the last rune read
 	s.tokStart = s.srcPos.Offset - s.lastCharLen
+	s.text = s.text[:copy(s.text, s.text[len(s.text)-s.lastCharLen:])]
 
 	// token position, initial next() is moving the offset by one(size of rune
 	// actually), though we are interested with the starting point
@@ -148,11 +266,25 @@ func (s *Scanner) Scan() token.Token {
 	}
 
 	switch {
+	case s.inTemplate && (ch == '$' || ch == '%') && s.peek() == '{':
+		s.next()
+		tok = token.TEMPLATE_INTERP
+		if !s.scanInterpolation() {
+			s.inTemplate = false
+		}
+	case s.inTemplate:
+		tok = s.scanTemplateText(ch)
 	case isLetter(ch):
 		tok = token.IDENT
 		lit := s.scanIdentifier()
-		if lit == "true" || lit == "false" {
+		if string(lit) == "true" || string(lit) == "false" {
 			tok = token.BOOL
+		} else if string(lit) == "null" {
+			tok = token.NULL
+		} else if s.FoldKeywords && (bytes.EqualFold(lit, []byte("true")) || bytes.EqualFold(lit, []byte("false"))) {
+			tok = token.BOOL
+		} else if s.FoldKeywords && bytes.EqualFold(lit, []byte("null")) {
+			tok = token.NULL
 		}
 	case isDecimal(ch):
 		tok = s.scanNumber(ch)
@@ -162,7 +294,12 @@ func (s *Scanner) Scan() token.Token {
 			tok = token.EOF
 		case '"':
 			tok = token.STRING
-			s.scanString()
+			s.strPos = s.tokPos
+			if s.Interpolations {
+				tok = s.scanTemplateText(ch)
+			} else {
+				s.scanString()
+			}
 		case '#', '/':
 			tok = token.COMMENT
 			s.scanComment(ch)
@@ -188,6 +325,14 @@ func (s *Scanner) Scan() token.Token {
 			tok = token.ASSIGN
 		case '+':
 			tok = token.ADD
+		case '<':
+			if s.peek() == '<' {
+				s.next()
+				tok = token.HEREDOC
+				s.scanHeredoc()
+			} else {
+				s.err("illegal char")
+			}
 		case '-':
 			if isDecimal(s.peek()) {
 				ch := s.next()
@@ -200,31 +345,28 @@ func (s *Scanner) Scan() token.Token {
 		}
 	}
 
-	// finish token ending
-	s.tokEnd = s.srcPos.Offset
-
-	// create token literal
-	var tokenText string
-	if s.tokStart >= 0 {
-		tokenText = string(s.src[s.tokStart:s.tokEnd])
+	if s.Units && (tok == token.NUMBER || tok == token.FLOAT) {
+		if ch := s.peek(); (isLetter(ch) || ch == '%') && !s.hexadecimal() {
+			s.scanUnit()
+			tok = token.UNIT
+		}
 	}
-	s.tokStart = s.tokEnd // ensure idempotency of tokenText() call
 
-	return token.Token{
-		Type: tok,
-		Pos:  s.tokPos,
-		Text: tokenText,
-	}
+	// finish token ending
+	s.tokEnd = s.srcPos.Offset
+	return tok
 }
 
 func (s *Scanner) scanComment(ch rune) {
 	// single line comments
 	if ch == '#' || (ch == '/' && s.peek() != '*') {
 		ch = s.next()
-		for ch != '\n' && ch >= 0 {
+		for ch != '\n' && ch != eof {
 			ch = s.next()
 		}
-		s.unread()
+		if ch != eof {
+			s.unread()
+		}
 		return
 	}
 
@@ -237,8 +379,8 @@ func (s *Scanner) scanComment(ch rune) {
 
 	// look for /* - style comments
 	for {
-		if ch < 0 || ch == eof {
-			s.err("comment not terminated")
+		if ch == eof {
+			s.err(fmt.Sprintf("comment opened at %s not terminated, expected: */", s.tokPos))
 			break
 		}
 
@@ -275,6 +417,27 @@ func (s *Scanner) scanNumber(ch rune) token.Type {
 			return token.NUMBER
 		}
 
+		if ch == 'o' || ch == 'O' {
+			// octal with a prefix, like in Go
+			ch = s.next()
+			found, illegal := false, false
+			for isDecimal(ch) {
+				illegal = illegal || ch == '8' || ch == '9'
+				ch = s.next()
+				found = true
+			}
+
+			if !found || illegal {
+				s.err("illegal octal number")
+			}
+
+			if ch != eof {
+				s.unread()
+			}
+
+			return token.NUMBER
+		}
+
 		// now it's either something like: 0421(octal) or 0.1231(float)
 		illegalOctal := false
 		for isDecimal(ch) {
@@ -336,6 +499,23 @@ func (s *Scanner) scanNumber(ch rune) token.Type {
 	return token.NUMBER
 }
 
+// scanUnit scans the unit following a number
+func (s *Scanner) scanUnit() {
+	for ch := s.peek(); isLetter(ch) || isDecimal(ch) || ch == '%'; ch = s.peek() {
+		s.next()
+	}
+}
+
+// hexadecimal reports whether the number scanned is hexadecimal, its letters
+// aren't a unit
+func (s *Scanner) hexadecimal() bool {
+	if s.tokStart < 0 {
+		return false
+	}
+
+	return bytes.IndexByte(s.text, 'x') >= 0 || bytes.IndexByte(s.text, 'X') >= 0
+}
+
 // scanMantissa scans the mantissa begining from the rune. It returns the next
 // non decimal rune. It's used to determine wheter it's a fraction or exponent.
 func (s *Scanner) scanMantissa(ch rune) rune {
@@ -368,47 +548,191 @@ func (s *Scanner) scanExponent(ch rune) rune {
 		if ch == '-' || ch == '+' {
 			ch = s.next()
 		}
+		if !isDecimal(ch) {
+			s.err("exponent has no digits")
+		}
 		ch = s.scanMantissa(ch)
 	}
 	return ch
 }
 
-// scanString scans a quoted string
-func (s *Scanner) scanString() {
-	braces := 0
+// scanString scans a string after its opening quote, up to the closing one.
+// Interpolations and directives may contain quoted strings of their own,
+// whose braces and quotes don't end them. It reports whether the string is
+// terminated.
+func (s *Scanner) scanString() bool {
 	for {
-		// '"' opening already consumed
-		// read character after quote
 		ch := s.next()
-
-		if ch == '\n' || ch < 0 || ch == eof {
-			s.err("literal not terminated")
-			return
+		switch ch {
+		case '\n', eof:
+			s.err(fmt.Sprintf("literal opened at %s not terminated, expected: \"", s.strPos))
+			return false
+		case '"':
+			return true
+		case '\\':
+			s.scanEscape()
+		case '$', '%':
+			if s.scanTemplateStart(ch) && !s.scanInterpolation() {
+				return false
+			}
 		}
+	}
+}
 
-		if ch == '"' && braces == 0 {
-			break
-		}
-		
-		// If we're going into a ${} then we can ignore quotes for awhile.
-		// This is a weird HCL-ism but most places HCL is use also use this
-		// syntax for interpolations.
-		if braces == 0 && ch == '$' && s.peek() == '{' {
-			braces++
-			s.next()
-		} else if braces > 0 && ch == '{' {
+// scanTemplateStart scans the "${" or "%{" starting an interpolation or a
+// directive after its first character ch, and reports whether it does. The
+// characters are escaped by doubling them, as in "$${" and "%%{".
+func (s *Scanner) scanTemplateStart(ch rune) bool {
+	n := 1
+	for s.peek() == ch {
+		s.next()
+		n++
+	}
+
+	if s.peek() != '{' {
+		return false
+	}
+	s.next()
+	return n == 1
+}
+
+// scanInterpolation scans an interpolation or directive of a string after
+// its "${" or "%{", up to the brace closing it. It reports whether it's
+// terminated.
+func (s *Scanner) scanInterpolation() bool {
+	braces := 1
+	for {
+		ch := s.next()
+		switch ch {
+		case '\n', eof:
+			s.err(fmt.Sprintf("literal opened at %s not terminated, expected: \"", s.strPos))
+			return false
+		case '"':
+			if !s.scanString() {
+				return false
+			}
+		case '\\':
+			s.scanEscape()
+		case '{':
 			braces++
+		case '}':
+			if braces--; braces == 0 {
+				return true
+			}
 		}
-		if braces > 0 && ch == '}' {
-			braces--
+	}
+}
+
+// scanTemplateText scans the text of a string split at its interpolations
+// by Interpolations, up to the quote closing the string or the next
+// interpolation, after its first character ch. It returns token.STRING for
+// strings without interpolations.
+func (s *Scanner) scanTemplateText(ch rune) token.Type {
+	tok := token.TEMPLATE_TEXT
+	opening := !s.inTemplate // ch is the opening quote
+	if opening {
+		tok = token.STRING
+	}
+
+	for {
+		if !opening {
+			switch ch {
+			case '\n', eof:
+				s.err(fmt.Sprintf("literal opened at %s not terminated, expected: \"", s.strPos))
+				s.inTemplate = false
+				return tok
+			case '"':
+				s.inTemplate = false
+				return tok
+			case '\\':
+				s.scanEscape()
+			case '$', '%':
+				s.scanTemplateStart(ch)
+			}
 		}
+		opening = false
 
-		if ch == '\\' {
-			s.scanEscape()
+		if b, _ := s.buf.Peek(2); len(b) == 2 && (b[0] == '$' || b[0] == '%') && b[1] == '{' {
+			s.inTemplate = true
+			return token.TEMPLATE_TEXT
 		}
+		ch = s.next()
 	}
+}
 
-	return
+// scanHeredoc scans a heredoc after the "<<", up to the marker closing it,
+// which must be on a line of its own. The marker of an indented heredoc,
+// such as <<-EOF, may be indented.
+func (s *Scanner) scanHeredoc() {
+	indented := false
+	if s.peek() == '-' {
+		s.next()
+		indented = true
+	}
+
+	// the marker is kept in the text of the token, between start and end
+	start := len(s.text)
+	for ch := s.peek(); isLetter(ch) || isDigit(ch); ch = s.peek() {
+		s.next()
+	}
+	end := len(s.text)
+
+	// the rune read after a peek can't be unread, so a carriage return is
+	// only read if a newline follows
+	if b, _ := s.buf.Peek(2); string(b) == "\r\n" {
+		s.next()
+	}
+	ch := s.next()
+	if start == end || ch != '\n' {
+		if start == end {
+			s.err("heredoc has no marker, expected: <<MARKER or <<-MARKER")
+		} else {
+			s.err(fmt.Sprintf("heredoc marker %s must be followed by a newline", s.text[start:end]))
+		}
+		if ch != eof {
+			s.unread()
+		}
+		return
+	}
+
+	// hint is the explanation of a line almost closing the heredoc, for
+	// the error of an unterminated one
+	hint := ""
+	for {
+		line := s.srcPos.Line
+		lineStart := len(s.text)
+		ch := s.next()
+		for ch != '\n' && ch != eof {
+			ch = s.next()
+		}
+
+		marker := s.text[start:end]
+		l := bytes.TrimSuffix(bytes.TrimSuffix(s.text[lineStart:], []byte("\n")), []byte("\r"))
+		if indented {
+			l = bytes.TrimLeft(l, " \t")
+		}
+		if bytes.Equal(l, marker) {
+			if ch != eof {
+				s.unread() // the newline isn't part of the heredoc
+			}
+			return
+		}
+
+		if trimmed := bytes.TrimLeft(l, " \t"); hint == "" && bytes.HasPrefix(trimmed, marker) {
+			rest := trimmed[len(marker):]
+			if r, _ := utf8.DecodeRune(rest); len(rest) == 0 {
+				hint = fmt.Sprintf(" (the marker on line %d is indented, use <<-%s)", line, marker)
+			} else if !isLetter(r) && !isDigit(r) {
+				hint = fmt.Sprintf(" (the marker on line %d is followed by %q)", line, rest)
+			}
+		}
+
+		if ch == eof {
+			s.err(fmt.Sprintf("heredoc opened at %s not terminated, expected: %s on a line of its own%s",
+				s.tokPos, marker, hint))
+			return
+		}
+	}
 }
 
 // scanEscape scans an escape sequence
@@ -420,26 +744,34 @@ func (s *Scanner) scanEscape() rune {
 		// nothing to do
 	case '0', '1', '2', '3', '4', '5', '6', '7':
 		// octal notation
-		ch = s.scanDigits(ch, 8, 3)
+		s.scanDigits(ch, 8, 3)
 	case 'x':
 		// hexademical notation
-		ch = s.scanDigits(s.next(), 16, 2)
-	case 'u':
-		// universal character name
-		ch = s.scanDigits(s.next(), 16, 4)
-	case 'U':
-		// universal character name
-		ch = s.scanDigits(s.next(), 16, 8)
+		s.scanDigits(s.next(), 16, 2)
+	case 'u', 'U':
+		// universal character name, \uXXXX or \UXXXXXXXX
+		n := 4
+		if ch == 'U' {
+			n = 8
+		}
+		x, ok := s.scanDigits(s.next(), 16, n)
+		// x overflows for \U values beyond 0x7fffffff
+		if ok && (x < 0 || x > unicode.MaxRune || 0xd800 <= x && x < 0xe000) {
+			s.err("escape sequence is invalid Unicode code point")
+		}
 	default:
 		s.err("illegal char escape")
 	}
 	return ch
 }
 
-// scanDigits scans a rune with the given base for n times. For example an
-// octal notation \184 would yield in scanDigits(ch, 8, 3)
-func (s *Scanner) scanDigits(ch rune, base, n int) rune {
+// scanDigits scans a rune with the given base for n times and returns its
+// value and whether there were n digits. For example an octal notation \184
+// would yield in scanDigits(ch, 8, 3)
+func (s *Scanner) scanDigits(ch rune, base, n int) (rune, bool) {
+	var x rune
 	for n > 0 && digitVal(ch) < base {
+		x = x*rune(base) + rune(digitVal(ch))
 		ch = s.next()
 		n--
 	}
@@ -448,13 +780,15 @@ func (s *Scanner) scanDigits(ch rune, base, n int) rune {
 	}
 
 	// we scanned all digits, put the last non digit char back
-	s.unread()
-	return ch
+	if ch != eof {
+		s.unread()
+	}
+	return x, n == 0
 }
 
-// scanIdentifier scans an identifier and returns the literal string
-func (s *Scanner) scanIdentifier() string {
-	offs := s.srcPos.Offset - s.lastCharLen
+// scanIdentifier scans an identifier, which starts the token, and returns
+// its text
+func (s *Scanner) scanIdentifier() []byte {
 	ch := s.next()
 	for isLetter(ch) || isDigit(ch) {
 		ch = s.next()
@@ -464,12 +798,26 @@ func (s *Scanner) scanIdentifier() string {
 		s.unread() // we got identifier, put back latest char
 	}
 
-	return string(s.src[offs:s.srcPos.Offset])
+	return s.text
+}
+
+// Pos returns the position of the character immediately after the token
+// returned by the last call to Scan, i.e. the end of its source range. It's
+// the start of the source before the first call.
+func (s *Scanner) Pos() token.Pos {
+	pos := s.srcPos
+	if !s.atEOF {
+		// the end of the source already moved the column past the last
+		// character
+		pos.Column++
+	}
+	return pos
 }
 
 // recentPosition returns the position of the character immediately after the
 // character or token returned by the last call to Scan.
 func (s *Scanner) recentPosition() (pos token.Pos) {
+	pos.Filename = s.srcPos.Filename
 	pos.Offset = s.srcPos.Offset - s.lastCharLen
 	switch {
 	case s.srcPos.Column > 0:
@@ -484,14 +832,14 @@ func (s *Scanner) recentPosition() (pos token.Pos) {
 		pos.Column = s.lastLineLen
 	default:
 		// at the beginning of the source
-		pos.Line = 1
-		pos.Column = 1
+		pos.Line = s.srcPos.Line
+		pos.Column = s.srcPos.Column + 1
 	}
 	return
 }
 
 // err prints the error of any scanning to s.Error function. If the function is
-// not defined, by default it prints them to os.Stderr
+// not defined, by default it reports them with report
 func (s *Scanner) err(msg string) {
 	s.ErrorCount++
 	pos := s.recentPosition()
@@ -501,7 +849,7 @@ func (s *Scanner) err(msg string) {
 		return
 	}
 
-	fmt.Fprintf(os.Stderr, "%s: %s\n", pos, msg)
+	report(pos, msg)
 }
 
 // isHexadecimal returns true if the given rune is a letter
@@ -2,7 +2,11 @@ package scanner
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
+	"io"
+	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/fatih/hcl/token"
@@ -75,6 +79,10 @@ var tokenLists = map[string][]tokenPair{
 		{token.STRING, `"a"`},
 		{token.STRING, `"本"`},
 		{token.STRING, `"${file("foo")}"`},
+		{token.STRING, `"%{ if a == "b" }x%{ endif }"`},
+		{token.STRING, `"$${literal}"`},
+		{token.STRING, `"%%{literal}"`},
+		{token.STRING, `"100%"`},
 		{token.STRING, `"\a"`},
 		{token.STRING, `"\b"`},
 		{token.STRING, `"\f"`},
@@ -116,6 +124,9 @@ var tokenLists = map[string][]tokenPair{
 		{token.NUMBER, "0X42"},
 		{token.NUMBER, "0X123456789abcDEF"},
 		{token.NUMBER, "0X" + f100},
+		{token.NUMBER, "0o0"},
+		{token.NUMBER, "0o17"},
+		{token.NUMBER, "0O755"},
 		{token.NUMBER, "0e0"},
 		{token.NUMBER, "1e0"},
 		{token.NUMBER, "42e0"},
@@ -154,6 +165,7 @@ var tokenLists = map[string][]tokenPair{
 		{token.NUMBER, "-0X42"},
 		{token.NUMBER, "-0X123456789abcDEF"},
 		{token.NUMBER, "-0X" + f100},
+		{token.NUMBER, "-0o17"},
 		{token.NUMBER, "-0e0"},
 		{token.NUMBER, "-1e0"},
 		{token.NUMBER, "-42e0"},
@@ -285,6 +297,135 @@ func TestBool(t *testing.T) {
 	testTokenList(t, tokenLists["bool"])
 }
 
+func TestFoldKeywords(t *testing.T) {
+	cases := []struct {
+		fold bool
+		text string
+		tok  token.Type
+	}{
+		{false, "TRUE", token.IDENT},
+		{false, "False", token.IDENT},
+		{true, "TRUE", token.BOOL},
+		{true, "False", token.BOOL},
+		{true, "true", token.BOOL},
+		{true, "Truth", token.IDENT},
+	}
+
+	for _, c := range cases {
+		s := New([]byte(c.text))
+		s.FoldKeywords = c.fold
+		tok := s.Scan()
+		if tok.Type != c.tok || tok.Text != c.text {
+			t.Errorf("%q (fold %v): want %s, got %s %q", c.text, c.fold, c.tok, tok.Type, tok.Text)
+		}
+	}
+}
+
+func TestUnits(t *testing.T) {
+	cases := []struct {
+		units bool
+		text  string
+		tok   token.Type
+		lit   string
+	}{
+		{false, "10s", token.NUMBER, "10"},
+		{true, "10s", token.UNIT, "10s"},
+		{true, "1.5GB", token.UNIT, "1.5GB"},
+		{true, "80%", token.UNIT, "80%"},
+		{true, "-1h30m", token.UNIT, "-1h30m"},
+		{true, "1e3s", token.UNIT, "1e3s"},
+		{true, "1e3", token.NUMBER, "1e3"},
+		{true, "0x1F", token.NUMBER, "0x1F"},
+		{true, "42", token.NUMBER, "42"},
+	}
+
+	for _, c := range cases {
+		s := New([]byte(c.text))
+		s.Units = c.units
+		tok := s.Scan()
+		if tok.Type != c.tok || tok.Text != c.lit {
+			t.Errorf("%q (units %v): want %s %q, got %s %q", c.text, c.units, c.tok, c.lit, tok.Type, tok.Text)
+		}
+	}
+}
+
+func TestHeredoc(t *testing.T) {
+	cases := []struct {
+		src  string
+		text string
+		next token.Type // type of the following token
+	}{
+		{"<<EOF\nhello\nEOF", "<<EOF\nhello\nEOF", token.EOF},
+		{"<<EOF\nhello\n  world\nEOF\nb", "<<EOF\nhello\n  world\nEOF", token.IDENT},
+		{"<<EOF\nEOF\n", "<<EOF\nEOF", token.EOF},
+		{"<<EOT\nEOF\n EOT\nEOT x\nEOT\n", "<<EOT\nEOF\n EOT\nEOT x\nEOT", token.EOF},
+		{"<<-EOT\n    a\n    EOT\n", "<<-EOT\n    a\n    EOT", token.EOF},
+		{"<<-EOT\n\ta\n\tEOT\n,", "<<-EOT\n\ta\n\tEOT", token.COMMA},
+		{"<<END2\r\na\r\nEND2\r\n", "<<END2\r\na\r\nEND2\r", token.EOF},
+	}
+
+	for _, c := range cases {
+		s := New([]byte(c.src))
+		s.Error = func(pos token.Pos, msg string) {
+			t.Errorf("%q: unexpected error at %s: %s", c.src, pos, msg)
+		}
+
+		tok := s.Scan()
+		if tok.Type != token.HEREDOC || tok.Text != c.text {
+			t.Errorf("%q: want HEREDOC %q, got %s %q", c.src, c.text, tok.Type, tok.Text)
+		}
+		if next := s.Scan(); next.Type != c.next {
+			t.Errorf("%q: want %s after the heredoc, got %s", c.src, c.next, next)
+		}
+	}
+}
+
+func TestInterpolations(t *testing.T) {
+	cases := []struct {
+		src    string
+		tokens []string // types and texts of the tokens, if it's split
+	}{
+		{`"abc"`, []string{`STRING "abc"`}},
+		{`"a ${b} c"`, []string{`TEMPLATE_TEXT "a `, `TEMPLATE_INTERP ${b}`, `TEMPLATE_TEXT  c"`}},
+		{`"${a}${b}"`, []string{`TEMPLATE_TEXT "`, `TEMPLATE_INTERP ${a}`, `TEMPLATE_INTERP ${b}`, `TEMPLATE_TEXT "`}},
+		{`"${lookup(var.map, "key")}"`, []string{`TEMPLATE_TEXT "`, `TEMPLATE_INTERP ${lookup(var.map, "key")}`, `TEMPLATE_TEXT "`}},
+		{`"${format("}{", x)}!"`, []string{`TEMPLATE_TEXT "`, `TEMPLATE_INTERP ${format("}{", x)}`, `TEMPLATE_TEXT !"`}},
+		{`"x${a("${b}")}"`, []string{`TEMPLATE_TEXT "x`, `TEMPLATE_INTERP ${a("${b}")}`, `TEMPLATE_TEXT "`}},
+		{`"%{ if a }b%{ endif }"`, []string{`TEMPLATE_TEXT "`, `TEMPLATE_INTERP %{ if a }`, `TEMPLATE_TEXT b`, `TEMPLATE_INTERP %{ endif }`, `TEMPLATE_TEXT "`}},
+		{`"$${a} %%{b} $x ${c}"`, []string{`TEMPLATE_TEXT "$${a} %%{b} $x `, `TEMPLATE_INTERP ${c}`, `TEMPLATE_TEXT "`}},
+		{`"a \"${b}\""`, []string{`TEMPLATE_TEXT "a \"`, `TEMPLATE_INTERP ${b}`, `TEMPLATE_TEXT \""`}},
+	}
+
+	scan := func(src string, split bool) []string {
+		s := New([]byte(src))
+		s.Interpolations = split
+		s.Error = func(pos token.Pos, msg string) {
+			t.Errorf("%s: unexpected error at %s: %s", src, pos, msg)
+		}
+
+		var tokens []string
+		for tok := s.Scan(); tok.Type != token.EOF; tok = s.Scan() {
+			tokens = append(tokens, tok.Type.String()+" "+tok.Text)
+		}
+		return tokens
+	}
+
+	for _, c := range cases {
+		if got := scan(c.src, false); !reflect.DeepEqual(got, []string{"STRING " + c.src}) {
+			t.Errorf("%s: want a single string, got %q", c.src, got)
+		}
+		if got := scan(c.src, true); !reflect.DeepEqual(got, c.tokens) {
+			t.Errorf("%s: want %q, got %q", c.src, c.tokens, got)
+		}
+	}
+
+	// white space is kept between the parts
+	want := []string{`IDENT a`, `ASSIGN =`, `TEMPLATE_TEXT " `, `TEMPLATE_INTERP ${b}`, `TEMPLATE_TEXT  "`, `IDENT c`}
+	if got := scan(`a = " ${b} " c`, true); !reflect.DeepEqual(got, want) {
+		t.Errorf("want %q, got %q", want, got)
+	}
+}
+
 func TestIdent(t *testing.T) {
 	testTokenList(t, tokenLists["ident"])
 }
@@ -301,6 +442,77 @@ func TestFloat(t *testing.T) {
 	testTokenList(t, tokenLists["float"])
 }
 
+func TestCommentEOF(t *testing.T) {
+	for _, src := range []string{"//", "# comment", "foo // comment"} {
+		s := New([]byte(src))
+		tok := s.Scan()
+		for tok.Type != token.COMMENT {
+			tok = s.Scan()
+		}
+
+		if tok.Type = s.Scan().Type; tok.Type != token.EOF {
+			t.Errorf("want: EOF got: %s for %q", tok.Type, src)
+		}
+	}
+}
+
+func TestEOFPosition(t *testing.T) {
+	cases := []struct {
+		src string
+		pos token.Pos
+	}{
+		{"", token.Pos{Offset: 0, Line: 1, Column: 1}},
+		{"a = 1", token.Pos{Offset: 5, Line: 1, Column: 6}},
+		{"a = 1\n", token.Pos{Offset: 6, Line: 2, Column: 1}},
+		{"a = 1\n\n  \t", token.Pos{Offset: 10, Line: 3, Column: 4}},
+		{"a = foo", token.Pos{Offset: 7, Line: 1, Column: 8}},
+		{"a # comment", token.Pos{Offset: 11, Line: 1, Column: 12}},
+	}
+
+	for _, c := range cases {
+		s := New([]byte(c.src))
+		tok := s.Scan()
+		for tok.Type != token.EOF {
+			tok = s.Scan()
+		}
+
+		// scanning past the end keeps the position
+		if tok.Pos != c.pos || s.Scan().Pos != c.pos {
+			t.Errorf("%q: want EOF at %#v, got %#v", c.src, c.pos, tok.Pos)
+		}
+	}
+}
+
+func TestEndPosition(t *testing.T) {
+	src := "name = \"välue\" # comment\n" +
+		"/* multi\n   line */ list = [1,\n  2.5]\n" +
+		"doc = <<EOT\nhello\nEOT\n" +
+		"a.b { c = true }"
+
+	s := New([]byte(src))
+	if got := s.Pos(); got != (token.Pos{Offset: 0, Line: 1, Column: 1}) {
+		t.Errorf("want start of the source, got %#v", got)
+	}
+
+	for {
+		tok := s.Scan()
+		end := s.Pos()
+		if end != tok.End() {
+			t.Errorf("%q: want end %#v, got %#v", tok.Text, tok.End(), end)
+		}
+		if text := src[tok.Pos.Offset:end.Offset]; text != tok.Text {
+			t.Errorf("%q: source range is %q", tok.Text, text)
+		}
+		if tok.Type == token.EOF {
+			break
+		}
+	}
+
+	if want := (token.Pos{Offset: len(src), Line: 8, Column: 17}); s.Pos() != want {
+		t.Errorf("want end of the source %#v, got %#v", want, s.Pos())
+	}
+}
+
 func TestRealExample(t *testing.T) {
 	complexHCL := `// This comes from Terraform, as a test
 	variable "foo" {
@@ -414,16 +626,78 @@ func TestError(t *testing.T) {
 	testError(t, `"ab`+"\x80", "1:4", "illegal UTF-8 encoding", token.STRING)
 	testError(t, `"abc`+"\xff", "1:5", "illegal UTF-8 encoding", token.STRING)
 
+	testError(t, "\x00", "1:1", "illegal character NUL", token.ILLEGAL)
+	testError(t, "ab\x00", "1:3", "illegal character NUL", token.IDENT)
+	testError(t, `"ab`+"\x00\"", "1:4", "illegal character NUL", token.STRING)
+
 	testError(t, `01238`, "1:6", "illegal octal number", token.NUMBER)
 	testError(t, `01238123`, "1:9", "illegal octal number", token.NUMBER)
+	testError(t, `0o`, "1:3", "illegal octal number", token.NUMBER)
+	testError(t, `0o78`, "1:5", "illegal octal number", token.NUMBER)
 	testError(t, `0x`, "1:3", "illegal hexadecimal number", token.NUMBER)
 	testError(t, `0xg`, "1:3", "illegal hexadecimal number", token.NUMBER)
+	testError(t, `1e`, "1:3", "exponent has no digits", token.NUMBER)
+	testError(t, `1e+x`, "1:4", "exponent has no digits", token.NUMBER)
 	testError(t, `'aa'`, "1:1", "illegal char", token.ILLEGAL)
 
-	testError(t, `"`, "1:2", "literal not terminated", token.STRING)
-	testError(t, `"abc`, "1:5", "literal not terminated", token.STRING)
-	testError(t, `"abc`+"\n", "1:5", "literal not terminated", token.STRING)
-	testError(t, `/*/`, "1:4", "comment not terminated", token.COMMENT)
+	testError(t, `"\0`, "1:4", "illegal char escape", token.STRING)
+	testError(t, `"\x1`, "1:5", "illegal char escape", token.STRING)
+	testError(t, `"`, "1:2", `literal opened at 1:1 not terminated, expected: "`, token.STRING)
+	testError(t, `"abc`, "1:5", `literal opened at 1:1 not terminated, expected: "`, token.STRING)
+	testError(t, "\n  \"abc\n", "2:7", `literal opened at 2:3 not terminated, expected: "`, token.STRING)
+	testError(t, `"${f("a)}"`, "1:11", `literal opened at 1:1 not terminated, expected: "`, token.STRING)
+	testError(t, `/*/`, "1:4", "comment opened at 1:1 not terminated, expected: */", token.COMMENT)
+
+	testError(t, "<<\n", "1:3", "heredoc has no marker, expected: <<MARKER or <<-MARKER", token.HEREDOC)
+	testError(t, "<<EOF x\n", "1:6", "heredoc marker EOF must be followed by a newline", token.HEREDOC)
+	testError(t, "<<EOF", "1:6", "heredoc marker EOF must be followed by a newline", token.HEREDOC)
+	testError(t, "<<EOF\rx", "1:6", "heredoc marker EOF must be followed by a newline", token.HEREDOC)
+	testError(t, "<<\r", "1:3", "heredoc has no marker, expected: <<MARKER or <<-MARKER", token.HEREDOC)
+	testError(t, "<<EOF\nabc", "2:4", "heredoc opened at 1:1 not terminated, expected: EOF on a line of its own", token.HEREDOC)
+	testError(t, "<<EOF\nabc\n  EOF\n", "4:1",
+		"heredoc opened at 1:1 not terminated, expected: EOF on a line of its own (the marker on line 3 is indented, use <<-EOF)", token.HEREDOC)
+	testError(t, "<<EOF\nabc\nEOF,\n", "4:1",
+		`heredoc opened at 1:1 not terminated, expected: EOF on a line of its own (the marker on line 3 is followed by ",")`, token.HEREDOC)
+	testError(t, "<", "1:1", "illegal char", token.ILLEGAL)
+
+	testError(t, `"\uD800"`, "1:7", "escape sequence is invalid Unicode code point", token.STRING)
+	testError(t, `"\U00110000"`, "1:11", "escape sequence is invalid Unicode code point", token.STRING)
+	testError(t, `"\UFFFFFFFF"`, "1:11", "escape sequence is invalid Unicode code point", token.STRING)
+}
+
+func TestUnicode(t *testing.T) {
+	cases := []struct {
+		src    string
+		tokens []string
+	}{
+		// the byte order mark takes no column
+		{"\ufeffa = 1", []string{`IDENT "a" 1:1 3`, `ASSIGN "=" 1:3 5`, `NUMBER "1" 1:5 7`}},
+		{"\ufeff", nil},
+		// but only at the start of the source
+		{"a\ufeff", []string{`IDENT "a" 1:1 0`, `ILLEGAL "\ufeff" 1:2 1`}},
+		// columns count runes, a \r\n is one line break
+		{"é = \"ü\"\r\nb = 2\r\n", []string{
+			`IDENT "é" 1:1 0`, `ASSIGN "=" 1:3 3`, `STRING "\"ü\"" 1:5 5`,
+			`IDENT "b" 2:1 11`, `ASSIGN "=" 2:3 13`, `NUMBER "2" 2:5 15`,
+		}},
+		{`"\u00e9 \U0001F600"`, []string{`STRING "\"\\u00e9 \\U0001F600\"" 1:1 0`}},
+	}
+
+	for _, c := range cases {
+		s := New([]byte(c.src))
+		s.Error = func(pos token.Pos, msg string) {}
+
+		var got []string
+		for tok := s.Scan(); tok.Type != token.EOF; tok = s.Scan() {
+			got = append(got, fmt.Sprintf("%s %q %s %d", tok.Type, tok.Text, tok.Pos, tok.Pos.Offset))
+		}
+		if strings.Join(got, "\n") != strings.Join(c.tokens, "\n") {
+			t.Errorf("%q:\nwant: %v\ngot:  %v", c.src, c.tokens, got)
+		}
+		if illegal := strings.Contains(strings.Join(c.tokens, " "), "ILLEGAL"); (s.ErrorCount > 0) != illegal {
+			t.Errorf("%q: %d errors", c.src, s.ErrorCount)
+		}
+	}
 }
 
 func testError(t *testing.T, src, pos, msg string, tok token.Type) {
@@ -485,3 +759,176 @@ func countNewlines(s string) int {
 	}
 	return n
 }
+
+// repeatReader reads src n times without holding the result in memory
+type repeatReader struct {
+	src  []byte
+	n    int
+	off  int
+	fail error // returned after the last repetition instead of io.EOF
+}
+
+func (r *repeatReader) Read(p []byte) (int, error) {
+	if r.n == 0 {
+		if r.fail != nil {
+			return 0, r.fail
+		}
+		return 0, io.EOF
+	}
+
+	n := copy(p, r.src[r.off:])
+	if r.off += n; r.off == len(r.src) {
+		r.off = 0
+		r.n--
+	}
+	return n, nil
+}
+
+func TestNewReader(t *testing.T) {
+	for name, list := range tokenLists {
+		buf := new(bytes.Buffer)
+		for _, pair := range list {
+			fmt.Fprintf(buf, "%s\n", pair.text)
+		}
+
+		a, b := New(buf.Bytes()), NewReader(bytes.NewReader(buf.Bytes()))
+		for {
+			ta, tb := a.Scan(), b.Scan()
+			if ta != tb {
+				t.Errorf("%s: want %#v, got %#v", name, ta, tb)
+				break
+			}
+			if ta.Type == token.EOF {
+				break
+			}
+		}
+	}
+
+	// invalid UTF-8 is kept in the text
+	s := NewReader(bytes.NewReader([]byte("\"a\xffb\"")))
+	s.Error = func(pos token.Pos, msg string) {}
+	if tok := s.Scan(); tok.Text != "\"a\xffb\"" {
+		t.Errorf("want the invalid byte in the text, got %q", tok.Text)
+	}
+
+	// read errors end the source
+	var errs []string
+	s = NewReader(&repeatReader{src: []byte("a = 1\n"), n: 1, fail: errors.New("disk on fire")})
+	s.Error = func(pos token.Pos, msg string) {
+		errs = append(errs, fmt.Sprintf("%s: %s", pos, msg))
+	}
+	for s.Scan().Type != token.EOF {
+	}
+	if len(errs) != 1 || errs[0] != "2:1: disk on fire" {
+		t.Errorf("unexpected errors: %q", errs)
+	}
+}
+
+func TestNewReaderMemory(t *testing.T) {
+	// 16 MB of source, the scanner holds the current token only
+	s := NewReader(&repeatReader{src: []byte("service \"web\" { port = 8080 } # comment\n"), n: 400000})
+	n := 0
+	for s.Scan().Type != token.EOF {
+		n++
+	}
+
+	if n != 400000*8 {
+		t.Errorf("want %d tokens, got %d", 400000*8, n)
+	}
+
+	if cap(s.text) > 64 {
+		t.Errorf("want the token buffer to stay small, got a capacity of %d", cap(s.text))
+	}
+}
+
+func TestStartAt(t *testing.T) {
+	s := New([]byte("a = 1\nb = 2"))
+	s.StartAt(token.Pos{Offset: 100, Line: 10, Column: 1})
+
+	var got []string
+	for tok := s.Scan(); tok.Type != token.EOF; tok = s.Scan() {
+		got = append(got, fmt.Sprintf("%s %d", tok.Pos, tok.Pos.Offset))
+	}
+
+	want := []string{"10:1 100", "10:3 102", "10:5 104", "11:1 106", "11:3 108", "11:5 110"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("want %q, got %q", want, got)
+	}
+}
+
+func TestScanRaw(t *testing.T) {
+	src := []byte("a = \"b\" # c\nd \"e\" { f = [1, 2.5, true] }\ng = <<EOF\nh\nEOF\n")
+
+	var want []token.Token
+	s := New(src)
+	for {
+		tok := s.Scan()
+		want = append(want, tok)
+		if tok.Type == token.EOF {
+			break
+		}
+	}
+
+	var got []token.Token
+	New(src).ScanRaw(func(typ token.Type, text []byte, pos token.Pos) bool {
+		got = append(got, token.Token{Type: typ, Pos: pos, Text: string(text)})
+		return true
+	})
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("want %v, got %v", want, got)
+	}
+
+	n := 0
+	New(src).ScanRaw(func(typ token.Type, text []byte, pos token.Pos) bool {
+		n++
+		return typ != token.STRING
+	})
+	if n != 3 {
+		t.Errorf("want 3 tokens up to the first string, got %d", n)
+	}
+
+	// the tokens are scanned without allocating, once the buffers are
+	// allocated
+	allocs := func(src []byte) float64 {
+		return testing.AllocsPerRun(10, func() {
+			New(src).ScanRaw(func(token.Type, []byte, token.Pos) bool { return true })
+		})
+	}
+	if short, long := allocs(benchSource[:len(benchSource)/16384]), allocs(benchSource); long > short {
+		t.Errorf("want %v allocations for any source, got %v", short, long)
+	}
+}
+
+// benchSource is the source of the benchmarks, a generated config of 1 MB
+var benchSource = bytes.Repeat([]byte("service \"web\" {\n  port = 8080\n  tags = [\"a\", \"b\"] # comment\n}\n"), 16384)
+
+func BenchmarkScan(b *testing.B) {
+	b.SetBytes(int64(len(benchSource)))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		s := New(benchSource)
+		for s.Scan().Type != token.EOF {
+		}
+	}
+}
+
+func BenchmarkScanRaw(b *testing.B) {
+	b.SetBytes(int64(len(benchSource)))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		New(benchSource).ScanRaw(func(token.Type, []byte, token.Pos) bool { return true })
+	}
+}
+
+// BenchmarkScanReader scans the same source from a reader generating it, the
+// bytes allocated per operation are the text of the tokens only
+func BenchmarkScanReader(b *testing.B) {
+	line := []byte("service \"web\" {\n  port = 8080\n  tags = [\"a\", \"b\"] # comment\n}\n")
+	b.SetBytes(int64(len(benchSource)))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		s := NewReader(&repeatReader{src: line, n: 16384})
+		for s.Scan().Type != token.EOF {
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package schema
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/fatih/hcl/ast"
+	"github.com/fatih/hcl/token"
+)
+
+// Deprecation is a deprecated attribute defined in a file, reported by
+// Deprecations.
+type Deprecation struct {
+	Pos       token.Pos // position of the item defining the attribute
+	Path      string    // path of the attribute, such as service.web.port
+	Attribute *Attribute
+
+	// Removed is set if the attribute is already removed by the version
+	// given to Deprecations, which fails to decode the file.
+	Removed bool
+}
+
+// String returns the deprecation and what to change, i.e. "At 3:3:
+// service.web.port is deprecated since 1.2 and removed in 2.0, use listen
+// instead".
+func (d *Deprecation) String() string {
+	attr := d.Attribute
+	msg := fmt.Sprintf("At %s: %s is deprecated", d.Pos, d.Path)
+	switch {
+	case d.Removed:
+		msg = fmt.Sprintf("At %s: %s is removed in %s", d.Pos, d.Path, attr.Removed)
+	case attr.Deprecated != "" && attr.Removed != "":
+		msg += fmt.Sprintf(" since %s and removed in %s", attr.Deprecated, attr.Removed)
+	case attr.Deprecated != "":
+		msg += " since " + attr.Deprecated
+	}
+
+	if attr.ReplacedBy != "" {
+		msg += ", use " + attr.ReplacedBy + " instead"
+	}
+	return msg
+}
+
+// Deprecations returns the attributes defined in the file or object n, and
+// the blocks inside of it, which are deprecated or removed by the running
+// version, in the order they're defined. These are the changes to make
+// before upgrading to a version removing them. Versions are compared by
+// their dotted numbers, i.e. 1.10 is after 1.9 and v2 after 1.10.
+func (s *Schema) Deprecations(n ast.Node, version string) []*Deprecation {
+	list, ok := ast.ObjectListOf(n)
+	if !ok {
+		return nil
+	}
+
+	var res []*Deprecation
+	var walk func(s *Schema, list *ast.ObjectList, path []string)
+	walk = func(s *Schema, list *ast.ObjectList, path []string) {
+		for _, item := range list.Items {
+			if len(item.Keys) == 0 {
+				continue
+			}
+			name := item.Keys[0].Token.Value()
+
+			if attr := s.attribute(name); attr != nil {
+				deprecated := attr.Deprecated != "" && compareVersions(version, attr.Deprecated) >= 0
+				removed := attr.Removed != "" && compareVersions(version, attr.Removed) >= 0
+				if deprecated || removed {
+					res = append(res, &Deprecation{
+						Pos:       item.Pos(),
+						Path:      ast.JoinPath(append(path[:len(path):len(path)], name)),
+						Attribute: attr,
+						Removed:   removed,
+					})
+				}
+				continue
+			}
+
+			block := s.block(name)
+			body, ok := ast.ObjectListOf(item.Val)
+			if block == nil || block.Body == nil || !ok {
+				continue
+			}
+
+			p := path[:len(path):len(path)]
+			for _, k := range item.Keys {
+				p = append(p, k.Token.Value())
+			}
+			walk(block.Body, body, p)
+		}
+	}
+	walk(s, list, nil)
+
+	return res
+}
+
+// compareVersions compares the versions a and b, such as 1.2.3 or v2,
+// returning -1 if a is before b, 0 if they're equal and 1 if a is after b.
+// Their dotted parts are compared as numbers, missing parts are 0, and
+// anything following the number of a part, such as "-beta", as text.
+func compareVersions(a, b string) int {
+	as := strings.Split(strings.TrimPrefix(a, "v"), ".")
+	bs := strings.Split(strings.TrimPrefix(b, "v"), ".")
+	for i := 0; i < len(as) || i < len(bs); i++ {
+		an, arest := versionPart(as, i)
+		bn, brest := versionPart(bs, i)
+		switch {
+		case an < bn:
+			return -1
+		case an > bn:
+			return 1
+		case arest != brest:
+			// a pre-release, such as 1.0-beta, is before the release
+			if arest == "" || brest != "" && arest > brest {
+				return 1
+			}
+			return -1
+		}
+	}
+	return 0
+}
+
+// versionPart returns the number and the rest of the part i of a version
+func versionPart(parts []string, i int) (int, string) {
+	if i >= len(parts) {
+		return 0, ""
+	}
+
+	part := parts[i]
+	j := 0
+	for j < len(part) && '0' <= part[j] && part[j] <= '9' {
+		j++
+	}
+	n, _ := strconv.Atoi(part[:j])
+	return n, part[j:]
+}
@@ -0,0 +1,107 @@
+package schema
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/fatih/hcl/parser"
+)
+
+func TestDeprecations(t *testing.T) {
+	s := &Schema{
+		Attributes: []*Attribute{
+			{Name: "region", Type: String},
+			{Name: "zone", Type: String, Deprecated: "1.2", ReplacedBy: "region"},
+		},
+		Blocks: []*Block{
+			{
+				Type:   "service",
+				Labels: []string{"name"},
+				Body: &Schema{
+					Attributes: []*Attribute{
+						{Name: "listen", Type: String},
+						{Name: "port", Type: Number, Deprecated: "1.5", Removed: "2.0", ReplacedBy: "listen"},
+						{Name: "legacy", Type: Bool, Removed: "1.10"},
+					},
+				},
+			},
+		},
+	}
+
+	src := `zone = "eu"
+region = "eu"
+
+service "web" {
+  port = 80
+  legacy = true
+}
+
+service "db" {
+  listen = ":5432"
+}
+`
+	f, err := parser.Parse([]byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		version string
+		want    []string
+	}{
+		{"1.0", nil},
+		{"1.2", []string{
+			`At 1:1: zone is deprecated since 1.2, use region instead`,
+		}},
+		{"v1.9.3", []string{
+			`At 1:1: zone is deprecated since 1.2, use region instead`,
+			`At 5:3: service.web.port is deprecated since 1.5 and removed in 2.0, use listen instead`,
+		}},
+		{"1.10.0-beta", []string{
+			`At 1:1: zone is deprecated since 1.2, use region instead`,
+			`At 5:3: service.web.port is deprecated since 1.5 and removed in 2.0, use listen instead`,
+		}},
+		{"1.10", []string{
+			`At 1:1: zone is deprecated since 1.2, use region instead`,
+			`At 5:3: service.web.port is deprecated since 1.5 and removed in 2.0, use listen instead`,
+			`At 6:3: service.web.legacy is removed in 1.10`,
+		}},
+		{"2", []string{
+			`At 1:1: zone is deprecated since 1.2, use region instead`,
+			`At 5:3: service.web.port is removed in 2.0, use listen instead`,
+			`At 6:3: service.web.legacy is removed in 1.10`,
+		}},
+	}
+
+	for _, c := range cases {
+		var got []string
+		for _, d := range s.Deprecations(f, c.version) {
+			got = append(got, d.String())
+		}
+
+		if !reflect.DeepEqual(got, c.want) {
+			t.Errorf("%s: want %q, got %q", c.version, c.want, got)
+		}
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	cases := []struct {
+		a, b string
+		want int
+	}{
+		{"1.2", "1.2.0", 0},
+		{"v1.2", "1.2", 0},
+		{"1.9", "1.10", -1},
+		{"2", "1.10", 1},
+		{"1.0-beta", "1.0", -1},
+		{"1.0-rc.1", "1.0-beta", 1},
+		{"1.0", "1.0-beta", 1},
+	}
+
+	for _, c := range cases {
+		if got := compareVersions(c.a, c.b); got != c.want {
+			t.Errorf("%s, %s: want %d, got %d", c.a, c.b, c.want, got)
+		}
+	}
+}
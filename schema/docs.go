@@ -0,0 +1,137 @@
+package schema
+
+import (
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// section is the documentation of the top level or of a single block
+type section struct {
+	Level      int    // heading level
+	Title      string // such as `service "name"`
+	Path       string // types of the enclosing blocks and the block
+	Desc       string
+	Attributes []*Attribute
+	Blocks     []*Block
+}
+
+// sections returns the sections of s and all nested blocks in depth-first
+// order
+func sections(s *Schema, title string) []section {
+	secs := []section{{Level: 1, Title: title, Attributes: s.Attributes, Blocks: s.Blocks}}
+
+	var add func(blocks []*Block, level int, path []string)
+	add = func(blocks []*Block, level int, path []string) {
+		for _, b := range blocks {
+			p := append(path[:len(path):len(path)], b.Type)
+			sec := section{
+				Level: level,
+				Title: blockUsage(b),
+				Path:  strings.Join(p, " > "),
+				Desc:  b.Description,
+			}
+
+			if b.Body != nil {
+				sec.Attributes = b.Body.Attributes
+				sec.Blocks = b.Body.Blocks
+			}
+
+			secs = append(secs, sec)
+			if b.Body != nil {
+				add(b.Body.Blocks, level+1, p)
+			}
+		}
+	}
+	add(s.Blocks, 2, nil)
+
+	return secs
+}
+
+// blockUsage returns the header of a block, such as service "name", with
+// optional labels in brackets
+func blockUsage(b *Block) string {
+	parts := []string{b.Type}
+	for i, l := range b.Labels {
+		if i >= len(b.Labels)-b.OptionalLabels {
+			parts = append(parts, "["+strconv.Quote(l)+"]")
+			continue
+		}
+		parts = append(parts, strconv.Quote(l))
+	}
+	return strings.Join(parts, " ")
+}
+
+var funcs = map[string]interface{}{
+	"heading": func(level int) string {
+		if level > 6 {
+			level = 6
+		}
+		return strings.Repeat("#", level)
+	},
+	"usage":   blockUsage,
+	"default": formatDefault,
+	"cell": func(s string) string {
+		s = strings.Replace(s, "|", `\|`, -1)
+		return strings.Replace(s, "\n", " ", -1)
+	},
+}
+
+var markdownTemplate = template.Must(template.New("markdown").Funcs(funcs).Parse(
+	`{{range .}}{{heading .Level}} {{if .Path}}` + "`{{.Title}}`" + `{{else}}{{.Title}}{{end}}
+{{if .Desc}}
+{{.Desc}}
+{{end}}{{if .Path}}
+Path: {{.Path}}
+{{end}}{{if .Attributes}}
+| Attribute | Type | Required | Default | Description |
+|-----------|------|----------|---------|-------------|
+{{range .Attributes}}| ` + "`{{.Name}}`" + ` | {{.Type}} | {{if .Required}}yes{{else}}no{{end}} | {{with default .Default}}` + "`{{cell .}}`" + `{{end}} | {{cell .Description}} |
+{{end}}{{end}}{{if .Blocks}}
+Blocks: {{range $i, $b := .Blocks}}{{if $i}}, {{end}}` + "`{{usage $b}}`" + `{{end}}
+{{end}}
+{{end}}`))
+
+var htmlTemplate = htmltemplate.Must(htmltemplate.New("html").Funcs(funcs).Parse(
+	`{{range .}}<h{{.Level}}>{{if .Path}}<code>{{.Title}}</code>{{else}}{{.Title}}{{end}}</h{{.Level}}>
+{{if .Desc}}<p>{{.Desc}}</p>
+{{end}}{{if .Path}}<p>Path: {{.Path}}</p>
+{{end}}{{if .Attributes}}<table>
+<tr><th>Attribute</th><th>Type</th><th>Required</th><th>Default</th><th>Description</th></tr>
+{{range .Attributes}}<tr><td><code>{{.Name}}</code></td><td>{{.Type}}</td><td>{{if .Required}}yes{{else}}no{{end}}</td><td>{{with default .Default}}<code>{{.}}</code>{{end}}</td><td>{{.Description}}</td></tr>
+{{end}}</table>
+{{end}}{{if .Blocks}}<p>Blocks: {{range $i, $b := .Blocks}}{{if $i}}, {{end}}<code>{{usage $b}}</code>{{end}}</p>
+{{end}}{{end}}`))
+
+// Markdown writes the reference documentation of s as Markdown to w. It
+// starts with a heading of the given title, followed by a table of the
+// attributes and a section for every block.
+func (s *Schema) Markdown(w io.Writer, title string) error {
+	return markdownTemplate.Execute(w, sections(s, title))
+}
+
+// HTML writes the reference documentation of s as an HTML fragment to w. See
+// Markdown.
+func (s *Schema) HTML(w io.Writer, title string) error {
+	return htmlTemplate.Execute(w, sections(s, title))
+}
+
+// formatDefault returns a default value as written in HCL
+func formatDefault(v interface{}) string {
+	switch t := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return strconv.Quote(t)
+	case []interface{}:
+		elems := make([]string, 0, len(t))
+		for _, elem := range t {
+			elems = append(elems, formatDefault(elem))
+		}
+		return "[" + strings.Join(elems, ", ") + "]"
+	}
+	return fmt.Sprint(v)
+}
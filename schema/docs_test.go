@@ -0,0 +1,259 @@
+package schema
+
+import (
+	"bytes"
+	"net"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testConfig struct {
+	Region   string              `hcl:"region,required" doc:"AWS region to deploy to."`
+	Debug    bool                `hcl:"debug" doc:"Enables debug logging."`
+	Services map[string]*testSvc `hcl:"service" doc:"A service to run."`
+	Ignored  string              `hcl:"-"`
+	OnReload func()              `hcl:"on_reload"`
+	Events   []chan string       `hcl:"events"`
+}
+
+type testSvc struct {
+	Port   int               `hcl:"port,required"`
+	Hosts  []string          `hcl:"hosts" doc:"Hosts | addresses."`
+	Tags   map[string]string `hcl:"tags"`
+	Checks []struct {
+		Path string `hcl:"path"`
+	} `hcl:"check"`
+}
+
+func TestFromStruct(t *testing.T) {
+	s, err := FromStruct(&testConfig{Debug: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(s.Attributes) != 2 || len(s.Blocks) != 1 {
+		t.Fatalf("unexpected schema: %+v", s)
+	}
+
+	region, debug := s.Attributes[0], s.Attributes[1]
+	if region.Name != "region" || region.Type != String || !region.Required || region.Default != nil {
+		t.Errorf("unexpected attribute: %+v", region)
+	}
+
+	if debug.Type != Bool || debug.Required || debug.Default != true {
+		t.Errorf("unexpected attribute: %+v", debug)
+	}
+
+	svc := s.Blocks[0]
+	if svc.Type != "service" || len(svc.Labels) != 1 || svc.Labels[0] != "name" {
+		t.Fatalf("unexpected block: %+v", svc)
+	}
+
+	if len(svc.Body.Attributes) != 3 || svc.Body.Attributes[2].Type != Object || len(svc.Body.Blocks) != 1 {
+		t.Errorf("unexpected block body: %+v", svc.Body)
+	}
+
+	for _, a := range svc.Body.Attributes {
+		if want := map[string]Type{"port": Any, "hosts": String, "tags": String}[a.Name]; a.Elem != want {
+			t.Errorf("%s: want element type %s, got %s", a.Name, want, a.Elem)
+		}
+	}
+
+	if _, err := FromStruct("foo"); err == nil {
+		t.Error("FromStruct should fail for non-struct values")
+	}
+}
+
+func TestFromStructEmbedded(t *testing.T) {
+	type Logging struct {
+		Level string `hcl:"level" doc:"The log level."`
+		Debug bool   `hcl:"debug"`
+	}
+	type config struct {
+		*Logging
+		Debug bool `hcl:"debug" doc:"Hides the promoted field."`
+		Cache struct {
+			Size int `hcl:"size"`
+		} `hcl:",squash"`
+	}
+
+	s, err := FromStruct(&config{Logging: &Logging{Level: "info"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for _, a := range s.Attributes {
+		names = append(names, a.Name)
+	}
+	if want := []string{"level", "debug", "size"}; !reflect.DeepEqual(names, want) {
+		t.Fatalf("want attributes %q, got %q", want, names)
+	}
+
+	if s.Attributes[0].Default != "info" || s.Attributes[1].Description != "Hides the promoted field." {
+		t.Errorf("unexpected attributes: %+v, %+v", s.Attributes[0], s.Attributes[1])
+	}
+}
+
+func TestFromStructKey(t *testing.T) {
+	type service struct {
+		Name    string   `hcl:",key"`
+		Port    int      `hcl:"port"`
+		Decoded []string `hcl:",decodedFields"`
+	}
+	type config struct {
+		Services []service          `hcl:"service"`
+		Backends map[string]service `hcl:"backend"`
+	}
+
+	s, err := FromStruct(config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	services, backends := s.Blocks[0], s.Blocks[1]
+	if !reflect.DeepEqual(services.Labels, []string{"name"}) || services.OptionalLabels != 1 {
+		t.Errorf("want an optional name label, got %q, %d optional", services.Labels, services.OptionalLabels)
+	}
+	if !reflect.DeepEqual(backends.Labels, []string{"name"}) || backends.OptionalLabels != 0 {
+		t.Errorf("want a required name label, got %q, %d optional", backends.Labels, backends.OptionalLabels)
+	}
+	if len(services.Body.Attributes) != 1 || services.Body.Attributes[0].Name != "port" {
+		t.Errorf("want only the port attribute, got %+v", services.Body.Attributes)
+	}
+	if got := blockUsage(services); got != `service ["name"]` {
+		t.Errorf("unexpected usage %s", got)
+	}
+}
+
+func TestFromStructStringTypes(t *testing.T) {
+	type config struct {
+		Timeout time.Duration `hcl:"timeout"`
+		Expires time.Time     `hcl:"expires"`
+		Allowed []net.IP      `hcl:"allowed"`
+		URL     *url.URL      `hcl:"url"`
+	}
+
+	s, err := FromStruct(config{Timeout: 90 * time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(s.Attributes) != 4 || len(s.Blocks) != 0 {
+		t.Fatalf("unexpected schema: %+v", s)
+	}
+
+	var types []Type
+	for _, attr := range s.Attributes {
+		types = append(types, attr.Type)
+	}
+	if want := []Type{Any, String, List, String}; !reflect.DeepEqual(types, want) {
+		t.Errorf("want types %v, got %v", want, types)
+	}
+
+	if elem := s.Attributes[2].Elem; elem != String {
+		t.Errorf("want elements of type string, got %s", elem)
+	}
+
+	if d := s.Attributes[0].Default; d != "1m30s" {
+		t.Errorf("unexpected default: %#v", d)
+	}
+}
+
+func TestFromStructParse(t *testing.T) {
+	type schedule struct{ Minute, Hour string }
+	type config struct {
+		Schedule schedule   `hcl:"schedule,required" parse:"cron"`
+		Windows  []schedule `hcl:"windows" parse:"cron"`
+	}
+
+	s, err := FromStruct(config{Schedule: schedule{Minute: "0"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(s.Attributes) != 2 || len(s.Blocks) != 0 {
+		t.Fatalf("unexpected schema: %+v", s)
+	}
+
+	if a := s.Attributes[0]; a.Type != String || !a.Required || a.Default != nil {
+		t.Errorf("unexpected attribute: %+v", a)
+	}
+	if a := s.Attributes[1]; a.Type != List || a.Elem != String {
+		t.Errorf("unexpected attribute: %+v", a)
+	}
+}
+
+func TestMarkdown(t *testing.T) {
+	s, err := FromStruct(testConfig{Debug: true, Region: "eu-west-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := s.Markdown(&buf, "Configuration"); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "# Configuration\n" +
+		"\n" +
+		"| Attribute | Type | Required | Default | Description |\n" +
+		"|-----------|------|----------|---------|-------------|\n" +
+		"| `region` | string | yes | `\"eu-west-1\"` | AWS region to deploy to. |\n" +
+		"| `debug` | bool | no | `true` | Enables debug logging. |\n" +
+		"\n" +
+		"Blocks: `service \"name\"`\n" +
+		"\n" +
+		"## `service \"name\"`\n" +
+		"\n" +
+		"A service to run.\n" +
+		"\n" +
+		"Path: service\n" +
+		"\n" +
+		"| Attribute | Type | Required | Default | Description |\n" +
+		"|-----------|------|----------|---------|-------------|\n" +
+		"| `port` | number | yes |  |  |\n" +
+		"| `hosts` | list | no |  | Hosts \\| addresses. |\n" +
+		"| `tags` | object | no |  |  |\n" +
+		"\n" +
+		"Blocks: `check`\n" +
+		"\n" +
+		"### `check`\n" +
+		"\n" +
+		"Path: service > check\n" +
+		"\n" +
+		"| Attribute | Type | Required | Default | Description |\n" +
+		"|-----------|------|----------|---------|-------------|\n" +
+		"| `path` | string | no |  |  |\n" +
+		"\n"
+
+	if buf.String() != expected {
+		t.Errorf("\nwant:\n%s\ngot:\n%s", expected, buf.String())
+	}
+}
+
+func TestHTML(t *testing.T) {
+	s := &Schema{
+		Attributes: []*Attribute{{Name: "name", Type: String, Default: "<web>", Description: "Name & title."}},
+		Blocks:     []*Block{{Type: "check"}},
+	}
+
+	var buf bytes.Buffer
+	if err := s.HTML(&buf, "Reference"); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{
+		"<h1>Reference</h1>",
+		"<td><code>name</code></td><td>string</td><td>no</td><td><code>&#34;&lt;web&gt;&#34;</code></td><td>Name &amp; title.</td>",
+		"<h2><code>check</code></h2>",
+		"<p>Path: check</p>",
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("output doesn't contain %q:\n%s", want, buf.String())
+		}
+	}
+}
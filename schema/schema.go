@@ -0,0 +1,552 @@
+// Package schema validates HCL (HashiCorp Configuration Language) syntax
+// trees against a declarative description of the expected attributes and
+// blocks, independent of the Go types a file might be decoded into.
+package schema
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/fatih/hcl/ast"
+	"github.com/fatih/hcl/token"
+)
+
+// Type is the type of the value of an attribute.
+type Type int
+
+const (
+	Any Type = iota
+	String
+	Number // integers and floats
+	Bool
+	List
+	Object
+)
+
+func (t Type) String() string {
+	switch t {
+	case Any:
+		return "any"
+	case String:
+		return "string"
+	case Number:
+		return "number"
+	case Bool:
+		return "bool"
+	case List:
+		return "list"
+	case Object:
+		return "object"
+	}
+	return "unknown"
+}
+
+// Schema describes the content of a file or the body of a block.
+type Schema struct {
+	Attributes []*Attribute
+	Blocks     []*Block
+}
+
+// Attribute describes an attribute, such as port = 80.
+type Attribute struct {
+	Name        string
+	Type        Type
+	Elem        Type // type of the elements of a list or values of an object
+	Required    bool
+	Default     interface{} // value of a missing attribute, see ApplyDefaults
+	Description string
+
+	// Deprecated and Removed are the versions deprecating and removing the
+	// attribute, if any, and ReplacedBy the attribute to use instead. See
+	// Deprecations.
+	Deprecated string
+	Removed    string
+	ReplacedBy string
+}
+
+// Block describes a block, such as service "web" { ... }. A block might be
+// repeated, unless MaxItems limits it.
+type Block struct {
+	Type        string   // name of the block, such as "service"
+	Labels      []string // names of the labels, such as "name"
+	Description string
+	Body        *Schema // content of the block, nil for any content
+
+	// MinItems and MaxItems are the minimum and maximum number of blocks of
+	// the type in an object, i.e. 1 and 1 for exactly one server block. A
+	// MaxItems of 0 allows any number of blocks.
+	MinItems int
+	MaxItems int
+
+	// OptionalLabels is the number of labels at the end of Labels which a
+	// block may omit, i.e. 1 for both output "name" {} and output {}.
+	OptionalLabels int
+
+	// LabelRules constrains the values of the labels, LabelRules[i] the one
+	// of the label named Labels[i]. Missing and nil rules allow any value.
+	LabelRules []*LabelRule
+}
+
+// LabelRule constrains the value of a block label, such as the name of an
+// environment "prod" {} block.
+type LabelRule struct {
+	// Pattern is the regular expression the label must match, if set. It
+	// matches substrings unless it's anchored, i.e. ^[a-z][a-z0-9-]*$.
+	Pattern *regexp.Regexp
+
+	// Values are the allowed values of the label, any if empty.
+	Values []string
+}
+
+// Severity is the severity of a Diagnostic.
+type Severity int
+
+const (
+	Error Severity = iota
+	Warning
+)
+
+func (s Severity) String() string {
+	if s == Warning {
+		return "warning"
+	}
+	return "error"
+}
+
+// Diagnostic describes a problem found by Validate.
+type Diagnostic struct {
+	Severity Severity
+	Pos      token.Pos
+	Summary  string // short description, such as "Missing required attribute"
+	Detail   string // full description of the problem
+}
+
+func (d *Diagnostic) Error() string {
+	return fmt.Sprintf("At %s: %s: %s", d.Pos, d.Summary, d.Detail)
+}
+
+// Diagnostics is a list of diagnostics in the order they were found.
+type Diagnostics []*Diagnostic
+
+// HasErrors reports whether any of the diagnostics is an error.
+func (d Diagnostics) HasErrors() bool {
+	for _, diag := range d {
+		if diag.Severity == Error {
+			return true
+		}
+	}
+	return false
+}
+
+func (d Diagnostics) Error() string {
+	msgs := make([]string, 0, len(d))
+	for _, diag := range d {
+		msgs = append(msgs, diag.Error())
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// Validate validates the file or object n against the schema and returns the
+// problems found, such as missing required or unexpected attributes, values
+// and elements of the wrong type, blocks with the wrong number of labels or
+// labels not matching Block.LabelRules, and missing or extra blocks, outside
+// of Block.MinItems and Block.MaxItems. Strings containing interpolations
+// match every type.
+func (s *Schema) Validate(n ast.Node) Diagnostics {
+	list, ok := ast.ObjectListOf(n)
+	if !ok {
+		return Diagnostics{{
+			Severity: Error,
+			Pos:      n.Pos(),
+			Summary:  "Unexpected value",
+			Detail:   "An object is expected here.",
+		}}
+	}
+
+	v := &validator{}
+	v.validate(s, list, n.Pos())
+	return v.diags
+}
+
+// ApplyDefaults adds the attributes with a default value, which are missing
+// in the file or object n and the blocks inside of it, to the syntax tree.
+// Defaults must be strings, ints, floats, bools or lists of these.
+func (s *Schema) ApplyDefaults(n ast.Node) error {
+	list, ok := ast.ObjectListOf(n)
+	if !ok {
+		return fmt.Errorf("expected an object, got: %T", n)
+	}
+
+	for _, attr := range s.Attributes {
+		if attr.Default == nil || len(items(list, attr.Name)) > 0 {
+			continue
+		}
+
+		val, err := literal(attr.Default)
+		if err != nil {
+			return fmt.Errorf("default of %s: %s", attr.Name, err)
+		}
+
+		list.Add(&ast.ObjectItem{
+			Keys: []*ast.ObjectKey{{Token: token.Token{Type: token.IDENT, Text: attr.Name}}},
+			Val:  val,
+		})
+	}
+
+	for _, block := range s.Blocks {
+		if block.Body == nil {
+			continue
+		}
+
+		for _, item := range items(list, block.Type) {
+			if err := block.Body.ApplyDefaults(item.Val); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+type validator struct {
+	diags Diagnostics
+}
+
+func (v *validator) errorf(pos token.Pos, summary, format string, args ...interface{}) {
+	v.diags = append(v.diags, &Diagnostic{
+		Severity: Error,
+		Pos:      pos,
+		Summary:  summary,
+		Detail:   fmt.Sprintf(format, args...),
+	})
+}
+
+// validate validates the items of an object, pos is the position of the
+// object used for missing attributes
+func (v *validator) validate(s *Schema, list *ast.ObjectList, pos token.Pos) {
+	seen := make(map[string]*ast.ObjectItem)
+	blocks := make(map[string][]*ast.ObjectItem)
+	for _, item := range list.Items {
+		if len(item.Keys) == 0 {
+			continue
+		}
+		name := item.Keys[0].Token.Value()
+
+		if attr := s.attribute(name); attr != nil {
+			if prev, ok := seen[name]; ok {
+				v.errorf(item.Pos(), "Duplicate attribute",
+					"The attribute %q was already defined at %s.", name, prev.Pos())
+				continue
+			}
+			seen[name] = item
+
+			v.attribute(attr, item)
+			continue
+		}
+
+		if block := s.block(name); block != nil {
+			blocks[name] = append(blocks[name], item)
+			if block.MaxItems > 0 && len(blocks[name]) > block.MaxItems {
+				v.tooMany(block, blocks[name])
+				continue
+			}
+
+			v.block(block, item)
+			continue
+		}
+
+		v.errorf(item.Pos(), "Unsupported argument",
+			"An attribute or block named %q is not expected here.%s", name, s.suggest(name))
+	}
+
+	for _, attr := range s.Attributes {
+		if _, ok := seen[attr.Name]; attr.Required && !ok {
+			v.errorf(pos, "Missing required attribute",
+				"The attribute %q is required, but no definition was found.", attr.Name)
+		}
+	}
+
+	for _, block := range s.Blocks {
+		if n := len(blocks[block.Type]); n < block.MinItems {
+			v.errorf(pos, "Insufficient blocks",
+				"At least %s required, but %d found.", blockCount(block.MinItems, block.Type), n)
+		}
+	}
+}
+
+// tooMany reports the last of the blocks, which exceeds the MaxItems of block
+func (v *validator) tooMany(block *Block, items []*ast.ObjectItem) {
+	item := items[len(items)-1]
+	if block.MaxItems == 1 {
+		v.errorf(item.Pos(), "Duplicate block",
+			"Only one %q block is allowed. Another was defined at %s.", block.Type, items[0].Pos())
+		return
+	}
+
+	v.errorf(item.Pos(), "Too many blocks",
+		"At most %s allowed, the first at %s.", blockCount(block.MaxItems, block.Type), items[0].Pos())
+}
+
+// blockCount returns n blocks of type typ in words, i.e. `2 "server" blocks`
+func blockCount(n int, typ string) string {
+	if n == 1 {
+		return fmt.Sprintf("1 %q block is", typ)
+	}
+	return fmt.Sprintf("%d %q blocks are", n, typ)
+}
+
+func (v *validator) attribute(attr *Attribute, item *ast.ObjectItem) {
+	if len(item.Keys) > 1 {
+		v.errorf(item.Keys[1].Pos(), "Unexpected label",
+			"%q is an attribute and can't have labels.", attr.Name)
+		return
+	}
+
+	typ := typeOf(item.Val)
+	if attr.Type != Any && typ != Any && typ != attr.Type {
+		v.errorf(item.Val.Pos(), "Incorrect attribute type",
+			"The attribute %q must be of type %s, got: %s.", attr.Name, attr.Type, typ)
+		return
+	}
+
+	if attr.Elem == Any {
+		return
+	}
+
+	var elems []ast.Node
+	switch t := item.Val.(type) {
+	case *ast.ListType:
+		elems = t.List
+	case *ast.ObjectType:
+		for _, i := range t.List.Items {
+			elems = append(elems, i.Val)
+		}
+	}
+
+	for _, elem := range elems {
+		if typ := typeOf(elem); typ != Any && typ != attr.Elem {
+			v.errorf(elem.Pos(), "Incorrect attribute type",
+				"The elements of the attribute %q must be of type %s, got: %s.", attr.Name, attr.Elem, typ)
+		}
+	}
+}
+
+func (v *validator) block(block *Block, item *ast.ObjectItem) {
+	labels := item.Keys[1:]
+	if item.Assign.IsValid() {
+		v.errorf(item.Assign, "Unexpected assignment",
+			"%q is a block, use %s { ... } instead.", block.Type, block.Type)
+		return
+	}
+
+	if len(labels) > len(block.Labels) || len(labels) < len(block.Labels)-block.OptionalLabels {
+		pos := item.Val.Pos()
+		if len(labels) > len(block.Labels) {
+			pos = labels[len(block.Labels)].Pos()
+		}
+
+		required := labelNames(block.Labels)
+		if block.OptionalLabels > 0 {
+			required = fmt.Sprintf("at least %d of %s", len(block.Labels)-block.OptionalLabels, required)
+		}
+		v.errorf(pos, "Wrong number of labels",
+			"A %q block requires %s, got: %d.", block.Type, required, len(labels))
+		return
+	}
+
+	for i, label := range labels {
+		if i < len(block.LabelRules) && block.LabelRules[i] != nil {
+			v.label(block, i, label)
+		}
+	}
+
+	list, ok := ast.ObjectListOf(item.Val)
+	if !ok {
+		v.errorf(item.Val.Pos(), "Unexpected value",
+			"The body of a %q block must be an object.", block.Type)
+		return
+	}
+
+	if block.Body != nil {
+		v.validate(block.Body, list, item.Val.Pos())
+	}
+}
+
+// label validates the i-th label of a block against its rule
+func (v *validator) label(block *Block, i int, label *ast.ObjectKey) {
+	rule, value := block.LabelRules[i], label.Token.Value()
+	if rule.Pattern != nil && !rule.Pattern.MatchString(value) {
+		v.errorf(label.Pos(), "Invalid label",
+			"The %s of a %q block must match %s, got: %q.", block.Labels[i], block.Type, rule.Pattern, value)
+		return
+	}
+
+	if len(rule.Values) == 0 {
+		return
+	}
+	for _, allowed := range rule.Values {
+		if value == allowed {
+			return
+		}
+	}
+
+	quoted := make([]string, 0, len(rule.Values))
+	for _, allowed := range rule.Values {
+		quoted = append(quoted, strconv.Quote(allowed))
+	}
+	v.errorf(label.Pos(), "Invalid label",
+		"The %s of a %q block must be one of %s, got: %q.", block.Labels[i], block.Type, strings.Join(quoted, ", "), value)
+}
+
+func (s *Schema) attribute(name string) *Attribute {
+	for _, attr := range s.Attributes {
+		if attr.Name == name {
+			return attr
+		}
+	}
+	return nil
+}
+
+func (s *Schema) block(typ string) *Block {
+	for _, block := range s.Blocks {
+		if block.Type == typ {
+			return block
+		}
+	}
+	return nil
+}
+
+// suggest returns a hint for the expected name closest to the given one
+func (s *Schema) suggest(name string) string {
+	var names []string
+	for _, attr := range s.Attributes {
+		names = append(names, attr.Name)
+	}
+	for _, block := range s.Blocks {
+		names = append(names, block.Type)
+	}
+
+	best, dist := "", 3 // suggest names with at most two edits only
+	for _, n := range names {
+		if d := distance(strings.ToLower(name), strings.ToLower(n)); d < dist {
+			best, dist = n, d
+		}
+	}
+
+	if best == "" {
+		return ""
+	}
+	return fmt.Sprintf(" Did you mean %q?", best)
+}
+
+// distance returns the Levenshtein distance of a and b
+func distance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		cur := make([]int, len(rb)+1)
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(rb)]
+}
+
+func min(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
+
+func labelNames(labels []string) string {
+	switch len(labels) {
+	case 0:
+		return "no labels"
+	case 1:
+		return fmt.Sprintf("1 label (%s)", labels[0])
+	}
+	return fmt.Sprintf("%d labels (%s)", len(labels), strings.Join(labels, ", "))
+}
+
+// typeOf returns the type of a value, strings with interpolations are of
+// any type
+func typeOf(n ast.Node) Type {
+	switch t := n.(type) {
+	case *ast.ObjectType:
+		return Object
+	case *ast.ListType:
+		return List
+	case *ast.LiteralType:
+		switch t.Token.Type {
+		case token.NUMBER, token.FLOAT:
+			return Number
+		case token.UNIT:
+			return Any
+		case token.BOOL:
+			return Bool
+		case token.STRING, token.HEREDOC:
+			if strings.Contains(t.Token.Text, "${") || strings.Contains(t.Token.Text, "%{") {
+				return Any
+			}
+			return String
+		}
+	}
+	return Any
+}
+
+// literal returns the node of a default value
+func literal(v interface{}) (ast.Node, error) {
+	lit := func(typ token.Type, text string) ast.Node {
+		return &ast.LiteralType{Token: token.Token{Type: typ, Text: text}}
+	}
+
+	switch t := v.(type) {
+	case string:
+		return lit(token.STRING, strconv.Quote(t)), nil
+	case int:
+		return lit(token.NUMBER, strconv.Itoa(t)), nil
+	case int64:
+		return lit(token.NUMBER, strconv.FormatInt(t, 10)), nil
+	case float64:
+		return lit(token.FLOAT, strconv.FormatFloat(t, 'f', -1, 64)), nil
+	case bool:
+		return lit(token.BOOL, strconv.FormatBool(t)), nil
+	case []interface{}:
+		list := &ast.ListType{}
+		for _, elem := range t {
+			n, err := literal(elem)
+			if err != nil {
+				return nil, err
+			}
+			list.Add(n)
+		}
+		return list, nil
+	}
+	return nil, fmt.Errorf("unsupported default value of type %T", v)
+}
+
+// items returns the items of list with the given first key
+func items(list *ast.ObjectList, name string) []*ast.ObjectItem {
+	var res []*ast.ObjectItem
+	for _, item := range list.Items {
+		if len(item.Keys) > 0 && item.Keys[0].Token.Value() == name {
+			res = append(res, item)
+		}
+	}
+	return res
+}
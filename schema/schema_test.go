@@ -0,0 +1,297 @@
+package schema
+
+import (
+	"bytes"
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/fatih/hcl/parser"
+	"github.com/fatih/hcl/printer"
+)
+
+var testSchema = &Schema{
+	Attributes: []*Attribute{
+		{Name: "region", Type: String, Required: true},
+		{Name: "debug", Type: Bool, Default: false},
+	},
+	Blocks: []*Block{
+		{
+			Type:   "service",
+			Labels: []string{"name"},
+			Body: &Schema{
+				Attributes: []*Attribute{
+					{Name: "port", Type: Number, Required: true},
+					{Name: "hosts", Type: List, Elem: String, Default: []interface{}{"localhost"}},
+					{Name: "tags", Type: Object, Elem: String},
+				},
+				Blocks: []*Block{
+					{Type: "check"},
+				},
+			},
+		},
+		{Type: "provider", Labels: []string{"type", "name"}},
+	},
+}
+
+func TestValidate(t *testing.T) {
+	src := `region = "eu-west-1"
+debug = "${var.debug}"
+
+service "web" {
+  port = 80
+  hosts = ["a", "b"]
+  tags { env = "prod" }
+
+  check {
+    anything = true
+  }
+}
+
+provider "aws" "main" {}
+`
+	f, err := parser.Parse([]byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diags := testSchema.Validate(f); len(diags) != 0 {
+		t.Errorf("unexpected diagnostics:\n%s", diags)
+	}
+}
+
+func TestValidateDiagnostics(t *testing.T) {
+	src := `region = 1
+region = "x"
+regoin = "y"
+
+service {
+  port = 80
+}
+
+service "web" {
+  hosts = "localhost"
+  check = true
+}
+
+service "db" "extra" {}
+
+provider "aws" "main" {}
+unknown = true
+`
+	f, err := parser.Parse([]byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	diags := testSchema.Validate(f)
+	for _, d := range diags {
+		got = append(got, d.Error())
+	}
+
+	expected := []string{
+		`At 1:10: Incorrect attribute type: The attribute "region" must be of type string, got: number.`,
+		`At 2:1: Duplicate attribute: The attribute "region" was already defined at 1:1.`,
+		`At 3:1: Unsupported argument: An attribute or block named "regoin" is not expected here. Did you mean "region"?`,
+		`At 5:9: Wrong number of labels: A "service" block requires 1 label (name), got: 0.`,
+		`At 10:11: Incorrect attribute type: The attribute "hosts" must be of type list, got: string.`,
+		`At 11:9: Unexpected assignment: "check" is a block, use check { ... } instead.`,
+		`At 9:15: Missing required attribute: The attribute "port" is required, but no definition was found.`,
+		`At 14:14: Wrong number of labels: A "service" block requires 1 label (name), got: 2.`,
+		`At 17:1: Unsupported argument: An attribute or block named "unknown" is not expected here.`,
+	}
+
+	if !reflect.DeepEqual(expected, got) {
+		t.Errorf("\nwant:\n%q\ngot:\n%q", expected, got)
+	}
+
+	if !diags.HasErrors() {
+		t.Error("diagnostics should have errors")
+	}
+}
+
+func TestValidateElements(t *testing.T) {
+	src := `region = "eu"
+
+service "web" {
+  port = 80
+  hosts = ["a", 1, "${var.host}", 2.5]
+  tags = {
+    env = "prod"
+    tier = 2
+  }
+}
+`
+	f, err := parser.Parse([]byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, d := range testSchema.Validate(f) {
+		got = append(got, d.Error())
+	}
+
+	expected := []string{
+		`At 5:17: Incorrect attribute type: The elements of the attribute "hosts" must be of type string, got: number.`,
+		`At 5:35: Incorrect attribute type: The elements of the attribute "hosts" must be of type string, got: number.`,
+		`At 8:12: Incorrect attribute type: The elements of the attribute "tags" must be of type string, got: number.`,
+	}
+	if !reflect.DeepEqual(expected, got) {
+		t.Errorf("\nwant:\n%q\ngot:\n%q", expected, got)
+	}
+}
+
+func TestValidateBlockCounts(t *testing.T) {
+	s := &Schema{
+		Blocks: []*Block{
+			{Type: "server", MinItems: 1, MaxItems: 1},
+			{Type: "tls", MaxItems: 1},
+			{Type: "upstream", Labels: []string{"name"}, MinItems: 2, MaxItems: 3},
+			{Type: "listener", MinItems: 1},
+		},
+	}
+
+	cases := []struct {
+		src   string
+		diags []string
+	}{
+		{"server {}\nupstream \"a\" {}\nupstream \"b\" {}\nlistener {}\nlistener {}", nil},
+		{
+			"server {}\nserver {}\ntls {}\ntls {}\ntls {}\nupstream \"a\" {}\nupstream \"b\" {}\nlistener {}",
+			[]string{
+				`At 2:1: Duplicate block: Only one "server" block is allowed. Another was defined at 1:1.`,
+				`At 4:1: Duplicate block: Only one "tls" block is allowed. Another was defined at 3:1.`,
+				`At 5:1: Duplicate block: Only one "tls" block is allowed. Another was defined at 3:1.`,
+			},
+		},
+		{
+			"upstream \"a\" {}\nupstream \"b\" {}\nupstream \"c\" {}\nupstream \"d\" {}\nserver {}\nlistener {}",
+			[]string{
+				`At 4:1: Too many blocks: At most 3 "upstream" blocks are allowed, the first at 1:1.`,
+			},
+		},
+		{
+			"tls {}\nupstream \"a\" {}",
+			[]string{
+				`At 1:1: Insufficient blocks: At least 1 "server" block is required, but 0 found.`,
+				`At 1:1: Insufficient blocks: At least 2 "upstream" blocks are required, but 1 found.`,
+				`At 1:1: Insufficient blocks: At least 1 "listener" block is required, but 0 found.`,
+			},
+		},
+	}
+
+	for _, c := range cases {
+		f, err := parser.Parse([]byte(c.src))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var got []string
+		for _, d := range s.Validate(f) {
+			got = append(got, d.Error())
+		}
+
+		if !reflect.DeepEqual(c.diags, got) {
+			t.Errorf("%q:\nwant:\n%q\ngot:\n%q", c.src, c.diags, got)
+		}
+	}
+}
+
+func TestValidateLabels(t *testing.T) {
+	s := &Schema{
+		Blocks: []*Block{
+			{
+				Type:       "environment",
+				Labels:     []string{"name"},
+				LabelRules: []*LabelRule{{Pattern: regexp.MustCompile(`^[a-z][a-z0-9-]*$`)}},
+			},
+			{
+				Type:           "resource",
+				Labels:         []string{"type", "name"},
+				OptionalLabels: 1,
+				LabelRules:     []*LabelRule{{Values: []string{"bucket", "queue"}}},
+			},
+		},
+	}
+
+	cases := []struct {
+		src   string
+		diags []string
+	}{
+		{"environment \"prod\" {}\nresource \"bucket\" \"logs\" {}\nresource \"queue\" {}", nil},
+		{
+			"environment \"prod!\" {}",
+			[]string{
+				`At 1:13: Invalid label: The name of a "environment" block must match ^[a-z][a-z0-9-]*$, got: "prod!".`,
+			},
+		},
+		{
+			"resource \"table\" \"users\" {}",
+			[]string{
+				`At 1:10: Invalid label: The type of a "resource" block must be one of "bucket", "queue", got: "table".`,
+			},
+		},
+		{
+			"resource {}\nresource \"queue\" \"a\" \"b\" {}",
+			[]string{
+				`At 1:10: Wrong number of labels: A "resource" block requires at least 1 of 2 labels (type, name), got: 0.`,
+				`At 2:22: Wrong number of labels: A "resource" block requires at least 1 of 2 labels (type, name), got: 3.`,
+			},
+		},
+	}
+
+	for _, c := range cases {
+		f, err := parser.Parse([]byte(c.src))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var got []string
+		for _, d := range s.Validate(f) {
+			got = append(got, d.Error())
+		}
+
+		if !reflect.DeepEqual(c.diags, got) {
+			t.Errorf("%q:\nwant:\n%q\ngot:\n%q", c.src, c.diags, got)
+		}
+	}
+}
+
+func TestApplyDefaults(t *testing.T) {
+	f, err := parser.Parse([]byte(`region = "x"
+service "web" {
+  port = 80
+}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := testSchema.ApplyDefaults(f); err != nil {
+		t.Fatal(err)
+	}
+
+	if diags := testSchema.Validate(f); len(diags) != 0 {
+		t.Errorf("unexpected diagnostics:\n%s", diags)
+	}
+
+	var buf bytes.Buffer
+	if err := printer.Fprint(&buf, f); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `region = "x"
+
+service "web" {
+  port = 80
+
+  hosts = ["localhost"]
+}
+
+debug = false`
+	if buf.String() != expected {
+		t.Errorf("\nwant:\n%s\ngot:\n%s", expected, buf.String())
+	}
+}
@@ -0,0 +1,362 @@
+package schema
+
+import (
+	"fmt"
+	"math/big"
+	"net"
+	"net/url"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// stringTypes are the types package hcl decodes from strings, such as
+// "2026-01-02T15:04:05Z", with the formatting of their defaults. Durations
+// are decoded from numbers too.
+var stringTypes = map[reflect.Type]func(v interface{}) string{
+	reflect.TypeOf(time.Duration(0)): func(v interface{}) string { return v.(time.Duration).String() },
+	reflect.TypeOf(time.Time{}):      func(v interface{}) string { return v.(time.Time).Format(time.RFC3339Nano) },
+	reflect.TypeOf(net.IP{}):         func(v interface{}) string { return v.(net.IP).String() },
+	reflect.TypeOf(url.URL{}):        func(v interface{}) string { u := v.(url.URL); return u.String() },
+}
+
+// bigTypes are the types package hcl decodes numbers into with all their
+// digits
+var bigTypes = map[reflect.Type]bool{
+	reflect.TypeOf(big.Int{}):   true,
+	reflect.TypeOf(big.Float{}): true,
+}
+
+// FromStruct returns the schema of the struct v, or the struct v points to,
+// as decoded by package hcl. The name of an item is given by the "hcl" tag of
+// a field, or the field name otherwise. The option ",required" of the tag
+// marks an attribute as required and the "doc" tag contains its description.
+// Fields with the options ",unknown" and ",unused", collecting unknown
+// blocks and other items, and ",key" and ",decodedFields" are skipped.
+// Fields of a non-zero value in v are used as defaults.
+//
+// Fields of struct types, pointers, slices and maps of them are blocks. Each
+// level of maps with string keys adds a label to the block, otherwise a
+// field with the option ",key" adds an optional one. Fields with a
+// "parse" tag are string attributes, or lists of strings for slices, without
+// defaults.
+func FromStruct(v interface{}) (*Schema, error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			rv = reflect.Zero(rv.Type().Elem())
+			break
+		}
+		rv = rv.Elem()
+	}
+
+	if rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("expected a struct, got: %T", v)
+	}
+	return fromStruct(rv), nil
+}
+
+func fromStruct(rv reflect.Value) *Schema {
+	s := &Schema{}
+	t := rv.Type()
+
+	// the items of promoted fields, which the fields of t hide
+	promoted := make(map[interface{}]bool)
+	defined := make(map[string]bool)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if st := squashed(field); st != nil && st != t {
+			fv := rv.Field(i)
+			if fv.Kind() == reflect.Ptr {
+				if fv.IsNil() {
+					fv = reflect.Zero(st)
+				} else {
+					fv = fv.Elem()
+				}
+			}
+
+			sub := fromStruct(fv)
+			for _, attr := range sub.Attributes {
+				promoted[attr] = true
+				s.Attributes = append(s.Attributes, attr)
+			}
+			for _, block := range sub.Blocks {
+				promoted[block] = true
+				s.Blocks = append(s.Blocks, block)
+			}
+			continue
+		}
+
+		if field.PkgPath != "" || unsupported(field.Type) {
+			continue // never decoded
+		}
+
+		tag := strings.Split(field.Tag.Get("hcl"), ",")
+		name := tag[0]
+		if name == "-" || hasOption(tag, "unknown") || hasOption(tag, "unused") ||
+			hasOption(tag, "key") || hasOption(tag, "decodedFields") {
+			continue // not decoded from an item of its own
+		}
+		if name == "" {
+			name = field.Name
+		}
+		defined[name] = true
+
+		// parsed fields are strings, whatever the type they're parsed into
+		if field.Tag.Get("parse") != "" {
+			typ, elem := String, Any
+			if t := field.Type; t.Kind() == reflect.Slice || t.Kind() == reflect.Ptr && t.Elem().Kind() == reflect.Slice {
+				typ, elem = List, String
+			}
+
+			s.Attributes = append(s.Attributes, &Attribute{
+				Name:        name,
+				Type:        typ,
+				Elem:        elem,
+				Description: field.Tag.Get("doc"),
+				Required:    hasOption(tag, "required"),
+			})
+			continue
+		}
+
+		if body, labels, optional, ok := blockType(field.Type); ok {
+			s.Blocks = append(s.Blocks, &Block{
+				Type:           name,
+				Labels:         labels,
+				OptionalLabels: optional,
+				Description:    field.Tag.Get("doc"),
+				Body:           body,
+			})
+			continue
+		}
+
+		attr := &Attribute{
+			Name:        name,
+			Type:        kindType(field.Type),
+			Elem:        elemType(field.Type),
+			Description: field.Tag.Get("doc"),
+		}
+
+		attr.Required = hasOption(tag, "required")
+
+		if fv := rv.Field(i); !isZero(fv) {
+			attr.Default = defaultValue(fv)
+		}
+
+		s.Attributes = append(s.Attributes, attr)
+	}
+
+	if len(promoted) == 0 {
+		return s
+	}
+
+	// like in Go, the first promoted item of a name wins
+	var attrs []*Attribute
+	for _, attr := range s.Attributes {
+		if !promoted[attr] || !defined[attr.Name] {
+			defined[attr.Name] = true
+			attrs = append(attrs, attr)
+		}
+	}
+	var blocks []*Block
+	for _, block := range s.Blocks {
+		if !promoted[block] || !defined[block.Type] {
+			defined[block.Type] = true
+			blocks = append(blocks, block)
+		}
+	}
+	s.Attributes, s.Blocks = attrs, blocks
+	return s
+}
+
+// squashed returns the struct type whose fields package hcl decodes in place
+// of field: embedded structs without a name in their tag and structs with the
+// tag option ",squash". It returns nil for other fields.
+func squashed(field reflect.StructField) reflect.Type {
+	tag := strings.Split(field.Tag.Get("hcl"), ",")
+	if !hasOption(tag, "squash") && (!field.Anonymous || tag[0] != "") {
+		return nil
+	}
+
+	t := field.Type
+	if t.Kind() == reflect.Ptr {
+		if field.PkgPath != "" {
+			return nil
+		}
+		t = t.Elem()
+	}
+
+	if t.Kind() != reflect.Struct || stringTypes[t] != nil || bigTypes[t] {
+		return nil
+	}
+	return t
+}
+
+// unsupported reports whether a field of type t is never decoded, as it's a
+// func, chan or unsafe pointer, or a pointer, slice or map of them
+func unsupported(t reflect.Type) bool {
+	seen := make(map[reflect.Type]bool)
+	for !seen[t] && (t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Map) {
+		seen[t] = true
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Func, reflect.Chan, reflect.UnsafePointer:
+		return true
+	}
+	return false
+}
+
+// hasOption reports whether the options of the split tag contain opt
+func hasOption(tag []string, opt string) bool {
+	for _, o := range tag[1:] {
+		if o == opt {
+			return true
+		}
+	}
+	return false
+}
+
+// blockType reports whether a field of type t is decoded from blocks and
+// returns the schema of their body, the names of their labels and the number
+// of optional ones.
+func blockType(t reflect.Type) (*Schema, []string, int, bool) {
+	var labels []string
+	for {
+		switch t.Kind() {
+		case reflect.Ptr, reflect.Slice:
+			if stringTypes[t] != nil {
+				return nil, nil, 0, false
+			}
+			t = t.Elem()
+			continue
+		case reflect.Map:
+			if t.Key().Kind() != reflect.String {
+				return nil, nil, 0, false
+			}
+			labels = append(labels, fmt.Sprintf("label%d", len(labels)+1))
+			t = t.Elem()
+			continue
+		case reflect.Struct:
+			if stringTypes[t] != nil || bigTypes[t] {
+				return nil, nil, 0, false
+			}
+			if len(labels) == 1 {
+				labels[0] = "name"
+			}
+
+			// the key field holds the label of a block outside of maps
+			optional := 0
+			if key, ok := keyField(t); ok && len(labels) == 0 {
+				labels, optional = []string{strings.ToLower(key.Name)}, 1
+			}
+			return fromStruct(reflect.Zero(t)), labels, optional, true
+		}
+		return nil, nil, 0, false
+	}
+}
+
+// keyField returns the field of the struct type t with the option ",key"
+func keyField(t reflect.Type) (reflect.StructField, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if hasOption(strings.Split(field.Tag.Get("hcl"), ","), "key") {
+			return field, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+func kindType(t reflect.Type) Type {
+	if t == reflect.TypeOf(time.Duration(0)) {
+		return Any
+	}
+	if stringTypes[t] != nil {
+		return String
+	}
+	if bigTypes[t] {
+		return Number
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		return kindType(t.Elem())
+	case reflect.String:
+		return String
+	case reflect.Bool:
+		return Bool
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return Number
+	case reflect.Slice, reflect.Array:
+		return List
+	case reflect.Map:
+		return Object
+	}
+	return Any
+}
+
+// elemType returns the type of the elements of lists and the values of
+// objects decoded into t, Any for other types
+func elemType(t reflect.Type) Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch kindType(t) {
+	case List, Object:
+		return kindType(t.Elem())
+	}
+	return Any
+}
+
+func isZero(rv reflect.Value) bool {
+	return reflect.DeepEqual(rv.Interface(), reflect.Zero(rv.Type()).Interface())
+}
+
+// defaultValue converts a field value into the types supported as Default
+func defaultValue(rv reflect.Value) interface{} {
+	if format := stringTypes[rv.Type()]; format != nil {
+		return format(rv.Interface())
+	}
+
+	if bigTypes[rv.Type()] {
+		p := reflect.New(rv.Type())
+		p.Elem().Set(rv)
+		switch v := p.Interface().(type) {
+		case *big.Int:
+			if v.IsInt64() {
+				return v.Int64()
+			}
+			f, _ := new(big.Float).SetInt(v).Float64()
+			return f
+		case *big.Float:
+			f, _ := v.Float64()
+			return f
+		}
+	}
+
+	switch rv.Kind() {
+	case reflect.Ptr:
+		return defaultValue(rv.Elem())
+	case reflect.String:
+		return rv.String()
+	case reflect.Bool:
+		return rv.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int64(rv.Uint())
+	case reflect.Float32, reflect.Float64:
+		return rv.Float()
+	case reflect.Slice, reflect.Array:
+		list := make([]interface{}, 0, rv.Len())
+		for i := 0; i < rv.Len(); i++ {
+			list = append(list, defaultValue(rv.Index(i)))
+		}
+		return list
+	}
+	return rv.Interface()
+}
@@ -0,0 +1,214 @@
+// Package secrets detects credentials in HCL (HashiCorp Configuration
+// Language) files, such as passwords and API tokens, and prints files with
+// their values masked. It's meant for pre-commit hooks and log output.
+package secrets
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strings"
+
+	"github.com/fatih/hcl/ast"
+	"github.com/fatih/hcl/printer"
+	"github.com/fatih/hcl/token"
+)
+
+// DefaultConfig is the configuration used by Find and Fprint.
+var DefaultConfig = Config{
+	Keys:          []string{"password", "passwd", "secret", "token", "api_key", "apikey", "private_key", "credential"},
+	MinLength:     20,
+	Base64Entropy: 4.0,
+	HexEntropy:    3.0,
+}
+
+// Config controls which values are reported as secrets.
+type Config struct {
+	// Keys are the parts of keys whose values are secrets, compared case
+	// insensitively, i.e. "password" matches "db_password".
+	Keys []string
+
+	// MinLength is the minimum length of strings checked for their
+	// entropy.
+	MinLength int
+
+	// Base64Entropy and HexEntropy are the minimum Shannon entropies in bits
+	// per character of base64 and hex strings to be reported as secrets.
+	Base64Entropy float64
+	HexEntropy    float64
+}
+
+// Reason is the reason a value is reported as a secret.
+type Reason int
+
+const (
+	SecretKey   Reason = iota // the key is named like a secret
+	HighEntropy               // the value looks randomly generated
+)
+
+func (r Reason) String() string {
+	switch r {
+	case SecretKey:
+		return "secret key"
+	case HighEntropy:
+		return "high entropy string"
+	}
+	return "unknown"
+}
+
+// Finding is a value reported as a secret.
+type Finding struct {
+	Pos    token.Pos
+	Key    string // keys of the enclosing items, see ast.JoinPath
+	Reason Reason
+	Lit    *ast.LiteralType
+}
+
+func (f Finding) String() string {
+	return fmt.Sprintf("%s: %s: %s", f.Pos, f.Key, f.Reason)
+}
+
+// Find returns the string values of n which are secrets, in order of their
+// position. Strings containing interpolations are not reported, as they
+// refer to values defined elsewhere.
+func (c *Config) Find(n ast.Node) []Finding {
+	var findings []Finding
+	var walk func(n ast.Node, path []string, secret bool)
+	walk = func(n ast.Node, path []string, secret bool) {
+		switch t := n.(type) {
+		case *ast.File:
+			walk(t.Node, path, secret)
+		case *ast.ObjectList:
+			for _, item := range t.Items {
+				p := path[:len(path):len(path)]
+				s := secret
+				for _, k := range item.Keys {
+					key := k.Token.Value()
+					p = append(p, key)
+					s = s || c.secretKey(key)
+				}
+				walk(item.Val, p, s)
+			}
+		case *ast.ObjectType:
+			walk(t.List, path, secret)
+		case *ast.ListType:
+			for _, elem := range t.List {
+				walk(elem, path, secret)
+			}
+		case *ast.LiteralType:
+			var s string
+			switch t.Token.Type {
+			case token.STRING:
+				s = t.Token.Value()
+			case token.HEREDOC:
+				s = t.Token.HeredocValue()
+			default:
+				return
+			}
+
+			if s == "" || strings.Contains(s, "${") || strings.Contains(s, "%{") {
+				return
+			}
+
+			f := Finding{Pos: t.Pos(), Key: ast.JoinPath(path), Lit: t}
+			switch {
+			case secret:
+				f.Reason = SecretKey
+			case c.highEntropy(s):
+				f.Reason = HighEntropy
+			default:
+				return
+			}
+			findings = append(findings, f)
+		}
+	}
+
+	walk(n, nil, false)
+	return findings
+}
+
+// Fprint prints n to w like printer.Fprint, with the values of all secrets
+// replaced by printer.Masked.
+func (c *Config) Fprint(w io.Writer, n ast.Node) error {
+	masked := make(map[*ast.LiteralType]bool)
+	for _, f := range c.Find(n) {
+		masked[f.Lit] = true
+	}
+
+	cfg := printer.DefaultConfig
+	cfg.Mask = func(lit *ast.LiteralType) bool {
+		return masked[lit]
+	}
+	return cfg.Fprint(w, n)
+}
+
+// Find returns the secrets of n. It calls Config.Find with the default
+// settings.
+func Find(n ast.Node) []Finding {
+	return DefaultConfig.Find(n)
+}
+
+// Fprint prints n to w with the values of all secrets masked. It calls
+// Config.Fprint with the default settings.
+func Fprint(w io.Writer, n ast.Node) error {
+	return DefaultConfig.Fprint(w, n)
+}
+
+func (c *Config) secretKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, k := range c.Keys {
+		if strings.Contains(key, strings.ToLower(k)) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Config) highEntropy(s string) bool {
+	if len(s) < c.MinLength {
+		return false
+	}
+
+	switch {
+	case isCharset(s, hexChars):
+		return Entropy(s) >= c.HexEntropy
+	case isCharset(s, base64Chars):
+		return Entropy(s) >= c.Base64Entropy
+	}
+	return false
+}
+
+const (
+	hexChars    = "0123456789abcdefABCDEF"
+	base64Chars = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+/=_-"
+)
+
+func isCharset(s, chars string) bool {
+	for _, ch := range s {
+		if !strings.ContainsRune(chars, ch) {
+			return false
+		}
+	}
+	return true
+}
+
+// Entropy returns the Shannon entropy of s in bits per character.
+func Entropy(s string) float64 {
+	if s == "" {
+		return 0
+	}
+
+	counts := make(map[rune]int)
+	var n int
+	for _, ch := range s {
+		counts[ch]++
+		n++
+	}
+
+	var e float64
+	for _, c := range counts {
+		p := float64(c) / float64(n)
+		e -= p * math.Log2(p)
+	}
+	return e
+}
@@ -0,0 +1,92 @@
+package secrets
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/fatih/hcl/parser"
+)
+
+const src = `provider "aws" {
+  region     = "us-east-1"
+  secret_key = "hunter2"
+  password   = "${var.password}"
+}
+
+database {
+  credentials {
+    user = "admin"
+  }
+}
+
+tokens = ["a", ""]
+port   = 8080
+id     = "ami-0c55b159cbfafe1f0"
+key    = "wJalrXUtnFEMI/K7MDENG/bPxRfiCYEXAMPLEKEY"
+sha    = "9a0364b9e99bb480dd25e1f0284c8555"
+`
+
+func TestFind(t *testing.T) {
+	f, err := parser.Parse([]byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, finding := range Find(f) {
+		got = append(got, finding.String())
+	}
+
+	expected := []string{
+		"3:16: provider.aws.secret_key: secret key",
+		"9:12: database.credentials.user: secret key",
+		"13:11: tokens: secret key",
+		"16:10: key: high entropy string",
+		"17:10: sha: high entropy string",
+	}
+
+	if !reflect.DeepEqual(expected, got) {
+		t.Errorf("\nwant: %q\ngot:  %q", expected, got)
+	}
+}
+
+func TestFprint(t *testing.T) {
+	f, err := parser.Parse([]byte(`user     = "admin"
+password = "hunter2"
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := Fprint(&buf, f); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `user = "admin"
+
+password = "********"`
+
+	if buf.String() != expected {
+		t.Errorf("\nwant: %q\ngot:  %q", expected, buf.String())
+	}
+}
+
+func TestEntropy(t *testing.T) {
+	cases := []struct {
+		Input    string
+		Expected float64
+	}{
+		{"", 0},
+		{"aaaa", 0},
+		{"abab", 1},
+		{"abcd", 2},
+	}
+
+	for _, c := range cases {
+		if got := Entropy(c.Input); got != c.Expected {
+			t.Errorf("%q: want: %v, got: %v", c.Input, c.Expected, got)
+		}
+	}
+}
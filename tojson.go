@@ -0,0 +1,152 @@
+package hcl
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/fatih/hcl/ast"
+)
+
+// ToJSON converts the syntax tree n into the equivalent JSON, i.e. for tools
+// in other languages. Literals are converted like DecodeObject converts them
+// into an empty interface, including the Resolver of c for strings with
+// interpolations.
+//
+// The labels of blocks become nested objects, so blocks of the same type with
+// different labels are merged into one object. Items whose keys are
+// repeated become arrays of their values, and repeated lists are
+// concatenated, like Decode does for values decoded into an empty interface:
+//
+//	service "web" { port = 80 }
+//	service "db" { port = 5432 }
+//	rule { allow = true }
+//	rule { allow = false }
+//
+// converts into
+//
+//	{
+//	  "rule": [{"allow": true}, {"allow": false}],
+//	  "service": {"db": {"port": 5432}, "web": {"port": 80}}
+//	}
+//
+// Objects are written with sorted keys and indented by two spaces.
+func (c *DecoderConfig) ToJSON(n ast.Node) ([]byte, error) {
+	j := &jsonConverter{decoder{config: c}}
+	v, err := j.value("root", n)
+	if err != nil {
+		return nil, err
+	}
+	return json.MarshalIndent(v, "", "  ")
+}
+
+// ToJSON converts n into JSON with DefaultDecoderConfig.
+func ToJSON(n ast.Node) ([]byte, error) {
+	return DefaultDecoderConfig.ToJSON(n)
+}
+
+// labeled is the object of the labels of blocks, whose blocks are merged
+type labeled map[string]interface{}
+
+// repeated are the values of the items with the same keys
+type repeated []interface{}
+
+type jsonConverter struct {
+	decoder
+}
+
+func (j *jsonConverter) value(name string, n ast.Node) (interface{}, error) {
+	switch t := n.(type) {
+	case *ast.File:
+		return j.value(name, t.Node)
+	case *ast.ObjectType:
+		if t.List == nil {
+			return map[string]interface{}{}, nil
+		}
+		return j.value(name, t.List)
+	case *ast.ObjectList:
+		m := make(map[string]interface{})
+		for _, item := range t.Items {
+			if len(item.Keys) == 0 {
+				continue
+			}
+
+			keys := make([]string, len(item.Keys))
+			for i, k := range item.Keys {
+				keys[i] = k.Token.Value()
+			}
+
+			v, err := j.value(name+"."+ast.JoinPath(keys), item.Val)
+			if err != nil {
+				return nil, err
+			}
+			add(m, keys, v)
+		}
+		return m, nil
+	case *ast.ListType:
+		list := make([]interface{}, 0, len(t.List))
+		for i, elem := range t.List {
+			v, err := j.value(fmt.Sprintf("%s[%d]", name, i), elem)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, v)
+		}
+		return list, nil
+	case *ast.LiteralType:
+		return j.literal(t)
+	}
+
+	return nil, posErrorf(n.Pos(), "%s: cannot convert %s into JSON", name, nodeName(n))
+}
+
+// add adds the value v of an item with the given keys to the object m. The
+// keys after the first one are labels.
+func add(m map[string]interface{}, keys []string, v interface{}) {
+	k := keys[0]
+	if len(keys) == 1 {
+		addValue(m, k, v)
+		return
+	}
+
+	labels, ok := lastLabels(m[k])
+	if !ok {
+		labels = make(labeled)
+		addValue(m, k, labels)
+	}
+	add(labels, keys[1:], v)
+}
+
+// addValue adds the value v of the key k to m, appending it to the values
+// of k if it's repeated
+func addValue(m map[string]interface{}, k string, v interface{}) {
+	existing, ok := m[k]
+	if !ok {
+		m[k] = v
+		return
+	}
+
+	switch t := existing.(type) {
+	case repeated:
+		m[k] = append(t, v)
+	case []interface{}:
+		if list, ok := v.([]interface{}); ok {
+			m[k] = append(t, list...)
+			return
+		}
+		m[k] = repeated{t, v}
+	default:
+		m[k] = repeated{t, v}
+	}
+}
+
+// lastLabels returns the last object of labels of the value v of a key
+func lastLabels(v interface{}) (labeled, bool) {
+	switch t := v.(type) {
+	case labeled:
+		return t, true
+	case repeated:
+		l, ok := t[len(t)-1].(labeled)
+		return l, ok
+	}
+	return nil, false
+}
@@ -0,0 +1,147 @@
+package hcl
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/fatih/hcl/ast"
+	"github.com/fatih/hcl/parser"
+)
+
+func TestToJSON(t *testing.T) {
+	cases := []struct {
+		src  string
+		json string
+	}{
+		{``, `{}`},
+		{`a = "b" c = 1 d = 1.5 e = true`, `{"a": "b", "c": 1, "d": 1.5, "e": true}`},
+		{`a = [1, "x"]`, `{"a": [1, "x"]}`},
+		{`a = [1] a = [2, 3]`, `{"a": [1, 2, 3]}`},
+		{`a = 1 a = 2`, `{"a": [1, 2]}`},
+		{`tags = { env = "prod" }`, `{"tags": {"env": "prod"}}`},
+		{`empty {}`, `{"empty": {}}`},
+		{
+			`service "web" { port = 80 } service "db" { port = 5432 }`,
+			`{"service": {"web": {"port": 80}, "db": {"port": 5432}}}`,
+		},
+		{
+			`rule { allow = true } rule { allow = false } rule { allow = true }`,
+			`{"rule": [{"allow": true}, {"allow": false}, {"allow": true}]}`,
+		},
+		{
+			`resource "aws_instance" "web" { ami = "x" } resource "aws_instance" "db" {} resource "s3" "logs" {}`,
+			`{"resource": {"aws_instance": {"web": {"ami": "x"}, "db": {}}, "s3": {"logs": {}}}}`,
+		},
+		{
+			`service "web" { port = 80 } service "web" { port = 81 }`,
+			`{"service": {"web": [{"port": 80}, {"port": 81}]}}`,
+		},
+		{
+			`limits = { cpu = 1 } limits "mem" { max = 2 } limits "disk" {}`,
+			`{"limits": [{"cpu": 1}, {"mem": {"max": 2}, "disk": {}}]}`,
+		},
+		{`"app.io/name" = "x"`, `{"app.io/name": "x"}`},
+		{`a = "${var.name}"`, `{"a": "${var.name}"}`},
+		{`a = null b = [1, null]`, `{"a": null, "b": [1, null]}`},
+	}
+
+	// the experimental syntax has null literals
+	dc := &DecoderConfig{Syntax: parser.Experimental}
+	for _, c := range cases {
+		f, err := (&parser.Config{Syntax: dc.Syntax}).Parse([]byte(c.src))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		res, err := dc.ToJSON(f)
+		if err != nil {
+			t.Errorf("%s: %s", c.src, err)
+			continue
+		}
+
+		var got, want interface{}
+		if err := json.Unmarshal(res, &got); err != nil {
+			t.Fatalf("%s: invalid JSON: %s\n%s", c.src, err, res)
+		}
+		if err := json.Unmarshal([]byte(c.json), &want); err != nil {
+			t.Fatal(err)
+		}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s:\nwant: %s\ngot:  %s", c.src, c.json, res)
+		}
+
+		// Decode agrees with ToJSON on repeated items
+		var decoded interface{}
+		if err := dc.Decode(&decoded, c.src); err != nil {
+			t.Errorf("%s: %s", c.src, err)
+			continue
+		}
+		b, err := json.Marshal(decoded)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = nil
+		if err := json.Unmarshal(b, &got); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: decoded:\nwant: %s\ngot:  %s", c.src, c.json, b)
+		}
+	}
+}
+
+func TestToJSONRoundTrip(t *testing.T) {
+	src := `name = "app"
+service "web" {
+  port = 80
+  tags = ["a", "b"]
+}
+service "db" {
+  port = 5432
+}
+`
+
+	f, err := parser.Parse([]byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := ToJSON(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the JSON parses into the same value as the HCL
+	var want, got interface{}
+	if err := Decode(&want, src); err != nil {
+		t.Fatal(err)
+	}
+	if err := Decode(&got, string(res)); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("want %#v, got %#v", want, got)
+	}
+}
+
+func TestDecoderConfigToJSON(t *testing.T) {
+	dc := &DecoderConfig{Resolver: func(t *ast.Template) (interface{}, error) {
+		return "resolved", nil
+	}}
+
+	f, err := parser.Parse([]byte(`a = "${var.name}"`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := dc.ToJSON(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "{\n  \"a\": \"resolved\"\n}"; string(res) != want {
+		t.Errorf("want %s, got %s", want, res)
+	}
+}
@@ -0,0 +1,81 @@
+package token
+
+import (
+	"strconv"
+	"strings"
+)
+
+// HeredocBody returns the body of a HEREDOC token, such as "<<EOF\nhi\nEOF":
+// the lines between the marker lines, each with its newline, and the
+// position of the first one. indent is the indentation an indented heredoc,
+// <<-EOF, removes from every line, the smallest one of its lines which
+// aren't blank. The body of other tokens is empty.
+func (t Token) HeredocBody() (body string, pos Pos, indent int) {
+	text := t.Text
+	start := strings.IndexByte(text, '\n')
+	end := strings.LastIndexByte(text, '\n')
+	if t.Type != HEREDOC || !strings.HasPrefix(text, "<<") || start < 0 {
+		return "", t.Pos, 0
+	}
+
+	body = text[start+1 : end+1]
+	pos = Pos{Filename: t.Pos.Filename, Offset: t.Pos.Offset + start + 1, Line: t.Pos.Line + 1, Column: 1}
+	if !strings.HasPrefix(text, "<<-") {
+		return body, pos, 0
+	}
+
+	indent = -1
+	for _, line := range strings.SplitAfter(body, "\n") {
+		trimmed := strings.TrimLeft(line, " \t")
+		if strings.TrimSpace(trimmed) == "" {
+			continue
+		}
+		if n := len(line) - len(trimmed); indent < 0 || n < indent {
+			indent = n
+		}
+	}
+	if indent < 0 {
+		indent = 0
+	}
+	return body, pos, indent
+}
+
+// HeredocValue returns the string a HEREDOC token stands for, its body
+// without the indentation of an indented heredoc.
+func (t Token) HeredocValue() string {
+	body, _, indent := t.HeredocBody()
+	return unindent(body, indent)
+}
+
+// Value returns the string the token stands for: the unquoted text of a
+// STRING, the value of a HEREDOC and the text of other tokens, such as the
+// IDENT or UNIT of a key. Strings which can't be unquoted are returned as
+// they are.
+func (t Token) Value() string {
+	switch t.Type {
+	case STRING:
+		if s, err := strconv.Unquote(t.Text); err == nil {
+			return s
+		}
+	case HEREDOC:
+		return t.HeredocValue()
+	}
+	return t.Text
+}
+
+// unindent removes up to n spaces and tabs from the start of every line of s
+func unindent(s string, n int) string {
+	if n == 0 {
+		return s
+	}
+
+	lines := strings.SplitAfter(s, "\n")
+	for i, line := range lines {
+		j := 0
+		for j < n && j < len(line) && (line[j] == ' ' || line[j] == '\t') {
+			j++
+		}
+		lines[i] = line[j:]
+	}
+	return strings.Join(lines, "")
+}
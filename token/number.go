@@ -0,0 +1,88 @@
+package token
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// maxExponent is the largest exponent of an integer, i.e. 1e1000, so that
+// numbers like 1e999999999 don't take all memory
+const maxExponent = 1000
+
+// BigInt returns the integer a NUMBER or FLOAT token stands for, with all
+// its digits. Numbers are decimal, hexadecimal like 0x1f, octal like 0o17 or
+// 017, or have an exponent like 1e6. Numbers with a fraction are an error.
+func (t Token) BigInt() (*big.Int, error) {
+	exp, ok := exponent(t.Text)
+	if t.Type == FLOAT || t.Type == NUMBER && ok {
+		if exp > maxExponent {
+			return nil, fmt.Errorf("exponent of %s is too large", t.Text)
+		}
+
+		f, err := t.BigFloat()
+		if err != nil {
+			return nil, err
+		}
+		if !f.IsInt() {
+			return nil, fmt.Errorf("%s is not an integer", t.Text)
+		}
+		i, _ := f.Int(nil)
+		return i, nil
+	}
+
+	i, ok := new(big.Int).SetString(t.Text, 0)
+	if t.Type != NUMBER || !ok {
+		return nil, fmt.Errorf("invalid number %s", t.Text)
+	}
+	return i, nil
+}
+
+// BigFloat returns the number a NUMBER or FLOAT token stands for. Integers
+// up to an exponent of 1000 are exact, the precision of other numbers is
+// large enough for all digits of the token and at least the one of a
+// float64.
+func (t Token) BigFloat() (*big.Float, error) {
+	exp, ok := exponent(t.Text)
+	if t.Type == NUMBER && !ok {
+		i, err := t.BigInt()
+		if err != nil {
+			return nil, err
+		}
+		return new(big.Float).SetInt(i), nil
+	}
+
+	// a decimal digit takes less than four bits
+	prec := uint(len(t.Text)) * 4
+	if exp > 0 && exp <= maxExponent {
+		prec += uint(exp) * 4
+	}
+	if prec < 64 {
+		prec = 64
+	}
+
+	f, _, err := big.ParseFloat(t.Text, 10, prec, big.ToNearestEven)
+	if (t.Type != NUMBER && t.Type != FLOAT) || err != nil {
+		return nil, fmt.Errorf("invalid number %s", t.Text)
+	}
+	return f, nil
+}
+
+// exponent returns the exponent of the decimal number text and whether it
+// has one
+func exponent(text string) (int, bool) {
+	i := strings.IndexAny(text, "eE")
+	if i < 0 || strings.ContainsAny(text, "xX") {
+		return 0, false
+	}
+
+	exp, err := strconv.Atoi(text[i+1:])
+	if e, ok := err.(*strconv.NumError); ok && e.Err == strconv.ErrRange {
+		exp = maxExponent + 1
+		if text[i+1] == '-' {
+			exp = -exp
+		}
+	}
+	return exp, true
+}
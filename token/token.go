@@ -5,6 +5,8 @@ package token
 import (
 	"fmt"
 	"strconv"
+	"strings"
+	"unicode"
 )
 
 // Token defines a single HCL token which can be obtained via the Scanner
@@ -26,26 +28,55 @@ const (
 	identifier_beg
 	IDENT // literals
 	literal_beg
-	NUMBER // 12345
-	FLOAT  // 123.45
-	BOOL   // true,false
-	STRING // "abc"
+	NUMBER  // 12345
+	FLOAT   // 123.45
+	BOOL    // true,false
+	NULL    // null
+	STRING  // "abc"
+	UNIT    // 10s, 512k, 80%
+	HEREDOC // <<EOF ... EOF
 	literal_end
 	identifier_end
 
 	operator_beg
 	LBRACK // [
 	LBRACE // {
+	LPAREN // (
 	COMMA  // ,
 	PERIOD // .
 
 	RBRACK // ]
 	RBRACE // }
+	RPAREN // )
 
 	ASSIGN // =
 	ADD    // +
 	SUB    // -
+	MUL    // *
+	QUO    // /
+	MOD    // %
+
+	EQL // ==
+	NEQ // !=
+	LSS // <
+	GTR // >
+	LEQ // <=
+	GEQ // >=
+
+	AND // &&
+	OR  // ||
+	NOT // !
+
+	QUESTION // ?
+	COLON    // :
 	operator_end
+
+	// Parts of strings split at their interpolations
+	TEMPLATE_TEXT   // "a ${
+	TEMPLATE_INTERP // ${b}
+
+	// White space between tokens, which only hcl.Tokens returns
+	WHITESPACE
 )
 
 var tokens = [...]string{
@@ -54,23 +85,50 @@ var tokens = [...]string{
 	EOF:     "EOF",
 	COMMENT: "COMMENT",
 
-	IDENT:  "IDENT",
-	NUMBER: "NUMBER",
-	FLOAT:  "FLOAT",
-	BOOL:   "BOOL",
-	STRING: "STRING",
+	IDENT:   "IDENT",
+	NUMBER:  "NUMBER",
+	FLOAT:   "FLOAT",
+	BOOL:    "BOOL",
+	NULL:    "NULL",
+	STRING:  "STRING",
+	UNIT:    "UNIT",
+	HEREDOC: "HEREDOC",
 
 	LBRACK: "LBRACK",
 	LBRACE: "LBRACE",
+	LPAREN: "LPAREN",
 	COMMA:  "COMMA",
 	PERIOD: "PERIOD",
 
 	RBRACK: "RBRACK",
 	RBRACE: "RBRACE",
+	RPAREN: "RPAREN",
 
 	ASSIGN: "ASSIGN",
 	ADD:    "ADD",
 	SUB:    "SUB",
+	MUL:    "MUL",
+	QUO:    "QUO",
+	MOD:    "MOD",
+
+	EQL: "EQL",
+	NEQ: "NEQ",
+	LSS: "LSS",
+	GTR: "GTR",
+	LEQ: "LEQ",
+	GEQ: "GEQ",
+
+	AND: "AND",
+	OR:  "OR",
+	NOT: "NOT",
+
+	QUESTION: "QUESTION",
+	COLON:    "COLON",
+
+	TEMPLATE_TEXT:   "TEMPLATE_TEXT",
+	TEMPLATE_INTERP: "TEMPLATE_INTERP",
+
+	WHITESPACE: "WHITESPACE",
 }
 
 // String returns the string corresponding to the token tok.
@@ -85,6 +143,43 @@ func (t Type) String() string {
 	return s
 }
 
+// GoString returns the Go syntax of the type, i.e. token.IDENT, for the %#v
+// verb of package fmt.
+func (t Type) GoString() string {
+	if 0 <= t && t < Type(len(tokens)) && tokens[t] != "" {
+		return "token." + tokens[t]
+	}
+	return "token.Type(" + strconv.Itoa(int(t)) + ")"
+}
+
+// Lookup returns the type of the identifier ident: BOOL for true and false,
+// NULL for null and IDENT otherwise.
+func Lookup(ident string) Type {
+	switch ident {
+	case "true", "false":
+		return BOOL
+	case "null":
+		return NULL
+	}
+	return IDENT
+}
+
+// IsIdent reports whether s can be written as an unquoted key, an identifier
+// other than true and false.
+func IsIdent(s string) bool {
+	if s == "" || s == "true" || s == "false" {
+		return false
+	}
+
+	for i, ch := range s {
+		if ch == '_' || unicode.IsLetter(ch) || i > 0 && unicode.IsDigit(ch) {
+			continue
+		}
+		return false
+	}
+	return true
+}
+
 // IsIdentifier returns true for tokens corresponding to identifiers and basic
 // type literals; it returns false otherwise.
 func (t Type) IsIdentifier() bool { return identifier_beg < t && t < identifier_end }
@@ -97,9 +192,93 @@ func (t Type) IsLiteral() bool { return literal_beg < t && t < literal_end }
 // delimiters; it returns false otherwise.
 func (t Type) IsOperator() bool { return operator_beg < t && t < operator_end }
 
+// IsKeyword returns true for the tokens of the keywords true, false and null;
+// it returns false otherwise.
+func (t Type) IsKeyword() bool { return t == BOOL || t == NULL }
+
+// IsTemplate returns true for the parts of strings split at their
+// interpolations; it returns false otherwise.
+func (t Type) IsTemplate() bool { return t == TEMPLATE_TEXT || t == TEMPLATE_INTERP }
+
+// A set of constants for precedence-based expression parsing. Non-operators
+// have lowest precedence, followed by operators starting with precedence 1 up
+// to unary operators.
+const (
+	LowestPrec  = 0 // non-operators
+	UnaryPrec   = 6
+	HighestPrec = 7
+)
+
+// Precedence returns the operator precedence of the binary operator t. If t
+// is not a binary operator, the result is LowestPrec.
+func (t Type) Precedence() int {
+	switch t {
+	case OR:
+		return 1
+	case AND:
+		return 2
+	case EQL, NEQ, LSS, LEQ, GTR, GEQ:
+		return 3
+	case ADD, SUB:
+		return 4
+	case MUL, QUO, MOD:
+		return 5
+	}
+	return LowestPrec
+}
+
 // String returns the token's literal text. Note that this is only
 // applicable for certain token types, such as token.IDENT,
 // token.STRING, etc..
 func (t Token) String() string {
 	return fmt.Sprintf("%s %s %s", t.Pos.String(), t.Type.String(), t.Text)
 }
+
+// GoString returns the Go syntax of the token, i.e. to print tokens in
+// tests with the %#v verb of package fmt.
+func (t Token) GoString() string {
+	return fmt.Sprintf("token.Token{Type: %#v, Pos: %#v, Text: %q}", t.Type, t.Pos, t.Text)
+}
+
+// End returns the position of the character immediately after the token,
+// the end of its source range. Tokens spanning several lines, such as
+// heredocs and /* */ comments, end on their last line.
+func (t Token) End() Pos {
+	pos := t.Pos
+	pos.Offset += len(t.Text)
+	for _, ch := range t.Text {
+		if ch == '\n' {
+			pos.Line++
+			pos.Column = 1
+			continue
+		}
+		pos.Column++
+	}
+	return pos
+}
+
+// Unit returns the number and the unit of a UNIT token, i.e. "10" and "s" for
+// 10s. The unit of other tokens is empty.
+func (t Token) Unit() (number, unit string) {
+	if t.Type != UNIT {
+		return t.Text, ""
+	}
+
+	text := t.Text
+	i := 0
+	if i < len(text) && text[i] == '-' {
+		i++
+	}
+
+	for i < len(text) {
+		switch c := text[i]; {
+		case '0' <= c && c <= '9', c == '.':
+			i++
+		case (c == 'e' || c == 'E') && i+1 < len(text) && strings.IndexByte("0123456789+-", text[i+1]) >= 0:
+			i += 2
+		default:
+			return text[:i], text[i:]
+		}
+	}
+	return text, ""
+}
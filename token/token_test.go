@@ -1,6 +1,10 @@
 package token
 
-import "testing"
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
 
 func TestTypeString(t *testing.T) {
 	var tokens = []struct {
@@ -15,15 +19,33 @@ func TestTypeString(t *testing.T) {
 		{FLOAT, "FLOAT"},
 		{BOOL, "BOOL"},
 		{STRING, "STRING"},
+		{UNIT, "UNIT"},
 		{LBRACK, "LBRACK"},
 		{LBRACE, "LBRACE"},
+		{LPAREN, "LPAREN"},
 		{COMMA, "COMMA"},
 		{PERIOD, "PERIOD"},
 		{RBRACK, "RBRACK"},
 		{RBRACE, "RBRACE"},
+		{RPAREN, "RPAREN"},
 		{ASSIGN, "ASSIGN"},
 		{ADD, "ADD"},
 		{SUB, "SUB"},
+		{MUL, "MUL"},
+		{QUO, "QUO"},
+		{MOD, "MOD"},
+		{EQL, "EQL"},
+		{NEQ, "NEQ"},
+		{LSS, "LSS"},
+		{GTR, "GTR"},
+		{LEQ, "LEQ"},
+		{GEQ, "GEQ"},
+		{AND, "AND"},
+		{OR, "OR"},
+		{NOT, "NOT"},
+		{QUESTION, "QUESTION"},
+		{COLON, "COLON"},
+		{WHITESPACE, "WHITESPACE"},
 	}
 
 	for _, token := range tokens {
@@ -34,3 +56,222 @@ func TestTypeString(t *testing.T) {
 	}
 
 }
+
+func TestUnit(t *testing.T) {
+	cases := []struct {
+		tok          Token
+		number, unit string
+	}{
+		{Token{Type: UNIT, Text: "10s"}, "10", "s"},
+		{Token{Type: UNIT, Text: "-1.5GiB"}, "-1.5", "GiB"},
+		{Token{Type: UNIT, Text: "2e3ms"}, "2e3", "ms"},
+		{Token{Type: UNIT, Text: "3e+2h"}, "3e+2", "h"},
+		{Token{Type: UNIT, Text: "1h30m"}, "1", "h30m"},
+		{Token{Type: UNIT, Text: "80%"}, "80", "%"},
+		{Token{Type: NUMBER, Text: "10"}, "10", ""},
+	}
+
+	for _, c := range cases {
+		number, unit := c.tok.Unit()
+		if number != c.number || unit != c.unit {
+			t.Errorf("%s: want %q %q, got %q %q", c.tok.Text, c.number, c.unit, number, unit)
+		}
+	}
+}
+
+func TestBigNumbers(t *testing.T) {
+	cases := []struct {
+		tok        Token
+		int, float string // empty for an error
+	}{
+		{Token{Type: NUMBER, Text: "42"}, "42", "42"},
+		{Token{Type: NUMBER, Text: "-0x1F"}, "-31", "-31"},
+		{Token{Type: NUMBER, Text: "0o17"}, "15", "15"},
+		{Token{Type: NUMBER, Text: "017"}, "15", "15"},
+		{Token{Type: NUMBER, Text: "1e6"}, "1000000", "1e+06"},
+		{Token{Type: NUMBER, Text: "2.5E-3"}, "", "0.0025"},
+		{Token{Type: NUMBER, Text: "123456789012345678901234567890"}, "123456789012345678901234567890", "1.2345678901234567890123456789e+29"},
+		{Token{Type: FLOAT, Text: "4.0"}, "4", "4"},
+		{Token{Type: FLOAT, Text: "0.1000000000000000000001"}, "", "0.1000000000000000000001"},
+		{Token{Type: NUMBER, Text: "1e1000"}, "1" + strings.Repeat("0", 1000), "1e+1000"},
+		{Token{Type: NUMBER, Text: "1e1001"}, "", "1e+1001"},
+		{Token{Type: NUMBER, Text: "1e99999999999999999999"}, "", ""},
+		{Token{Type: NUMBER, Text: "0x"}, "", ""},
+		{Token{Type: NUMBER, Text: "1e"}, "", ""},
+		{Token{Type: STRING, Text: "1"}, "", ""},
+	}
+
+	for _, c := range cases {
+		if i, err := c.tok.BigInt(); err != nil {
+			if c.int != "" {
+				t.Errorf("%s: %s", c.tok.Text, err)
+			}
+		} else if i.String() != c.int {
+			t.Errorf("%s: want integer %q, got %q", c.tok.Text, c.int, i)
+		}
+
+		if f, err := c.tok.BigFloat(); err != nil {
+			if c.float != "" {
+				t.Errorf("%s: %s", c.tok.Text, err)
+			}
+		} else if got := f.Text('g', -1); got != c.float {
+			t.Errorf("%s: want float %q, got %q", c.tok.Text, c.float, got)
+		}
+	}
+}
+
+func TestEnd(t *testing.T) {
+	cases := []struct {
+		tok Token
+		end Pos
+	}{
+		{Token{Type: IDENT, Pos: Pos{Offset: 4, Line: 2, Column: 3}, Text: "foo"}, Pos{Offset: 7, Line: 2, Column: 6}},
+		{Token{Type: STRING, Pos: Pos{Offset: 0, Line: 1, Column: 1}, Text: `"ä"`}, Pos{Offset: 4, Line: 1, Column: 4}},
+		{Token{Type: HEREDOC, Pos: Pos{Offset: 6, Line: 1, Column: 7}, Text: "<<EOF\nhi\nEOF"}, Pos{Offset: 18, Line: 3, Column: 4}},
+		{Token{Type: COMMENT, Pos: Pos{Offset: 0, Line: 1, Column: 1}, Text: "/* a\n */"}, Pos{Offset: 8, Line: 2, Column: 4}},
+		{Token{Type: EOF, Pos: Pos{Offset: 9, Line: 3, Column: 1}}, Pos{Offset: 9, Line: 3, Column: 1}},
+	}
+
+	for _, c := range cases {
+		if end := c.tok.End(); end != c.end {
+			t.Errorf("%q: want %#v, got %#v", c.tok.Text, c.end, end)
+		}
+	}
+}
+
+func TestHeredoc(t *testing.T) {
+	cases := []struct {
+		text   string
+		body   string
+		indent int
+		value  string
+	}{
+		{"<<EOF\nhello\n  world\nEOF", "hello\n  world\n", 0, "hello\n  world\n"},
+		{"<<EOF\nEOF", "", 0, ""},
+		{"<<-EOF\n    hello\n      world\n    EOF", "    hello\n      world\n", 4, "hello\n  world\n"},
+		{"<<-EOF\n\thello\n\n\t  world\n\tEOF", "\thello\n\n\t  world\n", 1, "hello\n\n  world\n"},
+		{"<<-EOF\n  \n    a\n  EOF", "  \n    a\n", 4, "\na\n"},
+	}
+
+	for _, c := range cases {
+		tok := Token{Type: HEREDOC, Pos: Pos{Offset: 4, Line: 1, Column: 5}, Text: c.text}
+		body, pos, indent := tok.HeredocBody()
+		if body != c.body || indent != c.indent {
+			t.Errorf("%q: want body %q indented by %d, got %q indented by %d", c.text, c.body, c.indent, body, indent)
+		}
+		if want := (Pos{Offset: 4 + strings.IndexByte(c.text, '\n') + 1, Line: 2, Column: 1}); pos != want {
+			t.Errorf("%q: want body at %#v, got %#v", c.text, want, pos)
+		}
+		if value := tok.HeredocValue(); value != c.value {
+			t.Errorf("%q: want value %q, got %q", c.text, c.value, value)
+		}
+	}
+
+	if value := (Token{Type: STRING, Text: `"a"`}).HeredocValue(); value != "" {
+		t.Errorf("want no value of a string, got %q", value)
+	}
+}
+
+func TestValue(t *testing.T) {
+	cases := []struct {
+		tok  Token
+		want string
+	}{
+		{Token{Type: STRING, Text: `"a\tb"`}, "a\tb"},
+		{Token{Type: STRING, Text: `"${var.x}"`}, "${var.x}"},
+		{Token{Type: STRING, Text: `"a`}, `"a`},
+		{Token{Type: HEREDOC, Text: "<<-EOF\n  a\n  EOF"}, "a\n"},
+		{Token{Type: IDENT, Text: "web"}, "web"},
+		{Token{Type: UNIT, Text: "10s"}, "10s"},
+		{Token{Type: NUMBER, Text: "0x10"}, "0x10"},
+	}
+
+	for _, c := range cases {
+		if got := c.tok.Value(); got != c.want {
+			t.Errorf("%s: want %q, got %q", c.tok, c.want, got)
+		}
+	}
+}
+
+func TestIsIdent(t *testing.T) {
+	for s, want := range map[string]bool{
+		"web": true, "_a1": true, "été": true, "null": true,
+		"": false, "1a": false, "a.b": false, "a-b": false, "true": false, "false": false,
+	} {
+		if got := IsIdent(s); got != want {
+			t.Errorf("%q: want %t, got %t", s, want, got)
+		}
+	}
+}
+
+func TestPrecedence(t *testing.T) {
+	ordered := [][]Type{
+		{IDENT, NUMBER, ASSIGN, NOT, QUESTION, COLON},
+		{OR},
+		{AND},
+		{EQL, NEQ, LSS, GTR, LEQ, GEQ},
+		{ADD, SUB},
+		{MUL, QUO, MOD},
+	}
+
+	for prec, types := range ordered {
+		for _, tt := range types {
+			if tt.Precedence() != prec {
+				t.Errorf("%s: want precedence: %d got: %d", tt, prec, tt.Precedence())
+			}
+		}
+	}
+}
+
+func TestGoString(t *testing.T) {
+	cases := []struct {
+		v    interface{}
+		want string
+	}{
+		{IDENT, "token.IDENT"},
+		{TEMPLATE_INTERP, "token.TEMPLATE_INTERP"},
+		{Type(1000), "token.Type(1000)"},
+		{
+			Token{Type: STRING, Pos: Pos{Offset: 4, Line: 1, Column: 5}, Text: `"a"`},
+			`token.Token{Type: token.STRING, Pos: token.Pos{Filename:"", Offset:4, Line:1, Column:5}, Text: "\"a\""}`,
+		},
+	}
+
+	for _, c := range cases {
+		if got := fmt.Sprintf("%#v", c.v); got != c.want {
+			t.Errorf("want %s, got %s", c.want, got)
+		}
+	}
+}
+
+func TestClassification(t *testing.T) {
+	cases := []struct {
+		tt                                          Type
+		ident, literal, operator, keyword, template bool
+	}{
+		{IDENT, true, false, false, false, false},
+		{STRING, true, true, false, false, false},
+		{BOOL, true, true, false, true, false},
+		{NULL, true, true, false, true, false},
+		{ASSIGN, false, false, true, false, false},
+		{TEMPLATE_TEXT, false, false, false, false, true},
+		{COMMENT, false, false, false, false, false},
+	}
+
+	for _, c := range cases {
+		got := []bool{c.tt.IsIdentifier(), c.tt.IsLiteral(), c.tt.IsOperator(), c.tt.IsKeyword(), c.tt.IsTemplate()}
+		want := []bool{c.ident, c.literal, c.operator, c.keyword, c.template}
+		for i := range got {
+			if got[i] != want[i] {
+				t.Errorf("%s: want %v, got %v", c.tt, want, got)
+				break
+			}
+		}
+	}
+
+	for ident, want := range map[string]Type{"true": BOOL, "false": BOOL, "null": NULL, "nil": IDENT, "True": IDENT} {
+		if got := Lookup(ident); got != want {
+			t.Errorf("%s: want %s, got %s", ident, want, got)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package hcl
+
+import (
+	"github.com/fatih/hcl/parser"
+	"github.com/fatih/hcl/scanner"
+	"github.com/fatih/hcl/token"
+)
+
+// Tokens returns all tokens of src with their exact source ranges, i.e. for
+// syntax highlighting and language servers: the texts of the tokens add up to
+// src. The white space between tokens is returned as WHITESPACE tokens and
+// comments as COMMENT tokens. Syntax errors don't stop the scanning: a token
+// the scanner reports an error for, such as an illegal character or an
+// unterminated string, is an ILLEGAL token spanning the broken source, and
+// the first error is returned along with the tokens as a *parser.PosError.
+// The EOF token isn't returned.
+func Tokens(src []byte) ([]token.Token, error) {
+	var err error
+	errs := 0
+	s := scanner.New(src)
+	s.Error = func(pos token.Pos, msg string) {
+		errs++
+		if err == nil {
+			err = &parser.PosError{Pos: pos, Err: &parser.ParseError{Pos: pos, Msg: msg}}
+		}
+	}
+
+	var tokens []token.Token
+	var end token.Pos // end of the previous token
+	end.Line, end.Column = 1, 1
+	for {
+		before := errs
+		tok := s.Scan()
+		if tok.Pos.Offset > end.Offset {
+			tokens = append(tokens, token.Token{
+				Type: token.WHITESPACE,
+				Pos:  end,
+				Text: string(src[end.Offset:tok.Pos.Offset]),
+			})
+		}
+		if tok.Type == token.EOF {
+			break
+		}
+
+		if errs > before {
+			tok.Type = token.ILLEGAL
+		}
+		tokens = append(tokens, tok)
+		end = tok.End()
+	}
+	return tokens, err
+}
@@ -0,0 +1,96 @@
+package hcl
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestTokens(t *testing.T) {
+	src := "# config\nname = \"app\" // the name\n\nport =  80\n"
+	tokens, err := Tokens([]byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	var text strings.Builder
+	for _, tok := range tokens {
+		got = append(got, fmt.Sprintf("%s %q %s", tok.Type, tok.Text, tok.Pos))
+		text.WriteString(tok.Text)
+		if src[tok.Pos.Offset:tok.Pos.Offset+len(tok.Text)] != tok.Text {
+			t.Errorf("%s: wrong range of %q", tok.Pos, tok.Text)
+		}
+	}
+
+	expected := []string{
+		`COMMENT "# config" 1:1`,
+		`WHITESPACE "\n" 1:9`,
+		`IDENT "name" 2:1`,
+		`WHITESPACE " " 2:5`,
+		`ASSIGN "=" 2:6`,
+		`WHITESPACE " " 2:7`,
+		`STRING "\"app\"" 2:8`,
+		`WHITESPACE " " 2:13`,
+		`COMMENT "// the name" 2:14`,
+		`WHITESPACE "\n\n" 2:25`,
+		`IDENT "port" 4:1`,
+		`WHITESPACE " " 4:5`,
+		`ASSIGN "=" 4:6`,
+		`WHITESPACE "  " 4:7`,
+		`NUMBER "80" 4:9`,
+		`WHITESPACE "\n" 4:11`,
+	}
+	if strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("\nwant:\n%s\ngot:\n%s", strings.Join(expected, "\n"), strings.Join(got, "\n"))
+	}
+	if text.String() != src {
+		t.Errorf("tokens don't add up to the source: %q", text.String())
+	}
+
+	// the byte order mark is white space of no column
+	tokens, err = Tokens([]byte("\ufeffa\r\n"))
+	if err != nil || len(tokens) != 3 ||
+		tokens[0].Type.String()+tokens[0].Text != "WHITESPACE\ufeff" ||
+		tokens[1].Pos.String() != "1:1" || tokens[1].Pos.Offset != 3 {
+		t.Errorf("unexpected tokens: %v, %v", tokens, err)
+	}
+}
+
+func TestTokensErrors(t *testing.T) {
+	cases := []struct {
+		src   string
+		types string
+		err   string
+	}{
+		{"a = @ b", "IDENT WHITESPACE ASSIGN WHITESPACE ILLEGAL WHITESPACE IDENT", "At 1:5: illegal char"},
+		{"a = \"abc\nb = 1", "IDENT WHITESPACE ASSIGN WHITESPACE ILLEGAL IDENT WHITESPACE ASSIGN WHITESPACE NUMBER",
+			"At 1:9: literal opened at 1:5 not terminated, expected: \""},
+		{"x = 1\n/* open", "IDENT WHITESPACE ASSIGN WHITESPACE NUMBER WHITESPACE ILLEGAL",
+			"At 2:8: comment opened at 2:1 not terminated, expected: */"},
+	}
+
+	for _, c := range cases {
+		tokens, err := Tokens([]byte(c.src))
+		if err == nil || err.Error() != c.err {
+			t.Errorf("%q: want error %q, got: %v", c.src, c.err, err)
+		}
+
+		var types []string
+		var text strings.Builder
+		for _, tok := range tokens {
+			types = append(types, tok.Type.String())
+			text.WriteString(tok.Text)
+		}
+		if got := strings.Join(types, " "); got != c.types {
+			t.Errorf("%q:\nwant: %s\ngot:  %s", c.src, c.types, got)
+		}
+		if text.String() != c.src {
+			t.Errorf("%q: tokens add up to %q", c.src, text.String())
+		}
+	}
+
+	if tokens, err := Tokens(nil); err != nil || len(tokens) != 0 {
+		t.Errorf("unexpected tokens of empty source: %v, %v", tokens, err)
+	}
+}
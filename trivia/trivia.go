@@ -0,0 +1,167 @@
+// Package trivia exposes the formatting of HCL (HashiCorp Configuration
+// Language) source text which the syntax tree leaves out: the exact
+// whitespace between the tokens and the placement of the comments. It's
+// meant for formatters and style checkers implementing policies the printer
+// doesn't, without scanning the source again themselves.
+package trivia
+
+import (
+	"errors"
+	"sort"
+	"strings"
+
+	"github.com/fatih/hcl/ast"
+	"github.com/fatih/hcl/parser"
+	"github.com/fatih/hcl/scanner"
+	"github.com/fatih/hcl/token"
+)
+
+// Token is a token of the source with the whitespace before it.
+type Token struct {
+	token.Token
+
+	// Space is the whitespace between the previous token, or the start of
+	// the source, and the token, as it is in the source.
+	Space string
+}
+
+// Newlines returns the number of line breaks in the space before t, i.e. 2
+// for a token following a blank line.
+func (t Token) Newlines() int {
+	return strings.Count(t.Space, "\n")
+}
+
+// Placement is the placement of a comment relative to the other tokens on
+// its lines.
+type Placement int
+
+const (
+	OwnLine   Placement = iota // no other tokens on the lines of the comment
+	EndOfLine                  // after a token, with none following on its line
+	Inline                     // followed by a token on its line: a = /* c */ 1
+)
+
+var placements = [...]string{
+	OwnLine:   "OwnLine",
+	EndOfLine: "EndOfLine",
+	Inline:    "Inline",
+}
+
+func (p Placement) String() string {
+	if 0 <= p && int(p) < len(placements) {
+		return placements[p]
+	}
+	return "Invalid"
+}
+
+// File is the tokens of a source, the syntax tree of the source is parsed
+// separately.
+type File struct {
+	// Tokens are the tokens of the source in source order, without the EOF
+	// token. Comments are tokens as well.
+	Tokens []Token
+
+	// Trailing is the whitespace after the last token.
+	Trailing string
+}
+
+// Scan returns the tokens of src. A scanner error is returned as a
+// *parser.PosError, with the tokens scanned up to the end of the source.
+func Scan(src []byte) (*File, error) {
+	var err error
+	s := scanner.New(src)
+	s.Error = func(pos token.Pos, msg string) {
+		if err == nil {
+			err = &parser.PosError{Pos: pos, Err: errors.New(msg)}
+		}
+	}
+
+	f := &File{}
+	prev := 0
+	for {
+		tok := s.Scan()
+		space := string(src[prev:tok.Pos.Offset])
+		if tok.Type == token.EOF {
+			f.Trailing = space
+			break
+		}
+
+		f.Tokens = append(f.Tokens, Token{Token: tok, Space: space})
+		prev = tok.Pos.Offset + len(tok.Text)
+	}
+	return f, err
+}
+
+// Node returns the tokens of the source range of n, a node of the syntax
+// tree of the source, whose Space fields are the spacing within the node.
+func (f *File) Node(n ast.Node) []Token {
+	start, end := f.index(n.Pos().Offset), f.index(ast.End(n).Offset)
+	if start == end && start < len(f.Tokens) && f.Tokens[start].Pos.Offset == n.Pos().Offset {
+		// a node without an end of its own, such as a template expression
+		end++
+	}
+	return f.Tokens[start:end]
+}
+
+// Leading returns the whitespace before n.
+func (f *File) Leading(n ast.Node) string {
+	if tokens := f.Node(n); len(tokens) > 0 {
+		return tokens[0].Space
+	}
+	return ""
+}
+
+// Indent returns the whitespace at the start of the line of n and whether n
+// is the first token on its line.
+func (f *File) Indent(n ast.Node) (string, bool) {
+	i := f.index(n.Pos().Offset)
+	if i == len(f.Tokens) {
+		return "", false
+	}
+
+	space := f.Tokens[i].Space
+	if j := strings.LastIndex(space, "\n"); j >= 0 {
+		return space[j+1:], true
+	}
+	if i == 0 {
+		return space, true
+	}
+	return "", false
+}
+
+// Comments returns the comment tokens of the source in source order. The
+// comments of a node are in the ast.CommentMap of the syntax tree.
+func (f *File) Comments() []Token {
+	var comments []Token
+	for _, tok := range f.Tokens {
+		if tok.Type == token.COMMENT {
+			comments = append(comments, tok)
+		}
+	}
+	return comments
+}
+
+// Placement returns the placement of the comment at pos.
+func (f *File) Placement(pos token.Pos) Placement {
+	i := f.index(pos.Offset)
+	if i == len(f.Tokens) || f.Tokens[i].Pos.Offset != pos.Offset {
+		return OwnLine
+	}
+
+	// the text of a line comment ends before its newline, which is the
+	// space of the next token
+	if i+1 < len(f.Tokens) && !strings.Contains(f.Tokens[i+1].Space, "\n") {
+		return Inline
+	}
+	if i > 0 && !strings.Contains(f.Tokens[i].Space, "\n") {
+		return EndOfLine
+	}
+	return OwnLine
+}
+
+// index returns the index of the first token starting at offset or after it
+func (f *File) index(offset int) int {
+	return sort.Search(len(f.Tokens), func(i int) bool {
+		return f.Tokens[i].Pos.Offset >= offset
+	})
+}
@@ -0,0 +1,125 @@
+package trivia
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/fatih/hcl/ast"
+	"github.com/fatih/hcl/parser"
+)
+
+const src = `# header
+
+name  =   "app" # the name
+service "web" {
+	port = /* default */ 80
+  /* legacy */
+	tags = [ "a","b" ]
+}
+`
+
+func TestScan(t *testing.T) {
+	f, err := Scan([]byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the source is the concatenation of the spaces and the tokens
+	var buf strings.Builder
+	for _, tok := range f.Tokens {
+		buf.WriteString(tok.Space)
+		buf.WriteString(tok.Text)
+	}
+	buf.WriteString(f.Trailing)
+	if buf.String() != src {
+		t.Errorf("want:\n%s\ngot:\n%s", src, buf.String())
+	}
+
+	if f.Trailing != "\n" {
+		t.Errorf("unexpected trailing space %q", f.Trailing)
+	}
+	if n := f.Tokens[1].Newlines(); n != 2 {
+		t.Errorf("want 2 newlines before name, got %d", n)
+	}
+
+	_, err = Scan([]byte("a = \"b"))
+	if err == nil || !strings.HasPrefix(err.Error(), "At 1:7: ") {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestNodes(t *testing.T) {
+	f, err := Scan([]byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	file, err := parser.Parse([]byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	items := file.Node.(*ast.ObjectList).Items
+	name := items[0]
+	service := items[1].Val.(*ast.ObjectType).List.Items
+	port, tags := service[0], service[1]
+
+	var spaces []string
+	for _, tok := range f.Node(name) {
+		spaces = append(spaces, tok.Space)
+	}
+	if got := strings.Join(spaces, "|"); got != "\n\n|  |   " {
+		t.Errorf("unexpected spacing of name: %q", got)
+	}
+
+	var texts []string
+	for _, tok := range f.Node(tags.Val) {
+		texts = append(texts, tok.Space+tok.Text)
+	}
+	if got := strings.Join(texts, ""); got != ` [ "a","b" ]` {
+		t.Errorf("unexpected tokens of tags: %q", got)
+	}
+
+	if got := f.Leading(port); got != "\n\t" {
+		t.Errorf("unexpected leading space of port: %q", got)
+	}
+
+	cases := []struct {
+		n      ast.Node
+		indent string
+		first  bool
+	}{
+		{name, "", true},
+		{items[1], "", true},
+		{port, "\t", true},
+		{tags, "\t", true},
+		{port.Val, "", false},
+	}
+	for _, c := range cases {
+		indent, first := f.Indent(c.n)
+		if indent != c.indent || first != c.first {
+			t.Errorf("%s: want indent %q, %t, got %q, %t", c.n.Pos(), c.indent, c.first, indent, first)
+		}
+	}
+}
+
+func TestComments(t *testing.T) {
+	f, err := Scan([]byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, c := range f.Comments() {
+		got = append(got, c.Text+" "+f.Placement(c.Pos).String())
+	}
+
+	expected := []string{
+		"# header OwnLine",
+		"# the name EndOfLine",
+		"/* default */ Inline",
+		"/* legacy */ OwnLine",
+	}
+	if strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("\nwant: %q\ngot:  %q", expected, got)
+	}
+}
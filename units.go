@@ -0,0 +1,88 @@
+package hcl
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/fatih/hcl/ast"
+	"github.com/fatih/hcl/token"
+)
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// unitMultipliers are the multipliers of the units of numbers: decimal and
+// binary sizes, optionally followed by B for bytes, and percentages.
+var unitMultipliers = map[string]float64{
+	"%": 0.01,
+	"B": 1,
+}
+
+func init() {
+	prefixes := map[string]float64{
+		"k": 1e3, "K": 1e3, "M": 1e6, "G": 1e9, "T": 1e12, "P": 1e15,
+		"Ki": 1 << 10, "Mi": 1 << 20, "Gi": 1 << 30, "Ti": 1 << 40, "Pi": 1 << 50,
+	}
+
+	for p, m := range prefixes {
+		unitMultipliers[p] = m
+		unitMultipliers[p+"B"] = m
+	}
+}
+
+// decodeUnit decodes a literal with a unit, i.e. 10s or 512k, into rv.
+// Durations are parsed with time.ParseDuration, numbers are multiplied by
+// their unit and strings are the text of the literal.
+func (d *decoder) decodeUnit(name string, lit *ast.LiteralType, rv reflect.Value) error {
+	text := lit.Token.Text
+	if rv.Type() == durationType {
+		dur, err := time.ParseDuration(text)
+		if err != nil {
+			return posErrorf(lit.Pos(), "%s: invalid duration %s", name, text)
+		}
+		rv.SetInt(int64(dur))
+		return nil
+	}
+
+	switch rv.Kind() {
+	case reflect.Ptr:
+		if rv.IsNil() {
+			rv.Set(reflect.New(rv.Type().Elem()))
+		}
+		return d.decodeUnit(name, lit, rv.Elem())
+	case reflect.Interface:
+		if rv.NumMethod() == 0 {
+			rv.Set(reflect.ValueOf(text))
+			return nil
+		}
+	case reflect.String:
+		rv.SetString(text)
+		return nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		v, err := unitNumber(lit.Token)
+		if err != nil {
+			return posErrorf(lit.Pos(), "%s: %s", name, err)
+		}
+		return d.decodeValue(name, lit.Pos(), v, rv)
+	}
+
+	return posErrorf(lit.Pos(), "%s: cannot decode %s into %s", name, text, rv.Type())
+}
+
+// unitNumber returns the number of a UNIT token multiplied by its unit
+func unitNumber(tok token.Token) (float64, error) {
+	number, unit := tok.Unit()
+	m, ok := unitMultipliers[unit]
+	if !ok {
+		return 0, fmt.Errorf("unknown unit %q of %s", unit, tok.Text)
+	}
+
+	f, err := strconv.ParseFloat(number, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid number %s", tok.Text)
+	}
+	return f * m, nil
+}
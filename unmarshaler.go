@@ -0,0 +1,40 @@
+package hcl
+
+import (
+	"reflect"
+
+	"github.com/fatih/hcl/ast"
+	"github.com/fatih/hcl/parser"
+)
+
+// Unmarshaler is implemented by types decoding themselves from a syntax tree,
+// like json.Unmarshaler. The decoder calls UnmarshalHCL instead of decoding
+// the node itself, for the value of every item decoded into the type,
+// including repeated items, and for the root of the file, which is an
+// *ast.ObjectList instead of an *ast.ObjectType. Errors which aren't
+// of type *parser.PosError are reported at the position of the node.
+type Unmarshaler interface {
+	UnmarshalHCL(n ast.Node) error
+}
+
+var unmarshalerType = reflect.TypeOf((*Unmarshaler)(nil)).Elem()
+
+// isUnmarshaler reports whether a pointer to a value of type t, which isn't a
+// pointer itself, implements Unmarshaler
+func isUnmarshaler(t reflect.Type) bool {
+	return t.Kind() != reflect.Ptr && reflect.PtrTo(t).Implements(unmarshalerType)
+}
+
+// unmarshal decodes n into rv with its UnmarshalHCL method, rv must be
+// addressable
+func (d *decoder) unmarshal(name string, n ast.Node, rv reflect.Value) error {
+	err := rv.Addr().Interface().(Unmarshaler).UnmarshalHCL(n)
+	if err == nil {
+		return nil
+	}
+
+	if _, ok := err.(*parser.PosError); ok {
+		return err
+	}
+	return posErrorf(n.Pos(), "%s: %s", name, err)
+}
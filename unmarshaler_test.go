@@ -0,0 +1,145 @@
+package hcl
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/fatih/hcl/ast"
+	"github.com/fatih/hcl/parser"
+)
+
+// csv decodes a string of comma separated values or a list of strings
+type csv []string
+
+func (c *csv) UnmarshalHCL(n ast.Node) error {
+	var v interface{}
+	if err := DecodeObject(&v, n); err != nil {
+		return err
+	}
+
+	switch v := v.(type) {
+	case string:
+		*c = append(*c, strings.Split(v, ",")...)
+	case []interface{}:
+		for _, elem := range v {
+			s, ok := elem.(string)
+			if !ok {
+				return errors.New("expected strings")
+			}
+			*c = append(*c, s)
+		}
+	default:
+		return errors.New("expected a string or a list")
+	}
+	return nil
+}
+
+// rule decodes itself from a block, counting the items
+type rule struct {
+	Name  string
+	Items int
+}
+
+func (r *rule) UnmarshalHCL(n ast.Node) error {
+	var list *ast.ObjectList
+	switch n := n.(type) {
+	case *ast.ObjectType:
+		list = n.List
+	case *ast.ObjectList:
+		list = n
+	default:
+		return &parser.PosError{Pos: n.Pos(), Err: errors.New("rule must be a block")}
+	}
+
+	r.Items += len(list.Items)
+	if name := (&ast.File{Node: list}).Attr("name"); name != nil {
+		r.Name, _ = name.AsString()
+	}
+	return nil
+}
+
+func TestUnmarshaler(t *testing.T) {
+	type config struct {
+		Hosts   csv             `hcl:"hosts"`
+		Ports   *csv            `hcl:"ports"`
+		Rule    rule            `hcl:"rule"`
+		Rules   []*rule         `hcl:"rules"`
+		ByName  map[string]rule `hcl:"named"`
+		Ignored int             `hcl:"ignored"`
+	}
+
+	src := `hosts = "a,b"
+hosts = ["c"]
+ports = ["80", "443"]
+rule { name = "x" }
+rule { level = 1 }
+rules { name = "y" }
+rules { name = "z" }
+named "w" { name = "v" }
+ignored = 1
+`
+
+	var out config
+	if err := Decode(&out, src); err != nil {
+		t.Fatal(err)
+	}
+
+	results := []struct {
+		name string
+		want string
+		got  interface{}
+	}{
+		{"hosts", "[a b c]", out.Hosts},
+		{"ports", "[80 443]", *out.Ports},
+		{"rule", "{x 2}", out.Rule},
+		{"rules", "[{y 1} {z 1}]", []rule{*out.Rules[0], *out.Rules[1]}},
+		{"named", "map[w:{v 1}]", out.ByName},
+	}
+	for _, r := range results {
+		if got := fmt.Sprint(r.got); got != r.want {
+			t.Errorf("%s: want %s, got %s", r.name, r.want, got)
+		}
+	}
+
+	// the root decodes itself too
+	var r rule
+	if err := Decode(&r, "name = \"root\"\na = 1"); err != nil || r.Name != "root" || r.Items != 2 {
+		t.Errorf("unexpected root: %+v, %v", r, err)
+	}
+}
+
+func TestUnmarshalerError(t *testing.T) {
+	type config struct {
+		Hosts csv  `hcl:"hosts"`
+		Rule  rule `hcl:"rule"`
+	}
+
+	cases := []struct {
+		src string
+		err string
+	}{
+		{`hosts = [1]`, "At 1:9: root.hosts: expected strings"},
+		{`hosts = 1`, "At 1:9: root.hosts: expected a string or a list"},
+		{`rule = "x"`, "At 1:8: rule must be a block"},
+	}
+
+	for _, c := range cases {
+		var out config
+		err := Decode(&out, c.src)
+		if err == nil || err.Error() != c.err {
+			t.Errorf("%s: want error %q, got: %v", c.src, c.err, err)
+		}
+
+		// the types aren't checked, UnmarshalHCL isn't called
+		f, _ := parser.Parse([]byte(c.src))
+		if errs := CheckTypes(f, out); len(errs) != 0 {
+			t.Errorf("%s: unexpected check errors: %v", c.src, errs)
+		}
+	}
+
+	if _, ok := Decode(new(config), `rule = "x"`).(*parser.PosError); !ok {
+		t.Error("expected a *parser.PosError")
+	}
+}
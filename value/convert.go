@@ -0,0 +1,295 @@
+package value
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// FromGo returns the value of a Go value. Strings, bools, integers and floats
+// are primitive values, slices are lists and maps with string keys are maps.
+// The element type of lists and maps is the type of their elements, or
+// DynamicPseudoType if they are of different types. nil is a null value of
+// DynamicPseudoType.
+func FromGo(v interface{}) (Value, error) {
+	if v == nil {
+		return NullVal(DynamicPseudoType), nil
+	}
+
+	if val, ok := v.(Value); ok {
+		return val, nil
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.String:
+		return StringVal(rv.String()), nil
+	case reflect.Bool:
+		return BoolVal(rv.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return NumberIntVal(rv.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return NumberIntVal(int64(rv.Uint())), nil
+	case reflect.Float32, reflect.Float64:
+		return NumberFloatVal(rv.Float()), nil
+	case reflect.Ptr, reflect.Interface:
+		if rv.IsNil() {
+			return NullVal(DynamicPseudoType), nil
+		}
+		return FromGo(rv.Elem().Interface())
+	case reflect.Slice, reflect.Array:
+		elems := make([]Value, 0, rv.Len())
+		for i := 0; i < rv.Len(); i++ {
+			e, err := FromGo(rv.Index(i).Interface())
+			if err != nil {
+				return Value{}, err
+			}
+			elems = append(elems, e)
+		}
+
+		elem, elems := unifyAll(elems)
+		return Value{ty: List(elem), v: elems}, nil
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			break
+		}
+
+		keys := rv.MapKeys()
+		names := make([]string, 0, len(keys))
+		elems := make([]Value, 0, len(keys))
+		for _, k := range keys {
+			e, err := FromGo(rv.MapIndex(k).Interface())
+			if err != nil {
+				return Value{}, err
+			}
+			names = append(names, k.String())
+			elems = append(elems, e)
+		}
+
+		elem, elems := unifyAll(elems)
+		m := make(map[string]Value, len(elems))
+		for i, e := range elems {
+			m[names[i]] = e
+		}
+		return Value{ty: Map(elem), v: m}, nil
+	}
+
+	return Value{}, fmt.Errorf("unsupported Go type %T", v)
+}
+
+// ToGo returns the Go value of a known value: string, int64, float64, bool,
+// []interface{} or map[string]interface{}. Null values are nil. Unknown
+// values are an error.
+func ToGo(v Value) (interface{}, error) {
+	if v.unknown {
+		return nil, fmt.Errorf("value of type %s is not known", v.ty.FriendlyName())
+	}
+
+	switch t := v.v.(type) {
+	case []Value:
+		list := make([]interface{}, 0, len(t))
+		for _, e := range t {
+			g, err := ToGo(e)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, g)
+		}
+		return list, nil
+	case map[string]Value:
+		m := make(map[string]interface{}, len(t))
+		for k, e := range t {
+			g, err := ToGo(e)
+			if err != nil {
+				return nil, err
+			}
+			m[k] = g
+		}
+		return m, nil
+	}
+	return v.v, nil
+}
+
+// Convert converts v to the type want. Every value converts to
+// DynamicPseudoType, null and unknown values to any type. The conversions
+// between primitive types are:
+//
+//	number -> string: decimal representation, such as "42" or "1.5"
+//	bool   -> string: "true" or "false"
+//	string -> number: strings parsing as a number
+//	string -> bool:   "true" and "false"
+//
+// Lists convert to lists and maps or objects to maps and objects, if all of
+// their elements convert. Objects convert to objects with the same
+// attributes only.
+func Convert(v Value, want Type) (Value, error) {
+	if want.kind == DynamicKind || v.ty.Equals(want) {
+		return v, nil
+	}
+
+	if v.unknown {
+		return UnknownVal(want), nil
+	}
+
+	if v.IsNull() {
+		return NullVal(want), nil
+	}
+
+	fail := func() (Value, error) {
+		return Value{}, fmt.Errorf("cannot convert %s to %s", v.ty.FriendlyName(), want.FriendlyName())
+	}
+
+	switch want.kind {
+	case StringKind:
+		switch t := v.v.(type) {
+		case int64:
+			return StringVal(strconv.FormatInt(t, 10)), nil
+		case float64:
+			return StringVal(strconv.FormatFloat(t, 'g', -1, 64)), nil
+		case bool:
+			return StringVal(strconv.FormatBool(t)), nil
+		}
+	case NumberKind:
+		if s, ok := v.v.(string); ok {
+			if i, err := strconv.ParseInt(s, 0, 64); err == nil {
+				return NumberIntVal(i), nil
+			}
+			if f, err := strconv.ParseFloat(s, 64); err == nil {
+				return NumberFloatVal(f), nil
+			}
+			return Value{}, fmt.Errorf("cannot convert %q to number", s)
+		}
+	case BoolKind:
+		if s, ok := v.v.(string); ok {
+			switch s {
+			case "true":
+				return BoolVal(true), nil
+			case "false":
+				return BoolVal(false), nil
+			}
+			return Value{}, fmt.Errorf("cannot convert %q to bool", s)
+		}
+	case ListKind:
+		list, ok := v.v.([]Value)
+		if !ok {
+			return fail()
+		}
+
+		elems := make([]Value, 0, len(list))
+		for i, e := range list {
+			c, err := Convert(e, *want.elem)
+			if err != nil {
+				return Value{}, fmt.Errorf("element %d: %s", i, err)
+			}
+			elems = append(elems, c)
+		}
+		return Value{ty: want, v: elems}, nil
+	case MapKind:
+		m, ok := v.v.(map[string]Value)
+		if !ok {
+			return fail()
+		}
+
+		elems := make(map[string]Value, len(m))
+		for k, e := range m {
+			c, err := Convert(e, *want.elem)
+			if err != nil {
+				return Value{}, fmt.Errorf("element %q: %s", k, err)
+			}
+			elems[k] = c
+		}
+		return Value{ty: want, v: elems}, nil
+	case ObjectKind:
+		m, ok := v.v.(map[string]Value)
+		if !ok {
+			return fail()
+		}
+
+		if v.ty.kind == ObjectKind && len(m) != len(want.attrs) {
+			return fail()
+		}
+
+		attrs := make(map[string]Value, len(want.attrs))
+		for k, at := range want.attrs {
+			e, ok := m[k]
+			if !ok {
+				return Value{}, fmt.Errorf("attribute %q is required", k)
+			}
+
+			c, err := Convert(e, at)
+			if err != nil {
+				return Value{}, fmt.Errorf("attribute %q: %s", k, err)
+			}
+			attrs[k] = c
+		}
+
+		for k := range m {
+			if _, ok := want.attrs[k]; !ok {
+				return Value{}, fmt.Errorf("unexpected attribute %q", k)
+			}
+		}
+		return Value{ty: want, v: attrs}, nil
+	}
+
+	return fail()
+}
+
+// Unify returns the type all of the given types can be converted to: the
+// type itself if they are all equal, string if they are primitive types,
+// a list or map of the unified element types for lists and maps, or
+// DynamicPseudoType otherwise.
+func Unify(types []Type) Type {
+	if len(types) == 0 {
+		return DynamicPseudoType
+	}
+
+	first := types[0]
+	same, primitive := true, true
+	var elems []Type
+	for _, t := range types {
+		same = same && t.Equals(first)
+		primitive = primitive && t.IsPrimitive()
+		if t.kind == first.kind && t.elem != nil {
+			elems = append(elems, *t.elem)
+		}
+	}
+
+	switch {
+	case same:
+		return first
+	case primitive:
+		return String
+	case (first.kind == ListKind || first.kind == MapKind) && len(elems) == len(types):
+		return Type{kind: first.kind, elem: ptr(Unify(elems))}
+	}
+	return DynamicPseudoType
+}
+
+// unifyAll returns the type of all values, or DynamicPseudoType if they're
+// of different types. Null values are converted to the type.
+func unifyAll(values []Value) (Type, []Value) {
+	t, set := DynamicPseudoType, false
+	for _, v := range values {
+		if v.IsNull() {
+			continue
+		}
+
+		if set && !v.ty.Equals(t) {
+			return DynamicPseudoType, values
+		}
+		t, set = v.ty, true
+	}
+
+	res := make([]Value, 0, len(values))
+	for _, v := range values {
+		if v.IsNull() {
+			v = NullVal(t)
+		}
+		res = append(res, v)
+	}
+	return t, res
+}
+
+func ptr(t Type) *Type {
+	return &t
+}
@@ -0,0 +1,177 @@
+package value
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFromGo(t *testing.T) {
+	cases := []struct {
+		Go       interface{}
+		Expected Value
+	}{
+		{"a", StringVal("a")},
+		{42, NumberIntVal(42)},
+		{uint8(42), NumberIntVal(42)},
+		{1.5, NumberFloatVal(1.5)},
+		{true, BoolVal(true)},
+		{nil, NullVal(DynamicPseudoType)},
+		{[]string{"a", "b"}, ListVal([]Value{StringVal("a"), StringVal("b")})},
+		{[]interface{}{}, ListValEmpty(DynamicPseudoType)},
+		{[]interface{}{"a", nil}, ListVal([]Value{StringVal("a"), NullVal(String)})},
+		{map[string]interface{}{"a": int64(1)}, MapVal(map[string]Value{"a": NumberIntVal(1)})},
+	}
+
+	for _, c := range cases {
+		v, err := FromGo(c.Go)
+		if err != nil {
+			t.Errorf("%#v: %s", c.Go, err)
+			continue
+		}
+
+		if !v.Type().Equals(c.Expected.Type()) || !v.Equals(c.Expected) {
+			t.Errorf("%#v:\nwant: %#v\ngot:  %#v", c.Go, c.Expected, v)
+		}
+	}
+}
+
+func TestFromGoMixed(t *testing.T) {
+	v, err := FromGo([]interface{}{"a", int64(1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !v.Type().Equals(List(DynamicPseudoType)) {
+		t.Fatalf("want: list(dynamic), got: %#v", v.Type())
+	}
+
+	elems := v.AsValueSlice()
+	if !elems[0].Equals(StringVal("a")) || !elems[1].Equals(NumberIntVal(1)) {
+		t.Errorf("elements lost their types: %#v", elems)
+	}
+
+	if _, err := FromGo(struct{}{}); err == nil {
+		t.Error("expected an error for a struct")
+	}
+}
+
+func TestToGo(t *testing.T) {
+	v := ObjectVal(map[string]Value{
+		"list": ListVal([]Value{NumberIntVal(1), NumberFloatVal(1.5)}),
+		"s":    StringVal("a"),
+		"null": NullVal(Bool),
+	})
+
+	got, err := ToGo(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]interface{}{
+		"list": []interface{}{int64(1), 1.5},
+		"s":    "a",
+		"null": nil,
+	}
+
+	if !reflect.DeepEqual(expected, got) {
+		t.Errorf("\nwant: %#v\ngot:  %#v", expected, got)
+	}
+
+	if _, err := ToGo(ListVal([]Value{UnknownVal(String)})); err == nil {
+		t.Error("expected an error for an unknown value")
+	}
+}
+
+func TestConvert(t *testing.T) {
+	cases := []struct {
+		Value    Value
+		Type     Type
+		Expected Value
+		Err      string
+	}{
+		{NumberIntVal(42), String, StringVal("42"), ""},
+		{NumberFloatVal(1.5), String, StringVal("1.5"), ""},
+		{BoolVal(true), String, StringVal("true"), ""},
+		{StringVal("42"), Number, NumberIntVal(42), ""},
+		{StringVal("0x10"), Number, NumberIntVal(16), ""},
+		{StringVal("1e3"), Number, NumberFloatVal(1000), ""},
+		{StringVal("a"), Number, Value{}, `cannot convert "a" to number`},
+		{StringVal("false"), Bool, BoolVal(false), ""},
+		{StringVal("yes"), Bool, Value{}, `cannot convert "yes" to bool`},
+		{NumberIntVal(1), Bool, Value{}, "cannot convert number to bool"},
+		{NullVal(DynamicPseudoType), String, NullVal(String), ""},
+		{UnknownVal(DynamicPseudoType), Number, UnknownVal(Number), ""},
+		{StringVal("a"), DynamicPseudoType, StringVal("a"), ""},
+		{
+			ListVal([]Value{NumberIntVal(1), NumberIntVal(2)}),
+			List(String),
+			ListVal([]Value{StringVal("1"), StringVal("2")}),
+			"",
+		},
+		{
+			ListVal([]Value{StringVal("1"), StringVal("b")}),
+			List(Number),
+			Value{},
+			`element 1: cannot convert "b" to number`,
+		},
+		{
+			MapVal(map[string]Value{"port": StringVal("80")}),
+			Object(map[string]Type{"port": Number}),
+			ObjectVal(map[string]Value{"port": NumberIntVal(80)}),
+			"",
+		},
+		{
+			MapVal(map[string]Value{"port": StringVal("80")}),
+			Object(map[string]Type{"host": String, "port": Number}),
+			Value{},
+			`attribute "host" is required`,
+		},
+		{
+			MapVal(map[string]Value{"a": StringVal("1"), "b": StringVal("2")}),
+			Object(map[string]Type{"a": Number}),
+			Value{},
+			`unexpected attribute "b"`,
+		},
+		{StringVal("a"), List(String), Value{}, "cannot convert string to list"},
+	}
+
+	for _, c := range cases {
+		v, err := Convert(c.Value, c.Type)
+		if c.Err != "" {
+			if err == nil || err.Error() != c.Err {
+				t.Errorf("%#v to %#v: want error %q, got: %v", c.Value, c.Type, c.Err, err)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("%#v to %#v: %s", c.Value, c.Type, err)
+			continue
+		}
+
+		if !v.Type().Equals(c.Expected.Type()) || v.IsKnown() != c.Expected.IsKnown() ||
+			v.IsKnown() && !v.Equals(c.Expected) {
+			t.Errorf("%#v to %#v:\nwant: %#v\ngot:  %#v", c.Value, c.Type, c.Expected, v)
+		}
+	}
+}
+
+func TestUnify(t *testing.T) {
+	cases := []struct {
+		Types    []Type
+		Expected Type
+	}{
+		{nil, DynamicPseudoType},
+		{[]Type{Number, Number}, Number},
+		{[]Type{Number, Bool, String}, String},
+		{[]Type{List(Number), List(String)}, List(String)},
+		{[]Type{List(Number), Map(Number)}, DynamicPseudoType},
+		{[]Type{String, List(String)}, DynamicPseudoType},
+	}
+
+	for _, c := range cases {
+		if got := Unify(c.Types); !got.Equals(c.Expected) {
+			t.Errorf("%#v: want: %#v, got: %#v", c.Types, c.Expected, got)
+		}
+	}
+}
@@ -0,0 +1,385 @@
+// Package value implements the types and values of evaluated HCL (HashiCorp
+// Configuration Language) expressions, similar to cty. Values are of type
+// string, number, bool, list, map or object and might be null or unknown.
+// The conversion rules between them are shared by the evaluator and the
+// decoder.
+package value
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Kind is the kind of a Type.
+type Kind int
+
+const (
+	DynamicKind Kind = iota // any type, not known yet
+	StringKind
+	NumberKind
+	BoolKind
+	ListKind
+	MapKind
+	ObjectKind
+)
+
+// Type is the type of a Value. Types are compared with Equals.
+type Type struct {
+	kind  Kind
+	elem  *Type           // element type of lists and maps
+	attrs map[string]Type // attribute types of objects
+}
+
+// The primitive types. DynamicPseudoType is a placeholder for any type, i.e.
+// of null or unknown values whose type isn't known.
+var (
+	DynamicPseudoType = Type{kind: DynamicKind}
+	String            = Type{kind: StringKind}
+	Number            = Type{kind: NumberKind}
+	Bool              = Type{kind: BoolKind}
+)
+
+// List returns the type of lists with elements of type elem.
+func List(elem Type) Type {
+	return Type{kind: ListKind, elem: &elem}
+}
+
+// Map returns the type of maps with elements of type elem.
+func Map(elem Type) Type {
+	return Type{kind: MapKind, elem: &elem}
+}
+
+// Object returns the type of objects with the given attributes.
+func Object(attrs map[string]Type) Type {
+	m := make(map[string]Type, len(attrs))
+	for k, t := range attrs {
+		m[k] = t
+	}
+	return Type{kind: ObjectKind, attrs: m}
+}
+
+// Kind returns the kind of t.
+func (t Type) Kind() Kind { return t.kind }
+
+// IsPrimitive reports whether t is a string, number or bool.
+func (t Type) IsPrimitive() bool {
+	return t.kind == StringKind || t.kind == NumberKind || t.kind == BoolKind
+}
+
+// ElementType returns the element type of a list or map type. It panics for
+// other types.
+func (t Type) ElementType() Type {
+	if t.elem == nil {
+		panic(fmt.Sprintf("value: ElementType of %s", t.FriendlyName()))
+	}
+	return *t.elem
+}
+
+// AttributeTypes returns the attribute types of an object type. It panics
+// for other types.
+func (t Type) AttributeTypes() map[string]Type {
+	if t.kind != ObjectKind {
+		panic(fmt.Sprintf("value: AttributeTypes of %s", t.FriendlyName()))
+	}
+	return t.attrs
+}
+
+// Equals reports whether t and o are the same type.
+func (t Type) Equals(o Type) bool {
+	if t.kind != o.kind {
+		return false
+	}
+
+	switch t.kind {
+	case ListKind, MapKind:
+		return t.elem.Equals(*o.elem)
+	case ObjectKind:
+		if len(t.attrs) != len(o.attrs) {
+			return false
+		}
+		for k, at := range t.attrs {
+			ot, ok := o.attrs[k]
+			if !ok || !at.Equals(ot) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// FriendlyName returns the name of the kind of t, as used in error messages.
+func (t Type) FriendlyName() string {
+	switch t.kind {
+	case DynamicKind:
+		return "dynamic"
+	case StringKind:
+		return "string"
+	case NumberKind:
+		return "number"
+	case BoolKind:
+		return "bool"
+	case ListKind:
+		return "list"
+	case MapKind:
+		return "map"
+	case ObjectKind:
+		return "object"
+	}
+	return "unknown"
+}
+
+// GoString returns the full type, such as list(map(string)).
+func (t Type) GoString() string {
+	switch t.kind {
+	case ListKind, MapKind:
+		return fmt.Sprintf("%s(%#v)", t.FriendlyName(), *t.elem)
+	case ObjectKind:
+		keys := make([]string, 0, len(t.attrs))
+		for k := range t.attrs {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		attrs := make([]string, 0, len(keys))
+		for _, k := range keys {
+			attrs = append(attrs, fmt.Sprintf("%s=%#v", k, t.attrs[k]))
+		}
+		return "object({" + strings.Join(attrs, ", ") + "})"
+	}
+	return t.FriendlyName()
+}
+
+// Value is a value of a Type. The zero Value is a null value of
+// DynamicPseudoType.
+type Value struct {
+	ty      Type
+	v       interface{} // string, int64, float64, bool, []Value or map[string]Value
+	unknown bool
+}
+
+// StringVal returns a string value.
+func StringVal(s string) Value { return Value{ty: String, v: s} }
+
+// NumberIntVal returns an integer number value.
+func NumberIntVal(i int64) Value { return Value{ty: Number, v: i} }
+
+// NumberFloatVal returns a floating point number value.
+func NumberFloatVal(f float64) Value { return Value{ty: Number, v: f} }
+
+// BoolVal returns a bool value.
+func BoolVal(b bool) Value { return Value{ty: Bool, v: b} }
+
+// NullVal returns the null value of type t.
+func NullVal(t Type) Value { return Value{ty: t} }
+
+// UnknownVal returns a value of type t which isn't known yet.
+func UnknownVal(t Type) Value { return Value{ty: t, unknown: true} }
+
+// ListVal returns a list of the given elements, which must be of the same
+// type. Use ListValEmpty for empty lists.
+func ListVal(elems []Value) Value {
+	if len(elems) == 0 {
+		panic("value: ListVal with no elements, use ListValEmpty")
+	}
+
+	for _, e := range elems[1:] {
+		if !e.ty.Equals(elems[0].ty) {
+			panic(fmt.Sprintf("value: inconsistent list element types %#v and %#v", elems[0].ty, e.ty))
+		}
+	}
+	return Value{ty: List(elems[0].ty), v: elems}
+}
+
+// ListValEmpty returns an empty list with elements of type elem.
+func ListValEmpty(elem Type) Value {
+	return Value{ty: List(elem), v: []Value{}}
+}
+
+// MapVal returns a map of the given elements, which must be of the same
+// type. Use MapValEmpty for empty maps.
+func MapVal(elems map[string]Value) Value {
+	var elem *Type
+	for _, e := range elems {
+		if elem == nil {
+			t := e.ty
+			elem = &t
+		} else if !e.ty.Equals(*elem) {
+			panic(fmt.Sprintf("value: inconsistent map element types %#v and %#v", *elem, e.ty))
+		}
+	}
+
+	if elem == nil {
+		panic("value: MapVal with no elements, use MapValEmpty")
+	}
+	return Value{ty: Map(*elem), v: elems}
+}
+
+// MapValEmpty returns an empty map with elements of type elem.
+func MapValEmpty(elem Type) Value {
+	return Value{ty: Map(elem), v: map[string]Value{}}
+}
+
+// ObjectVal returns an object with the given attributes.
+func ObjectVal(attrs map[string]Value) Value {
+	types := make(map[string]Type, len(attrs))
+	for k, a := range attrs {
+		types[k] = a.ty
+	}
+	return Value{ty: Object(types), v: attrs}
+}
+
+// Type returns the type of v.
+func (v Value) Type() Type { return v.ty }
+
+// IsNull reports whether v is null.
+func (v Value) IsNull() bool { return !v.unknown && v.v == nil }
+
+// IsKnown reports whether v is known.
+func (v Value) IsKnown() bool { return !v.unknown }
+
+// IsWhollyKnown reports whether v and all of its elements are known.
+func (v Value) IsWhollyKnown() bool {
+	if v.unknown {
+		return false
+	}
+
+	switch t := v.v.(type) {
+	case []Value:
+		for _, e := range t {
+			if !e.IsWhollyKnown() {
+				return false
+			}
+		}
+	case map[string]Value:
+		for _, e := range t {
+			if !e.IsWhollyKnown() {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// AsString returns the string of a known string value. It panics for other
+// values.
+func (v Value) AsString() string {
+	v.assert(StringKind)
+	return v.v.(string)
+}
+
+// True returns the bool of a known bool value. It panics for other values.
+func (v Value) True() bool {
+	v.assert(BoolKind)
+	return v.v.(bool)
+}
+
+// IsInt reports whether v is a known number with an integer representation.
+func (v Value) IsInt() bool {
+	_, ok := v.v.(int64)
+	return ok
+}
+
+// AsInt64 returns the integer of a known number value, floats with a
+// fractional part are an error. It panics for other values.
+func (v Value) AsInt64() (int64, error) {
+	v.assert(NumberKind)
+	switch t := v.v.(type) {
+	case int64:
+		return t, nil
+	case float64:
+		if i := int64(t); float64(i) == t {
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("%v is not an integer", v.v)
+}
+
+// AsFloat64 returns the float of a known number value. It panics for other
+// values.
+func (v Value) AsFloat64() float64 {
+	v.assert(NumberKind)
+	if i, ok := v.v.(int64); ok {
+		return float64(i)
+	}
+	return v.v.(float64)
+}
+
+// AsValueSlice returns the elements of a known list value. It panics for
+// other values.
+func (v Value) AsValueSlice() []Value {
+	v.assert(ListKind)
+	return v.v.([]Value)
+}
+
+// AsValueMap returns the elements of a known map or object value. It panics
+// for other values.
+func (v Value) AsValueMap() map[string]Value {
+	if v.ty.kind != ObjectKind {
+		v.assert(MapKind)
+	}
+	return v.v.(map[string]Value)
+}
+
+// Equals reports whether v and o are known values of equal types and
+// contents. Numbers are compared by their value, i.e. 1 equals 1.0.
+func (v Value) Equals(o Value) bool {
+	if v.unknown || o.unknown || v.IsNull() != o.IsNull() {
+		return false
+	}
+
+	if v.ty.kind == NumberKind && o.ty.kind == NumberKind && !v.IsNull() {
+		if v.IsInt() && o.IsInt() {
+			return v.v.(int64) == o.v.(int64)
+		}
+		return v.AsFloat64() == o.AsFloat64()
+	}
+
+	if !v.ty.Equals(o.ty) {
+		return false
+	}
+
+	switch t := v.v.(type) {
+	case []Value:
+		ol := o.v.([]Value)
+		if len(t) != len(ol) {
+			return false
+		}
+		for i := range t {
+			if !t[i].Equals(ol[i]) {
+				return false
+			}
+		}
+		return true
+	case map[string]Value:
+		om := o.v.(map[string]Value)
+		if len(t) != len(om) {
+			return false
+		}
+		for k, e := range t {
+			oe, ok := om[k]
+			if !ok || !e.Equals(oe) {
+				return false
+			}
+		}
+		return true
+	}
+	return v.v == o.v
+}
+
+// GoString returns a representation of v for debugging.
+func (v Value) GoString() string {
+	switch {
+	case v.unknown:
+		return fmt.Sprintf("value.UnknownVal(%#v)", v.ty)
+	case v.IsNull():
+		return fmt.Sprintf("value.NullVal(%#v)", v.ty)
+	}
+	return fmt.Sprintf("value.Value(%#v, %#v)", v.ty, v.v)
+}
+
+func (v Value) assert(k Kind) {
+	if v.ty.kind != k || v.unknown || v.v == nil {
+		panic(fmt.Sprintf("value: %#v is not a known %s", v, Type{kind: k}.FriendlyName()))
+	}
+}
@@ -0,0 +1,118 @@
+package value
+
+import (
+	"testing"
+)
+
+func TestTypeGoString(t *testing.T) {
+	cases := []struct {
+		Type     Type
+		Expected string
+	}{
+		{DynamicPseudoType, "dynamic"},
+		{String, "string"},
+		{List(Map(Number)), "list(map(number))"},
+		{Object(map[string]Type{"b": Bool, "a": List(String)}), "object({a=list(string), b=bool})"},
+	}
+
+	for _, c := range cases {
+		if got := c.Type.GoString(); got != c.Expected {
+			t.Errorf("want: %q, got: %q", c.Expected, got)
+		}
+	}
+}
+
+func TestTypeEquals(t *testing.T) {
+	cases := []struct {
+		A, B     Type
+		Expected bool
+	}{
+		{String, String, true},
+		{String, Number, false},
+		{List(String), List(String), true},
+		{List(String), Map(String), false},
+		{List(String), List(Number), false},
+		{Object(map[string]Type{"a": Bool}), Object(map[string]Type{"a": Bool}), true},
+		{Object(map[string]Type{"a": Bool}), Object(map[string]Type{"b": Bool}), false},
+		{Object(map[string]Type{"a": Bool}), Object(map[string]Type{}), false},
+	}
+
+	for _, c := range cases {
+		if got := c.A.Equals(c.B); got != c.Expected {
+			t.Errorf("%#v equals %#v: want: %t, got: %t", c.A, c.B, c.Expected, got)
+		}
+	}
+}
+
+func TestValueEquals(t *testing.T) {
+	cases := []struct {
+		A, B     Value
+		Expected bool
+	}{
+		{StringVal("a"), StringVal("a"), true},
+		{StringVal("a"), StringVal("b"), false},
+		{NumberIntVal(1), NumberFloatVal(1), true},
+		{NumberIntVal(1), StringVal("1"), false},
+		{NullVal(String), NullVal(String), true},
+		{NullVal(String), StringVal(""), false},
+		{UnknownVal(String), UnknownVal(String), false},
+		{ListVal([]Value{BoolVal(true)}), ListVal([]Value{BoolVal(true)}), true},
+		{ListVal([]Value{BoolVal(true)}), ListVal([]Value{BoolVal(false)}), false},
+		{MapVal(map[string]Value{"a": NumberIntVal(1)}), MapVal(map[string]Value{"a": NumberIntVal(1)}), true},
+		{MapVal(map[string]Value{"a": NumberIntVal(1)}), ObjectVal(map[string]Value{"a": NumberIntVal(1)}), false},
+	}
+
+	for _, c := range cases {
+		if got := c.A.Equals(c.B); got != c.Expected {
+			t.Errorf("%#v equals %#v: want: %t, got: %t", c.A, c.B, c.Expected, got)
+		}
+	}
+}
+
+func TestValueKnown(t *testing.T) {
+	list := ListVal([]Value{StringVal("a"), UnknownVal(String)})
+	if !list.IsKnown() {
+		t.Error("list should be known")
+	}
+	if list.IsWhollyKnown() {
+		t.Error("list shouldn't be wholly known")
+	}
+
+	if v := UnknownVal(Number); v.IsKnown() || v.IsNull() {
+		t.Error("unknown value should be neither known nor null")
+	}
+}
+
+func TestValueAsInt64(t *testing.T) {
+	cases := []struct {
+		Value    Value
+		Expected int64
+		Err      bool
+	}{
+		{NumberIntVal(42), 42, false},
+		{NumberFloatVal(2), 2, false},
+		{NumberFloatVal(1.5), 0, true},
+	}
+
+	for _, c := range cases {
+		i, err := c.Value.AsInt64()
+		if (err != nil) != c.Err {
+			t.Errorf("%#v: unexpected error: %v", c.Value, err)
+			continue
+		}
+
+		if i != c.Expected {
+			t.Errorf("%#v: want: %d, got: %d", c.Value, c.Expected, i)
+		}
+	}
+}
+
+func TestListValInconsistent(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a panic")
+		}
+	}()
+
+	ListVal([]Value{StringVal("a"), NumberIntVal(1)})
+}
@@ -0,0 +1,66 @@
+package hcl
+
+import (
+	"os"
+	"strings"
+
+	"github.com/fatih/hcl/ast"
+)
+
+// DecodeWithVars decodes src into out like Decode, substituting the
+// references ${var.name} in strings with vars["name"] and ${env.NAME} with
+// the environment variable NAME. See VarsResolver.
+func DecodeWithVars(out interface{}, vars map[string]string, src string) error {
+	c := DefaultDecoderConfig
+	c.Resolver = VarsResolver(vars)
+	return c.Decode(out, src)
+}
+
+// VarsResolver returns a Resolver substituting the references ${var.name}
+// with vars["name"] and ${env.NAME} with the environment variable NAME, for
+// the Resolver of a DecoderConfig with other options. Strings are always
+// strings after the substitution, "port-${var.port}" as well as
+// "${var.port}", which the decoder converts like other strings, i.e. into a
+// number for an int field. "$${" escapes a literal "${".
+//
+// A reference to an undefined variable or an unset environment variable is an
+// error with the position of the reference, as is any other expression, such
+// as a function call.
+func VarsResolver(vars map[string]string) Resolver {
+	return func(t *ast.Template) (interface{}, error) {
+		var buf strings.Builder
+		for _, part := range t.Parts {
+			switch p := part.(type) {
+			case *ast.TemplateText:
+				buf.WriteString(p.Text)
+			case *ast.Variable:
+				v, err := lookupVar(p, vars)
+				if err != nil {
+					return nil, err
+				}
+				buf.WriteString(v)
+			default:
+				return nil, posErrorf(part.Pos(), "unsupported expression, only ${var.name} and ${env.NAME} are substituted")
+			}
+		}
+		return buf.String(), nil
+	}
+}
+
+// lookupVar returns the value of the reference v to a variable of vars or an
+// environment variable
+func lookupVar(v *ast.Variable, vars map[string]string) (string, error) {
+	switch {
+	case strings.HasPrefix(v.Name, "var."):
+		if s, ok := vars[v.Name[len("var."):]]; ok {
+			return s, nil
+		}
+		return "", posErrorf(v.Pos(), "undefined variable %s", v.Name)
+	case strings.HasPrefix(v.Name, "env."):
+		if s, ok := os.LookupEnv(v.Name[len("env."):]); ok {
+			return s, nil
+		}
+		return "", posErrorf(v.Pos(), "unset environment variable %s", v.Name[len("env."):])
+	}
+	return "", posErrorf(v.Pos(), "unknown reference %s, only var.name and env.NAME are substituted", v.Name)
+}
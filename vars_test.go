@@ -0,0 +1,62 @@
+package hcl
+
+import (
+	"os"
+	"testing"
+)
+
+func TestDecodeWithVars(t *testing.T) {
+	os.Setenv("HCL_TEST_HOME", "/home/app")
+	defer os.Unsetenv("HCL_TEST_HOME")
+
+	var out struct {
+		Name  string   `hcl:"name"`
+		Port  int      `hcl:"port"`
+		Dir   string   `hcl:"dir"`
+		Raw   string   `hcl:"raw"`
+		Tags  []string `hcl:"tags"`
+		Shell string   `hcl:"shell"`
+	}
+
+	src := `name = "${var.name}-${var.env}"
+port = "${var.port}"
+dir = "${env.HCL_TEST_HOME}/data"
+raw = "$${var.name}"
+tags = ["${var.env}", "static"]
+shell = <<EOT
+cd ${env.HCL_TEST_HOME}
+EOT
+`
+	vars := map[string]string{"name": "web", "env": "prod", "port": "8080"}
+	if err := DecodeWithVars(&out, vars, src); err != nil {
+		t.Fatal(err)
+	}
+
+	if out.Name != "web-prod" || out.Port != 8080 || out.Dir != "/home/app/data" ||
+		out.Raw != "${var.name}" || len(out.Tags) != 2 || out.Tags[0] != "prod" ||
+		out.Shell != "cd /home/app\n" {
+		t.Errorf("unexpected result %+v", out)
+	}
+}
+
+func TestDecodeWithVarsErrors(t *testing.T) {
+	os.Unsetenv("HCL_TEST_UNSET")
+
+	cases := []struct {
+		src string
+		err string
+	}{
+		{`a = "x ${var.missing}"`, "At 1:10: undefined variable var.missing"},
+		{`a = "${env.HCL_TEST_UNSET}"`, "At 1:8: unset environment variable HCL_TEST_UNSET"},
+		{`a = "${local.x}"`, "At 1:8: unknown reference local.x, only var.name and env.NAME are substituted"},
+		{`a = "${upper(var.x)}"`, "At 1:8: unsupported expression, only ${var.name} and ${env.NAME} are substituted"},
+	}
+
+	for _, c := range cases {
+		var out map[string]interface{}
+		err := DecodeWithVars(&out, map[string]string{"x": "1"}, c.src)
+		if err == nil || err.Error() != c.err {
+			t.Errorf("%s: want error %q, got: %v", c.src, c.err, err)
+		}
+	}
+}
@@ -0,0 +1,300 @@
+// Package watch reloads HCL (HashiCorp Configuration Language) config files
+// when they change. A Watcher re-parses and decodes the files on every
+// change reported by a Notifier and delivers the new configuration over a
+// channel.
+package watch
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fatih/hcl"
+)
+
+// A Notifier reports changes of watched files, i.e. by polling or with
+// inotify.
+type Notifier interface {
+	// Events returns the channel receiving a value on every change.
+	Events() <-chan struct{}
+
+	// Close stops the notifier and closes the events channel.
+	Close() error
+}
+
+// Watcher watches a config file, or all files with the extension ".hcl" in
+// a directory, and decodes them on every change.
+type Watcher struct {
+	// Path is the watched file or directory.
+	Path string
+
+	// New returns a pointer to a new config value the files are decoded
+	// into, i.e. func() interface{} { return &Config{} }.
+	New func() interface{}
+
+	// Validate, if set, is called with the current and the new config
+	// before the new config is delivered. If it returns an error, the
+	// error is delivered instead and the current config is kept.
+	Validate func(old, new interface{}) error
+
+	// Decoder is the configuration used to decode the files. If nil,
+	// hcl.DefaultDecoderConfig is used.
+	Decoder *hcl.DecoderConfig
+
+	notifier Notifier
+	configs  chan interface{}
+	errors   chan error
+	done     chan struct{}
+	wg       sync.WaitGroup
+
+	closeOnce sync.Once
+	closeErr  error
+
+	current interface{}
+	hash    string
+}
+
+// New returns a watcher for the config file or directory at path, which is
+// decoded into the values returned by newConfig on every event of n.
+func New(path string, n Notifier, newConfig func() interface{}) *Watcher {
+	return &Watcher{
+		Path:     path,
+		New:      newConfig,
+		notifier: n,
+		configs:  make(chan interface{}),
+		errors:   make(chan error),
+		done:     make(chan struct{}),
+	}
+}
+
+// Start loads the current config and starts watching for changes. It
+// returns the current config or an error if it can't be loaded.
+func (w *Watcher) Start() (interface{}, error) {
+	config, hash, err := w.load()
+	if err != nil {
+		return nil, err
+	}
+
+	if w.Validate != nil {
+		if err := w.Validate(nil, config); err != nil {
+			return nil, err
+		}
+	}
+
+	w.current, w.hash = config, hash
+
+	w.wg.Add(1)
+	go w.run()
+	return config, nil
+}
+
+// Configs returns the channel receiving the new config after every change.
+// Changes of the formatting or comments only don't deliver a new config.
+func (w *Watcher) Configs() <-chan interface{} {
+	return w.configs
+}
+
+// Errors returns the channel receiving the errors of loading or validating
+// changed files.
+func (w *Watcher) Errors() <-chan error {
+	return w.errors
+}
+
+// Close stops watching and closes the notifier. Closing the watcher again
+// returns the error of the first Close.
+func (w *Watcher) Close() error {
+	w.closeOnce.Do(func() {
+		close(w.done)
+		w.closeErr = w.notifier.Close()
+		w.wg.Wait()
+	})
+	return w.closeErr
+}
+
+func (w *Watcher) run() {
+	defer w.wg.Done()
+
+	for {
+		select {
+		case <-w.done:
+			return
+		case _, ok := <-w.notifier.Events():
+			if !ok {
+				return
+			}
+			w.reload()
+		}
+	}
+}
+
+// reload loads the files and delivers the new config or an error
+func (w *Watcher) reload() {
+	config, hash, err := w.load()
+	if err == nil && hash == w.hash {
+		return // only the formatting changed
+	}
+
+	if err == nil && w.Validate != nil {
+		err = w.Validate(w.current, config)
+	}
+
+	if err != nil {
+		select {
+		case w.errors <- err:
+		case <-w.done:
+		}
+		return
+	}
+
+	w.current, w.hash = config, hash
+	select {
+	case w.configs <- config:
+	case <-w.done:
+	}
+}
+
+// load decodes the watched files into a new config and returns it with the
+// hash of the files
+func (w *Watcher) load() (interface{}, string, error) {
+	files, err := w.files()
+	if err != nil {
+		return nil, "", err
+	}
+
+	decoder := w.Decoder
+	if decoder == nil {
+		decoder = &hcl.DefaultDecoderConfig
+	}
+
+	config := w.New()
+	var hashes []string
+	for _, name := range files {
+		src, err := ioutil.ReadFile(name)
+		if err != nil {
+			return nil, "", err
+		}
+
+		// parsed like Decode does, JSON included
+		f, err := decoder.Parse(src)
+		if err != nil {
+			return nil, "", fmt.Errorf("%s: %s", name, err)
+		}
+
+		if err := decoder.DecodeObject(config, f); err != nil {
+			return nil, "", fmt.Errorf("%s: %s", name, err)
+		}
+		hashes = append(hashes, hcl.Hash(f))
+	}
+	return config, strings.Join(hashes, ","), nil
+}
+
+// files returns the names of the watched files in lexical order
+func (w *Watcher) files() ([]string, error) {
+	info, err := os.Stat(w.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	if !info.IsDir() {
+		return []string{w.Path}, nil
+	}
+
+	files, err := filepath.Glob(filepath.Join(w.Path, "*.hcl"))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(files) == 0 {
+		return nil, errors.New("no .hcl files in " + w.Path)
+	}
+
+	sort.Strings(files)
+	return files, nil
+}
+
+// poller is a Notifier polling the modification times of files
+type poller struct {
+	path   string
+	events chan struct{}
+	done   chan struct{}
+	once   sync.Once
+}
+
+// Poll returns a notifier checking the modification times and sizes of the
+// file or the files in the directory at path in the given interval.
+func Poll(path string, interval time.Duration) Notifier {
+	p := &poller{
+		path:   path,
+		events: make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+
+	go p.run(interval)
+	return p
+}
+
+func (p *poller) Events() <-chan struct{} {
+	return p.events
+}
+
+func (p *poller) Close() error {
+	p.once.Do(func() { close(p.done) })
+	return nil
+}
+
+func (p *poller) run(interval time.Duration) {
+	defer close(p.events)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	last := p.state()
+	for {
+		select {
+		case <-p.done:
+			return
+		case <-ticker.C:
+		}
+
+		state := p.state()
+		if state == last {
+			continue
+		}
+		last = state
+
+		select {
+		case p.events <- struct{}{}:
+		case <-p.done:
+			return
+		}
+	}
+}
+
+// state returns the names, modification times and sizes of the files
+func (p *poller) state() string {
+	infos := []os.FileInfo{}
+	info, err := os.Stat(p.path)
+	if err != nil {
+		return ""
+	}
+
+	if info.IsDir() {
+		if infos, err = ioutil.ReadDir(p.path); err != nil {
+			return ""
+		}
+	} else {
+		infos = append(infos, info)
+	}
+
+	var state []string
+	for _, fi := range infos {
+		state = append(state, fmt.Sprintf("%s %d %d", fi.Name(), fi.ModTime().UnixNano(), fi.Size()))
+	}
+	return strings.Join(state, "\n")
+}
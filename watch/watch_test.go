@@ -0,0 +1,223 @@
+package watch
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/fatih/hcl"
+	"github.com/fatih/hcl/parser"
+)
+
+type config struct {
+	Port int    `hcl:"port"`
+	Host string `hcl:"host"`
+}
+
+// notifier is a Notifier triggered by the tests. Like file system
+// notifications, events are coalesced while the watcher is busy.
+type notifier chan struct{}
+
+func (n notifier) Events() <-chan struct{} { return n }
+func (n notifier) Close() error            { return nil }
+
+func (n notifier) notify() {
+	select {
+	case n <- struct{}{}:
+	default:
+	}
+}
+
+func TestWatcher(t *testing.T) {
+	dir, err := ioutil.TempDir("", "watch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "config.hcl")
+	// write replaces the file atomically, so it's never read half written
+	write := func(src string) {
+		tmp := filepath.Join(dir, "config.tmp")
+		if err := ioutil.WriteFile(tmp, []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Rename(tmp, path); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(`port = 80`)
+
+	n := make(notifier, 1)
+	w := New(path, n, func() interface{} { return &config{} })
+	w.Validate = func(old, new interface{}) error {
+		if new.(*config).Port == 0 {
+			return errors.New("port is required")
+		}
+		return nil
+	}
+
+	c, err := w.Start()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	if c.(*config).Port != 80 {
+		t.Fatalf("want port 80, got: %#v", c)
+	}
+
+	// reformatting doesn't deliver a config, so the next one is 8080
+	write("port   =   80 # http")
+	n.notify()
+
+	write(`port = 8080`)
+	n.notify()
+	select {
+	case c := <-w.Configs():
+		if c.(*config).Port != 8080 {
+			t.Errorf("want port 8080, got: %#v", c)
+		}
+	case err := <-w.Errors():
+		t.Fatal(err)
+	}
+
+	write(`host = "localhost"`)
+	n.notify()
+	select {
+	case c := <-w.Configs():
+		t.Errorf("invalid config delivered: %#v", c)
+	case err := <-w.Errors():
+		if err.Error() != "port is required" {
+			t.Errorf("unexpected error: %s", err)
+		}
+	}
+
+	write(`port = `)
+	n.notify()
+	select {
+	case c := <-w.Configs():
+		t.Errorf("invalid config delivered: %#v", c)
+	case err := <-w.Errors():
+		if err == nil {
+			t.Error("expected a parse error")
+		}
+	}
+}
+
+func TestWatcherDir(t *testing.T) {
+	dir, err := ioutil.TempDir("", "watch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"a.hcl":    `port = 80`,
+		"b.hcl":    `host = "localhost"`,
+		"c.txt":    `port = `,
+		"override": `port = 1`,
+	}
+	for name, src := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	w := New(dir, make(notifier), func() interface{} { return &config{} })
+	c, err := w.Start()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	if expected := (&config{Port: 80, Host: "localhost"}); *c.(*config) != *expected {
+		t.Errorf("want: %#v, got: %#v", expected, c)
+	}
+}
+
+// TestWatcherDecoder checks that the files are parsed like Decode parses
+// them, with the settings of the Decoder
+func TestWatcherDecoder(t *testing.T) {
+	dir, err := ioutil.TempDir("", "watch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "config.hcl")
+	cases := []struct {
+		src string
+		err string
+	}{
+		{`{"port": 80, "host": "localhost"}`, ""},
+		{"port = 80\nport = 81", path + ": At 2:1: duplicate attribute port, first defined at 1:1"},
+	}
+
+	for _, c := range cases {
+		if err := ioutil.WriteFile(path, []byte(c.src), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		w := New(path, make(notifier), func() interface{} { return &config{} })
+		w.Decoder = &hcl.DecoderConfig{Duplicates: parser.ErrorOnDuplicates}
+		got, err := w.Start()
+		switch {
+		case c.err != "":
+			if err == nil || err.Error() != c.err {
+				t.Errorf("%q: want error %q, got %v", c.src, c.err, err)
+			}
+			continue
+		case err != nil:
+			t.Errorf("%q: %s", c.src, err)
+			continue
+		}
+
+		if expected := (&config{Port: 80, Host: "localhost"}); *got.(*config) != *expected {
+			t.Errorf("%q: want: %#v, got: %#v", c.src, expected, got)
+		}
+
+		// closing again doesn't panic
+		if err := w.Close(); err != nil {
+			t.Error(err)
+		}
+		if err := w.Close(); err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+func TestPoll(t *testing.T) {
+	dir, err := ioutil.TempDir("", "watch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "config.hcl")
+	if err := ioutil.WriteFile(path, []byte(`port = 80`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	p := Poll(dir, 10*time.Millisecond)
+	defer p.Close()
+
+	time.Sleep(20 * time.Millisecond)
+	if err := ioutil.WriteFile(path, []byte(`port = 8080`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-p.Events():
+	case <-time.After(time.Second):
+		t.Fatal("no event for the changed file")
+	}
+
+	p.Close()
+	if _, ok := <-p.Events(); ok {
+		t.Error("events channel not closed")
+	}
+}
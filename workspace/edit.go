@@ -0,0 +1,285 @@
+package workspace
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/fatih/hcl/ast"
+	"github.com/fatih/hcl/parser"
+	"github.com/fatih/hcl/token"
+)
+
+// Range is the part of the source of a file from Start up to End, excluding
+// End. Only the offsets of the positions are used.
+type Range struct {
+	Start, End token.Pos
+}
+
+// ApplyEdit replaces the range r of the file name with text, i.e. for the
+// edits of an editor, and returns the updated file. Edits inside of a
+// single item at the top level of the file only parse the item again and
+// splice it into a copy of the syntax tree, which shares the items before
+// it with the previous tree, so that large files give fast feedback. Other
+// edits parse the whole file.
+func (w *Workspace) ApplyEdit(name string, r Range, text string) (*File, error) {
+	name = filepath.Clean(name)
+
+	w.mu.RLock()
+	f := w.files[name]
+	w.mu.RUnlock()
+	if f == nil {
+		return nil, fmt.Errorf("file %s is not part of the workspace", name)
+	}
+
+	start, end := r.Start.Offset, r.End.Offset
+	if start < 0 || start > end || end > len(f.Src) {
+		return nil, fmt.Errorf("range %d-%d is outside of file %s of %d bytes", start, end, name, len(f.Src))
+	}
+
+	src := make([]byte, 0, len(f.Src)-(end-start)+len(text))
+	src = append(src, f.Src[:start]...)
+	src = append(src, text...)
+	src = append(src, f.Src[end:]...)
+
+	begin := time.Now()
+	nf := w.reparse(f, start, end, src)
+	if nf != nil {
+		w.log("hcl: applied edit", "file", name, "duration", time.Since(begin), "bytes", len(text), "incremental", true)
+	} else {
+		nf = w.parse(name, src)
+	}
+
+	w.mu.Lock()
+	w.files[name] = nf
+	w.merged = nil
+	w.mu.Unlock()
+	return nf, nil
+}
+
+// reparse returns the file f with the source src, in which the bytes from
+// start up to end of the source of f are replaced, if only the item at the
+// top level containing them needs to be parsed again. It returns nil if the
+// whole file has to be parsed.
+func (w *Workspace) reparse(f *File, start, end int, src []byte) *File {
+	if f.AST == nil || !w.utf8(f.Src) || !w.utf8(src) {
+		return nil
+	}
+
+	list, ok := f.AST.Node.(*ast.ObjectList)
+	if !ok {
+		return nil
+	}
+
+	i := sort.Search(len(list.Items), func(i int) bool {
+		return list.Items[i].End().Offset >= end
+	})
+	if i == len(list.Items) {
+		return nil
+	}
+
+	// the edit must be inside of the item, which must still be separated
+	// from the source around it
+	item := list.Items[i]
+	from, to := item.Pos().Offset, item.End().Offset
+	newTo := to + len(src) - len(f.Src)
+	if start < from || end > to || newTo <= from ||
+		(from > 0 && !isSpace(src[from-1])) || (newTo < len(src) && !isSpace(src[newTo])) {
+		return nil
+	}
+
+	for _, d := range f.AST.Directives {
+		if from <= d.Pos.Offset && d.Pos.Offset < to {
+			return nil
+		}
+	}
+
+	part, err := w.config.Parse(src[from:newTo])
+	if err != nil || len(part.Directives) > 0 {
+		return nil
+	}
+
+	items := part.Node.(*ast.ObjectList).Items
+	if len(items) != 1 || items[0].Pos().Offset != 0 || items[0].End().Offset != newTo-from {
+		return nil
+	}
+
+	// the line comment is kept if the value still starts on the line of the
+	// key
+	newItem := items[0]
+	if sameLine(newItem) != sameLine(item) {
+		return nil
+	}
+
+	// the positions of the new item are relative to the item, the ones
+	// after it are moved by the difference of the ends of the items
+	base := item.Pos()
+	inItem := func(pos token.Pos) token.Pos {
+		pos.Offset += base.Offset
+		if pos.Line == 1 {
+			pos.Column += base.Column - 1
+		}
+		pos.Line += base.Line - 1
+		return pos
+	}
+	move(newItem, inItem)
+	for _, g := range part.Comments {
+		moveComments(g, inItem)
+	}
+
+	oldEnd, newEnd := item.End(), newItem.End()
+	afterItem := func(pos token.Pos) token.Pos {
+		if pos.Line == oldEnd.Line {
+			pos.Column += newEnd.Column - oldEnd.Column
+		}
+		pos.Line += newEnd.Line - oldEnd.Line
+		pos.Offset += newEnd.Offset - oldEnd.Offset
+		return pos
+	}
+
+	// copy the items and comments after the item together, which keeps the
+	// comment groups shared by both
+	var before, after []*ast.CommentGroup
+	lineComment := -1
+	for _, g := range f.AST.Comments {
+		switch {
+		case g.Pos().Offset < from:
+			before = append(before, g)
+		case g.Pos().Offset >= to:
+			if g == item.LineComment {
+				lineComment = len(after)
+			}
+			after = append(after, g)
+		}
+	}
+
+	rest := ast.Copy(&ast.File{
+		Node:     &ast.ObjectList{Items: list.Items[i+1:]},
+		Comments: after,
+	}).(*ast.File)
+	move(rest.Node, afterItem)
+	for _, g := range rest.Comments {
+		moveComments(g, afterItem)
+	}
+
+	newItem.LeadComment = item.LeadComment
+	if lineComment >= 0 {
+		newItem.LineComment = rest.Comments[lineComment]
+	}
+	newItem.Directives = moveDirectives(item.Directives, to, afterItem)
+
+	nl := &ast.ObjectList{Items: make([]*ast.ObjectItem, 0, len(list.Items))}
+	nl.Items = append(nl.Items, list.Items[:i]...)
+	nl.Items = append(nl.Items, newItem)
+	nl.Items = append(nl.Items, rest.Node.(*ast.ObjectList).Items...)
+
+	nf := &ast.File{
+		Node:       nl,
+		Directives: moveDirectives(f.AST.Directives, to, afterItem),
+		EndPos:     afterItem(f.AST.EndPos),
+	}
+	nf.Comments = append(nf.Comments, before...)
+	nf.Comments = append(nf.Comments, part.Comments...)
+	nf.Comments = append(nf.Comments, rest.Comments...)
+	return &File{Name: f.Name, Src: src, AST: nf}
+}
+
+// utf8 reports whether src is parsed as UTF-8, so that offsets into src are
+// offsets of the syntax tree
+func (w *Workspace) utf8(src []byte) bool {
+	switch w.config.Encoding {
+	case parser.UTF8:
+		return true
+	case parser.DetectEncoding:
+		return !bytes.HasPrefix(src, []byte{0xff, 0xfe}) && !bytes.HasPrefix(src, []byte{0xfe, 0xff})
+	}
+	return false
+}
+
+// sameLine reports whether the value of the item starts on the line of its
+// key, only then the parser attaches a line comment to it
+func sameLine(item *ast.ObjectItem) bool {
+	return item.Val.Pos().Line == item.Keys[0].Pos().Line
+}
+
+func isSpace(b byte) bool {
+	return b == ' ' || b == '\t' || b == '\n' || b == '\r'
+}
+
+// move changes the positions of the nodes of n, which must not be shared,
+// with fn. The comments are moved with the comments of the file.
+func move(n ast.Node, fn func(token.Pos) token.Pos) {
+	m := func(pos *token.Pos) {
+		if pos.IsValid() {
+			*pos = fn(*pos)
+		}
+	}
+
+	ast.Walk(n, func(n ast.Node) (ast.Node, bool) {
+		switch t := n.(type) {
+		case *ast.ObjectItem:
+			m(&t.Assign)
+			for _, d := range t.Directives {
+				m(&d.Pos)
+			}
+		case *ast.ObjectKey:
+			m(&t.Token.Pos)
+		case *ast.LiteralType:
+			m(&t.Token.Pos)
+		case *ast.ListType:
+			m(&t.Lbrack)
+			m(&t.Rbrack)
+		case *ast.ObjectType:
+			m(&t.Lbrace)
+			m(&t.Rbrace)
+		case *ast.Template:
+			m(&t.Start)
+		case *ast.TemplateText:
+			m(&t.Start)
+		case *ast.Variable:
+			m(&t.NamePos)
+		case *ast.Call:
+			m(&t.NamePos)
+			m(&t.Lparen)
+			m(&t.Rparen)
+		case *ast.Binary:
+			m(&t.OpPos)
+		case *ast.Unary:
+			m(&t.OpPos)
+		case *ast.Conditional:
+			m(&t.Question)
+			m(&t.Colon)
+		case *ast.ForDirective:
+			m(&t.For)
+			m(&t.EndFor)
+		case *ast.IfDirective:
+			m(&t.If)
+			m(&t.EndIf)
+		}
+		return n, true
+	})
+}
+
+// moveComments changes the positions of the comments of g with fn
+func moveComments(g *ast.CommentGroup, fn func(token.Pos) token.Pos) {
+	for _, c := range g.List {
+		c.Start = fn(c.Start)
+	}
+}
+
+// moveDirectives returns a copy of the directives with the positions from
+// the offset from on changed by fn
+func moveDirectives(ds []*ast.CommentDirective, from int, fn func(token.Pos) token.Pos) []*ast.CommentDirective {
+	var res []*ast.CommentDirective
+	for _, d := range ds {
+		if d.Pos.Offset >= from {
+			cp := *d
+			cp.Pos = fn(cp.Pos)
+			d = &cp
+		}
+		res = append(res, d)
+	}
+	return res
+}
@@ -0,0 +1,88 @@
+package workspace
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/fatih/hcl/parser"
+	"github.com/fatih/hcl/token"
+)
+
+func TestApplyEdit(t *testing.T) {
+	src := `# the name
+name = "app" # line
+
+// the web service
+service "web" {
+  port = 80 #hcl:disable-rule=port
+  tags = ["a", "${var.tag}"]
+}
+
+# trailing
+`
+
+	cases := []struct {
+		old, new    string
+		incremental bool
+	}{
+		{`port = 80`, `port = 8080`, true},
+		{`"app"`, "\"my-app\"", true},
+		{`["a", `, "[\n    \"a\",\n    ", true},
+		{`80`, `upper("${var.port}")`, true},
+		{`"web" {`, `"api" {`, true},
+		{`# line`, `# comment`, false},
+		{`service`, "x = 1\nservice", false},
+		{`= "app"`, "=\n\"app\"", false},
+		{`tags`, `tags = [`, false},
+		{"}\n", "}\nother = true\n", false},
+	}
+
+	for _, c := range cases {
+		w := &Workspace{config: &parser.DefaultConfig, files: make(map[string]*File)}
+		old := w.Update("main.hcl", []byte(src))
+
+		start := strings.Index(src, c.old)
+		r := Range{Start: token.Pos{Offset: start}, End: token.Pos{Offset: start + len(c.old)}}
+		f, err := w.ApplyEdit("main.hcl", r, c.new)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		want := strings.Replace(src, c.old, c.new, 1)
+		if string(f.Src) != want {
+			t.Errorf("%q: want source %q, got %q", c.new, want, f.Src)
+		}
+		if w.File("main.hcl") != f {
+			t.Errorf("%q: file isn't updated", c.new)
+		}
+
+		wantAST, wantErr := parser.Parse([]byte(want))
+		if !reflect.DeepEqual(f.AST, wantAST) || !reflect.DeepEqual(f.Err, wantErr) {
+			t.Errorf("%q: syntax tree differs from the parsed source", c.new)
+		}
+
+		if f.AST == nil {
+			continue
+		}
+
+		// incremental edits share the nodes before the edited item
+		if shared := f.AST.Comments[0] == old.AST.Comments[0]; shared != c.incremental {
+			t.Errorf("%q: want incremental %t", c.new, c.incremental)
+		}
+	}
+}
+
+func TestApplyEditErrors(t *testing.T) {
+	w := &Workspace{config: &parser.DefaultConfig, files: make(map[string]*File)}
+	w.Update("main.hcl", []byte("a = 1\n"))
+
+	if _, err := w.ApplyEdit("other.hcl", Range{}, "b = 2\n"); err == nil {
+		t.Errorf("edit of a missing file succeeded")
+	}
+
+	r := Range{Start: token.Pos{Offset: 4}, End: token.Pos{Offset: 10}}
+	if _, err := w.ApplyEdit("main.hcl", r, "2"); err == nil {
+		t.Errorf("edit outside of the file succeeded")
+	}
+}
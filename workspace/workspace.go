@@ -0,0 +1,291 @@
+// Package workspace keeps the syntax trees of all HCL (HashiCorp
+// Configuration Language) files of a directory tree, i.e. as the state of a
+// language server or a linter daemon. Files are reloaded one at a time when
+// they change, the merged configuration and queries across files are
+// derived from the current trees.
+package workspace
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/fatih/hcl/ast"
+	"github.com/fatih/hcl/merge"
+	"github.com/fatih/hcl/parser"
+	"github.com/fatih/hcl/token"
+)
+
+// File is a file of the workspace. Files are never modified, a reload
+// replaces the File, so they can be used without holding any lock. The
+// syntax tree must not be modified either.
+type File struct {
+	// Name is the path of the file relative to the root of the workspace.
+	Name string
+
+	Src []byte
+
+	// AST is the syntax tree of the file, nil if it doesn't parse.
+	AST *ast.File
+
+	// Err is the error parsing the file.
+	Err error
+}
+
+// Workspace is the set of files with the extension ".hcl" in a directory
+// tree. It's safe for concurrent use.
+type Workspace struct {
+	root   string
+	config *parser.Config
+
+	mu     sync.RWMutex
+	files  map[string]*File
+	merged *merged // cache of Merged, reset by every change
+}
+
+type merged struct {
+	file *ast.File
+	prov *merge.Provenance
+	err  error
+}
+
+// Load returns the workspace of all files with the extension ".hcl" in the
+// directory root and its subdirectories. The files are parsed with config,
+// parser.DefaultConfig if nil. Files which don't parse are part of the
+// workspace with their error, only errors reading the files are returned.
+// If config has a Logger, the parses are logged with the names of the files,
+// as well as the cache hits and misses of Merged.
+func Load(root string, config *parser.Config) (*Workspace, error) {
+	if config == nil {
+		config = &parser.DefaultConfig
+	}
+
+	w := &Workspace{root: root, config: config, files: make(map[string]*File)}
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() || filepath.Ext(path) != ".hcl" {
+			return err
+		}
+
+		name, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+
+		src, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		w.files[name] = w.parse(name, src)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// Root returns the directory of the workspace.
+func (w *Workspace) Root() string {
+	return w.root
+}
+
+// parse returns the file name with the contents src
+func (w *Workspace) parse(name string, src []byte) *File {
+	start := time.Now()
+	f, err := w.config.Parse(src)
+	w.log("hcl: loaded file", "file", name, "duration", time.Since(start), "bytes", len(src), "error", err)
+	if err != nil {
+		return &File{Name: name, Src: src, Err: err}
+	}
+	return &File{Name: name, Src: src, AST: f}
+}
+
+// log records a phase to the logger of the parser configuration, if any
+func (w *Workspace) log(msg string, args ...interface{}) {
+	if w.config.Logger != nil {
+		w.config.Logger.Debug(msg, append([]interface{}{"phase", "workspace"}, args...)...)
+	}
+}
+
+// Reload reads the file name, relative to the root, from disk again. A file
+// which doesn't exist anymore is removed from the workspace. Files with the
+// same contents as before aren't parsed again.
+func (w *Workspace) Reload(name string) error {
+	src, err := ioutil.ReadFile(filepath.Join(w.root, name))
+	if os.IsNotExist(err) {
+		w.Remove(name)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	w.Update(name, src)
+	return nil
+}
+
+// Update sets the contents of the file name, i.e. to the unsaved contents of
+// an editor, and parses it. The file is added if it's not part of the
+// workspace yet.
+func (w *Workspace) Update(name string, src []byte) *File {
+	name = filepath.Clean(name)
+
+	w.mu.RLock()
+	f := w.files[name]
+	w.mu.RUnlock()
+	if f != nil && string(f.Src) == string(src) {
+		return f
+	}
+
+	f = w.parse(name, src)
+
+	w.mu.Lock()
+	w.files[name] = f
+	w.merged = nil
+	w.mu.Unlock()
+	return f
+}
+
+// Remove removes the file name from the workspace.
+func (w *Workspace) Remove(name string) {
+	w.mu.Lock()
+	delete(w.files, filepath.Clean(name))
+	w.merged = nil
+	w.mu.Unlock()
+}
+
+// File returns the file name, or nil if it's not part of the workspace.
+func (w *Workspace) File(name string) *File {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.files[filepath.Clean(name)]
+}
+
+// Files returns all files of the workspace sorted by name.
+func (w *Workspace) Files() []*File {
+	w.mu.RLock()
+	files := make([]*File, 0, len(w.files))
+	for _, f := range w.files {
+		files = append(files, f)
+	}
+	w.mu.RUnlock()
+
+	sort.Slice(files, func(i, j int) bool { return files[i].Name < files[j].Name })
+	return files
+}
+
+// Errors returns the errors of all files which don't parse, sorted by the
+// name of the file. The positions of errors of type *parser.PosError contain
+// the name of the file.
+func (w *Workspace) Errors() []error {
+	var errs []error
+	for _, f := range w.Files() {
+		if f.Err == nil {
+			continue
+		}
+
+		if e, ok := f.Err.(*parser.PosError); ok {
+			pe := *e
+			pe.Pos.Filename = f.Name
+			errs = append(errs, &pe)
+			continue
+		}
+		errs = append(errs, f.Err)
+	}
+	return errs
+}
+
+// Merged returns the files which parse merged in the order of their names
+// with merge.DefaultConfig, along with the provenance of the items. The
+// result is cached until the workspace changes and must not be modified.
+func (w *Workspace) Merged() (*ast.File, *merge.Provenance, error) {
+	w.mu.RLock()
+	m := w.merged
+	w.mu.RUnlock()
+	if m != nil {
+		w.log("hcl: merged", "cache", "hit")
+		return m.file, m.prov, m.err
+	}
+
+	start := time.Now()
+	var files []merge.File
+	for _, f := range w.Files() {
+		if f.AST != nil {
+			files = append(files, merge.File{Name: f.Name, File: f.AST})
+		}
+	}
+
+	m = &merged{}
+	m.file, m.prov, m.err = merge.DefaultConfig.MergeFiles(files...)
+	w.log("hcl: merged", "cache", "miss", "duration", time.Since(start), "files", len(files), "error", m.err)
+
+	w.mu.Lock()
+	w.merged = m
+	w.mu.Unlock()
+	return m.file, m.prov, m.err
+}
+
+// Lookup returns the positions of all items at path in the files of the
+// workspace, in the order of the names of the files and the items. The path
+// is the unquoted keys of the item and of the enclosing items joined by dots,
+// i.e. "service.web.port", see ast.SplitPath. The positions contain the names
+// of the files.
+func (w *Workspace) Lookup(path string) []token.Pos {
+	var res []token.Pos
+	for _, f := range w.Files() {
+		if f.AST == nil {
+			continue
+		}
+
+		list, ok := f.AST.Node.(*ast.ObjectList)
+		if !ok {
+			continue
+		}
+
+		for _, item := range lookup(list, ast.SplitPath(path)) {
+			pos := item.Pos()
+			pos.Filename = f.Name
+			res = append(res, pos)
+		}
+	}
+	return res
+}
+
+// lookup returns the items of list at path
+func lookup(list *ast.ObjectList, path []string) []*ast.ObjectItem {
+	var res []*ast.ObjectItem
+	for _, item := range list.Items {
+		rest, ok := matchKeys(item.Keys, path)
+		if !ok {
+			continue
+		}
+
+		if len(rest) == 0 {
+			res = append(res, item)
+			continue
+		}
+
+		if obj, ok := item.Val.(*ast.ObjectType); ok && obj.List != nil {
+			res = append(res, lookup(obj.List, rest)...)
+		}
+	}
+	return res
+}
+
+// matchKeys returns the rest of path after the keys, if path starts with them
+func matchKeys(keys []*ast.ObjectKey, path []string) ([]string, bool) {
+	if len(keys) > len(path) {
+		return nil, false
+	}
+
+	for i, k := range keys {
+		if k.Token.Value() != path[i] {
+			return nil, false
+		}
+	}
+	return path[len(keys):], true
+}
@@ -0,0 +1,167 @@
+package workspace
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/fatih/hcl/parser"
+	"github.com/fatih/hcl/token"
+)
+
+func TestWorkspace(t *testing.T) {
+	dir, err := ioutil.TempDir("", "workspace")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	write := func(name, src string) {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write("main.hcl", "service \"web\" {\n  port = 80\n}")
+	write(filepath.Join("env", "prod.hcl"), "service \"web\" {\n  port = 443\n}")
+	write("broken.hcl", "a = [")
+	write("README.md", "not hcl")
+
+	w, err := Load(dir, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for _, f := range w.Files() {
+		names = append(names, f.Name)
+	}
+	want := []string{"broken.hcl", filepath.Join("env", "prod.hcl"), "main.hcl"}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("want files %v, got %v", want, names)
+	}
+
+	errs := w.Errors()
+	if len(errs) != 1 || errs[0].Error() != "At broken.hcl:1:6: list opened at 1:5 not terminated, expected: ] got: EOF" {
+		t.Errorf("unexpected errors: %v", errs)
+	}
+
+	wantPos := []token.Pos{
+		{Filename: filepath.Join("env", "prod.hcl"), Offset: 18, Line: 2, Column: 3},
+		{Filename: "main.hcl", Offset: 18, Line: 2, Column: 3},
+	}
+	if pos := w.Lookup("service.web.port"); !reflect.DeepEqual(pos, wantPos) {
+		t.Errorf("want positions %v, got %v", wantPos, pos)
+	}
+
+	// files are merged in the order of their names, main.hcl wins
+	f, prov, err := w.Merged()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f == nil || prov.Explain("service.web.port")[0].Filename != "main.hcl" {
+		t.Errorf("unexpected provenance: %v", prov.Explain("service.web.port"))
+	}
+
+	if f2, _, _ := w.Merged(); f2 != f {
+		t.Error("expected the cached merged file")
+	}
+
+	// fixing and removing files reloads them one at a time
+	write("broken.hcl", "a = [1]")
+	if err := w.Reload("broken.hcl"); err != nil {
+		t.Fatal(err)
+	}
+	if errs := w.Errors(); len(errs) != 0 {
+		t.Errorf("unexpected errors: %v", errs)
+	}
+	if w.File("broken.hcl").AST == nil {
+		t.Error("expected the reloaded file to parse")
+	}
+
+	os.Remove(filepath.Join(dir, "main.hcl"))
+	if err := w.Reload("main.hcl"); err != nil {
+		t.Fatal(err)
+	}
+	if w.File("main.hcl") != nil {
+		t.Error("expected main.hcl to be removed")
+	}
+
+	_, prov, err = w.Merged()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pos := prov.Explain("service.web.port"); len(pos) != 1 || pos[0].Filename != filepath.Join("env", "prod.hcl") {
+		t.Errorf("unexpected provenance after removing main.hcl: %v", pos)
+	}
+
+	// unsaved contents
+	old := w.File("broken.hcl")
+	if w.Update("broken.hcl", []byte("a = [1]")) != old {
+		t.Error("expected unchanged contents to keep the file")
+	}
+	if f := w.Update("new.hcl", []byte("b = 1")); f.AST == nil || w.File("new.hcl") != f {
+		t.Error("expected the updated file to be added")
+	}
+
+	// keys containing dots are quoted in paths
+	w.Update("labels.hcl", []byte("labels {\n  \"app.kubernetes.io/name\" = \"web\"\n}"))
+	path := `labels."app.kubernetes.io/name"`
+	if pos := w.Lookup(path); len(pos) != 1 || pos[0].Line != 2 {
+		t.Errorf("unexpected positions of %s: %v", path, pos)
+	}
+	if _, prov, _ := w.Merged(); len(prov.Explain(path)) != 1 {
+		t.Errorf("unexpected provenance of %s: %v", path, prov.Explain(path))
+	}
+}
+
+// cacheLogger records the files loaded and the cache results of Merged
+type cacheLogger []string
+
+func (l *cacheLogger) Debug(msg string, args ...interface{}) {
+	for i := 0; i+1 < len(args); i += 2 {
+		if args[i] == "file" || args[i] == "cache" {
+			*l = append(*l, msg+" "+args[i+1].(string))
+		}
+	}
+}
+
+func TestWorkspaceLogger(t *testing.T) {
+	dir, err := ioutil.TempDir("", "workspace")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	if err := ioutil.WriteFile(filepath.Join(dir, "main.hcl"), []byte("a = 1"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var l cacheLogger
+	w, err := Load(dir, &parser.Config{Logger: &l})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w.Merged()
+	w.Merged()
+	w.Update("other.hcl", []byte("b = 2"))
+	w.Merged()
+
+	want := []string{
+		"hcl: loaded file main.hcl",
+		"hcl: merged miss",
+		"hcl: merged hit",
+		"hcl: loaded file other.hcl",
+		"hcl: merged miss",
+	}
+	if !reflect.DeepEqual([]string(l), want) {
+		t.Errorf("want %q, got: %q", want, l)
+	}
+}